@@ -2,13 +2,17 @@ package main
 
 import (
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
+	"errors"
+	"flag"
 	"fmt"
+	"os"
+	"syscall"
 
 	"github.com/fatih/color"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"GophKeeper/internal/client"
@@ -16,13 +20,25 @@ import (
 	"GophKeeper/internal/client/app_services/app_service_binary"
 	"GophKeeper/internal/client/app_services/app_service_card"
 	"GophKeeper/internal/client/app_services/app_service_cred"
+	"GophKeeper/internal/client/app_services/app_service_rotation"
 	"GophKeeper/internal/client/app_services/app_service_text"
+	"GophKeeper/internal/client/cache"
+	"GophKeeper/internal/client/cli"
 	"GophKeeper/internal/client/grpc_services/grpc_service_auth"
 	"GophKeeper/internal/client/grpc_services/grpc_service_binary"
 	"GophKeeper/internal/client/grpc_services/grpc_service_card"
 	"GophKeeper/internal/client/grpc_services/grpc_service_cred"
+	"GophKeeper/internal/client/grpc_services/grpc_service_rotation"
 	"GophKeeper/internal/client/grpc_services/grpc_service_text"
+	"GophKeeper/internal/client/interceptors"
+	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/internal/client/session"
+	"GophKeeper/internal/client/tui"
 	"GophKeeper/pkg/logzap"
+	"GophKeeper/pkg/secret"
+	"GophKeeper/pkg/tlsconf"
 )
 
 var (
@@ -38,20 +54,62 @@ func main() {
 	logger := zap.L()
 	cfg := newConfig()
 
-	conn, err := grpc.Dial(cfg.AddrGRPC, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Неинтерактивная команда: результат выводится в stdout, сообщения - в stderr
+	args := flag.Args()
+	if len(args) != 0 {
+		color.Output = os.Stderr
+	} else {
+		printBuildInfo()
+	}
+
+	keys, err := loadKeys(cfg)
+	if err != nil {
+		color.Red("Ошибка ключей шифрования: %v", err)
+		os.Exit(1)
+	}
+
+	transport, err := transportCredentials(cfg)
+	if err != nil {
+		color.Red("Ошибка сертификатов TLS: %v", err)
+		os.Exit(1)
+	}
+
+	// Сессия интерактивного режима сохраняется между запусками,
+	// команды без токена используют ее же
+	sessions := newSessions(cfg, keys.encrypted())
+
+	// Токен доступа продлевается автоматически по refresh token,
+	// новые токены сохраняются в сессии профиля
+	var refresherOpts []interceptors.RefresherOptions
+	if sessions != nil {
+		refresherOpts = append(refresherOpts, interceptors.WithOnUpdate(sessions.Update))
+	}
+	refresher := interceptors.NewTokenRefresher(refresherOpts...)
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(transport)}, refresher.DialOptions()...)
+	// Сервер может отклонять данные, которые клиент не шифрует
+	opts = append(opts, interceptors.NewEncryptionHeader(keys.encrypted()).DialOptions()...)
+
+	conn, err := grpc.Dial(cfg.AddrGRPC, opts...)
 	if err != nil {
 		logger.Fatal("failed gRPC connect", zap.Error(err))
 	}
 
-	cli := newClient(conn, cfg)
-	cli.Start()
+	if len(args) != 0 {
+		code := newCommands(conn, cfg, keys, sessions).Run(args)
+		conn.Close()
+		os.Exit(code)
+	}
+
+	keeper := newClient(conn, cfg, keys, sessions)
+	keeper.Start()
 
 	if err := conn.Close(); err != nil {
 		logger.Fatal("failed gRPC disconnect", zap.Error(err))
 	}
 }
 
-func init() {
+// printBuildInfo - Вывод сведений о сборке в интерактивном режиме.
+func printBuildInfo() {
 
 	fmt.Printf("Build version: %s\n", buildVersion)
 	fmt.Printf("Build date: %s\n", buildDate)
@@ -62,21 +120,39 @@ func newConfig() *client.Config {
 	cfg := client.NewConfig()
 	if err := cfg.ParseArgs(); err != nil {
 		logger := zap.L()
-		logger.Fatal("failed run gRPC server: %v\n", zap.Error(err))
+		logger.Fatal("failed parse config", zap.Error(err))
 	}
 
 	return cfg
 }
 
-func newClient(conn *grpc.ClientConn, cfg *client.Config) *client.Client {
+// transportCredentials - TLS, если он включен в конфигурации, иначе соединение без шифрования.
+func transportCredentials(cfg *client.Config) (credentials.TransportCredentials, error) {
+
+	if !cfg.TLS {
+		color.Red("Connection: insecure (use -tls or -tls-ca)")
+		return insecure.NewCredentials(), nil
+	}
 
-	pubKey := publicKey(cfg.PublicKey)
-	privKey := privateKey(cfg.PrivateKey)
+	tlsCfg, err := tlsconf.Client(cfg.TLSCA, cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, err
+	}
 
-	if pubKey != nil && privKey != nil {
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+func newClient(conn *grpc.ClientConn, cfg *client.Config, keys encryptionKeys, sessions *session.Store) *client.Client {
+
+	pubKey, privKey, vaultKey := keys.public, keys.private, keys.vault
+
+	switch {
+	case vaultKey:
+		color.Green("Encoding data: enabled (master password)")
+	case keys.encrypted():
 		color.Green("Encoding data: enabled")
-	} else {
-		color.Yellow("Encoding data: disabled")
+	default:
+		color.Red("Encoding data: disabled (insecure plaintext)")
 	}
 
 	rpcAuth := grpc_service_auth.NewService(conn)
@@ -84,51 +160,233 @@ func newClient(conn *grpc.ClientConn, cfg *client.Config) *client.Client {
 	rpcBin := grpc_service_binary.NewService(conn)
 	rpcCred := grpc_service_cred.NewService(conn)
 	rpcCard := grpc_service_card.NewService(conn)
+	rpcRotation := grpc_service_rotation.NewService(conn)
+
+	var textSender app_service_text.Sender = rpcText
+	var binSender app_service_binary.Sender = rpcBin
+	var credSender app_service_cred.Sender = rpcCred
+	var cardSender app_service_card.Sender = rpcCard
+
+	// Локальный кэш шифруется ключом, полученным из закрытого ключа,
+	// поэтому без него кэш не используется
+	vault := newVault(cfg, keys.encrypted())
+	if vault != nil {
+		color.Green("Local cache: %s", cfg.CacheDir)
+
+		textSender = cache.NewSender[text_model.Text](vault, "text", rpcText,
+			func(data text_model.Text) string { return data.MetaInfo },
+			func(data *text_model.Text) *int64 { return &data.Version })
+		binSender = cache.NewBinarySender(vault, "binary", rpcBin)
+		credSender = cache.NewSender[cred_model.Credential](vault, "cred", rpcCred,
+			func(data cred_model.Credential) string { return data.MetaInfo },
+			func(data *cred_model.Credential) *int64 { return &data.Version })
+		cardSender = cache.NewSender[card_model.Card](vault, "card", rpcCard,
+			func(data card_model.Card) string { return data.MetaInfo },
+			func(data *card_model.Card) *int64 { return &data.Version })
+	} else {
+		color.Yellow("Local cache: disabled")
+	}
 
-	authApp := app_service_auth.NewService(rpcAuth, app_service_auth.WithSalt(cfg.Salt))
-	textApp := app_service_text.NewService(rpcText, app_service_text.WithPublicKey(pubKey), app_service_text.WithPrivateKey(privKey))
-	binApp := app_service_binary.NewService(rpcBin, app_service_binary.WithPublicKey(pubKey), app_service_binary.WithPrivateKey(privKey))
-	credApp := app_service_cred.NewService(rpcCred, app_service_cred.WithPublicKey(pubKey), app_service_cred.WithPrivateKey(privKey))
-	cardApp := app_service_card.NewService(rpcCard, app_service_card.WithPublicKey(pubKey), app_service_card.WithPrivateKey(privKey))
+	authApp := app_service_auth.NewService(rpcAuth, app_service_auth.WithSalt(cfg.Salt), app_service_auth.WithOffline(vault != nil))
+	textApp := app_service_text.NewService(textSender, app_service_text.WithPublicKey(pubKey), app_service_text.WithPrivateKey(privKey),
+		app_service_text.WithPlaintext(cfg.InsecurePlaintext))
+	binApp := app_service_binary.NewService(binSender, app_service_binary.WithPublicKey(pubKey), app_service_binary.WithPrivateKey(privKey),
+		app_service_binary.WithPlaintext(cfg.InsecurePlaintext))
+	credApp := app_service_cred.NewService(credSender, app_service_cred.WithPublicKey(pubKey), app_service_cred.WithPrivateKey(privKey),
+		app_service_cred.WithPlaintext(cfg.InsecurePlaintext))
+	cardApp := app_service_card.NewService(cardSender, app_service_card.WithPublicKey(pubKey), app_service_card.WithPrivateKey(privKey),
+		app_service_card.WithPlaintext(cfg.InsecurePlaintext))
 
-	return client.NewClient(authApp,
+	// Смена ключа работает с данными на сервере, минуя кэш
+	var rotationOpts []app_service_rotation.RotationOptions
+	if vaultKey {
+		rotationOpts = append(rotationOpts, app_service_rotation.WithVaultKeys(rpcAuth))
+	}
+	rotationApp := app_service_rotation.NewService(rpcRotation, rpcText, rpcCred, rpcCard, rpcBin, rotationOpts...)
+
+	opts := []client.Options{
+		client.WithPrivateKey(privKey),
+		client.WithRotation(rotationApp),
 		client.WithService(textApp),
 		client.WithService(binApp),
 		client.WithService(credApp),
-		client.WithService(cardApp))
+		client.WithService(cardApp),
+	}
+
+	if vault != nil {
+		opts = append(opts, client.WithVault(vault))
+	}
+
+	if vaultKey {
+		opts = append(opts, client.WithVaultKey())
+	}
+
+	if sessions != nil {
+		opts = append(opts, client.WithSessions(sessions))
+	}
+
+	if cfg.TUI {
+		opts = append(opts, client.WithBrowser(tui.New(
+			tui.WithText(textSender),
+			tui.WithCred(credSender),
+			tui.WithCard(cardSender),
+			tui.WithBinary(binSender),
+			tui.WithPlaintext(cfg.InsecurePlaintext))))
+	}
+
+	return client.NewClient(authApp, opts...)
 }
 
-func publicKey(key []byte) *rsa.PublicKey {
+// newCommands - Неинтерактивные команды. Данные передаются на сервер
+// напрямую, без локального кэша.
+func newCommands(conn *grpc.ClientConn, cfg *client.Config, keys encryptionKeys, sessions *session.Store) *cli.CLI {
 
-	block, _ := pem.Decode(key)
-	if block == nil {
-		return nil
+	opts := []cli.Options{
+		cli.WithSalt(cfg.Salt),
+		cli.WithText(grpc_service_text.NewService(conn)),
+		cli.WithCred(grpc_service_cred.NewService(conn)),
+		cli.WithCard(grpc_service_card.NewService(conn)),
+		cli.WithBinary(grpc_service_binary.NewService(conn)),
+		cli.WithKeys(keys.public, keys.private),
+		cli.WithPlaintext(cfg.InsecurePlaintext),
 	}
 
-	pubKey, errKey := x509.ParsePKIXPublicKey(block.Bytes)
-	if errKey != nil {
-		return nil
+	if keys.vault {
+		opts = append(opts, cli.WithVaultKey())
 	}
 
-	switch pub := pubKey.(type) {
-	case *rsa.PublicKey:
-		return pub
-	default:
-		return nil
+	if sessions != nil {
+		opts = append(opts, cli.WithSessions(sessions))
 	}
+
+	return cli.New(grpc_service_auth.NewService(conn), opts...)
 }
 
-func privateKey(key []byte) *rsa.PrivateKey {
+// newVault - Создание локального кэша, если он не отключен и данные шифруются.
+func newVault(cfg *client.Config, encrypted bool) *cache.Vault {
 
-	block, _ := pem.Decode(key)
-	if block == nil {
+	if len(cfg.CacheDir) == 0 || !encrypted {
 		return nil
 	}
 
-	privKey, errParse := x509.ParsePKCS1PrivateKey(block.Bytes)
-	if errParse != nil {
+	return cache.NewVault(cfg.CacheDir, cfg.AddrGRPC)
+}
+
+// newSessions - Сессия профиля, если ее сохранение не отключено.
+// Токены шифруются ключом данных, поэтому без шифрования сессия не сохраняется.
+func newSessions(cfg *client.Config, encrypted bool) *session.Store {
+
+	if len(cfg.SessionDir) == 0 || !encrypted {
 		return nil
 	}
 
-	return privKey
+	store, err := session.NewStore(cfg.SessionDir, cfg.Profile, cfg.AddrGRPC)
+	if err != nil {
+		color.Red("Ошибка профиля: %v", err)
+		os.Exit(1)
+	}
+
+	color.Green("Profile: %s", cfg.Profile)
+	return store
+}
+
+// encryptionKeys - Ключи шифрования данных.
+type encryptionKeys struct {
+	public  *rsa.PublicKey
+	private *rsa.PrivateKey
+	// vault - Ключи не заданы, данные шифруются ключом хранилища,
+	// который открывается мастер-паролем после авторизации.
+	vault bool
+}
+
+// encrypted - Данные шифруются на клиенте.
+func (k encryptionKeys) encrypted() bool {
+	return k.vault || k.public != nil
+}
+
+// loadKeys - Чтение ключей шифрования из конфигурации.
+// Ключи задаются парой, ошибка чтения любого из них - ошибка запуска.
+// Без ключей используется ключ хранилища, а в режиме --insecure-plaintext
+// данные не шифруются.
+func loadKeys(cfg *client.Config) (encryptionKeys, error) {
+
+	if len(cfg.PublicKey) == 0 && len(cfg.PrivateKey) == 0 {
+		return encryptionKeys{vault: !cfg.InsecurePlaintext}, nil
+	}
+
+	if len(cfg.PublicKey) == 0 || len(cfg.PrivateKey) == 0 {
+		return encryptionKeys{}, errors.New("public and private keys must be set together")
+	}
+
+	pubKey, err := publicKey(cfg.PublicKey)
+	if err != nil {
+		return encryptionKeys{}, fmt.Errorf("public key: %w", err)
+	}
+
+	privKey, err := privateKey(cfg.PrivateKey)
+	if err != nil {
+		return encryptionKeys{}, fmt.Errorf("private key: %w", err)
+	}
+
+	if !privKey.PublicKey.Equal(pubKey) {
+		return encryptionKeys{}, errors.New("public key does not match private key")
+	}
+
+	return encryptionKeys{public: pubKey, private: privKey}, nil
+}
+
+// passphraseAttempts - Количество попыток ввода парольной фразы закрытого ключа.
+const passphraseAttempts = 3
+
+// publicKey - Открытый ключ RSA из PEM. Другие типы ключей
+// не подходят для шифрования данных.
+func publicKey(key []byte) (*rsa.PublicKey, error) {
+
+	pubKey, err := secret.ParsePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := pubKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, data encryption requires an RSA key", secret.ErrKeyType, secret.KeyType(pubKey))
+	}
+
+	return pub, nil
+}
+
+// privateKey - Закрытый ключ RSA из PEM.
+// Для зашифрованного ключа запрашивается парольная фраза.
+func privateKey(key []byte) (*rsa.PrivateKey, error) {
+
+	var passphrase []byte
+	attempts := 1
+	if secret.IsEncryptedKey(key) {
+		attempts = passphraseAttempts
+	}
+
+	for i := 0; ; i++ {
+
+		if secret.IsEncryptedKey(key) {
+			fmt.Print("Парольная фраза закрытого ключа: ")
+			passphrase, _ = term.ReadPassword(int(syscall.Stdin))
+			fmt.Println()
+		}
+
+		privKey, err := secret.ParsePrivateKey(key, passphrase)
+		if errors.Is(err, secret.ErrPassphrase) && i+1 < attempts {
+			color.Red("Неверная парольная фраза")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		priv, ok := privKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s, data encryption requires an RSA key", secret.ErrKeyType, secret.KeyType(privKey))
+		}
+
+		return priv, nil
+	}
 }
@@ -1,52 +1,118 @@
 package main
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
-	"io/ioutil"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/term"
 
 	"GophKeeper/pkg/logzap"
+	"GophKeeper/pkg/secret"
 )
 
-func GenerateRsaKeyPair() (*rsa.PrivateKey, *rsa.PublicKey) {
-	key, _ := rsa.GenerateKey(rand.Reader, 4096)
-	return key, &key.PublicKey
+// Режимы работы.
+const (
+	// modeKeys - Ключи шифрования данных.
+	modeKeys = "keys"
+	// modePKI - Самоподписанный CA и сертификаты сервера и клиента для TLS.
+	modePKI = "pki"
+)
+
+// Config - Параметры создания ключей.
+type Config struct {
+	// Mode - Режим работы: modeKeys или modePKI.
+	Mode string
+	// OutDir - Каталог для файлов ключей.
+	OutDir      string
+	PrivateName string
+	PublicName  string
+	// KeyType - Тип ключа, один из secret.KeyTypes.
+	KeyType string
+	// Passphrase - Парольная фраза закрытого ключа. Если не задана, запрашивается.
+	Passphrase string
+	// NoPassphrase - Закрытый ключ записывается без шифрования.
+	NoPassphrase bool
+	// Hosts - Имена и адреса сервера в его сертификате (режим modePKI).
+	Hosts []string
+	// ClientName - Имя клиента в его сертификате (режим modePKI).
+	ClientName string
+	// Validity - Срок действия сертификатов (режим modePKI).
+	Validity time.Duration
 }
 
-func PrivateToString(key *rsa.PrivateKey) string {
-	bytes := x509.MarshalPKCS1PrivateKey(key)
-	private := pem.EncodeToMemory(
-		&pem.Block{
+// ParseArgs - Разбор аргументов командной строки.
+func (cfg *Config) ParseArgs() error {
+
+	flag.StringVar(&cfg.Mode, "mode", modeKeys, "string - keys: data encryption keys, pki: self-signed CA with server and client TLS certificates")
+	flag.StringVar(&cfg.OutDir, "out", ".", "string - output directory")
+	flag.StringVar(&cfg.PrivateName, "private", "private.key", "string - private key file name")
+	flag.StringVar(&cfg.PublicName, "public", "public.key", "string - public key file name")
+	flag.StringVar(&cfg.KeyType, "type", secret.KeyRSA4096, "string - key type: "+strings.Join(secret.KeyTypes, ", ")+" (keys mode accepts RSA only)")
+	flag.StringVar(&cfg.Passphrase, "passphrase", "", "string - private key passphrase, prompted if empty")
+	flag.BoolVar(&cfg.NoPassphrase, "no-passphrase", false, "bool - write private key unencrypted")
+	hosts := flag.String("hosts", "localhost,127.0.0.1", "string - comma-separated server names and addresses (pki mode)")
+	flag.StringVar(&cfg.ClientName, "client-name", "gophkeeper-client", "string - client certificate common name (pki mode)")
+	flag.DurationVar(&cfg.Validity, "validity", 365*24*time.Hour, "duration - certificate validity (pki mode)")
+	flag.Parse()
+
+	if cfg.Mode == modePKI {
+		// По умолчанию сертификаты подписываются Ed25519: RSA-4096 избыточен для TLS
+		typeSet := false
+		flag.Visit(func(f *flag.Flag) { typeSet = typeSet || f.Name == "type" })
+		if !typeSet {
+			cfg.KeyType = secret.KeyEd25519
+		}
+
+		if cfg.KeyType == secret.KeyX25519 {
+			return errors.New("x25519 keys can not sign certificates")
+		}
+
+		for _, host := range strings.Split(*hosts, ",") {
+			if host = strings.TrimSpace(host); len(host) != 0 {
+				cfg.Hosts = append(cfg.Hosts, host)
+			}
+		}
+
+		if len(cfg.Hosts) == 0 {
+			return errors.New("server hosts can not be empty")
+		}
+
+		if cfg.Validity <= 0 {
+			return errors.New("certificate validity must be positive")
+		}
+
+		return nil
+	}
 
-			Type:  "RSA PRIVATE KEY",
-			Bytes: bytes,
-		},
-	)
-	return string(private)
-}
+	if cfg.Mode != modeKeys {
+		return fmt.Errorf("unknown mode %q", cfg.Mode)
+	}
 
-func PublicToString(key *rsa.PublicKey) (string, error) {
-	bytes, err := x509.MarshalPKIXPublicKey(key)
-	if err != nil {
-		return ``, err
+	if len(cfg.PrivateName) == 0 || len(cfg.PublicName) == 0 {
+		return errors.New("key file names can not be empty")
 	}
 
-	public := pem.EncodeToMemory(
-		&pem.Block{
-			Type:  "RSA PUBLIC KEY",
-			Bytes: bytes,
-		},
-	)
+	if cfg.NoPassphrase && len(cfg.Passphrase) != 0 {
+		return errors.New("-passphrase and -no-passphrase are mutually exclusive")
+	}
 
-	return string(public), nil
-}
+	// Клиент шифрует данные только ключами RSA: ключи других типов
+	// подходят лишь для сертификатов режима modePKI
+	switch cfg.KeyType {
+	case secret.KeyRSA3072, secret.KeyRSA4096:
+	default:
+		return fmt.Errorf("%s keys can not encrypt data, use %s or %s (or -mode pki for certificates)",
+			cfg.KeyType, secret.KeyRSA3072, secret.KeyRSA4096)
+	}
 
-func ExportToFile(data string, file string) error {
-	return ioutil.WriteFile(file, []byte(data), 0644)
+	return nil
 }
 
 func main() {
@@ -54,23 +120,123 @@ func main() {
 	logzap.ConfigZapLogger()
 	logger := zap.L()
 
-	privatePath := "private.key"
-	publicPath := "public.key"
+	cfg := &Config{}
+	if err := cfg.ParseArgs(); err != nil {
+		logger.Fatal("invalid arguments", zap.Error(err))
+	}
+
+	if cfg.Mode == modePKI {
+		generatePKI(cfg, logger)
+		return
+	}
+
+	generateKeys(cfg, logger)
+}
+
+// generateKeys - Создание ключей шифрования данных.
+func generateKeys(cfg *Config, logger *zap.Logger) {
+
+	privatePath := filepath.Join(cfg.OutDir, cfg.PrivateName)
+	publicPath := filepath.Join(cfg.OutDir, cfg.PublicName)
+
+	// Существующие ключи не перезаписываются: иначе зашифрованные ими данные будут потеряны
+	for _, path := range []string{privatePath, publicPath} {
+		if _, err := os.Stat(path); err == nil {
+			logger.Fatal("key file already exists", zap.String("file", path))
+		}
+	}
+
+	passphrase, err := readPassphrase(cfg)
+	if err != nil {
+		logger.Fatal("failed read passphrase", zap.Error(err))
+	}
+
+	privateKey, err := secret.GenerateKey(cfg.KeyType)
+	if err != nil {
+		logger.Fatal("failed generate key", zap.Error(err))
+	}
+
+	publicKey, err := secret.PublicKey(privateKey)
+	if err != nil {
+		logger.Fatal("failed get public key", zap.Error(err))
+	}
+
+	privatePEM, err := secret.MarshalPrivateKey(privateKey, passphrase)
+	if err != nil {
+		logger.Fatal("failed marshal private key", zap.Error(err))
+	}
+
+	publicPEM, err := secret.MarshalPublicKey(publicKey)
+	if err != nil {
+		logger.Fatal("failed marshal public key", zap.Error(err))
+	}
 
-	privateKey, publicKey := GenerateRsaKeyPair()
+	if err = os.MkdirAll(cfg.OutDir, 0700); err != nil {
+		logger.Fatal("failed create output directory", zap.Error(err))
+	}
 
-	privatePEM := PrivateToString(privateKey)
-	publicPEM, _ := PublicToString(publicKey)
+	if err = ExportToFile(privatePEM, privatePath, 0600); err != nil {
+		logger.Fatal("failed export to file private key", zap.Error(err))
+	}
+	logger.Info("success export private key to file",
+		zap.String("file", privatePath),
+		zap.String("type", cfg.KeyType),
+		zap.Bool("encrypted", len(passphrase) != 0))
 
-	if err := ExportToFile(privatePEM, privatePath); err != nil {
-		logger.Error("failed export to file private key", zap.Error(err))
-	} else {
-		logger.Info("success export private key to file", zap.String("file", privatePath))
+	if err = ExportToFile(publicPEM, publicPath, 0644); err != nil {
+		logger.Fatal("failed export to file public key", zap.Error(err))
 	}
+	logger.Info("success export public key to file", zap.String("file", publicPath))
+}
+
+// ExportToFile - Запись data в новый файл с правами perm.
+func ExportToFile(data []byte, file string, perm os.FileMode) error {
 
-	if err := ExportToFile(publicPEM, publicPath); err != nil {
-		logger.Error("failed export to file public key", zap.Error(err))
-	} else {
-		logger.Info("success export public key to file", zap.String("file", publicPath))
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
 	}
+
+	return f.Close()
+}
+
+// readPassphrase - Парольная фраза из аргументов или с подтверждением из терминала.
+func readPassphrase(cfg *Config) ([]byte, error) {
+
+	if cfg.NoPassphrase {
+		return nil, nil
+	}
+
+	if len(cfg.Passphrase) != 0 {
+		return []byte(cfg.Passphrase), nil
+	}
+
+	fmt.Print("Passphrase: ")
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("%w (use -passphrase or -no-passphrase)", err)
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase (use -no-passphrase to write the key unencrypted)")
+	}
+
+	fmt.Print("Confirm passphrase: ")
+	confirm, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return nil, err
+	}
+
+	if string(passphrase) != string(confirm) {
+		return nil, errors.New("passphrases do not match")
+	}
+
+	return passphrase, nil
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"GophKeeper/pkg/secret"
+)
+
+func TestGenerateKeys(t *testing.T) {
+
+	cfg := &Config{
+		OutDir:      filepath.Join(t.TempDir(), "keys"),
+		PrivateName: "private.key",
+		PublicName:  "public.key",
+		KeyType:     secret.KeyRSA3072,
+		Passphrase:  "secret",
+	}
+
+	generateKeys(cfg, zap.NewNop())
+
+	privatePath := filepath.Join(cfg.OutDir, cfg.PrivateName)
+
+	// Закрытый ключ доступен только владельцу
+	stat, err := os.Stat(privatePath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+
+	stat, err = os.Stat(cfg.OutDir)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0700), stat.Mode().Perm())
+
+	data, err := os.ReadFile(privatePath)
+	require.NoError(t, err)
+	require.True(t, secret.IsEncryptedKey(data))
+
+	key, err := secret.ParsePrivateKey(data, []byte(cfg.Passphrase))
+	require.NoError(t, err)
+	require.Equal(t, secret.KeyRSA3072, secret.KeyType(key))
+
+	pub, err := secret.PublicKey(key)
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(filepath.Join(cfg.OutDir, cfg.PublicName))
+	require.NoError(t, err)
+
+	parsed, err := secret.ParsePublicKey(data)
+	require.NoError(t, err)
+	require.Equal(t, pub, parsed)
+}
+
+func TestExportToFile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "private.key")
+
+	require.NoError(t, ExportToFile([]byte("first"), path, 0600))
+
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+
+	// Существующий файл не перезаписывается
+	require.ErrorIs(t, ExportToFile([]byte("second"), path, 0600), fs.ErrExist)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(data))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/pkg/tlsconf"
+)
+
+// Файлы сертификатов режима modePKI.
+const (
+	caCertFile     = "ca.crt"
+	caKeyFile      = "ca.key"
+	serverCertFile = "server.crt"
+	serverKeyFile  = "server.key"
+	clientCertFile = "client.crt"
+	clientKeyFile  = "client.key"
+)
+
+// generatePKI - Создание самоподписанного CA и подписанных им сертификатов
+// сервера и клиента для локального развертывания с TLS и mTLS.
+func generatePKI(cfg *Config, logger *zap.Logger) {
+
+	files := []string{caCertFile, caKeyFile, serverCertFile, serverKeyFile, clientCertFile, clientKeyFile}
+	for _, name := range files {
+		if _, err := os.Stat(filepath.Join(cfg.OutDir, name)); err == nil {
+			logger.Fatal("certificate file already exists", zap.String("file", filepath.Join(cfg.OutDir, name)))
+		}
+	}
+
+	ca, err := tlsconf.NewCA(cfg.KeyType, "GophKeeper CA", cfg.Validity)
+	if err != nil {
+		logger.Fatal("failed generate CA", zap.Error(err))
+	}
+
+	serverCert, err := tlsconf.NewServerCert(ca, cfg.KeyType, cfg.Hosts, cfg.Validity)
+	if err != nil {
+		logger.Fatal("failed generate server certificate", zap.Error(err))
+	}
+
+	clientCert, err := tlsconf.NewClientCert(ca, cfg.KeyType, cfg.ClientName, cfg.Validity)
+	if err != nil {
+		logger.Fatal("failed generate client certificate", zap.Error(err))
+	}
+
+	if err = os.MkdirAll(cfg.OutDir, 0700); err != nil {
+		logger.Fatal("failed create output directory", zap.Error(err))
+	}
+
+	for _, issued := range []struct {
+		cert, key string
+		pair      *tlsconf.Issued
+	}{
+		{caCertFile, caKeyFile, ca},
+		{serverCertFile, serverKeyFile, serverCert},
+		{clientCertFile, clientKeyFile, clientCert},
+	} {
+		certPath := filepath.Join(cfg.OutDir, issued.cert)
+		keyPath := filepath.Join(cfg.OutDir, issued.key)
+
+		if err = ExportToFile(issued.pair.KeyPEM, keyPath, 0600); err != nil {
+			logger.Fatal("failed export to file private key", zap.Error(err))
+		}
+
+		if err = ExportToFile(issued.pair.CertPEM, certPath, 0644); err != nil {
+			logger.Fatal("failed export to file certificate", zap.Error(err))
+		}
+
+		logger.Info("success export certificate to file",
+			zap.String("cert", certPath),
+			zap.String("key", keyPath),
+			zap.String("subject", issued.pair.Cert.Subject.CommonName),
+			zap.String("type", cfg.KeyType))
+	}
+}
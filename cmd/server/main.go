@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,12 +10,15 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"GophKeeper/internal/server"
 	"GophKeeper/internal/server/app_services/app_service_auth"
 	"GophKeeper/internal/server/app_services/app_service_binary"
 	"GophKeeper/internal/server/app_services/app_service_card"
 	"GophKeeper/internal/server/app_services/app_service_credential"
+	"GophKeeper/internal/server/app_services/app_service_rotation"
 	"GophKeeper/internal/server/app_services/app_service_text"
 	"GophKeeper/internal/server/server_grpc"
 	"GophKeeper/internal/server/server_grpc/interceptors"
@@ -22,13 +26,19 @@ import (
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_binary"
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_card"
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_cred"
+	"GophKeeper/internal/server/server_grpc/services/grpc_service_rotation"
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_text"
 	"GophKeeper/internal/storage/auth_store"
 	"GophKeeper/internal/storage/binary_store"
 	"GophKeeper/internal/storage/card_store"
 	"GophKeeper/internal/storage/credential_store"
+	"GophKeeper/internal/storage/history"
+	"GophKeeper/internal/storage/key_store"
+	"GophKeeper/internal/storage/session_store"
 	"GophKeeper/internal/storage/text_store"
 	"GophKeeper/pkg/logzap"
+	"GophKeeper/pkg/passhash"
+	"GophKeeper/pkg/tlsconf"
 )
 
 var (
@@ -49,10 +59,17 @@ func main() {
 	cfg := newConfig()
 
 	var authStore auth_store.AuthStorage
+	var sessionStore session_store.SessionStorage
+	var keyStore key_store.KeyStorage
 	var credStore credential_store.CredStorage
 	var binStore binary_store.BinaryStorage
 	var textStore text_store.TextStorage
 	var cardStore card_store.CardStorage
+	// Данные, которые удаляются вместе с учетной записью отдельно от нее
+	var accountData []app_service_auth.AccountData
+
+	// Количество хранимых прежних версий записей
+	retention := history.WithRetention(cfg.RevisionRetention)
 
 	// Создание хранилищ
 	if len(cfg.DatabaseURI) != 0 {
@@ -63,24 +80,48 @@ func main() {
 		}
 
 		authStore = auth_store.NewPostgresStorage(db)
-		textStore = text_store.NewPostgresStorage(db)
-		binStore = binary_store.NewPostgresStorage(db)
-		credStore = credential_store.NewPostgresStorage(db)
-		cardStore = card_store.NewPostgresStorage(db)
+		sessionStore = session_store.NewPostgresStorage(db)
+		keyStore = key_store.NewPostgresStorage(db)
+		textStore = text_store.NewPostgresStorage(db, retention)
+		binStore = binary_store.NewPostgresStorage(db, retention)
+		credStore = credential_store.NewPostgresStorage(db, retention)
+		cardStore = card_store.NewPostgresStorage(db, retention)
 	} else {
 		authStore = auth_store.NewMemoryStorage()
-		credStore = credential_store.NewMemoryStorage()
-		binStore = binary_store.NewMemoryStorage()
-		textStore = text_store.NewMemoryStorage()
-		cardStore = card_store.NewMemoryStorage()
+		sessionStore = session_store.NewMemoryStorage()
+
+		keys := key_store.NewMemoryStorage()
+		creds := credential_store.NewMemoryStorage(retention)
+		bins := binary_store.NewMemoryStorage(retention)
+		texts := text_store.NewMemoryStorage(retention)
+		cards := card_store.NewMemoryStorage(retention)
+
+		keyStore, credStore, binStore, textStore, cardStore = keys, creds, bins, texts, cards
+		accountData = []app_service_auth.AccountData{keys, creds, bins, texts, cards}
+	}
+
+	// Параметры проверены при разборе конфигурации
+	hashParams, _ := passhash.ByName(cfg.PasswordHash)
+
+	authOpts := []app_service_auth.AuthAppOption{
+		app_service_auth.WithPasswordHash(hashParams),
+		app_service_auth.WithSecretKey(cfg.SecretKey),
+		app_service_auth.WithSessionStore(sessionStore),
+		app_service_auth.WithKeyStore(keyStore),
+		app_service_auth.WithTokenTTL(cfg.AccessTokenTTL, cfg.RefreshTokenTTL),
+		app_service_auth.WithAccountData(accountData...),
+	}
+	if cfg.UniformAuthErrors {
+		authOpts = append(authOpts, app_service_auth.WithUniformErrors())
 	}
 
 	// Создание сервисов приложения
-	authApp := app_service_auth.NewAuthService(authStore, app_service_auth.WithSecretKey(cfg.SecretKey))
+	authApp := app_service_auth.NewAuthService(authStore, authOpts...)
 	credApp := app_service_credential.NewCredentialAppService(credStore)
 	binApp := app_service_binary.NewBinaryAppService(binStore)
 	textApp := app_service_text.NewTextAppService(textStore)
 	cardApp := app_service_card.NewCardAppService(cardStore)
+	rotationApp := app_service_rotation.NewRotationAppService(keyStore, textStore, credStore, cardStore, binStore)
 
 	// Создание gRPC сервисов
 	authRPC := grpc_service_auth.NewAuthServiceRPC(authApp)
@@ -88,8 +129,32 @@ func main() {
 	binRPC := grpc_service_binary.NewBinaryServiceRPC(binApp)
 	textRPC := grpc_service_text.NewTextServiceRPC(textApp)
 	cardRPC := grpc_service_card.NewCardServiceRPC(cardApp)
+	rotationRPC := grpc_service_rotation.NewRotationServiceRPC(rotationApp)
+
+	validate := interceptors.NewValidateInterceptor(cfg.SecretKey, sessionStore)
+	validate = append(validate, interceptors.NewRateLimitInterceptor(interceptors.RateLimit{
+		MaxFailures: cfg.LoginMaxFailures,
+		Backoff:     cfg.LoginBackoff,
+		Lockout:     cfg.LoginLockout,
+	})...)
+	if cfg.RejectPlaintext {
+		validate = append(validate, interceptors.NewEncryptionInterceptor()...)
+	}
+
+	// Сертификаты перечитываются при изменении файлов
+	ctx, cancel := context.WithCancel(context.Background())
+	if len(cfg.TLSCert) != 0 {
+		certs, errTLS := tlsconf.NewServer(cfg.TLSCert, cfg.TLSKey, cfg.TLSClientCA)
+		if errTLS != nil {
+			logger.Fatal("failed load TLS certificates", zap.Error(errTLS))
+		}
+		go certs.Watch(ctx, tlsconf.ReloadInterval)
 
-	validate := interceptors.NewValidateInterceptor(cfg.SecretKey)
+		validate = append(validate, grpc.Creds(credentials.NewTLS(certs.Config())))
+		logger.Info("TLS enabled", zap.Bool("mTLS", len(cfg.TLSClientCA) != 0))
+	} else {
+		logger.Warn("TLS disabled: passwords and tokens are sent unencrypted")
+	}
 
 	// Создание сервера
 	grpcServer, err := server_grpc.NewServer(
@@ -100,6 +165,7 @@ func main() {
 		server_grpc.WithBinaryServiceRPC(binRPC),
 		server_grpc.WithTextServiceRPC(textRPC),
 		server_grpc.WithCardServiceRPC(cardRPC),
+		server_grpc.WithRotationServiceRPC(rotationRPC),
 	)
 
 	if err != nil {
@@ -109,10 +175,19 @@ func main() {
 
 	grpcServer.Start()
 
-	done := make(chan os.Signal)
+	// Фоновая очистка корзины
+	go purgeTrash(ctx, cfg.TrashRetention, map[string]trashPurger{
+		"cred":   credStore,
+		"binary": binStore,
+		"text":   textStore,
+		"card":   cardStore,
+	})
+
+	done := make(chan os.Signal, 1)
 	signal.Notify(done, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	<-done
 
+	cancel()
 	grpcServer.Stop()
 }
 
@@ -128,7 +203,7 @@ func newConfig() *server.Config {
 	cfg := server.NewConfig()
 	if err := cfg.ParseArgs(); err != nil {
 		logger := zap.L()
-		logger.Error("failed parse args", zap.Error(err))
+		logger.Fatal("failed parse config", zap.Error(err))
 	}
 
 	return cfg
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// purgeInterval - Период очистки корзины.
+const purgeInterval = time.Hour
+
+// trashPurger - Хранилище с корзиной удаленных данных.
+type trashPurger interface {
+	PurgeExpired(before time.Time) (int, error)
+}
+
+// purgeTrash - Фоновая очистка корзин stores от данных,
+// удаленных раньше чем retention назад. Работает до отмены ctx.
+func purgeTrash(ctx context.Context, retention time.Duration, stores map[string]trashPurger) {
+
+	logger := zap.L()
+
+	interval := purgeInterval
+	if retention < interval {
+		interval = retention
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for name, store := range stores {
+			purged, err := store.PurgeExpired(time.Now().Add(-retention))
+			if err != nil {
+				logger.Error("failed purge trash", zap.Error(err), zap.String("store", name))
+				continue
+			}
+
+			if purged != 0 {
+				logger.Info("trash purged", zap.String("store", name), zap.Int("records", purged))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
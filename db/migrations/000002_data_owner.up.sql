@@ -0,0 +1,15 @@
+ALTER TABLE cred_data ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE cred_data DROP CONSTRAINT IF EXISTS cred_data_meta_key;
+ALTER TABLE cred_data ADD CONSTRAINT cred_data_user_meta_key UNIQUE (user_id, meta);
+
+ALTER TABLE bin_data ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE bin_data DROP CONSTRAINT IF EXISTS bin_data_meta_key;
+ALTER TABLE bin_data ADD CONSTRAINT bin_data_user_meta_key UNIQUE (user_id, meta);
+
+ALTER TABLE text_data ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE text_data DROP CONSTRAINT IF EXISTS text_data_meta_key;
+ALTER TABLE text_data ADD CONSTRAINT text_data_user_meta_key UNIQUE (user_id, meta);
+
+ALTER TABLE card_data ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE card_data DROP CONSTRAINT IF EXISTS card_data_meta_key;
+ALTER TABLE card_data ADD CONSTRAINT card_data_user_meta_key UNIQUE (user_id, meta);
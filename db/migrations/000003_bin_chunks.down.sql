@@ -0,0 +1,6 @@
+ALTER TABLE bin_data ADD COLUMN IF NOT EXISTS bytes BYTEA;
+
+UPDATE bin_data
+SET bytes = (SELECT string_agg(bytes, '' ORDER BY idx) FROM bin_chunks WHERE data_id = bin_data.id);
+
+DROP TABLE IF EXISTS bin_chunks;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS bin_chunks (
+    data_id      INTEGER NOT NULL REFERENCES bin_data (id) ON DELETE CASCADE,
+    idx          INTEGER NOT NULL,
+    bytes        BYTEA   NOT NULL,
+    PRIMARY KEY (data_id, idx)
+);
+
+INSERT INTO bin_chunks (data_id, idx, bytes)
+SELECT id, 0, bytes
+FROM bin_data
+WHERE bytes IS NOT NULL AND length(bytes) > 0;
+
+ALTER TABLE bin_data DROP COLUMN IF EXISTS bytes;
@@ -0,0 +1,11 @@
+ALTER TABLE cred_data DROP COLUMN IF EXISTS version;
+ALTER TABLE cred_data DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE bin_data DROP COLUMN IF EXISTS version;
+ALTER TABLE bin_data DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE text_data DROP COLUMN IF EXISTS version;
+ALTER TABLE text_data DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE card_data DROP COLUMN IF EXISTS version;
+ALTER TABLE card_data DROP COLUMN IF EXISTS updated_at;
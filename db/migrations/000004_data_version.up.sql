@@ -0,0 +1,11 @@
+ALTER TABLE cred_data ADD COLUMN IF NOT EXISTS version    BIGINT      NOT NULL DEFAULT 1;
+ALTER TABLE cred_data ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE bin_data ADD COLUMN IF NOT EXISTS version    BIGINT      NOT NULL DEFAULT 1;
+ALTER TABLE bin_data ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE text_data ADD COLUMN IF NOT EXISTS version    BIGINT      NOT NULL DEFAULT 1;
+ALTER TABLE text_data ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE card_data ADD COLUMN IF NOT EXISTS version    BIGINT      NOT NULL DEFAULT 1;
+ALTER TABLE card_data ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS bin_history_chunks;
+DROP TABLE IF EXISTS bin_history;
+DROP TABLE IF EXISTS card_history;
+DROP TABLE IF EXISTS text_history;
+DROP TABLE IF EXISTS cred_history;
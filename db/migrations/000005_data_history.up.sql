@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS cred_history (
+    data_id       INTEGER     NOT NULL REFERENCES cred_data (id) ON DELETE CASCADE,
+    version       BIGINT      NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    email         BYTEA,
+    password_hash BYTEA,
+    PRIMARY KEY (data_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS text_history (
+    data_id       INTEGER     NOT NULL REFERENCES text_data (id) ON DELETE CASCADE,
+    version       BIGINT      NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    text          BYTEA,
+    PRIMARY KEY (data_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS card_history (
+    data_id       INTEGER     NOT NULL REFERENCES card_data (id) ON DELETE CASCADE,
+    version       BIGINT      NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    num           BYTEA,
+    period_dt     BYTEA,
+    cvv           BYTEA,
+    full_name     BYTEA,
+    PRIMARY KEY (data_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS bin_history (
+    id            SERIAL PRIMARY KEY,
+    data_id       INTEGER     NOT NULL REFERENCES bin_data (id) ON DELETE CASCADE,
+    version       BIGINT      NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL,
+    UNIQUE (data_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS bin_history_chunks (
+    history_id    INTEGER NOT NULL REFERENCES bin_history (id) ON DELETE CASCADE,
+    idx           INTEGER NOT NULL,
+    bytes         BYTEA   NOT NULL,
+    PRIMARY KEY (history_id, idx)
+);
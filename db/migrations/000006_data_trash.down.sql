@@ -0,0 +1,27 @@
+DELETE FROM cred_data WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS cred_data_deleted_at;
+DROP INDEX IF EXISTS cred_data_user_meta_trash;
+DROP INDEX IF EXISTS cred_data_user_meta_live;
+ALTER TABLE cred_data ADD CONSTRAINT cred_data_user_meta_key UNIQUE (user_id, meta);
+ALTER TABLE cred_data DROP COLUMN IF EXISTS deleted_at;
+
+DELETE FROM bin_data WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS bin_data_deleted_at;
+DROP INDEX IF EXISTS bin_data_user_meta_trash;
+DROP INDEX IF EXISTS bin_data_user_meta_live;
+ALTER TABLE bin_data ADD CONSTRAINT bin_data_user_meta_key UNIQUE (user_id, meta);
+ALTER TABLE bin_data DROP COLUMN IF EXISTS deleted_at;
+
+DELETE FROM text_data WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS text_data_deleted_at;
+DROP INDEX IF EXISTS text_data_user_meta_trash;
+DROP INDEX IF EXISTS text_data_user_meta_live;
+ALTER TABLE text_data ADD CONSTRAINT text_data_user_meta_key UNIQUE (user_id, meta);
+ALTER TABLE text_data DROP COLUMN IF EXISTS deleted_at;
+
+DELETE FROM card_data WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS card_data_deleted_at;
+DROP INDEX IF EXISTS card_data_user_meta_trash;
+DROP INDEX IF EXISTS card_data_user_meta_live;
+ALTER TABLE card_data ADD CONSTRAINT card_data_user_meta_key UNIQUE (user_id, meta);
+ALTER TABLE card_data DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,24 @@
+ALTER TABLE cred_data ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE cred_data DROP CONSTRAINT IF EXISTS cred_data_user_meta_key;
+CREATE UNIQUE INDEX IF NOT EXISTS cred_data_user_meta_live  ON cred_data (user_id, meta) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS cred_data_user_meta_trash ON cred_data (user_id, meta) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE bin_data ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE bin_data DROP CONSTRAINT IF EXISTS bin_data_user_meta_key;
+CREATE UNIQUE INDEX IF NOT EXISTS bin_data_user_meta_live  ON bin_data (user_id, meta) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS bin_data_user_meta_trash ON bin_data (user_id, meta) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE text_data ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE text_data DROP CONSTRAINT IF EXISTS text_data_user_meta_key;
+CREATE UNIQUE INDEX IF NOT EXISTS text_data_user_meta_live  ON text_data (user_id, meta) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS text_data_user_meta_trash ON text_data (user_id, meta) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE card_data ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE card_data DROP CONSTRAINT IF EXISTS card_data_user_meta_key;
+CREATE UNIQUE INDEX IF NOT EXISTS card_data_user_meta_live  ON card_data (user_id, meta) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS card_data_user_meta_trash ON card_data (user_id, meta) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS cred_data_deleted_at ON cred_data (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS bin_data_deleted_at  ON bin_data (deleted_at)  WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS text_data_deleted_at ON text_data (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS card_data_deleted_at ON card_data (deleted_at) WHERE deleted_at IS NOT NULL;
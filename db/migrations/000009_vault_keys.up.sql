@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS vault_keys (
+    user_id    INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    sealed_key BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS key_rotations (
+    user_id    INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    key_id     VARCHAR     NOT NULL,
+    sealed_key BYTEA,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS users_totp (
+    user_id         INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    sealed_secret   BYTEA   NOT NULL,
+    confirmed       BOOLEAN NOT NULL DEFAULT false,
+    recovery_hashes TEXT[]  NOT NULL DEFAULT '{}',
+    last_step       BIGINT  NOT NULL DEFAULT 0
+);
@@ -0,0 +1,19 @@
+DROP FUNCTION IF EXISTS claim_orphan(TEXT, INTEGER, INTEGER);
+
+ALTER TABLE cred_data ALTER COLUMN user_id DROP NOT NULL;
+INSERT INTO cred_data SELECT * FROM orphan_cred_data;
+DROP TABLE IF EXISTS orphan_cred_data;
+
+ALTER TABLE text_data ALTER COLUMN user_id DROP NOT NULL;
+INSERT INTO text_data SELECT * FROM orphan_text_data;
+DROP TABLE IF EXISTS orphan_text_data;
+
+ALTER TABLE card_data ALTER COLUMN user_id DROP NOT NULL;
+INSERT INTO card_data SELECT * FROM orphan_card_data;
+DROP TABLE IF EXISTS orphan_card_data;
+
+ALTER TABLE bin_data ALTER COLUMN user_id DROP NOT NULL;
+INSERT INTO bin_data SELECT * FROM orphan_bin_data;
+INSERT INTO bin_chunks SELECT * FROM orphan_bin_chunks;
+DROP TABLE IF EXISTS orphan_bin_chunks;
+DROP TABLE IF EXISTS orphan_bin_data;
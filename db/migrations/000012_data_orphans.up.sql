@@ -0,0 +1,57 @@
+-- Записи, созданные до появления владельца (000002), не принадлежат ни одному
+-- пользователю и через API недоступны. Они переносятся без изменений
+-- в таблицы orphan_*, после чего user_id становится NOT NULL.
+-- Владелец записей по данным не определяется: администратор возвращает
+-- запись пользователю функцией claim_orphan, например
+-- SELECT claim_orphan('text_data', 42, (SELECT id FROM users WHERE email = 'user@example.com')).
+-- Записи, которые не возвращены владельцам, клиентам не видны.
+
+CREATE TABLE IF NOT EXISTS orphan_cred_data (LIKE cred_data INCLUDING DEFAULTS);
+INSERT INTO orphan_cred_data SELECT * FROM cred_data WHERE user_id IS NULL;
+DELETE FROM cred_data WHERE user_id IS NULL;
+ALTER TABLE cred_data ALTER COLUMN user_id SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS orphan_text_data (LIKE text_data INCLUDING DEFAULTS);
+INSERT INTO orphan_text_data SELECT * FROM text_data WHERE user_id IS NULL;
+DELETE FROM text_data WHERE user_id IS NULL;
+ALTER TABLE text_data ALTER COLUMN user_id SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS orphan_card_data (LIKE card_data INCLUDING DEFAULTS);
+INSERT INTO orphan_card_data SELECT * FROM card_data WHERE user_id IS NULL;
+DELETE FROM card_data WHERE user_id IS NULL;
+ALTER TABLE card_data ALTER COLUMN user_id SET NOT NULL;
+
+-- Части бинарных данных удаляются каскадно вместе с записью, поэтому переносятся до нее
+CREATE TABLE IF NOT EXISTS orphan_bin_data (LIKE bin_data INCLUDING DEFAULTS);
+CREATE TABLE IF NOT EXISTS orphan_bin_chunks (LIKE bin_chunks INCLUDING DEFAULTS);
+INSERT INTO orphan_bin_data SELECT * FROM bin_data WHERE user_id IS NULL;
+INSERT INTO orphan_bin_chunks SELECT c.* FROM bin_chunks c JOIN orphan_bin_data o ON o.id = c.data_id;
+DELETE FROM bin_data WHERE user_id IS NULL;
+ALTER TABLE bin_data ALTER COLUMN user_id SET NOT NULL;
+
+-- claim_orphan - Возврат записи orphan_id таблицы data_table владельцу owner_id.
+-- Если у владельца уже есть запись с той же метаинформацией, возвращается ошибка
+-- уникального индекса, и запись остается в orphan_*.
+CREATE OR REPLACE FUNCTION claim_orphan(data_table TEXT, orphan_id INTEGER, owner_id INTEGER) RETURNS VOID AS $$
+DECLARE
+    claimed INTEGER;
+BEGIN
+    IF data_table NOT IN ('cred_data', 'text_data', 'card_data', 'bin_data') THEN
+        RAISE EXCEPTION 'unknown data table %', data_table;
+    END IF;
+
+    EXECUTE format('UPDATE %I SET user_id = $2 WHERE id = $1', 'orphan_' || data_table) USING orphan_id, owner_id;
+    EXECUTE format('INSERT INTO %I SELECT * FROM %I WHERE id = $1', data_table, 'orphan_' || data_table) USING orphan_id;
+    GET DIAGNOSTICS claimed = ROW_COUNT;
+    IF claimed = 0 THEN
+        RAISE EXCEPTION 'orphan % % not found', data_table, orphan_id;
+    END IF;
+
+    IF data_table = 'bin_data' THEN
+        INSERT INTO bin_chunks SELECT * FROM orphan_bin_chunks WHERE data_id = orphan_id;
+        DELETE FROM orphan_bin_chunks WHERE data_id = orphan_id;
+    END IF;
+
+    EXECUTE format('DELETE FROM %I WHERE id = $1', 'orphan_' || data_table) USING orphan_id;
+END;
+$$ LANGUAGE plpgsql;
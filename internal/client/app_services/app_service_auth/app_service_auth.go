@@ -2,10 +2,14 @@ package app_service_auth
 
 import (
 	"bufio"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"go.uber.org/zap"
@@ -14,18 +18,41 @@ import (
 	"GophKeeper/internal/client/model/auth_model"
 	"GophKeeper/pkg/errs"
 	"GophKeeper/pkg/secret"
+	"GophKeeper/pkg/token"
 )
 
 type Sender interface {
-	SignIn(auth_model.Credential) (string, error)
+	SignIn(auth_model.Credential) (string, string, error)
 	SignUp(auth_model.Credential) (string, error)
+	VerifyOTP(challenge, code string) (string, error)
+	Refresh(refresh string) (string, error)
+	Logout(token string) error
+	ListSessions(token string) ([]auth_model.SessionInfo, error)
+	RevokeSession(id, token string) error
+	GetVaultKey(token string) ([]byte, error)
+	SetVaultKey(sealed []byte, token string) error
+	Enroll2FA(token string) (auth_model.Enrollment, error)
+	Confirm2FA(code, token string) error
+	Disable2FA(code, token string) error
+	DeleteAccount(password, token string) error
 }
 
+const (
+	// masterAttempts - Количество попыток ввода мастер-пароля.
+	masterAttempts = 3
+	// minMasterLength - Минимальная длина мастер-пароля.
+	minMasterLength = 8
+	// otpAttempts - Количество попыток ввода кода второго фактора.
+	otpAttempts = 3
+)
+
 type AuthOptions func(c *AuthService)
 
 type AuthService struct {
 	logger *zap.Logger
 	salt   string
+	// offline - Разрешена работа без связи с сервером.
+	offline bool
 	Sender
 }
 
@@ -49,7 +76,17 @@ func WithSalt(salt string) AuthOptions {
 	}
 }
 
-func (serv AuthService) Token() (string, error) {
+// WithOffline - Разрешение работы с локальным кэшем, если сервер недоступен.
+func WithOffline(offline bool) AuthOptions {
+	return func(s *AuthService) {
+		s.offline = offline
+	}
+}
+
+// Token - Авторизация или регистрация пользователя.
+// Если сервер недоступен и разрешена работа без связи с ним,
+// возвращается сессия без токена.
+func (serv AuthService) Token() (auth_model.Session, error) {
 
 	stdin := bufio.NewReader(os.Stdin)
 
@@ -73,35 +110,357 @@ func (serv AuthService) Token() (string, error) {
 
 		switch choice {
 		case 0:
-			return ``, errs.ErrCancel
+			return auth_model.Session{}, errs.ErrCancel
 
 		case 1:
-			token, errToken := serv.signIn()
+			cred := serv.readCredential()
+			token, challenge, errToken := serv.Sender.SignIn(cred)
+
+			if errors.Is(errToken, errs.ErrUnavailable) && serv.offline && serv.confirmOffline() {
+				return auth_model.Session{Email: cred.Email}, nil
+			}
+
+			if errToken == nil && len(challenge) != 0 {
+				token, errToken = serv.verifyOTP(challenge)
+			}
+
 			if ok := serv.parseErr(errToken); ok {
-				return token, nil
+				return auth_model.Session{Email: cred.Email, Token: token}, nil
 			}
 
 		case 2:
-			token, errToken := serv.signUp()
+			cred := serv.readCredential()
+			token, errToken := serv.Sender.SignUp(cred)
 			if ok := serv.parseErr(errToken); ok {
-				return token, nil
+				return auth_model.Session{Email: cred.Email, Token: token}, nil
 			}
 		}
 	}
 }
 
-// SignIn - Авторизация пользователя.
-func (serv AuthService) signIn() (string, error) {
+// Resume - Продление сохраненной сессии без ввода пароля.
+// Возвращает новый токен доступа, а без refresh token - сохраненный,
+// если он еще действует. Если сессия истекла или закрыта, возвращается errs.ErrUnauthenticated.
+func (serv AuthService) Resume(access, refresh string) (string, error) {
+
+	if len(refresh) != 0 {
+		return serv.Sender.Refresh(refresh)
+	}
+
+	expiresAt, err := token.ExpiresAt(access)
+	if err != nil || time.Now().After(expiresAt) {
+		return ``, errs.ErrUnauthenticated
+	}
+
+	return access, nil
+}
+
+// verifyOTP - Второй шаг авторизации: ввод кода из приложения-аутентификатора
+// или кода восстановления.
+func (serv AuthService) verifyOTP(challenge string) (string, error) {
+
+	for i := 0; i < otpAttempts; i++ {
+		code := serv.readCode("Код из приложения или код восстановления: ")
+
+		token, err := serv.Sender.VerifyOTP(challenge, code)
+		if !errors.Is(err, errs.ErrInvalidArgument) {
+			return token, err
+		}
+
+		color.Red("\tНеверный код")
+	}
+
+	return ``, errs.ErrUnauthenticated
+}
+
+// TwoFactor - Подключение второго фактора авторизации,
+// а если он уже подключен - отключение.
+func (serv AuthService) TwoFactor(token string) {
+
+	enrollment, err := serv.Sender.Enroll2FA(token)
+	switch {
+	case err == nil:
+		serv.confirm2FA(enrollment, token)
+
+	case errors.Is(err, errs.ErrAlreadyExist):
+		serv.disable2FA(token)
+
+	default:
+		color.New(color.FgRed).Print("\tОшибка: ")
+		serv.printErr(err)
+	}
+}
+
+// confirm2FA - Вывод данных для приложения-аутентификатора
+// и подтверждение подключения кодом из него.
+func (serv AuthService) confirm2FA(enrollment auth_model.Enrollment, token string) {
+
+	fmt.Println("Добавьте ссылку в приложение-аутентификатор:")
+	color.Cyan("  %s", enrollment.URI)
+
+	fmt.Println("Коды восстановления для входа без приложения, каждый действует один раз.")
+	fmt.Println("Сохраните их: повторно они не показываются.")
+	for _, code := range enrollment.RecoveryCodes {
+		color.Cyan("  %s", code)
+	}
+
+	for i := 0; i < otpAttempts; i++ {
+		code := serv.readCode("Код из приложения (пусто - отмена): ")
+		if len(code) == 0 {
+			break
+		}
+
+		err := serv.Sender.Confirm2FA(code, token)
+		switch {
+		case err == nil:
+			color.Green("Второй фактор подключен")
+			return
+
+		case errors.Is(err, errs.ErrInvalidArgument):
+			color.Red("\tНеверный код")
+
+		default:
+			color.New(color.FgRed).Print("\tОшибка: ")
+			serv.printErr(err)
+			return
+		}
+	}
+
+	color.Yellow("Второй фактор не подключен")
+}
+
+// disable2FA - Отключение второго фактора после подтверждения кодом.
+func (serv AuthService) disable2FA(token string) {
+
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Второй фактор подключен. Отключить? [y/n]: ")
+	answer, _ := reader.ReadString('\n')
+	if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+		return
+	}
+
+	err := serv.Sender.Disable2FA(serv.readCode("Код из приложения или код восстановления: "), token)
+	switch {
+	case err == nil:
+		color.Green("Второй фактор отключен")
+
+	case errors.Is(err, errs.ErrInvalidArgument):
+		color.Red("\tНеверный код")
+
+	default:
+		color.New(color.FgRed).Print("\tОшибка: ")
+		serv.printErr(err)
+	}
+}
+
+// readCode - Чтение кода второго фактора.
+func (serv AuthService) readCode(title string) string {
+
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print(title)
+	code, _ := reader.ReadString('\n')
+
+	return strings.TrimSpace(code)
+}
+
+// confirmOffline - Запрос согласия на работу без связи с сервером.
+func (serv AuthService) confirmOffline() bool {
+
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Println()
+	color.Yellow("Сервер недоступен")
+	fmt.Print("Работать с локальными данными? [y/n]: ")
+
+	answer, _ := reader.ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
+
+// VaultKey - Получение ключа шифрования данных пользователя.
+// Ключ хранится на сервере зашифрованным мастер-паролем и расшифровывается
+// только на клиенте. При первом входе ключ создается.
+// Без связи с сервером используется копия cached из локального кэша.
+// Возвращает ключ и его зашифрованную копию для локального кэша.
+func (serv AuthService) VaultKey(session auth_model.Session, cached []byte) (*rsa.PrivateKey, []byte, error) {
+
+	sealed := cached
+
+	if !session.Offline() {
+		remote, err := serv.Sender.GetVaultKey(session.Token)
+		switch {
+		case err == nil:
+			sealed = remote
+
+		case errors.Is(err, errs.ErrNotFound):
+			return serv.newVaultKey(session.Token)
+
+		case errors.Is(err, errs.ErrUnavailable) && len(cached) != 0:
+			// Используется копия из локального кэша
+
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if len(sealed) == 0 {
+		return nil, nil, errs.ErrNotFound
+	}
+
+	for i := 0; i < masterAttempts; i++ {
+		key, err := secret.OpenVaultKey(serv.readMaster("Мастер-пароль: "), sealed)
+		if err == nil {
+			return key, sealed, nil
+		}
+
+		if !errors.Is(err, secret.ErrWrongPassword) {
+			return nil, nil, err
+		}
+
+		color.Red("\tНеверный мастер-пароль")
+	}
+
+	return nil, nil, secret.ErrWrongPassword
+}
+
+// newVaultKey - Создание ключа хранилища и сохранение его на сервере.
+func (serv AuthService) newVaultKey(token string) (*rsa.PrivateKey, []byte, error) {
+
+	color.Yellow("Создание ключа хранилища")
+	fmt.Println("Мастер-пароль шифрует ваши данные и не передается на сервер.")
+	fmt.Println("Если его забыть, данные восстановить нельзя.")
+
+	var master string
+	for {
+		master = serv.readMaster("Новый мастер-пароль: ")
+		if len(master) < minMasterLength {
+			color.Red("\tМастер-пароль должен быть не короче %d символов", minMasterLength)
+			continue
+		}
+
+		if serv.readMaster("Повторите мастер-пароль: ") == master {
+			break
+		}
+
+		color.Red("\tМастер-пароли не совпадают")
+	}
+
+	key, sealed, err := secret.NewVaultKey(master)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = serv.Sender.SetVaultKey(sealed, token); err != nil {
+		return nil, nil, err
+	}
+
+	return key, sealed, nil
+}
+
+// readMaster - Чтение мастер-пароля без отображения на экране.
+func (serv AuthService) readMaster(title string) string {
+
+	fmt.Print(title)
+	pwd, _ := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+
+	return string(pwd)
+}
+
+// Sessions - Просмотр открытых сессий пользователя и закрытие выбранной.
+func (serv AuthService) Sessions(token string) {
+
+	sessions, err := serv.Sender.ListSessions(token)
+	if err != nil {
+		color.New(color.FgRed).Print("\tОшибка: ")
+		serv.printErr(err)
+		return
+	}
+
+	for i, session := range sessions {
+		current := ""
+		if session.Current {
+			current = " (текущая)"
+		}
+
+		color.Cyan("  [%d] открыта: %s, действует до: %s%s", i+1,
+			session.CreatedAt.Local().Format("02.01.2006 15:04:05"),
+			session.ExpiresAt.Local().Format("02.01.2006 15:04:05"),
+			current)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Номер сессии для закрытия (пусто - назад): ")
+	answer, _ := reader.ReadString('\n')
+
+	choice, errChoice := strconv.Atoi(strings.TrimSpace(answer))
+	if errChoice != nil || choice < 1 || choice > len(sessions) {
+		return
+	}
+
+	if sessions[choice-1].Current {
+		color.Yellow("Текущая сессия закрывается при выходе")
+		return
+	}
+
+	err = serv.Sender.RevokeSession(sessions[choice-1].ID, token)
+	switch {
+	case err == nil:
+		color.Green("Сессия закрыта")
+
+	case errors.Is(err, errs.ErrNotFound):
+		color.Yellow("Сессия уже закрыта")
+
+	default:
+		color.New(color.FgRed).Print("\tОшибка: ")
+		serv.printErr(err)
+	}
+}
+
+// DeleteAccount - Удаление учетной записи email со всеми данными на сервере.
+// Удаление подтверждается вводом email и текущего пароля.
+// Возвращает true, если учетная запись удалена.
+func (serv AuthService) DeleteAccount(email, token string) bool {
+
+	reader := bufio.NewReader(os.Stdin)
+
+	color.Red("Учетная запись и все данные будут удалены без возможности восстановления")
+	fmt.Print("Для подтверждения введите email: ")
+	answer, _ := reader.ReadString('\n')
+	if strings.TrimSpace(answer) != strings.TrimSpace(email) {
+		color.Yellow("Удаление отменено")
+		return false
+	}
+
+	fmt.Print("Пароль: ")
+	pwd, _ := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+
+	err := serv.Sender.DeleteAccount(secret.GeneratePasswordHash(string(pwd), serv.salt), token)
+	switch {
+	case err == nil:
+		color.Green("Учетная запись удалена")
+		return true
+
+	case errors.Is(err, errs.ErrInvalidArgument):
+		color.Red("\tНеверный пароль")
 
-	cred := serv.readCredential()
-	return serv.Sender.SignIn(cred)
+	default:
+		color.New(color.FgRed).Print("\tОшибка: ")
+		serv.printErr(err)
+	}
+
+	return false
 }
 
-// SignUp - Регистрация пользователя.
-func (serv AuthService) signUp() (string, error) {
+// Logout - Завершение сессии на сервере.
+func (serv AuthService) Logout(token string) {
 
-	cred := serv.readCredential()
-	return serv.Sender.SignUp(cred)
+	if err := serv.Sender.Logout(token); err != nil && !errors.Is(err, errs.ErrUnavailable) {
+		serv.logger.Error("failed logout", zap.Error(err))
+	}
 }
 
 func (serv AuthService) readCredential() auth_model.Credential {
@@ -127,6 +486,13 @@ func (serv AuthService) parseErr(err error) bool {
 	}
 
 	color.New(color.FgRed).Print("\tОшибка авторизации: ")
+	serv.printErr(err)
+
+	return false
+}
+
+// printErr - Вывод описания ошибки сервиса авторизации.
+func (serv AuthService) printErr(err error) {
 
 	switch {
 
@@ -139,10 +505,17 @@ func (serv AuthService) parseErr(err error) bool {
 	case errors.Is(err, errs.ErrInvalidArgument):
 		fmt.Println("Неверный логин или пароль")
 
+	case errors.Is(err, errs.ErrUnavailable):
+		fmt.Println("Сервер недоступен")
+
+	case errors.Is(err, errs.ErrUnauthenticated):
+		fmt.Println("Вход не подтвержден, повторите авторизацию")
+
+	case errors.Is(err, errs.ErrTooManyAttempts):
+		fmt.Println("Слишком много попыток, повторите позже")
+
 	default:
 		fmt.Println("Внутренняя ошибка сервиса")
 		serv.logger.Error("unknown error", zap.Error(err))
 	}
-
-	return false
 }
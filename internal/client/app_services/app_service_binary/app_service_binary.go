@@ -5,25 +5,40 @@ import (
 	"crypto/rsa"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	"GophKeeper/pkg/secret"
 )
 
 type Sender interface {
-	Create(text binary_model.Binary, token string) error
-	Get(meta string, token string) (binary_model.Binary, error)
-	Delete(meta string, token string) error
-	Change(text binary_model.Binary, token string) error
+	Delete(meta string, version int64, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+	Upload(in binary_model.Upload, r io.Reader, token string) error
+	Download(meta string, w io.Writer, token string) (binary_model.Info, error)
+	ListRevisions(meta string, token string) ([]revision_model.Revision, error)
+	GetRevision(meta string, version int64, token string) (binary_model.Binary, error)
+	Restore(meta string, version, current int64, token string) error
+	ListTrash(token string) ([]trash_model.Item, error)
+	Undelete(meta string, token string) error
+	Purge(meta string, token string) error
 }
 
+// errNoVersion - Сервер не передает версии данных в списке метаинформации.
+var errNoVersion = errors.New("server does not report data versions")
+
 type BinaryOptions func(c *BinaryService)
 
 type BinaryService struct {
@@ -32,15 +47,21 @@ type BinaryService struct {
 	publicKey  *rsa.PublicKey
 	privateKey *rsa.PrivateKey
 	logger     *zap.Logger
+	// plaintext - Разрешена запись данных без шифрования.
+	plaintext bool
 
 	token string
+	// versions - Версии данных, полученных или загруженных в этом сеансе.
+	// Изменение и удаление проверяют, что данные на сервере с тех пор не менялись.
+	versions map[string]int64
 }
 
 // NewService - Создание экземпляра сервиса для текстовых данных.
 func NewService(s Sender, opts ...BinaryOptions) *BinaryService {
 	serv := &BinaryService{
-		logger: zap.L(),
-		Sender: s,
+		logger:   zap.L(),
+		Sender:   s,
+		versions: make(map[string]int64),
 	}
 
 	for _, opt := range opts {
@@ -62,6 +83,13 @@ func WithPrivateKey(key *rsa.PrivateKey) BinaryOptions {
 	}
 }
 
+// WithPlaintext - Разрешение записи данных без шифрования (режим --insecure-plaintext).
+func WithPlaintext(allowed bool) BinaryOptions {
+	return func(serv *BinaryService) {
+		serv.plaintext = allowed
+	}
+}
+
 func (serv BinaryService) ShowMenu() {
 
 	stdin := bufio.NewReader(os.Stdin)
@@ -75,6 +103,9 @@ func (serv BinaryService) ShowMenu() {
 		fmt.Println("[2] Найти")
 		fmt.Println("[3] Удалить")
 		fmt.Println("[4] Изменить")
+		fmt.Println("[5] Показать все")
+		fmt.Println("[6] История версий")
+		fmt.Println("[7] Корзина")
 		fmt.Println("---------------")
 		fmt.Print("-> ")
 
@@ -101,34 +132,73 @@ func (serv BinaryService) ShowMenu() {
 
 		case 4:
 			serv.Change()
+
+		case 5:
+			serv.List()
+
+		case 6:
+			serv.History()
+
+		case 7:
+			serv.Trash()
 		}
 	}
 }
 
+// Create - Сохранение содержимого локального файла.
 func (serv BinaryService) Create() {
+	if ok := serv.upload(false); ok {
+		color.Green("Данные созданы")
+	}
+}
 
-	data := binary_model.Binary{}
+// Get - Сохранение данных в локальный файл с правами 0600.
+// Если путь не указан или указан каталог, используется исходное имя файла.
+func (serv BinaryService) Get() {
 
-	data.MetaInfo = serv.getInput("Метаинформация: ")
-	data.Data = serv.getInputEncode("Данные: ")
+	meta := serv.getInput("Метаинформация: ")
 
-	if len(data.MetaInfo) == 0 {
+	if len(meta) == 0 {
 		color.Red("Метаинформация не может быть пустой")
 		return
 	}
 
-	if len(data.Data) == 0 {
-		color.Red("Данные не могут быть пустыми")
+	serv.download(meta)
+}
+
+// download - Сохранение данных meta в локальный файл.
+func (serv BinaryService) download(meta string) {
+
+	path := serv.getInput("Путь для сохранения (пусто - исходное имя файла): ")
+
+	var info binary_model.Info
+	saved, fileInfo, err := SaveFile(path, meta, func(w io.Writer) error {
+
+		dec := secret.NewDecryptWriter(serv.privateKey, w)
+
+		var errDownload error
+		if info, errDownload = serv.Sender.Download(meta, dec, serv.token); errDownload != nil {
+			return errDownload
+		}
+
+		return dec.Close()
+	})
+
+	if err != nil {
+		serv.parseError(err)
 		return
 	}
 
-	err := serv.Sender.Create(data, serv.token)
-	if ok := serv.parseError(err); ok {
-		color.Green("Данные созданы")
+	serv.versions[meta] = info.Version
+
+	color.Green("Файл сохранен: %s", saved)
+	if fileInfo != nil {
+		color.Cyan("Исходный файл: %s, %d байт, %s", fileInfo.Name, fileInfo.Size, fileInfo.Mode)
 	}
+	showVersion(info.Version, info.UpdatedAt)
 }
 
-func (serv BinaryService) Get() {
+func (serv BinaryService) Delete() {
 
 	meta := serv.getInput("Метаинформация: ")
 
@@ -137,56 +207,291 @@ func (serv BinaryService) Get() {
 		return
 	}
 
-	text, err := serv.Sender.Get(meta, serv.token)
+	if answer := serv.getInput("Данные будут перемещены в корзину. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	version, err := serv.version(meta)
 	if ok := serv.parseError(err); !ok {
 		return
 	}
 
-	dataDec, errDec := secret.Decrypt(serv.privateKey, text.Data)
-	if errDec != nil {
-		serv.logger.Error("failed decrypt data", zap.Error(errDec))
-		color.Red("Упс... Что-то пошло не так")
+	err = serv.Sender.Delete(meta, version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
 		return
 	}
 
-	color.Cyan("Данные: %s", string(dataDec))
+	if ok := serv.parseError(err); ok {
+		delete(serv.versions, meta)
+		color.Green("Данные перемещены в корзину")
+	}
 }
 
-func (serv BinaryService) Delete() {
+// Change - Замена данных содержимым локального файла.
+func (serv BinaryService) Change() {
+	if ok := serv.upload(true); ok {
+		color.Green("Данные успешно изменены")
+	}
+}
+
+// upload - Загрузка локального файла вместе со сведениями о нем.
+// Файл читается и шифруется по частям, целиком в память не загружается.
+// При замене проверяется версия данных, см. version.
+func (serv BinaryService) upload(overwrite bool) bool {
+
+	if !serv.writable() {
+		return false
+	}
 
 	meta := serv.getInput("Метаинформация: ")
 
 	if len(meta) == 0 {
 		color.Red("Метаинформация не может быть пустой")
-		return
+		return false
 	}
 
-	err := serv.Sender.Delete(meta, serv.token)
-	if ok := serv.parseError(err); ok {
-		color.Green("Данные успешно удалены")
+	path := serv.getInput("Путь к файлу: ")
+
+	if len(path) == 0 {
+		color.Red("Путь к файлу не может быть пустым")
+		return false
+	}
+
+	file, err := OpenFile(path)
+	if errors.Is(err, ErrDirectory) {
+		color.Red("Указан каталог, а не файл")
+		return false
+	}
+	if err != nil {
+		color.Red("Не удалось открыть файл: %v", err)
+		return false
 	}
+	defer file.Close()
+
+	data := secret.NewEncryptReader(serv.publicKey, file)
+
+	in := binary_model.Upload{
+		MetaInfo:  meta,
+		Overwrite: overwrite,
+	}
+	if overwrite {
+		if in.Version, err = serv.version(meta); !serv.parseError(err) {
+			return false
+		}
+	}
+
+	err = serv.Sender.Upload(in, data, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return false
+	}
+
+	if ok := serv.parseError(err); !ok {
+		return false
+	}
+
+	// Изменение в очереди кэша еще не отправлено - версия не изменилась
+	if err != nil {
+		return true
+	}
+
+	// Сервер увеличивает версию при каждом изменении
+	serv.versions[meta] = in.Version + 1
+
+	return true
 }
 
-func (serv BinaryService) Change() {
+// version - Версия данных meta, полученных в этом сеансе, иначе текущая версия на сервере.
+func (serv BinaryService) version(meta string) (int64, error) {
+
+	if ver, ok := serv.versions[meta]; ok {
+		return ver, nil
+	}
+
+	return CurrentVersion(serv.Sender, meta, serv.token)
+}
+
+// CurrentVersion - Текущая версия данных meta на сервере.
+// Версия бинарных данных без их загрузки известна только из списка метаинформации:
+// запрашивается первая запись, начинающаяся с meta, - сама meta, если она есть.
+func CurrentVersion(s Sender, meta, token string) (int64, error) {
+
+	list, err := s.List(list_model.Filter{Prefix: meta, Limit: 1}, token)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(list.MetaInfo) == 0 || list.MetaInfo[0] != meta {
+		return 0, errs.ErrNotFound
+	}
+
+	if len(list.Versions) == 0 {
+		return 0, errNoVersion
+	}
+
+	return list.Versions[0], nil
+}
+
+// conflict - Сообщение о том, что данные изменены на другом устройстве,
+// и предложение загрузить актуальные данные.
+func (serv BinaryService) conflict(meta string) {
+
+	color.Red("Данные изменены на другом устройстве, изменения не сохранены")
+
+	if answer := serv.getInput("Загрузить актуальные данные? [y/n]: "); strings.EqualFold(answer, "y") {
+		serv.download(meta)
+	}
+}
+
+// List - Постраничный вывод метаинформации с фильтром по подстроке.
+func (serv BinaryService) List() {
+	filter := list_model.Filter{
+		Contains: serv.getInput("Фильтр (пусто - все): "),
+		Limit:    list_model.PageSize,
+	}
+
+	for {
+		list, err := serv.Sender.List(filter, serv.token)
+		if ok := serv.parseError(err); !ok {
+			return
+		}
+
+		if len(list.MetaInfo) == 0 && len(filter.PageToken) == 0 {
+			color.Yellow("Данные не найдены")
+			return
+		}
+
+		for _, meta := range list.MetaInfo {
+			color.Cyan("  %s", meta)
+		}
+
+		if len(list.NextPageToken) == 0 {
+			return
+		}
+
+		if next := serv.getInput("Enter - следующая страница, 0 - назад: "); next == "0" {
+			return
+		}
+
+		filter.PageToken = list.NextPageToken
+	}
+}
 
-	data := binary_model.Binary{}
+// History - Вывод прежних версий данных, сохранение выбранной версии в файл
+// и ее восстановление.
+func (serv BinaryService) History() {
 
-	data.MetaInfo = serv.getInput("Метаинформация: ")
-	data.Data = serv.getInputEncode("Текст: ")
+	meta := serv.getInput("Метаинформация: ")
 
-	if len(data.MetaInfo) == 0 {
+	if len(meta) == 0 {
 		color.Red("Метаинформация не может быть пустой")
 		return
 	}
 
-	if len(data.Data) == 0 {
-		color.Red("Данные не могут быть пустыми")
+	revs, err := serv.Sender.ListRevisions(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(revs) == 0 {
+		color.Yellow("Прежних версий нет")
+		return
+	}
+
+	for _, rev := range revs {
+		showVersion(rev.Version, rev.UpdatedAt)
+	}
+
+	version, errParse := strconv.ParseInt(serv.getInput("Версия (пусто - назад): "), 10, 64)
+	if errParse != nil {
+		return
+	}
+
+	data, err := serv.Sender.GetRevision(meta, version, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	path := serv.getInput("Путь для сохранения (пусто - исходное имя файла): ")
+
+	saved, _, err := SaveFile(path, meta, func(w io.Writer) error {
+
+		dec := secret.NewDecryptWriter(serv.privateKey, w)
+		if _, errWrite := dec.Write(data.Data); errWrite != nil {
+			return errWrite
+		}
+
+		return dec.Close()
+	})
+
+	if err != nil {
+		serv.parseError(err)
+		return
+	}
+
+	color.Green("Файл сохранен: %s", saved)
+	showVersion(data.Version, data.UpdatedAt)
+
+	if answer := serv.getInput("Восстановить эту версию? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	current, err := serv.version(meta)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	err = serv.Sender.Restore(meta, version, current, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
 		return
 	}
 
-	err := serv.Sender.Change(data, serv.token)
 	if ok := serv.parseError(err); ok {
-		color.Green("Данные успешно изменены")
+		serv.versions[meta] = current + 1
+		color.Green("Версия %d восстановлена", version)
+	}
+}
+
+// Trash - Вывод удаленных данных, восстановление или окончательное удаление выбранных данных.
+func (serv BinaryService) Trash() {
+
+	items, err := serv.Sender.ListTrash(serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(items) == 0 {
+		color.Yellow("Корзина пуста")
+		return
+	}
+
+	for _, item := range items {
+		color.Cyan("  %s, удалено: %s", item.MetaInfo, item.DeletedAt.Local().Format("02.01.2006 15:04:05"))
+	}
+
+	meta := serv.getInput("Метаинформация (пусто - назад): ")
+	if len(meta) == 0 {
+		return
+	}
+
+	switch serv.getInput("[1] Восстановить, [2] Удалить навсегда: ") {
+	case "1":
+		err = serv.Sender.Undelete(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные восстановлены")
+		}
+
+	case "2":
+		if answer := serv.getInput("Данные будут удалены без возможности восстановления. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+			return
+		}
+
+		err = serv.Sender.Purge(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные удалены навсегда")
+		}
 	}
 }
 
@@ -196,6 +501,12 @@ func (serv BinaryService) parseError(err error) bool {
 		return true
 	}
 
+	// Сервер недоступен - изменение сохранено в локальном кэше
+	if errors.Is(err, errs.ErrQueued) {
+		color.Yellow("Сервер недоступен, изменения будут отправлены при подключении")
+		return true
+	}
+
 	color.New(color.FgRed).Print("\tОшибка: ")
 
 	switch {
@@ -209,6 +520,27 @@ func (serv BinaryService) parseError(err error) bool {
 	case errors.Is(err, errs.ErrLargeData):
 		fmt.Println("Размер данных слишком большой")
 
+	case errors.Is(err, errs.ErrChecksum):
+		fmt.Println("Данные повреждены при передаче")
+
+	case errors.Is(err, errs.ErrConflict):
+		fmt.Println("Данные изменены на другом устройстве")
+
+	case errors.Is(err, fs.ErrExist):
+		fmt.Println("Файл уже существует")
+
+	case errors.Is(err, errs.ErrUnavailable):
+		fmt.Println("Сервер недоступен")
+
+	case errors.Is(err, errs.ErrUnauthenticated):
+		fmt.Println("Сессия закрыта, войдите заново")
+
+	case errors.Is(err, errs.ErrPlaintext):
+		fmt.Println("Сервер принимает только зашифрованные данные")
+
+	case errors.Is(err, errNoVersion):
+		fmt.Println("Сервер не сообщает версию данных, обновите сервер")
+
 	default:
 		fmt.Println("Внутренняя ошибка сервиса")
 		serv.logger.Error("unknown error", zap.Error(err))
@@ -229,36 +561,41 @@ func (serv BinaryService) getInput(title string) string {
 	return data
 }
 
-func (serv BinaryService) getInputEncode(title string) []byte {
-
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print(title)
-	data, _ := reader.ReadString('\n')
-	data = strings.Replace(data, "\n", "", -1)
-	data = strings.Replace(data, "\r", "", -1)
+// showVersion - Вывод версии и времени последнего изменения данных.
+// Без связи с сервером время изменения может быть неизвестно.
+func showVersion(version int64, updatedAt time.Time) {
 
-	// Выбрали путь к файлу
-	if _, err := os.Stat(data); err == nil {
-		fileData, errRead := ioutil.ReadFile(data)
-		if errRead != nil {
-			return nil
-		}
-
-		color.Cyan("Выбран файл")
-		data = string(fileData)
-	} else {
-		color.Cyan("Вы ввели данные вручную")
+	if updatedAt.IsZero() {
+		color.Cyan("Версия: %d", version)
+		return
 	}
 
-	encodeData, _ := secret.Encrypt(serv.publicKey, []byte(data))
-	return encodeData
+	color.Cyan("Версия: %d, изменено: %s", version, updatedAt.Local().Format("02.01.2006 15:04:05"))
 }
 
 func (serv *BinaryService) SetToken(token string) {
 	serv.token = token
 }
 
+// SetKey - Ключ шифрования данных, полученный после авторизации.
+func (serv *BinaryService) SetKey(key *rsa.PrivateKey) {
+	serv.privateKey = key
+	serv.publicKey = &key.PublicKey
+}
+
+// writable - Проверка, что данные будут зашифрованы перед отправкой.
+// Без ключа шифрования запись разрешена только в режиме --insecure-plaintext.
+func (serv BinaryService) writable() bool {
+
+	if serv.publicKey != nil || serv.plaintext {
+		return true
+	}
+
+	color.Red("Шифрование отключено, запись данных запрещена")
+	color.Yellow("Для хранения данных без шифрования запустите клиент с флагом --insecure-plaintext")
+	return false
+}
+
 func (serv BinaryService) Name() string {
 	return "Бинарные данные"
 }
@@ -0,0 +1,272 @@
+package app_service_binary
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"GophKeeper/internal/client/model/binary_model"
+)
+
+// fileMagic - Признак заголовка со сведениями о файле в начале данных.
+// Заголовок шифруется вместе с содержимым файла, поэтому сервер его не видит.
+// Формат: fileMagic, JSON binary_model.FileInfo, '\n', содержимое файла.
+const fileMagic = "GK-FILE/1\n"
+
+// maxHeaderSize - Ограничение размера заголовка, чтобы не накапливать
+// в памяти данные без перевода строки.
+const maxHeaderSize = 4096
+
+// fileMode - Права на файлы, создаваемые при сохранении данных.
+const fileMode = 0600
+
+var errBadHeader = errors.New("invalid file header")
+
+// ErrDirectory - Вместо файла указан каталог.
+var ErrDirectory = errors.New("path is a directory")
+
+// OpenFile - Чтение локального файла path вместе с заголовком сведений о нем.
+// Файл читается по частям, целиком в память не загружается.
+func OpenFile(path string) (io.ReadCloser, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		file.Close()
+		return nil, ErrDirectory
+	}
+
+	header, err := encodeFileHeader(binary_model.FileInfo{
+		Name: filepath.Base(path),
+		Size: stat.Size(),
+		Mode: stat.Mode().Perm(),
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return fileReader{
+		Reader: io.MultiReader(bytes.NewReader(header), file),
+		file:   file,
+	}, nil
+}
+
+// fileReader - Заголовок и содержимое открытого файла.
+type fileReader struct {
+	io.Reader
+	file *os.File
+}
+
+func (r fileReader) Close() error {
+	return r.file.Close()
+}
+
+// SaveFile - Сохранение данных meta, которые записывает write, в локальный файл.
+// Если path пустой или каталог, используется исходное имя файла.
+// При ошибке частично записанный файл удаляется.
+// Возвращает путь файла и сведения об исходном файле, если они были сохранены.
+func SaveFile(path, meta string, write func(w io.Writer) error) (string, *binary_model.FileInfo, error) {
+
+	file := newFileWriter(path, meta)
+
+	err := write(file)
+	if err == nil {
+		err = file.Close()
+	}
+
+	if err != nil {
+		file.remove()
+		return ``, nil, err
+	}
+
+	return file.filePath(), file.info, nil
+}
+
+// encodeFileHeader - Формирование заголовка со сведениями о файле.
+func encodeFileHeader(info binary_model.FileInfo) ([]byte, error) {
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(fileMagic), data...)
+	return append(header, '\n'), nil
+}
+
+// fileWriter - Запись расшифрованных данных в файл.
+// Заголовок отделяется от содержимого, файл создается при первой записи
+// содержимого с правами fileMode. Данные, сохраненные без заголовка,
+// записываются целиком.
+type fileWriter struct {
+	// path - Путь, указанный пользователем. Пустой - текущий каталог.
+	path string
+	// meta - Метаинформация данных, имя файла если заголовка нет.
+	meta string
+
+	info   *binary_model.FileInfo
+	head   bytes.Buffer
+	parsed bool
+	file   *os.File
+}
+
+func newFileWriter(path, meta string) *fileWriter {
+	return &fileWriter{
+		path: path,
+		meta: meta,
+	}
+}
+
+func (fw *fileWriter) Write(p []byte) (int, error) {
+
+	if fw.parsed {
+		return fw.writeFile(p, len(p))
+	}
+
+	fw.head.Write(p)
+
+	content, ok, err := fw.parseHeader()
+	if err != nil || !ok {
+		return len(p), err
+	}
+
+	return fw.writeFile(content, len(p))
+}
+
+// parseHeader - Отделение заголовка от содержимого.
+// ok = false - данных для разбора заголовка пока недостаточно.
+func (fw *fileWriter) parseHeader() (content []byte, ok bool, err error) {
+
+	buf := fw.head.Bytes()
+	magic := []byte(fileMagic)
+
+	if len(buf) < len(magic) {
+		if bytes.HasPrefix(magic, buf) {
+			return nil, false, nil
+		}
+		// Данные сохранены без заголовка
+		fw.parsed = true
+		return buf, true, nil
+	}
+
+	if !bytes.HasPrefix(buf, magic) {
+		fw.parsed = true
+		return buf, true, nil
+	}
+
+	end := bytes.IndexByte(buf[len(magic):], '\n')
+	if end < 0 {
+		if len(buf) > maxHeaderSize {
+			return nil, false, errBadHeader
+		}
+		return nil, false, nil
+	}
+
+	var info binary_model.FileInfo
+	if err = json.Unmarshal(buf[len(magic):len(magic)+end], &info); err != nil {
+		return nil, false, errBadHeader
+	}
+
+	fw.info = &info
+	fw.parsed = true
+
+	return buf[len(magic)+end+1:], true, nil
+}
+
+func (fw *fileWriter) writeFile(p []byte, n int) (int, error) {
+
+	if err := fw.open(); err != nil {
+		return 0, err
+	}
+
+	if _, err := fw.file.Write(p); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// open - Создание файла. Существующие файлы не перезаписываются.
+func (fw *fileWriter) open() error {
+
+	if fw.file != nil {
+		return nil
+	}
+
+	file, err := os.OpenFile(fw.filePath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return err
+	}
+
+	fw.file = file
+	return nil
+}
+
+// filePath - Путь сохраняемого файла.
+// Если пользователь указал каталог или ничего не указал,
+// используется исходное имя файла, а без заголовка - метаинформация.
+func (fw *fileWriter) filePath() string {
+
+	name := fw.meta
+	if fw.info != nil && len(fw.info.Name) != 0 {
+		name = fw.info.Name
+	}
+
+	// Имя из данных не должно выводить за пределы каталога
+	name = filepath.Base(name)
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		name = "data.bin"
+	}
+
+	if len(fw.path) == 0 {
+		return name
+	}
+
+	if stat, err := os.Stat(fw.path); err == nil && stat.IsDir() {
+		return filepath.Join(fw.path, name)
+	}
+
+	return fw.path
+}
+
+// Close - Запись оставшихся данных и закрытие файла.
+func (fw *fileWriter) Close() error {
+
+	if !fw.parsed {
+		// Данные короче заголовка - сохранены без него
+		fw.parsed = true
+		if _, err := fw.writeFile(fw.head.Bytes(), 0); err != nil {
+			return err
+		}
+	}
+
+	if err := fw.open(); err != nil {
+		return err
+	}
+
+	return fw.file.Close()
+}
+
+// remove - Удаление частично записанного файла.
+func (fw *fileWriter) remove() {
+
+	if fw.file == nil {
+		return
+	}
+
+	fw.file.Close()
+	os.Remove(fw.file.Name())
+}
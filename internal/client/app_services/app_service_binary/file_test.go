@@ -0,0 +1,271 @@
+package app_service_binary
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/client/model/binary_model"
+)
+
+// header - Заголовок со сведениями о файле name.
+func header(t *testing.T, name string) string {
+	data, err := encodeFileHeader(binary_model.FileInfo{Name: name, Size: 5, Mode: 0640})
+	require.NoError(t, err)
+	return string(data)
+}
+
+// writeBy - Запись data частями по size байт.
+func writeBy(data string, size int) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		for len(data) != 0 {
+			n := size
+			if n > len(data) {
+				n = len(data)
+			}
+			if _, err := w.Write([]byte(data[:n])); err != nil {
+				return err
+			}
+			data = data[n:]
+		}
+		return nil
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+
+	tests := []struct {
+		name string
+		meta string
+		data string
+		// file - Имя сохраненного файла в каталоге теста.
+		file    string
+		content string
+		// source - Имя исходного файла из заголовка, пустое - заголовка нет.
+		source string
+	}{
+		{
+			name:    "header",
+			meta:    "report",
+			data:    header(t, "report.pdf") + "hello",
+			file:    "report.pdf",
+			content: "hello",
+			source:  "report.pdf",
+		},
+		{
+			name:    "header without content",
+			meta:    "empty",
+			data:    header(t, "empty.txt"),
+			file:    "empty.txt",
+			content: "",
+			source:  "empty.txt",
+		},
+		{
+			name:    "no header",
+			meta:    "raw",
+			data:    "plain data without header",
+			file:    "raw",
+			content: "plain data without header",
+		},
+		{
+			name:    "shorter than header prefix",
+			meta:    "short",
+			data:    fileMagic[:3],
+			file:    "short",
+			content: fileMagic[:3],
+		},
+		{
+			name:    "magic without newline",
+			meta:    "magic",
+			data:    strings.TrimSuffix(fileMagic, "\n") + "x",
+			file:    "magic",
+			content: strings.TrimSuffix(fileMagic, "\n") + "x",
+		},
+		{
+			name:    "path in source name",
+			meta:    "hostile",
+			data:    header(t, "../../etc/passwd") + "root",
+			file:    "passwd",
+			content: "root",
+			source:  "../../etc/passwd",
+		},
+		{
+			name:    "parent directory as source name",
+			meta:    "dots",
+			data:    header(t, "..") + "data",
+			file:    "data.bin",
+			content: "data",
+			source:  "..",
+		},
+		{
+			name:    "root as source name",
+			meta:    "root",
+			data:    header(t, "/") + "data",
+			file:    "data.bin",
+			content: "data",
+			source:  "/",
+		},
+		{
+			name:    "path in meta",
+			meta:    "../secret/notes",
+			data:    "text",
+			file:    "notes",
+			content: "text",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, size := range []int{1, 7, len(tt.data) + 1} {
+			t.Run(fmt.Sprintf("%s by %d", tt.name, size), func(t *testing.T) {
+
+				dir := t.TempDir()
+
+				saved, info, err := SaveFile(dir, tt.meta, writeBy(tt.data, size))
+				require.NoError(t, err)
+				require.Equal(t, filepath.Join(dir, tt.file), saved)
+
+				content, err := os.ReadFile(saved)
+				require.NoError(t, err)
+				require.Equal(t, tt.content, string(content))
+
+				if len(tt.source) == 0 {
+					require.Nil(t, info)
+				} else {
+					require.Equal(t, tt.source, info.Name)
+				}
+
+				stat, err := os.Stat(saved)
+				require.NoError(t, err)
+				require.Equal(t, os.FileMode(fileMode), stat.Mode().Perm())
+
+				// Других файлов не создается
+				entries, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+			})
+		}
+	}
+}
+
+func TestSaveFile_Errors(t *testing.T) {
+
+	errWrite := errors.New("connection lost")
+
+	tests := []struct {
+		name  string
+		write func(w io.Writer) error
+		err   error
+	}{
+		{
+			name:  "oversize header",
+			write: writeBy(fileMagic+strings.Repeat("x", maxHeaderSize), 512),
+			err:   errBadHeader,
+		},
+		{
+			name:  "invalid header",
+			write: writeBy(fileMagic+"{not json}\ncontent", 4),
+			err:   errBadHeader,
+		},
+		{
+			name: "failed after header",
+			write: func(w io.Writer) error {
+				if err := writeBy(header(t, "part.bin")+"partial", 3)(w); err != nil {
+					return err
+				}
+				return errWrite
+			},
+			err: errWrite,
+		},
+		{
+			name: "failed without header",
+			write: func(w io.Writer) error {
+				if err := writeBy("partial data", 3)(w); err != nil {
+					return err
+				}
+				return errWrite
+			},
+			err: errWrite,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir := t.TempDir()
+
+			saved, info, err := SaveFile(dir, "part.bin", tt.write)
+			require.ErrorIs(t, err, tt.err)
+			require.Empty(t, saved)
+			require.Nil(t, info)
+
+			// Частично записанный файл удален
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+		})
+	}
+}
+
+func TestSaveFile_Exists(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("original"), 0644))
+
+	// Ни в каталог под исходным именем, ни по явному пути файл не перезаписывается
+	for _, target := range []string{dir, path} {
+		_, _, err := SaveFile(target, "report", writeBy(header(t, "report.pdf")+"new", 4))
+		require.ErrorIs(t, err, fs.ErrExist)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "original", string(content))
+	}
+
+	// Явный путь к новому файлу важнее исходного имени
+	other := filepath.Join(dir, "copy.pdf")
+	saved, _, err := SaveFile(other, "report", writeBy(header(t, "report.pdf")+"new", 4))
+	require.NoError(t, err)
+	require.Equal(t, other, saved)
+}
+
+func TestOpenFile(t *testing.T) {
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(src, []byte("image bytes"), 0640))
+
+	file, err := OpenFile(src)
+	require.NoError(t, err)
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), fileMagic))
+
+	// Данные OpenFile сохраняются под исходным именем
+	out := filepath.Join(dir, "out")
+	require.NoError(t, os.Mkdir(out, 0700))
+
+	saved, info, err := SaveFile(out, "photo", writeBy(string(data), 5))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(out, "photo.jpg"), saved)
+	require.Equal(t, &binary_model.FileInfo{Name: "photo.jpg", Size: 11, Mode: 0640}, info)
+
+	content, err := os.ReadFile(saved)
+	require.NoError(t, err)
+	require.Equal(t, "image bytes", string(content))
+
+	_, err = OpenFile(dir)
+	require.ErrorIs(t, err, ErrDirectory)
+
+	_, err = OpenFile(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -15,6 +15,9 @@ import (
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	"GophKeeper/pkg/secret"
 )
@@ -24,8 +27,15 @@ var PeriodLayout = "01.2006"
 type Sender interface {
 	Create(data card_model.Card, token string) error
 	Get(meta string, token string) (card_model.Card, error)
-	Delete(meta string, token string) error
+	Delete(meta string, version int64, token string) error
 	Change(data card_model.Card, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+	ListRevisions(meta string, token string) ([]revision_model.Revision, error)
+	GetRevision(meta string, version int64, token string) (card_model.Card, error)
+	Restore(meta string, version, current int64, token string) error
+	ListTrash(token string) ([]trash_model.Item, error)
+	Undelete(meta string, token string) error
+	Purge(meta string, token string) error
 }
 
 type CardOptions func(c *CardService)
@@ -36,6 +46,8 @@ type CardService struct {
 	publicKey  *rsa.PublicKey
 	privateKey *rsa.PrivateKey
 	logger     *zap.Logger
+	// plaintext - Разрешена запись данных без шифрования.
+	plaintext bool
 
 	token string
 }
@@ -66,6 +78,13 @@ func WithPrivateKey(key *rsa.PrivateKey) CardOptions {
 	}
 }
 
+// WithPlaintext - Разрешение записи данных без шифрования (режим --insecure-plaintext).
+func WithPlaintext(allowed bool) CardOptions {
+	return func(serv *CardService) {
+		serv.plaintext = allowed
+	}
+}
+
 func (serv CardService) ShowMenu() {
 	stdin := bufio.NewReader(os.Stdin)
 
@@ -78,6 +97,9 @@ func (serv CardService) ShowMenu() {
 		fmt.Println("[2] Найти")
 		fmt.Println("[3] Удалить")
 		fmt.Println("[4] Изменить")
+		fmt.Println("[5] Показать все")
+		fmt.Println("[6] История версий")
+		fmt.Println("[7] Корзина")
 		fmt.Println("---------------")
 		fmt.Print("-> ")
 
@@ -104,11 +126,25 @@ func (serv CardService) ShowMenu() {
 
 		case 4:
 			serv.Change()
+
+		case 5:
+			serv.List()
+
+		case 6:
+			serv.History()
+
+		case 7:
+			serv.Trash()
 		}
 	}
 }
 
 func (serv CardService) Create() {
+
+	if !serv.writable() {
+		return
+	}
+
 	data := card_model.Card{}
 
 	data.MetaInfo = serv.getInput("Метаинформация: ")
@@ -145,11 +181,23 @@ func (serv CardService) Get() {
 		return
 	}
 
+	serv.show(meta)
+}
+
+// show - Вывод расшифрованных данных meta.
+func (serv CardService) show(meta string) {
+
 	data, err := serv.Sender.Get(meta, serv.token)
 	if ok := serv.parseError(err); !ok {
 		return
 	}
 
+	serv.print(data)
+}
+
+// print - Вывод расшифрованных данных.
+func (serv CardService) print(data card_model.Card) {
+
 	number, errDec := secret.Decrypt(serv.privateKey, data.Number)
 	if errDec != nil {
 		serv.logger.Error("failed decrypt data", zap.Error(errDec))
@@ -182,6 +230,7 @@ func (serv CardService) Get() {
 	color.Cyan("Период   : %s", string(period))
 	color.Cyan("CVV      : %s", string(CVV))
 	color.Cyan("Держатель: %s", string(holder))
+	showVersion(data.Version, data.UpdatedAt)
 }
 
 func (serv CardService) Delete() {
@@ -192,26 +241,56 @@ func (serv CardService) Delete() {
 		return
 	}
 
-	err := serv.Sender.Delete(meta, serv.token)
+	// Удаление проверяет, что данные не изменились после получения версии
+	current, err := serv.Sender.Get(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if answer := serv.getInput("Данные будут перемещены в корзину. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	err = serv.Sender.Delete(meta, current.Version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return
+	}
+
 	if ok := serv.parseError(err); ok {
-		color.Green("Данные успешно удалены")
+		color.Green("Данные перемещены в корзину")
 	}
 }
 
 func (serv CardService) Change() {
+
+	if !serv.writable() {
+		return
+	}
+
 	data := card_model.Card{}
 
 	data.MetaInfo = serv.getInput("Метаинформация: ")
-	number := serv.getInput("Номер: ")
-	period := serv.getInput("Период: ")
-	CVV := serv.getInput("CVV: ")
-	holder := serv.getInput("Держатель: ")
 
 	if len(data.MetaInfo) == 0 {
 		color.Red("Метаинформация не может быть пустой")
 		return
 	}
 
+	// Изменение делается на основе текущей версии данных
+	current, err := serv.Sender.Get(data.MetaInfo, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	showVersion(current.Version, current.UpdatedAt)
+	data.Version = current.Version
+
+	number := serv.getInput("Номер: ")
+	period := serv.getInput("Период: ")
+	CVV := serv.getInput("CVV: ")
+	holder := serv.getInput("Держатель: ")
+
 	if ok := serv.checkCardData(number, period, CVV, holder); !ok {
 		return
 	}
@@ -221,17 +300,160 @@ func (serv CardService) Change() {
 	data.CVV = serv.encode(CVV)
 	data.FullName = serv.encode(holder)
 
-	err := serv.Sender.Change(data, serv.token)
+	err = serv.Sender.Change(data, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(data.MetaInfo)
+		return
+	}
+
 	if ok := serv.parseError(err); ok {
 		color.Green("Данные успешно изменены")
 	}
 }
 
+// List - Постраничный вывод метаинформации с фильтром по подстроке.
+func (serv CardService) List() {
+	filter := list_model.Filter{
+		Contains: serv.getInput("Фильтр (пусто - все): "),
+		Limit:    list_model.PageSize,
+	}
+
+	for {
+		list, err := serv.Sender.List(filter, serv.token)
+		if ok := serv.parseError(err); !ok {
+			return
+		}
+
+		if len(list.MetaInfo) == 0 && len(filter.PageToken) == 0 {
+			color.Yellow("Данные не найдены")
+			return
+		}
+
+		for _, meta := range list.MetaInfo {
+			color.Cyan("  %s", meta)
+		}
+
+		if len(list.NextPageToken) == 0 {
+			return
+		}
+
+		if next := serv.getInput("Enter - следующая страница, 0 - назад: "); next == "0" {
+			return
+		}
+
+		filter.PageToken = list.NextPageToken
+	}
+}
+
+// History - Вывод прежних версий данных и восстановление выбранной версии.
+func (serv CardService) History() {
+
+	meta := serv.getInput("Метаинформация: ")
+
+	if len(meta) == 0 {
+		color.Red("Метаинформация не может быть пустой")
+		return
+	}
+
+	revs, err := serv.Sender.ListRevisions(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(revs) == 0 {
+		color.Yellow("Прежних версий нет")
+		return
+	}
+
+	for _, rev := range revs {
+		showVersion(rev.Version, rev.UpdatedAt)
+	}
+
+	version, errParse := strconv.ParseInt(serv.getInput("Версия (пусто - назад): "), 10, 64)
+	if errParse != nil {
+		return
+	}
+
+	data, err := serv.Sender.GetRevision(meta, version, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	serv.print(data)
+
+	if answer := serv.getInput("Восстановить эту версию? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	// Восстановление делается на основе текущей версии данных
+	current, err := serv.Sender.Get(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	err = serv.Sender.Restore(meta, version, current.Version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return
+	}
+
+	if ok := serv.parseError(err); ok {
+		color.Green("Версия %d восстановлена", version)
+	}
+}
+
+// Trash - Вывод удаленных данных, восстановление или окончательное удаление выбранных данных.
+func (serv CardService) Trash() {
+
+	items, err := serv.Sender.ListTrash(serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(items) == 0 {
+		color.Yellow("Корзина пуста")
+		return
+	}
+
+	for _, item := range items {
+		color.Cyan("  %s, удалено: %s", item.MetaInfo, item.DeletedAt.Local().Format("02.01.2006 15:04:05"))
+	}
+
+	meta := serv.getInput("Метаинформация (пусто - назад): ")
+	if len(meta) == 0 {
+		return
+	}
+
+	switch serv.getInput("[1] Восстановить, [2] Удалить навсегда: ") {
+	case "1":
+		err = serv.Sender.Undelete(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные восстановлены")
+		}
+
+	case "2":
+		if answer := serv.getInput("Данные будут удалены без возможности восстановления. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+			return
+		}
+
+		err = serv.Sender.Purge(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные удалены навсегда")
+		}
+	}
+}
+
 func (serv CardService) parseError(err error) bool {
 	if err == nil {
 		return true
 	}
 
+	// Сервер недоступен - изменение сохранено в локальном кэше
+	if errors.Is(err, errs.ErrQueued) {
+		color.Yellow("Сервер недоступен, изменения будут отправлены при подключении")
+		return true
+	}
+
 	color.New(color.FgRed).Print("\tОшибка: ")
 
 	switch {
@@ -242,9 +464,21 @@ func (serv CardService) parseError(err error) bool {
 	case errors.Is(err, errs.ErrNotFound):
 		fmt.Println("Такая метаинформация не найдена")
 
+	case errors.Is(err, errs.ErrConflict):
+		fmt.Println("Данные изменены на другом устройстве")
+
 	case errors.Is(err, errs.ErrLargeData):
 		fmt.Println("Размер данных слишком большой")
 
+	case errors.Is(err, errs.ErrUnavailable):
+		fmt.Println("Сервер недоступен")
+
+	case errors.Is(err, errs.ErrUnauthenticated):
+		fmt.Println("Сессия закрыта, войдите заново")
+
+	case errors.Is(err, errs.ErrPlaintext):
+		fmt.Println("Сервер принимает только зашифрованные данные")
+
 	default:
 		fmt.Println("Внутренняя ошибка сервиса")
 		serv.logger.Error("unknown error", zap.Error(err))
@@ -272,44 +506,91 @@ func (serv CardService) encode(data string) []byte {
 
 func (serv CardService) checkCardData(number, period, cvv, holder string) bool {
 
-	if _, errTime := time.Parse(PeriodLayout, period); errTime != nil {
-		color.Red("Некорректный период")
+	if err := CheckCard(number, period, cvv, holder); err != nil {
+		color.Red(err.Error())
 		return false
 	}
 
+	return true
+}
+
+// CheckCard - Проверка данных карты перед сохранением.
+// Ошибка содержит описание неверного поля для пользователя.
+func CheckCard(number, period, cvv, holder string) error {
+
+	if _, errTime := time.Parse(PeriodLayout, period); errTime != nil {
+		return errors.New("Некорректный период")
+	}
+
 	if len(number) != 16 {
-		color.Red("Некорректный номер карты")
-		return false
+		return errors.New("Некорректный номер карты")
 	}
 
 	if ok, err := luhn.IsValid(number); !ok || err != nil {
-		color.Red("Некорректный номер карты")
-		return false
+		return errors.New("Некорректный номер карты")
 	}
 
 	if len(cvv) != 3 {
-		color.Red("Некорректный CVV")
-		return false
+		return errors.New("Некорректный CVV")
 	}
 
 	// Используется ParseUint - т.к. не должно быть отрицательного CVV. Например, "-12".
 	if _, err := strconv.ParseUint(cvv, 10, 32); err != nil {
-		color.Red("Некорректный CVV")
-		return false
+		return errors.New("Некорректный CVV")
 	}
 
 	if len(holder) < 4 {
-		color.Red("Некорректные данные держателя")
-		return false
+		return errors.New("Некорректные данные держателя")
 	}
 
-	return true
+	return nil
+}
+
+// conflict - Сообщение о том, что данные изменены на другом устройстве,
+// и предложение загрузить актуальные данные.
+func (serv CardService) conflict(meta string) {
+
+	color.Red("Данные изменены на другом устройстве, изменения не сохранены")
+
+	if answer := serv.getInput("Загрузить актуальные данные? [y/n]: "); strings.EqualFold(answer, "y") {
+		serv.show(meta)
+	}
+}
+
+// showVersion - Вывод версии и времени последнего изменения данных.
+func showVersion(version int64, updatedAt time.Time) {
+
+	if updatedAt.IsZero() {
+		color.Cyan("Версия: %d", version)
+		return
+	}
+
+	color.Cyan("Версия: %d, изменено: %s", version, updatedAt.Local().Format("02.01.2006 15:04:05"))
 }
 
 func (serv *CardService) SetToken(token string) {
 	serv.token = token
 }
 
+// SetKey - Ключ шифрования данных, полученный после авторизации.
+func (serv *CardService) SetKey(key *rsa.PrivateKey) {
+	serv.privateKey = key
+	serv.publicKey = &key.PublicKey
+}
+
+// writable - Проверка, что данные будут зашифрованы перед отправкой.
+// Без ключа шифрования запись разрешена только в режиме --insecure-plaintext.
+func (serv CardService) writable() bool {
+
+	if serv.publicKey != nil || serv.plaintext {
+		return true
+	}
+
+	color.Red("Шифрование отключено, запись данных запрещена")
+	color.Yellow("Для хранения данных без шифрования запустите клиент с флагом --insecure-plaintext")
+	return false
+}
+
 func (serv CardService) Name() string {
 	return "Банковские карты"
 }
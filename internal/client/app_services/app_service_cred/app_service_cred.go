@@ -6,12 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	"GophKeeper/pkg/secret"
 )
@@ -19,8 +24,15 @@ import (
 type Sender interface {
 	Create(data cred_model.Credential, token string) error
 	Get(meta string, token string) (cred_model.Credential, error)
-	Delete(meta string, token string) error
+	Delete(meta string, version int64, token string) error
 	Change(data cred_model.Credential, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+	ListRevisions(meta string, token string) ([]revision_model.Revision, error)
+	GetRevision(meta string, version int64, token string) (cred_model.Credential, error)
+	Restore(meta string, version, current int64, token string) error
+	ListTrash(token string) ([]trash_model.Item, error)
+	Undelete(meta string, token string) error
+	Purge(meta string, token string) error
 }
 
 type CredOptions func(c *CredService)
@@ -31,6 +43,8 @@ type CredService struct {
 	publicKey  *rsa.PublicKey
 	privateKey *rsa.PrivateKey
 	logger     *zap.Logger
+	// plaintext - Разрешена запись данных без шифрования.
+	plaintext bool
 
 	token string
 }
@@ -61,6 +75,13 @@ func WithPrivateKey(key *rsa.PrivateKey) CredOptions {
 	}
 }
 
+// WithPlaintext - Разрешение записи данных без шифрования (режим --insecure-plaintext).
+func WithPlaintext(allowed bool) CredOptions {
+	return func(serv *CredService) {
+		serv.plaintext = allowed
+	}
+}
+
 func (serv CredService) ShowMenu() {
 	stdin := bufio.NewReader(os.Stdin)
 
@@ -73,6 +94,9 @@ func (serv CredService) ShowMenu() {
 		fmt.Println("[2] Найти")
 		fmt.Println("[3] Удалить")
 		fmt.Println("[4] Изменить")
+		fmt.Println("[5] Показать все")
+		fmt.Println("[6] История версий")
+		fmt.Println("[7] Корзина")
 		fmt.Println("---------------")
 		fmt.Print("-> ")
 
@@ -99,16 +123,30 @@ func (serv CredService) ShowMenu() {
 
 		case 4:
 			serv.Change()
+
+		case 5:
+			serv.List()
+
+		case 6:
+			serv.History()
+
+		case 7:
+			serv.Trash()
 		}
 	}
 }
 
 func (serv CredService) Create() {
+
+	if !serv.writable() {
+		return
+	}
+
 	data := cred_model.Credential{}
 
 	data.MetaInfo = serv.getInput("Метаинформация: ")
 	data.Login = serv.getInputEncode("Логин: ")
-	data.Password = serv.getInputEncode("Пароль: ")
+	data.Password = serv.getPassword()
 
 	if len(data.MetaInfo) == 0 {
 		color.Red("Метаинформация не может быть пустой")
@@ -139,11 +177,23 @@ func (serv CredService) Get() {
 		return
 	}
 
+	serv.show(meta)
+}
+
+// show - Вывод расшифрованных данных meta.
+func (serv CredService) show(meta string) {
+
 	data, err := serv.Sender.Get(meta, serv.token)
 	if ok := serv.parseError(err); !ok {
 		return
 	}
 
+	serv.print(data)
+}
+
+// print - Вывод расшифрованных данных.
+func (serv CredService) print(data cred_model.Credential) {
+
 	loginDec, errDec := secret.Decrypt(serv.privateKey, data.Login)
 	if errDec != nil {
 		serv.logger.Error("failed decrypt data", zap.Error(errDec))
@@ -160,6 +210,7 @@ func (serv CredService) Get() {
 
 	color.Cyan("Логин : %s", string(loginDec))
 	color.Cyan("Пароль: %s", string(passwordDec))
+	showVersion(data.Version, data.UpdatedAt)
 }
 
 func (serv CredService) Delete() {
@@ -170,24 +221,54 @@ func (serv CredService) Delete() {
 		return
 	}
 
-	err := serv.Sender.Delete(meta, serv.token)
+	// Удаление проверяет, что данные не изменились после получения версии
+	current, err := serv.Sender.Get(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if answer := serv.getInput("Данные будут перемещены в корзину. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	err = serv.Sender.Delete(meta, current.Version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return
+	}
+
 	if ok := serv.parseError(err); ok {
-		color.Green("Данные успешно удалены")
+		color.Green("Данные перемещены в корзину")
 	}
 }
 
 func (serv CredService) Change() {
+
+	if !serv.writable() {
+		return
+	}
+
 	data := cred_model.Credential{}
 
 	data.MetaInfo = serv.getInput("Метаинформация: ")
-	data.Login = serv.getInputEncode("Логин: ")
-	data.Password = serv.getInputEncode("Пароль: ")
 
 	if len(data.MetaInfo) == 0 {
 		color.Red("Метаинформация не может быть пустой")
 		return
 	}
 
+	// Изменение делается на основе текущей версии данных
+	current, err := serv.Sender.Get(data.MetaInfo, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	showVersion(current.Version, current.UpdatedAt)
+	data.Version = current.Version
+
+	data.Login = serv.getInputEncode("Логин: ")
+	data.Password = serv.getPassword()
+
 	if len(data.Login) == 0 {
 		color.Red("Логин не может быть пустым")
 		return
@@ -198,17 +279,160 @@ func (serv CredService) Change() {
 		return
 	}
 
-	err := serv.Sender.Change(data, serv.token)
+	err = serv.Sender.Change(data, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(data.MetaInfo)
+		return
+	}
+
 	if ok := serv.parseError(err); ok {
 		color.Green("Данные успешно изменены")
 	}
 }
 
+// List - Постраничный вывод метаинформации с фильтром по подстроке.
+func (serv CredService) List() {
+	filter := list_model.Filter{
+		Contains: serv.getInput("Фильтр (пусто - все): "),
+		Limit:    list_model.PageSize,
+	}
+
+	for {
+		list, err := serv.Sender.List(filter, serv.token)
+		if ok := serv.parseError(err); !ok {
+			return
+		}
+
+		if len(list.MetaInfo) == 0 && len(filter.PageToken) == 0 {
+			color.Yellow("Данные не найдены")
+			return
+		}
+
+		for _, meta := range list.MetaInfo {
+			color.Cyan("  %s", meta)
+		}
+
+		if len(list.NextPageToken) == 0 {
+			return
+		}
+
+		if next := serv.getInput("Enter - следующая страница, 0 - назад: "); next == "0" {
+			return
+		}
+
+		filter.PageToken = list.NextPageToken
+	}
+}
+
+// History - Вывод прежних версий данных и восстановление выбранной версии.
+func (serv CredService) History() {
+
+	meta := serv.getInput("Метаинформация: ")
+
+	if len(meta) == 0 {
+		color.Red("Метаинформация не может быть пустой")
+		return
+	}
+
+	revs, err := serv.Sender.ListRevisions(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(revs) == 0 {
+		color.Yellow("Прежних версий нет")
+		return
+	}
+
+	for _, rev := range revs {
+		showVersion(rev.Version, rev.UpdatedAt)
+	}
+
+	version, errParse := strconv.ParseInt(serv.getInput("Версия (пусто - назад): "), 10, 64)
+	if errParse != nil {
+		return
+	}
+
+	data, err := serv.Sender.GetRevision(meta, version, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	serv.print(data)
+
+	if answer := serv.getInput("Восстановить эту версию? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	// Восстановление делается на основе текущей версии данных
+	current, err := serv.Sender.Get(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	err = serv.Sender.Restore(meta, version, current.Version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return
+	}
+
+	if ok := serv.parseError(err); ok {
+		color.Green("Версия %d восстановлена", version)
+	}
+}
+
+// Trash - Вывод удаленных данных, восстановление или окончательное удаление выбранных данных.
+func (serv CredService) Trash() {
+
+	items, err := serv.Sender.ListTrash(serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(items) == 0 {
+		color.Yellow("Корзина пуста")
+		return
+	}
+
+	for _, item := range items {
+		color.Cyan("  %s, удалено: %s", item.MetaInfo, item.DeletedAt.Local().Format("02.01.2006 15:04:05"))
+	}
+
+	meta := serv.getInput("Метаинформация (пусто - назад): ")
+	if len(meta) == 0 {
+		return
+	}
+
+	switch serv.getInput("[1] Восстановить, [2] Удалить навсегда: ") {
+	case "1":
+		err = serv.Sender.Undelete(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные восстановлены")
+		}
+
+	case "2":
+		if answer := serv.getInput("Данные будут удалены без возможности восстановления. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+			return
+		}
+
+		err = serv.Sender.Purge(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные удалены навсегда")
+		}
+	}
+}
+
 func (serv CredService) parseError(err error) bool {
 	if err == nil {
 		return true
 	}
 
+	// Сервер недоступен - изменение сохранено в локальном кэше
+	if errors.Is(err, errs.ErrQueued) {
+		color.Yellow("Сервер недоступен, изменения будут отправлены при подключении")
+		return true
+	}
+
 	color.New(color.FgRed).Print("\tОшибка: ")
 
 	switch {
@@ -219,9 +443,21 @@ func (serv CredService) parseError(err error) bool {
 	case errors.Is(err, errs.ErrNotFound):
 		fmt.Println("Такая метаинформация не найдена")
 
+	case errors.Is(err, errs.ErrConflict):
+		fmt.Println("Данные изменены на другом устройстве")
+
 	case errors.Is(err, errs.ErrLargeData):
 		fmt.Println("Размер данных слишком большой")
 
+	case errors.Is(err, errs.ErrUnavailable):
+		fmt.Println("Сервер недоступен")
+
+	case errors.Is(err, errs.ErrUnauthenticated):
+		fmt.Println("Сессия закрыта, войдите заново")
+
+	case errors.Is(err, errs.ErrPlaintext):
+		fmt.Println("Сервер принимает только зашифрованные данные")
+
 	default:
 		fmt.Println("Внутренняя ошибка сервиса")
 		serv.logger.Error("unknown error", zap.Error(err))
@@ -253,10 +489,133 @@ func (serv CredService) getInputEncode(title string) []byte {
 	return encodeData
 }
 
+// getPassword - Ввод пароля. Если пароль не введен, его можно сгенерировать.
+func (serv CredService) getPassword() []byte {
+
+	password := serv.getInput("Пароль (пусто - сгенерировать): ")
+	if len(password) == 0 {
+		password = serv.generate()
+	}
+
+	if len(password) == 0 {
+		return nil
+	}
+
+	encodeData, _ := secret.Encrypt(serv.publicKey, []byte(password))
+	return encodeData
+}
+
+// generate - Генерация пароля или парольной фразы с оценкой энтропии.
+// Пустая строка - пользователь отказался от сгенерированного пароля.
+func (serv CredService) generate() string {
+
+	fmt.Println("[1] Сгенерировать пароль")
+	fmt.Println("[2] Сгенерировать парольную фразу")
+
+	var gen secret.Generated
+	var err error
+
+	switch serv.getInput("-> ") {
+	case "1":
+		policy := secret.DefaultPassword
+		policy.Length = serv.getNumber(fmt.Sprintf("Длина (%d): ", policy.Length), policy.Length)
+		policy.Symbols = !strings.EqualFold(serv.getInput("Спецсимволы [Y/n]: "), "n")
+		policy.ExcludeAmbiguous = !strings.EqualFold(serv.getInput("Исключить похожие символы 0/O, 1/l/I [Y/n]: "), "n")
+
+		gen, err = secret.GeneratePassword(policy)
+
+	case "2":
+		policy := secret.DefaultPassphrase
+		policy.Words = serv.getNumber(fmt.Sprintf("Количество слов (%d): ", policy.Words), policy.Words)
+
+		gen, err = secret.GeneratePassphrase(policy)
+
+	default:
+		return ``
+	}
+
+	if errors.Is(err, secret.ErrPasswordPolicy) {
+		color.Red("Неверные параметры пароля: длина от %d до %d символов, от %d до %d слов",
+			secret.MinPasswordLength, secret.MaxPasswordLength, secret.MinPassphraseWords, secret.MaxPassphraseWords)
+		return ``
+	}
+	if err != nil {
+		serv.logger.Error("failed generate password", zap.Error(err))
+		color.Red("Упс... Что-то пошло не так")
+		return ``
+	}
+
+	color.Cyan("Пароль: %s", gen.Value)
+	color.Cyan("Энтропия: %.0f бит", gen.Entropy)
+
+	if answer := serv.getInput("Использовать этот пароль? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return ``
+	}
+
+	return gen.Value
+}
+
+// getNumber - Ввод числа, пустой ввод - значение def.
+func (serv CredService) getNumber(title string, def int) int {
+
+	input := serv.getInput(title)
+	if len(input) == 0 {
+		return def
+	}
+
+	n, err := strconv.Atoi(input)
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// conflict - Сообщение о том, что данные изменены на другом устройстве,
+// и предложение загрузить актуальные данные.
+func (serv CredService) conflict(meta string) {
+
+	color.Red("Данные изменены на другом устройстве, изменения не сохранены")
+
+	if answer := serv.getInput("Загрузить актуальные данные? [y/n]: "); strings.EqualFold(answer, "y") {
+		serv.show(meta)
+	}
+}
+
+// showVersion - Вывод версии и времени последнего изменения данных.
+func showVersion(version int64, updatedAt time.Time) {
+
+	if updatedAt.IsZero() {
+		color.Cyan("Версия: %d", version)
+		return
+	}
+
+	color.Cyan("Версия: %d, изменено: %s", version, updatedAt.Local().Format("02.01.2006 15:04:05"))
+}
+
 func (serv *CredService) SetToken(token string) {
 	serv.token = token
 }
 
+// SetKey - Ключ шифрования данных, полученный после авторизации.
+func (serv *CredService) SetKey(key *rsa.PrivateKey) {
+	serv.privateKey = key
+	serv.publicKey = &key.PublicKey
+}
+
+// writable - Проверка, что данные будут зашифрованы перед отправкой.
+// Без ключа шифрования запись разрешена только в режиме --insecure-plaintext.
+func (serv CredService) writable() bool {
+
+	if serv.publicKey != nil || serv.plaintext {
+		return true
+	}
+
+	color.Red("Шифрование отключено, запись данных запрещена")
+	color.Yellow("Для хранения данных без шифрования запустите клиент с флагом --insecure-plaintext")
+	return false
+}
+
 func (serv CredService) Name() string {
 	return "Логины и пароли"
 }
@@ -0,0 +1,741 @@
+package app_service_rotation
+
+import (
+	"bufio"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/rotation_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+// batchSize - Количество записей в одном пакетном изменении на сервере.
+const batchSize = 50
+
+// binaryBatch - Наибольший суммарный размер бинарных данных в одном пакете.
+// Данные большего размера записываются потоком по одной записи.
+const binaryBatch = 4 * 1024 * 1024
+
+// attempts - Количество попыток ввода мастер-пароля или парольной фразы.
+const attempts = 3
+
+type Sender interface {
+	Begin(in rotation_model.Rotation, token string) (rotation_model.Rotation, error)
+	Get(token string) (rotation_model.Rotation, error)
+	UpdateTexts(keyID string, in []text_model.Text, token string) error
+	UpdateCreds(keyID string, in []cred_model.Credential, token string) error
+	UpdateCards(keyID string, in []card_model.Card, token string) error
+	UpdateBinaries(keyID string, in []binary_model.Binary, token string) error
+	UpdateBinary(keyID string, in binary_model.Upload, r io.Reader, token string) error
+	Finish(keyID string, token string) error
+}
+
+// Lister - Получение страницы списка метаинформации данных одного типа.
+type Lister interface {
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+}
+
+type TextSource interface {
+	Lister
+	Get(meta string, token string) (text_model.Text, error)
+}
+
+type CredSource interface {
+	Lister
+	Get(meta string, token string) (cred_model.Credential, error)
+}
+
+type CardSource interface {
+	Lister
+	Get(meta string, token string) (card_model.Card, error)
+}
+
+type BinarySource interface {
+	Lister
+	Download(meta string, w io.Writer, token string) (binary_model.Info, error)
+}
+
+// VaultKeySource - Получение ключа хранилища, зашифрованного мастер-паролем.
+type VaultKeySource interface {
+	GetVaultKey(token string) ([]byte, error)
+}
+
+type RotationOptions func(c *RotationService)
+
+// RotationService - Смена ключа шифрования всех данных пользователя.
+//
+// Записи читаются с сервера, расшифровываются прежним ключом, шифруются
+// новым и записываются пакетами, каждый пакет - все или ни одной записи.
+// Смена ключа хранится на сервере до завершения, поэтому прерванная смена
+// продолжается: уже перешифрованные записи пропускаются.
+type RotationService struct {
+	Sender
+
+	texts    TextSource
+	creds    CredSource
+	cards    CardSource
+	binaries BinarySource
+	// vaultKeys - Ключ шифрования данных - ключ хранилища, открываемый
+	// мастер-паролем. Если не задан, новый ключ читается из файла.
+	vaultKeys VaultKeySource
+	logger    *zap.Logger
+}
+
+// NewService - Создание экземпляра сервиса смены ключа шифрования.
+// Данные читаются и записываются напрямую на сервере, минуя локальный кэш.
+func NewService(s Sender, texts TextSource, creds CredSource, cards CardSource, binaries BinarySource,
+	opts ...RotationOptions) *RotationService {
+
+	serv := &RotationService{
+		Sender:   s,
+		texts:    texts,
+		creds:    creds,
+		cards:    cards,
+		binaries: binaries,
+		logger:   zap.L(),
+	}
+
+	for _, opt := range opts {
+		opt(serv)
+	}
+
+	return serv
+}
+
+// WithVaultKeys - Данные шифруются ключом хранилища, новый ключ
+// создается и шифруется тем же мастер-паролем.
+func WithVaultKeys(keys VaultKeySource) RotationOptions {
+	return func(serv *RotationService) {
+		serv.vaultKeys = keys
+	}
+}
+
+// Pending - Незавершенная смена ключа на сервере.
+func (serv RotationService) Pending(token string) (rotation_model.Rotation, bool) {
+
+	current, err := serv.Sender.Get(token)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) && !errors.Is(err, errs.ErrUnavailable) {
+			serv.logger.Error("failed get key rotation", zap.Error(err))
+		}
+		return rotation_model.Rotation{}, false
+	}
+
+	return current, true
+}
+
+// Rotate - Смена ключа oldKey на новый с перешифровкой всех данных.
+// Возвращает новый ключ и, если данные шифруются ключом хранилища,
+// его копию, зашифрованную мастер-паролем.
+func (serv RotationService) Rotate(oldKey *rsa.PrivateKey, token string) (*rsa.PrivateKey, []byte, bool) {
+
+	newKey, current, ok := serv.begin(oldKey, token)
+	if !ok {
+		return nil, nil, false
+	}
+
+	err := serv.rotateAll(oldKey, newKey, current.KeyID, token)
+	if err == nil {
+		err = serv.Sender.Finish(current.KeyID, token)
+	}
+
+	if err != nil {
+		serv.printErr(err)
+		color.Yellow("Смена ключа не завершена, ее можно продолжить позже")
+		return nil, nil, false
+	}
+
+	color.Green("Ключ шифрования изменен")
+	if serv.vaultKeys == nil {
+		color.Yellow("Запускайте клиент с новым ключом (-pbk, -prk), прежний ключ больше не нужен")
+	}
+
+	return newKey, current.SealedKey, true
+}
+
+// begin - Начало новой смены ключа или продолжение незавершенной.
+func (serv RotationService) begin(oldKey *rsa.PrivateKey, token string) (*rsa.PrivateKey, rotation_model.Rotation, bool) {
+
+	current, err := serv.Sender.Get(token)
+	switch {
+	case err == nil:
+		color.Yellow("Продолжение смены ключа, начатой %s", current.StartedAt.Local().Format("02.01.2006 15:04:05"))
+
+		newKey, errKey := serv.resumeKey(current)
+		if errKey != nil {
+			serv.printErr(errKey)
+			return nil, rotation_model.Rotation{}, false
+		}
+
+		return newKey, current, true
+
+	case errors.Is(err, errs.ErrNotFound):
+		break
+
+	default:
+		serv.printErr(err)
+		return nil, rotation_model.Rotation{}, false
+	}
+
+	fmt.Println("Все данные будут перешифрованы новым ключом.")
+	fmt.Println("История версий и корзина остаются зашифрованными прежним ключом.")
+	if answer := serv.getInput("Продолжить? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return nil, rotation_model.Rotation{}, false
+	}
+
+	newKey, sealed, err := serv.newKey(oldKey, token)
+	if err != nil {
+		serv.printErr(err)
+		return nil, rotation_model.Rotation{}, false
+	}
+
+	in := rotation_model.Rotation{KeyID: keyID(newKey), SealedKey: sealed}
+	current, err = serv.Sender.Begin(in, token)
+	if err != nil {
+		serv.printErr(err)
+		return nil, rotation_model.Rotation{}, false
+	}
+
+	return newKey, current, true
+}
+
+// newKey - Новый ключ шифрования: ключ хранилища или ключ из файла.
+func (serv RotationService) newKey(oldKey *rsa.PrivateKey, token string) (*rsa.PrivateKey, []byte, error) {
+
+	if serv.vaultKeys != nil {
+
+		sealed, err := serv.vaultKeys.GetVaultKey(token)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Новый ключ шифруется тем же мастер-паролем, поэтому он проверяется
+		master, _, err := serv.readMaster(sealed)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return secret.NewVaultKey(master)
+	}
+
+	newKey, err := serv.readKeyFile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if newKey.PublicKey.Equal(&oldKey.PublicKey) {
+		return nil, nil, errSameKey
+	}
+
+	return newKey, nil, nil
+}
+
+// resumeKey - Ключ незавершенной смены ключа current.
+func (serv RotationService) resumeKey(current rotation_model.Rotation) (*rsa.PrivateKey, error) {
+
+	if serv.vaultKeys != nil {
+
+		if len(current.SealedKey) == 0 {
+			return nil, errOtherKey
+		}
+
+		_, newKey, err := serv.readMaster(current.SealedKey)
+		return newKey, err
+	}
+
+	newKey, err := serv.readKeyFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if keyID(newKey) != current.KeyID {
+		return nil, errOtherKey
+	}
+
+	return newKey, nil
+}
+
+var (
+	// errSameKey - Новый ключ совпадает с текущим.
+	errSameKey = errors.New("new key equals current key")
+	// errOtherKey - Ключ не совпадает с ключом незавершенной смены.
+	errOtherKey = errors.New("key does not match started rotation")
+)
+
+// rotateAll - Перешифровка данных всех типов.
+func (serv RotationService) rotateAll(oldKey, newKey *rsa.PrivateKey, keyID string, token string) error {
+
+	newPub := &newKey.PublicKey
+
+	err := rotateKind[text_model.Text](kind[text_model.Text]{
+		name:   "Текстовые данные",
+		lister: serv.texts,
+		get:    serv.texts.Get,
+		fields: func(data *text_model.Text) []*[]byte { return []*[]byte{&data.Data} },
+		update: func(in []text_model.Text) error { return serv.Sender.UpdateTexts(keyID, in, token) },
+	}, oldKey, newPub, token)
+	if err != nil {
+		return err
+	}
+
+	err = rotateKind[cred_model.Credential](kind[cred_model.Credential]{
+		name:   "Логины и пароли",
+		lister: serv.creds,
+		get:    serv.creds.Get,
+		fields: func(data *cred_model.Credential) []*[]byte { return []*[]byte{&data.Login, &data.Password} },
+		update: func(in []cred_model.Credential) error { return serv.Sender.UpdateCreds(keyID, in, token) },
+	}, oldKey, newPub, token)
+	if err != nil {
+		return err
+	}
+
+	err = rotateKind[card_model.Card](kind[card_model.Card]{
+		name:   "Банковские карты",
+		lister: serv.cards,
+		get:    serv.cards.Get,
+		fields: func(data *card_model.Card) []*[]byte {
+			return []*[]byte{&data.Number, &data.Period, &data.CVV, &data.FullName}
+		},
+		update: func(in []card_model.Card) error { return serv.Sender.UpdateCards(keyID, in, token) },
+	}, oldKey, newPub, token)
+	if err != nil {
+		return err
+	}
+
+	return serv.rotateBinaries(oldKey, newPub, keyID, token)
+}
+
+// kind - Операции над записями одного типа при смене ключа.
+type kind[T any] struct {
+	name   string
+	lister Lister
+	get    func(meta string, token string) (T, error)
+	// fields - Зашифрованные поля записи.
+	fields func(data *T) []*[]byte
+	// update - Запись пакета перешифрованных записей.
+	update func(in []T) error
+}
+
+// rotateKind - Перешифровка записей одного типа пакетами по batchSize.
+// Записи, уже зашифрованные новым ключом, пропускаются.
+func rotateKind[T any](k kind[T], oldKey *rsa.PrivateKey, newPub *rsa.PublicKey, token string) error {
+
+	metas, err := listAll(k.lister, token)
+	if err != nil {
+		return err
+	}
+
+	batch := make([]T, 0, batchSize)
+	done, skipped := 0, 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if errUpdate := k.update(batch); errUpdate != nil {
+			return errUpdate
+		}
+
+		done += len(batch)
+		batch = batch[:0]
+		progress(k.name, done+skipped, len(metas))
+		return nil
+	}
+
+	progress(k.name, 0, len(metas))
+
+	for _, meta := range metas {
+
+		data, errGet := k.get(meta, token)
+		if errors.Is(errGet, errs.ErrNotFound) {
+			// Запись удалена после получения списка
+			skipped++
+			continue
+		}
+		if errGet != nil {
+			return errGet
+		}
+
+		changed, errEnc := reencrypt(k.fields(&data), oldKey, newPub)
+		if errEnc != nil {
+			return fmt.Errorf("%s \"%s\": %w", k.name, meta, errEnc)
+		}
+
+		if !changed {
+			skipped++
+			progress(k.name, done+skipped, len(metas))
+			continue
+		}
+
+		batch = append(batch, data)
+		if len(batch) == batchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if skipped != 0 {
+		color.Cyan("\tперешифровано: %d, пропущено: %d", done, skipped)
+	}
+
+	return nil
+}
+
+// reencrypt - Перешифровка полей записи новым ключом.
+// Возвращает false, если все поля уже зашифрованы новым ключом.
+func reencrypt(fields []*[]byte, oldKey *rsa.PrivateKey, newPub *rsa.PublicKey) (bool, error) {
+
+	changed := false
+	for _, field := range fields {
+
+		if secret.EncryptedWith(newPub, *field) {
+			continue
+		}
+
+		plain, err := secret.Decrypt(oldKey, *field)
+		if err != nil {
+			return false, err
+		}
+
+		enc, err := secret.Encrypt(newPub, plain)
+		if err != nil {
+			return false, err
+		}
+
+		*field = enc
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// rotateBinaries - Перешифровка бинарных данных. Данные до binaryBatch
+// записываются пакетами, как записи других типов, большие - потоком
+// по одной записи. Сервер проверяет ключ и версию в обоих случаях.
+func (serv RotationService) rotateBinaries(oldKey *rsa.PrivateKey, newPub *rsa.PublicKey, keyID string, token string) error {
+
+	name := "Бинарные данные"
+
+	metas, err := listAll(serv.binaries, token)
+	if err != nil {
+		return err
+	}
+
+	batch := make([]binary_model.Binary, 0, batchSize)
+	size, done, skipped := 0, 0, 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if errUpdate := serv.Sender.UpdateBinaries(keyID, batch, token); errUpdate != nil {
+			return errUpdate
+		}
+
+		done += len(batch)
+		batch, size = batch[:0], 0
+		progress(name, done+skipped, len(metas))
+		return nil
+	}
+
+	progress(name, 0, len(metas))
+
+	for _, meta := range metas {
+
+		data, changed, errRotate := serv.rotateBinary(meta, oldKey, newPub, keyID, token)
+		if errors.Is(errRotate, errs.ErrNotFound) {
+			// Запись удалена после получения списка
+			skipped++
+			continue
+		}
+		if errRotate != nil {
+			fmt.Println()
+			return fmt.Errorf("%s \"%s\": %w", name, meta, errRotate)
+		}
+
+		switch {
+		case !changed:
+			skipped++
+
+		// Данные записаны потоком
+		case data == nil:
+			done++
+
+		default:
+			if len(batch) == batchSize || size+len(data.Data) > binaryBatch {
+				if err = flush(); err != nil {
+					return err
+				}
+			}
+
+			batch = append(batch, *data)
+			size += len(data.Data)
+			continue
+		}
+
+		progress(name, done+skipped, len(metas))
+	}
+
+	if err = flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if skipped != 0 {
+		color.Cyan("\tперешифровано: %d, пропущено: %d", done, skipped)
+	}
+
+	return nil
+}
+
+// rotateBinary - Перешифровка бинарных данных meta.
+// Зашифрованные данные сохраняются во временный файл. Данные до binaryBatch
+// перешифровываются в памяти и возвращаются для записи пакетом, большие
+// расшифровываются и шифруются новым ключом при записи потоком.
+// changed = false, если данные уже зашифрованы новым ключом.
+func (serv RotationService) rotateBinary(meta string, oldKey *rsa.PrivateKey, newPub *rsa.PublicKey,
+	keyID string, token string) (data *binary_model.Binary, changed bool, err error) {
+
+	tmp, err := os.CreateTemp("", "gophkeeper-rotation-*")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	info, err := serv.binaries.Download(meta, tmp, token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, false, err
+	}
+
+	head := make([]byte, 64)
+	n, err := io.ReadFull(tmp, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, false, err
+	}
+
+	if secret.EncryptedWith(newPub, head[:n]) {
+		return nil, false, nil
+	}
+
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, false, err
+	}
+
+	if size <= binaryBatch {
+		enc, errRead := io.ReadAll(tmp)
+		if errRead != nil {
+			return nil, false, errRead
+		}
+
+		data = &binary_model.Binary{MetaInfo: meta, Data: enc, Version: info.Version}
+		if _, err = reencrypt([]*[]byte{&data.Data}, oldKey, newPub); err != nil {
+			return nil, false, err
+		}
+
+		return data, true, nil
+	}
+
+	pr, pw := io.Pipe()
+	decErr := make(chan error, 1)
+
+	go func() {
+		dec := secret.NewDecryptWriter(oldKey, pw)
+		_, errCopy := io.Copy(dec, tmp)
+		if errCopy == nil {
+			errCopy = dec.Close()
+		}
+		pw.CloseWithError(errCopy)
+		decErr <- errCopy
+	}()
+
+	in := binary_model.Upload{
+		MetaInfo:  meta,
+		Overwrite: true,
+		Version:   info.Version,
+	}
+
+	err = serv.Sender.UpdateBinary(keyID, in, secret.NewEncryptReader(newPub, pr), token)
+	pr.Close()
+
+	if errDec := <-decErr; errDec != nil && err == nil {
+		return nil, false, errDec
+	}
+
+	return nil, err == nil, err
+}
+
+// listAll - Метаинформация всех записей одного типа.
+func listAll(lister Lister, token string) ([]string, error) {
+
+	metas := make([]string, 0)
+	filter := list_model.Filter{Limit: batchSize}
+
+	for {
+		list, err := lister.List(filter, token)
+		if err != nil {
+			return nil, err
+		}
+
+		metas = append(metas, list.MetaInfo...)
+
+		if len(list.NextPageToken) == 0 {
+			return metas, nil
+		}
+		filter.PageToken = list.NextPageToken
+	}
+}
+
+// progress - Вывод количества обработанных записей типа name.
+func progress(name string, done, total int) {
+	fmt.Printf("\r\t%s: %d/%d", name, done, total)
+}
+
+// keyID - Идентификатор ключа в hex.
+func keyID(key *rsa.PrivateKey) string {
+	return hex.EncodeToString(secret.KeyID(&key.PublicKey))
+}
+
+// readMaster - Чтение мастер-пароля, которым открывается ключ sealed.
+// Возвращает мастер-пароль и открытый им ключ.
+func (serv RotationService) readMaster(sealed []byte) (string, *rsa.PrivateKey, error) {
+
+	for i := 0; i < attempts; i++ {
+
+		fmt.Print("Мастер-пароль: ")
+		pwd, _ := term.ReadPassword(int(syscall.Stdin))
+		fmt.Println()
+
+		key, err := secret.OpenVaultKey(string(pwd), sealed)
+		if err == nil {
+			return string(pwd), key, nil
+		}
+
+		if !errors.Is(err, secret.ErrWrongPassword) {
+			return "", nil, err
+		}
+
+		color.Red("\tНеверный мастер-пароль")
+	}
+
+	return "", nil, secret.ErrWrongPassword
+}
+
+// readKeyFile - Чтение нового закрытого ключа RSA из файла.
+// Для зашифрованного ключа запрашивается парольная фраза.
+func (serv RotationService) readKeyFile() (*rsa.PrivateKey, error) {
+
+	path := serv.getInput("Файл нового закрытого ключа: ")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var passphrase []byte
+	for i := 0; ; i++ {
+
+		if secret.IsEncryptedKey(data) {
+			fmt.Print("Парольная фраза закрытого ключа: ")
+			passphrase, _ = term.ReadPassword(int(syscall.Stdin))
+			fmt.Println()
+		}
+
+		key, errParse := secret.ParsePrivateKey(data, passphrase)
+		if errors.Is(errParse, secret.ErrPassphrase) && i+1 < attempts {
+			color.Red("\tНеверная парольная фраза")
+			continue
+		}
+		if errParse != nil {
+			return nil, errParse
+		}
+
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s, data encryption requires an RSA key", secret.ErrKeyType, secret.KeyType(key))
+		}
+
+		return rsaKey, nil
+	}
+}
+
+func (serv RotationService) printErr(err error) {
+
+	color.New(color.FgRed).Print("\tОшибка: ")
+
+	switch {
+	case errors.Is(err, errs.ErrUnavailable):
+		fmt.Println("Сервер недоступен")
+
+	case errors.Is(err, errs.ErrConflict):
+		fmt.Println("Данные изменены на другом устройстве или начата смена на другой ключ")
+
+	case errors.Is(err, errs.ErrNoRotation):
+		fmt.Println("Смена ключа не начата или завершена на другом устройстве")
+
+	case errors.Is(err, secret.ErrWrongPassword):
+		fmt.Println("Неверный мастер-пароль")
+
+	case errors.Is(err, errSameKey):
+		fmt.Println("Новый ключ совпадает с текущим")
+
+	case errors.Is(err, errOtherKey):
+		fmt.Println("Ключ не совпадает с ключом начатой смены")
+
+	case errors.Is(err, secret.ErrKeyMismatch):
+		fmt.Println("Данные зашифрованы другим ключом")
+
+	case errors.Is(err, secret.ErrKeyType), errors.Is(err, secret.ErrPassphrase),
+		errors.Is(err, secret.ErrNoPEM), errors.Is(err, os.ErrNotExist):
+		fmt.Println(err)
+
+	default:
+		fmt.Println("Внутренняя ошибка сервиса")
+		serv.logger.Error("failed key rotation", zap.Error(err))
+	}
+}
+
+func (serv RotationService) getInput(title string) string {
+
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print(title)
+	data, _ := reader.ReadString('\n')
+	data = strings.Replace(data, "\n", "", -1)
+	data = strings.Replace(data, "\r", "", -1)
+
+	return data
+}
@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"go.uber.org/zap"
 
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
 	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	"GophKeeper/pkg/secret"
 )
@@ -20,8 +25,15 @@ import (
 type Sender interface {
 	Create(text text_model.Text, token string) error
 	Get(meta string, token string) (text_model.Text, error)
-	Delete(meta string, token string) error
+	Delete(meta string, version int64, token string) error
 	Change(text text_model.Text, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+	ListRevisions(meta string, token string) ([]revision_model.Revision, error)
+	GetRevision(meta string, version int64, token string) (text_model.Text, error)
+	Restore(meta string, version, current int64, token string) error
+	ListTrash(token string) ([]trash_model.Item, error)
+	Undelete(meta string, token string) error
+	Purge(meta string, token string) error
 }
 
 type TextOptions func(c *TextService)
@@ -32,6 +44,8 @@ type TextService struct {
 	publicKey  *rsa.PublicKey
 	privateKey *rsa.PrivateKey
 	logger     *zap.Logger
+	// plaintext - Разрешена запись данных без шифрования.
+	plaintext bool
 
 	token string
 }
@@ -62,6 +76,13 @@ func WithPrivateKey(key *rsa.PrivateKey) TextOptions {
 	}
 }
 
+// WithPlaintext - Разрешение записи данных без шифрования (режим --insecure-plaintext).
+func WithPlaintext(allowed bool) TextOptions {
+	return func(serv *TextService) {
+		serv.plaintext = allowed
+	}
+}
+
 func (serv TextService) ShowMenu() {
 
 	stdin := bufio.NewReader(os.Stdin)
@@ -75,6 +96,9 @@ func (serv TextService) ShowMenu() {
 		fmt.Println("[2] Найти")
 		fmt.Println("[3] Удалить")
 		fmt.Println("[4] Изменить")
+		fmt.Println("[5] Показать все")
+		fmt.Println("[6] История версий")
+		fmt.Println("[7] Корзина")
 		fmt.Println("---------------")
 		fmt.Print("-> ")
 
@@ -101,12 +125,25 @@ func (serv TextService) ShowMenu() {
 
 		case 4:
 			serv.Change()
+
+		case 5:
+			serv.List()
+
+		case 6:
+			serv.History()
+
+		case 7:
+			serv.Trash()
 		}
 	}
 }
 
 func (serv TextService) Create() {
 
+	if !serv.writable() {
+		return
+	}
+
 	data := text_model.Text{}
 
 	data.MetaInfo = serv.getInput("Метаинформация: ")
@@ -137,11 +174,23 @@ func (serv TextService) Get() {
 		return
 	}
 
+	serv.show(meta)
+}
+
+// show - Вывод расшифрованных данных meta.
+func (serv TextService) show(meta string) {
+
 	text, err := serv.Sender.Get(meta, serv.token)
 	if ok := serv.parseError(err); !ok {
 		return
 	}
 
+	serv.print(text)
+}
+
+// print - Вывод расшифрованных данных.
+func (serv TextService) print(text text_model.Text) {
+
 	dataDec, errDec := secret.Decrypt(serv.privateKey, text.Data)
 	if errDec != nil {
 		serv.logger.Error("failed decrypt data", zap.Error(errDec))
@@ -150,6 +199,7 @@ func (serv TextService) Get() {
 	}
 
 	color.Cyan("Данные: %s", string(dataDec))
+	showVersion(text.Version, text.UpdatedAt)
 }
 
 func (serv TextService) Delete() {
@@ -161,41 +211,213 @@ func (serv TextService) Delete() {
 		return
 	}
 
-	err := serv.Sender.Delete(meta, serv.token)
+	// Удаление проверяет, что данные не изменились после получения версии
+	current, err := serv.Sender.Get(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if answer := serv.getInput("Данные будут перемещены в корзину. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	err = serv.Sender.Delete(meta, current.Version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return
+	}
+
 	if ok := serv.parseError(err); ok {
-		color.Green("Данные успешно удалены")
+		color.Green("Данные перемещены в корзину")
 	}
 }
 
 func (serv TextService) Change() {
 
+	if !serv.writable() {
+		return
+	}
+
 	data := text_model.Text{}
 
 	data.MetaInfo = serv.getInput("Метаинформация: ")
-	data.Data = serv.getInputEncode("Текст: ")
 
 	if len(data.MetaInfo) == 0 {
 		color.Red("Метаинформация не может быть пустой")
 		return
 	}
 
+	// Изменение делается на основе текущей версии данных
+	current, err := serv.Sender.Get(data.MetaInfo, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	showVersion(current.Version, current.UpdatedAt)
+	data.Version = current.Version
+
+	data.Data = serv.getInputEncode("Текст: ")
+
 	if len(data.Data) == 0 {
 		color.Red("Данные не могут быть пустыми")
 		return
 	}
 
-	err := serv.Sender.Change(data, serv.token)
+	err = serv.Sender.Change(data, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(data.MetaInfo)
+		return
+	}
+
 	if ok := serv.parseError(err); ok {
 		color.Green("Данные успешно изменены")
 	}
 }
 
+// List - Постраничный вывод метаинформации с фильтром по подстроке.
+func (serv TextService) List() {
+	filter := list_model.Filter{
+		Contains: serv.getInput("Фильтр (пусто - все): "),
+		Limit:    list_model.PageSize,
+	}
+
+	for {
+		list, err := serv.Sender.List(filter, serv.token)
+		if ok := serv.parseError(err); !ok {
+			return
+		}
+
+		if len(list.MetaInfo) == 0 && len(filter.PageToken) == 0 {
+			color.Yellow("Данные не найдены")
+			return
+		}
+
+		for _, meta := range list.MetaInfo {
+			color.Cyan("  %s", meta)
+		}
+
+		if len(list.NextPageToken) == 0 {
+			return
+		}
+
+		if next := serv.getInput("Enter - следующая страница, 0 - назад: "); next == "0" {
+			return
+		}
+
+		filter.PageToken = list.NextPageToken
+	}
+}
+
+// History - Вывод прежних версий данных и восстановление выбранной версии.
+func (serv TextService) History() {
+
+	meta := serv.getInput("Метаинформация: ")
+
+	if len(meta) == 0 {
+		color.Red("Метаинформация не может быть пустой")
+		return
+	}
+
+	revs, err := serv.Sender.ListRevisions(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(revs) == 0 {
+		color.Yellow("Прежних версий нет")
+		return
+	}
+
+	for _, rev := range revs {
+		showVersion(rev.Version, rev.UpdatedAt)
+	}
+
+	version, errParse := strconv.ParseInt(serv.getInput("Версия (пусто - назад): "), 10, 64)
+	if errParse != nil {
+		return
+	}
+
+	text, err := serv.Sender.GetRevision(meta, version, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	serv.print(text)
+
+	if answer := serv.getInput("Восстановить эту версию? [y/n]: "); !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	// Восстановление делается на основе текущей версии данных
+	current, err := serv.Sender.Get(meta, serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	err = serv.Sender.Restore(meta, version, current.Version, serv.token)
+	if errors.Is(err, errs.ErrConflict) {
+		serv.conflict(meta)
+		return
+	}
+
+	if ok := serv.parseError(err); ok {
+		color.Green("Версия %d восстановлена", version)
+	}
+}
+
+// Trash - Вывод удаленных данных, восстановление или окончательное удаление выбранных данных.
+func (serv TextService) Trash() {
+
+	items, err := serv.Sender.ListTrash(serv.token)
+	if ok := serv.parseError(err); !ok {
+		return
+	}
+
+	if len(items) == 0 {
+		color.Yellow("Корзина пуста")
+		return
+	}
+
+	for _, item := range items {
+		color.Cyan("  %s, удалено: %s", item.MetaInfo, item.DeletedAt.Local().Format("02.01.2006 15:04:05"))
+	}
+
+	meta := serv.getInput("Метаинформация (пусто - назад): ")
+	if len(meta) == 0 {
+		return
+	}
+
+	switch serv.getInput("[1] Восстановить, [2] Удалить навсегда: ") {
+	case "1":
+		err = serv.Sender.Undelete(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные восстановлены")
+		}
+
+	case "2":
+		if answer := serv.getInput("Данные будут удалены без возможности восстановления. Удалить? [y/n]: "); !strings.EqualFold(answer, "y") {
+			return
+		}
+
+		err = serv.Sender.Purge(meta, serv.token)
+		if ok := serv.parseError(err); ok {
+			color.Green("Данные удалены навсегда")
+		}
+	}
+}
+
 func (serv TextService) parseError(err error) bool {
 
 	if err == nil {
 		return true
 	}
 
+	// Сервер недоступен - изменение сохранено в локальном кэше
+	if errors.Is(err, errs.ErrQueued) {
+		color.Yellow("Сервер недоступен, изменения будут отправлены при подключении")
+		return true
+	}
+
 	color.New(color.FgRed).Print("\tОшибка: ")
 
 	switch {
@@ -206,9 +428,21 @@ func (serv TextService) parseError(err error) bool {
 	case errors.Is(err, errs.ErrNotFound):
 		fmt.Println("Такая метаинформация не найдена")
 
+	case errors.Is(err, errs.ErrConflict):
+		fmt.Println("Данные изменены на другом устройстве")
+
 	case errors.Is(err, errs.ErrLargeData):
 		fmt.Println("Размер данных слишком большой")
 
+	case errors.Is(err, errs.ErrUnavailable):
+		fmt.Println("Сервер недоступен")
+
+	case errors.Is(err, errs.ErrUnauthenticated):
+		fmt.Println("Сессия закрыта, войдите заново")
+
+	case errors.Is(err, errs.ErrPlaintext):
+		fmt.Println("Сервер принимает только зашифрованные данные")
+
 	default:
 		fmt.Println("Внутренняя ошибка сервиса")
 		serv.logger.Error("unknown error", zap.Error(err))
@@ -255,10 +489,51 @@ func (serv TextService) getInputEncode(title string) []byte {
 	return encodeData
 }
 
+// conflict - Сообщение о том, что данные изменены на другом устройстве,
+// и предложение загрузить актуальные данные.
+func (serv TextService) conflict(meta string) {
+
+	color.Red("Данные изменены на другом устройстве, изменения не сохранены")
+
+	if answer := serv.getInput("Загрузить актуальные данные? [y/n]: "); strings.EqualFold(answer, "y") {
+		serv.show(meta)
+	}
+}
+
+// showVersion - Вывод версии и времени последнего изменения данных.
+func showVersion(version int64, updatedAt time.Time) {
+
+	if updatedAt.IsZero() {
+		color.Cyan("Версия: %d", version)
+		return
+	}
+
+	color.Cyan("Версия: %d, изменено: %s", version, updatedAt.Local().Format("02.01.2006 15:04:05"))
+}
+
 func (serv *TextService) SetToken(token string) {
 	serv.token = token
 }
 
+// SetKey - Ключ шифрования данных, полученный после авторизации.
+func (serv *TextService) SetKey(key *rsa.PrivateKey) {
+	serv.privateKey = key
+	serv.publicKey = &key.PublicKey
+}
+
+// writable - Проверка, что данные будут зашифрованы перед отправкой.
+// Без ключа шифрования запись разрешена только в режиме --insecure-plaintext.
+func (serv TextService) writable() bool {
+
+	if serv.publicKey != nil || serv.plaintext {
+		return true
+	}
+
+	color.Red("Шифрование отключено, запись данных запрещена")
+	color.Yellow("Для хранения данных без шифрования запустите клиент с флагом --insecure-plaintext")
+	return false
+}
+
 func (serv TextService) Name() string {
 	return "Текстовые данные"
 }
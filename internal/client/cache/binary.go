@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
+	"GophKeeper/pkg/errs"
+)
+
+// MaxBinarySize - Максимальный размер бинарных данных, сохраняемых в кэше.
+// Данные большего размера передаются потоком без кэширования
+// и без связи с сервером недоступны.
+const MaxBinarySize = 1024 * 1024 * 16
+
+// errTooLarge - Данные больше MaxBinarySize и не сохраняются в кэше.
+var errTooLarge = errors.New("binary data is too large for cache")
+
+// BinaryRemote - gRPC сервис бинарных данных.
+type BinaryRemote interface {
+	Delete(meta string, version int64, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+	Upload(in binary_model.Upload, r io.Reader, token string) error
+	Download(meta string, w io.Writer, token string) (binary_model.Info, error)
+	ListRevisions(meta string, token string) ([]revision_model.Revision, error)
+	GetRevision(meta string, version int64, token string) (binary_model.Binary, error)
+	Restore(meta string, version, current int64, token string) error
+	ListTrash(token string) ([]trash_model.Item, error)
+	Undelete(meta string, token string) error
+	Purge(meta string, token string) error
+}
+
+// BinarySender - Кэширующая обертка над gRPC сервисом бинарных данных.
+type BinarySender struct {
+	kind   *Kind
+	remote BinaryRemote
+}
+
+// NewBinarySender - Создание кэширующей обертки над remote.
+func NewBinarySender(v *Vault, name string, remote BinaryRemote) *BinarySender {
+	return &BinarySender{
+		kind:   v.Kind(name, binaryRemote{remote: remote}),
+		remote: remote,
+	}
+}
+
+func (s *BinarySender) Delete(meta string, version int64, token string) error {
+	return s.kind.Delete(meta, version, token)
+}
+
+func (s *BinarySender) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	return s.kind.List(filter, token)
+}
+
+// ListRevisions - Прежние версии данных. Запрашиваются только с сервера.
+func (s *BinarySender) ListRevisions(meta string, token string) ([]revision_model.Revision, error) {
+	return s.remote.ListRevisions(meta, token)
+}
+
+// GetRevision - Прежняя версия данных. Запрашивается только с сервера.
+func (s *BinarySender) GetRevision(meta string, version int64, token string) (binary_model.Binary, error) {
+	return s.remote.GetRevision(meta, version, token)
+}
+
+func (s *BinarySender) Restore(meta string, version, current int64, token string) error {
+	return s.kind.OnServer(meta, token, func() error {
+		return s.remote.Restore(meta, version, current, token)
+	})
+}
+
+// ListTrash - Удаленные данные. Запрашиваются только с сервера.
+func (s *BinarySender) ListTrash(token string) ([]trash_model.Item, error) {
+	return s.remote.ListTrash(token)
+}
+
+// Undelete - Восстановление данных из корзины на сервере.
+func (s *BinarySender) Undelete(meta string, token string) error {
+	return s.kind.OnServer(meta, token, func() error {
+		return s.remote.Undelete(meta, token)
+	})
+}
+
+// Purge - Окончательное удаление данных из корзины на сервере.
+func (s *BinarySender) Purge(meta string, token string) error {
+	return s.remote.Purge(meta, token)
+}
+
+// Upload - Загрузка данных. Данные не больше MaxBinarySize кэшируются,
+// большие передаются на сервер потоком, а их старая копия удаляется из кэша.
+func (s *BinarySender) Upload(in binary_model.Upload, r io.Reader, token string) error {
+
+	head, err := io.ReadAll(io.LimitReader(r, MaxBinarySize+1))
+	if err != nil {
+		return err
+	}
+
+	if len(head) <= MaxBinarySize {
+		if in.Overwrite {
+			return s.kind.Change(in.MetaInfo, head, in.Version, token)
+		}
+		return s.kind.Create(in.MetaInfo, head, token)
+	}
+
+	s.kind.vault.mutex.Lock()
+	defer s.kind.vault.mutex.Unlock()
+
+	if !s.kind.online(token) {
+		return errs.ErrUnavailable
+	}
+
+	err = s.remote.Upload(in, io.MultiReader(bytes.NewReader(head), r), token)
+	if err == nil {
+		s.kind.forget(in.MetaInfo)
+	}
+
+	return err
+}
+
+// Download - Получение данных. Без связи с сервером данные берутся из кэша,
+// время изменения данных при этом неизвестно.
+func (s *BinarySender) Download(meta string, w io.Writer, token string) (binary_model.Info, error) {
+
+	s.kind.vault.mutex.Lock()
+	defer s.kind.vault.mutex.Unlock()
+
+	if s.kind.online(token) {
+		buf := &limitBuffer{limit: MaxBinarySize}
+
+		info, err := s.remote.Download(meta, io.MultiWriter(w, buf), token)
+		switch {
+		case err == nil:
+			if buf.overflow {
+				s.kind.tooLarge(meta, info.Version)
+			} else {
+				s.kind.store(meta, buf.Bytes(), info.Version)
+			}
+			return info, nil
+
+		case errors.Is(err, errs.ErrNotFound):
+			s.kind.forget(meta)
+			return info, err
+
+		// Часть данных уже записана в w - взять данные из кэша нельзя
+		case !isOffline(err) || buf.written != 0:
+			return info, err
+		}
+	}
+
+	data, err := s.kind.vault.get(s.kind.name, meta)
+	if err != nil {
+		return binary_model.Info{}, err
+	}
+
+	ver, _ := s.kind.vault.version(s.kind.name, meta)
+	info := binary_model.Info{
+		MetaInfo: meta,
+		Version:  ver,
+	}
+
+	_, err = w.Write(data)
+	return info, err
+}
+
+// limitBuffer - Буфер, накапливающий не более limit байт.
+type limitBuffer struct {
+	bytes.Buffer
+	limit    int
+	written  int
+	overflow bool
+}
+
+func (b *limitBuffer) Write(p []byte) (int, error) {
+
+	b.written += len(p)
+	if b.overflow || b.Len()+len(p) > b.limit {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// capBuffer - Буфер, прерывающий запись с errTooLarge после limit байт.
+type capBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *capBuffer) Write(p []byte) (int, error) {
+
+	if b.Len()+len(p) > b.limit {
+		return 0, errTooLarge
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// binaryRemote - Remote поверх gRPC сервиса бинарных данных.
+type binaryRemote struct {
+	remote BinaryRemote
+}
+
+func (r binaryRemote) Create(meta string, data []byte, token string) error {
+	return r.remote.Upload(binary_model.Upload{MetaInfo: meta}, bytes.NewReader(data), token)
+}
+
+// Get - Получение данных не больше MaxBinarySize.
+// Загрузка больших данных прерывается с ошибкой errTooLarge.
+func (r binaryRemote) Get(meta string, token string) ([]byte, int64, error) {
+
+	buf := &capBuffer{limit: MaxBinarySize}
+	info, err := r.remote.Download(meta, buf, token)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return buf.Bytes(), info.Version, nil
+}
+
+func (r binaryRemote) Delete(meta string, version int64, token string) error {
+	return r.remote.Delete(meta, version, token)
+}
+
+func (r binaryRemote) Change(meta string, data []byte, version int64, token string) error {
+
+	upload := binary_model.Upload{
+		MetaInfo:  meta,
+		Overwrite: true,
+		Version:   version,
+	}
+
+	return r.remote.Upload(upload, bytes.NewReader(data), token)
+}
+
+func (r binaryRemote) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	return r.remote.List(filter, token)
+}
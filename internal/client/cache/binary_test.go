@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+// fakeBinaryRemote - Сервер бинарных данных, отдающий данные заданного размера.
+type fakeBinaryRemote struct {
+	BinaryRemote
+	sizes    map[string]int
+	versions map[string]int64
+	// downloads - Количество загрузок по метаинформации
+	downloads map[string]int
+	// written - Количество байт, записанных при последней загрузке
+	written int
+}
+
+func (r *fakeBinaryRemote) List(_ list_model.Filter, _ string) (list_model.Page, error) {
+
+	var list list_model.Page
+	for _, meta := range []string{"large", "small"} {
+		if ver, ok := r.versions[meta]; ok {
+			list.MetaInfo = append(list.MetaInfo, meta)
+			list.Versions = append(list.Versions, ver)
+		}
+	}
+
+	return list, nil
+}
+
+func (r *fakeBinaryRemote) Download(meta string, w io.Writer, _ string) (binary_model.Info, error) {
+
+	ver, ok := r.versions[meta]
+	if !ok {
+		return binary_model.Info{}, errs.ErrNotFound
+	}
+
+	r.downloads[meta]++
+	r.written = 0
+
+	chunk := bytes.Repeat([]byte{1}, 64*1024)
+	for left := r.sizes[meta]; left > 0; left -= len(chunk) {
+		if left < len(chunk) {
+			chunk = chunk[:left]
+		}
+
+		n, err := w.Write(chunk)
+		r.written += n
+		if err != nil {
+			return binary_model.Info{}, err
+		}
+	}
+
+	return binary_model.Info{MetaInfo: meta, Version: ver}, nil
+}
+
+func TestVault_SyncLargeBinary(t *testing.T) {
+
+	v := openVault(t)
+	remote := &fakeBinaryRemote{
+		sizes:     map[string]int{"large": MaxBinarySize + 1, "small": 10},
+		versions:  map[string]int64{"large": 1, "small": 1},
+		downloads: make(map[string]int),
+	}
+	sender := NewBinarySender(v, "binary", remote)
+
+	report, err := v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Received)
+	require.Equal(t, map[string]int{"large": 1, "small": 1}, remote.downloads)
+
+	// Загрузка больших данных прерывается, в кэш они не попадают
+	require.LessOrEqual(t, remote.written, MaxBinarySize)
+	require.Equal(t, map[string]int64{"small": 1}, v.index.Versions["binary"])
+	require.Equal(t, map[string]int64{"large": 1}, v.index.Large["binary"])
+
+	// Неизменившиеся большие данные повторно не загружаются
+	report, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Zero(t, report.Received)
+	require.Equal(t, map[string]int{"large": 1, "small": 1}, remote.downloads)
+
+	// Без связи с сервером большие данные недоступны
+	var buf bytes.Buffer
+	_, err = sender.Download("large", &buf, "")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	info, err := sender.Download("small", &buf, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), info.Version)
+	require.Equal(t, 10, buf.Len())
+
+	// Измененные данные загружаются снова
+	remote.versions["large"] = 2
+	remote.sizes["small"] = MaxBinarySize + 1
+	remote.versions["small"] = 2
+
+	_, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"large": 2, "small": 2}, remote.downloads)
+	require.Empty(t, v.index.Versions["binary"])
+	require.Equal(t, map[string]int64{"large": 2, "small": 2}, v.index.Large["binary"])
+
+	// Удаленные на сервере данные забываются
+	delete(remote.versions, "large")
+
+	_, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"small": 2}, v.index.Large["binary"])
+}
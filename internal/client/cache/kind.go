@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+// syncPageSize - Размер страницы списка данных при синхронизации.
+const syncPageSize = 500
+
+// Remote - Операции сервера над данными одного типа.
+// Данные передаются в виде байтов, чтобы кэш не зависел от типа данных.
+// version - версия данных на сервере; при изменении и удалении -
+// ожидаемая текущая версия.
+type Remote interface {
+	Create(meta string, data []byte, token string) error
+	Get(meta string, token string) (data []byte, version int64, err error)
+	Delete(meta string, version int64, token string) error
+	Change(meta string, data []byte, version int64, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+}
+
+// Kind - Данные одного типа в кэше.
+// При доступном сервере операции выполняются на сервере и сохраняются в кэше,
+// иначе - только в кэше, а изменения ставятся в очередь на отправку.
+type Kind struct {
+	vault  *Vault
+	name   string
+	remote Remote
+}
+
+// Kind - Регистрация типа данных name, хранящихся на сервере remote.
+func (v *Vault) Kind(name string, remote Remote) *Kind {
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	kind := &Kind{
+		vault:  v,
+		name:   name,
+		remote: remote,
+	}
+
+	v.kinds = append(v.kinds, kind)
+	return kind
+}
+
+// isOffline - Сервер недоступен.
+func isOffline(err error) bool {
+	return errors.Is(err, errs.ErrUnavailable)
+}
+
+// online - Проверка доступности сервера.
+// Перед обращением к серверу отправляются изменения из очереди,
+// чтобы операции на сервере выполнялись в порядке их совершения.
+// Пустой token - работа без авторизации на сервере.
+func (k *Kind) online(token string) bool {
+
+	if len(token) == 0 {
+		return false
+	}
+
+	return !isOffline(k.vault.replay(token))
+}
+
+func (k *Kind) Create(meta string, data []byte, token string) error {
+
+	k.vault.mutex.Lock()
+	defer k.vault.mutex.Unlock()
+
+	if k.online(token) {
+		err := k.remote.Create(meta, data, token)
+		if !isOffline(err) {
+			if err == nil {
+				k.store(meta, data, 1)
+			}
+			return err
+		}
+	}
+
+	if _, ok := k.vault.version(k.name, meta); ok {
+		return errs.ErrAlreadyExist
+	}
+
+	return k.queue(ActionCreate, meta, data, 0)
+}
+
+func (k *Kind) Get(meta string, token string) ([]byte, int64, error) {
+
+	k.vault.mutex.Lock()
+	defer k.vault.mutex.Unlock()
+
+	if k.online(token) {
+		data, ver, err := k.remote.Get(meta, token)
+		if !isOffline(err) {
+			switch {
+			case err == nil:
+				k.store(meta, data, ver)
+
+			case errors.Is(err, errs.ErrNotFound):
+				k.forget(meta)
+			}
+			return data, ver, err
+		}
+	}
+
+	data, err := k.vault.get(k.name, meta)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ver, _ := k.vault.version(k.name, meta)
+	return data, ver, nil
+}
+
+func (k *Kind) Delete(meta string, version int64, token string) error {
+
+	k.vault.mutex.Lock()
+	defer k.vault.mutex.Unlock()
+
+	if k.online(token) {
+		err := k.remote.Delete(meta, version, token)
+		if !isOffline(err) {
+			if err == nil || errors.Is(err, errs.ErrNotFound) {
+				k.forget(meta)
+			}
+			return err
+		}
+	}
+
+	if _, ok := k.vault.version(k.name, meta); !ok {
+		return errs.ErrNotFound
+	}
+
+	return k.queue(ActionDelete, meta, nil, version)
+}
+
+func (k *Kind) Change(meta string, data []byte, version int64, token string) error {
+
+	k.vault.mutex.Lock()
+	defer k.vault.mutex.Unlock()
+
+	if k.online(token) {
+		err := k.remote.Change(meta, data, version, token)
+		if !isOffline(err) {
+			if err == nil {
+				k.changed(meta, data, version)
+			}
+			return err
+		}
+	}
+
+	if _, ok := k.vault.version(k.name, meta); !ok {
+		return errs.ErrNotFound
+	}
+
+	return k.queue(ActionChange, meta, data, version)
+}
+
+// OnServer - Изменение данных meta на сервере функцией op.
+// Используется для операций с историей и корзиной, которые хранятся
+// только на сервере и без связи с ним недоступны.
+// После изменения данные кэша заменяются данными сервера.
+func (k *Kind) OnServer(meta string, token string, op func() error) error {
+
+	k.vault.mutex.Lock()
+	defer k.vault.mutex.Unlock()
+
+	if !k.online(token) {
+		return errs.ErrUnavailable
+	}
+
+	if err := op(); err != nil {
+		return err
+	}
+
+	k.reload(meta, token)
+	return nil
+}
+
+// List - Страница списка метаинформации.
+// Без связи с сервером список строится по данным кэша с теми же правилами
+// фильтрации и постраничного вывода.
+func (k *Kind) List(filter list_model.Filter, token string) (list_model.Page, error) {
+
+	k.vault.mutex.Lock()
+	defer k.vault.mutex.Unlock()
+
+	if k.online(token) {
+		list, err := k.remote.List(filter, token)
+		if !isOffline(err) {
+			return list, err
+		}
+	}
+
+	if len(k.vault.dir) == 0 {
+		return list_model.Page{}, errNotOpened
+	}
+
+	metas := make([]string, 0)
+	for _, meta := range k.vault.metas(k.name) {
+		if strings.HasPrefix(meta, filter.Prefix) &&
+			strings.Contains(meta, filter.Contains) &&
+			meta > filter.PageToken {
+			metas = append(metas, meta)
+		}
+	}
+
+	sort.Strings(metas)
+
+	list := list_model.Page{MetaInfo: metas}
+	if filter.Limit > 0 && len(metas) > filter.Limit {
+		list.MetaInfo = metas[:filter.Limit]
+		list.NextPageToken = metas[filter.Limit-1]
+	}
+
+	// Версии записей - версии на сервере, на основе которых сделаны локальные изменения
+	list.Versions = make([]int64, 0, len(list.MetaInfo))
+	for _, meta := range list.MetaInfo {
+		ver, _ := k.vault.version(k.name, meta)
+		list.Versions = append(list.Versions, ver)
+	}
+
+	return list, nil
+}
+
+// queue - Локальное изменение записи с постановкой в очередь на отправку.
+// version - версия записи на сервере, на основе которой сделано изменение.
+func (k *Kind) queue(action Action, meta string, data []byte, version int64) error {
+
+	var err error
+	if action == ActionDelete {
+		err = k.vault.remove(k.name, meta)
+	} else {
+		// Локальные данные сохраняют версию, на основе которой они изменены,
+		// чтобы следующее изменение было сделано на той же основе
+		err = k.vault.put(k.name, meta, data, version)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	op := Op{
+		Kind:    k.name,
+		Action:  action,
+		Meta:    meta,
+		Version: version,
+	}
+
+	if err = k.vault.enqueue(op); err != nil {
+		return err
+	}
+
+	return errs.ErrQueued
+}
+
+// store - Сохранение данных, полученных с сервера или отправленных на него.
+func (k *Kind) store(meta string, data []byte, version int64) {
+	if err := k.vault.put(k.name, meta, data, version); err != nil {
+		k.vault.logger.Error("failed save cache record", zap.Error(err), zap.String("meta", meta))
+	}
+}
+
+// changed - Сохранение данных, измененных на сервере.
+// Сервер увеличивает версию при каждом изменении.
+func (k *Kind) changed(meta string, data []byte, version int64) {
+	k.store(meta, data, version+1)
+}
+
+// tooLarge - Удаление из кэша данных, слишком больших для хранения в нем.
+// Версия ver данных на сервере запоминается, 0 - версия неизвестна.
+func (k *Kind) tooLarge(meta string, ver int64) {
+	if err := k.vault.putLarge(k.name, meta, ver); err != nil {
+		k.vault.logger.Error("failed remove cache record", zap.Error(err), zap.String("meta", meta))
+	}
+}
+
+// forget - Удаление данных, которых нет на сервере.
+func (k *Kind) forget(meta string) {
+	if err := k.vault.remove(k.name, meta); err != nil {
+		k.vault.logger.Error("failed remove cache record", zap.Error(err), zap.String("meta", meta))
+	}
+}
@@ -0,0 +1,65 @@
+package cache
+
+// Action - Вид изменения в очереди.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionChange Action = "change"
+	ActionDelete Action = "delete"
+)
+
+// Op - Изменение, сделанное без связи с сервером.
+// Данные изменения хранятся в записи кэша, а Version - версия записи
+// на сервере, на основе которой оно сделано.
+type Op struct {
+	Kind    string `json:"kind"`
+	Action  Action `json:"action"`
+	Meta    string `json:"meta"`
+	Version int64  `json:"version"`
+}
+
+// enqueue - Добавление изменения в очередь.
+// Для каждой записи в очереди хранится не более одного изменения:
+// новое изменение объединяется с уже поставленным в очередь.
+func (v *Vault) enqueue(op Op) error {
+
+	for i, queued := range v.index.Queue {
+		if queued.Kind != op.Kind || queued.Meta != op.Meta {
+			continue
+		}
+
+		switch {
+		// Запись еще не попала на сервер - отправлять нечего
+		case queued.Action == ActionCreate && op.Action == ActionDelete:
+			v.index.Queue = append(v.index.Queue[:i], v.index.Queue[i+1:]...)
+
+		// Запись еще не попала на сервер - отправится с последними данными
+		case queued.Action == ActionCreate:
+
+		// Запись удалена и создана заново - на сервере это изменение
+		case queued.Action == ActionDelete && op.Action == ActionCreate:
+			v.index.Queue[i].Action = ActionChange
+
+		default:
+			v.index.Queue[i].Action = op.Action
+		}
+
+		return v.saveIndex()
+	}
+
+	v.index.Queue = append(v.index.Queue, op)
+	return v.saveIndex()
+}
+
+// queued - Поиск изменения записи в очереди.
+func (v *Vault) queued(kind, meta string) (Op, bool) {
+
+	for _, op := range v.index.Queue {
+		if op.Kind == kind && op.Meta == meta {
+			return op, true
+		}
+	}
+
+	return Op{}, false
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVault_Enqueue(t *testing.T) {
+
+	tests := []struct {
+		name string
+		ops  []Op
+		want []Op
+	}{
+		{
+			name: "Create and delete",
+			ops: []Op{
+				{Kind: "text", Action: ActionCreate, Meta: "note"},
+				{Kind: "text", Action: ActionDelete, Meta: "note"},
+			},
+			want: nil,
+		},
+		{
+			name: "Create and change",
+			ops: []Op{
+				{Kind: "text", Action: ActionCreate, Meta: "note"},
+				{Kind: "text", Action: ActionChange, Meta: "note"},
+			},
+			want: []Op{{Kind: "text", Action: ActionCreate, Meta: "note"}},
+		},
+		{
+			name: "Delete and create",
+			ops: []Op{
+				{Kind: "text", Action: ActionDelete, Meta: "note", Version: 3},
+				{Kind: "text", Action: ActionCreate, Meta: "note"},
+			},
+			want: []Op{{Kind: "text", Action: ActionChange, Meta: "note", Version: 3}},
+		},
+		{
+			name: "Change and delete",
+			ops: []Op{
+				{Kind: "text", Action: ActionChange, Meta: "note", Version: 2},
+				{Kind: "text", Action: ActionDelete, Meta: "note", Version: 2},
+			},
+			want: []Op{{Kind: "text", Action: ActionDelete, Meta: "note", Version: 2}},
+		},
+		{
+			name: "Different records",
+			ops: []Op{
+				{Kind: "text", Action: ActionChange, Meta: "note", Version: 1},
+				{Kind: "card", Action: ActionDelete, Meta: "note", Version: 1},
+				{Kind: "text", Action: ActionCreate, Meta: "other"},
+			},
+			want: []Op{
+				{Kind: "text", Action: ActionChange, Meta: "note", Version: 1},
+				{Kind: "card", Action: ActionDelete, Meta: "note", Version: 1},
+				{Kind: "text", Action: ActionCreate, Meta: "other"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			v := openVault(t)
+
+			for _, op := range tt.ops {
+				require.NoError(t, v.enqueue(op))
+			}
+
+			if len(tt.want) == 0 {
+				require.Empty(t, v.index.Queue)
+			} else {
+				require.Equal(t, tt.want, v.index.Queue)
+			}
+
+			// Очередь сохраняется в индексе
+			reopened := NewVault(v.root, v.addr)
+			require.NoError(t, reopened.Open(testEmail, testKeys()[0]))
+			require.Equal(t, len(tt.want), reopened.Pending())
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"encoding/json"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
+)
+
+// TypedRemote - gRPC сервис данных типа T.
+type TypedRemote[T any] interface {
+	Create(data T, token string) error
+	Get(meta string, token string) (T, error)
+	Delete(meta string, version int64, token string) error
+	Change(data T, token string) error
+	List(filter list_model.Filter, token string) (list_model.Page, error)
+	ListRevisions(meta string, token string) ([]revision_model.Revision, error)
+	GetRevision(meta string, version int64, token string) (T, error)
+	Restore(meta string, version, current int64, token string) error
+	ListTrash(token string) ([]trash_model.Item, error)
+	Undelete(meta string, token string) error
+	Purge(meta string, token string) error
+}
+
+// Sender - Кэширующая обертка над gRPC сервисом данных типа T.
+// Реализует Sender сервисов приложения клиента.
+// Данные хранятся в кэше в JSON, поля данных уже зашифрованы клиентом.
+type Sender[T any] struct {
+	kind    *Kind
+	remote  TypedRemote[T]
+	meta    func(T) string
+	version func(*T) *int64
+}
+
+// NewSender - Создание кэширующей обертки над remote.
+// meta - получение метаинформации данных, version - доступ к версии данных.
+func NewSender[T any](v *Vault, name string, remote TypedRemote[T], meta func(T) string, version func(*T) *int64) *Sender[T] {
+	return &Sender[T]{
+		kind:    v.Kind(name, typedRemote[T]{remote: remote, version: version}),
+		remote:  remote,
+		meta:    meta,
+		version: version,
+	}
+}
+
+func (s *Sender[T]) Create(data T, token string) error {
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return s.kind.Create(s.meta(data), raw, token)
+}
+
+func (s *Sender[T]) Get(meta string, token string) (T, error) {
+
+	var data T
+
+	raw, ver, err := s.kind.Get(meta, token)
+	if err != nil {
+		return data, err
+	}
+
+	if err = json.Unmarshal(raw, &data); err != nil {
+		return data, err
+	}
+
+	*s.version(&data) = ver
+	return data, nil
+}
+
+func (s *Sender[T]) Delete(meta string, version int64, token string) error {
+	return s.kind.Delete(meta, version, token)
+}
+
+func (s *Sender[T]) Change(data T, token string) error {
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return s.kind.Change(s.meta(data), raw, *s.version(&data), token)
+}
+
+func (s *Sender[T]) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	return s.kind.List(filter, token)
+}
+
+// ListRevisions - Прежние версии данных. Запрашиваются только с сервера.
+func (s *Sender[T]) ListRevisions(meta string, token string) ([]revision_model.Revision, error) {
+	return s.remote.ListRevisions(meta, token)
+}
+
+// GetRevision - Прежняя версия данных. Запрашивается только с сервера.
+func (s *Sender[T]) GetRevision(meta string, version int64, token string) (T, error) {
+	return s.remote.GetRevision(meta, version, token)
+}
+
+func (s *Sender[T]) Restore(meta string, version, current int64, token string) error {
+	return s.kind.OnServer(meta, token, func() error {
+		return s.remote.Restore(meta, version, current, token)
+	})
+}
+
+// ListTrash - Удаленные данные. Запрашиваются только с сервера.
+func (s *Sender[T]) ListTrash(token string) ([]trash_model.Item, error) {
+	return s.remote.ListTrash(token)
+}
+
+// Undelete - Восстановление данных из корзины на сервере.
+func (s *Sender[T]) Undelete(meta string, token string) error {
+	return s.kind.OnServer(meta, token, func() error {
+		return s.remote.Undelete(meta, token)
+	})
+}
+
+// Purge - Окончательное удаление данных из корзины на сервере.
+func (s *Sender[T]) Purge(meta string, token string) error {
+	return s.remote.Purge(meta, token)
+}
+
+// typedRemote - Remote поверх gRPC сервиса данных типа T.
+type typedRemote[T any] struct {
+	remote  TypedRemote[T]
+	version func(*T) *int64
+}
+
+func (r typedRemote[T]) Create(meta string, raw []byte, token string) error {
+
+	var data T
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	return r.remote.Create(data, token)
+}
+
+func (r typedRemote[T]) Get(meta string, token string) ([]byte, int64, error) {
+
+	data, err := r.remote.Get(meta, token)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	raw, err := json.Marshal(data)
+	return raw, *r.version(&data), err
+}
+
+func (r typedRemote[T]) Delete(meta string, version int64, token string) error {
+	return r.remote.Delete(meta, version, token)
+}
+
+func (r typedRemote[T]) Change(meta string, raw []byte, version int64, token string) error {
+
+	var data T
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	*r.version(&data) = version
+	return r.remote.Change(data, token)
+}
+
+func (r typedRemote[T]) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	return r.remote.List(filter, token)
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+// Report - Результат синхронизации кэша с сервером.
+type Report struct {
+	// Sent - Количество отправленных изменений из очереди.
+	Sent int
+	// Pending - Количество изменений, оставшихся в очереди.
+	Pending int
+	// Received - Количество записей, полученных с сервера.
+	Received int
+	// Conflicts - Изменения, отклоненные из-за расхождения с сервером.
+	// В кэше для них сохранены данные сервера.
+	Conflicts []string
+}
+
+// Sync - Синхронизация с сервером: отправка изменений из очереди
+// и обновление кэша всеми данными с сервера.
+// Если сервер недоступен, возвращается errs.ErrUnavailable.
+func (v *Vault) Sync(token string) (Report, error) {
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if len(v.dir) == 0 {
+		return Report{}, errNotOpened
+	}
+
+	pending := len(v.index.Queue)
+	err := v.replay(token)
+
+	report := Report{
+		Pending:   len(v.index.Queue),
+		Conflicts: v.conflicts,
+	}
+	report.Sent = pending - report.Pending
+	v.conflicts = nil
+
+	if err != nil {
+		return report, err
+	}
+
+	for _, kind := range v.kinds {
+		received, errRefresh := kind.refresh(token)
+		report.Received += received
+
+		if errRefresh != nil {
+			return report, errRefresh
+		}
+	}
+
+	return report, nil
+}
+
+// replay - Отправка изменений из очереди на сервер.
+// Если сервер недоступен, отправка прекращается, а оставшиеся изменения
+// сохраняются в очереди.
+func (v *Vault) replay(token string) error {
+
+	for len(v.index.Queue) != 0 {
+
+		op := v.index.Queue[0]
+
+		var kind *Kind
+		for _, k := range v.kinds {
+			if k.name == op.Kind {
+				kind = k
+			}
+		}
+
+		if kind != nil {
+			err := kind.apply(op, token)
+			if isOffline(err) {
+				return err
+			}
+
+			if err != nil {
+				v.logger.Error("failed replay cache operation",
+					zap.Error(err),
+					zap.String("kind", op.Kind),
+					zap.String("meta", op.Meta))
+
+				v.conflicts = append(v.conflicts, op.Meta)
+				kind.reload(op.Meta, token)
+			}
+		}
+
+		v.index.Queue = v.index.Queue[1:]
+		if err := v.saveIndex(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply - Отправка изменения на сервер.
+// Изменение и удаление отправляются с версией, на основе которой они сделаны:
+// если данные на сервере с тех пор изменились, сервер их отклонит.
+func (k *Kind) apply(op Op, token string) error {
+
+	if op.Action == ActionDelete {
+		err := k.remote.Delete(op.Meta, op.Version, token)
+		// Удаленные на сервере данные удалять не нужно
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := k.vault.get(k.name, op.Meta)
+	if err != nil {
+		return err
+	}
+
+	if op.Action == ActionCreate {
+		if err = k.remote.Create(op.Meta, data, token); err == nil {
+			k.store(op.Meta, data, 1)
+		}
+		return err
+	}
+
+	if err = k.remote.Change(op.Meta, data, op.Version, token); err == nil {
+		k.changed(op.Meta, data, op.Version)
+	}
+	return err
+}
+
+// reload - Замена данных кэша данными сервера.
+func (k *Kind) reload(meta, token string) {
+
+	data, ver, err := k.remote.Get(meta, token)
+	switch {
+	case err == nil:
+		k.store(meta, data, ver)
+
+	case errors.Is(err, errs.ErrNotFound), errors.Is(err, errTooLarge):
+		k.forget(meta)
+	}
+}
+
+// refresh - Загрузка в кэш данных типа, изменившихся на сервере.
+// Запрашиваются только записи, версия которых на сервере отличается от версии
+// в кэше. Если сервер не передал версии, запрашиваются все записи.
+// Данные, которых нет на сервере, удаляются из кэша.
+func (k *Kind) refresh(token string) (int, error) {
+
+	// onServer - Версии записей на сервере, 0 - версия неизвестна
+	onServer := make(map[string]int64)
+	filter := list_model.Filter{Limit: syncPageSize}
+
+	for {
+		list, err := k.remote.List(filter, token)
+		if err != nil {
+			return 0, err
+		}
+
+		for i, meta := range list.MetaInfo {
+			onServer[meta] = 0
+			if len(list.Versions) == len(list.MetaInfo) {
+				onServer[meta] = list.Versions[i]
+			}
+		}
+
+		if len(list.NextPageToken) == 0 {
+			break
+		}
+		filter.PageToken = list.NextPageToken
+	}
+
+	received := 0
+	for meta, ver := range onServer {
+		if ver != 0 && k.actual(meta, ver) {
+			continue
+		}
+
+		data, got, err := k.remote.Get(meta, token)
+		if err != nil {
+			switch {
+			case errors.Is(err, errs.ErrNotFound):
+				continue
+
+			// Версия слишком больших данных запоминается, чтобы не загружать их снова
+			case errors.Is(err, errTooLarge):
+				k.tooLarge(meta, ver)
+				continue
+			}
+			return received, err
+		}
+
+		if cached, ok := k.vault.version(k.name, meta); !ok || cached != got {
+			k.store(meta, data, got)
+			received++
+		}
+	}
+
+	for _, meta := range append(k.vault.metas(k.name), k.vault.largeMetas(k.name)...) {
+		if _, ok := onServer[meta]; !ok {
+			k.forget(meta)
+		}
+	}
+
+	return received, nil
+}
+
+// actual - Версия ver записи meta на сервере уже известна кэшу:
+// запись сохранена с этой версией или пропущена из-за размера.
+func (k *Kind) actual(meta string, ver int64) bool {
+
+	if cached, ok := k.vault.version(k.name, meta); ok && cached == ver {
+		return true
+	}
+
+	large, ok := k.vault.large(k.name, meta)
+	return ok && large == ver
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+func TestVault_SyncReplay(t *testing.T) {
+
+	v := openVault(t)
+	remote := newFakeRemote()
+	kind := v.Kind("text", remote)
+
+	for _, meta := range []string{"change", "delete"} {
+		require.NoError(t, kind.Create(meta, []byte(meta), testToken))
+	}
+
+	// Без связи с сервером изменения ставятся в очередь
+	remote.offline = true
+
+	require.ErrorIs(t, kind.Create("create", []byte("new"), testToken), errs.ErrQueued)
+	require.ErrorIs(t, kind.Change("change", []byte("changed"), 1, testToken), errs.ErrQueued)
+	require.ErrorIs(t, kind.Delete("delete", 1, testToken), errs.ErrQueued)
+	require.ErrorIs(t, kind.Create("draft", []byte("draft"), testToken), errs.ErrQueued)
+	require.ErrorIs(t, kind.Delete("draft", 0, testToken), errs.ErrQueued)
+	require.Equal(t, 3, v.Pending())
+
+	report, err := v.Sync(testToken)
+	require.ErrorIs(t, err, errs.ErrUnavailable)
+	require.Equal(t, Report{Pending: 3}, report)
+
+	// После восстановления связи очередь отправляется по порядку
+	remote.offline = false
+
+	report, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Sent)
+	require.Zero(t, report.Pending)
+	require.Empty(t, report.Conflicts)
+
+	require.Equal(t, map[string]int64{"create": 1, "change": 2}, remote.versions)
+	require.Equal(t, "new", string(remote.data["create"]))
+	require.Equal(t, "changed", string(remote.data["change"]))
+
+	require.Equal(t, map[string]int64{"create": 1, "change": 2}, v.index.Versions["text"])
+}
+
+func TestVault_SyncConflict(t *testing.T) {
+
+	v := openVault(t)
+	remote := newFakeRemote()
+	kind := v.Kind("text", remote)
+
+	require.NoError(t, kind.Create("note", []byte("original"), testToken))
+	require.NoError(t, kind.Create("gone", []byte("original"), testToken))
+
+	remote.offline = true
+	require.ErrorIs(t, kind.Change("note", []byte("local"), 1, testToken), errs.ErrQueued)
+	require.ErrorIs(t, kind.Change("gone", []byte("local"), 1, testToken), errs.ErrQueued)
+
+	// Тем временем данные изменены и удалены на сервере с другого устройства
+	remote.offline = false
+	require.NoError(t, remote.Change("note", []byte("server"), 1, testToken))
+	require.NoError(t, remote.Delete("gone", 1, testToken))
+
+	report, err := v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{"note", "gone"}, report.Conflicts)
+	require.Zero(t, report.Pending)
+
+	// В кэше сохранены данные сервера
+	data, ver, err := kind.Get("note", "")
+	require.NoError(t, err)
+	require.Equal(t, "server", string(data))
+	require.Equal(t, int64(2), ver)
+
+	_, _, err = kind.Get("gone", "")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Конфликты возвращаются только один раз
+	report, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Empty(t, report.Conflicts)
+}
+
+func TestVault_SyncRefresh(t *testing.T) {
+
+	v := openVault(t)
+	remote := newFakeRemote()
+	kind := v.Kind("text", remote)
+
+	for _, meta := range []string{"a", "b", "c"} {
+		require.NoError(t, remote.Create(meta, []byte(meta), testToken))
+	}
+
+	report, err := v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Received)
+	require.ElementsMatch(t, []string{"a", "b", "c"}, remote.gets)
+
+	// Неизменившиеся данные повторно не запрашиваются
+	remote.gets = nil
+
+	report, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Zero(t, report.Received)
+	require.Empty(t, remote.gets)
+
+	// Запрашиваются только измененные данные, удаленные удаляются из кэша
+	require.NoError(t, remote.Change("b", []byte("new"), 1, testToken))
+	require.NoError(t, remote.Delete("c", 1, testToken))
+
+	report, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Received)
+	require.Equal(t, []string{"b"}, remote.gets)
+	require.Equal(t, map[string]int64{"a": 1, "b": 2}, v.index.Versions["text"])
+
+	// Если сервер не передает версии, запрашиваются все данные
+	remote.gets = nil
+	remote.noVersions = true
+
+	report, err = v.Sync(testToken)
+	require.NoError(t, err)
+	require.Zero(t, report.Received)
+	require.ElementsMatch(t, []string{"a", "b"}, remote.gets)
+
+	data, ver, err := kind.Get("b", "")
+	require.NoError(t, err)
+	require.Equal(t, "new", string(data))
+	require.Equal(t, int64(2), ver)
+}
+
+func TestKind_ListOffline(t *testing.T) {
+
+	v := openVault(t)
+	remote := newFakeRemote()
+	kind := v.Kind("text", remote)
+
+	for _, meta := range []string{"mail.ru", "gmail", "mail.google", "yandex", "mail.com"} {
+		require.NoError(t, kind.Create(meta, []byte(meta), testToken))
+	}
+
+	remote.offline = true
+
+	tests := []struct {
+		name   string
+		filter list_model.Filter
+		pages  [][]string
+	}{
+		{
+			name:   "All",
+			filter: list_model.Filter{},
+			pages:  [][]string{{"gmail", "mail.com", "mail.google", "mail.ru", "yandex"}},
+		},
+		{
+			name:   "Pages",
+			filter: list_model.Filter{Limit: 2},
+			pages:  [][]string{{"gmail", "mail.com"}, {"mail.google", "mail.ru"}, {"yandex"}},
+		},
+		{
+			name:   "Prefix",
+			filter: list_model.Filter{Prefix: "mail", Limit: 2},
+			pages:  [][]string{{"mail.com", "mail.google"}, {"mail.ru"}},
+		},
+		{
+			name:   "Contains",
+			filter: list_model.Filter{Contains: "mail", Limit: 3},
+			pages:  [][]string{{"gmail", "mail.com", "mail.google"}, {"mail.ru"}},
+		},
+		{
+			name:   "Nothing found",
+			filter: list_model.Filter{Prefix: "unknown"},
+			pages:  [][]string{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var pages [][]string
+
+			filter := tt.filter
+			for {
+				list, err := kind.List(filter, testToken)
+				require.NoError(t, err)
+
+				// Версии берутся из кэша
+				require.Len(t, list.Versions, len(list.MetaInfo))
+				for _, ver := range list.Versions {
+					require.Equal(t, int64(1), ver)
+				}
+
+				pages = append(pages, list.MetaInfo)
+
+				if len(list.NextPageToken) == 0 {
+					break
+				}
+				filter.PageToken = list.NextPageToken
+			}
+
+			require.Equal(t, tt.pages, pages)
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/pkg/errs"
+)
+
+const (
+	// indexFile - Файл с версиями записей и очередью изменений.
+	indexFile = "index"
+	// vaultKeyFile - Ключ хранилища, зашифрованный мастер-паролем.
+	vaultKeyFile = "vault_key"
+	dirMode      = 0700
+	fileMode     = 0600
+)
+
+// errNotOpened - Кэш используется до Open.
+var errNotOpened = errors.New("cache is not opened")
+
+// Vault - Локальный зашифрованный кэш данных пользователя.
+//
+// Каждая запись хранится в отдельном файле, а версии записей и очередь
+// изменений, сделанных без связи с сервером, - в индексе.
+// Все файлы шифруются AES-256-GCM.
+type Vault struct {
+	mutex sync.Mutex
+
+	// root - Каталог кэша всех пользователей.
+	root string
+	// addr - Адрес сервера, кэши разных серверов не смешиваются.
+	addr string
+	key  []byte
+	// dir - Каталог кэша пользователя, задается в Open.
+	dir string
+
+	index index
+	kinds []*Kind
+	// conflicts - Метаинформация изменений, отклоненных при синхронизации.
+	conflicts []string
+
+	logger *zap.Logger
+}
+
+// index - Содержимое индекса кэша.
+type index struct {
+	// Versions - Версии записей на сервере по типу данных и метаинформации.
+	// Версия 0 - запись создана локально и еще не отправлена на сервер.
+	Versions map[string]map[string]int64 `json:"versions"`
+	// Large - Версии записей на сервере, не сохраненных в кэше из-за размера.
+	Large map[string]map[string]int64 `json:"large,omitempty"`
+	Queue []Op                        `json:"queue"`
+}
+
+// NewVault - Создание кэша в каталоге root для сервера addr.
+func NewVault(root, addr string) *Vault {
+	return &Vault{
+		root:   root,
+		addr:   addr,
+		logger: zap.L(),
+	}
+}
+
+// DefaultDir - Каталог кэша по умолчанию в конфигурационном каталоге пользователя.
+func DefaultDir() string {
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "gophkeeper", "cache")
+}
+
+// Open - Открытие кэша пользователя email.
+// Ключ шифрования кэша получается из закрытого ключа клиента.
+func (v *Vault) Open(email string, privKey *rsa.PrivateKey) error {
+
+	if privKey == nil {
+		return fmt.Errorf("private key is required for cache encryption")
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	dir := v.userDir(email)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return err
+	}
+
+	key := sha256.Sum256(append([]byte("gophkeeper-cache:"), x509.MarshalPKCS1PrivateKey(privKey)...))
+	v.key = key[:]
+
+	idx := index{Versions: make(map[string]map[string]int64)}
+
+	data, err := v.readFile(filepath.Join(dir, indexFile))
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(data, &idx); err != nil {
+			return err
+		}
+
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	v.dir = dir
+	v.index = idx
+
+	return nil
+}
+
+// Reset - Удаление кэша пользователя email и открытие пустого кэша
+// с новым ключом шифрования. Используется после смены ключа, когда
+// сохраненные записи больше нельзя расшифровать.
+// Копия ключа хранилища и очередь изменений не сохраняются.
+func (v *Vault) Reset(email string, privKey *rsa.PrivateKey) error {
+
+	v.mutex.Lock()
+
+	dir := v.userDir(email)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		v.mutex.Unlock()
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == vaultKeyFile {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			v.mutex.Unlock()
+			return err
+		}
+	}
+
+	v.dir = ""
+	v.index = index{}
+	v.conflicts = nil
+	v.mutex.Unlock()
+
+	return v.Open(email, privKey)
+}
+
+// Remove - Удаление кэша пользователя email вместе с копией ключа хранилища.
+// Используется после удаления учетной записи.
+func (v *Vault) Remove(email string) error {
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.dir = ""
+	v.index = index{}
+	v.conflicts = nil
+
+	return os.RemoveAll(v.userDir(email))
+}
+
+// SealedKey - Копия ключа хранилища пользователя email, зашифрованного
+// мастер-паролем. Используется без связи с сервером. Если копии нет, возвращается nil.
+func (v *Vault) SealedKey(email string) []byte {
+
+	sealed, err := os.ReadFile(filepath.Join(v.userDir(email), vaultKeyFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			v.logger.Error("failed read vault key", zap.Error(err))
+		}
+		return nil
+	}
+
+	return sealed
+}
+
+// SaveSealedKey - Сохранение копии ключа хранилища пользователя email.
+// Ключ уже зашифрован мастер-паролем, поэтому хранится как есть.
+func (v *Vault) SaveSealedKey(email string, sealed []byte) error {
+
+	dir := v.userDir(email)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, vaultKeyFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, fileMode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// userDir - Каталог кэша пользователя email.
+func (v *Vault) userDir(email string) string {
+	user := sha256.Sum256([]byte(v.addr + "\n" + strings.TrimSpace(email)))
+	return filepath.Join(v.root, hex.EncodeToString(user[:]))
+}
+
+// Pending - Количество изменений, ожидающих отправки на сервер.
+func (v *Vault) Pending() int {
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return len(v.index.Queue)
+}
+
+// version - Версия записи в кэше.
+func (v *Vault) version(kind, meta string) (int64, bool) {
+	ver, ok := v.index.Versions[kind][meta]
+	return ver, ok
+}
+
+// put - Сохранение записи с версией ver.
+func (v *Vault) put(kind, meta string, data []byte, ver int64) error {
+
+	if len(v.dir) == 0 {
+		return errNotOpened
+	}
+
+	if err := v.writeFile(v.recordPath(kind, meta), data); err != nil {
+		return err
+	}
+
+	if v.index.Versions[kind] == nil {
+		v.index.Versions[kind] = make(map[string]int64)
+	}
+	v.index.Versions[kind][meta] = ver
+	delete(v.index.Large[kind], meta)
+
+	return v.saveIndex()
+}
+
+// large - Версия записи, не сохраненной в кэше из-за размера.
+func (v *Vault) large(kind, meta string) (int64, bool) {
+	ver, ok := v.index.Large[kind][meta]
+	return ver, ok
+}
+
+// putLarge - Удаление записи, слишком большой для кэша, с сохранением
+// ее версии ver на сервере. Версия 0 (неизвестна) не сохраняется.
+func (v *Vault) putLarge(kind, meta string, ver int64) error {
+
+	if err := v.remove(kind, meta); err != nil {
+		return err
+	}
+
+	if ver == 0 {
+		return nil
+	}
+
+	if v.index.Large == nil {
+		v.index.Large = make(map[string]map[string]int64)
+	}
+	if v.index.Large[kind] == nil {
+		v.index.Large[kind] = make(map[string]int64)
+	}
+	v.index.Large[kind][meta] = ver
+
+	return v.saveIndex()
+}
+
+// get - Чтение записи.
+func (v *Vault) get(kind, meta string) ([]byte, error) {
+
+	if len(v.dir) == 0 {
+		return nil, errNotOpened
+	}
+
+	if _, ok := v.version(kind, meta); !ok {
+		return nil, errs.ErrNotFound
+	}
+
+	return v.readFile(v.recordPath(kind, meta))
+}
+
+// remove - Удаление записи.
+func (v *Vault) remove(kind, meta string) error {
+
+	if len(v.dir) == 0 {
+		return errNotOpened
+	}
+
+	_, cached := v.version(kind, meta)
+	_, large := v.large(kind, meta)
+	if !cached && !large {
+		return nil
+	}
+
+	delete(v.index.Versions[kind], meta)
+	delete(v.index.Large[kind], meta)
+	if err := os.Remove(v.recordPath(kind, meta)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return v.saveIndex()
+}
+
+// metas - Метаинформация всех записей типа kind.
+func (v *Vault) metas(kind string) []string {
+
+	metas := make([]string, 0, len(v.index.Versions[kind]))
+	for meta := range v.index.Versions[kind] {
+		metas = append(metas, meta)
+	}
+
+	return metas
+}
+
+// largeMetas - Метаинформация записей типа kind, не сохраненных в кэше из-за размера.
+func (v *Vault) largeMetas(kind string) []string {
+
+	metas := make([]string, 0, len(v.index.Large[kind]))
+	for meta := range v.index.Large[kind] {
+		metas = append(metas, meta)
+	}
+
+	return metas
+}
+
+func (v *Vault) recordPath(kind, meta string) string {
+	name := sha256.Sum256([]byte(kind + "\n" + meta))
+	return filepath.Join(v.dir, hex.EncodeToString(name[:]))
+}
+
+func (v *Vault) saveIndex() error {
+
+	data, err := json.Marshal(v.index)
+	if err != nil {
+		return err
+	}
+
+	return v.writeFile(filepath.Join(v.dir, indexFile), data)
+}
+
+// writeFile - Запись зашифрованных данных через временный файл,
+// чтобы при сбое не остался поврежденный файл.
+func (v *Vault) writeFile(path string, data []byte) error {
+
+	enc, err := v.encrypt(data)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, enc, fileMode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (v *Vault) readFile(path string) ([]byte, error) {
+
+	enc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.decrypt(enc)
+}
+
+func (v *Vault) encrypt(data []byte) ([]byte, error) {
+
+	gcm, err := v.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (v *Vault) decrypt(data []byte) ([]byte, error) {
+
+	gcm, err := v.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("cache file is corrupted")
+	}
+
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+func (v *Vault) gcm() (cipher.AEAD, error) {
+
+	block, err := aes.NewCipher(v.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+const (
+	testEmail = "test@email.com"
+	testToken = "token"
+)
+
+var (
+	keyOnce    sync.Once
+	clientKeys [2]*rsa.PrivateKey
+)
+
+// testKeys - Закрытые ключи клиента, общие для всех тестов пакета.
+func testKeys() [2]*rsa.PrivateKey {
+
+	keyOnce.Do(func() {
+		for i := range clientKeys {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				panic(err)
+			}
+			clientKeys[i] = key
+		}
+	})
+
+	return clientKeys
+}
+
+// openVault - Открытый кэш в каталоге теста.
+func openVault(t *testing.T) *Vault {
+
+	v := NewVault(t.TempDir(), "localhost:3200")
+	require.NoError(t, v.Open(testEmail, testKeys()[0]))
+
+	return v
+}
+
+// fakeRemote - Сервер данных одного типа в памяти.
+type fakeRemote struct {
+	data     map[string][]byte
+	versions map[string]int64
+	// offline - Сервер недоступен
+	offline bool
+	// noVersions - Сервер не передает версии в списке
+	noVersions bool
+	// gets - Метаинформация запрошенных записей
+	gets []string
+}
+
+func newFakeRemote() *fakeRemote {
+	return &fakeRemote{
+		data:     make(map[string][]byte),
+		versions: make(map[string]int64),
+	}
+}
+
+func (r *fakeRemote) Create(meta string, data []byte, _ string) error {
+
+	if r.offline {
+		return errs.ErrUnavailable
+	}
+
+	if _, ok := r.versions[meta]; ok {
+		return errs.ErrAlreadyExist
+	}
+
+	r.data[meta] = data
+	r.versions[meta] = 1
+	return nil
+}
+
+func (r *fakeRemote) Get(meta string, _ string) ([]byte, int64, error) {
+
+	if r.offline {
+		return nil, 0, errs.ErrUnavailable
+	}
+
+	r.gets = append(r.gets, meta)
+
+	ver, ok := r.versions[meta]
+	if !ok {
+		return nil, 0, errs.ErrNotFound
+	}
+
+	return r.data[meta], ver, nil
+}
+
+func (r *fakeRemote) Delete(meta string, version int64, _ string) error {
+
+	if err := r.check(meta, version); err != nil {
+		return err
+	}
+
+	delete(r.data, meta)
+	delete(r.versions, meta)
+	return nil
+}
+
+func (r *fakeRemote) Change(meta string, data []byte, version int64, _ string) error {
+
+	if err := r.check(meta, version); err != nil {
+		return err
+	}
+
+	r.data[meta] = data
+	r.versions[meta]++
+	return nil
+}
+
+func (r *fakeRemote) List(filter list_model.Filter, _ string) (list_model.Page, error) {
+
+	if r.offline {
+		return list_model.Page{}, errs.ErrUnavailable
+	}
+
+	metas := make([]string, 0)
+	for meta := range r.versions {
+		if strings.HasPrefix(meta, filter.Prefix) &&
+			strings.Contains(meta, filter.Contains) &&
+			meta > filter.PageToken {
+			metas = append(metas, meta)
+		}
+	}
+	sort.Strings(metas)
+
+	var list list_model.Page
+	if filter.Limit > 0 && len(metas) > filter.Limit {
+		metas = metas[:filter.Limit]
+		list.NextPageToken = metas[filter.Limit-1]
+	}
+
+	list.MetaInfo = metas
+	if !r.noVersions {
+		for _, meta := range metas {
+			list.Versions = append(list.Versions, r.versions[meta])
+		}
+	}
+
+	return list, nil
+}
+
+// check - Проверка доступности сервера и ожидаемой версии записи.
+func (r *fakeRemote) check(meta string, version int64) error {
+
+	if r.offline {
+		return errs.ErrUnavailable
+	}
+
+	ver, ok := r.versions[meta]
+	if !ok {
+		return errs.ErrNotFound
+	}
+
+	if version != ver {
+		return errs.ErrConflict
+	}
+
+	return nil
+}
+
+func TestVault_Open(t *testing.T) {
+
+	root := t.TempDir()
+	keys := testKeys()
+
+	v := NewVault(root, "localhost:3200")
+	require.NoError(t, v.Open(testEmail, keys[0]))
+
+	kind := v.Kind("text", newFakeRemote())
+	require.ErrorIs(t, kind.Create("note", []byte("secret"), ""), errs.ErrQueued)
+
+	// Кэш открывается тем же ключом
+	same := NewVault(root, "localhost:3200")
+	require.NoError(t, same.Open(testEmail, keys[0]))
+	require.Equal(t, 1, same.Pending())
+
+	data, err := same.get("text", "note")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(data))
+
+	// Чужим ключом индекс кэша не расшифровывается
+	other := NewVault(root, "localhost:3200")
+	require.Error(t, other.Open(testEmail, keys[1]))
+
+	// Кэши разных серверов не смешиваются
+	server := NewVault(root, "localhost:3201")
+	require.NoError(t, server.Open(testEmail, keys[1]))
+	require.Zero(t, server.Pending())
+
+	require.Error(t, NewVault(root, "localhost:3200").Open(testEmail, nil))
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"GophKeeper/internal/client/model/auth_model"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+// loginView - Результат входа. В формате raw выводится токен доступа.
+type loginView struct {
+	Email string `json:"email"`
+	Token string `json:"token"`
+}
+
+func (v loginView) rows() [][]string {
+	return [][]string{{"FIELD", "VALUE"}, {"email", v.Email}, {"token", v.Token}}
+}
+
+func (v loginView) raw() []byte {
+	return []byte(v.Token)
+}
+
+// login - Авторизация и вывод токена доступа для следующих команд.
+// Пароль читается из --password-file, а если stdin - терминал, вводится без отображения.
+func (c *CLI) login(args []string) error {
+
+	fs := c.newFlagSet("login")
+	output := fs.String("output", formatTable, "output format: json, table or raw")
+	email := fs.String("email", c.getenv(envEmail), "email, default $"+envEmail)
+	password := fs.String("password-file", stdinSource, "file with the password, \"-\" - stdin")
+	otp := fs.String("otp", "", "two-factor code or recovery code")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return usageErrorf("login: unexpected arguments")
+	}
+
+	p, err := newPrinter(c.stdout, *output)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(*email)) == 0 {
+		return usageErrorf("login: --email or $%s is required", envEmail)
+	}
+
+	pwd, err := c.password(*password)
+	if err != nil {
+		return err
+	}
+
+	cred := auth_model.Credential{
+		Email:    strings.TrimSpace(*email),
+		Password: secret.GeneratePasswordHash(pwd, c.salt),
+	}
+
+	token, challenge, err := c.auth.SignIn(cred)
+	if err == nil && len(challenge) != 0 {
+		if len(*otp) == 0 {
+			return fmt.Errorf("%w: two-factor code is required, set --otp", errs.ErrUnauthenticated)
+		}
+		token, err = c.auth.VerifyOTP(challenge, *otp)
+	}
+
+	if errors.Is(err, errs.ErrInvalidArgument) || errors.Is(err, errs.ErrNotFound) {
+		return fmt.Errorf("%w: invalid email, password or code", errs.ErrUnauthenticated)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return p.print(loginView{Email: cred.Email, Token: token})
+}
+
+// password - Пароль из файла path или stdin. С терминала пароль
+// вводится без отображения на экране.
+func (c *CLI) password(path string) (string, error) {
+
+	if f, ok := c.stdin.(*os.File); ok && path == stdinSource && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(c.stderr, "Password: ")
+		pwd, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(c.stderr)
+		if err != nil {
+			return ``, err
+		}
+		return string(pwd), nil
+	}
+
+	return c.inputs().readText("password-file", path)
+}
+
+// logout - Завершение сессии токена доступа. Без токена завершается
+// сессия профиля, сохраненная интерактивным клиентом, и файл профиля удаляется.
+func (c *CLI) logout(args []string) error {
+
+	fs := c.newFlagSet("logout")
+	token := fs.String("token", "", "access token, default $"+envToken+" or the saved session of the profile")
+	cf := &common{}
+	fs.StringVar(&cf.masterFile, "master-file", "", "file with the master password, \"-\" - stdin")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return usageErrorf("logout: unexpected arguments")
+	}
+
+	if len(*token) == 0 {
+		*token = c.getenv(envToken)
+	}
+
+	if len(*token) != 0 {
+		return c.auth.Logout(*token)
+	}
+
+	if c.sessions == nil {
+		return errNoSession
+	}
+
+	if _, err = c.sessions.Load(); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errNoSession
+		}
+		return err
+	}
+
+	// Профиль удаляется, даже если сессию не удалось закрыть на сервере:
+	// без файла профиля ею уже не воспользоваться
+	access, err := c.resume(cf, c.inputs())
+	if err == nil {
+		err = c.auth.Logout(access)
+	}
+
+	if errRemove := c.sessions.Remove(); errRemove != nil {
+		return errRemove
+	}
+
+	if err != nil {
+		fmt.Fprintf(c.stderr, "warning: profile removed, but the session is not closed on the server: %s\n", err)
+	}
+
+	return nil
+}
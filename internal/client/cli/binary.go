@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"io"
+
+	"GophKeeper/internal/client/app_services/app_service_binary"
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+// binaryView - Сохраненный файл. В формате raw выводится путь файла.
+type binaryView struct {
+	header
+	File string                 `json:"file"`
+	Info *binary_model.FileInfo `json:"source,omitempty"`
+}
+
+func (v binaryView) rows() [][]string {
+
+	fields := []string{"file", v.File}
+	if v.Info != nil {
+		fields = append(fields, "source", v.Info.Name)
+	}
+
+	return v.header.rows(fields...)
+}
+
+func (v binaryView) raw() []byte {
+	return []byte(v.File)
+}
+
+// binary - Команды binary. Данные передаются между файлом и сервером потоком
+// и шифруются по частям, поэтому, в отличие от остальных типов, читаются
+// и сохраняются только файлами.
+func (c *CLI) binary(args []string) error {
+
+	if len(args) == 0 {
+		return usageErrorf("binary: subcommand is required: get, set, list or delete")
+	}
+
+	sub := args[0]
+	fs := c.newFlagSet("binary " + sub)
+	cf := c.commonFlags(fs)
+
+	var path *string
+	var filter list_model.Filter
+
+	switch sub {
+	case "get":
+		path = fs.String("out", "", "file or directory to save to, default - the original file name")
+
+	case "set":
+		path = fs.String("file", "", "file to upload")
+
+	case "list":
+		fs.StringVar(&filter.Prefix, "prefix", "", "meta starts with")
+		fs.StringVar(&filter.Contains, "contains", "", "meta contains")
+
+	case "delete":
+
+	default:
+		return usageErrorf("binary: unknown subcommand %q", sub)
+	}
+
+	positional, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if sub == "list" && len(positional) != 0 {
+		return usageErrorf("binary list: unexpected arguments")
+	}
+
+	var meta string
+	if sub != "list" {
+		if meta, err = requireMeta("binary "+sub, positional); err != nil {
+			return err
+		}
+	}
+
+	if sub == "set" && (len(*path) == 0 || *path == stdinSource) {
+		return usageErrorf("binary set: --file is required")
+	}
+
+	token, err := c.session(cf, c.inputs())
+	if err != nil {
+		return err
+	}
+
+	p, _ := newPrinter(c.stdout, cf.output)
+
+	switch sub {
+	case "get":
+		v, errGet := c.download(meta, *path, token)
+		if errGet != nil {
+			return errGet
+		}
+		return p.print(v)
+
+	case "set":
+		status, errSet := c.upload(meta, *path, token)
+		if errSet != nil {
+			return errSet
+		}
+		return p.print(statusView{Meta: meta, Status: status})
+
+	case "delete":
+		// Версия бинарных данных берется из списка метаинформации
+		version, errVer := app_service_binary.CurrentVersion(c.bin, meta, token)
+		if errVer != nil {
+			return errVer
+		}
+		if err = c.bin.Delete(meta, version, token); err != nil {
+			return err
+		}
+		return p.print(statusView{Meta: meta, Status: statusDeleted})
+	}
+
+	metas, err := listAll(c.bin.List, filter, token)
+	if err != nil {
+		return err
+	}
+
+	return p.print(listView(metas))
+}
+
+// download - Расшифровка и сохранение данных meta в файл path.
+func (c *CLI) download(meta, path, token string) (binaryView, error) {
+
+	var info binary_model.Info
+	saved, source, err := app_service_binary.SaveFile(path, meta, func(w io.Writer) error {
+
+		dec := secret.NewDecryptWriter(c.privateKey, w)
+
+		var errDownload error
+		if info, errDownload = c.bin.Download(meta, dec, token); errDownload != nil {
+			return errDownload
+		}
+
+		return dec.Close()
+	})
+
+	if err != nil {
+		return binaryView{}, err
+	}
+
+	return binaryView{
+		header: newHeader(meta, info.Version, info.UpdatedAt),
+		File:   saved,
+		Info:   source,
+	}, nil
+}
+
+// upload - Создание данных meta из файла path или, если они есть, их замена.
+func (c *CLI) upload(meta, path, token string) (string, error) {
+
+	if err := c.writable(); err != nil {
+		return ``, err
+	}
+
+	err := c.uploadFile(binary_model.Upload{MetaInfo: meta}, path, token)
+	if err == nil {
+		return statusCreated, nil
+	}
+
+	if !errors.Is(err, errs.ErrAlreadyExist) {
+		return ``, err
+	}
+
+	version, err := app_service_binary.CurrentVersion(c.bin, meta, token)
+	if err != nil {
+		return ``, err
+	}
+
+	in := binary_model.Upload{MetaInfo: meta, Overwrite: true, Version: version}
+	if err = c.uploadFile(in, path, token); err != nil {
+		return ``, err
+	}
+
+	return statusChanged, nil
+}
+
+// uploadFile - Шифрование и загрузка файла path.
+func (c *CLI) uploadFile(in binary_model.Upload, path, token string) error {
+
+	file, err := app_service_binary.OpenFile(path)
+	if err != nil {
+		if errors.Is(err, app_service_binary.ErrDirectory) {
+			return usageErrorf("--file: %s is a directory", path)
+		}
+		return err
+	}
+	defer file.Close()
+
+	return c.bin.Upload(in, secret.NewEncryptReader(c.publicKey, file), token)
+}
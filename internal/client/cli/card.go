@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"flag"
+
+	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/secret"
+)
+
+// cardKind - Команды card.
+type cardKind struct {
+	*CLI
+}
+
+// cardView - Расшифрованные данные карты. В формате raw выводится номер карты.
+type cardView struct {
+	header
+	Number string `json:"number"`
+	Period string `json:"period"`
+	CVV    string `json:"cvv"`
+	Holder string `json:"holder"`
+}
+
+func (v cardView) rows() [][]string {
+	return v.header.rows("number", v.Number, "period", v.Period, "cvv", v.CVV, "holder", v.Holder)
+}
+
+func (v cardView) raw() []byte {
+	return []byte(v.Number)
+}
+
+func (k cardKind) bind(fs *flag.FlagSet) reader {
+
+	number := fs.String("number", "", "card number")
+	period := fs.String("period", "", "expiration date, MM/YY")
+	holder := fs.String("holder", "", "card holder name")
+	cvv := fs.String("cvv-file", stdinSource, "file with the CVV, \"-\" - stdin")
+
+	return func(in *inputs) (record, error) {
+
+		for _, flagValue := range [][2]string{{"number", *number}, {"period", *period}, {"holder", *holder}} {
+			if len(flagValue[1]) == 0 {
+				return nil, usageErrorf("--%s is required", flagValue[0])
+			}
+		}
+
+		code, err := in.readText("cvv-file", *cvv)
+		if err != nil {
+			return nil, err
+		}
+
+		return record{
+			"number": []byte(*number),
+			"period": []byte(*period),
+			"cvv":    []byte(code),
+			"holder": []byte(*holder),
+		}, nil
+	}
+}
+
+func (k cardKind) get(meta, token string) (view, error) {
+
+	data, err := k.card.Get(meta, token)
+	if err != nil {
+		return nil, err
+	}
+
+	v := cardView{header: newHeader(meta, data.Version, data.UpdatedAt)}
+
+	for _, field := range []struct {
+		dst *string
+		src []byte
+	}{
+		{&v.Number, data.Number},
+		{&v.Period, data.Period},
+		{&v.CVV, data.CVV},
+		{&v.Holder, data.FullName},
+	} {
+		plain, errDec := secret.Decrypt(k.privateKey, field.src)
+		if errDec != nil {
+			return nil, errDec
+		}
+		*field.dst = string(plain)
+	}
+
+	return v, nil
+}
+
+func (k cardKind) version(meta, token string) (int64, error) {
+
+	data, err := k.card.Get(meta, token)
+	return data.Version, err
+}
+
+func (k cardKind) create(meta string, fields record, token string) error {
+
+	data, err := k.encode(meta, fields)
+	if err != nil {
+		return err
+	}
+
+	return k.card.Create(data, token)
+}
+
+func (k cardKind) change(meta string, fields record, version int64, token string) error {
+
+	data, err := k.encode(meta, fields)
+	if err != nil {
+		return err
+	}
+
+	data.Version = version
+	return k.card.Change(data, token)
+}
+
+func (k cardKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.card.List(filter, token)
+}
+
+func (k cardKind) remove(meta string, version int64, token string) error {
+	return k.card.Delete(meta, version, token)
+}
+
+// encode - Шифрование полей карты.
+func (k cardKind) encode(meta string, fields record) (card_model.Card, error) {
+
+	data := card_model.Card{MetaInfo: meta}
+
+	for _, field := range []struct {
+		dst  *[]byte
+		name string
+	}{
+		{&data.Number, "number"},
+		{&data.Period, "period"},
+		{&data.CVV, "cvv"},
+		{&data.FullName, "holder"},
+	} {
+		enc, err := secret.Encrypt(k.publicKey, fields[field.name])
+		if err != nil {
+			return card_model.Card{}, err
+		}
+		*field.dst = enc
+	}
+
+	return data, nil
+}
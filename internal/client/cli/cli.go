@@ -0,0 +1,387 @@
+// Package cli - Неинтерактивные команды клиента для скриптов и CI:
+// gophkeeper login, gophkeeper text get <meta>, gophkeeper cred set <meta> ...
+//
+// Результат выводится в stdout в формате --output (json, table или raw),
+// сообщения и ошибки - в stderr. Код завершения зависит от ошибки (см. ExitCode).
+// Данные передаются через реализации Sender из grpc_services, минуя локальный кэш.
+package cli
+
+import (
+	"crypto/rsa"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/client/app_services/app_service_binary"
+	"GophKeeper/internal/client/app_services/app_service_card"
+	"GophKeeper/internal/client/app_services/app_service_cred"
+	"GophKeeper/internal/client/app_services/app_service_text"
+	"GophKeeper/internal/client/model/auth_model"
+	"GophKeeper/internal/client/session"
+	"GophKeeper/pkg/errs"
+)
+
+const (
+	// envEmail - Email пользователя для login, если не задан флагом.
+	envEmail = "GOPHKEEPER_EMAIL"
+	// envToken - Токен доступа, если не задан флагом.
+	envToken = "GOPHKEEPER_TOKEN"
+	// envMaster - Мастер-пароль ключа хранилища, если не задан файлом.
+	envMaster = "GOPHKEEPER_MASTER_PASSWORD"
+)
+
+// AuthSender - Запросы авторизации, используемые командами.
+type AuthSender interface {
+	SignIn(auth_model.Credential) (string, string, error)
+	VerifyOTP(challenge, code string) (string, error)
+	Logout(token string) error
+	Refresh(refresh string) (string, error)
+	GetVaultKey(token string) ([]byte, error)
+}
+
+type Options func(c *CLI)
+
+// CLI - Неинтерактивные команды клиента.
+type CLI struct {
+	auth AuthSender
+	text app_service_text.Sender
+	cred app_service_cred.Sender
+	card app_service_card.Sender
+	bin  app_service_binary.Sender
+	// sessions - Сессия профиля, сохраненная интерактивным клиентом.
+	// Используется, если токен не задан.
+	sessions *session.Store
+
+	salt       string
+	publicKey  *rsa.PublicKey
+	privateKey *rsa.PrivateKey
+	// vaultKey - Данные шифруются ключом хранилища, который
+	// открывается мастер-паролем после авторизации.
+	vaultKey bool
+	// plaintext - Разрешена запись данных без шифрования.
+	plaintext bool
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	logger *zap.Logger
+}
+
+// New - Создание неинтерактивных команд клиента.
+func New(auth AuthSender, opts ...Options) *CLI {
+	c := &CLI{
+		auth:   auth,
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+		logger: zap.L(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithText - Команды text.
+func WithText(s app_service_text.Sender) Options {
+	return func(c *CLI) {
+		c.text = s
+	}
+}
+
+// WithCred - Команды cred.
+func WithCred(s app_service_cred.Sender) Options {
+	return func(c *CLI) {
+		c.cred = s
+	}
+}
+
+// WithCard - Команды card.
+func WithCard(s app_service_card.Sender) Options {
+	return func(c *CLI) {
+		c.card = s
+	}
+}
+
+// WithBinary - Команды binary.
+func WithBinary(s app_service_binary.Sender) Options {
+	return func(c *CLI) {
+		c.bin = s
+	}
+}
+
+// WithSessions - Сессия профиля для команд без токена.
+func WithSessions(store *session.Store) Options {
+	return func(c *CLI) {
+		c.sessions = store
+	}
+}
+
+// WithSalt - Соль хэширования пароля перед отправкой на сервер.
+func WithSalt(salt string) Options {
+	return func(c *CLI) {
+		c.salt = salt
+	}
+}
+
+// WithKeys - Ключи шифрования данных из файлов.
+func WithKeys(pub *rsa.PublicKey, priv *rsa.PrivateKey) Options {
+	return func(c *CLI) {
+		c.publicKey = pub
+		c.privateKey = priv
+	}
+}
+
+// WithVaultKey - Данные шифруются ключом хранилища с сервера,
+// мастер-пароль читается из --master-file или GOPHKEEPER_MASTER_PASSWORD.
+func WithVaultKey() Options {
+	return func(c *CLI) {
+		c.vaultKey = true
+	}
+}
+
+// WithPlaintext - Разрешение записи данных без шифрования (режим --insecure-plaintext).
+func WithPlaintext(allowed bool) Options {
+	return func(c *CLI) {
+		c.plaintext = allowed
+	}
+}
+
+// WithIO - Потоки ввода и вывода команд. По умолчанию - стандартные.
+func WithIO(stdin io.Reader, stdout, stderr io.Writer) Options {
+	return func(c *CLI) {
+		c.stdin = stdin
+		c.stdout = stdout
+		c.stderr = stderr
+	}
+}
+
+// Run - Выполнение команды args. Возвращает код завершения процесса.
+func (c *CLI) Run(args []string) int {
+
+	if len(args) == 0 {
+		c.usage()
+		return ExitUsage
+	}
+
+	var err error
+
+	switch args[0] {
+	case "login":
+		err = c.login(args[1:])
+
+	case "logout":
+		err = c.logout(args[1:])
+
+	case "text":
+		err = c.records(args[0], args[1:], textKind{c})
+
+	case "cred":
+		err = c.records(args[0], args[1:], credKind{c})
+
+	case "card":
+		err = c.records(args[0], args[1:], cardKind{c})
+
+	case "binary":
+		err = c.binary(args[1:])
+
+	case "password":
+		err = c.generatePassword(args[1:])
+
+	case "help", "-h", "--help":
+		c.usage()
+		return ExitOK
+
+	default:
+		err = usageErrorf("unknown command %q", args[0])
+	}
+
+	if err != nil {
+		var usage usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintf(c.stderr, "error: %s\nrun 'gophkeeper help' for usage\n", usage.msg)
+			return ExitUsage
+		}
+
+		fmt.Fprintf(c.stderr, "error: %s\n", err)
+	}
+
+	return ExitCode(err)
+}
+
+// usage - Справка по командам.
+func (c *CLI) usage() {
+	fmt.Fprint(c.stderr, `usage: gophkeeper [client flags] <command> [args] [flags]
+
+commands:
+  login  [--email E] [--password-file F] [--otp CODE]   sign in and print the access token
+  logout [--token T]                                   close the session of T or of the saved profile and remove the profile
+  text   get|set|list|delete
+  cred   get|set|list|delete
+  card   get|set|list|delete
+  binary get|set|list|delete
+  password [generator flags]                           print a generated password and its entropy
+
+  text set <meta> [--file F]
+  cred set <meta> --login L [--password-file F | --generate [generator flags]]
+  card set <meta> --number N --period MM/YY --holder NAME [--cvv-file F]
+  binary set <meta> --file F
+  binary get <meta> [--out F]                           save to F or a directory, default - the original file name
+  <kind> list [--prefix P] [--contains S]
+
+generator flags:
+  --length N                password length (default 20)
+  --no-symbols              letters and digits only
+  --allow-ambiguous         allow look-alike characters 0/O/o, 1/l/I
+  --words N                 passphrase of N words from the built-in wordlist
+  --separator S             passphrase word separator (default "-")
+
+common flags:
+  --output json|table|raw   output format (default table)
+  --token T                 access token, default $GOPHKEEPER_TOKEN or the session saved for -profile
+  --master-file F           master password of the vault key, default $GOPHKEEPER_MASTER_PASSWORD
+
+Secrets are read from a file or, when the file is "-", from stdin.
+`)
+}
+
+// common - Флаги, общие для команд с данными.
+type common struct {
+	output     string
+	token      string
+	masterFile string
+}
+
+// commonFlags - Регистрация общих флагов в fs.
+func (c *CLI) commonFlags(fs *flag.FlagSet) *common {
+
+	cf := &common{}
+	fs.StringVar(&cf.output, "output", formatTable, "output format: json, table or raw")
+	fs.StringVar(&cf.token, "token", "", "access token, default $"+envToken+" or the saved session of the profile")
+	fs.StringVar(&cf.masterFile, "master-file", "", "file with the master password, \"-\" - stdin")
+
+	return cf
+}
+
+// newFlagSet - Набор флагов команды name, ошибки разбора выводятся в stderr.
+func (c *CLI) newFlagSet(name string) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	return fs
+}
+
+// isSet - Флаг name задан в командной строке.
+func isSet(fs *flag.FlagSet, name string) bool {
+
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// parse - Разбор флагов, которые могут идти и до, и после позиционных аргументов.
+// Возвращает позиционные аргументы.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageErrorf("%s: %v", fs.Name(), err)
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// session - Токен доступа и ключи шифрования для команд с данными.
+// Если токен не задан, используется сессия профиля. Ключ хранилища
+// открывается мастер-паролем.
+func (c *CLI) session(cf *common, input *inputs) (string, error) {
+
+	if _, err := newPrinter(c.stdout, cf.output); err != nil {
+		return ``, err
+	}
+
+	token := cf.token
+	if len(token) == 0 {
+		token = c.getenv(envToken)
+	}
+
+	// Без токена используется сессия профиля
+	if len(token) == 0 {
+		return c.resume(cf, input)
+	}
+
+	if !c.vaultKey || c.privateKey != nil {
+		return token, nil
+	}
+
+	sealed, err := c.auth.GetVaultKey(token)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ``, fmt.Errorf("%w: vault key is not created yet, sign in interactively first", err)
+		}
+		return ``, err
+	}
+
+	if err = c.openVault(cf, input, sealed); err != nil {
+		return ``, err
+	}
+
+	return token, nil
+}
+
+// writable - Проверка, что данные будут зашифрованы перед отправкой.
+func (c *CLI) writable() error {
+
+	if c.publicKey != nil || c.plaintext {
+		return nil
+	}
+
+	return fmt.Errorf("%w: encryption is disabled, run with --insecure-plaintext to store plaintext", errs.ErrPlaintext)
+}
+
+// usageError - Неверные аргументы команды.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// requireMeta - Единственный позиционный аргумент - метаинформация.
+func requireMeta(cmd string, args []string) (string, error) {
+
+	if len(args) != 1 || len(strings.TrimSpace(args[0])) == 0 {
+		return ``, usageErrorf("%s: exactly one <meta> argument is required", cmd)
+	}
+
+	return args[0], nil
+}
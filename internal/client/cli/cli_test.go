@@ -0,0 +1,631 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/client/app_services/app_service_binary"
+	"GophKeeper/internal/client/app_services/app_service_cred"
+	"GophKeeper/internal/client/app_services/app_service_text"
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/internal/client/session"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+const testToken = "token"
+
+// testKey - Ключ шифрования данных для тестов, генерируется один раз.
+var testKey = func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// updatedAt - Время изменения данных в заглушках.
+var updatedAt = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+// pages - Постраничный список metas по pageSize записей с фильтром filter.
+func pages(metas []string, filter list_model.Filter, pageSize int) list_model.Page {
+
+	var found []string
+	for _, meta := range metas {
+		if strings.HasPrefix(meta, filter.Prefix) && strings.Contains(meta, filter.Contains) {
+			found = append(found, meta)
+		}
+	}
+	sort.Strings(found)
+
+	start := 0
+	if len(filter.PageToken) != 0 {
+		fmt.Sscan(filter.PageToken, &start)
+	}
+
+	end := start + pageSize
+	if end >= len(found) {
+		return list_model.Page{MetaInfo: found[start:]}
+	}
+
+	return list_model.Page{MetaInfo: found[start:end], NextPageToken: fmt.Sprint(end)}
+}
+
+// stubText - Текстовые данные в памяти. Список выдается по две записи.
+type stubText struct {
+	app_service_text.Sender
+	data map[string]text_model.Text
+}
+
+func (s *stubText) Get(meta, token string) (text_model.Text, error) {
+	if token != testToken {
+		return text_model.Text{}, errs.ErrUnauthenticated
+	}
+	data, ok := s.data[meta]
+	if !ok {
+		return text_model.Text{}, errs.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *stubText) Create(data text_model.Text, _ string) error {
+	if _, ok := s.data[data.MetaInfo]; ok {
+		return errs.ErrAlreadyExist
+	}
+	data.Version = 1
+	s.data[data.MetaInfo] = data
+	return nil
+}
+
+func (s *stubText) Change(data text_model.Text, _ string) error {
+	if s.data[data.MetaInfo].Version != data.Version {
+		return errs.ErrConflict
+	}
+	data.Version++
+	s.data[data.MetaInfo] = data
+	return nil
+}
+
+func (s *stubText) Delete(meta string, version int64, _ string) error {
+	if s.data[meta].Version != version {
+		return errs.ErrConflict
+	}
+	delete(s.data, meta)
+	return nil
+}
+
+func (s *stubText) List(filter list_model.Filter, _ string) (list_model.Page, error) {
+	metas := make([]string, 0, len(s.data))
+	for meta := range s.data {
+		metas = append(metas, meta)
+	}
+	return pages(metas, filter, 2), nil
+}
+
+// stubCred - Логин и пароль в памяти.
+type stubCred struct {
+	app_service_cred.Sender
+	data map[string]cred_model.Credential
+}
+
+func (s *stubCred) Get(meta, _ string) (cred_model.Credential, error) {
+	data, ok := s.data[meta]
+	if !ok {
+		return cred_model.Credential{}, errs.ErrNotFound
+	}
+	return data, nil
+}
+
+// stubBinary - Бинарные данные в памяти.
+type stubBinary struct {
+	app_service_binary.Sender
+	data    map[string][]byte
+	version map[string]int64
+}
+
+func (s *stubBinary) Upload(in binary_model.Upload, r io.Reader, _ string) error {
+
+	_, exists := s.data[in.MetaInfo]
+	switch {
+	case in.Overwrite && !exists:
+		return errs.ErrNotFound
+	case !in.Overwrite && exists:
+		return errs.ErrAlreadyExist
+	case in.Overwrite && s.version[in.MetaInfo] != in.Version:
+		return errs.ErrConflict
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.data[in.MetaInfo] = data
+	s.version[in.MetaInfo]++
+	return nil
+}
+
+func (s *stubBinary) Download(meta string, w io.Writer, _ string) (binary_model.Info, error) {
+	data, ok := s.data[meta]
+	if !ok {
+		return binary_model.Info{}, errs.ErrNotFound
+	}
+	if _, err := w.Write(data); err != nil {
+		return binary_model.Info{}, err
+	}
+	return binary_model.Info{MetaInfo: meta, Version: s.version[meta], UpdatedAt: updatedAt}, nil
+}
+
+func (s *stubBinary) Delete(meta string, version int64, _ string) error {
+	if _, ok := s.data[meta]; !ok {
+		return errs.ErrNotFound
+	}
+	if s.version[meta] != version {
+		return errs.ErrConflict
+	}
+	delete(s.data, meta)
+	delete(s.version, meta)
+	return nil
+}
+
+func (s *stubBinary) List(filter list_model.Filter, _ string) (list_model.Page, error) {
+	metas := make([]string, 0, len(s.data))
+	for meta := range s.data {
+		metas = append(metas, meta)
+	}
+
+	list := pages(metas, filter, 2)
+	for _, meta := range list.MetaInfo {
+		list.Versions = append(list.Versions, s.version[meta])
+	}
+	return list, nil
+}
+
+// stubAuth - Сервер авторизации. Refresh выдает testToken и, как перехватчик
+// соединения клиента, передает новые токены в onUpdate.
+type stubAuth struct {
+	AuthSender
+	refresh  string
+	onUpdate func(access, refresh string)
+	closed   []string
+}
+
+func (s *stubAuth) Refresh(refresh string) (string, error) {
+	if refresh != s.refresh {
+		return ``, errs.ErrUnauthenticated
+	}
+
+	s.refresh = "rotated-" + refresh
+	if s.onUpdate != nil {
+		s.onUpdate(testToken, s.refresh)
+	}
+
+	return testToken, nil
+}
+
+func (s *stubAuth) Logout(token string) error {
+	s.closed = append(s.closed, token)
+	return nil
+}
+
+// testCLI - Команды с заглушками и результат их выполнения.
+type testCLI struct {
+	*CLI
+	auth   *stubAuth
+	text   *stubText
+	cred   *stubCred
+	bin    *stubBinary
+	stdin  *bytes.Buffer
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func newTestCLI(t *testing.T, opts ...Options) *testCLI {
+
+	encrypt := func(plain string) []byte {
+		data, err := secret.Encrypt(&testKey.PublicKey, []byte(plain))
+		require.NoError(t, err)
+		return data
+	}
+
+	tc := &testCLI{
+		text: &stubText{data: map[string]text_model.Text{
+			"note": {MetaInfo: "note", Data: encrypt("hello\nworld"), Version: 3, UpdatedAt: updatedAt},
+		}},
+		cred: &stubCred{data: map[string]cred_model.Credential{
+			"mail": {MetaInfo: "mail", Login: encrypt("user"), Password: encrypt("p@ss"), Version: 1},
+		}},
+		bin:    &stubBinary{data: make(map[string][]byte), version: make(map[string]int64)},
+		auth:   &stubAuth{},
+		stdin:  &bytes.Buffer{},
+		stdout: &bytes.Buffer{},
+		stderr: &bytes.Buffer{},
+	}
+
+	opts = append([]Options{
+		WithText(tc.text),
+		WithCred(tc.cred),
+		WithBinary(tc.bin),
+		WithKeys(&testKey.PublicKey, testKey),
+		WithIO(tc.stdin, tc.stdout, tc.stderr),
+	}, opts...)
+
+	tc.CLI = New(tc.auth, opts...)
+	tc.CLI.getenv = func(string) string { return `` }
+
+	return tc
+}
+
+// run - Выполнение команды с токеном доступа.
+func (tc *testCLI) run(args ...string) int {
+	tc.stdout.Reset()
+	tc.stderr.Reset()
+	return tc.Run(append(args, "--token", testToken))
+}
+
+func TestExitCode(t *testing.T) {
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("unknown"), ExitInternal},
+		{errs.ErrInternal, ExitInternal},
+		{usageErrorf("bad flag"), ExitUsage},
+		{errs.ErrNotFound, ExitNotFound},
+		{errs.ErrAlreadyExist, ExitAlreadyExist},
+		{fs.ErrExist, ExitAlreadyExist},
+		{errs.ErrConflict, ExitConflict},
+		{errs.ErrUnauthenticated, ExitUnauthenticated},
+		{secret.ErrWrongPassword, ExitUnauthenticated},
+		{errs.ErrUnavailable, ExitUnavailable},
+		{errs.ErrTooManyAttempts, ExitTooManyAttempts},
+		{errs.ErrInvalidArgument, ExitInvalidArgument},
+		{errs.ErrPlaintext, ExitPlaintext},
+		{errs.ErrLargeData, ExitLargeData},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, ExitCode(tt.err), "%v", tt.err)
+
+		// Коды не зависят от пояснений, добавленных к ошибке
+		if tt.err != nil {
+			require.Equal(t, tt.want, ExitCode(fmt.Errorf("context: %w", tt.err)), "wrapped %v", tt.err)
+		}
+	}
+}
+
+func TestRun_Get(t *testing.T) {
+
+	tc := newTestCLI(t)
+
+	require.Equal(t, ExitOK, tc.run("text", "get", "note", "--output", "json"))
+
+	var text struct {
+		Meta      string    `json:"meta"`
+		Version   int64     `json:"version"`
+		UpdatedAt time.Time `json:"updated_at"`
+		Text      string    `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal(tc.stdout.Bytes(), &text))
+	require.Equal(t, "note", text.Meta)
+	require.Equal(t, int64(3), text.Version)
+	require.True(t, updatedAt.Equal(text.UpdatedAt))
+	require.Equal(t, "hello\nworld", text.Text)
+
+	// Флаги могут идти перед позиционными аргументами
+	require.Equal(t, ExitOK, tc.run("text", "get", "--output", "raw", "note"))
+	require.Equal(t, "hello\nworld\n", tc.stdout.String())
+
+	require.Equal(t, ExitOK, tc.run("text", "get", "note"))
+	lines := strings.Split(strings.TrimSpace(tc.stdout.String()), "\n")
+	require.Equal(t, []string{"FIELD", "VALUE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"meta", "note"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"text", "hello"}, strings.Fields(lines[2]))
+	require.Equal(t, []string{"version", "3"}, strings.Fields(lines[4]))
+
+	require.Equal(t, ExitOK, tc.run("cred", "get", "mail", "--output", "raw"))
+	require.Equal(t, "p@ss\n", tc.stdout.String())
+
+	require.Equal(t, ExitOK, tc.run("cred", "get", "mail", "--output", "json"))
+	require.JSONEq(t, `{"meta": "mail", "version": 1, "login": "user", "password": "p@ss"}`, tc.stdout.String())
+}
+
+func TestRun_List(t *testing.T) {
+
+	tc := newTestCLI(t)
+	for _, meta := range []string{"b", "c", "a", "other"} {
+		tc.text.data[meta] = text_model.Text{MetaInfo: meta}
+	}
+
+	// Все страницы списка
+	require.Equal(t, ExitOK, tc.run("text", "list", "--output", "json"))
+	require.JSONEq(t, `["a", "b", "c", "note", "other"]`, tc.stdout.String())
+
+	require.Equal(t, ExitOK, tc.run("text", "list", "--output", "raw", "--contains", "o"))
+	require.Equal(t, "note\nother\n", tc.stdout.String())
+
+	require.Equal(t, ExitOK, tc.run("text", "list", "--prefix", "o"))
+	require.Equal(t, "META\nother\n", tc.stdout.String())
+
+	// Пустой список в JSON - массив, а не null
+	require.Equal(t, ExitOK, tc.run("text", "list", "--output", "json", "--prefix", "none"))
+	require.JSONEq(t, `[]`, tc.stdout.String())
+
+	require.Equal(t, ExitOK, tc.run("text", "list", "--output", "raw", "--prefix", "none"))
+	require.Empty(t, tc.stdout.String())
+}
+
+func TestRun_Set(t *testing.T) {
+
+	tc := newTestCLI(t)
+
+	tc.stdin.WriteString("first")
+	require.Equal(t, ExitOK, tc.run("text", "set", "new", "--output", "json"))
+	require.JSONEq(t, `{"meta": "new", "status": "created"}`, tc.stdout.String())
+
+	plain, err := secret.Decrypt(testKey, tc.text.data["new"].Data)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(plain))
+
+	tc.stdin.WriteString("second")
+	require.Equal(t, ExitOK, tc.run("text", "set", "new", "--output", "json"))
+	require.JSONEq(t, `{"meta": "new", "status": "changed"}`, tc.stdout.String())
+	require.Equal(t, int64(2), tc.text.data["new"].Version)
+
+	require.Equal(t, ExitOK, tc.run("text", "delete", "new", "--output", "raw"))
+	require.Empty(t, tc.stdout.String())
+	require.NotContains(t, tc.text.data, "new")
+}
+
+func TestRun_Errors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "no command", want: ExitUsage},
+		{name: "unknown command", args: []string{"files"}, want: ExitUsage},
+		{name: "unknown subcommand", args: []string{"text", "show", "note"}, want: ExitUsage},
+		{name: "unknown format", args: []string{"text", "get", "note", "--output", "xml"}, want: ExitUsage},
+		{name: "missing meta", args: []string{"text", "get"}, want: ExitUsage},
+		{name: "unknown flag", args: []string{"text", "get", "note", "--verbose"}, want: ExitUsage},
+		{name: "not found", args: []string{"text", "get", "missing"}, want: ExitNotFound},
+		{name: "binary without file", args: []string{"binary", "set", "doc"}, want: ExitUsage},
+		{name: "binary not found", args: []string{"binary", "get", "missing"}, want: ExitNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tc := newTestCLI(t)
+			args := tt.args
+			if len(args) != 0 {
+				args = append(args, "--token", testToken)
+			}
+
+			require.Equal(t, tt.want, tc.Run(args))
+			require.Empty(t, tc.stdout.String())
+			require.NotEmpty(t, tc.stderr.String())
+		})
+	}
+
+	tc := newTestCLI(t)
+
+	// Без токена
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"text", "get", "note"}))
+	require.Contains(t, tc.stderr.String(), "--token")
+
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"text", "get", "note", "--token", "other"}))
+
+	// Без ключа шифрования запись запрещена
+	tc = newTestCLI(t, WithKeys(nil, nil))
+	tc.stdin.WriteString("text")
+	require.Equal(t, ExitPlaintext, tc.run("text", "set", "new"))
+	require.NotContains(t, tc.text.data, "new")
+}
+
+func TestRun_Binary(t *testing.T) {
+
+	tc := newTestCLI(t)
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "report.pdf")
+	content := bytes.Repeat([]byte("binary data "), 10000)
+	require.NoError(t, os.WriteFile(src, content, 0640))
+
+	require.Equal(t, ExitOK, tc.run("binary", "set", "report", "--file", src, "--output", "json"))
+	require.JSONEq(t, `{"meta": "report", "status": "created"}`, tc.stdout.String())
+	require.True(t, secret.IsEnvelope(tc.bin.data["report"]))
+
+	require.Equal(t, ExitOK, tc.run("binary", "set", "report", "--file", src, "--output", "json"))
+	require.JSONEq(t, `{"meta": "report", "status": "changed"}`, tc.stdout.String())
+	require.Equal(t, int64(2), tc.bin.version["report"])
+
+	// В каталог сохраняется файл с исходным именем
+	out := t.TempDir()
+	require.Equal(t, ExitOK, tc.run("binary", "get", "report", "--out", out, "--output", "raw"))
+	saved := filepath.Join(out, "report.pdf")
+	require.Equal(t, saved+"\n", tc.stdout.String())
+
+	data, err := os.ReadFile(saved)
+	require.NoError(t, err)
+	require.Equal(t, content, data)
+
+	stat, err := os.Stat(saved)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+
+	// Существующий файл не перезаписывается
+	require.Equal(t, ExitAlreadyExist, tc.run("binary", "get", "report", "--out", saved))
+
+	copyPath := filepath.Join(out, "copy.pdf")
+	require.Equal(t, ExitOK, tc.run("binary", "get", "report", "--out", copyPath, "--output", "json"))
+
+	var view struct {
+		Meta    string                 `json:"meta"`
+		Version int64                  `json:"version"`
+		File    string                 `json:"file"`
+		Source  *binary_model.FileInfo `json:"source"`
+	}
+	require.NoError(t, json.Unmarshal(tc.stdout.Bytes(), &view))
+	require.Equal(t, "report", view.Meta)
+	require.Equal(t, int64(2), view.Version)
+	require.Equal(t, copyPath, view.File)
+	require.Equal(t, &binary_model.FileInfo{Name: "report.pdf", Size: int64(len(content)), Mode: 0640}, view.Source)
+
+	require.Equal(t, ExitOK, tc.run("binary", "list", "--output", "raw"))
+	require.Equal(t, "report\n", tc.stdout.String())
+
+	require.Equal(t, ExitUsage, tc.run("binary", "set", "dir", "--file", dir))
+
+	require.Equal(t, ExitOK, tc.run("binary", "delete", "report"))
+	require.Equal(t, "META    STATUS\nreport  deleted\n", tc.stdout.String())
+	require.Empty(t, tc.bin.data)
+}
+
+// savedSession - Сессия профиля, сохраненная интерактивным клиентом с ключом key.
+func savedSession(t *testing.T, tc *testCLI, key *rsa.PrivateKey, sealed []byte) (*session.Store, string) {
+
+	dir := t.TempDir()
+
+	store, err := session.NewStore(dir, "work", "localhost:3200")
+	require.NoError(t, err)
+
+	store.Update("expired access", "refresh")
+	require.NoError(t, store.Save("user@mail.ru", sealed, key))
+
+	tc.auth.refresh = "refresh"
+	tc.auth.onUpdate = store.Update
+	tc.sessions = store
+
+	return store, filepath.Join(dir, "work.json")
+}
+
+func TestRun_SavedSession(t *testing.T) {
+
+	tc := newTestCLI(t)
+
+	// Без токена и сохраненной сессии
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"text", "get", "note"}))
+
+	store, _ := savedSession(t, tc, testKey, nil)
+
+	require.Equal(t, ExitOK, tc.Run([]string{"text", "get", "note", "--output", "raw"}))
+	require.Equal(t, "hello\nworld\n", tc.stdout.String())
+
+	// Одноразовый refresh token заменен новым в профиле
+	access, refresh, err := store.Unlock(testKey)
+	require.NoError(t, err)
+	require.Equal(t, testToken, access)
+	require.Equal(t, "rotated-refresh", refresh)
+
+	// Следующая команда продолжает сессию с новым refresh token
+	tc.stdout.Reset()
+	require.Equal(t, ExitOK, tc.Run([]string{"text", "list", "--output", "raw"}))
+	require.Equal(t, "note\n", tc.stdout.String())
+
+	// Сессия закрыта на сервере - профиль недействителен
+	tc.auth.refresh = "other"
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"text", "get", "note"}))
+	require.Contains(t, tc.stderr.String(), "sign in again")
+}
+
+func TestRun_SavedSessionVaultKey(t *testing.T) {
+
+	const master = "correct horse battery staple"
+
+	vaultKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	sealed, err := secret.SealVaultKey(master, vaultKey, secret.KDFParams{Time: 1, Memory: 1024, Threads: 1})
+	require.NoError(t, err)
+
+	tc := newTestCLI(t, WithKeys(nil, nil), WithVaultKey())
+	savedSession(t, tc, vaultKey, sealed)
+
+	// Токены сессии зашифрованы ключом хранилища: без мастер-пароля ее не открыть
+	require.Equal(t, ExitUsage, tc.Run([]string{"text", "list"}))
+	require.Contains(t, tc.stderr.String(), "master password")
+
+	tc.getenv = func(name string) string {
+		if name == envMaster {
+			return master
+		}
+		return ``
+	}
+
+	tc.stdin.WriteString("text")
+	require.Equal(t, ExitOK, tc.Run([]string{"text", "set", "new", "--output", "raw"}))
+
+	plain, err := secret.Decrypt(vaultKey, tc.text.data["new"].Data)
+	require.NoError(t, err)
+	require.Equal(t, "text", string(plain))
+}
+
+func TestRun_Logout(t *testing.T) {
+
+	tc := newTestCLI(t)
+
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"logout"}))
+
+	// Токен задан явно - профиль не затрагивается
+	_, path := savedSession(t, tc, testKey, nil)
+	require.Equal(t, ExitOK, tc.Run([]string{"logout", "--token", "explicit"}))
+	require.Equal(t, []string{"explicit"}, tc.auth.closed)
+	require.FileExists(t, path)
+
+	require.Equal(t, ExitOK, tc.Run([]string{"logout"}))
+	require.Equal(t, []string{"explicit", testToken}, tc.auth.closed)
+	require.NoFileExists(t, path)
+
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"logout"}))
+	require.Equal(t, ExitUnauthenticated, tc.Run([]string{"text", "get", "note"}))
+
+	// Сессия, которую не удалось продолжить, все равно удаляется
+	_, path = savedSession(t, tc, testKey, nil)
+	tc.auth.refresh = "other"
+	tc.stderr.Reset()
+	require.Equal(t, ExitOK, tc.Run([]string{"logout"}))
+	require.Contains(t, tc.stderr.String(), "warning")
+	require.NoFileExists(t, path)
+}
+
+func TestRun_Password(t *testing.T) {
+
+	tc := newTestCLI(t)
+
+	require.Equal(t, ExitOK, tc.Run([]string{"password", "--words", "5", "--separator", " ", "--output", "json"}))
+
+	var view passwordView
+	require.NoError(t, json.Unmarshal(tc.stdout.Bytes(), &view))
+	require.Len(t, strings.Split(view.Password, " "), 5, view.Password)
+	require.Equal(t, 55, view.Entropy)
+
+	tc.stdout.Reset()
+	require.Equal(t, ExitOK, tc.Run([]string{"password", "--length", "16", "--no-symbols", "--output", "raw"}))
+	password := strings.TrimSuffix(tc.stdout.String(), "\n")
+	require.Len(t, password, 16)
+	require.False(t, strings.ContainsAny(password, "0Oo1lI!#$%&"), password)
+
+	require.Equal(t, ExitUsage, tc.Run([]string{"password", "--words", "2"}))
+	require.Equal(t, ExitUsage, tc.Run([]string{"password", "--length", "3"}))
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"flag"
+
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/secret"
+)
+
+// credKind - Команды cred.
+type credKind struct {
+	*CLI
+}
+
+// credView - Расшифрованные логин и пароль. В формате raw выводится пароль.
+type credView struct {
+	header
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+func (v credView) rows() [][]string {
+	return v.header.rows("login", v.Login, "password", v.Password)
+}
+
+func (v credView) raw() []byte {
+	return []byte(v.Password)
+}
+
+func (k credKind) bind(fs *flag.FlagSet) reader {
+
+	login := fs.String("login", "", "login")
+	password := fs.String("password-file", stdinSource, "file with the password, \"-\" - stdin")
+	generate := fs.Bool("generate", false, "generate the password instead of reading it")
+	g := generatorFlags(fs)
+
+	return func(in *inputs) (record, error) {
+
+		if len(*login) == 0 {
+			return nil, usageErrorf("--login is required")
+		}
+
+		if *generate {
+			if isSet(fs, "password-file") {
+				return nil, usageErrorf("--generate and --password-file are mutually exclusive")
+			}
+
+			gen, err := g.generate()
+			if err != nil {
+				return nil, err
+			}
+			k.reportEntropy(gen)
+
+			return record{"login": []byte(*login), "password": []byte(gen.Value)}, nil
+		}
+
+		pwd, err := in.readText("password-file", *password)
+		if err != nil {
+			return nil, err
+		}
+
+		return record{"login": []byte(*login), "password": []byte(pwd)}, nil
+	}
+}
+
+func (k credKind) get(meta, token string) (view, error) {
+
+	data, err := k.cred.Get(meta, token)
+	if err != nil {
+		return nil, err
+	}
+
+	login, err := secret.Decrypt(k.privateKey, data.Login)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := secret.Decrypt(k.privateKey, data.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return credView{
+		header:   newHeader(meta, data.Version, data.UpdatedAt),
+		Login:    string(login),
+		Password: string(password),
+	}, nil
+}
+
+func (k credKind) version(meta, token string) (int64, error) {
+
+	data, err := k.cred.Get(meta, token)
+	return data.Version, err
+}
+
+func (k credKind) create(meta string, fields record, token string) error {
+
+	data, err := k.encode(meta, fields)
+	if err != nil {
+		return err
+	}
+
+	return k.cred.Create(data, token)
+}
+
+func (k credKind) change(meta string, fields record, version int64, token string) error {
+
+	data, err := k.encode(meta, fields)
+	if err != nil {
+		return err
+	}
+
+	data.Version = version
+	return k.cred.Change(data, token)
+}
+
+func (k credKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.cred.List(filter, token)
+}
+
+func (k credKind) remove(meta string, version int64, token string) error {
+	return k.cred.Delete(meta, version, token)
+}
+
+// encode - Шифрование логина и пароля.
+func (k credKind) encode(meta string, fields record) (cred_model.Credential, error) {
+
+	login, err := secret.Encrypt(k.publicKey, fields["login"])
+	if err != nil {
+		return cred_model.Credential{}, err
+	}
+
+	password, err := secret.Encrypt(k.publicKey, fields["password"])
+	if err != nil {
+		return cred_model.Credential{}, err
+	}
+
+	return cred_model.Credential{MetaInfo: meta, Login: login, Password: password}, nil
+}
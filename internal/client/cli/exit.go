@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"io/fs"
+
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+// Коды завершения команд.
+const (
+	ExitOK = 0
+	// ExitInternal - Внутренняя или неизвестная ошибка.
+	ExitInternal = 1
+	// ExitUsage - Неверные аргументы команды.
+	ExitUsage = 2
+	// ExitNotFound - Данные или пользователь не найдены.
+	ExitNotFound = 3
+	// ExitAlreadyExist - Данные, пользователь или сохраняемый файл уже существуют.
+	ExitAlreadyExist = 4
+	// ExitConflict - Данные изменены на другом устройстве.
+	ExitConflict = 5
+	// ExitUnauthenticated - Нет токена, токен недействителен, неверный пароль или код.
+	ExitUnauthenticated = 6
+	// ExitUnavailable - Сервер недоступен.
+	ExitUnavailable = 7
+	// ExitTooManyAttempts - Слишком много неудачных попыток входа.
+	ExitTooManyAttempts = 8
+	// ExitInvalidArgument - Сервер отклонил данные запроса.
+	ExitInvalidArgument = 9
+	// ExitPlaintext - Запись без шифрования запрещена клиентом или сервером.
+	ExitPlaintext = 10
+	// ExitLargeData - Размер данных слишком большой.
+	ExitLargeData = 11
+)
+
+// ExitCode - Код завершения для ошибки err.
+func ExitCode(err error) int {
+
+	switch {
+	case err == nil:
+		return ExitOK
+
+	case errors.As(err, new(usageError)):
+		return ExitUsage
+
+	case errors.Is(err, errs.ErrNotFound):
+		return ExitNotFound
+
+	case errors.Is(err, errs.ErrAlreadyExist), errors.Is(err, fs.ErrExist):
+		return ExitAlreadyExist
+
+	case errors.Is(err, errs.ErrConflict):
+		return ExitConflict
+
+	case errors.Is(err, errs.ErrUnauthenticated), errors.Is(err, secret.ErrWrongPassword):
+		return ExitUnauthenticated
+
+	case errors.Is(err, errs.ErrUnavailable):
+		return ExitUnavailable
+
+	case errors.Is(err, errs.ErrTooManyAttempts):
+		return ExitTooManyAttempts
+
+	case errors.Is(err, errs.ErrInvalidArgument):
+		return ExitInvalidArgument
+
+	case errors.Is(err, errs.ErrPlaintext):
+		return ExitPlaintext
+
+	case errors.Is(err, errs.ErrLargeData):
+		return ExitLargeData
+	}
+
+	return ExitInternal
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// stdinSource - Имя файла, вместо которого читается stdin.
+const stdinSource = "-"
+
+// inputs - Чтение секретов из файлов и stdin.
+// Stdin читается целиком, поэтому из него можно взять только один секрет.
+type inputs struct {
+	stdin io.Reader
+	// stdinBy - Флаг, для которого уже прочитан stdin.
+	stdinBy string
+}
+
+func (c *CLI) inputs() *inputs {
+	return &inputs{stdin: c.stdin}
+}
+
+// read - Секрет флага name из файла path или stdin, если path равен "-".
+// Завершающий перевод строки не входит в секрет.
+func (in *inputs) read(name, path string) ([]byte, error) {
+
+	data, err := in.readAll(name, path)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+
+	return data, nil
+}
+
+// readAll - Содержимое файла path или stdin, если path равен "-".
+func (in *inputs) readAll(name, path string) ([]byte, error) {
+
+	var data []byte
+	var err error
+
+	if path == stdinSource {
+		if len(in.stdinBy) != 0 {
+			return nil, usageErrorf("--%s: stdin is already used by --%s", name, in.stdinBy)
+		}
+		in.stdinBy = name
+
+		data, err = ioutil.ReadAll(in.stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", name, err)
+	}
+
+	return data, nil
+}
+
+// readText - Секрет в виде строки, пустой секрет - ошибка.
+func (in *inputs) readText(name, path string) (string, error) {
+
+	data, err := in.read(name, path)
+	if err != nil {
+		return ``, err
+	}
+
+	if len(data) == 0 {
+		return ``, usageErrorf("--%s: value is empty", name)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Форматы вывода результата.
+const (
+	// formatJSON - Результат в JSON.
+	formatJSON = "json"
+	// formatTable - Поля результата в столбцах для чтения человеком.
+	formatTable = "table"
+	// formatRaw - Только основное значение без оформления, для подстановки в скрипты.
+	formatRaw = "raw"
+)
+
+// view - Результат команды.
+type view interface {
+	// rows - Строки таблицы, первая - заголовок.
+	rows() [][]string
+	// raw - Основное значение результата.
+	raw() []byte
+}
+
+// printer - Вывод результата в выбранном формате.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+
+	switch format {
+	case formatJSON, formatTable, formatRaw:
+		return &printer{w: w, format: format}, nil
+	}
+
+	return nil, usageErrorf("--output: unknown format %q, want json, table or raw", format)
+}
+
+// print - Вывод результата v.
+func (p *printer) print(v view) error {
+
+	switch p.format {
+	case formatJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case formatRaw:
+		data := v.raw()
+		if len(data) == 0 {
+			return nil
+		}
+
+		if _, err := p.w.Write(data); err != nil {
+			return err
+		}
+
+		if data[len(data)-1] != '\n' {
+			_, err := fmt.Fprintln(p.w)
+			return err
+		}
+
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	for _, row := range v.rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// listView - Список метаинформации.
+type listView []string
+
+func (v listView) rows() [][]string {
+
+	rows := make([][]string, 0, len(v)+1)
+	rows = append(rows, []string{"META"})
+	for _, meta := range v {
+		rows = append(rows, []string{meta})
+	}
+
+	return rows
+}
+
+func (v listView) raw() []byte {
+
+	var data []byte
+	for _, meta := range v {
+		data = append(data, meta...)
+		data = append(data, '\n')
+	}
+
+	return data
+}
+
+func (v listView) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(v))
+}
+
+// Результаты изменения данных.
+const (
+	statusCreated = "created"
+	statusChanged = "changed"
+	statusDeleted = "deleted"
+)
+
+// statusView - Результат изменения данных.
+type statusView struct {
+	Meta   string `json:"meta"`
+	Status string `json:"status"`
+}
+
+func (v statusView) rows() [][]string {
+	return [][]string{{"META", "STATUS"}, {v.Meta, v.Status}}
+}
+
+func (v statusView) raw() []byte {
+	return nil
+}
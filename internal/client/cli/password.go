@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+
+	"GophKeeper/pkg/secret"
+)
+
+// generator - Флаги генерации пароля.
+type generator struct {
+	length         int
+	noSymbols      bool
+	allowAmbiguous bool
+	// words - Количество слов парольной фразы, 0 - случайный пароль.
+	words     int
+	separator string
+}
+
+// generatorFlags - Регистрация флагов генерации пароля в fs.
+func generatorFlags(fs *flag.FlagSet) *generator {
+
+	g := &generator{}
+	fs.IntVar(&g.length, "length", secret.DefaultPassword.Length, "password length")
+	fs.BoolVar(&g.noSymbols, "no-symbols", false, "letters and digits only")
+	fs.BoolVar(&g.allowAmbiguous, "allow-ambiguous", false, "allow look-alike characters 0/O/o, 1/l/I")
+	fs.IntVar(&g.words, "words", 0, "generate a passphrase of this many words instead")
+	fs.StringVar(&g.separator, "separator", secret.DefaultPassphrase.Separator, "passphrase word separator")
+
+	return g
+}
+
+// generate - Пароль или парольная фраза по флагам.
+func (g *generator) generate() (secret.Generated, error) {
+
+	var gen secret.Generated
+	var err error
+
+	if g.words != 0 {
+		gen, err = secret.GeneratePassphrase(secret.PassphrasePolicy{Words: g.words, Separator: g.separator})
+	} else {
+		policy := secret.DefaultPassword
+		policy.Length = g.length
+		policy.Symbols = !g.noSymbols
+		policy.ExcludeAmbiguous = !g.allowAmbiguous
+
+		gen, err = secret.GeneratePassword(policy)
+	}
+
+	if errors.Is(err, secret.ErrPasswordPolicy) {
+		return secret.Generated{}, usageErrorf("%v", err)
+	}
+
+	return gen, err
+}
+
+// passwordView - Сгенерированный пароль. В формате raw выводится пароль.
+type passwordView struct {
+	Password string `json:"password"`
+	// Entropy - Оценка энтропии в битах.
+	Entropy int `json:"entropy_bits"`
+}
+
+func newPasswordView(gen secret.Generated) passwordView {
+	return passwordView{Password: gen.Value, Entropy: entropyBits(gen)}
+}
+
+func (v passwordView) rows() [][]string {
+	return [][]string{{"FIELD", "VALUE"}, {"password", v.Password}, {"entropy_bits", strconv.Itoa(v.Entropy)}}
+}
+
+func (v passwordView) raw() []byte {
+	return []byte(v.Password)
+}
+
+// generatePassword - Генерация пароля без сохранения: gophkeeper password [flags].
+func (c *CLI) generatePassword(args []string) error {
+
+	fs := c.newFlagSet("password")
+	g := generatorFlags(fs)
+	output := fs.String("output", formatTable, "output format: json, table or raw")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 0 {
+		return usageErrorf("password: unexpected arguments")
+	}
+
+	p, err := newPrinter(c.stdout, *output)
+	if err != nil {
+		return err
+	}
+
+	gen, err := g.generate()
+	if err != nil {
+		return err
+	}
+
+	return p.print(newPasswordView(gen))
+}
+
+// entropyBits - Энтропия пароля, округленная вниз до бита.
+func entropyBits(gen secret.Generated) int {
+	return int(math.Floor(gen.Entropy))
+}
+
+// reportEntropy - Сообщение в stderr об энтропии сгенерированного пароля.
+func (c *CLI) reportEntropy(gen secret.Generated) {
+	fmt.Fprintf(c.stderr, "generated password, entropy %d bits\n", entropyBits(gen))
+}
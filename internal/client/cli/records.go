@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"time"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+// kind - Тип данных для команд get, set, list и delete.
+type kind interface {
+	// bind - Регистрация флагов команды set.
+	// Возвращает чтение данных после разбора флагов.
+	bind(fs *flag.FlagSet) reader
+	// get - Расшифрованные данные meta.
+	get(meta, token string) (view, error)
+	// version - Текущая версия данных meta на сервере.
+	version(meta, token string) (int64, error)
+	// create - Шифрование и создание данных.
+	create(meta string, fields record, token string) error
+	// change - Шифрование и изменение данных текущей версии version.
+	change(meta string, fields record, version int64, token string) error
+	list(filter list_model.Filter, token string) (list_model.Page, error)
+	remove(meta string, version int64, token string) error
+}
+
+// record - Открытые значения полей данных.
+type record map[string][]byte
+
+// reader - Чтение полей данных из флагов, файлов и stdin.
+type reader func(in *inputs) (record, error)
+
+// records - Команды get, set, list и delete для данных типа k.
+func (c *CLI) records(name string, args []string, k kind) error {
+
+	if len(args) == 0 {
+		return usageErrorf("%s: subcommand is required: get, set, list or delete", name)
+	}
+
+	sub := args[0]
+	fs := c.newFlagSet(name + " " + sub)
+	cf := c.commonFlags(fs)
+
+	var read reader
+	var filter list_model.Filter
+
+	switch sub {
+	case "set":
+		read = k.bind(fs)
+
+	case "list":
+		fs.StringVar(&filter.Prefix, "prefix", "", "meta starts with")
+		fs.StringVar(&filter.Contains, "contains", "", "meta contains")
+
+	case "get", "delete":
+
+	default:
+		return usageErrorf("%s: unknown subcommand %q", name, sub)
+	}
+
+	positional, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if sub == "list" && len(positional) != 0 {
+		return usageErrorf("%s list: unexpected arguments", name)
+	}
+
+	var meta string
+	if sub != "list" {
+		if meta, err = requireMeta(name+" "+sub, positional); err != nil {
+			return err
+		}
+	}
+
+	in := c.inputs()
+	token, err := c.session(cf, in)
+	if err != nil {
+		return err
+	}
+
+	p, _ := newPrinter(c.stdout, cf.output)
+
+	switch sub {
+	case "get":
+		v, errGet := k.get(meta, token)
+		if errGet != nil {
+			return errGet
+		}
+		return p.print(v)
+
+	case "set":
+		status, errSet := c.set(k, meta, read, in, token)
+		if errSet != nil {
+			return errSet
+		}
+		return p.print(statusView{Meta: meta, Status: status})
+
+	case "delete":
+		version, errVer := k.version(meta, token)
+		if errVer != nil {
+			return errVer
+		}
+
+		if err = k.remove(meta, version, token); err != nil {
+			return err
+		}
+		return p.print(statusView{Meta: meta, Status: statusDeleted})
+	}
+
+	metas, err := listAll(k.list, filter, token)
+	if err != nil {
+		return err
+	}
+
+	return p.print(listView(metas))
+}
+
+// set - Создание данных meta или, если они есть, изменение текущей версии.
+func (c *CLI) set(k kind, meta string, read reader, in *inputs, token string) (string, error) {
+
+	if err := c.writable(); err != nil {
+		return ``, err
+	}
+
+	fields, err := read(in)
+	if err != nil {
+		return ``, err
+	}
+
+	version, err := k.version(meta, token)
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		if err = k.create(meta, fields, token); err != nil {
+			return ``, err
+		}
+		return statusCreated, nil
+
+	case err != nil:
+		return ``, err
+	}
+
+	if err = k.change(meta, fields, version, token); err != nil {
+		return ``, err
+	}
+
+	return statusChanged, nil
+}
+
+// listAll - Метаинформация всех страниц списка, которые возвращает list.
+func listAll(list func(list_model.Filter, string) (list_model.Page, error), filter list_model.Filter, token string) ([]string, error) {
+
+	var metas []string
+
+	for {
+		page, err := list(filter, token)
+		if err != nil {
+			return nil, err
+		}
+
+		metas = append(metas, page.MetaInfo...)
+
+		if len(page.NextPageToken) == 0 {
+			return metas, nil
+		}
+
+		filter.PageToken = page.NextPageToken
+	}
+}
+
+// header - Метаинформация, версия и время изменения данных, общие для всех типов.
+type header struct {
+	Meta      string     `json:"meta"`
+	Version   int64      `json:"version"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func newHeader(meta string, version int64, updatedAt time.Time) header {
+
+	h := header{Meta: meta, Version: version}
+	if !updatedAt.IsZero() {
+		h.UpdatedAt = &updatedAt
+	}
+
+	return h
+}
+
+// rows - Строки таблицы с полями fields и общими сведениями.
+func (h header) rows(fields ...string) [][]string {
+
+	rows := [][]string{{"FIELD", "VALUE"}, {"meta", h.Meta}}
+	for i := 0; i+1 < len(fields); i += 2 {
+		rows = append(rows, []string{fields[i], fields[i+1]})
+	}
+
+	rows = append(rows, []string{"version", strconv.FormatInt(h.Version, 10)})
+	if h.UpdatedAt != nil {
+		rows = append(rows, []string{"updated_at", h.UpdatedAt.Local().Format(time.RFC3339)})
+	}
+
+	return rows
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+// errNoSession - Токен не задан, а сохраненной сессии профиля нет.
+var errNoSession = fmt.Errorf("%w: no saved session for the profile, set --token or $%s, see gophkeeper login", errs.ErrUnauthenticated, envToken)
+
+// resume - Токен доступа сессии, сохраненной интерактивным клиентом для профиля.
+// Токены сессии зашифрованы ключом данных, поэтому для ключа хранилища
+// сначала открывается сохраненная копия ключа мастер-паролем.
+func (c *CLI) resume(cf *common, input *inputs) (string, error) {
+
+	if c.sessions == nil {
+		return ``, errNoSession
+	}
+
+	saved, err := c.sessions.Load()
+	if errors.Is(err, errs.ErrNotFound) {
+		return ``, errNoSession
+	}
+	if err != nil {
+		return ``, err
+	}
+
+	if c.vaultKey && c.privateKey == nil {
+		if len(saved.SealedKey) == 0 {
+			return ``, fmt.Errorf("%w: saved session has no vault key, sign in again", errs.ErrUnauthenticated)
+		}
+
+		if err = c.openVault(cf, input, saved.SealedKey); err != nil {
+			return ``, err
+		}
+	}
+
+	if c.privateKey == nil {
+		return ``, errNoSession
+	}
+
+	access, refresh, err := c.sessions.Unlock(c.privateKey)
+	if err != nil {
+		return ``, fmt.Errorf("%w: %v", errs.ErrUnauthenticated, err)
+	}
+
+	if len(refresh) == 0 {
+		return access, nil
+	}
+
+	// Refresh token одноразовый: новые токены сохраняет в профиле перехватчик соединения
+	token, err := c.auth.Refresh(refresh)
+	if errors.Is(err, errs.ErrUnauthenticated) {
+		return ``, fmt.Errorf("%w: saved session has expired, sign in again", err)
+	}
+
+	return token, err
+}
+
+// openVault - Расшифровка ключа хранилища sealed мастер-паролем
+// из --master-file или GOPHKEEPER_MASTER_PASSWORD.
+func (c *CLI) openVault(cf *common, input *inputs, sealed []byte) error {
+
+	master := c.getenv(envMaster)
+	if len(cf.masterFile) != 0 {
+		data, err := input.read("master-file", cf.masterFile)
+		if err != nil {
+			return err
+		}
+		master = string(data)
+	}
+
+	if len(master) == 0 {
+		return usageErrorf("master password is required: set --master-file or $%s", envMaster)
+	}
+
+	key, err := secret.OpenVaultKey(master, sealed)
+	if err != nil {
+		return err
+	}
+
+	c.privateKey = key
+	c.publicKey = &key.PublicKey
+
+	return nil
+}
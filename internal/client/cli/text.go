@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"flag"
+
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/pkg/secret"
+)
+
+// textKind - Команды text.
+type textKind struct {
+	*CLI
+}
+
+// textView - Расшифрованные текстовые данные. В формате raw выводится текст.
+type textView struct {
+	header
+	Text string `json:"text"`
+}
+
+func (v textView) rows() [][]string {
+	return v.header.rows("text", v.Text)
+}
+
+func (v textView) raw() []byte {
+	return []byte(v.Text)
+}
+
+func (k textKind) bind(fs *flag.FlagSet) reader {
+
+	file := fs.String("file", stdinSource, "file with the text, \"-\" - stdin")
+
+	return func(in *inputs) (record, error) {
+
+		data, err := in.readAll("file", *file)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) == 0 {
+			return nil, usageErrorf("--file: text is empty")
+		}
+
+		return record{"text": data}, nil
+	}
+}
+
+func (k textKind) get(meta, token string) (view, error) {
+
+	data, err := k.text.Get(meta, token)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := secret.Decrypt(k.privateKey, data.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return textView{
+		header: newHeader(meta, data.Version, data.UpdatedAt),
+		Text:   string(plain),
+	}, nil
+}
+
+func (k textKind) version(meta, token string) (int64, error) {
+
+	data, err := k.text.Get(meta, token)
+	return data.Version, err
+}
+
+func (k textKind) create(meta string, fields record, token string) error {
+
+	data, err := k.encode(meta, fields)
+	if err != nil {
+		return err
+	}
+
+	return k.text.Create(data, token)
+}
+
+func (k textKind) change(meta string, fields record, version int64, token string) error {
+
+	data, err := k.encode(meta, fields)
+	if err != nil {
+		return err
+	}
+
+	data.Version = version
+	return k.text.Change(data, token)
+}
+
+func (k textKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.text.List(filter, token)
+}
+
+func (k textKind) remove(meta string, version int64, token string) error {
+	return k.text.Delete(meta, version, token)
+}
+
+// encode - Шифрование текста.
+func (k textKind) encode(meta string, fields record) (text_model.Text, error) {
+
+	data, err := secret.Encrypt(k.publicKey, fields["text"])
+	if err != nil {
+		return text_model.Text{}, err
+	}
+
+	return text_model.Text{MetaInfo: meta, Data: data}, nil
+}
@@ -2,6 +2,8 @@ package client
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"os"
@@ -10,7 +12,12 @@ import (
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/client/app_services/app_service_auth"
+	"GophKeeper/internal/client/app_services/app_service_rotation"
+	"GophKeeper/internal/client/cache"
+	"GophKeeper/internal/client/model/auth_model"
+	"GophKeeper/internal/client/session"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
 )
 
 type Options func(c *Client)
@@ -19,13 +26,37 @@ type IService interface {
 	Name() string
 	ShowMenu()
 	SetToken(token string)
+	SetKey(key *rsa.PrivateKey)
+}
+
+// Browser - Полноэкранный интерфейс вместо меню сервисов.
+type Browser interface {
+	// Run - Работа интерфейса до выхода пользователя. Пустой token - работа
+	// без связи с сервером, key - ключ шифрования данных.
+	Run(token string, key *rsa.PrivateKey) error
 }
 
 type Client struct {
 	logger   *zap.Logger
 	auth     *app_service_auth.AuthService
+	vault    *cache.Vault
 	services []IService
+	rotation *app_service_rotation.RotationService
 	token    string
+	email    string
+	// privateKey - Ключ шифрования данных из файла.
+	privateKey *rsa.PrivateKey
+	// vaultKey - Ключ шифрования данных хранится на сервере
+	// и открывается мастер-паролем после авторизации.
+	vaultKey bool
+	// sealedKey - Ключ хранилища, зашифрованный мастер-паролем.
+	sealedKey []byte
+	// sessions - Сессия, сохраненная между запусками.
+	sessions *session.Store
+	// saved - Сессия сохранена и при выходе не закрывается.
+	saved bool
+	// browser - Полноэкранный интерфейс, nil - меню сервисов.
+	browser Browser
 }
 
 // NewClient - Создание экземпляра клиента.
@@ -49,30 +80,120 @@ func WithService(serv IService) Options {
 	}
 }
 
+// WithVault - Использование локального кэша данных.
+func WithVault(vault *cache.Vault) Options {
+	return func(c *Client) {
+		c.vault = vault
+	}
+}
+
+// WithRotation - Смена ключа шифрования данных.
+func WithRotation(rotation *app_service_rotation.RotationService) Options {
+	return func(c *Client) {
+		c.rotation = rotation
+	}
+}
+
+// WithPrivateKey - Ключ шифрования данных из файла.
+func WithPrivateKey(key *rsa.PrivateKey) Options {
+	return func(c *Client) {
+		c.privateKey = key
+	}
+}
+
+// WithVaultKey - Ключ шифрования данных хранится на сервере в зашифрованном
+// мастер-паролем виде и открывается после авторизации.
+func WithVaultKey() Options {
+	return func(c *Client) {
+		c.vaultKey = true
+	}
+}
+
+// WithSessions - Сохранение сессии между запусками.
+func WithSessions(store *session.Store) Options {
+	return func(c *Client) {
+		c.sessions = store
+	}
+}
+
+// WithBrowser - Полноэкранный интерфейс вместо меню сервисов.
+func WithBrowser(browser Browser) Options {
+	return func(c *Client) {
+		c.browser = browser
+	}
+}
+
 func (c *Client) Start() {
-	token, err := c.auth.Token()
+	session, resumed := c.resume()
 
-	switch {
-	case err == nil:
-		break
+	if !resumed {
+		var err error
+		session, err = c.auth.Token()
 
-	case errors.Is(err, errs.ErrCancel):
-		color.HiMagenta("Goodbye... :'(")
-		return
+		switch {
+		case err == nil:
+			break
 
+		case errors.Is(err, errs.ErrCancel):
+			color.HiMagenta("Goodbye... :'(")
+			return
+
+		default:
+			c.logger.Error("failed get token", zap.Error(err))
+			color.Red("Увы, но что-то пошло не так...")
+			return
+		}
+	}
+
+	switch {
+	case session.Offline():
+		color.Yellow("Работа без связи с сервером: данные из локального кэша")
+	case resumed:
+		color.Green("Сессия %s продолжена", session.Email)
 	default:
-		c.logger.Error("failed get token", zap.Error(err))
-		color.Red("Увы, но что-то пошло не так...")
-		return
+		color.Green("Авторизация успешно пройдена")
+	}
+
+	if c.vaultKey {
+		if ok := c.unlock(session); !ok {
+			return
+		}
 	}
 
-	color.Green("Авторизация успешно пройдена")
+	if !session.Offline() {
+		c.saveSession(session.Email)
+	}
 
-	for i, _ := range c.services {
-		c.services[i].SetToken(token)
+	if c.vault != nil {
+		if errOpen := c.vault.Open(session.Email, c.privateKey); errOpen != nil {
+			c.logger.Error("failed open cache", zap.Error(errOpen))
+			color.Red("Не удалось открыть локальный кэш")
+			return
+		}
+	}
+
+	c.token = session.Token
+	c.email = session.Email
+	for i := range c.services {
+		c.services[i].SetToken(c.token)
+	}
+
+	c.sync()
+	c.checkRotation()
+
+	if c.browser != nil {
+		if err := c.browser.Run(c.token, c.privateKey); err != nil {
+			c.logger.Error("failed run browser", zap.Error(err))
+			color.Red("Не удалось запустить полноэкранный интерфейс")
+		}
+	} else {
+		c.showServicesMenu()
+	}
+
+	if len(c.token) != 0 && !c.saved {
+		c.auth.Logout(c.token)
 	}
 
-	c.showServicesMenu()
 	color.HiMagenta("Goodbye... :'(")
 }
 
@@ -92,6 +213,20 @@ func (c *Client) showServicesMenu() {
 		for i, serv := range c.services {
 			fmt.Printf("[%d] %s\n", i+1, serv.Name())
 		}
+		if c.vault != nil && len(c.token) != 0 {
+			fmt.Printf("[%d] Синхронизация\n", len(c.services)+1)
+		}
+		if len(c.token) != 0 {
+			fmt.Printf("[%d] Сессии\n", len(c.services)+2)
+		}
+		if c.canRotate() {
+			fmt.Printf("[%d] Смена ключа шифрования\n", len(c.services)+3)
+		}
+		if len(c.token) != 0 {
+			fmt.Printf("[%d] Второй фактор\n", len(c.services)+4)
+			fmt.Printf("[%d] Удалить учетную запись\n", len(c.services)+5)
+			fmt.Printf("[%d] Выйти из учетной записи\n", len(c.services)+6)
+		}
 		fmt.Println("---------------")
 		fmt.Print("-> ")
 
@@ -111,5 +246,319 @@ func (c *Client) showServicesMenu() {
 		if choice >= 1 && choice <= len(c.services) {
 			c.services[choice-1].ShowMenu()
 		}
+
+		if choice == len(c.services)+1 {
+			c.sync()
+		}
+
+		if choice == len(c.services)+2 && len(c.token) != 0 {
+			c.auth.Sessions(c.token)
+		}
+
+		if choice == len(c.services)+3 && c.canRotate() {
+			c.rotate()
+		}
+
+		if choice == len(c.services)+4 && len(c.token) != 0 {
+			c.auth.TwoFactor(c.token)
+		}
+
+		if choice == len(c.services)+5 && len(c.token) != 0 && c.deleteAccount() {
+			return
+		}
+
+		if choice == len(c.services)+6 && len(c.token) != 0 {
+			c.logout()
+			return
+		}
+	}
+}
+
+// resume - Продолжение сессии, сохраненной при прошлом запуске.
+// Токены сессии зашифрованы ключом данных, поэтому для ключа хранилища
+// сначала запрашивается мастер-пароль.
+func (c *Client) resume() (auth_model.Session, bool) {
+
+	if c.sessions == nil {
+		return auth_model.Session{}, false
+	}
+
+	saved, err := c.sessions.Load()
+	if err != nil {
+		if !errors.Is(err, errs.ErrNotFound) {
+			c.logger.Error("failed load session", zap.Error(err))
+		}
+		return auth_model.Session{}, false
+	}
+
+	key := c.privateKey
+	if c.vaultKey && len(saved.SealedKey) != 0 {
+		color.Cyan("Сохраненная сессия %s", saved.Email)
+
+		// Без токена используется сохраненная копия ключа хранилища
+		key, _, err = c.auth.VaultKey(auth_model.Session{Email: saved.Email}, saved.SealedKey)
+		if err != nil {
+			color.Red("Неверный мастер-пароль, войдите заново")
+			return auth_model.Session{}, false
+		}
+
+		// Ключ уже открыт, повторно мастер-пароль не запрашивается
+		c.privateKey = key
+		c.sealedKey = saved.SealedKey
+	}
+
+	if key == nil {
+		return auth_model.Session{}, false
+	}
+
+	access, refresh, err := c.sessions.Unlock(key)
+	if err != nil {
+		c.logger.Error("failed unlock session", zap.Error(err))
+		color.Yellow("Сохраненная сессия недоступна, войдите заново")
+		return auth_model.Session{}, false
+	}
+
+	token, err := c.auth.Resume(access, refresh)
+	switch {
+	case err == nil:
+		return auth_model.Session{Email: saved.Email, Token: token}, true
+
+	case errors.Is(err, errs.ErrUnavailable) && c.vault != nil:
+		return auth_model.Session{Email: saved.Email}, true
+
+	case errors.Is(err, errs.ErrUnauthenticated):
+		color.Yellow("Сессия истекла, войдите заново")
+		if errRemove := c.sessions.Remove(); errRemove != nil {
+			c.logger.Error("failed remove session", zap.Error(errRemove))
+		}
+
+	default:
+		c.logger.Error("failed resume session", zap.Error(err))
+		color.Yellow("Не удалось продолжить сессию, войдите заново")
+	}
+
+	return auth_model.Session{}, false
+}
+
+// saveSession - Сохранение сессии пользователя email до явного выхода.
+// Без шифрования данных ключа для токенов нет, и сессия не сохраняется.
+func (c *Client) saveSession(email string) {
+
+	if c.sessions == nil || c.privateKey == nil {
+		return
 	}
+
+	if err := c.sessions.Save(email, c.sealedKey, c.privateKey); err != nil {
+		c.logger.Error("failed save session", zap.Error(err))
+		color.Red("Не удалось сохранить сессию")
+		return
+	}
+
+	c.saved = true
+}
+
+// logout - Закрытие сессии на сервере и удаление сохраненной сессии.
+func (c *Client) logout() {
+
+	c.auth.Logout(c.token)
+	c.token = ""
+
+	if c.sessions != nil {
+		if err := c.sessions.Remove(); err != nil {
+			c.logger.Error("failed remove session", zap.Error(err))
+			color.Red("Не удалось удалить сохраненную сессию")
+			return
+		}
+	}
+
+	color.Green("Выход выполнен")
+}
+
+// deleteAccount - Удаление учетной записи на сервере и локального кэша.
+// Возвращает true, если учетная запись удалена и работа завершается.
+func (c *Client) deleteAccount() bool {
+
+	if !c.auth.DeleteAccount(c.email, c.token) {
+		return false
+	}
+
+	// Сессии закрыты на сервере вместе с учетной записью
+	c.token = ""
+
+	if c.sessions != nil {
+		if err := c.sessions.Remove(); err != nil {
+			c.logger.Error("failed remove session", zap.Error(err))
+		}
+	}
+
+	if c.vault != nil {
+		if err := c.vault.Remove(c.email); err != nil {
+			c.logger.Error("failed remove cache", zap.Error(err))
+			color.Red("Не удалось удалить локальный кэш")
+		}
+	}
+
+	return true
+}
+
+// unlock - Получение ключа хранилища и передача его сервисам.
+func (c *Client) unlock(session auth_model.Session) bool {
+
+	if c.privateKey != nil && c.current(session) {
+		c.setKey(c.privateKey)
+		color.Green("Хранилище открыто")
+		return true
+	}
+
+	var cached []byte
+	if c.vault != nil {
+		cached = c.vault.SealedKey(session.Email)
+	}
+
+	key, sealed, err := c.auth.VaultKey(session, cached)
+	switch {
+	case err == nil:
+		break
+
+	case errors.Is(err, secret.ErrWrongPassword):
+		color.Red("Неверный мастер-пароль")
+		return false
+
+	case errors.Is(err, errs.ErrNotFound):
+		color.Red("Ключ хранилища недоступен без связи с сервером")
+		return false
+
+	case errors.Is(err, errs.ErrUnavailable):
+		color.Red("Сервер недоступен")
+		return false
+
+	default:
+		c.logger.Error("failed get vault key", zap.Error(err))
+		color.Red("Не удалось получить ключ хранилища")
+		return false
+	}
+
+	if c.vault != nil {
+		if errSave := c.vault.SaveSealedKey(session.Email, sealed); errSave != nil {
+			c.logger.Error("failed save vault key", zap.Error(errSave))
+		}
+	}
+
+	c.sealedKey = sealed
+	c.setKey(key)
+
+	color.Green("Хранилище открыто")
+	return true
+}
+
+// current - Ключ хранилища, открытый при восстановлении сессии, не изменился на сервере.
+func (c *Client) current(session auth_model.Session) bool {
+
+	if session.Offline() {
+		return true
+	}
+
+	sealed, err := c.auth.GetVaultKey(session.Token)
+	return err == nil && bytes.Equal(sealed, c.sealedKey)
+}
+
+// setKey - Передача ключа шифрования данных сервисам.
+func (c *Client) setKey(key *rsa.PrivateKey) {
+
+	c.privateKey = key
+	for i := range c.services {
+		c.services[i].SetKey(key)
+	}
+}
+
+// sync - Синхронизация локального кэша с сервером.
+func (c *Client) sync() {
+
+	if c.vault == nil || len(c.token) == 0 {
+		return
+	}
+
+	report, err := c.vault.Sync(c.token)
+
+	for _, meta := range report.Conflicts {
+		color.Yellow("Конфликт: изменения \"%s\" не отправлены, сохранена версия сервера", meta)
+	}
+
+	switch {
+	case err == nil:
+		color.Green("Синхронизация завершена: отправлено %d, получено %d", report.Sent, report.Received)
+
+	case errors.Is(err, errs.ErrUnavailable):
+		color.Yellow("Сервер недоступен, изменений в очереди: %d", report.Pending)
+
+	default:
+		c.logger.Error("failed sync cache", zap.Error(err))
+		color.Red("Не удалось синхронизировать локальный кэш")
+	}
+}
+
+// canRotate - Смена ключа доступна: данные шифруются и есть связь с сервером.
+func (c *Client) canRotate() bool {
+	return c.rotation != nil && c.privateKey != nil && len(c.token) != 0
+}
+
+// checkRotation - Предупреждение о незавершенной смене ключа:
+// часть данных может быть уже зашифрована новым ключом.
+func (c *Client) checkRotation() {
+
+	if !c.canRotate() {
+		return
+	}
+
+	if current, ok := c.rotation.Pending(c.token); ok {
+		color.Yellow("Смена ключа шифрования, начатая %s, не завершена", current.StartedAt.Local().Format("02.01.2006 15:04:05"))
+		color.Yellow("Часть данных может не открываться, завершите смену ключа в меню")
+	}
+}
+
+// rotate - Смена ключа шифрования и переход сервисов на новый ключ.
+// Локальный кэш зашифрован прежним ключом, поэтому он создается заново.
+func (c *Client) rotate() {
+
+	if c.vault != nil && c.vault.Pending() != 0 {
+		c.sync()
+		if c.vault.Pending() != 0 {
+			color.Red("Есть неотправленные изменения, смена ключа невозможна")
+			return
+		}
+	}
+
+	key, sealed, ok := c.rotation.Rotate(c.privateKey, c.token)
+	if !ok {
+		return
+	}
+
+	c.setKey(key)
+
+	if c.vaultKey {
+		c.sealedKey = sealed
+	}
+
+	// Токены сессии зашифрованы прежним ключом
+	if c.saved {
+		c.saveSession(c.email)
+	}
+
+	if c.vault == nil {
+		return
+	}
+
+	if c.vaultKey {
+		if err := c.vault.SaveSealedKey(c.email, sealed); err != nil {
+			c.logger.Error("failed save vault key", zap.Error(err))
+		}
+	}
+
+	if err := c.vault.Reset(c.email, key); err != nil {
+		c.logger.Error("failed reset cache", zap.Error(err))
+		color.Red("Не удалось пересоздать локальный кэш")
+		return
+	}
+
+	c.sync()
 }
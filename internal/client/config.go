@@ -5,65 +5,169 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"os"
 	"strconv"
 	"strings"
+
+	"GophKeeper/internal/client/cache"
+	"GophKeeper/internal/client/session"
+	"GophKeeper/pkg/config"
 )
 
 type Config struct {
-	AddrGRPC   string `env:"ADDRESS_RPC" json:"address_rpc"`
-	Salt       string `env:"SALT" json:"salt"`
-	PublicKey  []byte `env:"PUBLIC_KEY" json:"public_key"`
-	PrivateKey []byte `env:"PRIVATE_KEY" json:"private_key"`
+	AddrGRPC string `env:"ADDRESS_RPC" json:"address_rpc"`
+	Salt     string `env:"SALT" json:"salt"`
+	// PublicKeyFile, PrivateKeyFile - Файлы ключей шифрования данных.
+	PublicKeyFile  string `env:"PUBLIC_KEY_FILE" json:"public_key_file"`
+	PrivateKeyFile string `env:"PRIVATE_KEY_FILE" json:"private_key_file"`
+	// PublicKey, PrivateKey - Ключи из файлов в PEM.
+	PublicKey  []byte `json:"-"`
+	PrivateKey []byte `json:"-"`
+	// CacheDir - Каталог локального кэша данных. Пустой - кэш не используется.
+	CacheDir string `env:"CACHE_DIR" json:"cache_dir"`
+	// Profile - Профиль сохраненной сессии. Разные профили - разные серверы или учетные записи.
+	Profile string `env:"PROFILE" json:"profile"`
+	// SessionDir - Каталог сохраненных сессий. Пустой - сессия не сохраняется.
+	SessionDir string `env:"SESSION_DIR" json:"session_dir"`
+	// InsecurePlaintext - Разрешить хранение данных без шифрования.
+	InsecurePlaintext bool `env:"INSECURE_PLAINTEXT" json:"insecure_plaintext"`
+	// TLS - Соединение с сервером по TLS. Включается и при заданных TLSCA или TLSCert.
+	TLS bool `env:"TLS" json:"tls"`
+	// TLSCA - Сертификаты CA сервера. Пустой - системные.
+	TLSCA string `env:"TLS_CA" json:"tls_ca"`
+	// TLSCert, TLSKey - Сертификат и ключ клиента для mTLS.
+	TLSCert string `env:"TLS_CERT" json:"tls_cert"`
+	TLSKey  string `env:"TLS_KEY" json:"tls_key"`
+	// TUI - Полноэкранный интерфейс вместо меню.
+	TUI bool `env:"TUI" json:"tui"`
 }
 
 // NewConfig Конфигурация сервера
 func NewConfig() *Config {
 
 	return &Config{
-		AddrGRPC: ":3200",
-		Salt:     "01.01.1970",
+		AddrGRPC:   ":3200",
+		Salt:       "01.01.1970",
+		CacheDir:   cache.DefaultDir(),
+		Profile:    session.DefaultProfile,
+		SessionDir: session.DefaultDir(),
 	}
 }
 
-// ParseArgs Разброр аргументов командной строки
+// flagFields - Поля конфигурации по имени флага.
+var flagFields = map[string]string{
+	"ad":                 "address_rpc",
+	"s":                  "salt",
+	"pbk":                "public_key_file",
+	"prk":                "private_key_file",
+	"cd":                 "cache_dir",
+	"profile":            "profile",
+	"sd":                 "session_dir",
+	"insecure-plaintext": "insecure_plaintext",
+	"tls":                "tls",
+	"tls-ca":             "tls_ca",
+	"tls-cert":           "tls_cert",
+	"tls-key":            "tls_key",
+	"tui":                "tui",
+}
+
+// ParseArgs Разброр конфигурации: значения по умолчанию, файл -c или $CONFIG,
+// переменные окружения и аргументы командной строки - каждый источник
+// переопределяет предыдущий.
 func (cfg *Config) ParseArgs() error {
+	return cfg.parse(flag.CommandLine, os.Args[1:], os.LookupEnv)
+}
 
-	addr := flag.String("ad", "", "string - address grpc gate")
-	salt := flag.String("s", "", "string - password salt")
-	privatePath := flag.String("prk", "", "private key - path to file")
-	publicPath := flag.String("pbk", "", "public key - path to file")
+func (cfg *Config) parse(fs *flag.FlagSet, args []string, lookup func(string) (string, bool)) error {
+
+	fs.String(config.FileFlag, "", "string - config file, JSON or YAML (.yaml, .yml)")
+	fs.String("ad", cfg.AddrGRPC, "string - address grpc gate, default - server of the saved session")
+	fs.String("s", cfg.Salt, "string - password salt, prefer $SALT or the config file")
+	fs.String("prk", "", "private key - path to file")
+	fs.String("pbk", "", "public key - path to file")
+	fs.String("cd", cfg.CacheDir, "string - local cache directory")
+	noCache := fs.Bool("nc", false, "bool - disable local cache")
+	fs.String("profile", cfg.Profile, "string - saved session profile")
+	fs.String("sd", cfg.SessionDir, "string - saved sessions directory")
+	noSession := fs.Bool("ns", false, "bool - do not save session between runs")
+	fs.Bool("insecure-plaintext", cfg.InsecurePlaintext, "bool - store data without encryption")
+	fs.Bool("tls", cfg.TLS, "bool - connect over TLS")
+	fs.String("tls-ca", cfg.TLSCA, "string - server CA bundle, system roots if empty")
+	fs.String("tls-cert", cfg.TLSCert, "string - client TLS certificate for mTLS")
+	fs.String("tls-key", cfg.TLSKey, "string - client TLS private key for mTLS")
+	fs.Bool("tui", cfg.TUI, "bool - full-screen terminal interface instead of the menu")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
 
-	flag.Parse()
+	loader := config.New(cfg)
 
-	if addr == nil || len(*addr) == 0 {
-		*addr = cfg.AddrGRPC
+	if path := config.FilePath(fs, lookup); len(path) != 0 {
+		if err := loader.File(path); err != nil {
+			return err
+		}
 	}
 
-	if salt != nil && len(*salt) > 0 {
-		cfg.Salt = *salt
+	if err := loader.Env(lookup); err != nil {
+		return err
 	}
 
-	if err := readKey(publicPath, &cfg.PublicKey); err != nil {
+	if err := loader.Flags(fs, flagFields); err != nil {
 		return err
 	}
 
-	if err := readKey(privatePath, &cfg.PrivateKey); err != nil {
-		return err
+	if *noCache {
+		cfg.CacheDir = ""
 	}
 
-	if err := isValidAddress(*addr); err != nil {
-		return err
+	if *noSession {
+		cfg.SessionDir = ""
+	}
+
+	// Без адреса в конфигурации используется сервер сохраненной сессии профиля
+	if loader.Source("address_rpc") == config.Default && len(cfg.SessionDir) != 0 {
+		if saved := session.Address(cfg.SessionDir, cfg.Profile); len(saved) != 0 {
+			cfg.AddrGRPC = saved
+		}
+	}
+
+	cfg.TLS = cfg.TLS || len(cfg.TLSCA) != 0 || len(cfg.TLSCert) != 0
+
+	return cfg.validate(loader)
+}
+
+// validate - Проверка значений и чтение ключей с указанием источника неверного значения.
+func (cfg *Config) validate(loader *config.Loader) error {
+
+	if err := isValidAddress(cfg.AddrGRPC); err != nil {
+		return loader.Errorf("address_rpc", "%v", err)
+	}
+
+	if len(cfg.TLSCert) == 0 && len(cfg.TLSKey) != 0 {
+		return loader.Errorf("tls_key", "TLS certificate and key must be set together")
+	}
+
+	if len(cfg.TLSCert) != 0 && len(cfg.TLSKey) == 0 {
+		return loader.Errorf("tls_cert", "TLS certificate and key must be set together")
+	}
+
+	if err := readKey(cfg.PublicKeyFile, &cfg.PublicKey); err != nil {
+		return loader.Errorf("public_key_file", "%v", err)
+	}
+
+	if err := readKey(cfg.PrivateKeyFile, &cfg.PrivateKey); err != nil {
+		return loader.Errorf("private_key_file", "%v", err)
 	}
 
-	cfg.AddrGRPC = *addr
 	return nil
 }
 
-func readKey(path *string, save *[]byte) error {
+func readKey(path string, save *[]byte) error {
 
-	if path != nil && len(*path) > 0 {
+	if len(path) > 0 {
 
-		key, err := ioutil.ReadFile(*path)
+		key, err := ioutil.ReadFile(path)
 		if err != nil {
 			return err
 		}
@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"GophKeeper/internal/client/model/auth_model"
@@ -29,7 +30,9 @@ func NewService(conn *grpc.ClientConn) *AuthService {
 
 // SignIn - Авторизация пользователя.
 // При отсутствии ошибки возвращается тоекн авторизации.
-func (c *AuthService) SignIn(cred auth_model.Credential) (string, error) {
+// Если у пользователя подключен второй фактор, вместо токена возвращается
+// challenge для подтверждения входа кодом в VerifyOTP.
+func (c *AuthService) SignIn(cred auth_model.Credential) (string, string, error) {
 
 	auth := &pb.AuthRequest{
 		Email:    cred.Email,
@@ -40,10 +43,15 @@ func (c *AuthService) SignIn(cred auth_model.Credential) (string, error) {
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return ``, ``, errs.ErrUnavailable
+
 			case codes.NotFound:
-				return ``, errs.ErrNotFound
+				return ``, ``, errs.ErrNotFound
 			case codes.InvalidArgument:
-				return ``, errs.ErrInvalidArgument
+				return ``, ``, errs.ErrInvalidArgument
+			case codes.ResourceExhausted:
+				return ``, ``, errs.ErrTooManyAttempts
 			default:
 				c.logger.Error("unknown gRPC error in SignUp",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -51,10 +59,10 @@ func (c *AuthService) SignIn(cred auth_model.Credential) (string, error) {
 			}
 		}
 
-		return ``, errs.ErrInternal
+		return ``, ``, errs.ErrInternal
 	}
 
-	return resp.Token, nil
+	return resp.Token, resp.Challenge, nil
 }
 
 // SignUp - Регистрация пользователя.
@@ -71,10 +79,15 @@ func (c *AuthService) SignUp(cred auth_model.Credential) (string, error) {
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return ``, errs.ErrUnavailable
+
 			case codes.AlreadyExists:
 				return ``, errs.ErrAlreadyExist
 			case codes.InvalidArgument:
 				return ``, errs.ErrInvalidArgument
+			case codes.ResourceExhausted:
+				return ``, errs.ErrTooManyAttempts
 			default:
 				c.logger.Error("unknown gRPC error in SignUp",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -87,3 +100,313 @@ func (c *AuthService) SignUp(cred auth_model.Credential) (string, error) {
 
 	return resp.Token, nil
 }
+
+// Logout - Завершение сессии на сервере.
+func (c *AuthService) Logout(token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := c.rpc.Logout(ctx, &pb.Empty{}); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				// Сессия уже закрыта
+				return nil
+
+			default:
+				c.logger.Error("unknown gRPC error in Logout",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// ListSessions - Открытые сессии пользователя.
+func (c *AuthService) ListSessions(token string) ([]auth_model.SessionInfo, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := c.rpc.ListSessions(ctx, &pb.Empty{})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return nil, errs.ErrUnavailable
+			}
+
+			c.logger.Error("unknown gRPC error in ListSessions",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+
+		return nil, errs.ErrInternal
+	}
+
+	sessions := make([]auth_model.SessionInfo, 0, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		sessions = append(sessions, auth_model.SessionInfo{
+			ID:        session.Id,
+			CreatedAt: session.CreatedAt.AsTime(),
+			ExpiresAt: session.ExpiresAt.AsTime(),
+			Current:   session.Current,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession - Закрытие сессии id.
+func (c *AuthService) RevokeSession(id, token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := c.rpc.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: id}); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			default:
+				c.logger.Error("unknown gRPC error in RevokeSession",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// GetVaultKey - Ключ хранилища, зашифрованный мастер-паролем.
+// Если ключ еще не сохранен, возвращается errs.ErrNotFound.
+func (c *AuthService) GetVaultKey(token string) ([]byte, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := c.rpc.GetVaultKey(ctx, &pb.Empty{})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return nil, errs.ErrUnavailable
+
+			case codes.NotFound:
+				return nil, errs.ErrNotFound
+
+			default:
+				c.logger.Error("unknown gRPC error in GetVaultKey",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return nil, errs.ErrInternal
+	}
+
+	return resp.SealedKey, nil
+}
+
+// SetVaultKey - Сохранение ключа хранилища, зашифрованного мастер-паролем.
+func (c *AuthService) SetVaultKey(sealed []byte, token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := c.rpc.SetVaultKey(ctx, &pb.VaultKey{SealedKey: sealed}); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.AlreadyExists:
+				return errs.ErrAlreadyExist
+
+			default:
+				c.logger.Error("unknown gRPC error in SetVaultKey",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// VerifyOTP - Второй шаг авторизации: код второго фактора для входа challenge.
+// При отсутствии ошибки возвращается токен авторизации.
+func (c *AuthService) VerifyOTP(challenge, code string) (string, error) {
+
+	resp, err := c.rpc.VerifyOTP(context.Background(), &pb.VerifyOTPRequest{
+		Challenge: challenge,
+		Code:      code,
+	})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return ``, errs.ErrUnavailable
+
+			case codes.InvalidArgument:
+				return ``, errs.ErrInvalidArgument
+			case codes.ResourceExhausted:
+				return ``, errs.ErrTooManyAttempts
+			case codes.Unauthenticated:
+				return ``, errs.ErrUnauthenticated
+			default:
+				c.logger.Error("unknown gRPC error in VerifyOTP",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return ``, errs.ErrInternal
+	}
+
+	return resp.Token, nil
+}
+
+// Refresh - Обмен сохраненного refresh token на новые токены.
+// Возвращается токен доступа, новый refresh token запоминает перехватчик TokenRefresher.
+func (c *AuthService) Refresh(refresh string) (string, error) {
+
+	resp, err := c.rpc.Refresh(context.Background(), &pb.RefreshRequest{
+		RefreshToken: refresh,
+	})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return ``, errs.ErrUnavailable
+
+			case codes.Unauthenticated:
+				return ``, errs.ErrUnauthenticated
+			default:
+				c.logger.Error("unknown gRPC error in Refresh",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return ``, errs.ErrInternal
+	}
+
+	return resp.Token, nil
+}
+
+// Enroll2FA - Подключение второго фактора.
+// Если второй фактор уже подключен, возвращается errs.ErrAlreadyExist.
+func (c *AuthService) Enroll2FA(token string) (auth_model.Enrollment, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := c.rpc.Enroll2FA(ctx, &pb.Empty{})
+	if err != nil {
+		return auth_model.Enrollment{}, c.otpError("Enroll2FA", err)
+	}
+
+	return auth_model.Enrollment{
+		URI:           resp.Uri,
+		RecoveryCodes: resp.RecoveryCodes,
+	}, nil
+}
+
+// Confirm2FA - Подтверждение подключения второго фактора кодом из приложения.
+func (c *AuthService) Confirm2FA(code, token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := c.rpc.Confirm2FA(ctx, &pb.OTPRequest{Code: code}); err != nil {
+		return c.otpError("Confirm2FA", err)
+	}
+
+	return nil
+}
+
+// Disable2FA - Отключение второго фактора кодом из приложения или кодом восстановления.
+func (c *AuthService) Disable2FA(code, token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := c.rpc.Disable2FA(ctx, &pb.OTPRequest{Code: code}); err != nil {
+		return c.otpError("Disable2FA", err)
+	}
+
+	return nil
+}
+
+// DeleteAccount - Удаление учетной записи со всеми данными на сервере.
+// password - текущий пароль для подтверждения.
+func (c *AuthService) DeleteAccount(password, token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := c.rpc.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: password}); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+			case codes.Unauthenticated:
+				return errs.ErrUnauthenticated
+			case codes.ResourceExhausted:
+				return errs.ErrTooManyAttempts
+			default:
+				c.logger.Error("unknown gRPC error in DeleteAccount",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// otpError - Ошибка запроса method управления вторым фактором.
+func (c *AuthService) otpError(method string, err error) error {
+
+	if e, ok := status.FromError(err); ok {
+		switch e.Code() {
+		case codes.Unavailable:
+			return errs.ErrUnavailable
+
+		case codes.NotFound:
+			return errs.ErrNotFound
+		case codes.AlreadyExists:
+			return errs.ErrAlreadyExist
+		case codes.InvalidArgument:
+			return errs.ErrInvalidArgument
+		default:
+			c.logger.Error("unknown gRPC error in "+method,
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+	}
+
+	return errs.ErrInternal
+}
@@ -2,8 +2,11 @@
 package grpc_service_binary
 
 import (
+	"bytes"
 	"context"
-	"strings"
+	"crypto/sha256"
+	"fmt"
+	"io"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -12,6 +15,9 @@ import (
 	"google.golang.org/grpc/status"
 
 	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/binary"
 )
@@ -32,27 +38,58 @@ func NewService(conn *grpc.ClientConn) *BinaryService {
 
 }
 
+// Create - Создание данных. Данные передаются потоком, поэтому их размер
+// не ограничен размером сообщения gRPC.
 func (serv BinaryService) Create(data binary_model.Binary, token string) error {
-	dataReq := &pb.CreateRequest{
-		MetaInfo: data.MetaInfo,
-		Data:     data.Data,
+	return serv.Upload(binary_model.Upload{MetaInfo: data.MetaInfo}, bytes.NewReader(data.Data), token)
+}
+
+// Get - Получение данных.
+func (serv BinaryService) Get(meta, token string) (binary_model.Binary, error) {
+	var buf bytes.Buffer
+	info, err := serv.Download(meta, &buf, token)
+	if err != nil {
+		return binary_model.Binary{}, err
+	}
+
+	return binary_model.Binary{
+		MetaInfo:  meta,
+		Data:      buf.Bytes(),
+		Version:   info.Version,
+		UpdatedAt: info.UpdatedAt,
+	}, nil
+}
+
+func (serv BinaryService) Delete(meta string, version int64, token string) error {
+	data := &pb.DeleteRequest{
+		MetaInfo: meta,
+		Version:  version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
 	ctx := metadata.NewOutgoingContext(context.Background(), md)
 
-	if _, err := serv.rpc.Create(ctx, dataReq); err != nil {
+	if _, err := serv.rpc.Delete(ctx, data); err != nil {
 		if e, ok := status.FromError(err); ok {
+
 			switch e.Code() {
-			case codes.AlreadyExists:
-				return errs.ErrAlreadyExist
+			case codes.Unavailable:
+				return errs.ErrUnavailable
 
-			default:
-				if strings.Contains(err.Error(), "larger than max") {
-					return errs.ErrLargeData
-				}
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.Aborted:
+				return errs.ErrConflict
 
-				serv.logger.Error("unknown gRPC error in binary service Create()",
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			default:
+				serv.logger.Error("unknown gRPC error in binary service Delete()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
 					zap.String("gRPC text", e.String()))
 			}
@@ -63,24 +100,264 @@ func (serv BinaryService) Create(data binary_model.Binary, token string) error {
 	return nil
 }
 
-func (serv BinaryService) Get(meta, token string) (binary_model.Binary, error) {
-	data := &pb.GetRequest{
+// Change - Изменение данных.
+func (serv BinaryService) Change(data binary_model.Binary, token string) error {
+	upload := binary_model.Upload{
+		MetaInfo:  data.MetaInfo,
+		Overwrite: true,
+		Version:   data.Version,
+	}
+
+	return serv.Upload(upload, bytes.NewReader(data.Data), token)
+}
+
+// List - Получение страницы списка метаинформации.
+func (serv BinaryService) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	data := &pb.ListRequest{
+		Prefix:    filter.Prefix,
+		Contains:  filter.Contains,
+		PageToken: filter.PageToken,
+		Limit:     uint32(filter.Limit),
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.List(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return list_model.Page{}, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return list_model.Page{}, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in binary service List()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return list_model.Page{}, errs.ErrInternal
+	}
+
+	return list_model.Page{
+		MetaInfo:      resp.MetaInfo,
+		Versions:      resp.Versions,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+// Upload - Потоковая загрузка данных из r частями по binary_model.ChunkSize.
+// Последним сообщением отправляется контрольная сумма SHA-256.
+func (serv BinaryService) Upload(in binary_model.Upload, r io.Reader, token string) error {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	defer cancel()
+
+	stream, err := serv.rpc.Upload(ctx)
+	if err != nil {
+		return serv.parseStreamError(err, "Upload")
+	}
+
+	hash := sha256.New()
+	buf := make([]byte, binary_model.ChunkSize)
+	req := &pb.UploadRequest{
+		MetaInfo:  in.MetaInfo,
+		Overwrite: in.Overwrite,
+		Version:   in.Version,
+	}
+
+	for {
+		n, errRead := io.ReadFull(r, buf)
+		// Ошибка чтения данных возвращается как есть
+		if errRead != nil && errRead != io.EOF && errRead != io.ErrUnexpectedEOF {
+			return errRead
+		}
+
+		hash.Write(buf[:n])
+		req.Chunk = buf[:n]
+
+		// Последняя часть отправляется вместе с контрольной суммой
+		if errRead != nil {
+			req.Checksum = hash.Sum(nil)
+		}
+
+		if err = stream.Send(req); err != nil {
+			// Ошибка сервера будет получена в CloseAndRecv
+			if err == io.EOF {
+				break
+			}
+			return serv.parseStreamError(err, "Upload")
+		}
+
+		if errRead != nil {
+			break
+		}
+
+		req = &pb.UploadRequest{}
+	}
+
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return serv.parseStreamError(err, "Upload")
+	}
+
+	return nil
+}
+
+// Download - Потоковое получение данных с записью в w.
+// Если контрольная сумма не совпала, возвращается errs.ErrChecksum.
+func (serv BinaryService) Download(meta string, w io.Writer, token string) (binary_model.Info, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	defer cancel()
+
+	stream, err := serv.rpc.Download(ctx, &pb.DownloadRequest{MetaInfo: meta})
+	if err != nil {
+		return binary_model.Info{}, serv.parseStreamError(err, "Download")
+	}
+
+	hash := sha256.New()
+	for {
+		resp, errRecv := stream.Recv()
+		if errRecv != nil {
+			// Поток завершен без контрольной суммы
+			if errRecv == io.EOF {
+				return binary_model.Info{}, errs.ErrChecksum
+			}
+			return binary_model.Info{}, serv.parseStreamError(errRecv, "Download")
+		}
+
+		if len(resp.Chunk) != 0 {
+			hash.Write(resp.Chunk)
+			// Ошибка записи данных возвращается как есть
+			if _, err = w.Write(resp.Chunk); err != nil {
+				return binary_model.Info{}, err
+			}
+		}
+
+		if len(resp.Checksum) != 0 {
+			if !bytes.Equal(resp.Checksum, hash.Sum(nil)) {
+				return binary_model.Info{}, errs.ErrChecksum
+			}
+
+			// Версия данных передается в последнем сообщении
+			return binary_model.Info{
+				MetaInfo:  meta,
+				Version:   resp.Version,
+				UpdatedAt: resp.UpdatedAt.AsTime(),
+			}, nil
+		}
+	}
+}
+
+// parseStreamError - Преобразование ошибки gRPC потока method в ошибку клиента.
+func (serv BinaryService) parseStreamError(err error, method string) error {
+
+	if e, ok := status.FromError(err); ok {
+		switch e.Code() {
+		case codes.Unavailable:
+			return errs.ErrUnavailable
+
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return errs.ErrUnauthenticated
+
+		case codes.AlreadyExists:
+			return errs.ErrAlreadyExist
+
+		case codes.NotFound:
+			return errs.ErrNotFound
+
+		case codes.DataLoss:
+			return errs.ErrChecksum
+
+		case codes.Aborted:
+			return errs.ErrConflict
+
+		case codes.InvalidArgument:
+			return errs.ErrInvalidArgument
+
+		case codes.FailedPrecondition:
+			return errs.ErrPlaintext
+
+		default:
+			serv.logger.Error(fmt.Sprintf("unknown gRPC error in binary service %s()", method),
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+	}
+
+	return errs.ErrInternal
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv BinaryService) ListRevisions(meta string, token string) ([]revision_model.Revision, error) {
+	data := &pb.ListRevisionsRequest{
 		MetaInfo: meta,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
 	ctx := metadata.NewOutgoingContext(context.Background(), md)
 
-	resp, err := serv.rpc.Get(ctx, data)
+	resp, err := serv.rpc.ListRevisions(ctx, data)
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return nil, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return nil, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return nil, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in binary service ListRevisions()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return nil, errs.ErrInternal
+	}
+
+	revs := make([]revision_model.Revision, 0, len(resp.Revisions))
+	for _, rev := range resp.Revisions {
+		revs = append(revs, revision_model.Revision{
+			Version:   rev.Version,
+			UpdatedAt: rev.UpdatedAt.AsTime(),
+		})
+	}
 
+	return revs, nil
+}
+
+// GetRevision - Получение прежней версии version данных.
+func (serv BinaryService) GetRevision(meta string, version int64, token string) (binary_model.Binary, error) {
+	data := &pb.GetRevisionRequest{
+		MetaInfo: meta,
+		Version:  version,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.GetRevision(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return binary_model.Binary{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return binary_model.Binary{}, errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return binary_model.Binary{}, errs.ErrNotFound
 
 			default:
-				serv.logger.Error("unknown gRPC error in binary service Get()",
+				serv.logger.Error("unknown gRPC error in binary service GetRevision()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
 					zap.String("gRPC text", e.String()))
 			}
@@ -89,28 +366,45 @@ func (serv BinaryService) Get(meta, token string) (binary_model.Binary, error) {
 	}
 
 	return binary_model.Binary{
-		MetaInfo: meta,
-		Data:     resp.Data,
+		MetaInfo:  meta,
+		Data:      resp.Data,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
 	}, nil
 }
 
-func (serv BinaryService) Delete(meta, token string) error {
-	data := &pb.DeleteRequest{
-		MetaInfo: meta,
+// Restore - Восстановление прежней версии version как новой версии данных.
+// current - ожидаемая текущая версия данных.
+func (serv BinaryService) Restore(meta string, version, current int64, token string) error {
+	data := &pb.RestoreRequest{
+		MetaInfo:       meta,
+		Version:        version,
+		CurrentVersion: current,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
 	ctx := metadata.NewOutgoingContext(context.Background(), md)
 
-	if _, err := serv.rpc.Delete(ctx, data); err != nil {
+	if _, err := serv.rpc.Restore(ctx, data); err != nil {
 		if e, ok := status.FromError(err); ok {
-
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
 			default:
-				serv.logger.Error("unknown gRPC error in binary service Delete()",
+				serv.logger.Error("unknown gRPC error in binary service Restore()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
 					zap.String("gRPC text", e.String()))
 			}
@@ -121,28 +415,99 @@ func (serv BinaryService) Delete(meta, token string) error {
 	return nil
 }
 
-func (serv BinaryService) Change(data binary_model.Binary, token string) error {
-	dataReq := &pb.ChangeRequest{
-		MetaInfo: data.MetaInfo,
-		Data:     data.Data,
-	}
+// ListTrash - Получение удаленных данных из корзины.
+func (serv BinaryService) ListTrash(token string) ([]trash_model.Item, error) {
 
 	md := metadata.New(map[string]string{"token": token})
 	ctx := metadata.NewOutgoingContext(context.Background(), md)
 
-	_, err := serv.rpc.Change(ctx, dataReq)
+	resp, err := serv.rpc.ListTrash(ctx, &pb.ListTrashRequest{})
 	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return nil, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return nil, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in binary service ListTrash()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return nil, errs.ErrInternal
+	}
+
+	items := make([]trash_model.Item, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, trash_model.Item{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: item.DeletedAt.AsTime(),
+		})
+	}
+
+	return items, nil
+}
+
+// Undelete - Восстановление данных из корзины.
+func (serv BinaryService) Undelete(meta string, token string) error {
+	data := &pb.UndeleteRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Undelete(ctx, data); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.AlreadyExists:
+				return errs.ErrAlreadyExist
+
 			default:
-				if strings.Contains(err.Error(), "larger than max") {
-					return errs.ErrLargeData
-				}
+				serv.logger.Error("unknown gRPC error in binary service Undelete()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv BinaryService) Purge(meta string, token string) error {
+	data := &pb.PurgeRequest{
+		MetaInfo: meta,
+	}
 
-				serv.logger.Error("unknown gRPC error in binary service Change()",
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Purge(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in binary service Purge()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
 					zap.String("gRPC text", e.String()))
 			}
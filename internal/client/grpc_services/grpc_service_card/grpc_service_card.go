@@ -11,6 +11,9 @@ import (
 	"google.golang.org/grpc/status"
 
 	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/card"
 )
@@ -43,9 +46,18 @@ func (serv CardService) Create(data card_model.Card, token string) error {
 	if _, err := serv.rpc.Create(ctx, dataReq); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.AlreadyExists:
 				return errs.ErrAlreadyExist
 
+			case codes.FailedPrecondition:
+				return errs.ErrPlaintext
+
 			default:
 				serv.logger.Error("unknown gRPC error in card service Create()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -70,6 +82,12 @@ func (serv CardService) Get(meta string, token string) (card_model.Card, error)
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return card_model.Card{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return card_model.Card{}, errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return card_model.Card{}, errs.ErrNotFound
 
@@ -85,16 +103,19 @@ func (serv CardService) Get(meta string, token string) (card_model.Card, error)
 	}
 
 	return card_model.Card{
-		Number:   resp.Number,
-		Period:   resp.Period,
-		CVV:      resp.CVV,
-		FullName: resp.FullName,
+		Number:    resp.Number,
+		Period:    resp.Period,
+		CVV:       resp.CVV,
+		FullName:  resp.FullName,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
 	}, nil
 }
 
-func (serv CardService) Delete(meta string, token string) error {
+func (serv CardService) Delete(meta string, version int64, token string) error {
 	data := &pb.DeleteRequest{
 		MetaInfo: meta,
+		Version:  version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
@@ -103,9 +124,21 @@ func (serv CardService) Delete(meta string, token string) error {
 	if _, err := serv.rpc.Delete(ctx, data); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
 			default:
 				serv.logger.Error("unknown gRPC error in card service Delete()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -125,6 +158,7 @@ func (serv CardService) Change(data card_model.Card, token string) error {
 		Period:   data.Period,
 		CVV:      data.CVV,
 		FullName: data.FullName,
+		Version:  data.Version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
@@ -134,9 +168,24 @@ func (serv CardService) Change(data card_model.Card, token string) error {
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			case codes.FailedPrecondition:
+				return errs.ErrPlaintext
+
 			default:
 				serv.logger.Error("unknown gRPC error in card service Change()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -148,3 +197,269 @@ func (serv CardService) Change(data card_model.Card, token string) error {
 
 	return nil
 }
+
+// List - Получение страницы списка метаинформации.
+func (serv CardService) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	data := &pb.ListRequest{
+		Prefix:    filter.Prefix,
+		Contains:  filter.Contains,
+		PageToken: filter.PageToken,
+		Limit:     uint32(filter.Limit),
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.List(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return list_model.Page{}, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return list_model.Page{}, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in card service List()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return list_model.Page{}, errs.ErrInternal
+	}
+
+	return list_model.Page{
+		MetaInfo:      resp.MetaInfo,
+		Versions:      resp.Versions,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv CardService) ListRevisions(meta string, token string) ([]revision_model.Revision, error) {
+	data := &pb.ListRevisionsRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.ListRevisions(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return nil, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return nil, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return nil, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in card service ListRevisions()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return nil, errs.ErrInternal
+	}
+
+	revs := make([]revision_model.Revision, 0, len(resp.Revisions))
+	for _, rev := range resp.Revisions {
+		revs = append(revs, revision_model.Revision{
+			Version:   rev.Version,
+			UpdatedAt: rev.UpdatedAt.AsTime(),
+		})
+	}
+
+	return revs, nil
+}
+
+// GetRevision - Получение прежней версии version данных.
+func (serv CardService) GetRevision(meta string, version int64, token string) (card_model.Card, error) {
+	data := &pb.GetRevisionRequest{
+		MetaInfo: meta,
+		Version:  version,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.GetRevision(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return card_model.Card{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return card_model.Card{}, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return card_model.Card{}, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in card service GetRevision()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return card_model.Card{}, errs.ErrInternal
+	}
+
+	return card_model.Card{
+		MetaInfo:  meta,
+		Number:    resp.Number,
+		Period:    resp.Period,
+		CVV:       resp.CVV,
+		FullName:  resp.FullName,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии version как новой версии данных.
+// current - ожидаемая текущая версия данных.
+func (serv CardService) Restore(meta string, version, current int64, token string) error {
+	data := &pb.RestoreRequest{
+		MetaInfo:       meta,
+		Version:        version,
+		CurrentVersion: current,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Restore(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			default:
+				serv.logger.Error("unknown gRPC error in card service Restore()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv CardService) ListTrash(token string) ([]trash_model.Item, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.ListTrash(ctx, &pb.ListTrashRequest{})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return nil, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return nil, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in card service ListTrash()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return nil, errs.ErrInternal
+	}
+
+	items := make([]trash_model.Item, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, trash_model.Item{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: item.DeletedAt.AsTime(),
+		})
+	}
+
+	return items, nil
+}
+
+// Undelete - Восстановление данных из корзины.
+func (serv CardService) Undelete(meta string, token string) error {
+	data := &pb.UndeleteRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Undelete(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.AlreadyExists:
+				return errs.ErrAlreadyExist
+
+			default:
+				serv.logger.Error("unknown gRPC error in card service Undelete()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv CardService) Purge(meta string, token string) error {
+	data := &pb.PurgeRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Purge(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in card service Purge()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
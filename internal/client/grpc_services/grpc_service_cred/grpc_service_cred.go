@@ -11,6 +11,9 @@ import (
 	"google.golang.org/grpc/status"
 
 	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/credential"
 )
@@ -42,9 +45,18 @@ func (serv CredService) Create(data cred_model.Credential, token string) error {
 	if _, err := serv.rpc.Create(ctx, dataReq); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.AlreadyExists:
 				return errs.ErrAlreadyExist
 
+			case codes.FailedPrecondition:
+				return errs.ErrPlaintext
+
 			default:
 				serv.logger.Error("unknown gRPC error in cred service Create()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -71,6 +83,12 @@ func (serv CredService) Get(meta string, token string) (cred_model.Credential, e
 		if e, ok := status.FromError(err); ok {
 
 			switch e.Code() {
+			case codes.Unavailable:
+				return cred_model.Credential{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return cred_model.Credential{}, errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return cred_model.Credential{}, errs.ErrNotFound
 
@@ -85,15 +103,18 @@ func (serv CredService) Get(meta string, token string) (cred_model.Credential, e
 	}
 
 	return cred_model.Credential{
-		MetaInfo: meta,
-		Login:    resp.Email,
-		Password: resp.Password,
+		MetaInfo:  meta,
+		Login:     resp.Email,
+		Password:  resp.Password,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
 	}, nil
 }
 
-func (serv CredService) Delete(meta string, token string) error {
+func (serv CredService) Delete(meta string, version int64, token string) error {
 	data := &pb.DeleteRequest{
 		MetaInfo: meta,
+		Version:  version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
@@ -103,9 +124,21 @@ func (serv CredService) Delete(meta string, token string) error {
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
 			default:
 				serv.logger.Error("unknown gRPC error in cred service Delete()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -124,6 +157,7 @@ func (serv CredService) Change(data cred_model.Credential, token string) error {
 		MetaInfo: data.MetaInfo,
 		Email:    data.Login,
 		Password: data.Password,
+		Version:  data.Version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
@@ -132,9 +166,24 @@ func (serv CredService) Change(data cred_model.Credential, token string) error {
 	if _, err := serv.rpc.Change(ctx, dataReq); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			case codes.FailedPrecondition:
+				return errs.ErrPlaintext
+
 			default:
 				serv.logger.Error("unknown gRPC error in cred service Change()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -146,3 +195,267 @@ func (serv CredService) Change(data cred_model.Credential, token string) error {
 
 	return nil
 }
+
+// List - Получение страницы списка метаинформации.
+func (serv CredService) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	data := &pb.ListRequest{
+		Prefix:    filter.Prefix,
+		Contains:  filter.Contains,
+		PageToken: filter.PageToken,
+		Limit:     uint32(filter.Limit),
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.List(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return list_model.Page{}, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return list_model.Page{}, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in cred service List()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return list_model.Page{}, errs.ErrInternal
+	}
+
+	return list_model.Page{
+		MetaInfo:      resp.MetaInfo,
+		Versions:      resp.Versions,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv CredService) ListRevisions(meta string, token string) ([]revision_model.Revision, error) {
+	data := &pb.ListRevisionsRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.ListRevisions(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return nil, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return nil, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return nil, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in cred service ListRevisions()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return nil, errs.ErrInternal
+	}
+
+	revs := make([]revision_model.Revision, 0, len(resp.Revisions))
+	for _, rev := range resp.Revisions {
+		revs = append(revs, revision_model.Revision{
+			Version:   rev.Version,
+			UpdatedAt: rev.UpdatedAt.AsTime(),
+		})
+	}
+
+	return revs, nil
+}
+
+// GetRevision - Получение прежней версии version данных.
+func (serv CredService) GetRevision(meta string, version int64, token string) (cred_model.Credential, error) {
+	data := &pb.GetRevisionRequest{
+		MetaInfo: meta,
+		Version:  version,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.GetRevision(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return cred_model.Credential{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return cred_model.Credential{}, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return cred_model.Credential{}, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in cred service GetRevision()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return cred_model.Credential{}, errs.ErrInternal
+	}
+
+	return cred_model.Credential{
+		MetaInfo:  meta,
+		Login:     resp.Email,
+		Password:  resp.Password,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии version как новой версии данных.
+// current - ожидаемая текущая версия данных.
+func (serv CredService) Restore(meta string, version, current int64, token string) error {
+	data := &pb.RestoreRequest{
+		MetaInfo:       meta,
+		Version:        version,
+		CurrentVersion: current,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Restore(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			default:
+				serv.logger.Error("unknown gRPC error in cred service Restore()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv CredService) ListTrash(token string) ([]trash_model.Item, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.ListTrash(ctx, &pb.ListTrashRequest{})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return nil, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return nil, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in cred service ListTrash()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return nil, errs.ErrInternal
+	}
+
+	items := make([]trash_model.Item, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, trash_model.Item{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: item.DeletedAt.AsTime(),
+		})
+	}
+
+	return items, nil
+}
+
+// Undelete - Восстановление данных из корзины.
+func (serv CredService) Undelete(meta string, token string) error {
+	data := &pb.UndeleteRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Undelete(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.AlreadyExists:
+				return errs.ErrAlreadyExist
+
+			default:
+				serv.logger.Error("unknown gRPC error in cred service Undelete()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv CredService) Purge(meta string, token string) error {
+	data := &pb.PurgeRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Purge(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in cred service Purge()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
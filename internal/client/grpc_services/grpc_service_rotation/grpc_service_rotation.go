@@ -0,0 +1,250 @@
+//go:generate mockgen -source grpc_service_rotation.go -destination mocks/grpc_service_rotation_mock.go -package grpc_service_rotation
+package grpc_service_rotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/rotation_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/pkg/errs"
+	pb "GophKeeper/pkg/proto/rotation"
+)
+
+type RotationService struct {
+	rpc    pb.RotationServiceClient
+	logger *zap.Logger
+}
+
+// NewService - Создание экземпляра сервиса смены ключа шифрования.
+func NewService(conn *grpc.ClientConn) *RotationService {
+	return &RotationService{
+		rpc:    pb.NewRotationServiceClient(conn),
+		logger: zap.L(),
+	}
+}
+
+// Begin - Начало смены ключа или продолжение начатой на этот же ключ.
+// Если начата смена на другой ключ, возвращается errs.ErrConflict.
+func (serv RotationService) Begin(in rotation_model.Rotation, token string) (rotation_model.Rotation, error) {
+
+	resp, err := serv.rpc.Begin(outgoing(token), &pb.BeginRequest{KeyId: in.KeyID, SealedKey: in.SealedKey})
+	if err != nil {
+		return rotation_model.Rotation{}, serv.parseError(err, "Begin")
+	}
+
+	return fromResponse(resp), nil
+}
+
+// Get - Незавершенная смена ключа. errs.ErrNotFound, если смена не начата.
+func (serv RotationService) Get(token string) (rotation_model.Rotation, error) {
+
+	resp, err := serv.rpc.Get(outgoing(token), &pb.Empty{})
+	if err != nil {
+		return rotation_model.Rotation{}, serv.parseError(err, "Get")
+	}
+
+	return fromResponse(resp), nil
+}
+
+// UpdateTexts - Запись пакета перешифрованных текстовых данных.
+func (serv RotationService) UpdateTexts(keyID string, in []text_model.Text, token string) error {
+
+	records := make([]*pb.TextRecord, 0, len(in))
+	for _, data := range in {
+		records = append(records, &pb.TextRecord{
+			MetaInfo: data.MetaInfo,
+			Text:     data.Data,
+			Version:  data.Version,
+		})
+	}
+
+	req := &pb.BatchUpdateRequest{KeyId: keyID, Records: &pb.BatchUpdateRequest_Texts{Texts: &pb.TextRecords{Records: records}}}
+	return serv.batchUpdate(req, token)
+}
+
+// UpdateCreds - Запись пакета перешифрованных логинов и паролей.
+func (serv RotationService) UpdateCreds(keyID string, in []cred_model.Credential, token string) error {
+
+	records := make([]*pb.CredRecord, 0, len(in))
+	for _, data := range in {
+		records = append(records, &pb.CredRecord{
+			MetaInfo: data.MetaInfo,
+			Email:    data.Login,
+			Password: data.Password,
+			Version:  data.Version,
+		})
+	}
+
+	req := &pb.BatchUpdateRequest{KeyId: keyID, Records: &pb.BatchUpdateRequest_Creds{Creds: &pb.CredRecords{Records: records}}}
+	return serv.batchUpdate(req, token)
+}
+
+// UpdateCards - Запись пакета перешифрованных данных карт.
+func (serv RotationService) UpdateCards(keyID string, in []card_model.Card, token string) error {
+
+	records := make([]*pb.CardRecord, 0, len(in))
+	for _, data := range in {
+		records = append(records, &pb.CardRecord{
+			MetaInfo: data.MetaInfo,
+			Number:   data.Number,
+			Period:   data.Period,
+			CVV:      data.CVV,
+			FullName: data.FullName,
+			Version:  data.Version,
+		})
+	}
+
+	req := &pb.BatchUpdateRequest{KeyId: keyID, Records: &pb.BatchUpdateRequest_Cards{Cards: &pb.CardRecords{Records: records}}}
+	return serv.batchUpdate(req, token)
+}
+
+// UpdateBinaries - Запись пакета перешифрованных бинарных данных.
+func (serv RotationService) UpdateBinaries(keyID string, in []binary_model.Binary, token string) error {
+
+	records := make([]*pb.BinaryRecord, 0, len(in))
+	for _, data := range in {
+		records = append(records, &pb.BinaryRecord{
+			MetaInfo: data.MetaInfo,
+			Data:     data.Data,
+			Version:  data.Version,
+		})
+	}
+
+	req := &pb.BatchUpdateRequest{KeyId: keyID, Records: &pb.BatchUpdateRequest_Binaries{Binaries: &pb.BinaryRecords{Records: records}}}
+	return serv.batchUpdate(req, token)
+}
+
+// UpdateBinary - Потоковая замена бинарных данных in.MetaInfo перешифрованными
+// данными из r. Для данных, которые не помещаются в пакет.
+func (serv RotationService) UpdateBinary(keyID string, in binary_model.Upload, r io.Reader, token string) error {
+
+	ctx, cancel := context.WithCancel(outgoing(token))
+	defer cancel()
+
+	stream, err := serv.rpc.UpdateBinary(ctx)
+	if err != nil {
+		return serv.parseError(err, "UpdateBinary")
+	}
+
+	hash := sha256.New()
+	buf := make([]byte, binary_model.ChunkSize)
+	req := &pb.BinaryChunk{
+		KeyId:    keyID,
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+	}
+
+	for {
+		n, errRead := io.ReadFull(r, buf)
+		// Ошибка чтения данных возвращается как есть
+		if errRead != nil && errRead != io.EOF && errRead != io.ErrUnexpectedEOF {
+			return errRead
+		}
+
+		hash.Write(buf[:n])
+		req.Chunk = buf[:n]
+
+		// Последняя часть отправляется вместе с контрольной суммой
+		if errRead != nil {
+			req.Checksum = hash.Sum(nil)
+		}
+
+		if err = stream.Send(req); err != nil {
+			// Ошибка сервера будет получена в CloseAndRecv
+			if err == io.EOF {
+				break
+			}
+			return serv.parseError(err, "UpdateBinary")
+		}
+
+		if errRead != nil {
+			break
+		}
+
+		req = &pb.BinaryChunk{}
+	}
+
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return serv.parseError(err, "UpdateBinary")
+	}
+
+	return nil
+}
+
+// Finish - Завершение смены ключа keyID.
+func (serv RotationService) Finish(keyID string, token string) error {
+
+	if _, err := serv.rpc.Finish(outgoing(token), &pb.FinishRequest{KeyId: keyID}); err != nil {
+		return serv.parseError(err, "Finish")
+	}
+
+	return nil
+}
+
+// batchUpdate - Отправка пакета записей.
+func (serv RotationService) batchUpdate(req *pb.BatchUpdateRequest, token string) error {
+
+	if _, err := serv.rpc.BatchUpdate(outgoing(token), req); err != nil {
+		return serv.parseError(err, "BatchUpdate")
+	}
+
+	return nil
+}
+
+// parseError - Преобразование ошибки gRPC метода method в ошибку клиента.
+func (serv RotationService) parseError(err error, method string) error {
+
+	if e, ok := status.FromError(err); ok {
+		switch e.Code() {
+		case codes.Unavailable:
+			return errs.ErrUnavailable
+
+		case codes.NotFound:
+			return errs.ErrNotFound
+
+		case codes.Aborted:
+			return errs.ErrConflict
+
+		case codes.InvalidArgument:
+			return errs.ErrInvalidArgument
+
+		case codes.FailedPrecondition:
+			return errs.ErrNoRotation
+
+		case codes.DataLoss:
+			return errs.ErrChecksum
+
+		default:
+			serv.logger.Error("unknown gRPC error in rotation service "+method+"()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+	}
+
+	return errs.ErrInternal
+}
+
+// outgoing - Контекст запроса с токеном доступа.
+func outgoing(token string) context.Context {
+	md := metadata.New(map[string]string{"token": token})
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+func fromResponse(resp *pb.Rotation) rotation_model.Rotation {
+	return rotation_model.Rotation{
+		KeyID:     resp.KeyId,
+		SealedKey: resp.SealedKey,
+		StartedAt: resp.StartedAt.AsTime(),
+	}
+}
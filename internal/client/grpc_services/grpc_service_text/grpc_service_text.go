@@ -11,7 +11,10 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/revision_model"
 	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/internal/client/model/trash_model"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/text"
 )
@@ -45,9 +48,18 @@ func (serv TextService) Create(data text_model.Text, token string) error {
 
 	if e, ok := status.FromError(err); ok {
 		switch e.Code() {
+		case codes.Unavailable:
+			return errs.ErrUnavailable
+
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return errs.ErrUnauthenticated
+
 		case codes.AlreadyExists:
 			return errs.ErrAlreadyExist
 
+		case codes.FailedPrecondition:
+			return errs.ErrPlaintext
+
 		default:
 			if strings.Contains(err.Error(), "larger than max") {
 				return errs.ErrLargeData
@@ -75,6 +87,12 @@ func (serv TextService) Get(meta, token string) (text_model.Text, error) {
 		if e, ok := status.FromError(err); ok {
 
 			switch e.Code() {
+			case codes.Unavailable:
+				return text_model.Text{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return text_model.Text{}, errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return text_model.Text{}, errs.ErrNotFound
 
@@ -88,14 +106,17 @@ func (serv TextService) Get(meta, token string) (text_model.Text, error) {
 	}
 
 	return text_model.Text{
-		MetaInfo: meta,
-		Data:     resp.Text,
+		MetaInfo:  meta,
+		Data:      resp.Text,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
 	}, nil
 }
 
-func (serv TextService) Delete(meta, token string) error {
+func (serv TextService) Delete(meta string, version int64, token string) error {
 	data := &pb.DeleteRequest{
 		MetaInfo: meta,
+		Version:  version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
@@ -104,8 +125,20 @@ func (serv TextService) Delete(meta, token string) error {
 	if _, err := serv.rpc.Delete(ctx, data); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
+
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
 			default:
 				serv.logger.Error("unknown gRPC error in text service Delete()",
 					zap.Uint32("gRPC code", uint32(e.Code())),
@@ -122,6 +155,7 @@ func (serv TextService) Change(data text_model.Text, token string) error {
 	dataReq := &pb.ChangeRequest{
 		MetaInfo: data.MetaInfo,
 		Text:     data.Data,
+		Version:  data.Version,
 	}
 
 	md := metadata.New(map[string]string{"token": token})
@@ -131,9 +165,24 @@ func (serv TextService) Change(data text_model.Text, token string) error {
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
 			case codes.NotFound:
 				return errs.ErrNotFound
 
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			case codes.FailedPrecondition:
+				return errs.ErrPlaintext
+
 			default:
 				if strings.Contains(err.Error(), "larger than max") {
 					return errs.ErrLargeData
@@ -149,3 +198,266 @@ func (serv TextService) Change(data text_model.Text, token string) error {
 
 	return nil
 }
+
+// List - Получение страницы списка метаинформации.
+func (serv TextService) List(filter list_model.Filter, token string) (list_model.Page, error) {
+	data := &pb.ListRequest{
+		Prefix:    filter.Prefix,
+		Contains:  filter.Contains,
+		PageToken: filter.PageToken,
+		Limit:     uint32(filter.Limit),
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.List(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return list_model.Page{}, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return list_model.Page{}, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in text service List()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return list_model.Page{}, errs.ErrInternal
+	}
+
+	return list_model.Page{
+		MetaInfo:      resp.MetaInfo,
+		Versions:      resp.Versions,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv TextService) ListRevisions(meta string, token string) ([]revision_model.Revision, error) {
+	data := &pb.ListRevisionsRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.ListRevisions(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return nil, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return nil, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return nil, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in text service ListRevisions()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return nil, errs.ErrInternal
+	}
+
+	revs := make([]revision_model.Revision, 0, len(resp.Revisions))
+	for _, rev := range resp.Revisions {
+		revs = append(revs, revision_model.Revision{
+			Version:   rev.Version,
+			UpdatedAt: rev.UpdatedAt.AsTime(),
+		})
+	}
+
+	return revs, nil
+}
+
+// GetRevision - Получение прежней версии version данных.
+func (serv TextService) GetRevision(meta string, version int64, token string) (text_model.Text, error) {
+	data := &pb.GetRevisionRequest{
+		MetaInfo: meta,
+		Version:  version,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.GetRevision(ctx, data)
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return text_model.Text{}, errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return text_model.Text{}, errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return text_model.Text{}, errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in text service GetRevision()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return text_model.Text{}, errs.ErrInternal
+	}
+
+	return text_model.Text{
+		MetaInfo:  meta,
+		Data:      resp.Text,
+		Version:   resp.Version,
+		UpdatedAt: resp.UpdatedAt.AsTime(),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии version как новой версии данных.
+// current - ожидаемая текущая версия данных.
+func (serv TextService) Restore(meta string, version, current int64, token string) error {
+	data := &pb.RestoreRequest{
+		MetaInfo:       meta,
+		Version:        version,
+		CurrentVersion: current,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Restore(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.Aborted:
+				return errs.ErrConflict
+
+			case codes.InvalidArgument:
+				return errs.ErrInvalidArgument
+
+			default:
+				serv.logger.Error("unknown gRPC error in text service Restore()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv TextService) ListTrash(token string) ([]trash_model.Item, error) {
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	resp, err := serv.rpc.ListTrash(ctx, &pb.ListTrashRequest{})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.Unavailable {
+				return nil, errs.ErrUnavailable
+			}
+			if e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied {
+				return nil, errs.ErrUnauthenticated
+			}
+
+			serv.logger.Error("unknown gRPC error in text service ListTrash()",
+				zap.Uint32("gRPC code", uint32(e.Code())),
+				zap.String("gRPC text", e.String()))
+		}
+		return nil, errs.ErrInternal
+	}
+
+	items := make([]trash_model.Item, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, trash_model.Item{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: item.DeletedAt.AsTime(),
+		})
+	}
+
+	return items, nil
+}
+
+// Undelete - Восстановление данных из корзины.
+func (serv TextService) Undelete(meta string, token string) error {
+	data := &pb.UndeleteRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Undelete(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			case codes.AlreadyExists:
+				return errs.ErrAlreadyExist
+
+			default:
+				serv.logger.Error("unknown gRPC error in text service Undelete()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv TextService) Purge(meta string, token string) error {
+	data := &pb.PurgeRequest{
+		MetaInfo: meta,
+	}
+
+	md := metadata.New(map[string]string{"token": token})
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	if _, err := serv.rpc.Purge(ctx, data); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.Unavailable:
+				return errs.ErrUnavailable
+
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return errs.ErrUnauthenticated
+
+			case codes.NotFound:
+				return errs.ErrNotFound
+
+			default:
+				serv.logger.Error("unknown gRPC error in text service Purge()",
+					zap.Uint32("gRPC code", uint32(e.Code())),
+					zap.String("gRPC text", e.String()))
+			}
+		}
+		return errs.ErrInternal
+	}
+
+	return nil
+}
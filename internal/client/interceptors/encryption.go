@@ -0,0 +1,64 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"GophKeeper/pkg/secret"
+)
+
+// EncryptionHeader - Перехватчик gRPC клиента, сообщающий серверу в метаданных
+// каждого запроса, шифрует ли клиент данные.
+type EncryptionHeader struct {
+	value string
+}
+
+// NewEncryptionHeader - Создание перехватчика. encrypted - данные шифруются на клиенте.
+func NewEncryptionHeader(encrypted bool) *EncryptionHeader {
+
+	value := secret.EncryptionNone
+	if encrypted {
+		value = secret.EncryptionEnvelope
+	}
+
+	return &EncryptionHeader{value: value}
+}
+
+// DialOptions - Опции соединения с перехватчиками unary и stream запросов.
+func (e *EncryptionHeader) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(e.Unary),
+		grpc.WithChainStreamInterceptor(e.Stream),
+	}
+}
+
+// Unary - Перехватчик unary запросов.
+func (e *EncryptionHeader) Unary(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption) error {
+
+	return invoker(e.withHeader(ctx), method, req, reply, cc, opts...)
+}
+
+// Stream - Перехватчик потоковых запросов.
+func (e *EncryptionHeader) Stream(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+	return streamer(e.withHeader(ctx), desc, cc, method, opts...)
+}
+
+// withHeader - Добавление заголовка шифрования в исходящие метаданные ctx.
+func (e *EncryptionHeader) withHeader(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, secret.EncryptionHeader, e.value)
+}
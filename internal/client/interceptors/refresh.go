@@ -0,0 +1,206 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "GophKeeper/pkg/proto/auth"
+	"GophKeeper/pkg/token"
+)
+
+// refreshMargin - Запас времени до окончания действия токена,
+// при котором токен обновляется перед потоковым запросом.
+const refreshMargin = 30 * time.Second
+
+const (
+	methodRegister  = "/auth.AuthService/Register"
+	methodLogin     = "/auth.AuthService/Login"
+	methodVerifyOTP = "/auth.AuthService/VerifyOTP"
+	methodRefresh   = "/auth.AuthService/Refresh"
+)
+
+// TokenRefresher - Перехватчик gRPC клиента, продлевающий сессию пользователя.
+// Запоминает токены из ответов Register, Login и VerifyOTP, подставляет в запросы
+// актуальный токен доступа и при его истечении получает новые токены через Refresh.
+type TokenRefresher struct {
+	mutex sync.Mutex
+	// renewing - Последовательное обновление: refresh token одноразовый
+	renewing sync.Mutex
+	access   string
+	refresh  string
+	// onUpdate - Вызывается после получения новых токенов.
+	onUpdate func(access, refresh string)
+	logger   *zap.Logger
+}
+
+type RefresherOptions func(r *TokenRefresher)
+
+// NewTokenRefresher - Создание перехватчика, продлевающего сессию.
+func NewTokenRefresher(opts ...RefresherOptions) *TokenRefresher {
+	r := &TokenRefresher{
+		logger: zap.L(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithOnUpdate - Обработка новых токенов, например сохранение сессии на диске:
+// refresh token одноразовый, и после обновления прежний уже недействителен.
+func WithOnUpdate(fn func(access, refresh string)) RefresherOptions {
+	return func(r *TokenRefresher) {
+		r.onUpdate = fn
+	}
+}
+
+// DialOptions - Опции соединения с перехватчиками unary и stream запросов.
+func (r *TokenRefresher) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(r.Unary),
+		grpc.WithChainStreamInterceptor(r.Stream),
+	}
+}
+
+// Unary - Перехватчик unary запросов.
+// Если сервер отклонил токен доступа, токены обновляются и запрос повторяется один раз.
+func (r *TokenRefresher) Unary(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption) error {
+
+	switch method {
+	case methodRegister, methodLogin, methodVerifyOTP, methodRefresh:
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if resp, ok := reply.(*pb.AuthResponse); ok && err == nil {
+			r.set(resp)
+		}
+		return err
+	}
+
+	ctx, used := r.withToken(ctx)
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if status.Code(err) != codes.PermissionDenied || len(used) == 0 {
+		return err
+	}
+
+	if errRefresh := r.renew(ctx, cc, used); errRefresh != nil {
+		return err
+	}
+
+	ctx, _ = r.withToken(ctx)
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// Stream - Перехватчик потоковых запросов.
+// Данные потока повторно не отправить, поэтому токен, который скоро истечет,
+// обновляется до начала запроса.
+func (r *TokenRefresher) Stream(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+	if used, expiring := r.expiring(); expiring {
+		if err := r.renew(ctx, cc, used); err != nil {
+			r.logger.Error("failed refresh token", zap.Error(err))
+		}
+	}
+
+	ctx, _ = r.withToken(ctx)
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+// withToken - Подстановка актуального токена доступа в метаданные запроса.
+// Возвращает токен, с которым будет выполнен запрос.
+func (r *TokenRefresher) withToken(ctx context.Context) (context.Context, string) {
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok || len(md.Get("token")) == 0 {
+		return ctx, ``
+	}
+
+	r.mutex.Lock()
+	access := r.access
+	r.mutex.Unlock()
+
+	if len(access) == 0 {
+		return ctx, md.Get("token")[0]
+	}
+
+	md = md.Copy()
+	md.Set("token", access)
+	return metadata.NewOutgoingContext(ctx, md), access
+}
+
+// expiring - Проверка, что токен доступа истекает в ближайшее время.
+func (r *TokenRefresher) expiring() (string, bool) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.access) == 0 || len(r.refresh) == 0 {
+		return r.access, false
+	}
+
+	expiresAt, err := token.ExpiresAt(r.access)
+	if err != nil {
+		return r.access, false
+	}
+
+	return r.access, time.Until(expiresAt) < refreshMargin
+}
+
+// renew - Получение новых токенов взамен токена доступа used.
+// Если токены уже обновлены другим запросом, повторно они не запрашиваются.
+// Новые токены сохраняет Unary при обработке ответа Refresh.
+func (r *TokenRefresher) renew(ctx context.Context, cc *grpc.ClientConn, used string) error {
+
+	r.renewing.Lock()
+	defer r.renewing.Unlock()
+
+	r.mutex.Lock()
+	access, refresh := r.access, r.refresh
+	r.mutex.Unlock()
+
+	if access != used && len(access) != 0 {
+		return nil
+	}
+
+	if len(refresh) == 0 {
+		return status.Error(codes.Unauthenticated, "no refresh token")
+	}
+
+	_, err := pb.NewAuthServiceClient(cc).Refresh(ctx, &pb.RefreshRequest{
+		RefreshToken: refresh,
+	})
+	return err
+}
+
+// set - Сохранение токенов из ответа сервера авторизации.
+func (r *TokenRefresher) set(resp *pb.AuthResponse) {
+
+	r.mutex.Lock()
+	r.access = resp.Token
+	r.refresh = resp.RefreshToken
+	r.mutex.Unlock()
+
+	if r.onUpdate != nil && len(resp.Token) != 0 {
+		r.onUpdate(resp.Token, resp.RefreshToken)
+	}
+}
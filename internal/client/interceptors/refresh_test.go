@@ -0,0 +1,212 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "GophKeeper/pkg/proto/auth"
+)
+
+// fakeAuth - Сервер авторизации, принимающий только последний выданный токен доступа.
+// Logout выполняется как обычный запрос с токеном.
+type fakeAuth struct {
+	pb.UnimplementedAuthServiceServer
+
+	mutex   sync.Mutex
+	access  string
+	refresh string
+	// failRefresh - Refresh отклоняет любой токен
+	failRefresh bool
+	refreshes   int
+	// calls - Токены, с которыми выполнялся Logout
+	calls []string
+	// expired - Запросы с истекшим токеном ждут друг друга,
+	// чтобы все они были отклонены до обновления токенов
+	expired *sync.WaitGroup
+}
+
+func (s *fakeAuth) Login(context.Context, *pb.AuthRequest) (*pb.AuthResponse, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return &pb.AuthResponse{Token: s.access, RefreshToken: s.refresh}, nil
+}
+
+func (s *fakeAuth) Refresh(_ context.Context, in *pb.RefreshRequest) (*pb.AuthResponse, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.refreshes++
+	if s.failRefresh || in.RefreshToken != s.refresh {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	s.access = "access-2"
+	s.refresh = "refresh-2"
+	return &pb.AuthResponse{Token: s.access, RefreshToken: s.refresh}, nil
+}
+
+func (s *fakeAuth) Logout(ctx context.Context, _ *pb.Empty) (*pb.Empty, error) {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	used := md.Get("token")[0]
+
+	s.mutex.Lock()
+	s.calls = append(s.calls, used)
+	valid := used == s.access
+	s.mutex.Unlock()
+
+	if valid {
+		return &pb.Empty{}, nil
+	}
+
+	if s.expired != nil {
+		s.expired.Done()
+		s.expired.Wait()
+	}
+
+	return nil, status.Error(codes.PermissionDenied, "token expired")
+}
+
+// dial - Соединение с сервером srv через перехватчик r.
+// Токены "access-1" и "refresh-1" перехватчик получает из ответа Login.
+func dial(t *testing.T, srv *fakeAuth, r *TokenRefresher) pb.AuthServiceClient {
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	s := grpc.NewServer()
+	pb.RegisterAuthServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	opts := append(r.DialOptions(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}))
+
+	conn, err := grpc.Dial("bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	srv.access, srv.refresh = "access-1", "refresh-1"
+
+	client := pb.NewAuthServiceClient(conn)
+	_, err = client.Login(context.Background(), &pb.AuthRequest{})
+	require.NoError(t, err)
+
+	return client
+}
+
+// withToken - Контекст запроса с токеном доступа.
+func withToken(t *testing.T, access string) context.Context {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	return metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{"token": access}))
+}
+
+func TestTokenRefresher_Unary(t *testing.T) {
+
+	const calls = 5
+
+	var expired sync.WaitGroup
+	expired.Add(calls)
+	srv := &fakeAuth{expired: &expired}
+
+	var updates [][2]string
+	r := NewTokenRefresher(WithOnUpdate(func(access, refresh string) {
+		updates = append(updates, [2]string{access, refresh})
+	}))
+
+	client := dial(t, srv, r)
+
+	// Срок действия токена истек, запросы с ним отклоняются
+	srv.mutex.Lock()
+	srv.access = "access-0"
+	srv.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make([]error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.Logout(withToken(t, "access-1"), &pb.Empty{})
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	// Токены обновлены один раз, каждый запрос повторен один раз с новым токеном
+	require.Equal(t, 1, srv.refreshes)
+	require.Len(t, srv.calls, 2*calls)
+
+	var retried int
+	for _, used := range srv.calls {
+		if used == "access-2" {
+			retried++
+		}
+	}
+	require.Equal(t, calls, retried)
+
+	require.Equal(t, [][2]string{{"access-1", "refresh-1"}, {"access-2", "refresh-2"}}, updates)
+
+	// Следующие запросы сразу выполняются с новым токеном
+	_, err := client.Logout(withToken(t, "access-1"), &pb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, "access-2", srv.calls[len(srv.calls)-1])
+	require.Equal(t, 1, srv.refreshes)
+}
+
+func TestTokenRefresher_UnaryRefreshFailed(t *testing.T) {
+
+	srv := &fakeAuth{failRefresh: true}
+	client := dial(t, srv, NewTokenRefresher())
+
+	srv.mutex.Lock()
+	srv.access = "access-0"
+	srv.mutex.Unlock()
+
+	// Ошибка обновления не скрывает исходную ошибку запроса, запрос не повторяется
+	_, err := client.Logout(withToken(t, "access-1"), &pb.Empty{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.Equal(t, "token expired", status.Convert(err).Message())
+
+	require.Equal(t, 1, srv.refreshes)
+	require.Equal(t, []string{"access-1"}, srv.calls)
+
+	// Без refresh token обновление не запрашивается
+	srv = &fakeAuth{}
+	r := NewTokenRefresher()
+	client = dial(t, srv, r)
+
+	r.mutex.Lock()
+	r.refresh = ``
+	r.mutex.Unlock()
+
+	srv.mutex.Lock()
+	srv.access = "access-0"
+	srv.mutex.Unlock()
+
+	_, err = client.Logout(withToken(t, "access-1"), &pb.Empty{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.Zero(t, srv.refreshes)
+}
@@ -1,6 +1,36 @@
 package auth_model
 
+import "time"
+
 type Credential struct {
 	Email    string
 	Password string
 }
+
+// Session - Сессия пользователя.
+// Пустой Token - работа без связи с сервером, только с локальным кэшем.
+type Session struct {
+	Email string
+	Token string
+}
+
+// Offline - Сессия без авторизации на сервере.
+func (s Session) Offline() bool {
+	return len(s.Token) == 0
+}
+
+// SessionInfo - Открытая сессия пользователя на сервере.
+// Current - сессия этого клиента.
+type SessionInfo struct {
+	ID        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Current   bool
+}
+
+// Enrollment - Подключение второго фактора: ссылка otpauth:// для
+// приложения-аутентификатора и одноразовые коды восстановления.
+type Enrollment struct {
+	URI           string
+	RecoveryCodes []string
+}
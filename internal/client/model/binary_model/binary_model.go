@@ -1,6 +1,39 @@
 package binary_model
 
+import (
+	"os"
+	"time"
+)
+
+// ChunkSize - Размер части данных при потоковой передаче.
+const ChunkSize = 1024 * 256
+
 type Binary struct {
+	MetaInfo  string
+	Data      []byte
+	Version   int64
+	UpdatedAt time.Time
+}
+
+// Upload - Параметры потоковой загрузки данных.
+type Upload struct {
 	MetaInfo string
-	Data     []byte
+	// Overwrite - Замена существующих данных, иначе создание новых.
+	Overwrite bool
+	// Version - При замене ожидаемая текущая версия, обязательна.
+	Version int64
+}
+
+// Info - Сведения о полученных данных.
+type Info struct {
+	MetaInfo  string
+	Version   int64
+	UpdatedAt time.Time
+}
+
+// FileInfo - Сведения об исходном файле бинарных данных.
+type FileInfo struct {
+	Name string      `json:"name"`
+	Size int64       `json:"size"`
+	Mode os.FileMode `json:"mode"`
 }
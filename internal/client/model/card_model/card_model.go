@@ -1,5 +1,7 @@
 package card_model
 
+import "time"
+
 type Card struct {
 	// MetaInfo - Метаинформация для хранимых данных
 	MetaInfo string
@@ -11,4 +13,8 @@ type Card struct {
 	CVV []byte
 	// FullName - Полное имя держателя карты
 	FullName []byte
+	// Version - Версия данных на сервере. При изменении - ожидаемая текущая версия
+	Version int64
+	// UpdatedAt - Время последнего изменения
+	UpdatedAt time.Time
 }
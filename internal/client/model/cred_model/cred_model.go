@@ -1,7 +1,11 @@
 package cred_model
 
+import "time"
+
 type Credential struct {
-	MetaInfo string
-	Login    []byte
-	Password []byte
+	MetaInfo  string
+	Login     []byte
+	Password  []byte
+	Version   int64
+	UpdatedAt time.Time
 }
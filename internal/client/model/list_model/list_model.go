@@ -0,0 +1,26 @@
+package list_model
+
+// PageSize - Количество записей на одной странице списка в меню.
+const PageSize = 20
+
+// Filter - Параметры получения страницы списка метаинформации.
+type Filter struct {
+	// Prefix - Метаинформация должна начинаться с этой строки
+	Prefix string
+	// Contains - Метаинформация должна содержать эту строку
+	Contains string
+	// PageToken - Токен страницы. Пустой - первая страница
+	PageToken string
+	// Limit - Размер страницы. 0 - размер по умолчанию сервера
+	Limit int
+}
+
+// Page - Страница списка метаинформации.
+type Page struct {
+	// MetaInfo - Метаинформация записей
+	MetaInfo []string
+	// Versions - Версии записей в порядке MetaInfo. Пусто, если сервер их не передал
+	Versions []int64
+	// NextPageToken - Токен следующей страницы. Пустой, если страница последняя
+	NextPageToken string
+}
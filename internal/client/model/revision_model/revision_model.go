@@ -0,0 +1,9 @@
+package revision_model
+
+import "time"
+
+// Revision - Прежняя версия данных на сервере.
+type Revision struct {
+	Version   int64
+	UpdatedAt time.Time
+}
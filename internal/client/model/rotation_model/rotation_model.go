@@ -0,0 +1,12 @@
+package rotation_model
+
+import "time"
+
+// Rotation - Незавершенная смена ключа шифрования на сервере.
+type Rotation struct {
+	// KeyID - Идентификатор нового ключа в hex
+	KeyID string
+	// SealedKey - Новый ключ хранилища, зашифрованный мастер-паролем
+	SealedKey []byte
+	StartedAt time.Time
+}
@@ -1,6 +1,10 @@
 package text_model
 
+import "time"
+
 type Text struct {
-	MetaInfo string
-	Data     []byte
+	MetaInfo  string
+	Data      []byte
+	Version   int64
+	UpdatedAt time.Time
 }
@@ -0,0 +1,9 @@
+package trash_model
+
+import "time"
+
+// Item - Удаленные данные в корзине на сервере.
+type Item struct {
+	MetaInfo  string
+	DeletedAt time.Time
+}
@@ -0,0 +1,330 @@
+// Package session - Сохранение сессии клиента между запусками.
+//
+// Сессия каждого профиля хранится в отдельном файле <profile>.json.
+// Адрес сервера, email и ключ хранилища, зашифрованный мастер-паролем,
+// записываются открыто: они нужны, чтобы открыть сессию. Токены шифруются
+// AES-256-GCM ключом, полученным из закрытого ключа шифрования данных,
+// поэтому без ключа или мастер-пароля сессией не воспользоваться.
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/pkg/errs"
+)
+
+const (
+	// DefaultProfile - Профиль, если он не задан.
+	DefaultProfile = "default"
+	fileExt        = ".json"
+	dirMode        = 0700
+	fileMode       = 0600
+)
+
+// profileName - Допустимое имя профиля, оно же имя файла.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Saved - Открытая часть сохраненной сессии.
+type Saved struct {
+	Address string `json:"address"`
+	Email   string `json:"email"`
+	// SealedKey - Ключ хранилища, зашифрованный мастер-паролем.
+	// Пустой - данные шифруются ключом из файла.
+	SealedKey []byte `json:"sealed_key,omitempty"`
+}
+
+// file - Содержимое файла сессии.
+type file struct {
+	Saved
+	// Tokens - Зашифрованные tokens.
+	Tokens []byte `json:"tokens"`
+}
+
+// tokens - Токены сессии.
+type tokens struct {
+	Access  string `json:"access"`
+	Refresh string `json:"refresh,omitempty"`
+}
+
+// Store - Сессия профиля на диске.
+//
+// Токены, полученные до Unlock или Save, запоминаются и записываются,
+// как только станет известен ключ шифрования.
+type Store struct {
+	mutex sync.Mutex
+
+	path string
+	addr string
+
+	saved  Saved
+	tokens tokens
+	// key - Ключ шифрования токенов, задается в Unlock или Save.
+	key []byte
+
+	logger *zap.Logger
+}
+
+// NewStore - Сессия профиля profile для сервера addr в каталоге root.
+func NewStore(root, profile, addr string) (*Store, error) {
+
+	if err := validProfile(profile); err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		path:   filepath.Join(root, profile+fileExt),
+		addr:   addr,
+		logger: zap.L(),
+	}, nil
+}
+
+// DefaultDir - Каталог сессий по умолчанию в конфигурационном каталоге пользователя
+// ($XDG_CONFIG_HOME/gophkeeper/profiles).
+func DefaultDir() string {
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "gophkeeper", "profiles")
+}
+
+// Address - Адрес сервера сохраненной сессии профиля profile.
+// Если сессии нет, возвращается пустая строка.
+func Address(root, profile string) string {
+
+	if validProfile(profile) != nil {
+		return ``
+	}
+
+	f, err := read(filepath.Join(root, profile+fileExt))
+	if err != nil {
+		return ``
+	}
+
+	return f.Address
+}
+
+// Load - Открытая часть сохраненной сессии.
+// Если сессии нет или она создана для другого сервера, возвращается errs.ErrNotFound.
+func (s *Store) Load() (Saved, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := read(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Saved{}, errs.ErrNotFound
+	}
+	if err != nil {
+		return Saved{}, err
+	}
+
+	if f.Address != s.addr || len(f.Tokens) == 0 {
+		return Saved{}, errs.ErrNotFound
+	}
+
+	return f.Saved, nil
+}
+
+// Unlock - Расшифровка токенов сохраненной сессии ключом privKey.
+// Возвращает токен доступа и refresh token.
+func (s *Store) Unlock(privKey *rsa.PrivateKey) (string, string, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := read(s.path)
+	if err != nil {
+		return ``, ``, err
+	}
+
+	key := deriveKey(privKey)
+	data, err := decrypt(key, f.Tokens)
+	if err != nil {
+		return ``, ``, fmt.Errorf("session is encrypted with another key: %w", err)
+	}
+
+	var t tokens
+	if err = json.Unmarshal(data, &t); err != nil {
+		return ``, ``, err
+	}
+
+	s.key = key
+	s.saved = f.Saved
+	s.tokens = t
+
+	return t.Access, t.Refresh, nil
+}
+
+// Save - Сохранение сессии пользователя email с токенами, полученными последними.
+// Токены шифруются ключом privKey, sealed - ключ хранилища, если он используется.
+func (s *Store) Save(email string, sealed []byte, privKey *rsa.PrivateKey) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.key = deriveKey(privKey)
+	s.saved = Saved{
+		Address:   s.addr,
+		Email:     strings.TrimSpace(email),
+		SealedKey: sealed,
+	}
+
+	return s.write()
+}
+
+// Update - Новые токены сессии. Если ключ шифрования уже известен,
+// сессия сразу перезаписывается.
+func (s *Store) Update(access, refresh string) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tokens = tokens{Access: access, Refresh: refresh}
+	if s.key == nil {
+		return
+	}
+
+	if err := s.write(); err != nil {
+		s.logger.Error("failed save session", zap.Error(err))
+	}
+}
+
+// Remove - Удаление сохраненной сессии.
+func (s *Store) Remove() error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.key = nil
+	s.tokens = tokens{}
+
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// write - Запись сессии через временный файл, чтобы при сбое
+// не остался поврежденный файл.
+func (s *Store) write() error {
+
+	if len(s.tokens.Access) == 0 && len(s.tokens.Refresh) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(s.tokens)
+	if err != nil {
+		return err
+	}
+
+	enc, err := encrypt(s.key, data)
+	if err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(file{Saved: s.saved, Tokens: enc}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.path), dirMode); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, fileMode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+func read(path string) (file, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file{}, err
+	}
+
+	var f file
+	if err = json.Unmarshal(data, &f); err != nil {
+		return file{}, fmt.Errorf("session file is corrupted: %w", err)
+	}
+
+	return f, nil
+}
+
+func validProfile(profile string) error {
+
+	if !profileName.MatchString(profile) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", profile)
+	}
+
+	return nil
+}
+
+// deriveKey - Ключ шифрования токенов из закрытого ключа клиента.
+func deriveKey(privKey *rsa.PrivateKey) []byte {
+	key := sha256.Sum256(append([]byte("gophkeeper-session:"), x509.MarshalPKCS1PrivateKey(privKey)...))
+	return key[:]
+}
+
+func encrypt(key, data []byte) ([]byte, error) {
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func decrypt(key, data []byte) ([]byte, error) {
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("session file is corrupted")
+	}
+
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
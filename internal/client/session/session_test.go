@@ -0,0 +1,201 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/pkg/errs"
+)
+
+const testAddr = "localhost:3200"
+
+// testKeys - Закрытые ключи клиента для тестов, генерируются один раз.
+var testKeys = func() [2]*rsa.PrivateKey {
+	var keys [2]*rsa.PrivateKey
+	for i := range keys {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}()
+
+// newStore - Сессия профиля profile в каталоге root.
+func newStore(t *testing.T, root, profile string) *Store {
+	s, err := NewStore(root, profile, testAddr)
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore_SaveUnlock(t *testing.T) {
+
+	root := filepath.Join(t.TempDir(), "profiles")
+
+	// Токены, полученные до Save, записываются при сохранении
+	s := newStore(t, root, DefaultProfile)
+	s.Update("access-1", "refresh-1")
+
+	_, err := os.Stat(root)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, s.Save(" test@email.ru ", []byte("sealed"), testKeys[0]))
+
+	path := filepath.Join(root, DefaultProfile+fileExt)
+
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(fileMode), stat.Mode().Perm())
+
+	stat, err = os.Stat(root)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(dirMode), stat.Mode().Perm())
+
+	// Токены записываются только в зашифрованном виде
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "access-1")
+	require.NotContains(t, string(data), "refresh-1")
+
+	_, err = os.Stat(path + ".tmp")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Сессия открывается при следующем запуске
+	next := newStore(t, root, DefaultProfile)
+
+	saved, err := next.Load()
+	require.NoError(t, err)
+	require.Equal(t, Saved{Address: testAddr, Email: "test@email.ru", SealedKey: []byte("sealed")}, saved)
+	require.Equal(t, testAddr, Address(root, DefaultProfile))
+
+	access, refresh, err := next.Unlock(testKeys[0])
+	require.NoError(t, err)
+	require.Equal(t, "access-1", access)
+	require.Equal(t, "refresh-1", refresh)
+
+	// После Unlock новые токены сразу сохраняются
+	next.Update("access-2", "refresh-2")
+
+	access, refresh, err = newStore(t, root, DefaultProfile).Unlock(testKeys[0])
+	require.NoError(t, err)
+	require.Equal(t, "access-2", access)
+	require.Equal(t, "refresh-2", refresh)
+
+	saved, err = newStore(t, root, DefaultProfile).Load()
+	require.NoError(t, err)
+	require.Equal(t, "test@email.ru", saved.Email)
+
+	stat, err = os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(fileMode), stat.Mode().Perm())
+}
+
+func TestStore_WrongKey(t *testing.T) {
+
+	root := t.TempDir()
+
+	s := newStore(t, root, DefaultProfile)
+	s.Update("access", "refresh")
+	require.NoError(t, s.Save("test@email.ru", nil, testKeys[0]))
+
+	other := newStore(t, root, DefaultProfile)
+	access, refresh, err := other.Unlock(testKeys[1])
+	require.Error(t, err)
+	require.Empty(t, access)
+	require.Empty(t, refresh)
+
+	// Ключ не запоминается: новые токены до Unlock или Save не записываются
+	other.Update("stolen", "stolen")
+
+	access, _, err = newStore(t, root, DefaultProfile).Unlock(testKeys[0])
+	require.NoError(t, err)
+	require.Equal(t, "access", access)
+}
+
+func TestStore_Missing(t *testing.T) {
+
+	root := t.TempDir()
+	s := newStore(t, root, DefaultProfile)
+
+	_, err := s.Load()
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, _, err = s.Unlock(testKeys[0])
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.Empty(t, Address(root, DefaultProfile))
+	require.NoError(t, s.Remove())
+
+	// Без токенов сессия не сохраняется
+	require.NoError(t, s.Save("test@email.ru", nil, testKeys[0]))
+	_, err = s.Load()
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Сессия другого сервера не загружается
+	s.Update("access", "refresh")
+	other, err := NewStore(root, DefaultProfile, "localhost:3201")
+	require.NoError(t, err)
+
+	_, err = other.Load()
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Поврежденный файл сессии не считается отсутствующим
+	require.NoError(t, os.WriteFile(filepath.Join(root, DefaultProfile+fileExt), []byte("{broken"), fileMode))
+	_, err = s.Load()
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errs.ErrNotFound)
+}
+
+func TestStore_Profiles(t *testing.T) {
+
+	root := t.TempDir()
+
+	home := newStore(t, root, DefaultProfile)
+	home.Update("home-access", "home-refresh")
+	require.NoError(t, home.Save("home@email.ru", nil, testKeys[0]))
+
+	work := newStore(t, root, "work.team_1")
+	work.Update("work-access", "work-refresh")
+	require.NoError(t, work.Save("work@email.ru", nil, testKeys[1]))
+
+	saved, err := newStore(t, root, DefaultProfile).Load()
+	require.NoError(t, err)
+	require.Equal(t, "home@email.ru", saved.Email)
+
+	saved, err = newStore(t, root, "work.team_1").Load()
+	require.NoError(t, err)
+	require.Equal(t, "work@email.ru", saved.Email)
+
+	access, _, err := newStore(t, root, "work.team_1").Unlock(testKeys[1])
+	require.NoError(t, err)
+	require.Equal(t, "work-access", access)
+
+	// Удаление сессии одного профиля не затрагивает другие
+	require.NoError(t, work.Remove())
+
+	_, err = newStore(t, root, "work.team_1").Load()
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	access, _, err = newStore(t, root, DefaultProfile).Unlock(testKeys[0])
+	require.NoError(t, err)
+	require.Equal(t, "home-access", access)
+
+	// После Remove токены не записываются
+	work.Update("work-access-2", "work-refresh-2")
+	_, err = os.Stat(filepath.Join(root, "work.team_1"+fileExt))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Имя профиля не выходит за пределы каталога сессий
+	for _, profile := range []string{"", "../home", "a/b", ".hidden", "-flag", strings.Repeat(" ", 3)} {
+		_, err = NewStore(root, profile, testAddr)
+		require.Error(t, err, profile)
+		require.Empty(t, Address(root, profile), profile)
+	}
+}
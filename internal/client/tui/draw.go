@@ -0,0 +1,276 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// sidebarWidth - Ширина боковой панели типов данных.
+	sidebarWidth = 14
+	// maskLen - Длина маски скрытого значения, не зависит от длины значения.
+	maskLen = 8
+	// dateLayout - Формат даты изменения в панели просмотра.
+	dateLayout = "02.01.2006 15:04:05"
+	// help - Подсказка по клавишам в строке состояния.
+	help = " ↑↓ выбор  Tab панель  / фильтр  n новая  e изменить  d удалить  r показать  q выход"
+)
+
+// styles - Стили элементов интерфейса.
+type styles struct {
+	normal   tcell.Style
+	dim      tcell.Style
+	title    tcell.Style
+	header   tcell.Style
+	selected tcell.Style
+	active   tcell.Style
+	error    tcell.Style
+	success  tcell.Style
+}
+
+func newStyles() styles {
+
+	normal := tcell.StyleDefault
+
+	return styles{
+		normal:   normal,
+		dim:      normal.Foreground(tcell.ColorGray),
+		title:    normal.Bold(true),
+		header:   normal.Reverse(true).Bold(true),
+		selected: normal.Bold(true).Underline(true),
+		active:   normal.Reverse(true),
+		error:    normal.Foreground(tcell.ColorRed),
+		success:  normal.Foreground(tcell.ColorGreen),
+	}
+}
+
+// draw - Вывод интерфейса на экран.
+func (t *TUI) draw() {
+
+	s := t.screen
+	st := newStyles()
+	w, h := s.Size()
+
+	s.Clear()
+	s.HideCursor()
+
+	fill(s, 0, 0, w, 1, st.header)
+	drawText(s, 1, 0, w-2, "GophKeeper — "+t.kinds[t.kind].title(), st.header)
+
+	listX := sidebarWidth + 1
+	listW := clamp((w-listX)/3, 16, 40)
+	detailX := listX + listW + 1
+
+	for y := 1; y < h-1; y++ {
+		s.SetContent(sidebarWidth, y, tcell.RuneVLine, nil, st.dim)
+		s.SetContent(detailX-1, y, tcell.RuneVLine, nil, st.dim)
+	}
+
+	t.drawKinds(s, st)
+	t.drawList(s, st, listX, listW, h)
+	t.drawDetail(s, st, detailX+1, w-detailX-2)
+	t.drawStatus(s, st, w, h)
+
+	if t.form != nil {
+		t.form.draw(s, st)
+	}
+
+	s.Show()
+}
+
+// drawKinds - Боковая панель типов данных.
+func (t *TUI) drawKinds(s tcell.Screen, st styles) {
+
+	for i, k := range t.kinds {
+		style := st.normal
+		if i == t.kind {
+			style = st.selected
+			if t.focus == paneKinds {
+				style = st.active
+			}
+		}
+
+		fill(s, 0, i+1, sidebarWidth, 1, style)
+		drawText(s, 1, i+1, sidebarWidth-2, k.title(), style)
+	}
+}
+
+// drawList - Строка фильтра и список записей.
+func (t *TUI) drawList(s tcell.Screen, st styles, x, width, h int) {
+
+	style := st.dim
+	if t.filtering {
+		style = st.normal
+	}
+
+	end := drawText(s, x+1, 1, width-2, "/ "+t.filter, style)
+	if t.filtering {
+		s.ShowCursor(end, 1)
+	}
+
+	if len(t.visible) == 0 {
+		msg := "Записей нет"
+		if len(t.filter) != 0 {
+			msg = "Ничего не найдено"
+		}
+		drawText(s, x+1, 2, width-2, msg, st.dim)
+		return
+	}
+
+	rows := t.listHeight()
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+rows {
+		t.offset = t.cursor - rows + 1
+	}
+	t.offset = clamp(t.offset, 0, len(t.visible)-1)
+
+	for i := t.offset; i < len(t.visible) && i < t.offset+rows; i++ {
+		y := 2 + i - t.offset
+		style := st.normal
+		if i == t.cursor {
+			style = st.selected
+			if t.focus == paneList {
+				style = st.active
+			}
+		}
+
+		fill(s, x, y, width, 1, style)
+		drawText(s, x+1, y, width-2, t.visible[i], style)
+	}
+}
+
+// drawDetail - Панель просмотра выбранной записи.
+// Секретные поля скрыты, пока не нажата r.
+func (t *TUI) drawDetail(s tcell.Screen, st styles, x, width int) {
+
+	if t.detail == nil {
+		return
+	}
+
+	e := t.detail
+	y := 1
+	drawText(s, x, y, width, e.meta, st.title)
+	y += 2
+
+	if _, ok := t.kinds[t.kind].(downloader); ok {
+		drawText(s, x, y, width, "s - сохранить в файл, e - заменить файлом", st.dim)
+		return
+	}
+
+	for i, fd := range t.kinds[t.kind].fields() {
+		if i >= len(e.values) {
+			break
+		}
+
+		drawText(s, x, y, width, fd.label+":", st.dim)
+		y++
+
+		value := e.values[i]
+		if fd.secret && !t.reveal {
+			value = mask(maskLen)
+		}
+
+		for _, line := range strings.Split(value, "\n") {
+			drawText(s, x+2, y, width-2, line, st.normal)
+			y++
+		}
+		y++
+	}
+
+	drawText(s, x, y, width, fmt.Sprintf("Версия: %d", e.version), st.dim)
+	if !e.updatedAt.IsZero() {
+		drawText(s, x, y+1, width, "Изменено: "+e.updatedAt.Local().Format(dateLayout), st.dim)
+	}
+}
+
+// drawStatus - Строка состояния: подтверждение, сообщение или подсказка.
+func (t *TUI) drawStatus(s tcell.Screen, st styles, w, h int) {
+
+	y := h - 1
+
+	switch {
+	case t.confirm:
+		drawText(s, 1, y, w-2, "Удалить «"+t.detail.meta+"»? (y/n)", st.error.Bold(true))
+
+	case len(t.status) != 0 && t.failed:
+		drawText(s, 1, y, w-2, t.status, st.error)
+
+	case len(t.status) != 0:
+		drawText(s, 1, y, w-2, t.status, st.success)
+
+	default:
+		drawText(s, 0, y, w, help, st.dim)
+	}
+}
+
+// listHeight - Количество строк списка записей.
+func (t *TUI) listHeight() int {
+
+	_, h := t.screen.Size()
+	if h < 4 {
+		return 1
+	}
+
+	return h - 3
+}
+
+// drawText - Вывод строки в ширину width с обрезкой по краю.
+// Возвращает столбец после последнего выведенного символа.
+func drawText(s tcell.Screen, x, y, width int, text string, style tcell.Style) int {
+
+	end := x + width
+	for _, r := range text {
+		rw := runewidth.RuneWidth(r)
+		if rw == 0 {
+			continue
+		}
+		if x+rw > end {
+			break
+		}
+
+		s.SetContent(x, y, r, nil, style)
+		x += rw
+	}
+
+	return x
+}
+
+// fill - Заливка прямоугольника стилем style.
+func fill(s tcell.Screen, x, y, width, height int, style tcell.Style) {
+
+	for row := y; row < y+height; row++ {
+		for col := x; col < x+width; col++ {
+			s.SetContent(col, row, ' ', nil, style)
+		}
+	}
+}
+
+// box - Рамка с очищенной внутренней областью.
+func box(s tcell.Screen, x, y, width, height int, style tcell.Style) {
+
+	fill(s, x, y, width, height, style)
+
+	for col := x + 1; col < x+width-1; col++ {
+		s.SetContent(col, y, tcell.RuneHLine, nil, style)
+		s.SetContent(col, y+height-1, tcell.RuneHLine, nil, style)
+	}
+	for row := y + 1; row < y+height-1; row++ {
+		s.SetContent(x, row, tcell.RuneVLine, nil, style)
+		s.SetContent(x+width-1, row, tcell.RuneVLine, nil, style)
+	}
+
+	s.SetContent(x, y, tcell.RuneULCorner, nil, style)
+	s.SetContent(x+width-1, y, tcell.RuneURCorner, nil, style)
+	s.SetContent(x, y+height-1, tcell.RuneLLCorner, nil, style)
+	s.SetContent(x+width-1, y+height-1, tcell.RuneLRCorner, nil, style)
+}
+
+// mask - Маска скрытого значения.
+func mask(n int) string {
+	return strings.Repeat("•", n)
+}
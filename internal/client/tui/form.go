@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+
+	"GophKeeper/pkg/secret"
+)
+
+// formAction - Результат нажатия клавиши в форме.
+type formAction int
+
+const (
+	formNone formAction = iota
+	formSave
+	formCancel
+)
+
+// metaLabel - Поле метаинформации в форме создания.
+const metaLabel = "Метаинформация"
+
+// form - Форма создания или изменения записи.
+type form struct {
+	title  string
+	create bool
+	meta   string
+	fields []field
+	values [][]rune
+	focus  int
+	reveal bool
+	// err - Ошибка проверки введенных данных.
+	err string
+	// note - Сообщение о сгенерированном пароле.
+	note string
+
+	// download - Форма сохранения данных в файл.
+	download bool
+}
+
+// newForm - Форма создания записи типа k (e == nil) или изменения записи e.
+// Метаинформация вводится только при создании: она определяет запись.
+func newForm(k kind, e *entry) *form {
+
+	f := &form{
+		title:  k.title() + ": изменение",
+		create: e == nil,
+	}
+
+	if f.create {
+		f.title = k.title() + ": новая запись"
+		f.fields = append(f.fields, field{label: metaLabel})
+		f.values = append(f.values, nil)
+	} else {
+		f.meta = e.meta
+	}
+
+	for i, fd := range k.fields() {
+		f.fields = append(f.fields, fd)
+
+		var value []rune
+		if e != nil && i < len(e.values) {
+			value = []rune(e.values[i])
+		}
+		f.values = append(f.values, value)
+	}
+
+	return f
+}
+
+// newDownloadForm - Форма сохранения записи e типа k в файл.
+func newDownloadForm(k kind, e *entry) *form {
+	return &form{
+		title:    k.title() + ": сохранение в файл",
+		download: true,
+		meta:     e.meta,
+		fields:   []field{{label: "Путь (пусто - исходное имя файла)"}},
+		values:   [][]rune{nil},
+	}
+}
+
+// handle - Обработка нажатия клавиши в форме.
+func (f *form) handle(ev *tcell.EventKey) formAction {
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return formCancel
+
+	case tcell.KeyCtrlS:
+		return formSave
+
+	case tcell.KeyEnter:
+		if f.focus == len(f.fields)-1 {
+			return formSave
+		}
+		f.focus++
+
+	case tcell.KeyTab, tcell.KeyDown:
+		f.focus = (f.focus + 1) % len(f.fields)
+
+	case tcell.KeyBacktab, tcell.KeyUp:
+		f.focus = (f.focus + len(f.fields) - 1) % len(f.fields)
+
+	case tcell.KeyCtrlR:
+		f.reveal = !f.reveal
+
+	case tcell.KeyCtrlG:
+		f.generate()
+
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if value := f.values[f.focus]; len(value) != 0 {
+			f.values[f.focus] = value[:len(value)-1]
+		}
+
+	case tcell.KeyCtrlU:
+		f.values[f.focus] = nil
+
+	case tcell.KeyRune:
+		f.values[f.focus] = append(f.values[f.focus], ev.Rune())
+	}
+
+	return formNone
+}
+
+// generate - Генерация пароля в поле с фокусом. Пароль сразу показывается,
+// чтобы его можно было запомнить или переписать.
+func (f *form) generate() {
+
+	if !f.fields[f.focus].generate {
+		return
+	}
+
+	gen, err := secret.GeneratePassword(secret.DefaultPassword)
+	if err != nil {
+		f.err = "Не удалось сгенерировать пароль"
+		return
+	}
+
+	f.values[f.focus] = []rune(gen.Value)
+	f.reveal = true
+	f.err = ``
+	f.note = fmt.Sprintf("Пароль сгенерирован, энтропия %.0f бит", gen.Entropy)
+}
+
+// result - Метаинформация и значения полей данных.
+func (f *form) result() (string, []string) {
+
+	meta := f.meta
+	values := make([]string, 0, len(f.values))
+	for _, value := range f.values {
+		values = append(values, string(value))
+	}
+
+	if f.create {
+		meta, values = values[0], values[1:]
+	}
+
+	return meta, values
+}
+
+// draw - Вывод формы поверх панелей.
+func (f *form) draw(s tcell.Screen, st styles) {
+
+	w, h := s.Size()
+
+	width := clamp(w-4, 20, 70)
+	height := 2*len(f.fields) + 5
+	x := (w - width) / 2
+	y := clamp((h-height)/2, 0, h)
+
+	box(s, x, y, width, height, st.normal)
+	drawText(s, x+2, y, width-4, " "+f.title+" ", st.title)
+
+	row := y + 1
+	if !f.create {
+		drawText(s, x+2, row, width-4, metaLabel+": "+f.meta, st.dim)
+	}
+	row++
+
+	s.HideCursor()
+	for i, fd := range f.fields {
+		style := st.dim
+		if i == f.focus {
+			style = st.title
+		}
+		label := fd.label + ":"
+		if fd.generate {
+			label = fd.label + " (Ctrl+G - сгенерировать):"
+		}
+		drawText(s, x+2, row, width-4, label, style)
+
+		value := string(f.values[i])
+		if fd.secret && !f.reveal {
+			value = mask(len(f.values[i]))
+		}
+
+		end := drawText(s, x+4, row+1, width-6, value, st.normal)
+		if i == f.focus {
+			s.ShowCursor(end, row+1)
+		}
+		row += 2
+	}
+
+	switch {
+	case len(f.err) != 0:
+		drawText(s, x+2, row, width-4, f.err, st.error)
+	case len(f.note) != 0:
+		drawText(s, x+2, row, width-4, f.note, st.success)
+	}
+
+	drawText(s, x+2, y+height-1, width-4,
+		" Tab поле  Ctrl+S сохранить  Ctrl+R показать  Esc отмена ", st.dim)
+}
@@ -0,0 +1,408 @@
+package tui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"GophKeeper/internal/client/app_services/app_service_binary"
+	"GophKeeper/internal/client/app_services/app_service_card"
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/card_model"
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/pkg/secret"
+)
+
+// field - Поле данных в панели просмотра и в форме.
+type field struct {
+	label string
+	// secret - Значение скрыто, пока не запрошен показ.
+	secret bool
+	// generate - Значение можно сгенерировать в форме (Ctrl+G).
+	generate bool
+}
+
+// entry - Расшифрованная запись.
+type entry struct {
+	meta      string
+	values    []string
+	version   int64
+	updatedAt time.Time
+}
+
+// kind - Тип данных в боковой панели.
+type kind interface {
+	title() string
+	fields() []field
+	// check - Проверка значений перед сохранением.
+	check(values []string) error
+	list(filter list_model.Filter, token string) (list_model.Page, error)
+	get(meta, token string) (entry, error)
+	create(e entry, token string) error
+	change(e entry, token string) error
+	remove(meta string, version int64, token string) error
+}
+
+// downloader - Тип данных, которые не показываются в панели просмотра,
+// а сохраняются в файл (клавиша s).
+type downloader interface {
+	// download - Сохранение данных meta в файл path.
+	// Возвращает путь сохраненного файла.
+	download(meta, path, token string) (string, error)
+}
+
+// decrypt - Расшифровка полей src в строки.
+func (t *TUI) decrypt(src ...[]byte) ([]string, error) {
+
+	values := make([]string, 0, len(src))
+	for _, data := range src {
+		plain, err := secret.Decrypt(t.privateKey, data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(plain))
+	}
+
+	return values, nil
+}
+
+// encrypt - Шифрование значений полей.
+func (t *TUI) encrypt(values []string) ([][]byte, error) {
+
+	enc := make([][]byte, 0, len(values))
+	for _, value := range values {
+		data, err := secret.Encrypt(t.publicKey, []byte(value))
+		if err != nil {
+			return nil, err
+		}
+		enc = append(enc, data)
+	}
+
+	return enc, nil
+}
+
+// required - Проверка, что все значения заполнены.
+func required(fields []field, values []string) error {
+
+	for i, value := range values {
+		if len(strings.TrimSpace(value)) == 0 {
+			return errors.New("Поле \"" + fields[i].label + "\" не заполнено")
+		}
+	}
+
+	return nil
+}
+
+// textKind - Текстовые данные.
+type textKind struct {
+	*TUI
+}
+
+func (k textKind) title() string {
+	return "Тексты"
+}
+
+func (k textKind) fields() []field {
+	return []field{{label: "Текст", secret: true}}
+}
+
+func (k textKind) check(values []string) error {
+	return required(k.fields(), values)
+}
+
+func (k textKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.text.List(filter, token)
+}
+
+func (k textKind) get(meta, token string) (entry, error) {
+
+	data, err := k.text.Get(meta, token)
+	if err != nil {
+		return entry{}, err
+	}
+
+	values, err := k.decrypt(data.Data)
+	if err != nil {
+		return entry{}, err
+	}
+
+	return entry{meta: meta, values: values, version: data.Version, updatedAt: data.UpdatedAt}, nil
+}
+
+func (k textKind) create(e entry, token string) error {
+
+	data, err := k.encode(e)
+	if err != nil {
+		return err
+	}
+
+	return k.text.Create(data, token)
+}
+
+func (k textKind) change(e entry, token string) error {
+
+	data, err := k.encode(e)
+	if err != nil {
+		return err
+	}
+
+	return k.text.Change(data, token)
+}
+
+func (k textKind) remove(meta string, version int64, token string) error {
+	return k.text.Delete(meta, version, token)
+}
+
+func (k textKind) encode(e entry) (text_model.Text, error) {
+
+	enc, err := k.encrypt(e.values)
+	if err != nil {
+		return text_model.Text{}, err
+	}
+
+	return text_model.Text{MetaInfo: e.meta, Data: enc[0], Version: e.version}, nil
+}
+
+// credKind - Логины и пароли.
+type credKind struct {
+	*TUI
+}
+
+func (k credKind) title() string {
+	return "Логины"
+}
+
+func (k credKind) fields() []field {
+	return []field{{label: "Логин"}, {label: "Пароль", secret: true, generate: true}}
+}
+
+func (k credKind) check(values []string) error {
+	return required(k.fields(), values)
+}
+
+func (k credKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.cred.List(filter, token)
+}
+
+func (k credKind) get(meta, token string) (entry, error) {
+
+	data, err := k.cred.Get(meta, token)
+	if err != nil {
+		return entry{}, err
+	}
+
+	values, err := k.decrypt(data.Login, data.Password)
+	if err != nil {
+		return entry{}, err
+	}
+
+	return entry{meta: meta, values: values, version: data.Version, updatedAt: data.UpdatedAt}, nil
+}
+
+func (k credKind) create(e entry, token string) error {
+
+	data, err := k.encode(e)
+	if err != nil {
+		return err
+	}
+
+	return k.cred.Create(data, token)
+}
+
+func (k credKind) change(e entry, token string) error {
+
+	data, err := k.encode(e)
+	if err != nil {
+		return err
+	}
+
+	return k.cred.Change(data, token)
+}
+
+func (k credKind) remove(meta string, version int64, token string) error {
+	return k.cred.Delete(meta, version, token)
+}
+
+func (k credKind) encode(e entry) (cred_model.Credential, error) {
+
+	enc, err := k.encrypt(e.values)
+	if err != nil {
+		return cred_model.Credential{}, err
+	}
+
+	return cred_model.Credential{MetaInfo: e.meta, Login: enc[0], Password: enc[1], Version: e.version}, nil
+}
+
+// cardKind - Банковские карты.
+type cardKind struct {
+	*TUI
+}
+
+func (k cardKind) title() string {
+	return "Карты"
+}
+
+func (k cardKind) fields() []field {
+	return []field{
+		{label: "Номер", secret: true},
+		{label: "Период (" + app_service_card.PeriodLayout + ")"},
+		{label: "CVV", secret: true},
+		{label: "Держатель"},
+	}
+}
+
+func (k cardKind) check(values []string) error {
+	return app_service_card.CheckCard(values[0], values[1], values[2], values[3])
+}
+
+func (k cardKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.card.List(filter, token)
+}
+
+func (k cardKind) get(meta, token string) (entry, error) {
+
+	data, err := k.card.Get(meta, token)
+	if err != nil {
+		return entry{}, err
+	}
+
+	values, err := k.decrypt(data.Number, data.Period, data.CVV, data.FullName)
+	if err != nil {
+		return entry{}, err
+	}
+
+	return entry{meta: meta, values: values, version: data.Version, updatedAt: data.UpdatedAt}, nil
+}
+
+func (k cardKind) create(e entry, token string) error {
+
+	data, err := k.encode(e)
+	if err != nil {
+		return err
+	}
+
+	return k.card.Create(data, token)
+}
+
+func (k cardKind) change(e entry, token string) error {
+
+	data, err := k.encode(e)
+	if err != nil {
+		return err
+	}
+
+	return k.card.Change(data, token)
+}
+
+func (k cardKind) remove(meta string, version int64, token string) error {
+	return k.card.Delete(meta, version, token)
+}
+
+func (k cardKind) encode(e entry) (card_model.Card, error) {
+
+	enc, err := k.encrypt(e.values)
+	if err != nil {
+		return card_model.Card{}, err
+	}
+
+	return card_model.Card{
+		MetaInfo: e.meta,
+		Number:   enc[0],
+		Period:   enc[1],
+		CVV:      enc[2],
+		FullName: enc[3],
+		Version:  e.version,
+	}, nil
+}
+
+// binaryKind - Бинарные данные. Данные передаются между файлом и сервером
+// потоком, поэтому вместо значений в форме указывается путь к файлу.
+type binaryKind struct {
+	*TUI
+}
+
+func (k binaryKind) title() string {
+	return "Файлы"
+}
+
+func (k binaryKind) fields() []field {
+	return []field{{label: "Путь к файлу"}}
+}
+
+func (k binaryKind) check(values []string) error {
+
+	if err := required(k.fields(), values); err != nil {
+		return err
+	}
+
+	stat, err := os.Stat(values[0])
+	if err != nil {
+		return errors.New("Файл не найден")
+	}
+
+	if stat.IsDir() {
+		return errors.New("Указан каталог, а не файл")
+	}
+
+	return nil
+}
+
+func (k binaryKind) list(filter list_model.Filter, token string) (list_model.Page, error) {
+	return k.bin.List(filter, token)
+}
+
+// get - Данные не загружаются до сохранения в файл,
+// версия берется из списка метаинформации.
+func (k binaryKind) get(meta, token string) (entry, error) {
+
+	version, err := app_service_binary.CurrentVersion(k.bin, meta, token)
+	if err != nil {
+		return entry{}, err
+	}
+
+	return entry{meta: meta, version: version}, nil
+}
+
+func (k binaryKind) create(e entry, token string) error {
+	return k.upload(binary_model.Upload{MetaInfo: e.meta}, e.values[0], token)
+}
+
+func (k binaryKind) change(e entry, token string) error {
+	in := binary_model.Upload{MetaInfo: e.meta, Overwrite: true, Version: e.version}
+	return k.upload(in, e.values[0], token)
+}
+
+func (k binaryKind) remove(meta string, version int64, token string) error {
+	return k.bin.Delete(meta, version, token)
+}
+
+// upload - Шифрование и загрузка файла path.
+func (k binaryKind) upload(in binary_model.Upload, path, token string) error {
+
+	file, err := app_service_binary.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return k.bin.Upload(in, secret.NewEncryptReader(k.publicKey, file), token)
+}
+
+func (k binaryKind) download(meta, path, token string) (string, error) {
+
+	saved, _, err := app_service_binary.SaveFile(path, meta, func(w io.Writer) error {
+
+		dec := secret.NewDecryptWriter(k.privateKey, w)
+		if _, err := k.bin.Download(meta, dec, token); err != nil {
+			return err
+		}
+
+		return dec.Close()
+	})
+
+	return saved, err
+}
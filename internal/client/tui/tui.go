@@ -0,0 +1,642 @@
+// Package tui - Полноэкранный интерфейс клиента: боковая панель типов данных,
+// список записей с фильтром и панель просмотра, в которой секретные поля
+// скрыты до запроса показа.
+//
+// Данные передаются через Sender сервисов app_service_*, поэтому работают
+// и локальный кэш, и очередь изменений без связи с сервером. Экран задается
+// через WithScreen: в тестах это tcell.SimulationScreen.
+package tui
+
+import (
+	"crypto/rsa"
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/client/app_services/app_service_binary"
+	"GophKeeper/internal/client/app_services/app_service_card"
+	"GophKeeper/internal/client/app_services/app_service_cred"
+	"GophKeeper/internal/client/app_services/app_service_text"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/pkg/errs"
+)
+
+// pane - Панель, в которой работают клавиши перемещения.
+type pane int
+
+const (
+	paneKinds pane = iota
+	paneList
+)
+
+type Options func(t *TUI)
+
+// TUI - Полноэкранный интерфейс хранилища.
+type TUI struct {
+	text app_service_text.Sender
+	cred app_service_cred.Sender
+	card app_service_card.Sender
+	bin  app_service_binary.Sender
+
+	screen     tcell.Screen
+	publicKey  *rsa.PublicKey
+	privateKey *rsa.PrivateKey
+	// plaintext - Разрешена запись данных без шифрования.
+	plaintext bool
+	token     string
+
+	kinds []kind
+	kind  int
+	focus pane
+
+	// metas - Метаинформация всех записей выбранного типа,
+	// visible - записей, подходящих под фильтр.
+	metas     []string
+	visible   []string
+	filter    string
+	filtering bool
+	cursor    int
+	// offset - Первая видимая строка списка.
+	offset int
+
+	detail *entry
+	reveal bool
+	form   *form
+	// confirm - Ожидается подтверждение удаления.
+	confirm bool
+
+	status string
+	failed bool
+
+	logger *zap.Logger
+}
+
+// New - Создание полноэкранного интерфейса.
+func New(opts ...Options) *TUI {
+	t := &TUI{
+		logger: zap.L(),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// WithText - Текстовые данные.
+func WithText(s app_service_text.Sender) Options {
+	return func(t *TUI) {
+		t.text = s
+	}
+}
+
+// WithCred - Логины и пароли.
+func WithCred(s app_service_cred.Sender) Options {
+	return func(t *TUI) {
+		t.cred = s
+	}
+}
+
+// WithCard - Банковские карты.
+func WithCard(s app_service_card.Sender) Options {
+	return func(t *TUI) {
+		t.card = s
+	}
+}
+
+// WithBinary - Бинарные данные.
+func WithBinary(s app_service_binary.Sender) Options {
+	return func(t *TUI) {
+		t.bin = s
+	}
+}
+
+// WithScreen - Экран интерфейса. По умолчанию - терминал.
+func WithScreen(screen tcell.Screen) Options {
+	return func(t *TUI) {
+		t.screen = screen
+	}
+}
+
+// WithPlaintext - Разрешение записи данных без шифрования (режим --insecure-plaintext).
+func WithPlaintext(allowed bool) Options {
+	return func(t *TUI) {
+		t.plaintext = allowed
+	}
+}
+
+// Run - Работа интерфейса до выхода пользователя (q или Ctrl+C).
+// Пустой token - работа без связи с сервером через локальный кэш,
+// key - ключ шифрования данных, nil - данные не шифруются.
+func (t *TUI) Run(token string, key *rsa.PrivateKey) error {
+
+	t.token = token
+	t.privateKey = key
+	t.publicKey = nil
+	if key != nil {
+		t.publicKey = &key.PublicKey
+	}
+
+	t.kinds = nil
+	if t.text != nil {
+		t.kinds = append(t.kinds, textKind{t})
+	}
+	if t.cred != nil {
+		t.kinds = append(t.kinds, credKind{t})
+	}
+	if t.card != nil {
+		t.kinds = append(t.kinds, cardKind{t})
+	}
+	if t.bin != nil {
+		t.kinds = append(t.kinds, binaryKind{t})
+	}
+
+	if len(t.kinds) == 0 {
+		return errors.New("no data services")
+	}
+
+	if t.screen == nil {
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			return err
+		}
+		t.screen = screen
+	}
+
+	if err := t.screen.Init(); err != nil {
+		return err
+	}
+	defer t.screen.Fini()
+
+	t.load(``)
+
+	for {
+		t.draw()
+
+		switch ev := t.screen.PollEvent().(type) {
+		case nil:
+			// Экран закрыт
+			return nil
+
+		case *tcell.EventResize:
+			t.screen.Sync()
+
+		case *tcell.EventKey:
+			if !t.handle(ev) {
+				return nil
+			}
+		}
+	}
+}
+
+// handle - Обработка нажатия клавиши. Возвращает false для выхода.
+func (t *TUI) handle(ev *tcell.EventKey) bool {
+
+	if ev.Key() == tcell.KeyCtrlC {
+		return false
+	}
+
+	t.status = ""
+
+	switch {
+	case t.form != nil:
+		t.handleForm(ev)
+		return true
+
+	case t.confirm:
+		t.confirm = false
+		if ev.Key() == tcell.KeyRune && strings.ContainsRune("yYдД", ev.Rune()) {
+			t.remove()
+		} else {
+			t.info("Удаление отменено")
+		}
+		return true
+
+	case t.filtering:
+		t.handleFilter(ev)
+		return true
+	}
+
+	switch ev.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab:
+		if t.focus == paneKinds {
+			t.focus = paneList
+		} else {
+			t.focus = paneKinds
+		}
+
+	case tcell.KeyLeft:
+		t.focus = paneKinds
+
+	case tcell.KeyRight, tcell.KeyEnter:
+		t.focus = paneList
+
+	case tcell.KeyUp:
+		t.move(-1)
+
+	case tcell.KeyDown:
+		t.move(1)
+
+	case tcell.KeyPgUp:
+		t.move(-t.listHeight())
+
+	case tcell.KeyPgDn:
+		t.move(t.listHeight())
+
+	case tcell.KeyCtrlR, tcell.KeyF5:
+		t.detail = nil
+		t.load(t.selected())
+
+	case tcell.KeyRune:
+		return t.handleRune(ev.Rune())
+	}
+
+	return true
+}
+
+// handleRune - Горячие клавиши.
+func (t *TUI) handleRune(r rune) bool {
+
+	switch r {
+	case 'q':
+		return false
+
+	case '/':
+		t.focus = paneList
+		t.filtering = true
+
+	case 'n':
+		if err := t.writable(); err != nil {
+			t.fail(err)
+			break
+		}
+		t.form = newForm(t.kinds[t.kind], nil)
+
+	case 'e':
+		if t.detail == nil {
+			break
+		}
+		if err := t.writable(); err != nil {
+			t.fail(err)
+			break
+		}
+		t.form = newForm(t.kinds[t.kind], t.detail)
+
+	case 'd':
+		if t.detail != nil {
+			t.confirm = true
+		}
+
+	case 's':
+		if _, ok := t.kinds[t.kind].(downloader); ok && t.detail != nil {
+			t.form = newDownloadForm(t.kinds[t.kind], t.detail)
+		}
+
+	case 'r':
+		t.reveal = !t.reveal
+
+	case 'k':
+		t.move(-1)
+
+	case 'j':
+		t.move(1)
+	}
+
+	return true
+}
+
+// handleFilter - Ввод фильтра списка.
+func (t *TUI) handleFilter(ev *tcell.EventKey) {
+
+	filter := []rune(t.filter)
+
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		t.filtering = false
+		return
+
+	case tcell.KeyEscape:
+		t.filtering = false
+		filter = nil
+
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(filter) != 0 {
+			filter = filter[:len(filter)-1]
+		}
+
+	case tcell.KeyRune:
+		filter = append(filter, ev.Rune())
+
+	default:
+		return
+	}
+
+	t.filter = string(filter)
+	t.applyFilter(t.selected())
+}
+
+// handleForm - Ввод в форме создания или изменения записи.
+func (t *TUI) handleForm(ev *tcell.EventKey) {
+
+	switch t.form.handle(ev) {
+	case formCancel:
+		t.form = nil
+
+	case formSave:
+		t.save()
+	}
+}
+
+// move - Перемещение в панели с фокусом на delta строк.
+func (t *TUI) move(delta int) {
+
+	if t.focus == paneKinds {
+		next := clamp(t.kind+delta, 0, len(t.kinds)-1)
+		if next != t.kind {
+			t.kind = next
+			t.filter = ``
+			t.detail = nil
+			t.load(``)
+		}
+		return
+	}
+
+	next := clamp(t.cursor+delta, 0, len(t.visible)-1)
+	if next != t.cursor {
+		t.cursor = next
+		t.selectEntry()
+	}
+}
+
+// load - Загрузка списка записей выбранного типа.
+// Курсор остается на записи selected, если она есть в списке.
+func (t *TUI) load(selected string) {
+
+	metas, err := t.listAll()
+	if err != nil {
+		t.metas = nil
+		t.fail(err)
+	} else {
+		t.metas = metas
+	}
+
+	t.applyFilter(selected)
+}
+
+// listAll - Метаинформация всех страниц списка.
+func (t *TUI) listAll() ([]string, error) {
+
+	var metas []string
+	var filter list_model.Filter
+
+	for {
+		page, err := t.kinds[t.kind].list(filter, t.token)
+		if err != nil {
+			return nil, err
+		}
+
+		metas = append(metas, page.MetaInfo...)
+
+		if len(page.NextPageToken) == 0 {
+			return metas, nil
+		}
+
+		filter.PageToken = page.NextPageToken
+	}
+}
+
+// applyFilter - Отбор записей, метаинформация которых содержит фильтр.
+// Курсор остается на записи selected, если она подходит под фильтр.
+func (t *TUI) applyFilter(selected string) {
+
+	filter := strings.ToLower(t.filter)
+
+	t.visible = t.visible[:0]
+	t.cursor = 0
+	for _, meta := range t.metas {
+		if !strings.Contains(strings.ToLower(meta), filter) {
+			continue
+		}
+
+		if meta == selected {
+			t.cursor = len(t.visible)
+		}
+		t.visible = append(t.visible, meta)
+	}
+
+	t.selectEntry()
+}
+
+// selected - Метаинформация записи под курсором.
+func (t *TUI) selected() string {
+
+	if t.cursor < len(t.visible) {
+		return t.visible[t.cursor]
+	}
+
+	return ``
+}
+
+// selectEntry - Загрузка записи под курсором в панель просмотра.
+func (t *TUI) selectEntry() {
+
+	if len(t.visible) == 0 {
+		t.detail = nil
+		return
+	}
+
+	meta := t.visible[t.cursor]
+	if t.detail != nil && t.detail.meta == meta {
+		return
+	}
+
+	t.detail = nil
+	t.reveal = false
+
+	e, err := t.kinds[t.kind].get(meta, t.token)
+	if err != nil {
+		t.fail(err)
+		return
+	}
+
+	t.detail = &e
+}
+
+// save - Сохранение записи из формы.
+func (t *TUI) save() {
+
+	if t.form.download {
+		t.download()
+		return
+	}
+
+	k := t.kinds[t.kind]
+	meta, values := t.form.result()
+
+	if len(strings.TrimSpace(meta)) == 0 {
+		t.form.err = "Метаинформация не заполнена"
+		return
+	}
+
+	if err := k.check(values); err != nil {
+		t.form.err = err.Error()
+		return
+	}
+
+	e := entry{meta: meta, values: values}
+
+	var err error
+	if t.form.create {
+		err = k.create(e, t.token)
+	} else {
+		e.version = t.detail.version
+		err = k.change(e, t.token)
+	}
+
+	switch {
+	case err == nil:
+		t.info("Сохранено")
+
+	case errors.Is(err, errs.ErrQueued):
+		t.info("Сервер недоступен, изменения будут отправлены при подключении")
+
+	case errors.Is(err, errs.ErrAlreadyExist):
+		t.form.err = "Запись с такой метаинформацией уже есть"
+		return
+
+	case errors.Is(err, errs.ErrConflict):
+		t.fail(err)
+
+	default:
+		t.form.err = message(err)
+		if errors.Is(err, errs.ErrInternal) {
+			t.logger.Error("failed save data", zap.Error(err))
+		}
+		return
+	}
+
+	t.form = nil
+	t.detail = nil
+	t.load(meta)
+}
+
+// download - Сохранение выбранной записи в файл из формы.
+func (t *TUI) download() {
+
+	_, values := t.form.result()
+
+	saved, err := t.kinds[t.kind].(downloader).download(t.detail.meta, values[0], t.token)
+	switch {
+	case err == nil:
+		t.info("Файл сохранен: " + saved)
+		t.form = nil
+
+	case errors.Is(err, fs.ErrExist):
+		t.form.err = "Файл уже существует"
+
+	default:
+		t.form.err = message(err)
+		if !isKnown(err) {
+			t.logger.Error("failed download data", zap.Error(err))
+		}
+	}
+}
+
+// remove - Удаление выбранной записи.
+func (t *TUI) remove() {
+
+	err := t.kinds[t.kind].remove(t.detail.meta, t.detail.version, t.token)
+	switch {
+	case err == nil:
+		t.info("Удалено")
+
+	case errors.Is(err, errs.ErrQueued):
+		t.info("Сервер недоступен, удаление будет отправлено при подключении")
+
+	default:
+		t.fail(err)
+	}
+
+	t.detail = nil
+	t.load(``)
+}
+
+// writable - Проверка, что данные будут зашифрованы перед отправкой.
+func (t *TUI) writable() error {
+
+	if t.publicKey != nil || t.plaintext {
+		return nil
+	}
+
+	return errs.ErrPlaintext
+}
+
+// info - Сообщение в строке состояния.
+func (t *TUI) info(msg string) {
+	t.status = msg
+	t.failed = false
+}
+
+// fail - Ошибка в строке состояния.
+func (t *TUI) fail(err error) {
+
+	t.status = message(err)
+	t.failed = true
+
+	if !isKnown(err) {
+		t.logger.Error("unknown error", zap.Error(err))
+	}
+}
+
+// known - Ошибки сервисов и их описания для пользователя.
+var known = []struct {
+	err error
+	msg string
+}{
+	{errs.ErrNotFound, "Запись не найдена"},
+	{errs.ErrAlreadyExist, "Запись с такой метаинформацией уже есть"},
+	{errs.ErrConflict, "Данные изменены на другом устройстве, загружена актуальная версия"},
+	{errs.ErrLargeData, "Размер данных слишком большой"},
+	{errs.ErrUnavailable, "Сервер недоступен"},
+	{errs.ErrPlaintext, "Шифрование отключено: запустите клиент с ключами или с -insecure-plaintext"},
+	{errs.ErrUnauthenticated, "Сессия закрыта, войдите заново"},
+}
+
+// message - Описание ошибки для пользователя.
+func message(err error) string {
+
+	for _, e := range known {
+		if errors.Is(err, e.err) {
+			return e.msg
+		}
+	}
+
+	return "Внутренняя ошибка сервиса"
+}
+
+func isKnown(err error) bool {
+
+	for _, e := range known {
+		if errors.Is(err, e.err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func clamp(v, min, max int) int {
+
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+
+	return v
+}
@@ -0,0 +1,579 @@
+package tui
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/client/app_services/app_service_binary"
+	"GophKeeper/internal/client/app_services/app_service_cred"
+	"GophKeeper/internal/client/app_services/app_service_text"
+	"GophKeeper/internal/client/model/binary_model"
+	"GophKeeper/internal/client/model/cred_model"
+	"GophKeeper/internal/client/model/list_model"
+	"GophKeeper/internal/client/model/text_model"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+const testToken = "token"
+
+// testKey - Ключ шифрования данных для тестов, генерируется один раз.
+var testKey = func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// seal - Шифрование значения ключом testKey, как это делает интерфейс.
+func seal(t *testing.T, value string) []byte {
+	data, err := secret.Encrypt(&testKey.PublicKey, []byte(value))
+	require.NoError(t, err)
+	return data
+}
+
+// open - Расшифровка значения ключом testKey.
+func open(t *testing.T, data []byte) string {
+	plain, err := secret.Decrypt(testKey, data)
+	require.NoError(t, err)
+	return string(plain)
+}
+
+// page - Список metas с фильтром filter одной страницей.
+func page(metas []string, filter list_model.Filter) list_model.Page {
+
+	var found []string
+	for _, meta := range metas {
+		if strings.HasPrefix(meta, filter.Prefix) && strings.Contains(meta, filter.Contains) {
+			found = append(found, meta)
+		}
+	}
+	sort.Strings(found)
+
+	return list_model.Page{MetaInfo: found}
+}
+
+// stubText - Текстовые данные в памяти.
+type stubText struct {
+	app_service_text.Sender
+	data map[string]text_model.Text
+}
+
+func (s *stubText) Get(meta, token string) (text_model.Text, error) {
+	if token != testToken {
+		return text_model.Text{}, errs.ErrUnauthenticated
+	}
+	data, ok := s.data[meta]
+	if !ok {
+		return text_model.Text{}, errs.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *stubText) Create(data text_model.Text, _ string) error {
+	if _, ok := s.data[data.MetaInfo]; ok {
+		return errs.ErrAlreadyExist
+	}
+	data.Version = 1
+	s.data[data.MetaInfo] = data
+	return nil
+}
+
+func (s *stubText) Change(data text_model.Text, _ string) error {
+	if s.data[data.MetaInfo].Version != data.Version {
+		return errs.ErrConflict
+	}
+	data.Version++
+	s.data[data.MetaInfo] = data
+	return nil
+}
+
+func (s *stubText) Delete(meta string, version int64, _ string) error {
+	if s.data[meta].Version != version {
+		return errs.ErrConflict
+	}
+	delete(s.data, meta)
+	return nil
+}
+
+func (s *stubText) List(filter list_model.Filter, _ string) (list_model.Page, error) {
+	metas := make([]string, 0, len(s.data))
+	for meta := range s.data {
+		metas = append(metas, meta)
+	}
+	return page(metas, filter), nil
+}
+
+// stubCred - Логины и пароли в памяти.
+type stubCred struct {
+	app_service_cred.Sender
+	data map[string]cred_model.Credential
+}
+
+func (s *stubCred) Get(meta, _ string) (cred_model.Credential, error) {
+	data, ok := s.data[meta]
+	if !ok {
+		return cred_model.Credential{}, errs.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *stubCred) List(filter list_model.Filter, _ string) (list_model.Page, error) {
+	metas := make([]string, 0, len(s.data))
+	for meta := range s.data {
+		metas = append(metas, meta)
+	}
+	return page(metas, filter), nil
+}
+
+// stubBinary - Бинарные данные в памяти.
+type stubBinary struct {
+	app_service_binary.Sender
+	data    map[string][]byte
+	version map[string]int64
+}
+
+func (s *stubBinary) Upload(in binary_model.Upload, r io.Reader, _ string) error {
+
+	_, exists := s.data[in.MetaInfo]
+	switch {
+	case in.Overwrite && !exists:
+		return errs.ErrNotFound
+	case !in.Overwrite && exists:
+		return errs.ErrAlreadyExist
+	case in.Overwrite && s.version[in.MetaInfo] != in.Version:
+		return errs.ErrConflict
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.data[in.MetaInfo] = data
+	s.version[in.MetaInfo]++
+	return nil
+}
+
+func (s *stubBinary) Download(meta string, w io.Writer, _ string) (binary_model.Info, error) {
+	data, ok := s.data[meta]
+	if !ok {
+		return binary_model.Info{}, errs.ErrNotFound
+	}
+	if _, err := w.Write(data); err != nil {
+		return binary_model.Info{}, err
+	}
+	return binary_model.Info{MetaInfo: meta, Version: s.version[meta]}, nil
+}
+
+func (s *stubBinary) Delete(meta string, version int64, _ string) error {
+	if _, ok := s.data[meta]; !ok {
+		return errs.ErrNotFound
+	}
+	if s.version[meta] != version {
+		return errs.ErrConflict
+	}
+	delete(s.data, meta)
+	delete(s.version, meta)
+	return nil
+}
+
+func (s *stubBinary) List(filter list_model.Filter, _ string) (list_model.Page, error) {
+	metas := make([]string, 0, len(s.data))
+	for meta := range s.data {
+		metas = append(metas, meta)
+	}
+
+	list := page(metas, filter)
+	for _, meta := range list.MetaInfo {
+		list.Versions = append(list.Versions, s.version[meta])
+	}
+	return list, nil
+}
+
+// syncScreen - Экран, сообщающий, что интерфейс вывел экран
+// и ждет следующего события.
+type syncScreen struct {
+	tcell.SimulationScreen
+	idle chan struct{}
+}
+
+func (s *syncScreen) PollEvent() tcell.Event {
+	s.idle <- struct{}{}
+	return s.SimulationScreen.PollEvent()
+}
+
+// harness - Интерфейс, работающий на экране-симуляторе.
+// Каждое нажатие ждет, пока интерфейс обработает его и выведет экран,
+// поэтому состояние можно проверять сразу после нажатия.
+type harness struct {
+	t      *testing.T
+	ui     *TUI
+	screen *syncScreen
+	done   chan error
+	exited bool
+}
+
+func start(t *testing.T, key *rsa.PrivateKey, opts ...Options) *harness {
+
+	screen := &syncScreen{
+		SimulationScreen: tcell.NewSimulationScreen("UTF-8"),
+		idle:             make(chan struct{}),
+	}
+
+	h := &harness{
+		t:      t,
+		ui:     New(append(opts, WithScreen(screen))...),
+		screen: screen,
+		done:   make(chan error, 1),
+	}
+
+	go func() {
+		h.done <- h.ui.Run(testToken, key)
+	}()
+	h.wait()
+
+	t.Cleanup(func() {
+		if !h.exited {
+			h.press(tcell.KeyCtrlC)
+		}
+	})
+
+	return h
+}
+
+// wait - Ожидание вывода экрана или выхода из интерфейса.
+func (h *harness) wait() {
+
+	select {
+	case <-h.screen.idle:
+	case err := <-h.done:
+		h.exited = true
+		require.NoError(h.t, err)
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("interface does not respond")
+	}
+}
+
+func (h *harness) press(key tcell.Key) {
+	h.screen.PostEventWait(tcell.NewEventKey(key, 0, tcell.ModNone))
+	h.wait()
+}
+
+func (h *harness) typeText(text string) {
+	for _, r := range text {
+		h.screen.PostEventWait(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		h.wait()
+	}
+}
+
+// contents - Строки экрана.
+func (h *harness) contents() string {
+
+	cells, w, _ := h.screen.GetContents()
+
+	var buf strings.Builder
+	for i, cell := range cells {
+		if len(cell.Runes) == 0 {
+			buf.WriteRune(' ')
+		} else {
+			buf.WriteString(string(cell.Runes))
+		}
+		if (i+1)%w == 0 {
+			buf.WriteRune('\n')
+		}
+	}
+
+	return buf.String()
+}
+
+// status - Строка состояния.
+func (h *harness) status() string {
+	lines := strings.Split(strings.TrimSuffix(h.contents(), "\n"), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func (h *harness) requireShown(texts ...string) {
+	h.t.Helper()
+	for _, text := range texts {
+		require.Contains(h.t, h.contents(), text)
+	}
+}
+
+func (h *harness) requireHidden(texts ...string) {
+	h.t.Helper()
+	for _, text := range texts {
+		require.NotContains(h.t, h.contents(), text)
+	}
+}
+
+func newText(t *testing.T, values map[string]string) *stubText {
+
+	s := &stubText{data: make(map[string]text_model.Text)}
+	for meta, value := range values {
+		s.data[meta] = text_model.Text{MetaInfo: meta, Data: seal(t, value), Version: 1}
+	}
+
+	return s
+}
+
+func TestTUI_Sidebar(t *testing.T) {
+
+	text := newText(t, map[string]string{"notes": "hello"})
+	cred := &stubCred{data: map[string]cred_model.Credential{
+		"mail": {MetaInfo: "mail", Login: seal(t, "user"), Password: seal(t, "pass"), Version: 3},
+	}}
+	bin := &stubBinary{data: map[string][]byte{"photo": nil}, version: map[string]int64{"photo": 1}}
+
+	h := start(t, testKey, WithText(text), WithCred(cred), WithBinary(bin))
+
+	h.requireShown("GophKeeper — Тексты", "Логины", "Файлы", "notes", "Версия: 1")
+	h.requireHidden("mail")
+
+	// Фокус сначала в боковой панели: стрелки переключают тип данных
+	h.press(tcell.KeyDown)
+	h.requireShown("GophKeeper — Логины", "mail", "user", "Версия: 3")
+	h.requireHidden("notes")
+
+	h.press(tcell.KeyDown)
+	h.requireShown("GophKeeper — Файлы", "photo", "s - сохранить в файл")
+
+	// Последний тип остается выбранным
+	h.press(tcell.KeyDown)
+	h.requireShown("GophKeeper — Файлы")
+
+	// В списке стрелки перемещают курсор, а не переключают тип
+	h.press(tcell.KeyTab)
+	h.press(tcell.KeyUp)
+	h.requireShown("GophKeeper — Файлы")
+
+	h.press(tcell.KeyLeft)
+	h.typeText("k")
+	h.requireShown("GophKeeper — Логины")
+
+	h.typeText("q")
+	require.True(t, h.exited)
+}
+
+func TestTUI_Filter(t *testing.T) {
+
+	text := newText(t, map[string]string{"bank": "1", "mail": "2", "work mail": "3"})
+	h := start(t, testKey, WithText(text))
+
+	h.requireShown("bank", "mail", "work mail")
+
+	// Фильтр без учета регистра, курсор на первой подходящей записи
+	h.typeText("/MAIL")
+	h.requireShown("/ MAIL", "mail", "work mail")
+	h.requireHidden("bank")
+	require.Equal(t, []string{"mail", "work mail"}, h.ui.visible)
+	require.Equal(t, "mail", h.ui.detail.meta)
+
+	h.press(tcell.KeyBackspace2)
+	h.press(tcell.KeyBackspace2)
+	h.press(tcell.KeyBackspace2)
+	h.press(tcell.KeyBackspace2)
+	h.typeText("x")
+	h.requireShown("Ничего не найдено")
+	require.Nil(t, h.ui.detail)
+
+	// Enter завершает ввод, фильтр остается
+	h.press(tcell.KeyEnter)
+	h.typeText("j")
+	require.Equal(t, "x", h.ui.filter)
+
+	// Esc сбрасывает фильтр
+	h.typeText("/")
+	h.press(tcell.KeyEscape)
+	h.requireShown("bank", "mail", "work mail")
+	require.Empty(t, h.ui.filter)
+}
+
+func TestTUI_Reveal(t *testing.T) {
+
+	cred := &stubCred{data: map[string]cred_model.Credential{
+		"a": {MetaInfo: "a", Login: seal(t, "alice"), Password: seal(t, "first-secret"), Version: 1},
+		"b": {MetaInfo: "b", Login: seal(t, "bob"), Password: seal(t, "second-secret"), Version: 1},
+	}}
+	h := start(t, testKey, WithCred(cred))
+
+	// Логин не секретный, пароль скрыт маской постоянной длины
+	h.requireShown("alice", mask(maskLen))
+	h.requireHidden("first-secret")
+
+	h.typeText("r")
+	h.requireShown("first-secret")
+	h.requireHidden(mask(maskLen))
+
+	h.typeText("r")
+	h.requireHidden("first-secret")
+
+	// При выборе другой записи значения снова скрываются
+	h.typeText("r")
+	h.press(tcell.KeyTab)
+	h.press(tcell.KeyDown)
+	h.requireShown("bob", mask(maskLen))
+	h.requireHidden("second-secret")
+}
+
+func TestTUI_CreateChangeDelete(t *testing.T) {
+
+	text := newText(t, nil)
+	h := start(t, testKey, WithText(text))
+
+	h.requireShown("Записей нет")
+
+	// Форма проверяет метаинформацию
+	h.typeText("n")
+	h.requireShown("Тексты: новая запись")
+	h.press(tcell.KeyCtrlS)
+	h.requireShown("Метаинформация не заполнена")
+
+	h.typeText("note")
+	h.press(tcell.KeyTab)
+	h.press(tcell.KeyCtrlS)
+	h.requireShown("Поле \"Текст\" не заполнено")
+
+	// Значение в форме скрыто
+	h.typeText("hello")
+	h.requireHidden("hello")
+
+	h.press(tcell.KeyCtrlS)
+	require.Equal(t, "Сохранено", h.status())
+	require.Equal(t, "hello", open(t, text.data["note"].Data))
+	h.requireShown("note", "Версия: 1")
+
+	// Запись с той же метаинформацией не создается
+	h.typeText("n")
+	h.typeText("note")
+	h.press(tcell.KeyTab)
+	h.typeText("again")
+	h.press(tcell.KeyCtrlS)
+	h.requireShown("Запись с такой метаинформацией уже есть")
+	h.press(tcell.KeyEscape)
+	require.Nil(t, h.ui.form)
+	require.Equal(t, "hello", open(t, text.data["note"].Data))
+
+	// Изменение: Enter в последнем поле сохраняет форму
+	h.typeText("e")
+	h.requireShown("Тексты: изменение", "Метаинформация: note")
+	h.press(tcell.KeyCtrlU)
+	h.typeText("bye")
+	h.press(tcell.KeyEnter)
+	require.Equal(t, "Сохранено", h.status())
+	require.Equal(t, "bye", open(t, text.data["note"].Data))
+	h.requireShown("Версия: 2")
+
+	// Удаление требует подтверждения
+	h.typeText("d")
+	h.requireShown("Удалить «note»? (y/n)")
+	h.typeText("n")
+	require.Equal(t, "Удаление отменено", h.status())
+	require.Contains(t, text.data, "note")
+
+	h.typeText("d")
+	h.typeText("y")
+	require.Equal(t, "Удалено", h.status())
+	require.NotContains(t, text.data, "note")
+	h.requireShown("Записей нет")
+}
+
+func TestTUI_Plaintext(t *testing.T) {
+
+	text := newText(t, nil)
+
+	// Без ключа данные не записываются
+	h := start(t, nil, WithText(text))
+	h.typeText("n")
+	require.Nil(t, h.ui.form)
+	require.Equal(t, message(errs.ErrPlaintext), h.status())
+	h.press(tcell.KeyCtrlC)
+
+	// В режиме --insecure-plaintext записываются как есть
+	h = start(t, nil, WithText(text), WithPlaintext(true))
+	h.typeText("n")
+	h.typeText("note")
+	h.press(tcell.KeyTab)
+	h.typeText("hello")
+	h.press(tcell.KeyCtrlS)
+	require.Equal(t, []byte("hello"), text.data["note"].Data)
+}
+
+func TestTUI_Binary(t *testing.T) {
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(src, []byte("first"), 0600))
+
+	bin := &stubBinary{data: make(map[string][]byte), version: make(map[string]int64)}
+	h := start(t, testKey, WithText(newText(t, nil)), WithBinary(bin))
+
+	h.press(tcell.KeyDown)
+	h.requireShown("GophKeeper — Файлы")
+
+	h.typeText("n")
+	h.requireShown("Путь к файлу")
+	h.typeText("report")
+	h.press(tcell.KeyTab)
+	h.typeText(filepath.Join(dir, "missing"))
+	h.press(tcell.KeyCtrlS)
+	h.requireShown("Файл не найден")
+
+	h.press(tcell.KeyCtrlU)
+	h.typeText(dir)
+	h.press(tcell.KeyCtrlS)
+	h.requireShown("Указан каталог, а не файл")
+
+	h.press(tcell.KeyCtrlU)
+	h.typeText(src)
+	h.press(tcell.KeyCtrlS)
+	require.Equal(t, "Сохранено", h.status())
+	require.NotContains(t, string(bin.data["report"]), "first")
+	h.requireShown("report", "s - сохранить в файл")
+
+	// Замена данных другим файлом
+	require.NoError(t, os.WriteFile(src, []byte("second"), 0600))
+	h.typeText("e")
+	h.typeText(src)
+	h.press(tcell.KeyEnter)
+	require.Equal(t, "Сохранено", h.status())
+
+	// Сохранение в каталог под исходным именем файла
+	out := filepath.Join(dir, "out")
+	require.NoError(t, os.Mkdir(out, 0700))
+
+	h.typeText("s")
+	h.requireShown("Файлы: сохранение в файл", "Метаинформация: report")
+	h.typeText(out)
+	h.press(tcell.KeyEnter)
+	require.Nil(t, h.ui.form)
+	require.True(t, strings.HasPrefix(h.status(), "Файл сохранен: "))
+
+	data, err := os.ReadFile(filepath.Join(out, "report.pdf"))
+	require.NoError(t, err)
+	require.True(t, bytes.Equal([]byte("second"), data))
+
+	// Существующий файл не перезаписывается
+	h.typeText("s")
+	h.typeText(out)
+	h.press(tcell.KeyEnter)
+	h.requireShown("Файл уже существует")
+	h.press(tcell.KeyEscape)
+
+	h.typeText("d")
+	h.typeText("y")
+	require.Equal(t, "Удалено", h.status())
+	require.Empty(t, bin.data)
+}
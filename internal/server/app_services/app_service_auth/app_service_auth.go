@@ -3,12 +3,17 @@ package app_service_auth
 import (
 	"errors"
 	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	authModel "GophKeeper/internal/server/model/auth"
 	"GophKeeper/internal/storage/auth_store"
+	"GophKeeper/internal/storage/key_store"
+	"GophKeeper/internal/storage/session_store"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/passhash"
 	"GophKeeper/pkg/token"
 )
 
@@ -16,9 +21,26 @@ import (
 var minPasswordLength = 6
 
 type AuthApp interface {
-	Login(in authModel.Credential) (string, error)
-	Register(in authModel.Credential) (string, error)
-	ChangePassword(email, password string) error
+	Login(in authModel.Credential) (authModel.Tokens, error)
+	Register(in authModel.Credential) (authModel.Tokens, error)
+	Refresh(refresh string) (authModel.Tokens, error)
+	Logout(email, session string) error
+	ListSessions(email string) ([]authModel.Session, error)
+	RevokeSession(email, session string) error
+	ChangePassword(email, session, password string) error
+	GetVaultKey(email string) ([]byte, error)
+	SetVaultKey(email string, sealed []byte) error
+	Enroll2FA(email string) (string, []string, error)
+	Confirm2FA(email, code string) error
+	Disable2FA(email, code string) error
+	VerifyOTP(challenge, code string) (authModel.Tokens, error)
+	DeleteAccount(email, password string) error
+}
+
+// AccountData - Хранилище данных пользователя, которые нужно удалить
+// вместе с учетной записью отдельно от хранилища пользователей.
+type AccountData interface {
+	DeleteAll(email string) error
 }
 
 // AuthAppOption - определяет операцию сервиса авторизации.
@@ -26,17 +48,40 @@ type AuthAppOption func(serv *AuthAppService)
 
 // AuthAppService отвеает за сервис авторизации и регистрации пользователя.
 type AuthAppService struct {
-	store     auth_store.AuthStorage
-	logger    *zap.Logger
+	store    auth_store.AuthStorage
+	sessions session_store.SessionStorage
+	keys     key_store.KeyStorage
+	logger   *zap.Logger
+	// data - Данные, удаляемые вместе с учетной записью.
+	data []AccountData
+	// hasher - Хэширование паролей, полученных от клиента.
+	hasher passhash.Hasher
+	// otp - Входы, ожидающие код второго фактора.
+	otp *challenges
+	// uniform - Одинаковая ошибка ErrInvalidCredential для неизвестного
+	// пользователя и неверного пароля, чтобы нельзя было перебирать email.
+	uniform *dummyHash
+	// secretKey - Ключ подписи JWT, из него же производится
+	// ключ шифрования секретов второго фактора.
 	secretKey string
+	// accessTTL - Время жизни токена доступа.
+	accessTTL time.Duration
+	// refreshTTL - Время жизни refresh token.
+	refreshTTL time.Duration
 }
 
 // NewAuthService - Создание экземпляра сервиса авторизации.
 func NewAuthService(store auth_store.AuthStorage, opts ...AuthAppOption) *AuthAppService {
 
 	auth := &AuthAppService{
-		store:  store,
-		logger: zap.L(),
+		store:      store,
+		sessions:   session_store.NewMemoryStorage(),
+		keys:       key_store.NewMemoryStorage(),
+		logger:     zap.L(),
+		hasher:     passhash.New(passhash.DefaultArgon2id),
+		otp:        newChallenges(),
+		accessTTL:  token.AccessTTL,
+		refreshTTL: token.RefreshTTL,
 	}
 
 	for _, opt := range opts {
@@ -53,71 +98,230 @@ func WithSecretKey(key string) AuthAppOption {
 	}
 }
 
-// Login - Авторизация пользователя.
-// При успешной авторизации возвращается JWT.
-func (auth AuthAppService) Login(in authModel.Credential) (string, error) {
+// WithSessionStore - Реестр сессий пользователей.
+// По умолчанию сессии хранятся в памяти.
+func WithSessionStore(sessions session_store.SessionStorage) AuthAppOption {
+	return func(auth *AuthAppService) {
+		auth.sessions = sessions
+	}
+}
 
-	if err := auth.store.Find(in); err != nil {
-		if errors.Is(err, errs.ErrNotFound) {
-			return ``, errs.ErrNotFound
+// WithKeyStore - Хранилище зашифрованных ключей хранилища пользователей.
+// По умолчанию ключи хранятся в памяти.
+func WithKeyStore(keys key_store.KeyStorage) AuthAppOption {
+	return func(auth *AuthAppService) {
+		auth.keys = keys
+	}
+}
+
+// WithAccountData - Хранилища данных, которые не удаляются вместе с пользователем
+// в auth_store.AuthStorage, например хранилища в памяти. В БД данные
+// удаляются в одной транзакции с пользователем.
+func WithAccountData(data ...AccountData) AuthAppOption {
+	return func(auth *AuthAppService) {
+		auth.data = append(auth.data, data...)
+	}
+}
+
+// WithPasswordHash - Алгоритм и параметры хэширования паролей.
+// По умолчанию - Argon2id. Хэши с другими параметрами заменяются при входе.
+func WithPasswordHash(params passhash.Params) AuthAppOption {
+	return func(auth *AuthAppService) {
+		auth.hasher = passhash.New(params)
+	}
+}
+
+// WithUniformErrors - Login возвращает ErrInvalidCredential и для неизвестного
+// пользователя, и для неверного пароля. Пароль неизвестного пользователя тоже
+// хэшируется, чтобы время ответа не выдавало наличие учетной записи.
+func WithUniformErrors() AuthAppOption {
+	return func(auth *AuthAppService) {
+		auth.uniform = &dummyHash{}
+	}
+}
+
+// WithTokenTTL - Время жизни токена доступа и refresh token.
+// Нулевые значения не меняют значения по умолчанию.
+func WithTokenTTL(access, refresh time.Duration) AuthAppOption {
+	return func(auth *AuthAppService) {
+		if access > 0 {
+			auth.accessTTL = access
 		}
+		if refresh > 0 {
+			auth.refreshTTL = refresh
+		}
+	}
+}
 
-		if errors.Is(err, auth_store.ErrInvalidPassword) {
-			return ``, ErrInvalidPassword
+// Login - Авторизация пользователя.
+// При успешной авторизации возвращаются токены пользователя.
+// Если подключен второй фактор, вместо токенов возвращается Challenge
+// для подтверждения входа кодом в VerifyOTP.
+// Устаревший хэш пароля заменяется хэшем с текущими параметрами.
+func (auth AuthAppService) Login(in authModel.Credential) (authModel.Tokens, error) {
+
+	user, err := auth.store.Get(in.Email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			if auth.uniform != nil {
+				auth.uniform.verify(auth.hasher, in.Password)
+				return authModel.Tokens{}, ErrInvalidCredential
+			}
+			return authModel.Tokens{}, errs.ErrNotFound
 		}
 
 		auth.logger.Error("failed find user", zap.Error(err))
-		return ``, errs.ErrInternal
+		return authModel.Tokens{}, errs.ErrInternal
 	}
 
-	tokenStr, errJWT := token.GenerateJWT(in.Email, auth.secretKey)
-	if errJWT != nil {
-		auth.logger.Error("failed generate JWT", zap.Error(errJWT))
-		return ``, errs.ErrInternal
+	ok, rehash, err := auth.hasher.Verify(user.PasswordHash, in.Password)
+	if err != nil {
+		auth.logger.Error("failed verify password", zap.Error(err))
+		return authModel.Tokens{}, errs.ErrInternal
 	}
 
-	return tokenStr, nil
+	if !ok {
+		if auth.uniform != nil {
+			return authModel.Tokens{}, ErrInvalidCredential
+		}
+		return authModel.Tokens{}, ErrInvalidPassword
+	}
+
+	if rehash {
+		auth.rehash(in)
+	}
+
+	if tokens, required, errOTP := auth.challenge(in.Email); errOTP != nil || required {
+		return tokens, errOTP
+	}
+
+	return auth.issue(in.Email)
 }
 
 // Register - Регистрация пользователя.
-// При успешной регистрации возвращается JWT.
-func (auth AuthAppService) Register(in authModel.Credential) (string, error) {
+// При успешной регистрации возвращаются токены пользователя.
+func (auth AuthAppService) Register(in authModel.Credential) (authModel.Tokens, error) {
 
-	cred := authModel.Credential{
-		Email:    in.Email,
-		Password: in.Password,
+	if errCred := checkCredential(in); errCred != nil {
+		return authModel.Tokens{}, errCred
 	}
 
-	if errCred := checkCredential(cred); errCred != nil {
-		return ``, errCred
+	hash, errHash := auth.hasher.Hash(in.Password)
+	if errHash != nil {
+		auth.logger.Error("failed hash password", zap.Error(errHash))
+		return authModel.Tokens{}, errs.ErrInternal
 	}
 
-	if err := auth.store.Create(cred); err != nil {
+	if err := auth.store.Create(authModel.User{Email: in.Email, PasswordHash: hash}); err != nil {
 		if err == errs.ErrAlreadyExist {
-			return ``, err
+			return authModel.Tokens{}, err
 		}
 
 		auth.logger.Error("failed create user", zap.Error(err))
-		return ``, errs.ErrInternal
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	return auth.issue(in.Email)
+}
+
+// Refresh - Обмен refresh token на новые токены пользователя.
+// Refresh token одноразовый: после обмена он больше не действует,
+// а сессия продолжается с новым refresh token.
+func (auth AuthAppService) Refresh(refresh string) (authModel.Tokens, error) {
+
+	next, errRefresh := token.GenerateRefresh()
+	if errRefresh != nil {
+		auth.logger.Error("failed generate refresh token", zap.Error(errRefresh))
+		return authModel.Tokens{}, errs.ErrInternal
 	}
 
-	tokenStr, errJWT := token.GenerateJWT(in.Email, auth.secretKey)
+	expiresAt := time.Now().Add(auth.refreshTTL)
+
+	session, err := auth.sessions.Rotate(token.HashRefresh(refresh), token.HashRefresh(next), expiresAt)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return authModel.Tokens{}, ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed rotate refresh token", zap.Error(err))
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	if session.ExpiresAt.Before(time.Now()) {
+		auth.closeSession(session.Email, session.ID)
+		return authModel.Tokens{}, ErrUnauthenticated
+	}
+
+	access, errJWT := token.GenerateSessionJWT(session.Email, session.ID, auth.secretKey, auth.accessTTL)
 	if errJWT != nil {
 		auth.logger.Error("failed generate JWT", zap.Error(errJWT))
-		return ``, errs.ErrInternal
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	return authModel.Tokens{
+		Access:  access,
+		Refresh: next,
+	}, nil
+}
+
+// Logout - Завершение сессии session пользователя email.
+func (auth AuthAppService) Logout(email, session string) error {
+
+	if err := auth.sessions.Delete(email, session); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed delete session", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// ListSessions - Открытые сессии пользователя email, от новых к старым.
+func (auth AuthAppService) ListSessions(email string) ([]authModel.Session, error) {
+
+	sessions, err := auth.sessions.List(email)
+	if err != nil {
+		auth.logger.Error("failed list sessions", zap.Error(err))
+		return nil, errs.ErrInternal
 	}
 
-	return tokenStr, nil
+	return sessions, nil
+}
+
+// RevokeSession - Закрытие сессии session пользователя email.
+// Если сессии нет, возвращается errs.ErrNotFound.
+func (auth AuthAppService) RevokeSession(email, session string) error {
+
+	if err := auth.sessions.Delete(email, session); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrNotFound
+		}
+
+		auth.logger.Error("failed delete session", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
 }
 
 // ChangePassword - Смена пароля пользователя.
-func (auth AuthAppService) ChangePassword(email, password string) error {
+// Все сессии пользователя, кроме текущей session, закрываются.
+func (auth AuthAppService) ChangePassword(email, session, password string) error {
 
 	if len(password) < minPasswordLength {
 		return ErrShortPassword
 	}
 
-	if err := auth.store.Update(email, password); err != nil {
+	hash, errHash := auth.hasher.Hash(password)
+	if errHash != nil {
+		auth.logger.Error("failed hash password", zap.Error(errHash))
+		return errs.ErrInternal
+	}
+
+	if err := auth.store.Update(email, hash); err != nil {
 
 		if errors.Is(err, errs.ErrNotFound) {
 			return ErrUnauthenticated
@@ -127,9 +331,187 @@ func (auth AuthAppService) ChangePassword(email, password string) error {
 		return errs.ErrInternal
 	}
 
+	if err := auth.sessions.DeleteOthers(email, session); err != nil {
+		auth.logger.Error("failed delete sessions", zap.Error(err))
+		return errs.ErrInternal
+	}
+
 	return nil
 }
 
+// DeleteAccount - Удаление учетной записи email и всех данных пользователя.
+// Требуется текущий пароль password, при неверном пароле возвращается ErrInvalidPassword.
+// Все сессии пользователя закрываются.
+func (auth AuthAppService) DeleteAccount(email, password string) error {
+
+	user, err := auth.store.Get(email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed find user", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	ok, _, err := auth.hasher.Verify(user.PasswordHash, password)
+	if err != nil {
+		auth.logger.Error("failed verify password", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	if !ok {
+		return ErrInvalidPassword
+	}
+
+	if err = auth.store.Delete(email); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed delete user", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	if err = auth.sessions.DeleteOthers(email, ``); err != nil {
+		auth.logger.Error("failed delete sessions", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	for _, data := range auth.data {
+		if err = data.DeleteAll(email); err != nil {
+			auth.logger.Error("failed delete user data", zap.Error(err))
+			return errs.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// GetVaultKey - Ключ хранилища пользователя email, зашифрованный мастер-паролем.
+// Если ключа нет, возвращается errs.ErrNotFound.
+func (auth AuthAppService) GetVaultKey(email string) ([]byte, error) {
+
+	sealed, err := auth.keys.Get(email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errs.ErrNotFound
+		}
+
+		auth.logger.Error("failed get vault key", zap.Error(err))
+		return nil, errs.ErrInternal
+	}
+
+	return sealed, nil
+}
+
+// SetVaultKey - Сохранение ключа хранилища пользователя email.
+// Сохраненный ключ не перезаписывается: данные, зашифрованные им,
+// стали бы недоступны. В этом случае возвращается errs.ErrAlreadyExist.
+func (auth AuthAppService) SetVaultKey(email string, sealed []byte) error {
+
+	if len(sealed) == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	if err := auth.keys.Create(email, sealed); err != nil {
+		if errors.Is(err, errs.ErrAlreadyExist) {
+			return errs.ErrAlreadyExist
+		}
+
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed save vault key", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// issue - Открытие новой сессии пользователя email
+// и выдача токена доступа и refresh token этой сессии.
+func (auth AuthAppService) issue(email string) (authModel.Tokens, error) {
+
+	id, errID := token.GenerateSessionID()
+	if errID != nil {
+		auth.logger.Error("failed generate session id", zap.Error(errID))
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	access, errJWT := token.GenerateSessionJWT(email, id, auth.secretKey, auth.accessTTL)
+	if errJWT != nil {
+		auth.logger.Error("failed generate JWT", zap.Error(errJWT))
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	refresh, errRefresh := token.GenerateRefresh()
+	if errRefresh != nil {
+		auth.logger.Error("failed generate refresh token", zap.Error(errRefresh))
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	now := time.Now()
+	session := authModel.Session{
+		ID:          id,
+		Email:       email,
+		RefreshHash: token.HashRefresh(refresh),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(auth.refreshTTL),
+	}
+
+	if err := auth.sessions.Create(session); err != nil {
+		auth.logger.Error("failed create session", zap.Error(err))
+		return authModel.Tokens{}, errs.ErrInternal
+	}
+
+	return authModel.Tokens{
+		Access:  access,
+		Refresh: refresh,
+	}, nil
+}
+
+// rehash - Замена устаревшего хэша пароля после успешного входа.
+// Ошибка только логируется: прежний хэш продолжает действовать.
+func (auth AuthAppService) rehash(in authModel.Credential) {
+
+	hash, err := auth.hasher.Hash(in.Password)
+	if err == nil {
+		err = auth.store.Update(in.Email, hash)
+	}
+
+	if err != nil {
+		auth.logger.Error("failed rehash password", zap.Error(err))
+	}
+}
+
+// dummyHash - Хэш случайного пароля для проверки паролей неизвестных пользователей.
+// Создается при первом обращении: хэширование с параметрами по умолчанию дорогое.
+type dummyHash struct {
+	once sync.Once
+	hash string
+}
+
+// verify - Проверка пароля по хэшу-пустышке, результат не важен.
+func (d *dummyHash) verify(hasher passhash.Hasher, password string) {
+
+	d.once.Do(func() {
+		d.hash, _ = hasher.Hash(password + "dummy")
+	})
+
+	hasher.Verify(d.hash, password)
+}
+
+// closeSession - Закрытие истекшей сессии. Ошибка только логируется:
+// сессия и так недействительна.
+func (auth AuthAppService) closeSession(email, id string) {
+
+	if err := auth.sessions.Delete(email, id); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		auth.logger.Error("failed delete session", zap.Error(err))
+	}
+}
+
 // checkCredential - Проверка корректности пароля и email.
 func checkCredential(cred authModel.Credential) error {
 	if len(cred.Password) < minPasswordLength {
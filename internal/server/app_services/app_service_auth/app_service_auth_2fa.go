@@ -0,0 +1,348 @@
+package app_service_auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	authModel "GophKeeper/internal/server/model/auth"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/token"
+	"GophKeeper/pkg/totp"
+)
+
+const (
+	// totpIssuer - Название сервиса в приложении-аутентификаторе.
+	totpIssuer = "GophKeeper"
+	// challengeTTL - Время ожидания кода второго фактора после проверки пароля.
+	challengeTTL = 5 * time.Minute
+	// challengeAttempts - Количество попыток ввести код для одного входа.
+	challengeAttempts = 5
+)
+
+// challenge - Вход, ожидающий код второго фактора.
+type challenge struct {
+	email     string
+	expiresAt time.Time
+	attempts  int
+}
+
+// challenges - Входы, ожидающие код второго фактора.
+// Хранятся в памяти: после перезапуска сервера вход начинается заново.
+type challenges struct {
+	mutex   sync.Mutex
+	pending map[string]challenge
+	// verify - Последовательная проверка кодов: принятый код
+	// не должен быть принят повторно параллельным запросом.
+	verify sync.Mutex
+}
+
+func newChallenges() *challenges {
+	return &challenges{
+		pending: make(map[string]challenge),
+	}
+}
+
+// add - Новый вход пользователя email. Истекшие входы удаляются.
+func (c *challenges) add(id, email string) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for key, ch := range c.pending {
+		if ch.expiresAt.Before(now) {
+			delete(c.pending, key)
+		}
+	}
+
+	c.pending[id] = challenge{
+		email:     email,
+		expiresAt: now.Add(challengeTTL),
+	}
+}
+
+// get - Пользователь незавершенного входа id.
+func (c *challenges) get(id string) (string, bool) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch, ok := c.pending[id]
+	if !ok {
+		return ``, false
+	}
+
+	if ch.expiresAt.Before(time.Now()) {
+		delete(c.pending, id)
+		return ``, false
+	}
+
+	return ch.email, true
+}
+
+// fail - Неудачная попытка ввода кода. После challengeAttempts попыток
+// вход отменяется.
+func (c *challenges) fail(id string) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ch, ok := c.pending[id]
+	if !ok {
+		return
+	}
+
+	ch.attempts++
+	if ch.attempts >= challengeAttempts {
+		delete(c.pending, id)
+		return
+	}
+
+	c.pending[id] = ch
+}
+
+// done - Завершение входа id.
+func (c *challenges) done(id string) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.pending, id)
+}
+
+// Enroll2FA - Подключение второго фактора пользователю email.
+// Возвращает ссылку otpauth:// для приложения-аутентификатора и коды
+// восстановления. Второй фактор действует после подтверждения кодом в Confirm2FA.
+func (auth AuthAppService) Enroll2FA(email string) (string, []string, error) {
+
+	current, err := auth.store.GetTOTP(email)
+	switch {
+	case err == nil && current.Confirmed:
+		return ``, nil, errs.ErrAlreadyExist
+
+	case err != nil && !errors.Is(err, errs.ErrNotFound):
+		auth.logger.Error("failed get totp", zap.Error(err))
+		return ``, nil, errs.ErrInternal
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		auth.logger.Error("failed generate totp secret", zap.Error(err))
+		return ``, nil, errs.ErrInternal
+	}
+
+	recovery, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		auth.logger.Error("failed generate recovery codes", zap.Error(err))
+		return ``, nil, errs.ErrInternal
+	}
+
+	sealed, err := totp.Seal(auth.secretKey, secret)
+	if err != nil {
+		auth.logger.Error("failed seal totp secret", zap.Error(err))
+		return ``, nil, errs.ErrInternal
+	}
+
+	state := authModel.TOTP{
+		SealedSecret:   sealed,
+		RecoveryHashes: make([]string, 0, len(recovery)),
+	}
+	for _, code := range recovery {
+		state.RecoveryHashes = append(state.RecoveryHashes, totp.HashRecoveryCode(code))
+	}
+
+	if err = auth.store.SetTOTP(email, state); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ``, nil, ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed save totp", zap.Error(err))
+		return ``, nil, errs.ErrInternal
+	}
+
+	return totp.URI(totpIssuer, email, secret), recovery, nil
+}
+
+// Confirm2FA - Подтверждение подключения второго фактора кодом из приложения.
+// Если подключение не начато, возвращается errs.ErrNotFound.
+func (auth AuthAppService) Confirm2FA(email, code string) error {
+
+	auth.otp.verify.Lock()
+	defer auth.otp.verify.Unlock()
+
+	state, err := auth.getTOTP(email)
+	if err != nil {
+		return err
+	}
+
+	if state.Confirmed {
+		return errs.ErrAlreadyExist
+	}
+
+	if state, err = auth.checkCode(state, code, false); err != nil {
+		return err
+	}
+
+	state.Confirmed = true
+	return auth.setTOTP(email, state)
+}
+
+// Disable2FA - Отключение второго фактора. Требуется код из приложения
+// или код восстановления. Если второй фактор не подключен, возвращается errs.ErrNotFound.
+func (auth AuthAppService) Disable2FA(email, code string) error {
+
+	auth.otp.verify.Lock()
+	defer auth.otp.verify.Unlock()
+
+	state, err := auth.getTOTP(email)
+	if err != nil {
+		return err
+	}
+
+	if _, err = auth.checkCode(state, code, state.Confirmed); err != nil {
+		return err
+	}
+
+	if err = auth.store.DeleteTOTP(email); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		auth.logger.Error("failed delete totp", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
+
+// VerifyOTP - Второй шаг входа: проверка кода второго фактора для входа id,
+// начатого Login. Принимается код из приложения или код восстановления.
+// Неизвестный или истекший вход - ErrUnauthenticated.
+func (auth AuthAppService) VerifyOTP(id, code string) (authModel.Tokens, error) {
+
+	email, ok := auth.otp.get(id)
+	if !ok {
+		return authModel.Tokens{}, ErrUnauthenticated
+	}
+
+	auth.otp.verify.Lock()
+	defer auth.otp.verify.Unlock()
+
+	state, err := auth.getTOTP(email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			// Второй фактор отключен после начала входа
+			auth.otp.done(id)
+			return authModel.Tokens{}, ErrUnauthenticated
+		}
+		return authModel.Tokens{}, err
+	}
+
+	state, err = auth.checkCode(state, code, true)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOTP) {
+			auth.otp.fail(id)
+		}
+		return authModel.Tokens{}, err
+	}
+
+	if err = auth.setTOTP(email, state); err != nil {
+		return authModel.Tokens{}, err
+	}
+
+	auth.otp.done(id)
+
+	return auth.issue(email)
+}
+
+// challenge - Первый шаг входа пользователя email с подключенным вторым фактором.
+// Токены не выдаются, пока код не подтвержден в VerifyOTP.
+// required - второй фактор подключен и вход ожидает код.
+func (auth AuthAppService) challenge(email string) (authModel.Tokens, bool, error) {
+
+	state, err := auth.store.GetTOTP(email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return authModel.Tokens{}, false, nil
+		}
+
+		auth.logger.Error("failed get totp", zap.Error(err))
+		return authModel.Tokens{}, false, errs.ErrInternal
+	}
+
+	if !state.Confirmed {
+		return authModel.Tokens{}, false, nil
+	}
+
+	id, err := token.GenerateRefresh()
+	if err != nil {
+		auth.logger.Error("failed generate challenge", zap.Error(err))
+		return authModel.Tokens{}, false, errs.ErrInternal
+	}
+
+	auth.otp.add(id, email)
+
+	return authModel.Tokens{Challenge: id}, true, nil
+}
+
+// checkCode - Проверка кода из приложения или, если recovery, кода восстановления.
+// Возвращает состояние второго фактора, в котором принятый код больше не действует.
+func (auth AuthAppService) checkCode(state authModel.TOTP, code string, recovery bool) (authModel.TOTP, error) {
+
+	secret, err := totp.Open(auth.secretKey, state.SealedSecret)
+	if err != nil {
+		auth.logger.Error("failed open totp secret", zap.Error(err))
+		return state, errs.ErrInternal
+	}
+
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		if step <= state.LastStep {
+			return state, ErrInvalidOTP
+		}
+
+		state.LastStep = step
+		return state, nil
+	}
+
+	if !recovery {
+		return state, ErrInvalidOTP
+	}
+
+	hashes, ok := totp.UseRecoveryCode(state.RecoveryHashes, code)
+	if !ok {
+		return state, ErrInvalidOTP
+	}
+
+	state.RecoveryHashes = hashes
+	return state, nil
+}
+
+// getTOTP - Второй фактор пользователя email, errs.ErrNotFound, если он не подключен.
+func (auth AuthAppService) getTOTP(email string) (authModel.TOTP, error) {
+
+	state, err := auth.store.GetTOTP(email)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return authModel.TOTP{}, errs.ErrNotFound
+		}
+
+		auth.logger.Error("failed get totp", zap.Error(err))
+		return authModel.TOTP{}, errs.ErrInternal
+	}
+
+	return state, nil
+}
+
+// setTOTP - Сохранение второго фактора пользователя email.
+func (auth AuthAppService) setTOTP(email string, state authModel.TOTP) error {
+
+	if err := auth.store.SetTOTP(email, state); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return ErrUnauthenticated
+		}
+
+		auth.logger.Error("failed save totp", zap.Error(err))
+		return errs.ErrInternal
+	}
+
+	return nil
+}
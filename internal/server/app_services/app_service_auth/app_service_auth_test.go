@@ -1,17 +1,31 @@
 package app_service_auth
 
 import (
+	"encoding/base32"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"GophKeeper/internal/server/model/auth"
+	"GophKeeper/internal/server/model/text"
 	"GophKeeper/internal/storage/auth_store"
 	storeMock "GophKeeper/internal/storage/auth_store/mocks"
+	"GophKeeper/internal/storage/key_store"
+	"GophKeeper/internal/storage/text_store"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/passhash"
+	"GophKeeper/pkg/token"
+	"GophKeeper/pkg/totp"
 )
 
+// testHash - Параметры хэширования паролей в тестах.
+var testHash = passhash.Params{Alg: passhash.Argon2id, Memory: 1024, Time: 1, Threads: 1}
+
 func TestAuthAppService_Login(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -19,18 +33,29 @@ func TestAuthAppService_Login(t *testing.T) {
 
 	store := storeMock.NewMockAuthStorage(ctrl)
 
+	hash, err := passhash.New(testHash).Hash("testPassword")
+	require.NoError(t, err)
+
+	// Хэш с прежними параметрами заменяется при входе
+	weakHash, err := passhash.New(passhash.Params{Alg: passhash.Argon2id, Memory: 512, Time: 1, Threads: 1}).Hash("testPassword")
+	require.NoError(t, err)
+
+	bcryptHash, err := passhash.New(passhash.Params{Alg: passhash.Bcrypt, Cost: 4}).Hash("testPassword")
+	require.NoError(t, err)
+
 	tests := []struct {
 		name      string
-		credStore auth.Credential
+		userStore auth.User
 		credServ  auth.Credential
 		waitErr   error
 		storeErr  error
+		rehash    bool
 	}{
 		{
 			name: "Success",
-			credStore: auth.Credential{
-				Email:    "test@emailcom",
-				Password: "testPassword",
+			userStore: auth.User{
+				Email:        "test@emailcom",
+				PasswordHash: hash,
 			},
 			credServ: auth.Credential{
 				Email:    "test@emailcom",
@@ -41,7 +66,7 @@ func TestAuthAppService_Login(t *testing.T) {
 		},
 		{
 			name:      "Unregistered",
-			credStore: auth.Credential{},
+			userStore: auth.User{},
 			credServ: auth.Credential{
 				Email:    "test@emailcom",
 				Password: "testPassword",
@@ -51,25 +76,86 @@ func TestAuthAppService_Login(t *testing.T) {
 		},
 		{
 			name: "Invalid password",
-			credStore: auth.Credential{
+			userStore: auth.User{
+				Email:        "test@emailcom",
+				PasswordHash: hash,
+			},
+			credServ: auth.Credential{
 				Email:    "test@emailcom",
 				Password: "passwordTest",
 			},
+			waitErr: ErrInvalidPassword,
+		},
+		{
+			name: "Legacy password",
+			userStore: auth.User{
+				Email:        "test@emailcom",
+				PasswordHash: "testPassword",
+			},
+			credServ: auth.Credential{
+				Email:    "test@emailcom",
+				Password: "testPassword",
+			},
+			rehash: true,
+		},
+		{
+			name: "Legacy invalid password",
+			userStore: auth.User{
+				Email:        "test@emailcom",
+				PasswordHash: "passwordTest",
+			},
 			credServ: auth.Credential{
 				Email:    "test@emailcom",
 				Password: "testPassword",
 			},
-			waitErr:  ErrInvalidPassword,
-			storeErr: auth_store.ErrInvalidPassword,
+			waitErr: ErrInvalidPassword,
+		},
+		{
+			name: "Outdated parameters",
+			userStore: auth.User{
+				Email:        "test@emailcom",
+				PasswordHash: weakHash,
+			},
+			credServ: auth.Credential{
+				Email:    "test@emailcom",
+				Password: "testPassword",
+			},
+			rehash: true,
+		},
+		{
+			name: "Another algorithm",
+			userStore: auth.User{
+				Email:        "test@emailcom",
+				PasswordHash: bcryptHash,
+			},
+			credServ: auth.Credential{
+				Email:    "test@emailcom",
+				Password: "testPassword",
+			},
+			rehash: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			store.EXPECT().Find(tt.credServ).Return(tt.storeErr)
+			store.EXPECT().Get(tt.credServ.Email).Return(tt.userStore, tt.storeErr)
+
+			if tt.waitErr == nil {
+				store.EXPECT().GetTOTP(tt.credServ.Email).Return(auth.TOTP{}, errs.ErrNotFound)
+			}
+
+			if tt.rehash {
+				store.EXPECT().Update(tt.credServ.Email, gomock.Any()).DoAndReturn(func(email, hash string) error {
+					ok, rehash, errVerify := passhash.New(testHash).Verify(hash, tt.credServ.Password)
+					require.NoError(t, errVerify)
+					assert.True(t, ok)
+					assert.False(t, rehash)
+					return nil
+				})
+			}
 
-			authServ := NewAuthService(store)
+			authServ := NewAuthService(store, WithPasswordHash(testHash))
 			_, err := authServ.Login(tt.credServ)
 
 			assert.Equal(t, err, tt.waitErr)
@@ -77,6 +163,27 @@ func TestAuthAppService_Login(t *testing.T) {
 	}
 }
 
+func TestAuthAppService_LoginUniformErrors(t *testing.T) {
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage(), WithPasswordHash(testHash), WithUniformErrors())
+	cred := auth.Credential{
+		Email:    "test@email.com",
+		Password: "testPassword",
+	}
+
+	_, err := authServ.Register(cred)
+	require.NoError(t, err)
+
+	_, err = authServ.Login(auth.Credential{Email: "other@email.com", Password: cred.Password})
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+
+	_, err = authServ.Login(auth.Credential{Email: cred.Email, Password: "passwordTest"})
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+
+	_, err = authServ.Login(cred)
+	require.NoError(t, err)
+}
+
 func TestAuthAppService_Register(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -132,10 +239,19 @@ func TestAuthAppService_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			if tt.callStore {
-				store.EXPECT().Create(tt.cred).Return(tt.waitErr)
+				// Пароль хранится только в виде хэша со случайной солью
+				store.EXPECT().Create(gomock.Any()).DoAndReturn(func(user auth.User) error {
+					assert.Equal(t, tt.cred.Email, user.Email)
+					assert.NotContains(t, user.PasswordHash, tt.cred.Password)
+
+					ok, _, errVerify := passhash.New(testHash).Verify(user.PasswordHash, tt.cred.Password)
+					require.NoError(t, errVerify)
+					assert.True(t, ok)
+					return tt.waitErr
+				})
 			}
 
-			authServ := NewAuthService(store)
+			authServ := NewAuthService(store, WithPasswordHash(testHash))
 			_, err := authServ.Register(tt.cred)
 
 			assert.Equal(t, err, tt.waitErr)
@@ -185,13 +301,260 @@ func TestAuthAppService_ChangePassword(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			if tt.callStoreUpdate {
-				store.EXPECT().Update(tt.email, tt.password).Return(tt.errStore)
+				store.EXPECT().Update(tt.email, gomock.Any()).DoAndReturn(func(email, hash string) error {
+					ok, _, errVerify := passhash.New(testHash).Verify(hash, tt.password)
+					require.NoError(t, errVerify)
+					assert.True(t, ok)
+					return tt.errStore
+				})
 			}
 
-			authServ := NewAuthService(store)
-			err := authServ.ChangePassword(tt.email, tt.password)
+			authServ := NewAuthService(store, WithPasswordHash(testHash))
+			err := authServ.ChangePassword(tt.email, "session", tt.password)
 
 			assert.Equal(t, tt.waitErr, err)
 		})
 	}
 }
+
+func TestAuthAppService_Refresh(t *testing.T) {
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage())
+	cred := auth.Credential{
+		Email:    "test@email.com",
+		Password: "testPassword",
+	}
+
+	tokens, err := authServ.Register(cred)
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.Access)
+	require.NotEmpty(t, tokens.Refresh)
+
+	renewed, err := authServ.Refresh(tokens.Refresh)
+	require.NoError(t, err)
+	require.NotEqual(t, tokens.Refresh, renewed.Refresh)
+
+	jwtToken, err := token.VerifyJWT(renewed.Access, "")
+	require.NoError(t, err)
+	assert.Equal(t, cred.Email, jwtToken.Claims.(*token.Token).Email)
+
+	// Сессия сохраняется при обмене refresh token
+	first, err := token.VerifyJWT(tokens.Access, "")
+	require.NoError(t, err)
+	assert.Equal(t, first.Claims.(*token.Token).Id, jwtToken.Claims.(*token.Token).Id)
+
+	// Refresh token одноразовый
+	_, err = authServ.Refresh(tokens.Refresh)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = authServ.Refresh("unknown")
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestAuthAppService_RefreshExpired(t *testing.T) {
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage(), WithTokenTTL(0, time.Nanosecond))
+
+	tokens, err := authServ.Register(auth.Credential{Email: "test@email.com", Password: "testPassword"})
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+
+	_, err = authServ.Refresh(tokens.Refresh)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestAuthAppService_Sessions(t *testing.T) {
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage())
+	cred := auth.Credential{
+		Email:    "test@email.com",
+		Password: "testPassword",
+	}
+
+	sessionOf := func(tokens auth.Tokens) string {
+		jwtToken, err := token.VerifyJWT(tokens.Access, "")
+		require.NoError(t, err)
+		return jwtToken.Claims.(*token.Token).Id
+	}
+
+	first, err := authServ.Register(cred)
+	require.NoError(t, err)
+	second, err := authServ.Login(cred)
+	require.NoError(t, err)
+	third, err := authServ.Login(cred)
+	require.NoError(t, err)
+
+	sessions, err := authServ.ListSessions(cred.Email)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	// Закрытие сессии другого пользователя
+	assert.ErrorIs(t, authServ.RevokeSession("other@email.com", sessionOf(first)), errs.ErrNotFound)
+
+	require.NoError(t, authServ.RevokeSession(cred.Email, sessionOf(first)))
+	_, err = authServ.Refresh(first.Refresh)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	require.NoError(t, authServ.Logout(cred.Email, sessionOf(second)))
+	assert.ErrorIs(t, authServ.Logout(cred.Email, sessionOf(second)), ErrUnauthenticated)
+
+	// Смена пароля закрывает все сессии, кроме текущей
+	_, err = authServ.Login(cred)
+	require.NoError(t, err)
+	require.NoError(t, authServ.ChangePassword(cred.Email, sessionOf(third), "newPassword"))
+
+	sessions, err = authServ.ListSessions(cred.Email)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, sessionOf(third), sessions[0].ID)
+}
+
+func TestAuthAppService_VaultKey(t *testing.T) {
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage())
+	email := "test@email.com"
+
+	_, err := authServ.GetVaultKey(email)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	assert.ErrorIs(t, authServ.SetVaultKey(email, nil), errs.ErrInvalidArgument)
+
+	require.NoError(t, authServ.SetVaultKey(email, []byte("sealed")))
+	assert.ErrorIs(t, authServ.SetVaultKey(email, []byte("other")), errs.ErrAlreadyExist)
+
+	sealed, err := authServ.GetVaultKey(email)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("sealed"), sealed)
+}
+
+func TestAuthAppService_DeleteAccount(t *testing.T) {
+
+	keys := key_store.NewMemoryStorage()
+	texts := text_store.NewMemoryStorage()
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage(),
+		WithPasswordHash(testHash),
+		WithKeyStore(keys),
+		WithAccountData(keys, texts),
+	)
+	cred := auth.Credential{
+		Email:    "test@email.com",
+		Password: "testPassword",
+	}
+
+	tokens, err := authServ.Register(cred)
+	require.NoError(t, err)
+	require.NoError(t, authServ.SetVaultKey(cred.Email, []byte("sealed")))
+	require.NoError(t, texts.Create(cred.Email, text.DataTextFull{MetaInfo: "note", Text: "secret"}))
+
+	// Учетная запись удаляется только с текущим паролем
+	assert.ErrorIs(t, authServ.DeleteAccount(cred.Email, "passwordTest"), ErrInvalidPassword)
+	assert.ErrorIs(t, authServ.DeleteAccount("other@email.com", cred.Password), ErrUnauthenticated)
+
+	require.NoError(t, authServ.DeleteAccount(cred.Email, cred.Password))
+
+	_, err = authServ.Login(cred)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = authServ.Refresh(tokens.Refresh)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = authServ.GetVaultKey(cred.Email)
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = texts.Get(cred.Email, text.DataTextGet{MetaInfo: "note"})
+	assert.ErrorIs(t, err, errs.ErrNotFound)
+
+	// После удаления email можно зарегистрировать заново
+	_, err = authServ.Register(cred)
+	require.NoError(t, err)
+}
+
+func TestAuthAppService_TwoFactor(t *testing.T) {
+
+	authServ := NewAuthService(auth_store.NewMemoryStorage(), WithSecretKey("secret"))
+	cred := auth.Credential{
+		Email:    "test@email.com",
+		Password: "testPassword",
+	}
+
+	_, err := authServ.Register(cred)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, authServ.Confirm2FA(cred.Email, "123456"), errs.ErrNotFound)
+
+	uri, recovery, err := authServ.Enroll2FA(cred.Email)
+	require.NoError(t, err)
+	require.Len(t, recovery, totp.RecoveryCodes)
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(parsed.Query().Get("secret"))
+	require.NoError(t, err)
+
+	// До подтверждения второй фактор не запрашивается
+	tokens, err := authServ.Login(cred)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.Access)
+	assert.Empty(t, tokens.Challenge)
+
+	step := totp.Step(time.Now())
+
+	assert.ErrorIs(t, authServ.Confirm2FA(cred.Email, "abc"), ErrInvalidOTP)
+	assert.ErrorIs(t, authServ.Confirm2FA(cred.Email, recovery[0]), ErrInvalidOTP)
+	require.NoError(t, authServ.Confirm2FA(cred.Email, totp.Code(secret, step)))
+
+	_, _, err = authServ.Enroll2FA(cred.Email)
+	assert.ErrorIs(t, err, errs.ErrAlreadyExist)
+
+	tokens, err = authServ.Login(cred)
+	require.NoError(t, err)
+	assert.Empty(t, tokens.Access)
+	require.NotEmpty(t, tokens.Challenge)
+
+	_, err = authServ.VerifyOTP("unknown", totp.Code(secret, step+1))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	// Принятый код повторно не принимается
+	_, err = authServ.VerifyOTP(tokens.Challenge, totp.Code(secret, step))
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+
+	verified, err := authServ.VerifyOTP(tokens.Challenge, totp.Code(secret, step+1))
+	require.NoError(t, err)
+	assert.NotEmpty(t, verified.Access)
+	assert.NotEmpty(t, verified.Refresh)
+
+	_, err = authServ.VerifyOTP(tokens.Challenge, recovery[0])
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	// Код восстановления одноразовый
+	tokens, err = authServ.Login(cred)
+	require.NoError(t, err)
+	_, err = authServ.VerifyOTP(tokens.Challenge, strings.ToUpper(recovery[0]))
+	require.NoError(t, err)
+
+	tokens, err = authServ.Login(cred)
+	require.NoError(t, err)
+	_, err = authServ.VerifyOTP(tokens.Challenge, recovery[0])
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+
+	// После challengeAttempts неудачных попыток вход отменяется
+	for i := 1; i < challengeAttempts; i++ {
+		_, err = authServ.VerifyOTP(tokens.Challenge, "abc")
+		assert.ErrorIs(t, err, ErrInvalidOTP)
+	}
+	_, err = authServ.VerifyOTP(tokens.Challenge, recovery[1])
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	assert.ErrorIs(t, authServ.Disable2FA(cred.Email, "abc"), ErrInvalidOTP)
+	require.NoError(t, authServ.Disable2FA(cred.Email, recovery[1]))
+	assert.ErrorIs(t, authServ.Disable2FA(cred.Email, recovery[2]), errs.ErrNotFound)
+
+	tokens, err = authServ.Login(cred)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.Access)
+	assert.Empty(t, tokens.Challenge)
+}
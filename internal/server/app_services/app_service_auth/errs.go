@@ -15,8 +15,10 @@ func (es ErrAppServices) Error() string {
 }
 
 var (
-	ErrInvalidEmail    = NewErr("invalid email")
-	ErrInvalidPassword = NewErr("invalid password")
-	ErrShortPassword   = NewErr("password must contain 6 or more characters")
-	ErrUnauthenticated = NewErr("unauthenticated")
+	ErrInvalidEmail      = NewErr("invalid email")
+	ErrInvalidPassword   = NewErr("invalid password")
+	ErrShortPassword     = NewErr("password must contain 6 or more characters")
+	ErrUnauthenticated   = NewErr("unauthenticated")
+	ErrInvalidOTP        = NewErr("invalid one-time code")
+	ErrInvalidCredential = NewErr("invalid email or password")
 )
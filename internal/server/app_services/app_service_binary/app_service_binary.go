@@ -1,10 +1,16 @@
 package app_service_binary
 
 import (
+	"io"
+
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/internal/storage/binary_store"
+	"GophKeeper/pkg/errs"
 )
 
 type BinaryAppService struct {
@@ -19,18 +25,106 @@ func NewBinaryAppService(store binary_store.BinaryStorage) *BinaryAppService {
 	}
 }
 
-func (serv BinaryAppService) Create(in binary.DataFull) error {
-	return serv.store.Create(in)
+func (serv BinaryAppService) Create(email string, in binary.DataFull) error {
+	return serv.store.Create(email, in)
+}
+
+func (serv BinaryAppService) Get(email string, in binary.DataGet) (binary.DataFull, error) {
+	return serv.store.Get(email, in)
+}
+
+// Delete - Перемещение данных в корзину. Ожидаемая версия in.Version обязательна.
+func (serv BinaryAppService) Delete(email string, in binary.DataGet) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Delete(email, in)
+}
+
+// Change - Изменение данных. Ожидаемая версия in.Version обязательна.
+func (serv BinaryAppService) Change(email string, in binary.DataFull) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Change(email, in)
+}
+
+// ListRevisions - Прежние версии данных meta пользователя email, от новых к старым.
+func (serv BinaryAppService) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	return serv.store.ListRevisions(email, meta)
+}
+
+// GetRevision - Прежняя версия данных пользователя email.
+func (serv BinaryAppService) GetRevision(email string, in revision.Request) (binary.DataFull, error) {
+	return serv.store.GetRevision(email, in)
+}
+
+// Restore - Восстановление прежней версии in.Version как новой версии данных.
+// Текущие данные при этом сохраняются в истории.
+// Ожидаемая текущая версия in.Current обязательна.
+func (serv BinaryAppService) Restore(email string, in revision.Request) error {
+
+	if in.Current == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	data, err := serv.store.GetRevision(email, in)
+	if err != nil {
+		return err
+	}
+
+	data.Version = in.Current
+	return serv.store.Change(email, data)
+}
+
+// ListTrash - Удаленные данные пользователя email.
+func (serv BinaryAppService) ListTrash(email string) ([]trash.Item, error) {
+	return serv.store.ListTrash(email)
 }
 
-func (serv BinaryAppService) Get(in binary.DataGet) (binary.DataFull, error) {
-	return serv.store.Get(in)
+// Undelete - Восстановление данных meta из корзины.
+func (serv BinaryAppService) Undelete(email, meta string) error {
+	return serv.store.Undelete(email, meta)
 }
 
-func (serv BinaryAppService) Delete(in binary.DataGet) error {
-	return serv.store.Delete(in)
+// Purge - Окончательное удаление данных meta из корзины.
+func (serv BinaryAppService) Purge(email, meta string) error {
+	return serv.store.Purge(email, meta)
+}
+
+// List - Получение страницы списка метаинформации данных пользователя email.
+func (serv BinaryAppService) List(email string, in page.Request) (page.Page, error) {
+
+	in = in.Normalize()
+
+	// Запрашивается на одну запись больше, чтобы понять, есть ли следующая страница
+	query := in
+	query.Limit++
+
+	items, err := serv.store.List(email, query)
+	if err != nil {
+		return page.Page{}, err
+	}
+
+	return page.New(in, items), nil
+}
+
+// Upload - Потоковая запись данных пользователя email из r.
+// При замене ожидаемая версия in.Version обязательна.
+func (serv BinaryAppService) Upload(email string, in binary.DataUpload, r io.Reader) error {
+
+	if in.Overwrite && in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Upload(email, in, r)
 }
 
-func (serv BinaryAppService) Change(in binary.DataFull) error {
-	return serv.store.Change(in)
+// Download - Потоковое чтение данных пользователя email в w.
+func (serv BinaryAppService) Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error) {
+	return serv.store.Download(email, in, w)
 }
@@ -4,14 +4,25 @@ import (
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/internal/storage/card_store"
+	"GophKeeper/pkg/errs"
 )
 
 type CardApp interface {
-	Create(data card.DataCardFull) error
-	Get(in card.DataCardGet) (card.DataCardFull, error)
-	Delete(in card.DataCardGet) error
-	Change(in card.DataCardFull) error
+	Create(email string, data card.DataCardFull) error
+	Get(email string, in card.DataCardGet) (card.DataCardFull, error)
+	Delete(email string, in card.DataCardGet) error
+	Change(email string, in card.DataCardFull) error
+	List(email string, in page.Request) (page.Page, error)
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	GetRevision(email string, in revision.Request) (card.DataCardFull, error)
+	Restore(email string, in revision.Request) error
+	ListTrash(email string) ([]trash.Item, error)
+	Undelete(email, meta string) error
+	Purge(email, meta string) error
 }
 
 type CardAppService struct {
@@ -26,21 +37,92 @@ func NewCardAppService(store card_store.CardStorage) *CardAppService {
 	}
 }
 
-func (serv CardAppService) Create(in card.DataCardFull) error {
+func (serv CardAppService) Create(email string, in card.DataCardFull) error {
 
-	return serv.store.Create(in)
+	return serv.store.Create(email, in)
 }
 
-func (serv CardAppService) Get(in card.DataCardGet) (card.DataCardFull, error) {
+func (serv CardAppService) Get(email string, in card.DataCardGet) (card.DataCardFull, error) {
 
-	return serv.store.Get(in)
+	return serv.store.Get(email, in)
 }
 
-func (serv CardAppService) Delete(in card.DataCardGet) error {
-	return serv.store.Delete(in)
+// Delete - Перемещение данных в корзину. Ожидаемая версия in.Version обязательна.
+func (serv CardAppService) Delete(email string, in card.DataCardGet) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Delete(email, in)
+}
+
+// Change - Изменение данных. Ожидаемая версия in.Version обязательна.
+func (serv CardAppService) Change(email string, in card.DataCardFull) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Change(email, in)
 }
 
-func (serv CardAppService) Change(in card.DataCardFull) error {
+// ListRevisions - Прежние версии данных meta пользователя email, от новых к старым.
+func (serv CardAppService) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	return serv.store.ListRevisions(email, meta)
+}
+
+// GetRevision - Прежняя версия данных пользователя email.
+func (serv CardAppService) GetRevision(email string, in revision.Request) (card.DataCardFull, error) {
+	return serv.store.GetRevision(email, in)
+}
+
+// Restore - Восстановление прежней версии in.Version как новой версии данных.
+// Текущие данные при этом сохраняются в истории.
+// Ожидаемая текущая версия in.Current обязательна.
+func (serv CardAppService) Restore(email string, in revision.Request) error {
+
+	if in.Current == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	data, err := serv.store.GetRevision(email, in)
+	if err != nil {
+		return err
+	}
+
+	data.Version = in.Current
+	return serv.store.Change(email, data)
+}
+
+// ListTrash - Удаленные данные пользователя email.
+func (serv CardAppService) ListTrash(email string) ([]trash.Item, error) {
+	return serv.store.ListTrash(email)
+}
+
+// Undelete - Восстановление данных meta из корзины.
+func (serv CardAppService) Undelete(email, meta string) error {
+	return serv.store.Undelete(email, meta)
+}
+
+// Purge - Окончательное удаление данных meta из корзины.
+func (serv CardAppService) Purge(email, meta string) error {
+	return serv.store.Purge(email, meta)
+}
+
+// List - Получение страницы списка метаинформации данных пользователя email.
+func (serv CardAppService) List(email string, in page.Request) (page.Page, error) {
+
+	in = in.Normalize()
+
+	// Запрашивается на одну запись больше, чтобы понять, есть ли следующая страница
+	query := in
+	query.Limit++
+
+	items, err := serv.store.List(email, query)
+	if err != nil {
+		return page.Page{}, err
+	}
 
-	return serv.store.Change(in)
+	return page.New(in, items), nil
 }
@@ -4,7 +4,11 @@ import (
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/internal/storage/credential_store"
+	"GophKeeper/pkg/errs"
 )
 
 type CredentialAppService struct {
@@ -19,18 +23,90 @@ func NewCredentialAppService(store credential_store.CredStorage) *CredentialAppS
 	}
 }
 
-func (serv CredentialAppService) Create(in cred.CredentialFull) error {
-	return serv.store.Create(in)
+func (serv CredentialAppService) Create(email string, in cred.CredentialFull) error {
+	return serv.store.Create(email, in)
 }
 
-func (serv CredentialAppService) Get(in cred.CredentialGet) (cred.CredentialFull, error) {
-	return serv.store.Get(in)
+func (serv CredentialAppService) Get(email string, in cred.CredentialGet) (cred.CredentialFull, error) {
+	return serv.store.Get(email, in)
 }
 
-func (serv CredentialAppService) Delete(in cred.CredentialGet) error {
-	return serv.store.Delete(in)
+// Delete - Перемещение данных в корзину. Ожидаемая версия in.Version обязательна.
+func (serv CredentialAppService) Delete(email string, in cred.CredentialGet) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Delete(email, in)
+}
+
+// Change - Изменение данных. Ожидаемая версия in.Version обязательна.
+func (serv CredentialAppService) Change(email string, in cred.CredentialFull) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Change(email, in)
+}
+
+// ListRevisions - Прежние версии данных meta пользователя email, от новых к старым.
+func (serv CredentialAppService) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	return serv.store.ListRevisions(email, meta)
+}
+
+// GetRevision - Прежняя версия данных пользователя email.
+func (serv CredentialAppService) GetRevision(email string, in revision.Request) (cred.CredentialFull, error) {
+	return serv.store.GetRevision(email, in)
+}
+
+// Restore - Восстановление прежней версии in.Version как новой версии данных.
+// Текущие данные при этом сохраняются в истории.
+// Ожидаемая текущая версия in.Current обязательна.
+func (serv CredentialAppService) Restore(email string, in revision.Request) error {
+
+	if in.Current == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	data, err := serv.store.GetRevision(email, in)
+	if err != nil {
+		return err
+	}
+
+	data.Version = in.Current
+	return serv.store.Change(email, data)
+}
+
+// ListTrash - Удаленные данные пользователя email.
+func (serv CredentialAppService) ListTrash(email string) ([]trash.Item, error) {
+	return serv.store.ListTrash(email)
+}
+
+// Undelete - Восстановление данных meta из корзины.
+func (serv CredentialAppService) Undelete(email, meta string) error {
+	return serv.store.Undelete(email, meta)
 }
 
-func (serv CredentialAppService) Change(in cred.CredentialFull) error {
-	return serv.store.Change(in)
+// Purge - Окончательное удаление данных meta из корзины.
+func (serv CredentialAppService) Purge(email, meta string) error {
+	return serv.store.Purge(email, meta)
+}
+
+// List - Получение страницы списка метаинформации данных пользователя email.
+func (serv CredentialAppService) List(email string, in page.Request) (page.Page, error) {
+
+	in = in.Normalize()
+
+	// Запрашивается на одну запись больше, чтобы понять, есть ли следующая страница
+	query := in
+	query.Limit++
+
+	items, err := serv.store.List(email, query)
+	if err != nil {
+		return page.Page{}, err
+	}
+
+	return page.New(in, items), nil
 }
@@ -0,0 +1,359 @@
+package app_service_rotation
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/storage/binary_store"
+	"GophKeeper/internal/storage/card_store"
+	"GophKeeper/internal/storage/credential_store"
+	"GophKeeper/internal/storage/key_store"
+	"GophKeeper/internal/storage/text_store"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+// MaxBatch - Наибольшее количество записей в одном пакетном изменении.
+const MaxBatch = 100
+
+// RotationAppService - Смена ключа шифрования данных пользователя.
+//
+// Клиент начинает смену ключа, перешифровывает данные и записывает их
+// пакетами, затем завершает смену. Пока смена не завершена, она хранится
+// на сервере, поэтому прерванная смена обнаруживается и продолжается.
+type RotationAppService struct {
+	keys   key_store.KeyStorage
+	texts  text_store.TextStorage
+	creds  credential_store.CredStorage
+	cards  card_store.CardStorage
+	bins   binary_store.BinaryStorage
+	logger *zap.Logger
+}
+
+func NewRotationAppService(
+	keys key_store.KeyStorage,
+	texts text_store.TextStorage,
+	creds credential_store.CredStorage,
+	cards card_store.CardStorage,
+	bins binary_store.BinaryStorage) *RotationAppService {
+
+	return &RotationAppService{
+		keys:   keys,
+		texts:  texts,
+		creds:  creds,
+		cards:  cards,
+		bins:   bins,
+		logger: zap.L(),
+	}
+}
+
+// Begin - Начало смены ключа пользователя email на ключ in.KeyID.
+// Если смена на этот ключ уже начата, возвращается начатая смена,
+// если на другой ключ - errs.ErrConflict.
+func (serv RotationAppService) Begin(email string, in rotation.Rotation) (rotation.Rotation, error) {
+
+	if !validKeyID(in.KeyID) {
+		return rotation.Rotation{}, errs.ErrInvalidArgument
+	}
+
+	err := serv.keys.BeginRotation(email, in)
+	if err != nil && !errors.Is(err, errs.ErrAlreadyExist) {
+		return rotation.Rotation{}, err
+	}
+
+	current, err := serv.keys.GetRotation(email)
+	if err != nil {
+		return rotation.Rotation{}, err
+	}
+
+	if current.KeyID != in.KeyID {
+		return rotation.Rotation{}, errs.ErrConflict
+	}
+
+	return current, nil
+}
+
+// Get - Незавершенная смена ключа пользователя email.
+// errs.ErrNotFound, если смена ключа не начата.
+func (serv RotationAppService) Get(email string) (rotation.Rotation, error) {
+	return serv.keys.GetRotation(email)
+}
+
+// Finish - Завершение смены ключа keyID пользователя email.
+// Если какие-либо данные зашифрованы не ключом keyID, например записаны
+// другим устройством во время смены, возвращается errs.ErrConflict:
+// клиент должен продолжить смену и перешифровать их.
+func (serv RotationAppService) Finish(email, keyID string) error {
+
+	if err := serv.check(email, keyID); err != nil {
+		return err
+	}
+
+	if err := serv.verify(email, keyID); err != nil {
+		return err
+	}
+
+	return serv.keys.FinishRotation(email)
+}
+
+// UpdateTexts - Запись перешифрованных текстовых данных: все или ни одной.
+// Данные должны быть зашифрованы ключом keyID начатой смены ключа.
+func (serv RotationAppService) UpdateTexts(email, keyID string, in []text.DataTextFull) error {
+
+	records := make([]record, 0, len(in))
+	for _, data := range in {
+		records = append(records, record{meta: data.MetaInfo, version: data.Version, payloads: [][]byte{[]byte(data.Text)}})
+	}
+
+	if err := serv.validate(email, keyID, records); err != nil {
+		return err
+	}
+
+	return serv.texts.ChangeBatch(email, in)
+}
+
+// UpdateCreds - Запись перешифрованных логинов и паролей: все или ни одной.
+func (serv RotationAppService) UpdateCreds(email, keyID string, in []cred.CredentialFull) error {
+
+	records := make([]record, 0, len(in))
+	for _, data := range in {
+		records = append(records, record{meta: data.MetaInfo, version: data.Version, payloads: [][]byte{[]byte(data.Email), []byte(data.Password)}})
+	}
+
+	if err := serv.validate(email, keyID, records); err != nil {
+		return err
+	}
+
+	return serv.creds.ChangeBatch(email, in)
+}
+
+// UpdateCards - Запись перешифрованных данных карт: все или ни одной.
+func (serv RotationAppService) UpdateCards(email, keyID string, in []card.DataCardFull) error {
+
+	records := make([]record, 0, len(in))
+	for _, data := range in {
+		records = append(records, record{meta: data.MetaInfo, version: data.Version, payloads: [][]byte{
+			[]byte(data.Number), []byte(data.Period), []byte(data.CVV), []byte(data.FullName),
+		}})
+	}
+
+	if err := serv.validate(email, keyID, records); err != nil {
+		return err
+	}
+
+	return serv.cards.ChangeBatch(email, in)
+}
+
+// UpdateBinaries - Запись перешифрованных бинарных данных: все или ни одной.
+func (serv RotationAppService) UpdateBinaries(email, keyID string, in []binary.DataFull) error {
+
+	records := make([]record, 0, len(in))
+	for _, data := range in {
+		records = append(records, record{meta: data.MetaInfo, version: data.Version, payloads: [][]byte{data.Bytes}})
+	}
+
+	if err := serv.validate(email, keyID, records); err != nil {
+		return err
+	}
+
+	return serv.bins.ChangeBatch(email, in)
+}
+
+// UpdateBinary - Замена бинарных данных, которые не помещаются в пакет,
+// перешифрованными данными, читаемыми из r до io.EOF. Заголовок конверта
+// проверяется до записи: данные должны быть зашифрованы ключом keyID.
+// Ожидаемая версия in.Version обязательна.
+func (serv RotationAppService) UpdateBinary(email, keyID string, in binary.DataUpload, r io.Reader) error {
+
+	if err := serv.check(email, keyID); err != nil {
+		return err
+	}
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	header, raw, err := secret.ReadHeader(r)
+	if errors.Is(err, secret.ErrMalformed) || errors.Is(err, secret.ErrUnsupported) {
+		return errs.ErrInvalidArgument
+	}
+	if err != nil {
+		return err
+	}
+
+	if id, _ := hex.DecodeString(keyID); !bytes.Equal(header.KeyID, id) {
+		return errs.ErrInvalidArgument
+	}
+
+	in.Overwrite = true
+	return serv.bins.Upload(email, in, io.MultiReader(bytes.NewReader(raw), r))
+}
+
+// record - Метаинформация и зашифрованные поля записи пакета.
+type record struct {
+	meta string
+	// version - Ожидаемая текущая версия записи, обязательна
+	version  int64
+	payloads [][]byte
+}
+
+// validate - Проверка пакета: смена ключа keyID начата, метаинформация
+// не повторяется, ожидаемые версии указаны, а все поля зашифрованы ключом keyID.
+func (serv RotationAppService) validate(email, keyID string, records []record) error {
+
+	if len(records) > MaxBatch {
+		return errs.ErrInvalidArgument
+	}
+
+	if err := serv.check(email, keyID); err != nil {
+		return err
+	}
+
+	id, _ := hex.DecodeString(keyID)
+	metas := make(map[string]struct{}, len(records))
+
+	for _, r := range records {
+
+		if _, ok := metas[r.meta]; ok || r.version == 0 {
+			return errs.ErrInvalidArgument
+		}
+		metas[r.meta] = struct{}{}
+
+		if !encryptedWith(id, r.payloads...) {
+			return errs.ErrInvalidArgument
+		}
+	}
+
+	return nil
+}
+
+// verify - Проверка, что все данные пользователя email зашифрованы ключом keyID.
+// Записи, удаленные во время проверки, пропускаются.
+func (serv RotationAppService) verify(email, keyID string) error {
+
+	id, _ := hex.DecodeString(keyID)
+
+	kinds := []struct {
+		list func(email string, in page.Request) ([]page.Item, error)
+		// payloads - Зашифрованные поля записи meta.
+		payloads func(meta string) ([][]byte, error)
+	}{
+		{serv.texts.List, func(meta string) ([][]byte, error) {
+			data, err := serv.texts.Get(email, text.DataTextGet{MetaInfo: meta})
+			return [][]byte{[]byte(data.Text)}, err
+		}},
+		{serv.creds.List, func(meta string) ([][]byte, error) {
+			data, err := serv.creds.Get(email, cred.CredentialGet{MetaInfo: meta})
+			return [][]byte{[]byte(data.Email), []byte(data.Password)}, err
+		}},
+		{serv.cards.List, func(meta string) ([][]byte, error) {
+			data, err := serv.cards.Get(email, card.DataCardGet{MetaInfo: meta})
+			return [][]byte{[]byte(data.Number), []byte(data.Period), []byte(data.CVV), []byte(data.FullName)}, err
+		}},
+		{serv.bins.List, func(meta string) ([][]byte, error) {
+			head, err := serv.binaryHeader(email, meta)
+			return [][]byte{head}, err
+		}},
+	}
+
+	for _, k := range kinds {
+
+		req := page.Request{Limit: page.MaxLimit}
+		for {
+			items, err := k.list(email, req)
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				payloads, errGet := k.payloads(item.MetaInfo)
+				if errors.Is(errGet, errs.ErrNotFound) {
+					continue
+				}
+				if errGet != nil {
+					return errGet
+				}
+
+				if !encryptedWith(id, payloads...) {
+					return errs.ErrConflict
+				}
+			}
+
+			if len(items) < req.Limit {
+				break
+			}
+			req.After = items[len(items)-1].MetaInfo
+		}
+	}
+
+	return nil
+}
+
+// binaryHeader - Заголовок конверта бинарных данных meta.
+// Данные выгружаются из хранилища только до конца заголовка.
+func (serv RotationAppService) binaryHeader(email, meta string) ([]byte, error) {
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := serv.bins.Download(email, binary.DataGet{MetaInfo: meta}, pw)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	_, raw, err := secret.ReadHeader(pr)
+	// Закрытие прерывает выгрузку остальных данных
+	pr.Close()
+
+	if errDownload := <-done; errors.Is(errDownload, errs.ErrNotFound) {
+		return nil, errDownload
+	}
+
+	// Данные не в формате конверта зашифрованы не новым ключом
+	if errors.Is(err, secret.ErrMalformed) || errors.Is(err, secret.ErrUnsupported) {
+		return nil, nil
+	}
+
+	return raw, err
+}
+
+// encryptedWith - Все payloads - конверты, зашифрованные ключом с идентификатором id.
+func encryptedWith(id []byte, payloads ...[]byte) bool {
+
+	for _, payload := range payloads {
+		header, _, err := secret.ParseHeader(payload)
+		if err != nil || !bytes.Equal(header.KeyID, id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// check - Проверка, что начата смена ключа keyID, иначе errs.ErrNoRotation.
+func (serv RotationAppService) check(email, keyID string) error {
+
+	current, err := serv.keys.GetRotation(email)
+	if errors.Is(err, errs.ErrNotFound) || (err == nil && current.KeyID != keyID) {
+		return errs.ErrNoRotation
+	}
+
+	return err
+}
+
+// validKeyID - Идентификатор ключа в hex.
+func validKeyID(keyID string) bool {
+	id, err := hex.DecodeString(keyID)
+	return err == nil && len(id) == secret.KeyIDSize
+}
@@ -0,0 +1,157 @@
+package app_service_rotation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/storage/binary_store"
+	"GophKeeper/internal/storage/card_store"
+	"GophKeeper/internal/storage/credential_store"
+	"GophKeeper/internal/storage/key_store"
+	"GophKeeper/internal/storage/text_store"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/secret"
+)
+
+func TestRotationAppService(t *testing.T) {
+
+	keys := key_store.NewMemoryStorage()
+	texts := text_store.NewMemoryStorage()
+	creds := credential_store.NewMemoryStorage()
+	cards := card_store.NewMemoryStorage()
+	bins := binary_store.NewMemoryStorage()
+	serv := NewRotationAppService(keys, texts, creds, cards, bins)
+	email := "test@email.com"
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encrypt := func(key *rsa.PrivateKey, data string) string {
+		sealed, errEnc := secret.Encrypt(&key.PublicKey, []byte(data))
+		require.NoError(t, errEnc)
+		return string(sealed)
+	}
+
+	keyID := hex.EncodeToString(secret.KeyID(&newKey.PublicKey))
+
+	require.NoError(t, keys.Create(email, []byte("old sealed key")))
+	require.NoError(t, texts.Create(email, text.DataTextFull{MetaInfo: "note", Text: encrypt(oldKey, "text")}))
+	require.NoError(t, creds.Create(email, cred.CredentialFull{MetaInfo: "site", Email: encrypt(oldKey, "login"), Password: encrypt(oldKey, "pwd")}))
+	require.NoError(t, bins.Create(email, binary.DataFull{MetaInfo: "small", Bytes: []byte(encrypt(oldKey, "small"))}))
+	require.NoError(t, bins.Create(email, binary.DataFull{MetaInfo: "large", Bytes: []byte(encrypt(oldKey, "large"))}))
+
+	// Без начатой смены ключа пакет не принимается
+	err = serv.UpdateTexts(email, keyID, []text.DataTextFull{{MetaInfo: "note", Text: encrypt(newKey, "text"), Version: 1}})
+	require.ErrorIs(t, err, errs.ErrNoRotation)
+
+	_, err = serv.Begin(email, rotation.Rotation{KeyID: "bad"})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+
+	started, err := serv.Begin(email, rotation.Rotation{KeyID: keyID, SealedKey: []byte("new sealed key")})
+	require.NoError(t, err)
+	require.Equal(t, keyID, started.KeyID)
+
+	// Повторное начало продолжает прерванную смену
+	resumed, err := serv.Begin(email, rotation.Rotation{KeyID: keyID})
+	require.NoError(t, err)
+	require.Equal(t, started, resumed)
+
+	// Смена на другой ключ не начинается, пока не завершена текущая
+	_, err = serv.Begin(email, rotation.Rotation{KeyID: hex.EncodeToString(secret.KeyID(&oldKey.PublicKey))})
+	require.ErrorIs(t, err, errs.ErrConflict)
+
+	current, err := serv.Get(email)
+	require.NoError(t, err)
+	require.Equal(t, keyID, current.KeyID)
+
+	// Данные, зашифрованные не новым ключом, не принимаются
+	err = serv.UpdateTexts(email, keyID, []text.DataTextFull{{MetaInfo: "note", Text: encrypt(oldKey, "text"), Version: 1}})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+	err = serv.UpdateTexts(email, keyID, []text.DataTextFull{{MetaInfo: "note", Text: "plain", Version: 1}})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+
+	// Повтор метаинформации в пакете
+	err = serv.UpdateCreds(email, keyID, []cred.CredentialFull{
+		{MetaInfo: "site", Email: encrypt(newKey, "login"), Password: encrypt(newKey, "pwd"), Version: 1},
+		{MetaInfo: "site", Email: encrypt(newKey, "login"), Password: encrypt(newKey, "pwd"), Version: 1},
+	})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+
+	// Изменение без ожидаемой версии
+	err = serv.UpdateTexts(email, keyID, []text.DataTextFull{{MetaInfo: "note", Text: encrypt(newKey, "text")}})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+
+	// Изменение с устаревшей версией
+	err = serv.UpdateTexts(email, keyID, []text.DataTextFull{{MetaInfo: "note", Text: encrypt(newKey, "text"), Version: 5}})
+	require.ErrorIs(t, err, errs.ErrConflict)
+
+	require.NoError(t, serv.UpdateTexts(email, keyID, []text.DataTextFull{{MetaInfo: "note", Text: encrypt(newKey, "text"), Version: 1}}))
+	require.NoError(t, serv.UpdateCreds(email, keyID, []cred.CredentialFull{
+		{MetaInfo: "site", Email: encrypt(newKey, "login"), Password: encrypt(newKey, "pwd"), Version: 1},
+	}))
+	require.NoError(t, serv.UpdateCards(email, keyID, nil))
+
+	data, err := texts.Get(email, text.DataTextGet{MetaInfo: "note"})
+	require.NoError(t, err)
+	plain, err := secret.Decrypt(newKey, []byte(data.Text))
+	require.NoError(t, err)
+	require.Equal(t, "text", string(plain))
+
+	require.ErrorIs(t, serv.Finish(email, "0000000000000000"), errs.ErrNoRotation)
+
+	// Бинарные данные еще зашифрованы прежним ключом - смена не завершается
+	require.ErrorIs(t, serv.Finish(email, keyID), errs.ErrConflict)
+
+	err = serv.UpdateBinaries(email, keyID, []binary.DataFull{{MetaInfo: "small", Bytes: []byte(encrypt(oldKey, "small")), Version: 1}})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+	require.NoError(t, serv.UpdateBinaries(email, keyID, []binary.DataFull{
+		{MetaInfo: "small", Bytes: []byte(encrypt(newKey, "small")), Version: 1},
+	}))
+
+	// Поток проверяется по заголовку до записи
+	upload := binary.DataUpload{MetaInfo: "large", Version: 1}
+	err = serv.UpdateBinary(email, keyID, upload, bytes.NewReader([]byte(encrypt(oldKey, "large"))))
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+	err = serv.UpdateBinary(email, keyID, upload, bytes.NewReader([]byte("plain")))
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+	err = serv.UpdateBinary(email, keyID, binary.DataUpload{MetaInfo: "large", Version: 5}, bytes.NewReader([]byte(encrypt(newKey, "large"))))
+	require.ErrorIs(t, err, errs.ErrConflict)
+	err = serv.UpdateBinary(email, keyID, binary.DataUpload{MetaInfo: "large"}, bytes.NewReader([]byte(encrypt(newKey, "large"))))
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+
+	require.ErrorIs(t, serv.Finish(email, keyID), errs.ErrConflict)
+
+	require.NoError(t, serv.UpdateBinary(email, keyID, upload, bytes.NewReader([]byte(encrypt(newKey, "large")))))
+
+	large, err := bins.Get(email, binary.DataGet{MetaInfo: "large"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), large.Version)
+	plain, err = secret.Decrypt(newKey, large.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, "large", string(plain))
+
+	require.NoError(t, serv.Finish(email, keyID))
+
+	_, err = serv.Get(email)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	sealed, err := keys.Get(email)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new sealed key"), sealed)
+
+	// После завершения пакеты не принимаются
+	err = serv.UpdateCards(email, keyID, []card.DataCardFull{{MetaInfo: "card"}})
+	require.ErrorIs(t, err, errs.ErrNoRotation)
+}
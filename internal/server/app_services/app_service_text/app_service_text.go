@@ -3,8 +3,12 @@ package app_service_text
 import (
 	"go.uber.org/zap"
 
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/internal/storage/text_store"
+	"GophKeeper/pkg/errs"
 )
 
 type TextAppService struct {
@@ -19,18 +23,90 @@ func NewTextAppService(store text_store.TextStorage) *TextAppService {
 	}
 }
 
-func (serv TextAppService) Create(in text.DataTextFull) error {
-	return serv.store.Create(in)
+func (serv TextAppService) Create(email string, in text.DataTextFull) error {
+	return serv.store.Create(email, in)
 }
 
-func (serv TextAppService) Get(in text.DataTextGet) (text.DataTextFull, error) {
-	return serv.store.Get(in)
+func (serv TextAppService) Get(email string, in text.DataTextGet) (text.DataTextFull, error) {
+	return serv.store.Get(email, in)
 }
 
-func (serv TextAppService) Delete(in text.DataTextGet) error {
-	return serv.store.Delete(in)
+// Delete - Перемещение данных в корзину. Ожидаемая версия in.Version обязательна.
+func (serv TextAppService) Delete(email string, in text.DataTextGet) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Delete(email, in)
+}
+
+// Change - Изменение данных. Ожидаемая версия in.Version обязательна.
+func (serv TextAppService) Change(email string, in text.DataTextFull) error {
+
+	if in.Version == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	return serv.store.Change(email, in)
+}
+
+// ListRevisions - Прежние версии данных meta пользователя email, от новых к старым.
+func (serv TextAppService) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	return serv.store.ListRevisions(email, meta)
+}
+
+// GetRevision - Прежняя версия данных пользователя email.
+func (serv TextAppService) GetRevision(email string, in revision.Request) (text.DataTextFull, error) {
+	return serv.store.GetRevision(email, in)
+}
+
+// Restore - Восстановление прежней версии in.Version как новой версии данных.
+// Текущие данные при этом сохраняются в истории.
+// Ожидаемая текущая версия in.Current обязательна.
+func (serv TextAppService) Restore(email string, in revision.Request) error {
+
+	if in.Current == 0 {
+		return errs.ErrInvalidArgument
+	}
+
+	data, err := serv.store.GetRevision(email, in)
+	if err != nil {
+		return err
+	}
+
+	data.Version = in.Current
+	return serv.store.Change(email, data)
+}
+
+// ListTrash - Удаленные данные пользователя email.
+func (serv TextAppService) ListTrash(email string) ([]trash.Item, error) {
+	return serv.store.ListTrash(email)
+}
+
+// Undelete - Восстановление данных meta из корзины.
+func (serv TextAppService) Undelete(email, meta string) error {
+	return serv.store.Undelete(email, meta)
 }
 
-func (serv TextAppService) Change(in text.DataTextFull) error {
-	return serv.store.Change(in)
+// Purge - Окончательное удаление данных meta из корзины.
+func (serv TextAppService) Purge(email, meta string) error {
+	return serv.store.Purge(email, meta)
+}
+
+// List - Получение страницы списка метаинформации данных пользователя email.
+func (serv TextAppService) List(email string, in page.Request) (page.Page, error) {
+
+	in = in.Normalize()
+
+	// Запрашивается на одну запись больше, чтобы понять, есть ли следующая страница
+	query := in
+	query.Limit++
+
+	items, err := serv.store.List(email, query)
+	if err != nil {
+		return page.Page{}, err
+	}
+
+	return page.New(in, items), nil
 }
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
 	"GophKeeper/internal/storage/text_store"
 	"GophKeeper/pkg/errs"
@@ -14,6 +16,7 @@ func TestTextAppService(t *testing.T) {
 
 	store := text_store.NewMemoryStorage()
 	serv := NewTextAppService(store)
+	email := "test@email.com"
 
 	testDataOK := text.DataTextFull{
 		MetaInfo: "note_private",
@@ -33,35 +36,155 @@ func TestTextAppService(t *testing.T) {
 		MetaInfo: "note_private_1",
 	}
 
-	errCreate := serv.Create(testDataOK)
+	errCreate := serv.Create(email, testDataOK)
 	require.NoError(t, errCreate)
 
-	data, errGet := serv.Get(testDataGet)
+	data, errGet := serv.Get(email, testDataGet)
 	require.NoError(t, errGet)
-	require.Equal(t, data, testDataOK)
+	require.Equal(t, int64(1), data.Version)
+	testDataOK.Version, testDataOK.UpdatedAt = data.Version, data.UpdatedAt
+	require.Equal(t, testDataOK, data)
 
-	_, errGet = serv.Get(testDataFail)
+	_, errGet = serv.Get(email, testDataFail)
 	require.Error(t, errGet, errs.ErrNotFound)
 
-	errChange := serv.Change(testDataChange)
+	// Изменение без ожидаемой версии отклоняется
+	errChange := serv.Change(email, testDataChange)
+	require.ErrorIs(t, errChange, errs.ErrInvalidArgument)
+
+	testDataChange.Version = 1
+	errChange = serv.Change(email, testDataChange)
 	require.NoError(t, errChange)
 
-	data, errGet = serv.Get(testDataGet)
+	data, errGet = serv.Get(email, testDataGet)
 	require.NoError(t, errGet)
-	require.Equal(t, data, testDataChange)
+	require.Equal(t, int64(2), data.Version)
+	testDataChange.Version, testDataChange.UpdatedAt = data.Version, data.UpdatedAt
+	require.Equal(t, testDataChange, data)
+
+	// Изменение с устаревшей версией отклоняется
+	testDataChange.Version = 1
+	errChange = serv.Change(email, testDataChange)
+	require.ErrorIs(t, errChange, errs.ErrConflict)
+
+	// Удаление без ожидаемой версии отклоняется
+	errDel := serv.Delete(email, testDataGet)
+	require.ErrorIs(t, errDel, errs.ErrInvalidArgument)
 
-	errDel := serv.Delete(testDataGet)
+	testDataGet.Version = 2
+	errDel = serv.Delete(email, testDataGet)
 	require.NoError(t, errDel)
 
-	_, errGet = serv.Get(testDataGet)
+	_, errGet = serv.Get(email, testDataGet)
 	require.Error(t, errGet, errs.ErrNotFound)
 
-	errChange = serv.Change(testDataChange)
+	errChange = serv.Change(email, testDataChange)
 	require.Error(t, errGet, errs.ErrNotFound)
 
-	errCreate = serv.Create(testDataOK)
+	errCreate = serv.Create(email, testDataOK)
 	require.NoError(t, errCreate)
 
-	errCreate = serv.Create(testDataOK)
+	errCreate = serv.Create(email, testDataOK)
 	require.Error(t, errCreate, errs.ErrAlreadyExist)
 }
+
+func TestTextAppService_List(t *testing.T) {
+
+	store := text_store.NewMemoryStorage()
+	serv := NewTextAppService(store)
+	email := "test@email.com"
+
+	for _, meta := range []string{"a", "b", "c", "d", "e"} {
+		errCreate := serv.Create(email, text.DataTextFull{MetaInfo: meta})
+		require.NoError(t, errCreate)
+	}
+
+	var metas []string
+	var pages int
+
+	req := page.Request{Limit: 2}
+	for {
+		list, err := serv.List(email, req)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(list.MetaInfo), req.Limit)
+		require.Len(t, list.Versions, len(list.MetaInfo))
+
+		metas = append(metas, list.MetaInfo...)
+		pages++
+
+		if len(list.NextPageToken) == 0 {
+			break
+		}
+
+		req.After = list.NextPageToken
+	}
+
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, metas)
+	require.Equal(t, 3, pages)
+}
+
+func TestTextAppService_Restore(t *testing.T) {
+
+	store := text_store.NewMemoryStorage()
+	serv := NewTextAppService(store)
+	email := "test@email.com"
+	meta := "restore"
+
+	first := text.DataTextFull{MetaInfo: meta, Text: "qwerty"}
+	require.NoError(t, serv.Create(email, first))
+	require.NoError(t, serv.Change(email, text.DataTextFull{MetaInfo: meta, Version: 1, Text: "qwerty123"}))
+
+	// Восстановление без ожидаемой текущей версии отклоняется
+	err := serv.Restore(email, revision.Request{MetaInfo: meta, Version: 1})
+	require.ErrorIs(t, err, errs.ErrInvalidArgument)
+
+	// Восстановление с устаревшей текущей версией отклоняется
+	err = serv.Restore(email, revision.Request{MetaInfo: meta, Version: 1, Current: 1})
+	require.ErrorIs(t, err, errs.ErrConflict)
+
+	err = serv.Restore(email, revision.Request{MetaInfo: meta, Version: 5, Current: 2})
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Прежняя версия становится новой версией данных
+	require.NoError(t, serv.Restore(email, revision.Request{MetaInfo: meta, Version: 1, Current: 2}))
+
+	data, err := serv.Get(email, text.DataTextGet{MetaInfo: meta})
+	require.NoError(t, err)
+	require.Equal(t, first.Text, data.Text)
+	require.Equal(t, int64(3), data.Version)
+
+	// Замененная версия сохраняется в истории
+	revs, err := serv.ListRevisions(email, meta)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	require.Equal(t, int64(2), revs[0].Version)
+}
+
+func TestTextAppService_Trash(t *testing.T) {
+
+	store := text_store.NewMemoryStorage()
+	serv := NewTextAppService(store)
+	email := "test@email.com"
+	meta := "trash"
+
+	require.NoError(t, serv.Create(email, text.DataTextFull{MetaInfo: meta, Text: "qwerty"}))
+	require.NoError(t, serv.Delete(email, text.DataTextGet{MetaInfo: meta, Version: 1}))
+
+	items, err := serv.ListTrash(email)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, meta, items[0].MetaInfo)
+
+	require.NoError(t, serv.Undelete(email, meta))
+
+	_, err = serv.Get(email, text.DataTextGet{MetaInfo: meta})
+	require.NoError(t, err)
+
+	require.NoError(t, serv.Delete(email, text.DataTextGet{MetaInfo: meta, Version: 1}))
+	require.NoError(t, serv.Purge(email, meta))
+	require.ErrorIs(t, serv.Undelete(email, meta), errs.ErrNotFound)
+
+	items, err = serv.ListTrash(email)
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
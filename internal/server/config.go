@@ -4,49 +4,200 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
+	"GophKeeper/pkg/config"
+	"GophKeeper/pkg/passhash"
+	"GophKeeper/pkg/token"
 )
 
+// minSecretKeySize - Минимальная длина ключа подписи токенов HS256 (RFC 7518, 3.2).
+const minSecretKeySize = 32
+
 type Config struct {
-	AddrGRPC    string `env:"ADDRESS_RPC" json:"address_rpc"`
-	SecretKey   string `env:"SECRET_KEY"  json:"secret_key"`
-	DatabaseURI string `env:"DatabaseURI" json:"database_uri"`
+	AddrGRPC  string `env:"ADDRESS_RPC" json:"address_rpc"`
+	SecretKey string `env:"SECRET_KEY"  json:"secret_key"`
+	// DatabaseURI - Строка подключения к БД. Прежнее имя переменной
+	// окружения DatabaseURI читается, если DATABASE_URI не задана
+	DatabaseURI string `env:"DATABASE_URI,DatabaseURI" json:"database_uri"`
+	// RevisionRetention - Количество хранимых прежних версий каждой записи
+	RevisionRetention int `env:"REVISION_RETENTION" json:"revision_retention"`
+	// TrashRetention - Время хранения удаленных данных в корзине
+	TrashRetention time.Duration `env:"TRASH_RETENTION" json:"trash_retention"`
+	// AccessTokenTTL - Время жизни токена доступа
+	AccessTokenTTL time.Duration `env:"ACCESS_TOKEN_TTL" json:"access_token_ttl"`
+	// RefreshTokenTTL - Время жизни refresh token
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" json:"refresh_token_ttl"`
+	// RejectPlaintext - Отклонять данные, которые клиент отправляет без шифрования
+	RejectPlaintext bool `env:"REJECT_PLAINTEXT" json:"reject_plaintext"`
+	// PasswordHash - Алгоритм хэширования паролей: argon2id или bcrypt
+	PasswordHash string `env:"PASSWORD_HASH" json:"password_hash"`
+	// LoginMaxFailures - Количество неудачных попыток входа, после которого
+	// IP клиента и учетная запись блокируются на LoginLockout, 0 - без ограничений
+	LoginMaxFailures int `env:"LOGIN_MAX_FAILURES" json:"login_max_failures"`
+	// LoginBackoff - Задержка после первой неудачной попытки, удваивается с каждой следующей
+	LoginBackoff time.Duration `env:"LOGIN_BACKOFF" json:"login_backoff"`
+	// LoginLockout - Время блокировки после LoginMaxFailures неудачных попыток
+	LoginLockout time.Duration `env:"LOGIN_LOCKOUT" json:"login_lockout"`
+	// UniformAuthErrors - Одинаковая ошибка для неизвестного пользователя и неверного пароля
+	UniformAuthErrors bool `env:"UNIFORM_AUTH_ERRORS" json:"uniform_auth_errors"`
+	// TLSCert, TLSKey - Сертификат и ключ сервера. Если не заданы, соединение не шифруется
+	TLSCert string `env:"TLS_CERT" json:"tls_cert"`
+	TLSKey  string `env:"TLS_KEY" json:"tls_key"`
+	// TLSClientCA - Сертификаты CA клиентов. Если заданы, клиент обязан
+	// предъявить подписанный ими сертификат (mTLS)
+	TLSClientCA string `env:"TLS_CLIENT_CA" json:"tls_client_ca"`
 }
 
 // NewConfig Конфигурация сервера
 func NewConfig() *Config {
 
 	return &Config{
-		AddrGRPC:    ":3200",
-		DatabaseURI: "user=postgres password=postgres dbname=GophKeeper sslmode=disable",
+		AddrGRPC:          ":3200",
+		DatabaseURI:       "user=postgres password=postgres dbname=GophKeeper sslmode=disable",
+		RevisionRetention: revision.DefaultRetention,
+		TrashRetention:    trash.DefaultRetention,
+		AccessTokenTTL:    token.AccessTTL,
+		RefreshTokenTTL:   token.RefreshTTL,
+		PasswordHash:      passhash.Argon2id,
+		LoginMaxFailures:  5,
+		LoginBackoff:      time.Second,
+		LoginLockout:      15 * time.Minute,
 	}
 }
 
-// ParseArgs Разброр аргументов командной строки
+// flagFields - Поля конфигурации по имени флага.
+var flagFields = map[string]string{
+	"a":                   "address_rpc",
+	"s":                   "secret_key",
+	"d":                   "database_uri",
+	"r":                   "revision_retention",
+	"t":                   "trash_retention",
+	"access-ttl":          "access_token_ttl",
+	"refresh-ttl":         "refresh_token_ttl",
+	"reject-plaintext":    "reject_plaintext",
+	"password-hash":       "password_hash",
+	"login-max-failures":  "login_max_failures",
+	"login-backoff":       "login_backoff",
+	"login-lockout":       "login_lockout",
+	"uniform-auth-errors": "uniform_auth_errors",
+	"tls-cert":            "tls_cert",
+	"tls-key":             "tls_key",
+	"tls-client-ca":       "tls_client_ca",
+}
+
+// ParseArgs Разброр конфигурации: значения по умолчанию, файл -c или $CONFIG,
+// переменные окружения и аргументы командной строки - каждый источник
+// переопределяет предыдущий.
 func (cfg *Config) ParseArgs() error {
+	return cfg.parse(flag.CommandLine, os.Args[1:], os.LookupEnv)
+}
 
-	addr := flag.String("a", "", "address grpc gate")
-	secret := flag.String("s", "", "secret key for JWT")
-	dsn := flag.String("d", "", "database DSN")
-	flag.Parse()
+func (cfg *Config) parse(fs *flag.FlagSet, args []string, lookup func(string) (string, bool)) error {
+
+	fs.String(config.FileFlag, "", "config file, JSON or YAML (.yaml, .yml)")
+	fs.String("a", cfg.AddrGRPC, "address grpc gate")
+	fs.String("s", "", "secret key for JWT, prefer $SECRET_KEY or the config file")
+	fs.String("d", cfg.DatabaseURI, "database DSN, empty - in-memory storage")
+	fs.Int("r", cfg.RevisionRetention, "number of stored revisions per record, 0 - disable history")
+	fs.Duration("t", cfg.TrashRetention, "how long deleted records are kept in the trash")
+	fs.Duration("access-ttl", cfg.AccessTokenTTL, "access token lifetime")
+	fs.Duration("refresh-ttl", cfg.RefreshTokenTTL, "refresh token lifetime")
+	fs.Bool("reject-plaintext", cfg.RejectPlaintext, "reject data sent without client-side encryption")
+	fs.String("password-hash", cfg.PasswordHash, "password hashing algorithm: argon2id or bcrypt")
+	fs.Int("login-max-failures", cfg.LoginMaxFailures, "failed sign-in attempts before lockout, 0 - no limit")
+	fs.Duration("login-backoff", cfg.LoginBackoff, "delay after the first failed sign-in attempt, doubled after each next one")
+	fs.Duration("login-lockout", cfg.LoginLockout, "lockout after login-max-failures failed sign-in attempts")
+	fs.Bool("uniform-auth-errors", cfg.UniformAuthErrors, "same error for unknown email and wrong password")
+	fs.String("tls-cert", cfg.TLSCert, "server TLS certificate file")
+	fs.String("tls-key", cfg.TLSKey, "server TLS private key file")
+	fs.String("tls-client-ca", cfg.TLSClientCA, "CA bundle to verify client certificates (mTLS)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	loader := config.New(cfg)
 
-	if addr == nil || len(*addr) == 0 {
-		*addr = cfg.AddrGRPC
+	if path := config.FilePath(fs, lookup); len(path) != 0 {
+		if err := loader.File(path); err != nil {
+			return err
+		}
 	}
 
-	if err := isValidAddress(*addr); err != nil {
+	if err := loader.Env(lookup); err != nil {
 		return err
 	}
 
-	cfg.AddrGRPC = *addr
+	if err := loader.Flags(fs, flagFields); err != nil {
+		return err
+	}
+
+	return cfg.validate(loader)
+}
+
+// validate - Проверка значений с указанием источника неверного значения.
+func (cfg *Config) validate(loader *config.Loader) error {
+
+	if err := isValidAddress(cfg.AddrGRPC); err != nil {
+		return loader.Errorf("address_rpc", "%v", err)
+	}
+
+	if len(cfg.SecretKey) == 0 {
+		return loader.Errorf("secret_key", "must be set")
+	}
+
+	if len(cfg.SecretKey) < minSecretKeySize {
+		return loader.Errorf("secret_key", "must be at least %d bytes", minSecretKeySize)
+	}
+
+	if cfg.RevisionRetention < 0 {
+		return loader.Errorf("revision_retention", "can not be negative")
+	}
+
+	if cfg.TrashRetention <= 0 {
+		return loader.Errorf("trash_retention", "must be positive")
+	}
+
+	if cfg.AccessTokenTTL <= 0 {
+		return loader.Errorf("access_token_ttl", "must be positive")
+	}
+
+	if cfg.RefreshTokenTTL <= 0 {
+		return loader.Errorf("refresh_token_ttl", "must be positive")
+	}
+
+	if _, err := passhash.ByName(cfg.PasswordHash); err != nil {
+		return loader.Errorf("password_hash", "%v", err)
+	}
+
+	if cfg.LoginMaxFailures < 0 {
+		return loader.Errorf("login_max_failures", "can not be negative")
+	}
+
+	if cfg.LoginBackoff < 0 {
+		return loader.Errorf("login_backoff", "can not be negative")
+	}
+
+	if cfg.LoginLockout < 0 {
+		return loader.Errorf("login_lockout", "can not be negative")
+	}
+
+	if len(cfg.TLSCert) == 0 && len(cfg.TLSKey) != 0 {
+		return loader.Errorf("tls_key", "TLS certificate and key must be set together")
+	}
 
-	if dsn != nil && len(*dsn) > 0 {
-		cfg.DatabaseURI = *dsn
+	if len(cfg.TLSCert) != 0 && len(cfg.TLSKey) == 0 {
+		return loader.Errorf("tls_cert", "TLS certificate and key must be set together")
 	}
 
-	if secret != nil {
-		cfg.SecretKey = *secret
+	if len(cfg.TLSClientCA) != 0 && len(cfg.TLSCert) == 0 {
+		return loader.Errorf("tls_client_ca", "client CA requires a server TLS certificate")
 	}
 
 	return nil
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testSecret - Ключ подписи токенов допустимой длины.
+var testSecret = strings.Repeat("k", minSecretKeySize)
+
+func parseConfig(args []string, env map[string]string) (*Config, error) {
+
+	cfg := NewConfig()
+	err := cfg.parse(flag.NewFlagSet("server", flag.ContinueOnError), args, func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+
+	return cfg, err
+}
+
+func TestConfig_SecretKey(t *testing.T) {
+
+	_, err := parseConfig(nil, nil)
+	require.EqualError(t, err, "config secret_key (default): must be set")
+
+	_, err = parseConfig([]string{"-s", "short"}, nil)
+	require.EqualError(t, err, "config secret_key (flag -s): must be at least 32 bytes")
+
+	_, err = parseConfig(nil, map[string]string{"SECRET_KEY": "short"})
+	require.EqualError(t, err, "config secret_key (env SECRET_KEY): must be at least 32 bytes")
+
+	cfg, err := parseConfig(nil, map[string]string{"SECRET_KEY": testSecret})
+	require.NoError(t, err)
+	require.Equal(t, testSecret, cfg.SecretKey)
+}
+
+func TestConfig_DatabaseURI(t *testing.T) {
+
+	env := map[string]string{"SECRET_KEY": testSecret, "DatabaseURI": "legacy"}
+
+	// Прежнее имя переменной окружения
+	cfg, err := parseConfig(nil, env)
+	require.NoError(t, err)
+	require.Equal(t, "legacy", cfg.DatabaseURI)
+
+	env["DATABASE_URI"] = ""
+	cfg, err = parseConfig(nil, env)
+	require.NoError(t, err)
+	require.Empty(t, cfg.DatabaseURI)
+
+	cfg, err = parseConfig([]string{"-d", "flag"}, env)
+	require.NoError(t, err)
+	require.Equal(t, "flag", cfg.DatabaseURI)
+}
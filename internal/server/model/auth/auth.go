@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 // Credential - Учетные данные пользователя.
 type Credential struct {
 	// Email - Почтовый адрес.
@@ -7,3 +9,50 @@ type Credential struct {
 	// Password - Пароль.
 	Password string
 }
+
+// User - Учетная запись пользователя в хранилище.
+type User struct {
+	// Email - Почтовый адрес.
+	Email string
+	// PasswordHash - Хэш пароля вместе с алгоритмом и параметрами.
+	// Прежние записи содержат пароль в том виде, в каком его передал клиент.
+	PasswordHash string
+}
+
+// Tokens - Токены авторизованного пользователя.
+type Tokens struct {
+	// Access - Короткоживущий JWT для доступа к данным.
+	Access string
+	// Refresh - Долгоживущий токен для получения новых токенов.
+	Refresh string
+	// Challenge - Идентификатор входа, ожидающего одноразовый код второго фактора.
+	// Если он задан, токены не выдаются до подтверждения кода.
+	Challenge string
+}
+
+// TOTP - Второй фактор авторизации пользователя.
+type TOTP struct {
+	// SealedSecret - Секрет TOTP, зашифрованный ключом сервера.
+	SealedSecret []byte
+	// Confirmed - Подключение подтверждено кодом, второй фактор запрашивается при входе.
+	Confirmed bool
+	// RecoveryHashes - Хэши неиспользованных кодов восстановления.
+	RecoveryHashes []string
+	// LastStep - Шаг времени последнего принятого кода: коды повторно не принимаются.
+	LastStep int64
+}
+
+// Session - Сессия пользователя, открытая при авторизации или регистрации.
+// Токены доступа сессии содержат ее идентификатор в claim jti.
+type Session struct {
+	// ID - Идентификатор сессии.
+	ID string
+	// Email - Почтовый адрес владельца.
+	Email string
+	// RefreshHash - Хэш действующего refresh token сессии.
+	RefreshHash string
+	// CreatedAt - Время открытия сессии.
+	CreatedAt time.Time
+	// ExpiresAt - Время окончания действия refresh token.
+	ExpiresAt time.Time
+}
@@ -1,10 +1,38 @@
 package binary
 
+import "time"
+
+// ChunkSize - Размер части бинарных данных при потоковой передаче и хранении.
+const ChunkSize = 1024 * 256
+
 type DataFull struct {
 	MetaInfo string
 	Bytes    []byte
+	// Version - Версия данных. При изменении - ожидаемая текущая версия,
+	// обязательна.
+	Version int64
+	// UpdatedAt - Время последнего изменения
+	UpdatedAt time.Time
 }
 
 type DataGet struct {
 	MetaInfo string
+	// Version - Ожидаемая текущая версия при удалении, обязательна.
+	Version int64
+}
+
+// DataInfo - Сведения о данных без самих данных.
+type DataInfo struct {
+	MetaInfo  string
+	Version   int64
+	UpdatedAt time.Time
+}
+
+// DataUpload - Параметры потоковой загрузки данных.
+// Overwrite - Заменить существующие данные, а не создавать новые.
+// Version - При замене - ожидаемая текущая версия, обязательна.
+type DataUpload struct {
+	MetaInfo  string
+	Overwrite bool
+	Version   int64
 }
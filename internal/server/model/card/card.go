@@ -1,5 +1,7 @@
 package card
 
+import "time"
+
 // DataCardFull - Данные карты и их принаждлежности
 type DataCardFull struct {
 	// MetaInfo - Метаинформация для хранимых данных
@@ -12,10 +14,17 @@ type DataCardFull struct {
 	CVV string
 	// FullName - Полное имя держателя карты
 	FullName string
+	// Version - Версия данных. При изменении - ожидаемая текущая версия,
+	// обязательна.
+	Version int64
+	// UpdatedAt - Время последнего изменения
+	UpdatedAt time.Time
 }
 
 // DataCardGet - Данные lkz получения
 type DataCardGet struct {
 	// MetaInfo - Метаинформация для хранимых данных
 	MetaInfo string
+	// Version - Ожидаемая текущая версия при удалении, обязательна.
+	Version int64
 }
@@ -1,5 +1,7 @@
 package cred
 
+import "time"
+
 // CredentialFull - Данные получения логинов и паролей
 type CredentialFull struct {
 	// MetaInfo - Метаинформация для хранимых данных
@@ -8,10 +10,17 @@ type CredentialFull struct {
 	Email string
 	// Password - Пароль
 	Password string
+	// Version - Версия данных. При изменении - ожидаемая текущая версия,
+	// обязательна.
+	Version int64
+	// UpdatedAt - Время последнего изменения
+	UpdatedAt time.Time
 }
 
 // CredentialGet - Данные получения логинов и паролей
 type CredentialGet struct {
 	// MetaInfo - Метаинформация для хранимых данных
 	MetaInfo string
+	// Version - Ожидаемая текущая версия при удалении, обязательна.
+	Version int64
 }
@@ -0,0 +1,102 @@
+package page
+
+import (
+	"strings"
+)
+
+const (
+	// DefaultLimit - Размер страницы, если клиент его не указал.
+	DefaultLimit = 50
+	// MaxLimit - Максимальный размер страницы.
+	MaxLimit = 500
+)
+
+// Request - Параметры получения страницы списка метаинформации.
+type Request struct {
+	// Prefix - Метаинформация должна начинаться с этой строки
+	Prefix string
+	// Contains - Метаинформация должна содержать эту строку
+	Contains string
+	// After - Метаинформация, после которой начинается страница (токен страницы)
+	After string
+	// Limit - Максимальное количество записей на странице
+	Limit int
+}
+
+// Item - Метаинформация и версия записи в списке.
+type Item struct {
+	// MetaInfo - Метаинформация записи
+	MetaInfo string `db:"meta"`
+	// Version - Версия записи
+	Version int64 `db:"version"`
+}
+
+// Page - Страница списка метаинформации.
+type Page struct {
+	// MetaInfo - Метаинформация записей, отсортированная по возрастанию
+	MetaInfo []string
+	// Versions - Версии записей в порядке MetaInfo
+	Versions []int64
+	// NextPageToken - Токен следующей страницы. Пустой, если страница последняя
+	NextPageToken string
+}
+
+// Normalize - Приведение размера страницы к допустимому значению.
+func (r Request) Normalize() Request {
+
+	switch {
+	case r.Limit <= 0:
+		r.Limit = DefaultLimit
+	case r.Limit > MaxLimit:
+		r.Limit = MaxLimit
+	}
+
+	return r
+}
+
+// Match - Проверка, что метаинформация проходит фильтр и находится после начала страницы.
+func (r Request) Match(meta string) bool {
+
+	return strings.HasPrefix(meta, r.Prefix) &&
+		strings.Contains(meta, r.Contains) &&
+		meta > r.After
+}
+
+// PrefixPattern - Шаблон LIKE для фильтра по началу метаинформации.
+func (r Request) PrefixPattern() string {
+	return escapeLike(r.Prefix) + "%"
+}
+
+// ContainsPattern - Шаблон LIKE для фильтра по подстроке метаинформации.
+func (r Request) ContainsPattern() string {
+	return "%" + escapeLike(r.Contains) + "%"
+}
+
+// New - Формирование страницы из items, полученных по запросу с лимитом r.Limit+1.
+// Лишняя запись означает, что за страницей есть продолжение.
+func New(r Request, items []Item) Page {
+
+	next := ``
+	if len(items) > r.Limit {
+		items = items[:r.Limit]
+		next = items[len(items)-1].MetaInfo
+	}
+
+	p := Page{
+		MetaInfo:      make([]string, 0, len(items)),
+		Versions:      make([]int64, 0, len(items)),
+		NextPageToken: next,
+	}
+
+	for _, item := range items {
+		p.MetaInfo = append(p.MetaInfo, item.MetaInfo)
+		p.Versions = append(p.Versions, item.Version)
+	}
+
+	return p
+}
+
+// escapeLike - Экранирование спецсимволов LIKE (экранирующий символ - '\').
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
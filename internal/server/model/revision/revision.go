@@ -0,0 +1,24 @@
+package revision
+
+import "time"
+
+// DefaultRetention - Количество хранимых прежних версий записи по умолчанию.
+const DefaultRetention = 10
+
+// Revision - Сведения о прежней версии данных.
+type Revision struct {
+	// Version - Номер версии
+	Version int64
+	// UpdatedAt - Время, когда версия была записана
+	UpdatedAt time.Time
+}
+
+// Request - Запрос прежней версии данных.
+type Request struct {
+	// MetaInfo - Метаинформация данных
+	MetaInfo string
+	// Version - Номер прежней версии
+	Version int64
+	// Current - Ожидаемая текущая версия при восстановлении, обязательна.
+	Current int64
+}
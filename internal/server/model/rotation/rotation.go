@@ -0,0 +1,15 @@
+package rotation
+
+import "time"
+
+// Rotation - Незавершенная смена ключа шифрования данных пользователя.
+// Пока смена не завершена, часть данных может быть зашифрована прежним ключом.
+type Rotation struct {
+	// KeyID - Идентификатор нового ключа (hex secret.KeyID).
+	KeyID string
+	// SealedKey - Новый ключ хранилища, зашифрованный мастер-паролем.
+	// Пустой, если ключи хранятся у пользователя в файлах.
+	SealedKey []byte
+	// StartedAt - Время начала смены ключа
+	StartedAt time.Time
+}
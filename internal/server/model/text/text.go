@@ -1,15 +1,24 @@
 package text
 
+import "time"
+
 // DataTextFull - Текстовые данные
 type DataTextFull struct {
 	// MetaInfo - Метаинформация для хранимого текста
 	MetaInfo string
 	// Text - Текст
 	Text string
+	// Version - Версия данных. При изменении - ожидаемая текущая версия,
+	// обязательна.
+	Version int64
+	// UpdatedAt - Время последнего изменения
+	UpdatedAt time.Time
 }
 
 // DataTextGet - Данные получения текста
 type DataTextGet struct {
 	// MetaInfo - Метаинформация для хранимого текста
 	MetaInfo string
+	// Version - Ожидаемая текущая версия при удалении, обязательна.
+	Version int64
 }
@@ -0,0 +1,14 @@
+package trash
+
+import "time"
+
+// DefaultRetention - Время хранения удаленных данных в корзине по умолчанию.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// Item - Удаленные данные в корзине.
+type Item struct {
+	// MetaInfo - Метаинформация удаленных данных
+	MetaInfo string
+	// DeletedAt - Время удаления
+	DeletedAt time.Time
+}
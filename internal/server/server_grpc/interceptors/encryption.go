@@ -0,0 +1,94 @@
+package interceptors
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"GophKeeper/pkg/secret"
+)
+
+// ErrPlaintext - Текст ошибки при отправке незашифрованных данных.
+const ErrPlaintext = "unencrypted data rejected"
+
+// EncryptionInterceptor - Перехватчик для gRPC, который отклоняет запись данных,
+// если клиент не сообщил в метаданных, что данные зашифрованы.
+type EncryptionInterceptor struct{}
+
+// NewEncryptionInterceptor - Создание перехватчиков, отклоняющих незашифрованные
+// данные в unary и stream запросах. Добавляются в цепочку после проверки JWT.
+func NewEncryptionInterceptor() []grpc.ServerOption {
+	e := EncryptionInterceptor{}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(e.EncryptionInterceptor),
+		grpc.ChainStreamInterceptor(e.EncryptionStreamInterceptor),
+	}
+}
+
+// EncryptionInterceptor - Проверяет, что данные запросов Create, Change и Upload
+// зашифрованы: в метаданных ctx по ключу secret.EncryptionHeader должно быть
+// значение secret.EncryptionEnvelope. Иначе возвращается codes.FailedPrecondition.
+// Запросы сервиса авторизации не проверяются.
+func (inter EncryptionInterceptor) EncryptionInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+
+	if err := inter.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// EncryptionStreamInterceptor - Аналог EncryptionInterceptor для потоковых запросов.
+func (inter EncryptionInterceptor) EncryptionStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	if err := inter.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// check - Проверка заголовка шифрования для запросов записи данных.
+func (inter EncryptionInterceptor) check(ctx context.Context, method string) error {
+
+	if !isWrite(method) {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(secret.EncryptionHeader)
+	if len(values) != 1 || values[0] != secret.EncryptionEnvelope {
+		return status.Error(codes.FailedPrecondition, ErrPlaintext)
+	}
+
+	return nil
+}
+
+// isWrite - Запрос записи новых данных пользователя.
+func isWrite(method string) bool {
+
+	if strings.HasPrefix(method, "/auth.") {
+		return false
+	}
+
+	switch path.Base(method) {
+	case "Create", "Change", "Upload", "BatchUpdate":
+		return true
+	}
+
+	return false
+}
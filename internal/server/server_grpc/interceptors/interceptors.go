@@ -2,6 +2,7 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"go.uber.org/zap"
@@ -10,23 +11,38 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"GophKeeper/internal/server/model/auth"
+	"GophKeeper/pkg/errs"
 	"GophKeeper/pkg/token"
 )
 
+// SessionFinder - Реестр сессий, по которому проверяется, что сессия токена не закрыта.
+type SessionFinder interface {
+	Find(id string) (auth.Session, error)
+}
+
 // ValidateInterceptor - Перехватчик для gRPC, который отвечает за проверку подлинности JWT.
 type ValidateInterceptor struct {
 	// secretKey - Секретный ключ дял проверки подлинности JWT.
 	secretKey string
-	logger    *zap.Logger
+	// sessions - Реестр открытых сессий. Если nil, сессии не проверяются.
+	sessions SessionFinder
+	logger   *zap.Logger
 }
 
-// NewValidateInterceptor - Создание экземпляра перехватчика для валидации JWT.
-func NewValidateInterceptor(key string) grpc.ServerOption {
+// NewValidateInterceptor - Создание перехватчиков для валидации JWT
+// в unary и stream запросах.
+func NewValidateInterceptor(key string, sessions SessionFinder) []grpc.ServerOption {
 	v := &ValidateInterceptor{
 		secretKey: key,
+		sessions:  sessions,
 		logger:    zap.L(),
 	}
-	return grpc.UnaryInterceptor(middleware.ChainUnaryServer(v.ValidateTokenInterceptor))
+
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(middleware.ChainUnaryServer(v.ValidateTokenInterceptor)),
+		grpc.StreamInterceptor(middleware.ChainStreamServer(v.ValidateTokenStreamInterceptor)),
+	}
 }
 
 // ValidateTokenInterceptor - Проверяет подлинность JWT.
@@ -34,20 +50,56 @@ func NewValidateInterceptor(key string) grpc.ServerOption {
 // Если токен не найден или он не прошел проверку подлинности, то возвращается
 // codes.PermissionDenied.
 // Если токен валидный, то создается новый context на базе ctx, а в его метаданные
-// записывается email пользователя (из токена) и новый context передается дальше в handler.
+// записывается email пользователя и идентификатор сессии (из токена)
+// и новый context передается дальше в handler.
 //
-// При запросе Register или Login токен не проверяется.
+// При запросе Register, Login, VerifyOTP или Refresh токен не проверяется.
+// Если срок действия токена истек, в тексте ошибки указывается "token expired",
+// чтобы клиент мог получить новый токен через Refresh.
+// Если сессия токена закрыта, в тексте ошибки указывается "session revoked".
 func (inter ValidateInterceptor) ValidateTokenInterceptor(
 	ctx context.Context,
 	req interface{},
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler) (interface{}, error) {
 
-	// При регистрации и авторизации не проверяем токен
-	if info.FullMethod == "/auth.AuthService/Register" || info.FullMethod == "/auth.AuthService/Login" {
+	// При регистрации, авторизации, проверке второго фактора и обновлении токенов не проверяем токен
+	if info.FullMethod == "/auth.AuthService/Register" ||
+		info.FullMethod == "/auth.AuthService/Login" ||
+		info.FullMethod == "/auth.AuthService/VerifyOTP" ||
+		info.FullMethod == "/auth.AuthService/Refresh" {
 		return handler(ctx, req)
 	}
 
+	ctx, err := inter.validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// ValidateTokenStreamInterceptor - Проверяет подлинность JWT для потоковых запросов.
+// Аналогичен ValidateTokenInterceptor, email пользователя доступен
+// в метаданных ServerStream.Context().
+func (inter ValidateInterceptor) ValidateTokenStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	ctx, err := inter.validate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &validatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// validate - Проверка JWT из метаданных ctx.
+// Возвращает context, в метаданных которого email пользователя и сессия из токена.
+func (inter ValidateInterceptor) validate(ctx context.Context) (context.Context, error) {
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, status.Error(codes.PermissionDenied, "Failed read metadata")
@@ -60,12 +112,56 @@ func (inter ValidateInterceptor) ValidateTokenInterceptor(
 
 	jwtToken, err := token.VerifyJWT(values[0], inter.secretKey)
 	if err != nil {
+		if token.IsExpired(err) {
+			return nil, status.Error(codes.PermissionDenied, "token expired")
+		}
 		return nil, status.Error(codes.PermissionDenied, "Invalid token")
 	}
 
-	email := jwtToken.Claims.(*token.Token).Email
-	md.Append("email", email)
-	ctx = metadata.NewIncomingContext(ctx, md)
+	claims := jwtToken.Claims.(*token.Token)
+	if err = inter.checkSession(claims); err != nil {
+		return nil, err
+	}
 
-	return handler(ctx, req)
+	// Set, а не Append: email, переданный клиентом в метаданных, не должен
+	// подменять владельца данных из токена
+	md = md.Copy()
+	md.Set("email", claims.Email)
+	md.Set("session", claims.Id)
+
+	return metadata.NewIncomingContext(ctx, md), nil
+}
+
+// checkSession - Проверка, что сессия токена открыта и принадлежит владельцу токена.
+func (inter ValidateInterceptor) checkSession(claims *token.Token) error {
+
+	if inter.sessions == nil {
+		return nil
+	}
+
+	session, err := inter.sessions.Find(claims.Id)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return status.Error(codes.PermissionDenied, "session revoked")
+		}
+
+		inter.logger.Error("failed find session", zap.Error(err))
+		return status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	if session.Email != claims.Email {
+		return status.Error(codes.PermissionDenied, "session revoked")
+	}
+
+	return nil
+}
+
+// validatedStream - ServerStream с context, прошедшим проверку JWT.
+type validatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *validatedStream) Context() context.Context {
+	return s.ctx
 }
@@ -2,15 +2,24 @@ package interceptors
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
+	"GophKeeper/internal/server/model/auth"
+	"GophKeeper/internal/storage/session_store"
 	"GophKeeper/pkg/md_ctx"
+	pb "GophKeeper/pkg/proto/auth"
+	"GophKeeper/pkg/secret"
 	"GophKeeper/pkg/token"
 )
 
@@ -52,6 +61,22 @@ func TestValidateTokenInterceptor(t *testing.T) {
 			wantErr:   false,
 			wantEmail: false,
 		},
+		{
+			name: "Check unprocessed endpoint VerifyOTP",
+			info: &grpc.UnaryServerInfo{
+				FullMethod: "/auth.AuthService/VerifyOTP",
+			},
+			wantErr:   false,
+			wantEmail: false,
+		},
+		{
+			name: "Check unprocessed endpoint Refresh",
+			info: &grpc.UnaryServerInfo{
+				FullMethod: "/auth.AuthService/Refresh",
+			},
+			wantErr:   false,
+			wantEmail: false,
+		},
 		{
 			name: "Validate valid token",
 			info: &grpc.UnaryServerInfo{
@@ -103,3 +128,373 @@ func TestValidateTokenInterceptor(t *testing.T) {
 		})
 	}
 }
+
+// TestValidateTokenInterceptor_SpoofEmail - email из метаданных клиента
+// не должен подменять email владельца токена.
+func TestValidateTokenInterceptor_SpoofEmail(t *testing.T) {
+
+	email := "test@email.ru"
+
+	tokenStr, errJWT := token.GenerateJWT(email, "")
+	require.NoError(t, errJWT)
+
+	service := func(ctx context.Context, req interface{}) (interface{}, error) {
+		emailMD, _ := md_ctx.ValueFromContext(ctx, "email")
+		return emailMD, nil
+	}
+
+	md := metadata.Pairs("token", tokenStr, "email", "other@email.ru")
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/text.TextService/Get",
+	}
+
+	v := ValidateInterceptor{}
+	emailGet, err := v.ValidateTokenInterceptor(ctx, nil, info, service)
+	require.NoError(t, err)
+	assert.Equal(t, email, emailGet)
+}
+
+// TestValidateTokenInterceptor_Expired - истекший токен отклоняется
+// с признаком "token expired", по которому клиент обновляет токен.
+func TestValidateTokenInterceptor_Expired(t *testing.T) {
+
+	tokenStr, errJWT := token.GenerateSessionJWT("test@email.ru", "", "", -time.Minute)
+	require.NoError(t, errJWT)
+
+	service := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	md := metadata.Pairs("token", tokenStr)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/text.TextService/Get",
+	}
+
+	v := ValidateInterceptor{}
+	_, err := v.ValidateTokenInterceptor(ctx, nil, info, service)
+
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, e.Code())
+	assert.Equal(t, "token expired", e.Message())
+}
+
+// TestValidateTokenInterceptor_Session - токен закрытой или чужой сессии отклоняется.
+func TestValidateTokenInterceptor_Session(t *testing.T) {
+
+	email := "test@email.ru"
+
+	sessions := session_store.NewMemoryStorage()
+	require.NoError(t, sessions.Create(auth.Session{
+		ID:        "session",
+		Email:     email,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	service := func(ctx context.Context, req interface{}) (interface{}, error) {
+		session, _ := md_ctx.ValueFromContext(ctx, "session")
+		return session, nil
+	}
+
+	info := &grpc.UnaryServerInfo{
+		FullMethod: "/text.TextService/Get",
+	}
+
+	v := ValidateInterceptor{sessions: sessions}
+
+	call := func(email, session string) (interface{}, error) {
+		tokenStr, errJWT := token.GenerateSessionJWT(email, session, "", time.Minute)
+		require.NoError(t, errJWT)
+
+		md := metadata.Pairs("token", tokenStr, "session", "spoofed")
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		return v.ValidateTokenInterceptor(ctx, nil, info, service)
+	}
+
+	// Открытая сессия
+	sessionGet, err := call(email, "session")
+	require.NoError(t, err)
+	assert.Equal(t, "session", sessionGet)
+
+	// Сессия другого пользователя
+	_, err = call("other@email.ru", "session")
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, e.Code())
+	assert.Equal(t, "session revoked", e.Message())
+
+	// Закрытая сессия
+	require.NoError(t, sessions.Delete(email, "session"))
+	_, err = call(email, "session")
+	e, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, e.Code())
+	assert.Equal(t, "session revoked", e.Message())
+}
+
+// testServerStream - ServerStream с заданным context.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// TestValidateTokenStreamInterceptor - Тест перехватчика JWT для потоковых запросов.
+func TestValidateTokenStreamInterceptor(t *testing.T) {
+
+	email := "test@email.ru"
+
+	tokenStr, errJWT := token.GenerateJWT(email, "")
+	require.NoError(t, errJWT)
+
+	var emailGet string
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		emailGet, _ = md_ctx.ValueFromContext(ss.Context(), "email")
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{
+		FullMethod: "/binary.BinaryService/Upload",
+	}
+
+	v := ValidateInterceptor{}
+
+	// Без токена
+	ss := testServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+	err := v.ValidateTokenStreamInterceptor(nil, ss, info, handler)
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.PermissionDenied, e.Code())
+
+	// С валидным токеном
+	md := metadata.Pairs("token", tokenStr, "email", "other@email.ru")
+	ss = testServerStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+	err = v.ValidateTokenStreamInterceptor(nil, ss, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, email, emailGet)
+}
+
+// TestEncryptionInterceptor - Тест перехватчика, отклоняющего незашифрованные данные.
+func TestEncryptionInterceptor(t *testing.T) {
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		md       metadata.MD
+		wantCode codes.Code
+	}{
+		{
+			name:     "Create with envelope",
+			method:   "/text.TextService/Create",
+			md:       metadata.Pairs(secret.EncryptionHeader, secret.EncryptionEnvelope),
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Change without header",
+			method:   "/cred.CredService/Change",
+			md:       metadata.MD{},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "Create plaintext",
+			method:   "/card.CardService/Create",
+			md:       metadata.Pairs(secret.EncryptionHeader, secret.EncryptionNone),
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "Batch update without header",
+			method:   "/rotation.RotationService/BatchUpdate",
+			md:       metadata.MD{},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "Read without header",
+			method:   "/text.TextService/Get",
+			md:       metadata.MD{},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Auth service without header",
+			method:   "/auth.AuthService/ChangePassword",
+			md:       metadata.MD{},
+			wantCode: codes.OK,
+		},
+	}
+
+	inter := EncryptionInterceptor{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+
+			_, err := inter.EncryptionInterceptor(ctx, nil, info, handler)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+
+	// Потоковая загрузка файла
+	info := &grpc.StreamServerInfo{FullMethod: "/binary.BinaryService/Upload"}
+	stream := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+	ss := testServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{})}
+	err := inter.EncryptionStreamInterceptor(nil, ss, info, stream)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	md := metadata.Pairs(secret.EncryptionHeader, secret.EncryptionEnvelope)
+	ss = testServerStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+	err = inter.EncryptionStreamInterceptor(nil, ss, info, stream)
+	assert.NoError(t, err)
+}
+
+// TestRateLimitInterceptor - Тест ограничения попыток авторизации.
+func TestRateLimitInterceptor(t *testing.T) {
+
+	now := time.Now()
+	limit := newRateLimit(RateLimit{MaxFailures: 3, Backoff: time.Second, Lockout: time.Minute})
+	limit.now = func() time.Time { return now }
+
+	fromIP := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000},
+		})
+	}
+
+	var handlerErr error
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", handlerErr
+	}
+
+	login := &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/Login"}
+	req := &pb.AuthRequest{Email: "test@email.ru"}
+
+	call := func(ctx context.Context, info *grpc.UnaryServerInfo, req interface{}) codes.Code {
+		_, err := limit.RateLimitInterceptor(ctx, req, info, handler)
+		return status.Code(err)
+	}
+
+	handlerErr = status.Error(codes.InvalidArgument, "invalid password")
+	assert.Equal(t, codes.InvalidArgument, call(fromIP("10.0.0.1"), login, req))
+
+	// Задержка после неудачи для IP и email
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP("10.0.0.1"), login, req))
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP("10.0.0.2"), login, req))
+	assert.Equal(t, codes.InvalidArgument, call(fromIP("10.0.0.10"), login, &pb.AuthRequest{Email: "other@email.ru"}))
+
+	// Задержка растет экспоненциально
+	now = now.Add(1500 * time.Millisecond)
+	assert.Equal(t, codes.InvalidArgument, call(fromIP("10.0.0.3"), login, req))
+	now = now.Add(time.Second)
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP("10.0.0.4"), login, req))
+
+	// После MaxFailures неудач учетная запись блокируется на Lockout
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, codes.InvalidArgument, call(fromIP("10.0.0.4"), login, req))
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP("10.0.0.5"), login, req))
+
+	now = now.Add(time.Minute)
+	handlerErr = nil
+	assert.Equal(t, codes.OK, call(fromIP("10.0.0.5"), login, req))
+
+	// Успешный вход сбрасывает счетчик
+	handlerErr = status.Error(codes.InvalidArgument, "invalid password")
+	assert.Equal(t, codes.InvalidArgument, call(fromIP("10.0.0.6"), login, req))
+
+	// Неудачная регистрация не блокирует вход для email
+	register := &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/Register"}
+	handlerErr = status.Error(codes.AlreadyExists, "already exist")
+	now = now.Add(time.Hour)
+	assert.Equal(t, codes.AlreadyExists, call(fromIP("10.0.0.7"), register, req))
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP("10.0.0.7"), register, req))
+	handlerErr = nil
+	assert.Equal(t, codes.OK, call(fromIP("10.0.0.8"), login, req))
+
+	// Другие запросы не ограничиваются
+	handlerErr = status.Error(codes.NotFound, "not found")
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, codes.NotFound, call(fromIP("10.0.0.9"), &grpc.UnaryServerInfo{FullMethod: "/text.TextService/Get"}, req))
+	}
+
+	assert.Nil(t, NewRateLimitInterceptor(RateLimit{}))
+}
+
+// TestRateLimitInterceptor_SecondFactor - Тест ограничения подбора кода второго фактора
+// чередованием Login и VerifyOTP с разных IP.
+func TestRateLimitInterceptor_SecondFactor(t *testing.T) {
+
+	now := time.Now()
+	limit := newRateLimit(RateLimit{MaxFailures: 3, Backoff: time.Second, Lockout: time.Minute})
+	limit.now = func() time.Time { return now }
+
+	fromIP := func(i int) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 1, byte(i)), Port: 40000},
+		})
+	}
+
+	login := &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/Login"}
+	verify := &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/VerifyOTP"}
+
+	// Пароль верный: Login выдает challenge, код второго фактора неверный
+	var verifyErr error
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if r, ok := req.(*pb.AuthRequest); ok {
+			return &pb.AuthResponse{Challenge: "challenge-" + r.Password}, nil
+		}
+		if verifyErr != nil {
+			return nil, verifyErr
+		}
+		return &pb.AuthResponse{Token: "token"}, nil
+	}
+
+	call := func(ctx context.Context, info *grpc.UnaryServerInfo, req interface{}) codes.Code {
+		_, err := limit.RateLimitInterceptor(ctx, req, info, handler)
+		return status.Code(err)
+	}
+
+	loginReq := func(i int) *pb.AuthRequest {
+		return &pb.AuthRequest{Email: "Test@email.ru", Password: fmt.Sprint(i)}
+	}
+	verifyReq := func(i int) *pb.VerifyOTPRequest {
+		return &pb.VerifyOTPRequest{Challenge: fmt.Sprint("challenge-", i), Code: "000000"}
+	}
+
+	verifyErr = status.Error(codes.InvalidArgument, "invalid code")
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, codes.OK, call(fromIP(i), login, loginReq(i)), "login %d", i)
+		assert.Equal(t, codes.InvalidArgument, call(fromIP(i), verify, verifyReq(i)), "verify %d", i)
+		now = now.Add(10 * time.Second)
+	}
+
+	// Неудачи кода учтены по email: успешная проверка пароля их не сбрасывает
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP(4), login, loginReq(4)))
+	assert.Equal(t, codes.ResourceExhausted, call(fromIP(4), verify, verifyReq(3)))
+
+	// Неизвестный challenge учитывается только по IP
+	assert.Equal(t, codes.InvalidArgument, call(fromIP(5), verify, verifyReq(100)))
+
+	// После блокировки вход завершается и сбрасывает счетчики
+	now = now.Add(time.Minute)
+	verifyErr = nil
+	assert.Equal(t, codes.OK, call(fromIP(6), login, loginReq(6)))
+	assert.Equal(t, codes.OK, call(fromIP(6), verify, verifyReq(6)))
+
+	verifyErr = status.Error(codes.InvalidArgument, "invalid code")
+	assert.Equal(t, codes.OK, call(fromIP(7), login, loginReq(7)))
+	assert.Equal(t, codes.InvalidArgument, call(fromIP(7), verify, verifyReq(7)))
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, codes.OK, call(fromIP(8), login, loginReq(8)))
+}
@@ -0,0 +1,273 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	methodLogin     = "/auth.AuthService/Login"
+	methodRegister  = "/auth.AuthService/Register"
+	methodVerifyOTP = "/auth.AuthService/VerifyOTP"
+	// methodDeleteAccount - Проверяет пароль, поэтому тоже ограничивается:
+	// иначе украденным токеном можно подбирать пароль.
+	methodDeleteAccount = "/auth.AuthService/DeleteAccount"
+)
+
+// challengeTTL - Время, в течение которого неудачи VerifyOTP для входа
+// учитываются по email. Не меньше времени ожидания кода второго фактора сервером.
+const challengeTTL = 10 * time.Minute
+
+// RateLimit - Параметры защиты авторизации от перебора.
+type RateLimit struct {
+	// MaxFailures - Количество неудачных попыток подряд, после которого
+	// вход блокируется на Lockout. 0 - ограничение отключено.
+	MaxFailures int
+	// Backoff - Задержка после первой неудачи, после каждой следующей она удваивается.
+	Backoff time.Duration
+	// Lockout - Время блокировки после MaxFailures неудач. Счетчик неудач
+	// сбрасывается, если за это время новых неудач не было.
+	Lockout time.Duration
+}
+
+// attempts - Неудачные попытки по одному ключу.
+type attempts struct {
+	failures int
+	last     time.Time
+	// blockedUntil - До этого момента запросы отклоняются.
+	blockedUntil time.Time
+}
+
+// challengeKey - Ключ email незавершенного входа со вторым фактором.
+type challengeKey struct {
+	key       string
+	expiresAt time.Time
+}
+
+// RateLimitInterceptor - Перехватчик для gRPC, ограничивающий попытки
+// авторизации по IP клиента и по email учетной записи.
+type RateLimitInterceptor struct {
+	limit RateLimit
+	mutex sync.Mutex
+	keys  map[string]attempts
+	// challenges - Email входов, ожидающих код второго фактора, по challenge
+	challenges map[string]challengeKey
+	swept      time.Time
+	now        func() time.Time
+}
+
+// NewRateLimitInterceptor - Создание перехватчика, ограничивающего
+// попытки авторизации. Добавляется в цепочку после проверки JWT.
+func NewRateLimitInterceptor(limit RateLimit) []grpc.ServerOption {
+
+	if limit.MaxFailures <= 0 {
+		return nil
+	}
+
+	r := newRateLimit(limit)
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(r.RateLimitInterceptor),
+	}
+}
+
+func newRateLimit(limit RateLimit) *RateLimitInterceptor {
+	return &RateLimitInterceptor{
+		limit:      limit,
+		keys:       make(map[string]attempts),
+		challenges: make(map[string]challengeKey),
+		now:        time.Now,
+	}
+}
+
+// RateLimitInterceptor - Ограничивает запросы Login, Register, VerifyOTP и DeleteAccount.
+// После каждой неудачи следующая попытка с того же IP (а для Login и VerifyOTP - и для
+// того же email) возможна через экспоненциально растущую задержку, после MaxFailures
+// неудач - через Lockout. До этого запросы отклоняются с codes.ResourceExhausted.
+// Успешный запрос сбрасывает счетчики. Login, ожидающий код второго фактора,
+// счетчики не сбрасывает: иначе повторный вход позволял бы подбирать код без ограничений.
+func (inter *RateLimitInterceptor) RateLimitInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+
+	switch info.FullMethod {
+	case methodLogin, methodRegister, methodVerifyOTP, methodDeleteAccount:
+	default:
+		return handler(ctx, req)
+	}
+
+	keys := inter.limitKeys(ctx, info.FullMethod, req)
+
+	if wait := inter.blocked(keys); wait > 0 {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many attempts, retry in %s", wait.Round(time.Second))
+	}
+
+	resp, err := handler(ctx, req)
+
+	switch status.Code(err) {
+	case codes.OK:
+		if r, ok := resp.(interface{ GetChallenge() string }); ok && len(r.GetChallenge()) != 0 {
+			inter.challenge(r.GetChallenge(), keys)
+			break
+		}
+		inter.reset(keys)
+
+	case codes.NotFound, codes.InvalidArgument, codes.Unauthenticated, codes.AlreadyExists:
+		inter.fail(keys)
+	}
+
+	return resp, err
+}
+
+// limitKeys - Ключи, по которым считаются попытки запроса.
+// Email учитывается только при входе: неудачная регистрация на чужой email
+// не должна блокировать вход владельцу. Для VerifyOTP email берется
+// из ответа Login, выдавшего challenge.
+func (inter *RateLimitInterceptor) limitKeys(ctx context.Context, method string, req interface{}) []string {
+
+	var keys []string
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		keys = append(keys, "ip:"+host)
+	}
+
+	if r, ok := req.(interface{ GetEmail() string }); ok && method == methodLogin {
+		keys = append(keys, "email:"+strings.ToLower(r.GetEmail()))
+	}
+
+	if r, ok := req.(interface{ GetChallenge() string }); ok && method == methodVerifyOTP {
+		inter.mutex.Lock()
+		ch, found := inter.challenges[r.GetChallenge()]
+		inter.mutex.Unlock()
+
+		if found && inter.now().Before(ch.expiresAt) {
+			keys = append(keys, ch.key)
+		}
+	}
+
+	return keys
+}
+
+// challenge - Запоминание email входа id, ожидающего код второго фактора.
+func (inter *RateLimitInterceptor) challenge(id string, keys []string) {
+
+	inter.mutex.Lock()
+	defer inter.mutex.Unlock()
+
+	now := inter.now()
+	inter.sweep(now)
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, "email:") {
+			inter.challenges[id] = challengeKey{key: key, expiresAt: now.Add(challengeTTL)}
+		}
+	}
+}
+
+// blocked - Время до следующей разрешенной попытки, 0 - попытка разрешена.
+func (inter *RateLimitInterceptor) blocked(keys []string) time.Duration {
+
+	inter.mutex.Lock()
+	defer inter.mutex.Unlock()
+
+	now := inter.now()
+
+	var wait time.Duration
+	for _, key := range keys {
+		if left := inter.keys[key].blockedUntil.Sub(now); left > wait {
+			wait = left
+		}
+	}
+
+	return wait
+}
+
+// fail - Учет неудачной попытки.
+func (inter *RateLimitInterceptor) fail(keys []string) {
+
+	inter.mutex.Lock()
+	defer inter.mutex.Unlock()
+
+	now := inter.now()
+	inter.sweep(now)
+
+	for _, key := range keys {
+		a := inter.keys[key]
+		if now.Sub(a.last) > inter.limit.Lockout {
+			a = attempts{}
+		}
+
+		a.failures++
+		a.last = now
+
+		if a.failures >= inter.limit.MaxFailures {
+			a.blockedUntil = now.Add(inter.limit.Lockout)
+		} else {
+			a.blockedUntil = now.Add(inter.backoff(a.failures))
+		}
+
+		inter.keys[key] = a
+	}
+}
+
+// backoff - Задержка после failures неудач подряд, не больше Lockout.
+func (inter *RateLimitInterceptor) backoff(failures int) time.Duration {
+
+	delay := inter.limit.Backoff
+	for i := 1; i < failures && delay < inter.limit.Lockout; i++ {
+		delay *= 2
+	}
+
+	if delay > inter.limit.Lockout {
+		return inter.limit.Lockout
+	}
+
+	return delay
+}
+
+// reset - Сброс счетчиков после успешной попытки.
+func (inter *RateLimitInterceptor) reset(keys []string) {
+
+	inter.mutex.Lock()
+	defer inter.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(inter.keys, key)
+	}
+}
+
+// sweep - Удаление счетчиков без неудач дольше Lockout и истекших входов,
+// не чаще раза за Lockout.
+func (inter *RateLimitInterceptor) sweep(now time.Time) {
+
+	if now.Sub(inter.swept) < inter.limit.Lockout {
+		return
+	}
+	inter.swept = now
+
+	for key, a := range inter.keys {
+		if now.Sub(a.last) > inter.limit.Lockout {
+			delete(inter.keys, key)
+		}
+	}
+
+	for id, ch := range inter.challenges {
+		if now.After(ch.expiresAt) {
+			delete(inter.challenges, id)
+		}
+	}
+}
@@ -11,11 +11,13 @@ import (
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_binary"
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_card"
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_cred"
+	"GophKeeper/internal/server/server_grpc/services/grpc_service_rotation"
 	"GophKeeper/internal/server/server_grpc/services/grpc_service_text"
 	pbAuth "GophKeeper/pkg/proto/auth"
 	pbBinary "GophKeeper/pkg/proto/binary"
 	pbCard "GophKeeper/pkg/proto/card"
 	pbCred "GophKeeper/pkg/proto/credential"
+	pbRotation "GophKeeper/pkg/proto/rotation"
 	pbText "GophKeeper/pkg/proto/text"
 )
 
@@ -35,7 +37,8 @@ type ServerGRPC struct {
 
 // NewServer - Создание экземпляра gRPC сервера, но не запускает его.
 // • addr - Адрес, на котором в при вызове Start() будет запущен сервер.
-func NewServer(addr string, interceptor grpc.ServerOption, opts ...ServerOption) (*ServerGRPC, error) {
+// • interceptors - Перехватчики запросов и другие опции gRPC, например grpc.Creds для TLS.
+func NewServer(addr string, interceptors []grpc.ServerOption, opts ...ServerOption) (*ServerGRPC, error) {
 	listen, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
@@ -43,10 +46,10 @@ func NewServer(addr string, interceptor grpc.ServerOption, opts ...ServerOption)
 
 	maxSize := 1024 * 1024 * 10
 	optGrpc := []grpc.ServerOption{
-		interceptor,
 		grpc.MaxRecvMsgSize(maxSize),
 		grpc.MaxSendMsgSize(maxSize),
 	}
+	optGrpc = append(optGrpc, interceptors...)
 
 	s := &ServerGRPC{
 		Server:   grpc.NewServer(optGrpc...),
@@ -95,6 +98,13 @@ func WithCardServiceRPC(cardServ *grpc_service_card.CardServiceRPC) ServerOption
 	}
 }
 
+// WithRotationServiceRPC - Регистрирует сервис gPRC смены ключа шифрования
+func WithRotationServiceRPC(rot *grpc_service_rotation.RotationServiceRPC) ServerOption {
+	return func(serv *ServerGRPC) {
+		pbRotation.RegisterRotationServiceServer(serv.Server, rot)
+	}
+}
+
 // Start - Запуск сервера.
 func (serv *ServerGRPC) Start() {
 	go func() {
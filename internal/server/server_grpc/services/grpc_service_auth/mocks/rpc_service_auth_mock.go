@@ -35,24 +35,112 @@ func (m *MockAuthApp) EXPECT() *MockAuthAppMockRecorder {
 }
 
 // ChangePassword mocks base method.
-func (m *MockAuthApp) ChangePassword(email, password string) error {
+func (m *MockAuthApp) ChangePassword(email, session, password string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangePassword", email, password)
+	ret := m.ctrl.Call(m, "ChangePassword", email, session, password)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ChangePassword indicates an expected call of ChangePassword.
-func (mr *MockAuthAppMockRecorder) ChangePassword(email, password interface{}) *gomock.Call {
+func (mr *MockAuthAppMockRecorder) ChangePassword(email, session, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthApp)(nil).ChangePassword), email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthApp)(nil).ChangePassword), email, session, password)
+}
+
+// Confirm2FA mocks base method.
+func (m *MockAuthApp) Confirm2FA(email, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm2FA", email, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm2FA indicates an expected call of Confirm2FA.
+func (mr *MockAuthAppMockRecorder) Confirm2FA(email, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm2FA", reflect.TypeOf((*MockAuthApp)(nil).Confirm2FA), email, code)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockAuthApp) DeleteAccount(email, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", email, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockAuthAppMockRecorder) DeleteAccount(email, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAuthApp)(nil).DeleteAccount), email, password)
+}
+
+// Disable2FA mocks base method.
+func (m *MockAuthApp) Disable2FA(email, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable2FA", email, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable2FA indicates an expected call of Disable2FA.
+func (mr *MockAuthAppMockRecorder) Disable2FA(email, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable2FA", reflect.TypeOf((*MockAuthApp)(nil).Disable2FA), email, code)
+}
+
+// Enroll2FA mocks base method.
+func (m *MockAuthApp) Enroll2FA(email string) (string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll2FA", email)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Enroll2FA indicates an expected call of Enroll2FA.
+func (mr *MockAuthAppMockRecorder) Enroll2FA(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll2FA", reflect.TypeOf((*MockAuthApp)(nil).Enroll2FA), email)
+}
+
+// GetVaultKey mocks base method.
+func (m *MockAuthApp) GetVaultKey(email string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultKey", email)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultKey indicates an expected call of GetVaultKey.
+func (mr *MockAuthAppMockRecorder) GetVaultKey(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultKey", reflect.TypeOf((*MockAuthApp)(nil).GetVaultKey), email)
+}
+
+// ListSessions mocks base method.
+func (m *MockAuthApp) ListSessions(email string) ([]auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", email)
+	ret0, _ := ret[0].([]auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockAuthAppMockRecorder) ListSessions(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockAuthApp)(nil).ListSessions), email)
 }
 
 // Login mocks base method.
-func (m *MockAuthApp) Login(in auth.Credential) (string, error) {
+func (m *MockAuthApp) Login(in auth.Credential) (auth.Tokens, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Login", in)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(auth.Tokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -63,11 +151,40 @@ func (mr *MockAuthAppMockRecorder) Login(in interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthApp)(nil).Login), in)
 }
 
+// Logout mocks base method.
+func (m *MockAuthApp) Logout(email, session string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", email, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthAppMockRecorder) Logout(email, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthApp)(nil).Logout), email, session)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthApp) Refresh(refresh string) (auth.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", refresh)
+	ret0, _ := ret[0].(auth.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthAppMockRecorder) Refresh(refresh interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthApp)(nil).Refresh), refresh)
+}
+
 // Register mocks base method.
-func (m *MockAuthApp) Register(in auth.Credential) (string, error) {
+func (m *MockAuthApp) Register(in auth.Credential) (auth.Tokens, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Register", in)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(auth.Tokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -77,3 +194,46 @@ func (mr *MockAuthAppMockRecorder) Register(in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthApp)(nil).Register), in)
 }
+
+// RevokeSession mocks base method.
+func (m *MockAuthApp) RevokeSession(email, session string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", email, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockAuthAppMockRecorder) RevokeSession(email, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockAuthApp)(nil).RevokeSession), email, session)
+}
+
+// SetVaultKey mocks base method.
+func (m *MockAuthApp) SetVaultKey(email string, sealed []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVaultKey", email, sealed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVaultKey indicates an expected call of SetVaultKey.
+func (mr *MockAuthAppMockRecorder) SetVaultKey(email, sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVaultKey", reflect.TypeOf((*MockAuthApp)(nil).SetVaultKey), email, sealed)
+}
+
+// VerifyOTP mocks base method.
+func (m *MockAuthApp) VerifyOTP(challenge, code string) (auth.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyOTP", challenge, code)
+	ret0, _ := ret[0].(auth.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyOTP indicates an expected call of VerifyOTP.
+func (mr *MockAuthAppMockRecorder) VerifyOTP(challenge, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyOTP", reflect.TypeOf((*MockAuthApp)(nil).VerifyOTP), challenge, code)
+}
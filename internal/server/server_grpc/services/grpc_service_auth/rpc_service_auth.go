@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/app_services/app_service_auth"
 	"GophKeeper/internal/server/model/auth"
@@ -17,9 +18,20 @@ import (
 )
 
 type AuthApp interface {
-	Login(in auth.Credential) (string, error)
-	Register(in auth.Credential) (string, error)
-	ChangePassword(email, password string) error
+	Login(in auth.Credential) (auth.Tokens, error)
+	Register(in auth.Credential) (auth.Tokens, error)
+	Refresh(refresh string) (auth.Tokens, error)
+	Logout(email, session string) error
+	ListSessions(email string) ([]auth.Session, error)
+	RevokeSession(email, session string) error
+	ChangePassword(email, session, password string) error
+	GetVaultKey(email string) ([]byte, error)
+	SetVaultKey(email string, sealed []byte) error
+	Enroll2FA(email string) (string, []string, error)
+	Confirm2FA(email, code string) error
+	Disable2FA(email, code string) error
+	VerifyOTP(challenge, code string) (auth.Tokens, error)
+	DeleteAccount(email, password string) error
 }
 
 type AuthServiceRPC struct {
@@ -47,7 +59,7 @@ func (serv *AuthServiceRPC) Register(ctx context.Context, in *pb.AuthRequest) (*
 		Password: in.Password,
 	}
 
-	tokenStr, err := serv.auth.Register(cred)
+	tokens, err := serv.auth.Register(cred)
 	if err != nil {
 
 		if errors.Is(err, errs.ErrAlreadyExist) {
@@ -64,7 +76,8 @@ func (serv *AuthServiceRPC) Register(ctx context.Context, in *pb.AuthRequest) (*
 	}
 
 	return &pb.AuthResponse{
-		Token: tokenStr,
+		Token:        tokens.Access,
+		RefreshToken: tokens.Refresh,
 	}, nil
 }
 
@@ -76,22 +89,68 @@ func (serv *AuthServiceRPC) Login(ctx context.Context, in *pb.AuthRequest) (*pb.
 		Password: in.Password,
 	}
 
-	tkn, err := serv.auth.Login(cred)
+	tokens, err := serv.auth.Login(cred)
 	if err != nil {
 
 		if errors.Is(err, errs.ErrNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
 
-		if errors.Is(err, app_service_auth.ErrInvalidPassword) {
+		if errors.Is(err, app_service_auth.ErrInvalidPassword) ||
+			errors.Is(err, app_service_auth.ErrInvalidCredential) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.AuthResponse{
+		Token:        tokens.Access,
+		RefreshToken: tokens.Refresh,
+		Challenge:    tokens.Challenge,
+	}, nil
+}
+
+// VerifyOTP - Второй шаг входа пользователя с подключенным вторым фактором.
+func (serv *AuthServiceRPC) VerifyOTP(ctx context.Context, in *pb.VerifyOTPRequest) (*pb.AuthResponse, error) {
+
+	tokens, err := serv.auth.VerifyOTP(in.Challenge, in.Code)
+	if err != nil {
+
+		switch {
+		case errors.Is(err, app_service_auth.ErrInvalidOTP):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
+
+		case errors.Is(err, app_service_auth.ErrUnauthenticated):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
 		}
 
+		serv.logger.Error("failed verify otp", zap.Error(err))
 		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
 	}
 
 	return &pb.AuthResponse{
-		Token: tkn,
+		Token:        tokens.Access,
+		RefreshToken: tokens.Refresh,
+	}, nil
+}
+
+// Refresh - Обмен refresh token на новые токены пользователя.
+func (serv *AuthServiceRPC) Refresh(ctx context.Context, in *pb.RefreshRequest) (*pb.AuthResponse, error) {
+
+	tokens, err := serv.auth.Refresh(in.RefreshToken)
+	if err != nil {
+
+		if errors.Is(err, app_service_auth.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.AuthResponse{
+		Token:        tokens.Access,
+		RefreshToken: tokens.Refresh,
 	}, nil
 }
 
@@ -106,7 +165,10 @@ func (serv *AuthServiceRPC) ChangePassword(ctx context.Context, in *pb.ChangePas
 		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
 	}
 
-	if err := serv.auth.ChangePassword(email, string(in.Password)); err != nil {
+	// Текущая сессия остается открытой, остальные закрываются
+	session, _ := md_ctx.ValueFromContext(ctx, "session")
+
+	if err := serv.auth.ChangePassword(email, session, string(in.Password)); err != nil {
 
 		if errors.Is(err, app_service_auth.ErrShortPassword) {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -118,3 +180,246 @@ func (serv *AuthServiceRPC) ChangePassword(ctx context.Context, in *pb.ChangePas
 
 	return &pb.Empty{}, nil
 }
+
+// DeleteAccount - Удаление учетной записи текущего пользователя со всеми данными.
+// Требуется текущий пароль.
+func (serv *AuthServiceRPC) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest) (*pb.Empty, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serv.auth.DeleteAccount(email, in.Password); err != nil {
+
+		if errors.Is(err, app_service_auth.ErrInvalidPassword) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, app_service_auth.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		serv.logger.Error("failed delete account", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Logout - Завершение текущей сессии пользователя.
+func (serv *AuthServiceRPC) Logout(ctx context.Context, _ *pb.Empty) (*pb.Empty, error) {
+
+	email, session, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serv.auth.Logout(email, session); err != nil {
+
+		if errors.Is(err, app_service_auth.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		serv.logger.Error("failed logout", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ListSessions - Открытые сессии пользователя.
+func (serv *AuthServiceRPC) ListSessions(ctx context.Context, _ *pb.Empty) (*pb.ListSessionsResponse, error) {
+
+	email, current, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := serv.auth.ListSessions(email)
+	if err != nil {
+		serv.logger.Error("failed list sessions", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	resp := &pb.ListSessionsResponse{
+		Sessions: make([]*pb.Session, 0, len(sessions)),
+	}
+
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &pb.Session{
+			Id:        session.ID,
+			CreatedAt: timestamppb.New(session.CreatedAt),
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+			Current:   session.ID == current,
+		})
+	}
+
+	return resp, nil
+}
+
+// RevokeSession - Закрытие сессии пользователя по идентификатору.
+func (serv *AuthServiceRPC) RevokeSession(ctx context.Context, in *pb.RevokeSessionRequest) (*pb.Empty, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serv.auth.RevokeSession(email, in.Id); err != nil {
+
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed revoke session", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// GetVaultKey - Ключ хранилища пользователя, зашифрованный мастер-паролем.
+func (serv *AuthServiceRPC) GetVaultKey(ctx context.Context, _ *pb.Empty) (*pb.VaultKey, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := serv.auth.GetVaultKey(email)
+	if err != nil {
+
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed get vault key", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.VaultKey{SealedKey: sealed}, nil
+}
+
+// SetVaultKey - Сохранение ключа хранилища пользователя.
+func (serv *AuthServiceRPC) SetVaultKey(ctx context.Context, in *pb.VaultKey) (*pb.Empty, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serv.auth.SetVaultKey(email, in.SealedKey); err != nil {
+
+		switch {
+		case errors.Is(err, errs.ErrAlreadyExist):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+
+		case errors.Is(err, errs.ErrInvalidArgument):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+
+		case errors.Is(err, app_service_auth.ErrUnauthenticated):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		serv.logger.Error("failed set vault key", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Enroll2FA - Подключение второго фактора текущему пользователю.
+func (serv *AuthServiceRPC) Enroll2FA(ctx context.Context, _ *pb.Empty) (*pb.Enroll2FAResponse, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, recovery, err := serv.auth.Enroll2FA(email)
+	if err != nil {
+
+		switch {
+		case errors.Is(err, errs.ErrAlreadyExist):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+
+		case errors.Is(err, app_service_auth.ErrUnauthenticated):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		serv.logger.Error("failed enroll 2fa", zap.Error(err))
+		return nil, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Enroll2FAResponse{
+		Uri:           uri,
+		RecoveryCodes: recovery,
+	}, nil
+}
+
+// Confirm2FA - Подтверждение подключения второго фактора кодом из приложения.
+func (serv *AuthServiceRPC) Confirm2FA(ctx context.Context, in *pb.OTPRequest) (*pb.Empty, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serv.auth.Confirm2FA(email, in.Code); err != nil {
+		return nil, serv.otpError("failed confirm 2fa", err)
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Disable2FA - Отключение второго фактора.
+func (serv *AuthServiceRPC) Disable2FA(ctx context.Context, in *pb.OTPRequest) (*pb.Empty, error) {
+
+	email, _, err := serv.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = serv.auth.Disable2FA(email, in.Code); err != nil {
+		return nil, serv.otpError("failed disable 2fa", err)
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// otpError - Статус gRPC для ошибки проверки кода второго фактора.
+func (serv *AuthServiceRPC) otpError(msg string, err error) error {
+
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+
+	case errors.Is(err, errs.ErrAlreadyExist):
+		return status.Error(codes.AlreadyExists, err.Error())
+
+	case errors.Is(err, app_service_auth.ErrInvalidOTP):
+		return status.Error(codes.InvalidArgument, err.Error())
+
+	case errors.Is(err, app_service_auth.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	serv.logger.Error(msg, zap.Error(err))
+	return status.Error(codes.Internal, errs.ErrInternal.Error())
+}
+
+// session - Email пользователя и идентификатор сессии из метаданных ctx.
+func (serv *AuthServiceRPC) session(ctx context.Context) (string, string, error) {
+
+	email, ok := md_ctx.ValueFromContext(ctx, "email")
+	if !ok {
+		serv.logger.Error("failed found email in ctx metadata")
+		// Internal, т.к. Interceptor должен был положить email в ctx
+		return ``, ``, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	session, _ := md_ctx.ValueFromContext(ctx, "session")
+
+	return email, session, nil
+}
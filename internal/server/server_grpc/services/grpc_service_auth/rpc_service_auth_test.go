@@ -3,6 +3,7 @@ package grpc_service_auth
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -80,7 +81,7 @@ func TestAuthServiceRPC_Login(t *testing.T) {
 				Password: tt.in.Password,
 			}
 
-			authApp.EXPECT().Login(cred).Return(tokenStr, tt.errApp)
+			authApp.EXPECT().Login(cred).Return(auth.Tokens{Access: tokenStr, Refresh: "refresh"}, tt.errApp)
 
 			serv := NewAuthServiceRPC(authApp)
 			resp, err := serv.Login(context.Background(), tt.in)
@@ -91,6 +92,7 @@ func TestAuthServiceRPC_Login(t *testing.T) {
 				}
 			} else {
 				assert.Equal(t, resp.Token, tokenStr)
+				assert.Equal(t, "refresh", resp.RefreshToken)
 			}
 		})
 	}
@@ -155,7 +157,7 @@ func TestAuthServiceRPC_Register(t *testing.T) {
 				Password: tt.in.Password,
 			}
 
-			authApp.EXPECT().Register(cred).Return(tokenStr, tt.errApp)
+			authApp.EXPECT().Register(cred).Return(auth.Tokens{Access: tokenStr, Refresh: "refresh"}, tt.errApp)
 
 			serv := NewAuthServiceRPC(authApp)
 			resp, err := serv.Register(context.Background(), tt.in)
@@ -166,6 +168,7 @@ func TestAuthServiceRPC_Register(t *testing.T) {
 				}
 			} else {
 				assert.Equal(t, resp.Token, tokenStr)
+				assert.Equal(t, "refresh", resp.RefreshToken)
 			}
 		})
 	}
@@ -247,15 +250,15 @@ func TestAuthServiceRPC_ChangePassword(t *testing.T) {
 			ctx := context.Background()
 
 			if len(tt.email) != 0 {
-				md := metadata.New(map[string]string{"email": tt.email})
+				md := metadata.New(map[string]string{"email": tt.email, "session": "session"})
 				ctx = metadata.NewIncomingContext(ctx, md)
 			}
 
 			if tt.callApp {
 				if tt.errApp == nil {
-					authApp.EXPECT().ChangePassword(tt.email, tt.in.Password).Return(nil)
+					authApp.EXPECT().ChangePassword(tt.email, "session", tt.in.Password).Return(nil)
 				} else {
-					authApp.EXPECT().ChangePassword(tt.email, tt.in.Password).Return(tt.errApp)
+					authApp.EXPECT().ChangePassword(tt.email, "session", tt.in.Password).Return(tt.errApp)
 				}
 			}
 
@@ -272,3 +275,454 @@ func TestAuthServiceRPC_ChangePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthServiceRPC_Refresh(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Invalid refresh token",
+			errApp:   app_service_auth.ErrUnauthenticated,
+			wantErr:  true,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "Anomaly AppService",
+			errApp:   errs.ErrInternal,
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tokens := auth.Tokens{Access: "access", Refresh: "new refresh"}
+			authApp.EXPECT().Refresh("refresh").Return(tokens, tt.errApp)
+
+			serv := NewAuthServiceRPC(authApp)
+			resp, err := serv.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: "refresh"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tokens.Access, resp.Token)
+				assert.Equal(t, tokens.Refresh, resp.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRPC_Logout(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+	serv := NewAuthServiceRPC(authApp)
+
+	md := metadata.New(map[string]string{"email": "test@email.com", "session": "session"})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	authApp.EXPECT().Logout("test@email.com", "session").Return(nil)
+	_, err := serv.Logout(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	authApp.EXPECT().Logout("test@email.com", "session").Return(app_service_auth.ErrUnauthenticated)
+	_, err = serv.Logout(ctx, &pb.Empty{})
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, e.Code())
+
+	// Без email в метаданных
+	_, err = serv.Logout(context.Background(), &pb.Empty{})
+	e, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, e.Code())
+}
+
+func TestAuthServiceRPC_ListSessions(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+	serv := NewAuthServiceRPC(authApp)
+
+	md := metadata.New(map[string]string{"email": "test@email.com", "session": "current"})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	now := time.Now()
+	sessions := []auth.Session{
+		{ID: "current", Email: "test@email.com", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "other", Email: "test@email.com", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+	}
+
+	authApp.EXPECT().ListSessions("test@email.com").Return(sessions, nil)
+	resp, err := serv.ListSessions(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, resp.Sessions, 2)
+	assert.Equal(t, "current", resp.Sessions[0].Id)
+	assert.True(t, resp.Sessions[0].Current)
+	assert.False(t, resp.Sessions[1].Current)
+	assert.True(t, now.Equal(resp.Sessions[0].CreatedAt.AsTime()))
+
+	authApp.EXPECT().ListSessions("test@email.com").Return(nil, errs.ErrInternal)
+	_, err = serv.ListSessions(ctx, &pb.Empty{})
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, e.Code())
+}
+
+func TestAuthServiceRPC_RevokeSession(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly AppService",
+			errApp:   errs.ErrInternal,
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			md := metadata.New(map[string]string{"email": "test@email.com"})
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			authApp.EXPECT().RevokeSession("test@email.com", "other").Return(tt.errApp)
+
+			serv := NewAuthServiceRPC(authApp)
+			_, err := serv.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: "other"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRPC_GetVaultKey(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+	serv := NewAuthServiceRPC(authApp)
+
+	md := metadata.New(map[string]string{"email": "test@email.com"})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	authApp.EXPECT().GetVaultKey("test@email.com").Return([]byte("sealed"), nil)
+	resp, err := serv.GetVaultKey(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("sealed"), resp.SealedKey)
+
+	authApp.EXPECT().GetVaultKey("test@email.com").Return(nil, errs.ErrNotFound)
+	_, err = serv.GetVaultKey(ctx, &pb.Empty{})
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, e.Code())
+}
+
+func TestAuthServiceRPC_SetVaultKey(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Already exist",
+			errApp:   errs.ErrAlreadyExist,
+			wantErr:  true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "Empty key",
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly AppService",
+			errApp:   errs.ErrInternal,
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			md := metadata.New(map[string]string{"email": "test@email.com"})
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			authApp.EXPECT().SetVaultKey("test@email.com", []byte("sealed")).Return(tt.errApp)
+
+			serv := NewAuthServiceRPC(authApp)
+			_, err := serv.SetVaultKey(ctx, &pb.VaultKey{SealedKey: []byte("sealed")})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRPC_DeleteAccount(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Invalid password",
+			errApp:   app_service_auth.ErrInvalidPassword,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Deleted user",
+			errApp:   app_service_auth.ErrUnauthenticated,
+			wantErr:  true,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "Anomaly AppService",
+			errApp:   errs.ErrInternal,
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			md := metadata.New(map[string]string{"email": "test@email.com", "session": "session"})
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			authApp.EXPECT().DeleteAccount("test@email.com", "password").Return(tt.errApp)
+
+			serv := NewAuthServiceRPC(authApp)
+			_, err := serv.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRPC_LoginChallenge(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+	serv := NewAuthServiceRPC(authApp)
+
+	cred := auth.Credential{Email: "test@email.com", Password: "testPassword"}
+	authApp.EXPECT().Login(cred).Return(auth.Tokens{Challenge: "challenge"}, nil)
+
+	resp, err := serv.Login(context.Background(), &pb.AuthRequest{Email: cred.Email, Password: cred.Password})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Token)
+	assert.Equal(t, "challenge", resp.Challenge)
+}
+
+func TestAuthServiceRPC_VerifyOTP(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Invalid code",
+			errApp:   app_service_auth.ErrInvalidOTP,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Unknown challenge",
+			errApp:   app_service_auth.ErrUnauthenticated,
+			wantErr:  true,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "Anomaly AppService",
+			errApp:   errs.ErrInternal,
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tokens := auth.Tokens{Access: "access", Refresh: "refresh"}
+			authApp.EXPECT().VerifyOTP("challenge", "123456").Return(tokens, tt.errApp)
+
+			serv := NewAuthServiceRPC(authApp)
+			resp, err := serv.VerifyOTP(context.Background(), &pb.VerifyOTPRequest{Challenge: "challenge", Code: "123456"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tokens.Access, resp.Token)
+				assert.Equal(t, tokens.Refresh, resp.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRPC_TwoFactor(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	authApp := mock.NewMockAuthApp(ctrl)
+	serv := NewAuthServiceRPC(authApp)
+
+	md := metadata.New(map[string]string{"email": "test@email.com"})
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	authApp.EXPECT().Enroll2FA("test@email.com").Return("otpauth://totp/x", []string{"aaaa-bbbb"}, nil)
+	resp, err := serv.Enroll2FA(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/x", resp.Uri)
+	assert.Equal(t, []string{"aaaa-bbbb"}, resp.RecoveryCodes)
+
+	authApp.EXPECT().Enroll2FA("test@email.com").Return(``, nil, errs.ErrAlreadyExist)
+	_, err = serv.Enroll2FA(ctx, &pb.Empty{})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantCode codes.Code
+	}{
+		{
+			name:     "Success",
+			errApp:   nil,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Not enrolled",
+			errApp:   errs.ErrNotFound,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Invalid code",
+			errApp:   app_service_auth.ErrInvalidOTP,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly AppService",
+			errApp:   errs.ErrInternal,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			authApp.EXPECT().Confirm2FA("test@email.com", "123456").Return(tt.errApp)
+			_, err := serv.Confirm2FA(ctx, &pb.OTPRequest{Code: "123456"})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			authApp.EXPECT().Disable2FA("test@email.com", "123456").Return(tt.errApp)
+			_, err = serv.Disable2FA(ctx, &pb.OTPRequest{Code: "123456"})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
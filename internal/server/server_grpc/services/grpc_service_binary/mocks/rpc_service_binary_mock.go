@@ -6,6 +6,10 @@ package grpc_service_binary
 
 import (
 	binary "GophKeeper/internal/server/model/binary"
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
+	trash "GophKeeper/internal/server/model/trash"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,58 +39,189 @@ func (m *MockBinaryApp) EXPECT() *MockBinaryAppMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockBinaryApp) Change(in binary.DataFull) error {
+func (m *MockBinaryApp) Change(email string, in binary.DataFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockBinaryAppMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockBinaryAppMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockBinaryApp)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockBinaryApp)(nil).Change), email, in)
 }
 
 // Create mocks base method.
-func (m *MockBinaryApp) Create(in binary.DataFull) error {
+func (m *MockBinaryApp) Create(email string, in binary.DataFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", in)
+	ret := m.ctrl.Call(m, "Create", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockBinaryAppMockRecorder) Create(in interface{}) *gomock.Call {
+func (mr *MockBinaryAppMockRecorder) Create(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBinaryApp)(nil).Create), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBinaryApp)(nil).Create), email, in)
 }
 
 // Delete mocks base method.
-func (m *MockBinaryApp) Delete(in binary.DataGet) error {
+func (m *MockBinaryApp) Delete(email string, in binary.DataGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockBinaryAppMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockBinaryAppMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBinaryApp)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBinaryApp)(nil).Delete), email, in)
+}
+
+// Download mocks base method.
+func (m *MockBinaryApp) Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", email, in, w)
+	ret0, _ := ret[0].(binary.DataInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Download indicates an expected call of Download.
+func (mr *MockBinaryAppMockRecorder) Download(email, in, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockBinaryApp)(nil).Download), email, in, w)
 }
 
 // Get mocks base method.
-func (m *MockBinaryApp) Get(in binary.DataGet) (binary.DataFull, error) {
+func (m *MockBinaryApp) Get(email string, in binary.DataGet) (binary.DataFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(binary.DataFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockBinaryAppMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockBinaryAppMockRecorder) Get(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBinaryApp)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockBinaryApp) GetRevision(email string, in revision.Request) (binary.DataFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(binary.DataFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockBinaryAppMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockBinaryApp)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockBinaryApp) List(email string, in page.Request) (page.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].(page.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockBinaryAppMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockBinaryApp)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockBinaryApp) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockBinaryAppMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockBinaryApp)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockBinaryApp) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockBinaryAppMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockBinaryApp)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockBinaryApp) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockBinaryAppMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockBinaryApp)(nil).Purge), email, meta)
+}
+
+// Restore mocks base method.
+func (m *MockBinaryApp) Restore(email string, in revision.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockBinaryAppMockRecorder) Restore(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockBinaryApp)(nil).Restore), email, in)
+}
+
+// Undelete mocks base method.
+func (m *MockBinaryApp) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockBinaryAppMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockBinaryApp)(nil).Undelete), email, meta)
+}
+
+// Upload mocks base method.
+func (m *MockBinaryApp) Upload(email string, in binary.DataUpload, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", email, in, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockBinaryAppMockRecorder) Upload(email, in, r interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBinaryApp)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockBinaryApp)(nil).Upload), email, in, r)
 }
@@ -4,21 +4,36 @@ package grpc_service_binary
 import (
 	"context"
 	"errors"
+	"io"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/md_ctx"
 	pb "GophKeeper/pkg/proto/binary"
 )
 
 type BinaryApp interface {
-	Create(in binary.DataFull) error
-	Get(in binary.DataGet) (binary.DataFull, error)
-	Delete(in binary.DataGet) error
-	Change(in binary.DataFull) error
+	Create(email string, in binary.DataFull) error
+	Get(email string, in binary.DataGet) (binary.DataFull, error)
+	Delete(email string, in binary.DataGet) error
+	Change(email string, in binary.DataFull) error
+	List(email string, in page.Request) (page.Page, error)
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	GetRevision(email string, in revision.Request) (binary.DataFull, error)
+	Restore(email string, in revision.Request) error
+	ListTrash(email string) ([]trash.Item, error)
+	Undelete(email, meta string) error
+	Purge(email, meta string) error
+	Upload(email string, in binary.DataUpload, r io.Reader) error
+	Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error)
 }
 
 type BinaryServiceRPC struct {
@@ -41,12 +56,17 @@ func NewBinaryServiceRPC(credApp BinaryApp) *BinaryServiceRPC {
 // Create - Добавление новых данных.
 func (serv *BinaryServiceRPC) Create(ctx context.Context, in *pb.CreateRequest) (*pb.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
 	data := binary.DataFull{
 		MetaInfo: in.MetaInfo,
 		Bytes:    in.Data,
 	}
 
-	err := serv.credApp.Create(data)
+	err := serv.credApp.Create(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrAlreadyExist) {
 			return &pb.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
@@ -65,18 +85,32 @@ func (serv *BinaryServiceRPC) Create(ctx context.Context, in *pb.CreateRequest)
 // Change - Изменение существующих данных.
 func (serv *BinaryServiceRPC) Change(ctx context.Context, in *pb.ChangeRequest) (*pb.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
 	data := binary.DataFull{
 		MetaInfo: in.MetaInfo,
 		Bytes:    in.Data,
+		Version:  in.Version,
 	}
 
-	err := serv.credApp.Change(data)
+	err := serv.credApp.Change(email, data)
 	if err != nil {
 
 		if errors.Is(err, errs.ErrNotFound) {
 			return &pb.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &pb.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &pb.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed change binary data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -90,16 +124,30 @@ func (serv *BinaryServiceRPC) Change(ctx context.Context, in *pb.ChangeRequest)
 // Delete - Удаление существующих данных.
 func (serv *BinaryServiceRPC) Delete(ctx context.Context, in *pb.DeleteRequest) (*pb.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
 	data := binary.DataGet{
 		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
 	}
 
-	err := serv.credApp.Delete(data)
+	err := serv.credApp.Delete(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &pb.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &pb.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &pb.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed delete binary data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -113,11 +161,16 @@ func (serv *BinaryServiceRPC) Delete(ctx context.Context, in *pb.DeleteRequest)
 // Get - Получение данных по email и метаданным.
 func (serv *BinaryServiceRPC) Get(ctx context.Context, in *pb.GetRequest) (*pb.GetResponse, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.GetResponse{}, errEmail
+	}
+
 	inData := binary.DataGet{
 		MetaInfo: in.MetaInfo,
 	}
 
-	data, err := serv.credApp.Get(inData)
+	data, err := serv.credApp.Get(email, inData)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &pb.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
@@ -131,9 +184,335 @@ func (serv *BinaryServiceRPC) Get(ctx context.Context, in *pb.GetRequest) (*pb.G
 	}
 
 	out := &pb.GetResponse{
-		MetaInfo: data.MetaInfo,
-		Data:     data.Bytes,
+		MetaInfo:  data.MetaInfo,
+		Data:      data.Bytes,
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}
+
+	return out, nil
+}
+
+// List - Получение страницы списка метаинформации.
+func (serv *BinaryServiceRPC) List(ctx context.Context, in *pb.ListRequest) (*pb.ListResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.ListResponse{}, errEmail
+	}
+
+	req := page.Request{
+		Prefix:   in.Prefix,
+		Contains: in.Contains,
+		After:    in.PageToken,
+		Limit:    int(in.Limit),
+	}
+
+	list, err := serv.credApp.List(email, req)
+	if err != nil {
+		serv.logger.Error("failed list binary data",
+			zap.Error(err),
+			zap.String("prefix", in.Prefix),
+			zap.String("contains", in.Contains))
+
+		return &pb.ListResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.ListResponse{
+		MetaInfo:      list.MetaInfo,
+		Versions:      list.Versions,
+		NextPageToken: list.NextPageToken,
+	}, nil
+}
+
+// Upload - Потоковая загрузка данных.
+// Первое сообщение потока должно содержать метаинформацию, последнее - контрольную сумму.
+func (serv *BinaryServiceRPC) Upload(stream pb.BinaryService_UploadServer) error {
+
+	email, errEmail := serv.userEmail(stream.Context())
+	if errEmail != nil {
+		return errEmail
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return status.Errorf(codes.InvalidArgument, errs.ErrInvalidArgument.Error())
+		}
+		return err
+	}
+
+	if len(first.MetaInfo) == 0 {
+		return status.Errorf(codes.InvalidArgument, errs.ErrInvalidArgument.Error())
+	}
+
+	reader := newUploadReader(stream)
+	if err = reader.push(first); err != nil {
+		return status.Errorf(codes.DataLoss, err.Error())
+	}
+
+	data := binary.DataUpload{
+		MetaInfo:  first.MetaInfo,
+		Overwrite: first.Overwrite,
+		Version:   first.Version,
+	}
+
+	if err = serv.credApp.Upload(email, data, reader); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrAlreadyExist):
+			return status.Errorf(codes.AlreadyExists, err.Error())
+
+		case errors.Is(err, errs.ErrNotFound):
+			return status.Errorf(codes.NotFound, err.Error())
+
+		case errors.Is(err, errs.ErrConflict):
+			return status.Errorf(codes.Aborted, err.Error())
+
+		case errors.Is(err, errs.ErrInvalidArgument):
+			return status.Errorf(codes.InvalidArgument, err.Error())
+
+		case errors.Is(err, errs.ErrChecksum):
+			return status.Errorf(codes.DataLoss, err.Error())
+		}
+
+		serv.logger.Error("failed upload binary data",
+			zap.Error(err),
+			zap.String("meta", first.MetaInfo))
+
+		return status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return stream.SendAndClose(&pb.Empty{})
+}
+
+// Download - Потоковая выгрузка данных.
+// Последнее сообщение потока содержит контрольную сумму и версию данных.
+func (serv *BinaryServiceRPC) Download(in *pb.DownloadRequest, stream pb.BinaryService_DownloadServer) error {
+
+	email, errEmail := serv.userEmail(stream.Context())
+	if errEmail != nil {
+		return errEmail
+	}
+
+	data := binary.DataGet{
+		MetaInfo: in.MetaInfo,
+	}
+
+	writer := newDownloadWriter(stream)
+	info, err := serv.credApp.Download(email, data, writer)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed download binary data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return writer.finish(info)
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv *BinaryServiceRPC) ListRevisions(ctx context.Context, in *pb.ListRevisionsRequest) (*pb.ListRevisionsResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.ListRevisionsResponse{}, errEmail
+	}
+
+	revs, err := serv.credApp.ListRevisions(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &pb.ListRevisionsResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed list binary data revisions",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &pb.ListRevisionsResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &pb.ListRevisionsResponse{
+		Revisions: make([]*pb.Revision, 0, len(revs)),
+	}
+
+	for _, rev := range revs {
+		out.Revisions = append(out.Revisions, &pb.Revision{
+			Version:   rev.Version,
+			UpdatedAt: timestamppb.New(rev.UpdatedAt),
+		})
 	}
 
 	return out, nil
 }
+
+// GetRevision - Получение прежней версии данных.
+func (serv *BinaryServiceRPC) GetRevision(ctx context.Context, in *pb.GetRevisionRequest) (*pb.GetResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.GetResponse{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+	}
+
+	data, err := serv.credApp.GetRevision(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &pb.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed get binary data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &pb.GetResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.GetResponse{
+		MetaInfo:  data.MetaInfo,
+		Data:      data.Bytes,
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии как новой версии данных.
+func (serv *BinaryServiceRPC) Restore(ctx context.Context, in *pb.RestoreRequest) (*pb.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+		Current:  in.CurrentVersion,
+	}
+
+	err := serv.credApp.Restore(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &pb.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrConflict) {
+			return &pb.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &pb.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
+		serv.logger.Error("failed restore binary data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &pb.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv *BinaryServiceRPC) ListTrash(ctx context.Context, _ *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.ListTrashResponse{}, errEmail
+	}
+
+	items, err := serv.credApp.ListTrash(email)
+	if err != nil {
+		serv.logger.Error("failed list bin data trash", zap.Error(err))
+		return &pb.ListTrashResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &pb.ListTrashResponse{
+		Items: make([]*pb.TrashItem, 0, len(items)),
+	}
+
+	for _, item := range items {
+		out.Items = append(out.Items, &pb.TrashItem{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: timestamppb.New(item.DeletedAt),
+		})
+	}
+
+	return out, nil
+}
+
+// Undelete - Восстановление данных из корзины.
+func (serv *BinaryServiceRPC) Undelete(ctx context.Context, in *pb.UndeleteRequest) (*pb.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
+	err := serv.credApp.Undelete(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &pb.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrAlreadyExist) {
+			return &pb.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
+		}
+
+		serv.logger.Error("failed undelete bin data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &pb.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv *BinaryServiceRPC) Purge(ctx context.Context, in *pb.PurgeRequest) (*pb.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
+	err := serv.credApp.Purge(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &pb.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed purge bin data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &pb.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// userEmail - Получение email владельца данных из метаданных ctx.
+func (serv *BinaryServiceRPC) userEmail(ctx context.Context) (string, error) {
+
+	email, ok := md_ctx.ValueFromContext(ctx, "email")
+	if !ok {
+		serv.logger.Error("failed found email in ctx metadata")
+		// Internal, т.к. Interceptor должен был положить email в ctx
+		return ``, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return email, nil
+}
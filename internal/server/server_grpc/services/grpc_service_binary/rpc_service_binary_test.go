@@ -1,23 +1,45 @@
 package grpc_service_binary
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	mock "GophKeeper/internal/server/server_grpc/services/grpc_service_binary/mocks"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/binary"
 )
 
+// testEmail - Владелец данных, которого ValidateInterceptor кладет в метаданные.
+const testEmail = "test@email.com"
+
+// testUpdatedAt - Время последнего изменения данных.
+var testUpdatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// ownerContext - Контекст запроса от пользователя testEmail.
+func ownerContext() context.Context {
+	md := metadata.New(map[string]string{"email": testEmail})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
 func TestBinaryServiceRPC_Create(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -71,10 +93,10 @@ func TestBinaryServiceRPC_Create(t *testing.T) {
 				Bytes:    tt.in.Data,
 			}
 
-			binApp.EXPECT().Create(data).Return(tt.errApp)
+			binApp.EXPECT().Create(testEmail, data).Return(tt.errApp)
 
 			serv := NewBinaryServiceRPC(binApp)
-			_, err := serv.Create(context.Background(), tt.in)
+			_, err := serv.Create(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -120,6 +142,27 @@ func TestBinaryServiceRPC_Change(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Version conflict",
+			in: &pb.ChangeRequest{
+				MetaInfo: "desktop.bin",
+				Data:     []byte("010101"),
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			in: &pb.ChangeRequest{
+				MetaInfo: "desktop.bin",
+				Data:     []byte("010101"),
+			},
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
 		{
 			name: "Anomaly app service",
 			in: &pb.ChangeRequest{
@@ -138,12 +181,13 @@ func TestBinaryServiceRPC_Change(t *testing.T) {
 			data := binary.DataFull{
 				MetaInfo: tt.in.MetaInfo,
 				Bytes:    tt.in.Data,
+				Version:  tt.in.Version,
 			}
 
-			binApp.EXPECT().Change(data).Return(tt.errApp)
+			binApp.EXPECT().Change(testEmail, data).Return(tt.errApp)
 
 			serv := NewBinaryServiceRPC(binApp)
-			_, err := serv.Change(context.Background(), tt.in)
+			_, err := serv.Change(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -187,6 +231,25 @@ func TestBinaryServiceRPC_Delete(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Version conflict",
+			in: &pb.DeleteRequest{
+				MetaInfo: "desktop.bin",
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			in: &pb.DeleteRequest{
+				MetaInfo: "desktop.bin",
+			},
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
 		{
 			name: "Anomaly app service",
 			in: &pb.DeleteRequest{
@@ -203,12 +266,13 @@ func TestBinaryServiceRPC_Delete(t *testing.T) {
 
 			data := binary.DataGet{
 				MetaInfo: tt.in.MetaInfo,
+				Version:  tt.in.Version,
 			}
 
-			binApp.EXPECT().Delete(data).Return(tt.errApp)
+			binApp.EXPECT().Delete(testEmail, data).Return(tt.errApp)
 
 			serv := NewBinaryServiceRPC(binApp)
-			_, err := serv.Delete(context.Background(), tt.in)
+			_, err := serv.Delete(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -242,8 +306,10 @@ func TestBinaryServiceRPC_Get(t *testing.T) {
 				MetaInfo: "desktop.bin",
 			},
 			out: &pb.GetResponse{
-				MetaInfo: "desktop.bin",
-				Data:     []byte("010101"),
+				MetaInfo:  "desktop.bin",
+				Data:      []byte("010101"),
+				Version:   3,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
 			},
 			errApp:  nil,
 			wantErr: false,
@@ -276,13 +342,15 @@ func TestBinaryServiceRPC_Get(t *testing.T) {
 			}
 
 			outApp := binary.DataFull{
-				MetaInfo: tt.in.MetaInfo,
-				Bytes:    []byte("010101"),
+				MetaInfo:  tt.in.MetaInfo,
+				Bytes:     []byte("010101"),
+				Version:   3,
+				UpdatedAt: testUpdatedAt,
 			}
 
-			binApp.EXPECT().Get(data).Return(outApp, tt.errApp)
+			binApp.EXPECT().Get(testEmail, data).Return(outApp, tt.errApp)
 			serv := NewBinaryServiceRPC(binApp)
-			get, err := serv.Get(context.Background(), tt.in)
+			get, err := serv.Get(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -295,3 +363,671 @@ func TestBinaryServiceRPC_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestBinaryServiceRPC_List(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		in       *pb.ListRequest
+		outApp   page.Page
+		out      *pb.ListResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			in: &pb.ListRequest{
+				Prefix:    "mail",
+				Contains:  "ru",
+				PageToken: "mail.com",
+				Limit:     2,
+			},
+			outApp: page.Page{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			out: &pb.ListResponse{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			in:       &pb.ListRequest{},
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := page.Request{
+				Prefix:   tt.in.Prefix,
+				Contains: tt.in.Contains,
+				After:    tt.in.PageToken,
+				Limit:    int(tt.in.Limit),
+			}
+
+			binApp.EXPECT().List(testEmail, req).Return(tt.outApp, tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			out, err := serv.List(ownerContext(), tt.in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_NoOwner(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Без email в метаданных запрос не должен доходить до сервиса приложения
+	binApp := mock.NewMockBinaryApp(ctrl)
+	serv := NewBinaryServiceRPC(binApp)
+
+	_, err := serv.Get(context.Background(), &pb.GetRequest{MetaInfo: "desktop.bin"})
+
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, e.Code())
+}
+
+// testUploadStream - Поток Upload, отдающий заранее подготовленные сообщения.
+type testUploadStream struct {
+	grpc.ServerStream
+	msgs []*pb.UploadRequest
+}
+
+func (s *testUploadStream) Context() context.Context {
+	return ownerContext()
+}
+
+func (s *testUploadStream) Recv() (*pb.UploadRequest, error) {
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return msg, nil
+}
+
+func (s *testUploadStream) SendAndClose(*pb.Empty) error {
+	return nil
+}
+
+// testDownloadStream - Поток Download, накапливающий отправленные сообщения.
+type testDownloadStream struct {
+	grpc.ServerStream
+	msgs []*pb.DownloadResponse
+}
+
+func (s *testDownloadStream) Context() context.Context {
+	return ownerContext()
+}
+
+func (s *testDownloadStream) Send(msg *pb.DownloadResponse) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func TestBinaryServiceRPC_Upload(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	data := []byte("0101010101")
+	sum := sha256.Sum256(data)
+
+	tests := []struct {
+		name     string
+		msgs     []*pb.UploadRequest
+		callApp  bool
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			msgs: []*pb.UploadRequest{
+				{MetaInfo: "desktop.bin", Chunk: data[:4]},
+				{Chunk: data[4:], Checksum: sum[:]},
+			},
+			callApp: true,
+			wantErr: false,
+		},
+		{
+			name: "Invalid checksum",
+			msgs: []*pb.UploadRequest{
+				{MetaInfo: "desktop.bin", Chunk: data[:4]},
+				{Chunk: data[4:], Checksum: []byte("0000")},
+			},
+			callApp:  true,
+			wantErr:  true,
+			wantCode: codes.DataLoss,
+		},
+		{
+			name: "Without checksum",
+			msgs: []*pb.UploadRequest{
+				{MetaInfo: "desktop.bin", Chunk: data},
+			},
+			callApp:  true,
+			wantErr:  true,
+			wantCode: codes.DataLoss,
+		},
+		{
+			name: "Without meta",
+			msgs: []*pb.UploadRequest{
+				{Chunk: data, Checksum: sum[:]},
+			},
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Already exist",
+			msgs: []*pb.UploadRequest{
+				{MetaInfo: "desktop.bin", Chunk: data, Checksum: sum[:]},
+			},
+			callApp:  true,
+			errApp:   errs.ErrAlreadyExist,
+			wantErr:  true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name: "Version conflict",
+			msgs: []*pb.UploadRequest{
+				{MetaInfo: "desktop.bin", Overwrite: true, Version: 2, Chunk: data, Checksum: sum[:]},
+			},
+			callApp:  true,
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			msgs: []*pb.UploadRequest{
+				{MetaInfo: "desktop.bin", Overwrite: true, Chunk: data, Checksum: sum[:]},
+			},
+			callApp:  true,
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if tt.callApp {
+				upload := binary.DataUpload{
+					MetaInfo:  "desktop.bin",
+					Overwrite: tt.msgs[0].Overwrite,
+					Version:   tt.msgs[0].Version,
+				}
+
+				binApp.EXPECT().
+					Upload(testEmail, upload, gomock.Any()).
+					DoAndReturn(func(email string, in binary.DataUpload, r io.Reader) error {
+						// Хранилище читает данные до конца потока
+						got, err := io.ReadAll(r)
+						if err != nil {
+							return err
+						}
+
+						assert.Equal(t, data, got)
+						return tt.errApp
+					})
+			}
+
+			serv := NewBinaryServiceRPC(binApp)
+			err := serv.Upload(&testUploadStream{msgs: tt.msgs})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_Download(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	// Данные больше одной части, чтобы проверить разбиение
+	data := bytes.Repeat([]byte("01"), binary.ChunkSize)
+	sum := sha256.Sum256(data)
+
+	in := binary.DataGet{MetaInfo: "desktop.bin"}
+
+	binApp.EXPECT().
+		Download(testEmail, in, gomock.Any()).
+		DoAndReturn(func(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error) {
+			_, err := w.Write(data)
+			return binary.DataInfo{MetaInfo: in.MetaInfo, Version: 3, UpdatedAt: testUpdatedAt}, err
+		})
+
+	serv := NewBinaryServiceRPC(binApp)
+	stream := &testDownloadStream{}
+
+	err := serv.Download(&pb.DownloadRequest{MetaInfo: in.MetaInfo}, stream)
+	require.NoError(t, err)
+	require.Len(t, stream.msgs, 3)
+
+	var got []byte
+	for _, msg := range stream.msgs {
+		assert.LessOrEqual(t, len(msg.Chunk), binary.ChunkSize)
+		got = append(got, msg.Chunk...)
+	}
+
+	assert.Equal(t, data, got)
+	assert.Equal(t, sum[:], stream.msgs[2].Checksum)
+	assert.Equal(t, int64(3), stream.msgs[2].Version)
+	assert.Equal(t, testUpdatedAt, stream.msgs[2].UpdatedAt.AsTime())
+
+	// Данные не найдены
+	binApp.EXPECT().Download(testEmail, in, gomock.Any()).Return(binary.DataInfo{}, errs.ErrNotFound)
+
+	err = serv.Download(&pb.DownloadRequest{MetaInfo: in.MetaInfo}, &testDownloadStream{})
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, e.Code())
+}
+
+func TestBinaryServiceRPC_ListRevisions(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []revision.Revision
+		out      *pb.ListRevisionsResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []revision.Revision{{Version: 2, UpdatedAt: testUpdatedAt}, {Version: 1, UpdatedAt: testUpdatedAt}},
+			out: &pb.ListRevisionsResponse{
+				Revisions: []*pb.Revision{
+					{Version: 2, UpdatedAt: timestamppb.New(testUpdatedAt)},
+					{Version: 1, UpdatedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			binApp.EXPECT().ListRevisions(testEmail, "book1").Return(tt.outApp, tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			out, err := serv.ListRevisions(ownerContext(), &pb.ListRevisionsRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_GetRevision(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		out      *pb.GetResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			out: &pb.GetResponse{
+				MetaInfo:  "book1",
+				Data:      []byte("testData"),
+				Version:   1,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := revision.Request{
+				MetaInfo: "book1",
+				Version:  1,
+			}
+
+			outApp := binary.DataFull{
+				MetaInfo:  "book1",
+				Bytes:     []byte("testData"),
+				Version:   1,
+				UpdatedAt: testUpdatedAt,
+			}
+
+			binApp.EXPECT().GetRevision(testEmail, req).Return(outApp, tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			out, err := serv.GetRevision(ownerContext(), &pb.GetRevisionRequest{MetaInfo: "book1", Version: 1})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_Restore(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Version conflict",
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Missing version",
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			in := &pb.RestoreRequest{
+				MetaInfo:       "book1",
+				Version:        1,
+				CurrentVersion: 3,
+			}
+
+			req := revision.Request{
+				MetaInfo: in.MetaInfo,
+				Version:  in.Version,
+				Current:  in.CurrentVersion,
+			}
+
+			binApp.EXPECT().Restore(testEmail, req).Return(tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			_, err := serv.Restore(ownerContext(), in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_ListTrash(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []trash.Item
+		out      *pb.ListTrashResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []trash.Item{{MetaInfo: "book1", DeletedAt: testUpdatedAt}},
+			out: &pb.ListTrashResponse{
+				Items: []*pb.TrashItem{
+					{MetaInfo: "book1", DeletedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			binApp.EXPECT().ListTrash(testEmail).Return(tt.outApp, tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			out, err := serv.ListTrash(ownerContext(), &pb.ListTrashRequest{})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_Undelete(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Already exists",
+			errApp:   errs.ErrAlreadyExist,
+			wantErr:  true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			binApp.EXPECT().Undelete(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			_, err := serv.Undelete(ownerContext(), &pb.UndeleteRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBinaryServiceRPC_Purge(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binApp := mock.NewMockBinaryApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			binApp.EXPECT().Purge(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewBinaryServiceRPC(binApp)
+			_, err := serv.Purge(ownerContext(), &pb.PurgeRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package grpc_service_binary
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"io"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/pkg/errs"
+	pb "GophKeeper/pkg/proto/binary"
+)
+
+// uploadReader - io.Reader поверх потока Upload.
+// io.EOF возвращается только после успешной проверки контрольной суммы,
+// иначе - errs.ErrChecksum, чтобы хранилище не сохранило поврежденные данные.
+type uploadReader struct {
+	stream pb.BinaryService_UploadServer
+	hash   hash.Hash
+	chunk  []byte
+	done   bool
+}
+
+func newUploadReader(stream pb.BinaryService_UploadServer) *uploadReader {
+	return &uploadReader{
+		stream: stream,
+		hash:   sha256.New(),
+	}
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+
+	for len(r.chunk) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		msg, err := r.stream.Recv()
+		if err != nil {
+			// Клиент закрыл поток, не передав контрольную сумму
+			if err == io.EOF {
+				return 0, errs.ErrChecksum
+			}
+			return 0, err
+		}
+
+		if err = r.push(msg); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
+
+// push - Обработка очередного сообщения потока.
+func (r *uploadReader) push(msg *pb.UploadRequest) error {
+
+	r.hash.Write(msg.Chunk)
+	r.chunk = msg.Chunk
+
+	if len(msg.Checksum) != 0 {
+		if !bytes.Equal(msg.Checksum, r.hash.Sum(nil)) {
+			return errs.ErrChecksum
+		}
+		r.done = true
+	}
+
+	return nil
+}
+
+// downloadWriter - io.Writer поверх потока Download.
+// Данные отправляются частями не более binary.ChunkSize.
+type downloadWriter struct {
+	stream pb.BinaryService_DownloadServer
+	hash   hash.Hash
+}
+
+func newDownloadWriter(stream pb.BinaryService_DownloadServer) *downloadWriter {
+	return &downloadWriter{
+		stream: stream,
+		hash:   sha256.New(),
+	}
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+
+	written := 0
+	for len(p) > 0 {
+		size := len(p)
+		if size > binary.ChunkSize {
+			size = binary.ChunkSize
+		}
+
+		if err := w.stream.Send(&pb.DownloadResponse{Chunk: p[:size]}); err != nil {
+			return written, err
+		}
+
+		w.hash.Write(p[:size])
+		written += size
+		p = p[size:]
+	}
+
+	return written, nil
+}
+
+// finish - Отправка контрольной суммы всех переданных данных и версии данных.
+func (w *downloadWriter) finish(info binary.DataInfo) error {
+	return w.stream.Send(&pb.DownloadResponse{
+		Checksum:  w.hash.Sum(nil),
+		Version:   info.Version,
+		UpdatedAt: timestamppb.New(info.UpdatedAt),
+	})
+}
@@ -6,6 +6,9 @@ package grpc_service_card
 
 import (
 	card "GophKeeper/internal/server/model/card"
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
+	trash "GophKeeper/internal/server/model/trash"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,58 +38,160 @@ func (m *MockCardApp) EXPECT() *MockCardAppMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockCardApp) Change(in card.DataCardFull) error {
+func (m *MockCardApp) Change(email string, in card.DataCardFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockCardAppMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockCardAppMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockCardApp)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockCardApp)(nil).Change), email, in)
 }
 
 // Create mocks base method.
-func (m *MockCardApp) Create(data card.DataCardFull) error {
+func (m *MockCardApp) Create(email string, data card.DataCardFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", data)
+	ret := m.ctrl.Call(m, "Create", email, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockCardAppMockRecorder) Create(data interface{}) *gomock.Call {
+func (mr *MockCardAppMockRecorder) Create(email, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCardApp)(nil).Create), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCardApp)(nil).Create), email, data)
 }
 
 // Delete mocks base method.
-func (m *MockCardApp) Delete(in card.DataCardGet) error {
+func (m *MockCardApp) Delete(email string, in card.DataCardGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCardAppMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockCardAppMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCardApp)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCardApp)(nil).Delete), email, in)
 }
 
 // Get mocks base method.
-func (m *MockCardApp) Get(in card.DataCardGet) (card.DataCardFull, error) {
+func (m *MockCardApp) Get(email string, in card.DataCardGet) (card.DataCardFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(card.DataCardFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCardAppMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockCardAppMockRecorder) Get(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCardApp)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCardApp)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockCardApp) GetRevision(email string, in revision.Request) (card.DataCardFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(card.DataCardFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockCardAppMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockCardApp)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockCardApp) List(email string, in page.Request) (page.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].(page.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCardAppMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCardApp)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockCardApp) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockCardAppMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockCardApp)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockCardApp) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockCardAppMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockCardApp)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockCardApp) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockCardAppMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockCardApp)(nil).Purge), email, meta)
+}
+
+// Restore mocks base method.
+func (m *MockCardApp) Restore(email string, in revision.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockCardAppMockRecorder) Restore(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockCardApp)(nil).Restore), email, in)
+}
+
+// Undelete mocks base method.
+func (m *MockCardApp) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockCardAppMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockCardApp)(nil).Undelete), email, meta)
 }
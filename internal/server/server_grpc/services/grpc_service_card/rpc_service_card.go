@@ -8,18 +8,30 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/app_services/app_service_card"
 	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/md_ctx"
 	"GophKeeper/pkg/proto/card"
 )
 
 type CardApp interface {
-	Create(data card.DataCardFull) error
-	Get(in card.DataCardGet) (card.DataCardFull, error)
-	Delete(in card.DataCardGet) error
-	Change(in card.DataCardFull) error
+	Create(email string, data card.DataCardFull) error
+	Get(email string, in card.DataCardGet) (card.DataCardFull, error)
+	Delete(email string, in card.DataCardGet) error
+	Change(email string, in card.DataCardFull) error
+	List(email string, in page.Request) (page.Page, error)
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	GetRevision(email string, in revision.Request) (card.DataCardFull, error)
+	Restore(email string, in revision.Request) error
+	ListTrash(email string) ([]trash.Item, error)
+	Undelete(email, meta string) error
+	Purge(email, meta string) error
 }
 
 type CardServiceRPC struct {
@@ -42,6 +54,11 @@ func NewCardServiceRPC(cardApp app_service_card.CardApp) *CardServiceRPC {
 // Create - Добавление новых данных.
 func (serv *CardServiceRPC) Create(ctx context.Context, in *card_store.CreateRequest) (*card_store.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.Empty{}, errEmail
+	}
+
 	data := card.DataCardFull{
 		MetaInfo: in.MetaInfo,
 		Number:   string(in.Number),
@@ -50,7 +67,7 @@ func (serv *CardServiceRPC) Create(ctx context.Context, in *card_store.CreateReq
 		FullName: string(in.FullName),
 	}
 
-	err := serv.cardApp.Create(data)
+	err := serv.cardApp.Create(email, data)
 	if err != nil {
 
 		if errors.Is(err, errs.ErrAlreadyExist) {
@@ -70,20 +87,34 @@ func (serv *CardServiceRPC) Create(ctx context.Context, in *card_store.CreateReq
 // Change - Изменение существующих данных.
 func (serv *CardServiceRPC) Change(ctx context.Context, in *card_store.ChangeRequest) (*card_store.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.Empty{}, errEmail
+	}
+
 	data := card.DataCardFull{
 		MetaInfo: in.MetaInfo,
 		Number:   string(in.Number),
 		Period:   string(in.Period),
 		CVV:      string(in.CVV),
 		FullName: string(in.FullName),
+		Version:  in.Version,
 	}
 
-	err := serv.cardApp.Change(data)
+	err := serv.cardApp.Change(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &card_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &card_store.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &card_store.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed change card data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -97,17 +128,31 @@ func (serv *CardServiceRPC) Change(ctx context.Context, in *card_store.ChangeReq
 // Delete - Удаление существующих данных.
 func (serv *CardServiceRPC) Delete(ctx context.Context, in *card_store.DeleteRequest) (*card_store.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.Empty{}, errEmail
+	}
+
 	data := card.DataCardGet{
 		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
 	}
 
-	err := serv.cardApp.Delete(data)
+	err := serv.cardApp.Delete(email, data)
 	if err != nil {
 
 		if errors.Is(err, errs.ErrNotFound) {
 			return &card_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &card_store.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &card_store.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed delete card data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -121,11 +166,16 @@ func (serv *CardServiceRPC) Delete(ctx context.Context, in *card_store.DeleteReq
 // Get - Получение существующих данных.
 func (serv *CardServiceRPC) Get(ctx context.Context, in *card_store.GetRequest) (*card_store.GetResponse, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.GetResponse{}, errEmail
+	}
+
 	data := card.DataCardGet{
 		MetaInfo: in.MetaInfo,
 	}
 
-	get, err := serv.cardApp.Get(data)
+	get, err := serv.cardApp.Get(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &card_store.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
@@ -139,9 +189,247 @@ func (serv *CardServiceRPC) Get(ctx context.Context, in *card_store.GetRequest)
 	}
 
 	return &card_store.GetResponse{
-		Number:   []byte(get.Number),
-		Period:   []byte(get.Period),
-		CVV:      []byte(get.CVV),
-		FullName: []byte(get.FullName),
+		Number:    []byte(get.Number),
+		Period:    []byte(get.Period),
+		CVV:       []byte(get.CVV),
+		FullName:  []byte(get.FullName),
+		Version:   get.Version,
+		UpdatedAt: timestamppb.New(get.UpdatedAt),
 	}, nil
 }
+
+// List - Получение страницы списка метаинформации.
+func (serv *CardServiceRPC) List(ctx context.Context, in *card_store.ListRequest) (*card_store.ListResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.ListResponse{}, errEmail
+	}
+
+	req := page.Request{
+		Prefix:   in.Prefix,
+		Contains: in.Contains,
+		After:    in.PageToken,
+		Limit:    int(in.Limit),
+	}
+
+	list, err := serv.cardApp.List(email, req)
+	if err != nil {
+		serv.logger.Error("failed list card data",
+			zap.Error(err),
+			zap.String("prefix", in.Prefix),
+			zap.String("contains", in.Contains))
+
+		return &card_store.ListResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &card_store.ListResponse{
+		MetaInfo:      list.MetaInfo,
+		Versions:      list.Versions,
+		NextPageToken: list.NextPageToken,
+	}, nil
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv *CardServiceRPC) ListRevisions(ctx context.Context, in *card_store.ListRevisionsRequest) (*card_store.ListRevisionsResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.ListRevisionsResponse{}, errEmail
+	}
+
+	revs, err := serv.cardApp.ListRevisions(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &card_store.ListRevisionsResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed list card data revisions",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &card_store.ListRevisionsResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &card_store.ListRevisionsResponse{
+		Revisions: make([]*card_store.Revision, 0, len(revs)),
+	}
+
+	for _, rev := range revs {
+		out.Revisions = append(out.Revisions, &card_store.Revision{
+			Version:   rev.Version,
+			UpdatedAt: timestamppb.New(rev.UpdatedAt),
+		})
+	}
+
+	return out, nil
+}
+
+// GetRevision - Получение прежней версии данных.
+func (serv *CardServiceRPC) GetRevision(ctx context.Context, in *card_store.GetRevisionRequest) (*card_store.GetResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.GetResponse{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+	}
+
+	data, err := serv.cardApp.GetRevision(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &card_store.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed get card data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &card_store.GetResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &card_store.GetResponse{
+		Number:    []byte(data.Number),
+		Period:    []byte(data.Period),
+		CVV:       []byte(data.CVV),
+		FullName:  []byte(data.FullName),
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии как новой версии данных.
+func (serv *CardServiceRPC) Restore(ctx context.Context, in *card_store.RestoreRequest) (*card_store.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.Empty{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+		Current:  in.CurrentVersion,
+	}
+
+	err := serv.cardApp.Restore(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &card_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrConflict) {
+			return &card_store.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &card_store.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
+		serv.logger.Error("failed restore card data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &card_store.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &card_store.Empty{}, nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv *CardServiceRPC) ListTrash(ctx context.Context, _ *card_store.ListTrashRequest) (*card_store.ListTrashResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.ListTrashResponse{}, errEmail
+	}
+
+	items, err := serv.cardApp.ListTrash(email)
+	if err != nil {
+		serv.logger.Error("failed list card data trash", zap.Error(err))
+		return &card_store.ListTrashResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &card_store.ListTrashResponse{
+		Items: make([]*card_store.TrashItem, 0, len(items)),
+	}
+
+	for _, item := range items {
+		out.Items = append(out.Items, &card_store.TrashItem{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: timestamppb.New(item.DeletedAt),
+		})
+	}
+
+	return out, nil
+}
+
+// Undelete - Восстановление данных из корзины.
+func (serv *CardServiceRPC) Undelete(ctx context.Context, in *card_store.UndeleteRequest) (*card_store.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.Empty{}, errEmail
+	}
+
+	err := serv.cardApp.Undelete(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &card_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrAlreadyExist) {
+			return &card_store.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
+		}
+
+		serv.logger.Error("failed undelete card data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &card_store.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &card_store.Empty{}, nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv *CardServiceRPC) Purge(ctx context.Context, in *card_store.PurgeRequest) (*card_store.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &card_store.Empty{}, errEmail
+	}
+
+	err := serv.cardApp.Purge(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &card_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed purge card data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &card_store.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &card_store.Empty{}, nil
+}
+
+// userEmail - Получение email владельца данных из метаданных ctx.
+func (serv *CardServiceRPC) userEmail(ctx context.Context) (string, error) {
+
+	email, ok := md_ctx.ValueFromContext(ctx, "email")
+	if !ok {
+		serv.logger.Error("failed found email in ctx metadata")
+		// Internal, т.к. Interceptor должен был положить email в ctx
+		return ``, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return email, nil
+}
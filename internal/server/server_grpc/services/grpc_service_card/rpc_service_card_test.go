@@ -2,21 +2,40 @@ package grpc_service_card
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	mock "GophKeeper/internal/server/server_grpc/services/grpc_service_card/mocks"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/card"
 )
 
+// testEmail - Владелец данных, которого ValidateInterceptor кладет в метаданные.
+const testEmail = "test@email.com"
+
+// testUpdatedAt - Время последнего изменения данных.
+var testUpdatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// ownerContext - Контекст запроса от пользователя testEmail.
+func ownerContext() context.Context {
+	md := metadata.New(map[string]string{"email": testEmail})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
 func TestCardServiceRPC_Create(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -69,10 +88,10 @@ func TestCardServiceRPC_Create(t *testing.T) {
 				FullName: string(tt.in.FullName),
 			}
 
-			cardApp.EXPECT().Create(data).Return(tt.errApp)
+			cardApp.EXPECT().Create(testEmail, data).Return(tt.errApp)
 
 			serv := NewCardServiceRPC(cardApp)
-			_, err := serv.Create(context.Background(), tt.in)
+			_, err := serv.Create(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -124,6 +143,20 @@ func TestCardServiceRPC_Change(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Check version conflict",
+			in: &pb.ChangeRequest{
+				MetaInfo: "MirPay",
+				Number:   []byte("4648289760410976"),
+				Period:   []byte("10.2030"),
+				CVV:      []byte("111"),
+				FullName: []byte("Test Test"),
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
 	}
 
 	for _, tt := range tests {
@@ -135,12 +168,13 @@ func TestCardServiceRPC_Change(t *testing.T) {
 				Period:   string(tt.in.Period),
 				CVV:      string(tt.in.CVV),
 				FullName: string(tt.in.FullName),
+				Version:  tt.in.Version,
 			}
 
-			cardApp.EXPECT().Change(data).Return(tt.errApp)
+			cardApp.EXPECT().Change(testEmail, data).Return(tt.errApp)
 
 			serv := NewCardServiceRPC(cardApp)
-			_, err := serv.Change(context.Background(), tt.in)
+			_, err := serv.Change(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -184,6 +218,16 @@ func TestCardServiceRPC_Delete(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Check version conflict",
+			in: &pb.DeleteRequest{
+				MetaInfo: "MirPay",
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
 	}
 
 	for _, tt := range tests {
@@ -191,12 +235,13 @@ func TestCardServiceRPC_Delete(t *testing.T) {
 
 			data := card.DataCardGet{
 				MetaInfo: tt.in.MetaInfo,
+				Version:  tt.in.Version,
 			}
 
-			cardApp.EXPECT().Delete(data).Return(tt.errApp)
+			cardApp.EXPECT().Delete(testEmail, data).Return(tt.errApp)
 
 			serv := NewCardServiceRPC(cardApp)
-			_, err := serv.Delete(context.Background(), tt.in)
+			_, err := serv.Delete(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -231,16 +276,20 @@ func TestCardServiceRPC_Get(t *testing.T) {
 				MetaInfo: "MirPay",
 			},
 			out: &pb.GetResponse{
-				Number:   []byte("4648289760410976"),
-				Period:   []byte("10.2030"),
-				CVV:      []byte("111"),
-				FullName: []byte("Test Test"),
+				Number:    []byte("4648289760410976"),
+				Period:    []byte("10.2030"),
+				CVV:       []byte("111"),
+				FullName:  []byte("Test Test"),
+				Version:   3,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
 			},
 			outApp: card.DataCardFull{
-				Number:   "4648289760410976",
-				Period:   "10.2030",
-				CVV:      "111",
-				FullName: "Test Test",
+				Number:    "4648289760410976",
+				Period:    "10.2030",
+				CVV:       "111",
+				FullName:  "Test Test",
+				Version:   3,
+				UpdatedAt: testUpdatedAt,
 			},
 			errApp:  nil,
 			wantErr: false,
@@ -265,10 +314,10 @@ func TestCardServiceRPC_Get(t *testing.T) {
 				MetaInfo: tt.in.MetaInfo,
 			}
 
-			cardApp.EXPECT().Get(data).Return(tt.outApp, tt.errApp)
+			cardApp.EXPECT().Get(testEmail, data).Return(tt.outApp, tt.errApp)
 
 			serv := NewCardServiceRPC(cardApp)
-			out, err := serv.Get(context.Background(), tt.in)
+			out, err := serv.Get(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -281,3 +330,463 @@ func TestCardServiceRPC_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestCardServiceRPC_List(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		in       *pb.ListRequest
+		outApp   page.Page
+		out      *pb.ListResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			in: &pb.ListRequest{
+				Prefix:    "mail",
+				Contains:  "ru",
+				PageToken: "mail.com",
+				Limit:     2,
+			},
+			outApp: page.Page{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			out: &pb.ListResponse{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			in:       &pb.ListRequest{},
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := page.Request{
+				Prefix:   tt.in.Prefix,
+				Contains: tt.in.Contains,
+				After:    tt.in.PageToken,
+				Limit:    int(tt.in.Limit),
+			}
+
+			cardApp.EXPECT().List(testEmail, req).Return(tt.outApp, tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			out, err := serv.List(ownerContext(), tt.in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCardServiceRPC_NoOwner(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Без email в метаданных запрос не должен доходить до сервиса приложения
+	cardApp := mock.NewMockCardApp(ctrl)
+	serv := NewCardServiceRPC(cardApp)
+
+	_, err := serv.Get(context.Background(), &pb.GetRequest{MetaInfo: "MirPay"})
+
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, e.Code())
+}
+
+func TestCardServiceRPC_ListRevisions(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []revision.Revision
+		out      *pb.ListRevisionsResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []revision.Revision{{Version: 2, UpdatedAt: testUpdatedAt}, {Version: 1, UpdatedAt: testUpdatedAt}},
+			out: &pb.ListRevisionsResponse{
+				Revisions: []*pb.Revision{
+					{Version: 2, UpdatedAt: timestamppb.New(testUpdatedAt)},
+					{Version: 1, UpdatedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			cardApp.EXPECT().ListRevisions(testEmail, "book1").Return(tt.outApp, tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			out, err := serv.ListRevisions(ownerContext(), &pb.ListRevisionsRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCardServiceRPC_GetRevision(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		out      *pb.GetResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			out: &pb.GetResponse{
+				Number:    []byte("1111"),
+				Period:    []byte(""),
+				CVV:       []byte(""),
+				FullName:  []byte(""),
+				Version:   1,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := revision.Request{
+				MetaInfo: "book1",
+				Version:  1,
+			}
+
+			outApp := card.DataCardFull{
+				MetaInfo:  "book1",
+				Number:    "1111",
+				Version:   1,
+				UpdatedAt: testUpdatedAt,
+			}
+
+			cardApp.EXPECT().GetRevision(testEmail, req).Return(outApp, tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			out, err := serv.GetRevision(ownerContext(), &pb.GetRevisionRequest{MetaInfo: "book1", Version: 1})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCardServiceRPC_Restore(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Version conflict",
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Missing version",
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			in := &pb.RestoreRequest{
+				MetaInfo:       "book1",
+				Version:        1,
+				CurrentVersion: 3,
+			}
+
+			req := revision.Request{
+				MetaInfo: in.MetaInfo,
+				Version:  in.Version,
+				Current:  in.CurrentVersion,
+			}
+
+			cardApp.EXPECT().Restore(testEmail, req).Return(tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			_, err := serv.Restore(ownerContext(), in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCardServiceRPC_ListTrash(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []trash.Item
+		out      *pb.ListTrashResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []trash.Item{{MetaInfo: "book1", DeletedAt: testUpdatedAt}},
+			out: &pb.ListTrashResponse{
+				Items: []*pb.TrashItem{
+					{MetaInfo: "book1", DeletedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			cardApp.EXPECT().ListTrash(testEmail).Return(tt.outApp, tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			out, err := serv.ListTrash(ownerContext(), &pb.ListTrashRequest{})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCardServiceRPC_Undelete(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Already exists",
+			errApp:   errs.ErrAlreadyExist,
+			wantErr:  true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			cardApp.EXPECT().Undelete(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			_, err := serv.Undelete(ownerContext(), &pb.UndeleteRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCardServiceRPC_Purge(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cardApp := mock.NewMockCardApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			cardApp.EXPECT().Purge(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewCardServiceRPC(cardApp)
+			_, err := serv.Purge(ownerContext(), &pb.PurgeRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -6,6 +6,9 @@ package grpc_service_cred
 
 import (
 	cred "GophKeeper/internal/server/model/cred"
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
+	trash "GophKeeper/internal/server/model/trash"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,58 +38,160 @@ func (m *MockCredentialApp) EXPECT() *MockCredentialAppMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockCredentialApp) Change(in cred.CredentialFull) error {
+func (m *MockCredentialApp) Change(email string, in cred.CredentialFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockCredentialAppMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockCredentialAppMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockCredentialApp)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockCredentialApp)(nil).Change), email, in)
 }
 
 // Create mocks base method.
-func (m *MockCredentialApp) Create(in cred.CredentialFull) error {
+func (m *MockCredentialApp) Create(email string, in cred.CredentialFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", in)
+	ret := m.ctrl.Call(m, "Create", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockCredentialAppMockRecorder) Create(in interface{}) *gomock.Call {
+func (mr *MockCredentialAppMockRecorder) Create(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCredentialApp)(nil).Create), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCredentialApp)(nil).Create), email, in)
 }
 
 // Delete mocks base method.
-func (m *MockCredentialApp) Delete(in cred.CredentialGet) error {
+func (m *MockCredentialApp) Delete(email string, in cred.CredentialGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCredentialAppMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockCredentialAppMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCredentialApp)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCredentialApp)(nil).Delete), email, in)
 }
 
 // Get mocks base method.
-func (m *MockCredentialApp) Get(in cred.CredentialGet) (cred.CredentialFull, error) {
+func (m *MockCredentialApp) Get(email string, in cred.CredentialGet) (cred.CredentialFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(cred.CredentialFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCredentialAppMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockCredentialAppMockRecorder) Get(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCredentialApp)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCredentialApp)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockCredentialApp) GetRevision(email string, in revision.Request) (cred.CredentialFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(cred.CredentialFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockCredentialAppMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockCredentialApp)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockCredentialApp) List(email string, in page.Request) (page.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].(page.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCredentialAppMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCredentialApp)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockCredentialApp) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockCredentialAppMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockCredentialApp)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockCredentialApp) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockCredentialAppMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockCredentialApp)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockCredentialApp) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockCredentialAppMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockCredentialApp)(nil).Purge), email, meta)
+}
+
+// Restore mocks base method.
+func (m *MockCredentialApp) Restore(email string, in revision.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockCredentialAppMockRecorder) Restore(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockCredentialApp)(nil).Restore), email, in)
+}
+
+// Undelete mocks base method.
+func (m *MockCredentialApp) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockCredentialAppMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockCredentialApp)(nil).Undelete), email, meta)
 }
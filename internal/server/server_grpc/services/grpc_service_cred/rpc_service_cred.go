@@ -8,17 +8,29 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/md_ctx"
 	"GophKeeper/pkg/proto/credential"
 )
 
 type CredentialApp interface {
-	Create(in cred.CredentialFull) error
-	Get(in cred.CredentialGet) (cred.CredentialFull, error)
-	Delete(in cred.CredentialGet) error
-	Change(in cred.CredentialFull) error
+	Create(email string, in cred.CredentialFull) error
+	Get(email string, in cred.CredentialGet) (cred.CredentialFull, error)
+	Delete(email string, in cred.CredentialGet) error
+	Change(email string, in cred.CredentialFull) error
+	List(email string, in page.Request) (page.Page, error)
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	GetRevision(email string, in revision.Request) (cred.CredentialFull, error)
+	Restore(email string, in revision.Request) error
+	ListTrash(email string) ([]trash.Item, error)
+	Undelete(email, meta string) error
+	Purge(email, meta string) error
 }
 
 type CredServiceRPC struct {
@@ -41,13 +53,18 @@ func NewCredServiceRPC(credApp CredentialApp) *CredServiceRPC {
 // Create - Добавление новых данных.
 func (serv *CredServiceRPC) Create(ctx context.Context, in *credential.CreateRequest) (*credential.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.Empty{}, errEmail
+	}
+
 	data := cred.CredentialFull{
 		MetaInfo: in.MetaInfo,
 		Email:    string(in.Email),
 		Password: string(in.Password),
 	}
 
-	err := serv.credApp.Create(data)
+	err := serv.credApp.Create(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrAlreadyExist) {
 			return &credential.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
@@ -68,18 +85,32 @@ func (serv *CredServiceRPC) Create(ctx context.Context, in *credential.CreateReq
 // Change - Изменение существующих данных.
 func (serv *CredServiceRPC) Change(ctx context.Context, in *credential.ChangeRequest) (*credential.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.Empty{}, errEmail
+	}
+
 	data := cred.CredentialFull{
 		MetaInfo: in.MetaInfo,
 		Email:    string(in.Email),
 		Password: string(in.Password),
+		Version:  in.Version,
 	}
 
-	err := serv.credApp.Change(data)
+	err := serv.credApp.Change(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &credential.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &credential.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &credential.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed change credential data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo),
@@ -95,16 +126,30 @@ func (serv *CredServiceRPC) Change(ctx context.Context, in *credential.ChangeReq
 // Delete - Удаление существующих данных.
 func (serv *CredServiceRPC) Delete(ctx context.Context, in *credential.DeleteRequest) (*credential.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.Empty{}, errEmail
+	}
+
 	data := cred.CredentialGet{
 		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
 	}
 
-	err := serv.credApp.Delete(data)
+	err := serv.credApp.Delete(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &credential.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &credential.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &credential.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed delete credential data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -118,11 +163,16 @@ func (serv *CredServiceRPC) Delete(ctx context.Context, in *credential.DeleteReq
 // Get - Получение данных по email и метаданным.
 func (serv *CredServiceRPC) Get(ctx context.Context, in *credential.GetRequest) (*credential.GetResponse, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.GetResponse{}, errEmail
+	}
+
 	inData := cred.CredentialGet{
 		MetaInfo: in.MetaInfo,
 	}
 
-	data, err := serv.credApp.Get(inData)
+	data, err := serv.credApp.Get(email, inData)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &credential.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
@@ -136,9 +186,245 @@ func (serv *CredServiceRPC) Get(ctx context.Context, in *credential.GetRequest)
 	}
 
 	out := &credential.GetResponse{
-		Email:    []byte(data.Email),
-		Password: []byte(data.Password),
+		Email:     []byte(data.Email),
+		Password:  []byte(data.Password),
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}
+
+	return out, nil
+}
+
+// List - Получение страницы списка метаинформации.
+func (serv *CredServiceRPC) List(ctx context.Context, in *credential.ListRequest) (*credential.ListResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.ListResponse{}, errEmail
+	}
+
+	req := page.Request{
+		Prefix:   in.Prefix,
+		Contains: in.Contains,
+		After:    in.PageToken,
+		Limit:    int(in.Limit),
+	}
+
+	list, err := serv.credApp.List(email, req)
+	if err != nil {
+		serv.logger.Error("failed list credential data",
+			zap.Error(err),
+			zap.String("prefix", in.Prefix),
+			zap.String("contains", in.Contains))
+
+		return &credential.ListResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &credential.ListResponse{
+		MetaInfo:      list.MetaInfo,
+		Versions:      list.Versions,
+		NextPageToken: list.NextPageToken,
+	}, nil
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv *CredServiceRPC) ListRevisions(ctx context.Context, in *credential.ListRevisionsRequest) (*credential.ListRevisionsResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.ListRevisionsResponse{}, errEmail
+	}
+
+	revs, err := serv.credApp.ListRevisions(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &credential.ListRevisionsResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed list cred data revisions",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &credential.ListRevisionsResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &credential.ListRevisionsResponse{
+		Revisions: make([]*credential.Revision, 0, len(revs)),
+	}
+
+	for _, rev := range revs {
+		out.Revisions = append(out.Revisions, &credential.Revision{
+			Version:   rev.Version,
+			UpdatedAt: timestamppb.New(rev.UpdatedAt),
+		})
+	}
+
+	return out, nil
+}
+
+// GetRevision - Получение прежней версии данных.
+func (serv *CredServiceRPC) GetRevision(ctx context.Context, in *credential.GetRevisionRequest) (*credential.GetResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.GetResponse{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+	}
+
+	data, err := serv.credApp.GetRevision(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &credential.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed get cred data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &credential.GetResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &credential.GetResponse{
+		Email:     []byte(data.Email),
+		Password:  []byte(data.Password),
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии как новой версии данных.
+func (serv *CredServiceRPC) Restore(ctx context.Context, in *credential.RestoreRequest) (*credential.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.Empty{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+		Current:  in.CurrentVersion,
+	}
+
+	err := serv.credApp.Restore(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &credential.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrConflict) {
+			return &credential.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &credential.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
+		serv.logger.Error("failed restore cred data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &credential.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &credential.Empty{}, nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv *CredServiceRPC) ListTrash(ctx context.Context, _ *credential.ListTrashRequest) (*credential.ListTrashResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.ListTrashResponse{}, errEmail
+	}
+
+	items, err := serv.credApp.ListTrash(email)
+	if err != nil {
+		serv.logger.Error("failed list cred data trash", zap.Error(err))
+		return &credential.ListTrashResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &credential.ListTrashResponse{
+		Items: make([]*credential.TrashItem, 0, len(items)),
+	}
+
+	for _, item := range items {
+		out.Items = append(out.Items, &credential.TrashItem{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: timestamppb.New(item.DeletedAt),
+		})
 	}
 
 	return out, nil
 }
+
+// Undelete - Восстановление данных из корзины.
+func (serv *CredServiceRPC) Undelete(ctx context.Context, in *credential.UndeleteRequest) (*credential.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.Empty{}, errEmail
+	}
+
+	err := serv.credApp.Undelete(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &credential.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrAlreadyExist) {
+			return &credential.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
+		}
+
+		serv.logger.Error("failed undelete cred data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &credential.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &credential.Empty{}, nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv *CredServiceRPC) Purge(ctx context.Context, in *credential.PurgeRequest) (*credential.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &credential.Empty{}, errEmail
+	}
+
+	err := serv.credApp.Purge(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &credential.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed purge cred data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &credential.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &credential.Empty{}, nil
+}
+
+// userEmail - Получение email владельца данных из метаданных ctx.
+func (serv *CredServiceRPC) userEmail(ctx context.Context) (string, error) {
+
+	email, ok := md_ctx.ValueFromContext(ctx, "email")
+	if !ok {
+		serv.logger.Error("failed found email in ctx metadata")
+		// Internal, т.к. Interceptor должен был положить email в ctx
+		return ``, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return email, nil
+}
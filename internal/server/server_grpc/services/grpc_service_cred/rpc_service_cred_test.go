@@ -4,19 +4,37 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 	mock "GophKeeper/internal/server/server_grpc/services/grpc_service_cred/mocks"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/credential"
 )
 
+// testEmail - Владелец данных, которого ValidateInterceptor кладет в метаданные.
+const testEmail = "test@email.com"
+
+// testUpdatedAt - Время последнего изменения данных.
+var testUpdatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// ownerContext - Контекст запроса от пользователя testEmail.
+func ownerContext() context.Context {
+	md := metadata.New(map[string]string{"email": testEmail})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
 func TestCredServiceRPC_Create(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -74,10 +92,10 @@ func TestCredServiceRPC_Create(t *testing.T) {
 				Password: string(tt.in.Password),
 			}
 
-			credApp.EXPECT().Create(data).Return(tt.errApp)
+			credApp.EXPECT().Create(testEmail, data).Return(tt.errApp)
 
 			serv := NewCredServiceRPC(credApp)
-			_, err := serv.Create(context.Background(), tt.in)
+			_, err := serv.Create(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -125,6 +143,29 @@ func TestCredServiceRPC_Change(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Version conflict",
+			in: &pb.ChangeRequest{
+				MetaInfo: "www.test.ru",
+				Email:    []byte("test@email.com"),
+				Password: []byte("testPwd"),
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			in: &pb.ChangeRequest{
+				MetaInfo: "www.test.ru",
+				Email:    []byte("test@email.com"),
+				Password: []byte("testPwd"),
+			},
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
 		{
 			name: "Anomaly app service",
 			in: &pb.ChangeRequest{
@@ -145,12 +186,13 @@ func TestCredServiceRPC_Change(t *testing.T) {
 				MetaInfo: tt.in.MetaInfo,
 				Email:    string(tt.in.Email),
 				Password: string(tt.in.Password),
+				Version:  tt.in.Version,
 			}
 
-			credApp.EXPECT().Change(data).Return(tt.errApp)
+			credApp.EXPECT().Change(testEmail, data).Return(tt.errApp)
 
 			serv := NewCredServiceRPC(credApp)
-			_, err := serv.Change(context.Background(), tt.in)
+			_, err := serv.Change(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -194,6 +236,25 @@ func TestCredServiceRPC_Delete(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Version conflict",
+			in: &pb.DeleteRequest{
+				MetaInfo: "www.test.ru",
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			in: &pb.DeleteRequest{
+				MetaInfo: "www.test.ru",
+			},
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
 		{
 			name: "Anomaly app service",
 			in: &pb.DeleteRequest{
@@ -210,12 +271,13 @@ func TestCredServiceRPC_Delete(t *testing.T) {
 
 			data := cred.CredentialGet{
 				MetaInfo: tt.in.MetaInfo,
+				Version:  tt.in.Version,
 			}
 
-			credApp.EXPECT().Delete(data).Return(tt.errApp)
+			credApp.EXPECT().Delete(testEmail, data).Return(tt.errApp)
 
 			serv := NewCredServiceRPC(credApp)
-			_, err := serv.Delete(context.Background(), tt.in)
+			_, err := serv.Delete(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -249,8 +311,10 @@ func TestCredServiceRPC_Get(t *testing.T) {
 				MetaInfo: "www.test.ru",
 			},
 			out: &pb.GetResponse{
-				Email:    []byte("test@email.com"),
-				Password: []byte("testPwd"),
+				Email:     []byte("test@email.com"),
+				Password:  []byte("testPwd"),
+				Version:   3,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
 			},
 			errApp:  nil,
 			wantErr: false,
@@ -286,16 +350,18 @@ func TestCredServiceRPC_Get(t *testing.T) {
 
 			if tt.out != nil {
 				outApp = cred.CredentialFull{
-					MetaInfo: tt.in.MetaInfo,
-					Email:    string(tt.out.Email),
-					Password: string(tt.out.Password),
+					MetaInfo:  tt.in.MetaInfo,
+					Email:     string(tt.out.Email),
+					Password:  string(tt.out.Password),
+					Version:   3,
+					UpdatedAt: testUpdatedAt,
 				}
 			}
 
-			credApp.EXPECT().Get(data).Return(outApp, tt.errApp)
+			credApp.EXPECT().Get(testEmail, data).Return(outApp, tt.errApp)
 
 			serv := NewCredServiceRPC(credApp)
-			get, err := serv.Get(context.Background(), tt.in)
+			get, err := serv.Get(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -308,3 +374,461 @@ func TestCredServiceRPC_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestCredServiceRPC_List(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		in       *pb.ListRequest
+		outApp   page.Page
+		out      *pb.ListResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			in: &pb.ListRequest{
+				Prefix:    "mail",
+				Contains:  "ru",
+				PageToken: "mail.com",
+				Limit:     2,
+			},
+			outApp: page.Page{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			out: &pb.ListResponse{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			in:       &pb.ListRequest{},
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := page.Request{
+				Prefix:   tt.in.Prefix,
+				Contains: tt.in.Contains,
+				After:    tt.in.PageToken,
+				Limit:    int(tt.in.Limit),
+			}
+
+			credApp.EXPECT().List(testEmail, req).Return(tt.outApp, tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			out, err := serv.List(ownerContext(), tt.in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCredServiceRPC_NoOwner(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Без email в метаданных запрос не должен доходить до сервиса приложения
+	credApp := mock.NewMockCredentialApp(ctrl)
+	serv := NewCredServiceRPC(credApp)
+
+	_, err := serv.Get(context.Background(), &pb.GetRequest{MetaInfo: "www.test.ru"})
+
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, e.Code())
+}
+
+func TestCredServiceRPC_ListRevisions(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []revision.Revision
+		out      *pb.ListRevisionsResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []revision.Revision{{Version: 2, UpdatedAt: testUpdatedAt}, {Version: 1, UpdatedAt: testUpdatedAt}},
+			out: &pb.ListRevisionsResponse{
+				Revisions: []*pb.Revision{
+					{Version: 2, UpdatedAt: timestamppb.New(testUpdatedAt)},
+					{Version: 1, UpdatedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			credApp.EXPECT().ListRevisions(testEmail, "book1").Return(tt.outApp, tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			out, err := serv.ListRevisions(ownerContext(), &pb.ListRevisionsRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCredServiceRPC_GetRevision(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		out      *pb.GetResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			out: &pb.GetResponse{
+				Email:     []byte(""),
+				Password:  []byte("qwerty"),
+				Version:   1,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := revision.Request{
+				MetaInfo: "book1",
+				Version:  1,
+			}
+
+			outApp := cred.CredentialFull{
+				MetaInfo:  "book1",
+				Password:  "qwerty",
+				Version:   1,
+				UpdatedAt: testUpdatedAt,
+			}
+
+			credApp.EXPECT().GetRevision(testEmail, req).Return(outApp, tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			out, err := serv.GetRevision(ownerContext(), &pb.GetRevisionRequest{MetaInfo: "book1", Version: 1})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCredServiceRPC_Restore(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Version conflict",
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Missing version",
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			in := &pb.RestoreRequest{
+				MetaInfo:       "book1",
+				Version:        1,
+				CurrentVersion: 3,
+			}
+
+			req := revision.Request{
+				MetaInfo: in.MetaInfo,
+				Version:  in.Version,
+				Current:  in.CurrentVersion,
+			}
+
+			credApp.EXPECT().Restore(testEmail, req).Return(tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			_, err := serv.Restore(ownerContext(), in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCredServiceRPC_ListTrash(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []trash.Item
+		out      *pb.ListTrashResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []trash.Item{{MetaInfo: "book1", DeletedAt: testUpdatedAt}},
+			out: &pb.ListTrashResponse{
+				Items: []*pb.TrashItem{
+					{MetaInfo: "book1", DeletedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			credApp.EXPECT().ListTrash(testEmail).Return(tt.outApp, tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			out, err := serv.ListTrash(ownerContext(), &pb.ListTrashRequest{})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestCredServiceRPC_Undelete(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Already exists",
+			errApp:   errs.ErrAlreadyExist,
+			wantErr:  true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			credApp.EXPECT().Undelete(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			_, err := serv.Undelete(ownerContext(), &pb.UndeleteRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCredServiceRPC_Purge(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credApp := mock.NewMockCredentialApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			credApp.EXPECT().Purge(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewCredServiceRPC(credApp)
+			_, err := serv.Purge(ownerContext(), &pb.PurgeRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: rpc_service_rotation.go
+
+// Package grpc_service_rotation is a generated GoMock package.
+package grpc_service_rotation
+
+import (
+	binary "GophKeeper/internal/server/model/binary"
+	card "GophKeeper/internal/server/model/card"
+	cred "GophKeeper/internal/server/model/cred"
+	rotation "GophKeeper/internal/server/model/rotation"
+	text "GophKeeper/internal/server/model/text"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRotationApp is a mock of RotationApp interface.
+type MockRotationApp struct {
+	ctrl     *gomock.Controller
+	recorder *MockRotationAppMockRecorder
+}
+
+// MockRotationAppMockRecorder is the mock recorder for MockRotationApp.
+type MockRotationAppMockRecorder struct {
+	mock *MockRotationApp
+}
+
+// NewMockRotationApp creates a new mock instance.
+func NewMockRotationApp(ctrl *gomock.Controller) *MockRotationApp {
+	mock := &MockRotationApp{ctrl: ctrl}
+	mock.recorder = &MockRotationAppMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRotationApp) EXPECT() *MockRotationAppMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockRotationApp) Begin(email string, in rotation.Rotation) (rotation.Rotation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", email, in)
+	ret0, _ := ret[0].(rotation.Rotation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockRotationAppMockRecorder) Begin(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockRotationApp)(nil).Begin), email, in)
+}
+
+// Finish mocks base method.
+func (m *MockRotationApp) Finish(email, keyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", email, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockRotationAppMockRecorder) Finish(email, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockRotationApp)(nil).Finish), email, keyID)
+}
+
+// Get mocks base method.
+func (m *MockRotationApp) Get(email string) (rotation.Rotation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", email)
+	ret0, _ := ret[0].(rotation.Rotation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRotationAppMockRecorder) Get(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRotationApp)(nil).Get), email)
+}
+
+// UpdateBinaries mocks base method.
+func (m *MockRotationApp) UpdateBinaries(email, keyID string, in []binary.DataFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBinaries", email, keyID, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBinaries indicates an expected call of UpdateBinaries.
+func (mr *MockRotationAppMockRecorder) UpdateBinaries(email, keyID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBinaries", reflect.TypeOf((*MockRotationApp)(nil).UpdateBinaries), email, keyID, in)
+}
+
+// UpdateBinary mocks base method.
+func (m *MockRotationApp) UpdateBinary(email, keyID string, in binary.DataUpload, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBinary", email, keyID, in, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBinary indicates an expected call of UpdateBinary.
+func (mr *MockRotationAppMockRecorder) UpdateBinary(email, keyID, in, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBinary", reflect.TypeOf((*MockRotationApp)(nil).UpdateBinary), email, keyID, in, r)
+}
+
+// UpdateCards mocks base method.
+func (m *MockRotationApp) UpdateCards(email, keyID string, in []card.DataCardFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCards", email, keyID, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCards indicates an expected call of UpdateCards.
+func (mr *MockRotationAppMockRecorder) UpdateCards(email, keyID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCards", reflect.TypeOf((*MockRotationApp)(nil).UpdateCards), email, keyID, in)
+}
+
+// UpdateCreds mocks base method.
+func (m *MockRotationApp) UpdateCreds(email, keyID string, in []cred.CredentialFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCreds", email, keyID, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCreds indicates an expected call of UpdateCreds.
+func (mr *MockRotationAppMockRecorder) UpdateCreds(email, keyID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCreds", reflect.TypeOf((*MockRotationApp)(nil).UpdateCreds), email, keyID, in)
+}
+
+// UpdateTexts mocks base method.
+func (m *MockRotationApp) UpdateTexts(email, keyID string, in []text.DataTextFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTexts", email, keyID, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTexts indicates an expected call of UpdateTexts.
+func (mr *MockRotationAppMockRecorder) UpdateTexts(email, keyID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTexts", reflect.TypeOf((*MockRotationApp)(nil).UpdateTexts), email, keyID, in)
+}
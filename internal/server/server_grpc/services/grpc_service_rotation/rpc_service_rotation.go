@@ -0,0 +1,247 @@
+//go:generate mockgen -source rpc_service_rotation.go -destination mocks/rpc_service_rotation_mock.go -package grpc_service_rotation
+package grpc_service_rotation
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/internal/server/model/text"
+	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/md_ctx"
+	pb "GophKeeper/pkg/proto/rotation"
+)
+
+type RotationApp interface {
+	Begin(email string, in rotation.Rotation) (rotation.Rotation, error)
+	Get(email string) (rotation.Rotation, error)
+	Finish(email, keyID string) error
+	UpdateTexts(email, keyID string, in []text.DataTextFull) error
+	UpdateCreds(email, keyID string, in []cred.CredentialFull) error
+	UpdateCards(email, keyID string, in []card.DataCardFull) error
+	UpdateBinaries(email, keyID string, in []binary.DataFull) error
+	UpdateBinary(email, keyID string, in binary.DataUpload, r io.Reader) error
+}
+
+type RotationServiceRPC struct {
+	pb.RotationServiceServer
+
+	rotationApp RotationApp
+	logger      *zap.Logger
+}
+
+// NewRotationServiceRPC - Создание экземпляра gRPC сервиса смены ключа шифрования.
+func NewRotationServiceRPC(rotationApp RotationApp) *RotationServiceRPC {
+	return &RotationServiceRPC{
+		rotationApp: rotationApp,
+		logger:      zap.L(),
+	}
+}
+
+// Begin - Начало или продолжение смены ключа.
+func (serv *RotationServiceRPC) Begin(ctx context.Context, in *pb.BeginRequest) (*pb.Rotation, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Rotation{}, errEmail
+	}
+
+	data, err := serv.rotationApp.Begin(email, rotation.Rotation{KeyID: in.KeyId, SealedKey: in.SealedKey})
+	if err != nil {
+		return &pb.Rotation{}, serv.statusError(err, "failed begin key rotation")
+	}
+
+	return rotationResponse(data), nil
+}
+
+// Get - Получение незавершенной смены ключа.
+func (serv *RotationServiceRPC) Get(ctx context.Context, _ *pb.Empty) (*pb.Rotation, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Rotation{}, errEmail
+	}
+
+	data, err := serv.rotationApp.Get(email)
+	if err != nil {
+		return &pb.Rotation{}, serv.statusError(err, "failed get key rotation")
+	}
+
+	return rotationResponse(data), nil
+}
+
+// BatchUpdate - Запись пакета перешифрованных записей.
+func (serv *RotationServiceRPC) BatchUpdate(ctx context.Context, in *pb.BatchUpdateRequest) (*pb.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
+	var err error
+
+	switch records := in.Records.(type) {
+	case *pb.BatchUpdateRequest_Texts:
+		data := make([]text.DataTextFull, 0, len(records.Texts.GetRecords()))
+		for _, r := range records.Texts.GetRecords() {
+			data = append(data, text.DataTextFull{
+				MetaInfo: r.MetaInfo,
+				Text:     string(r.Text),
+				Version:  r.Version,
+			})
+		}
+		err = serv.rotationApp.UpdateTexts(email, in.KeyId, data)
+
+	case *pb.BatchUpdateRequest_Creds:
+		data := make([]cred.CredentialFull, 0, len(records.Creds.GetRecords()))
+		for _, r := range records.Creds.GetRecords() {
+			data = append(data, cred.CredentialFull{
+				MetaInfo: r.MetaInfo,
+				Email:    string(r.Email),
+				Password: string(r.Password),
+				Version:  r.Version,
+			})
+		}
+		err = serv.rotationApp.UpdateCreds(email, in.KeyId, data)
+
+	case *pb.BatchUpdateRequest_Cards:
+		data := make([]card.DataCardFull, 0, len(records.Cards.GetRecords()))
+		for _, r := range records.Cards.GetRecords() {
+			data = append(data, card.DataCardFull{
+				MetaInfo: r.MetaInfo,
+				Number:   string(r.Number),
+				Period:   string(r.Period),
+				CVV:      string(r.CVV),
+				FullName: string(r.FullName),
+				Version:  r.Version,
+			})
+		}
+		err = serv.rotationApp.UpdateCards(email, in.KeyId, data)
+
+	case *pb.BatchUpdateRequest_Binaries:
+		data := make([]binary.DataFull, 0, len(records.Binaries.GetRecords()))
+		for _, r := range records.Binaries.GetRecords() {
+			data = append(data, binary.DataFull{
+				MetaInfo: r.MetaInfo,
+				Bytes:    r.Data,
+				Version:  r.Version,
+			})
+		}
+		err = serv.rotationApp.UpdateBinaries(email, in.KeyId, data)
+
+	default:
+		return &pb.Empty{}, status.Error(codes.InvalidArgument, errs.ErrInvalidArgument.Error())
+	}
+
+	if err != nil {
+		return &pb.Empty{}, serv.statusError(err, "failed batch update")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// UpdateBinary - Потоковая запись перешифрованных бинарных данных,
+// которые не помещаются в пакет BatchUpdate.
+func (serv *RotationServiceRPC) UpdateBinary(stream pb.RotationService_UpdateBinaryServer) error {
+
+	email, errEmail := serv.userEmail(stream.Context())
+	if errEmail != nil {
+		return errEmail
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return status.Error(codes.InvalidArgument, errs.ErrInvalidArgument.Error())
+		}
+		return err
+	}
+
+	if len(first.MetaInfo) == 0 {
+		return status.Error(codes.InvalidArgument, errs.ErrInvalidArgument.Error())
+	}
+
+	reader := newChunkReader(stream)
+	if err = reader.push(first); err != nil {
+		return status.Error(codes.DataLoss, err.Error())
+	}
+
+	data := binary.DataUpload{
+		MetaInfo: first.MetaInfo,
+		Version:  first.Version,
+	}
+
+	if err = serv.rotationApp.UpdateBinary(email, first.KeyId, data, reader); err != nil {
+		if errors.Is(err, errs.ErrChecksum) {
+			return status.Error(codes.DataLoss, err.Error())
+		}
+		return serv.statusError(err, "failed update binary data")
+	}
+
+	return stream.SendAndClose(&pb.Empty{})
+}
+
+// Finish - Завершение смены ключа.
+func (serv *RotationServiceRPC) Finish(ctx context.Context, in *pb.FinishRequest) (*pb.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &pb.Empty{}, errEmail
+	}
+
+	if err := serv.rotationApp.Finish(email, in.KeyId); err != nil {
+		return &pb.Empty{}, serv.statusError(err, "failed finish key rotation")
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// statusError - Код gRPC для ошибки сервиса приложения.
+// Неизвестные ошибки записываются в лог и скрываются от клиента.
+func (serv *RotationServiceRPC) statusError(err error, msg string) error {
+
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errs.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, errs.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errs.ErrNoRotation):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	serv.logger.Error(msg, zap.Error(err))
+	return status.Error(codes.Internal, errs.ErrInternal.Error())
+}
+
+// rotationResponse - Смена ключа в ответе.
+func rotationResponse(data rotation.Rotation) *pb.Rotation {
+	return &pb.Rotation{
+		KeyId:     data.KeyID,
+		SealedKey: data.SealedKey,
+		StartedAt: timestamppb.New(data.StartedAt),
+	}
+}
+
+// userEmail - Получение email владельца данных из метаданных ctx.
+func (serv *RotationServiceRPC) userEmail(ctx context.Context) (string, error) {
+
+	email, ok := md_ctx.ValueFromContext(ctx, "email")
+	if !ok {
+		serv.logger.Error("failed found email in ctx metadata")
+		// Internal, т.к. Interceptor должен был положить email в ctx
+		return ``, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return email, nil
+}
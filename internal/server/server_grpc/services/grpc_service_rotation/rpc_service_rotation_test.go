@@ -0,0 +1,384 @@
+package grpc_service_rotation
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/internal/server/model/text"
+	mock "GophKeeper/internal/server/server_grpc/services/grpc_service_rotation/mocks"
+	"GophKeeper/pkg/errs"
+	pb "GophKeeper/pkg/proto/rotation"
+)
+
+// testEmail - Владелец данных, которого ValidateInterceptor кладет в метаданные.
+const testEmail = "test@email.com"
+
+// testKeyID - Идентификатор нового ключа.
+const testKeyID = "0102030405060708"
+
+// ownerContext - Контекст запроса от пользователя testEmail.
+func ownerContext() context.Context {
+	md := metadata.New(map[string]string{"email": testEmail})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestRotationServiceRPC_Begin(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rotationApp := mock.NewMockRotationApp(ctrl)
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Another key rotation",
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Invalid key id",
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			in := rotation.Rotation{KeyID: testKeyID, SealedKey: []byte("sealed")}
+			out := rotation.Rotation{KeyID: testKeyID, SealedKey: []byte("sealed"), StartedAt: startedAt}
+			rotationApp.EXPECT().Begin(testEmail, in).Return(out, tt.errApp)
+
+			rotationRPC := NewRotationServiceRPC(rotationApp)
+			got, err := rotationRPC.Begin(ownerContext(), &pb.BeginRequest{KeyId: testKeyID, SealedKey: []byte("sealed")})
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, testKeyID, got.KeyId)
+				assert.Equal(t, []byte("sealed"), got.SealedKey)
+				assert.Equal(t, startedAt, got.StartedAt.AsTime())
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestRotationServiceRPC_Get(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rotationApp := mock.NewMockRotationApp(ctrl)
+	rotationApp.EXPECT().Get(testEmail).Return(rotation.Rotation{}, errs.ErrNotFound)
+
+	rotationRPC := NewRotationServiceRPC(rotationApp)
+	_, err := rotationRPC.Get(ownerContext(), &pb.Empty{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = rotationRPC.Get(context.Background(), &pb.Empty{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRotationServiceRPC_BatchUpdate(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rotationApp := mock.NewMockRotationApp(ctrl)
+
+	texts := &pb.BatchUpdateRequest{KeyId: testKeyID, Records: &pb.BatchUpdateRequest_Texts{Texts: &pb.TextRecords{
+		Records: []*pb.TextRecord{{MetaInfo: "book", Text: []byte("text"), Version: 2}},
+	}}}
+	creds := &pb.BatchUpdateRequest{KeyId: testKeyID, Records: &pb.BatchUpdateRequest_Creds{Creds: &pb.CredRecords{
+		Records: []*pb.CredRecord{{MetaInfo: "site", Email: []byte("login"), Password: []byte("pwd"), Version: 3}},
+	}}}
+	cards := &pb.BatchUpdateRequest{KeyId: testKeyID, Records: &pb.BatchUpdateRequest_Cards{Cards: &pb.CardRecords{
+		Records: []*pb.CardRecord{{MetaInfo: "bank", Number: []byte("1"), Period: []byte("2"), CVV: []byte("3"), FullName: []byte("4"), Version: 4}},
+	}}}
+	binaries := &pb.BatchUpdateRequest{KeyId: testKeyID, Records: &pb.BatchUpdateRequest_Binaries{Binaries: &pb.BinaryRecords{
+		Records: []*pb.BinaryRecord{{MetaInfo: "photo", Data: []byte("bytes"), Version: 5}},
+	}}}
+
+	tests := []struct {
+		name     string
+		in       *pb.BatchUpdateRequest
+		expect   func()
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Texts",
+			in:   texts,
+			expect: func() {
+				rotationApp.EXPECT().UpdateTexts(testEmail, testKeyID,
+					[]text.DataTextFull{{MetaInfo: "book", Text: "text", Version: 2}}).Return(nil)
+			},
+		},
+		{
+			name: "Creds",
+			in:   creds,
+			expect: func() {
+				rotationApp.EXPECT().UpdateCreds(testEmail, testKeyID,
+					[]cred.CredentialFull{{MetaInfo: "site", Email: "login", Password: "pwd", Version: 3}}).Return(nil)
+			},
+		},
+		{
+			name: "Cards",
+			in:   cards,
+			expect: func() {
+				rotationApp.EXPECT().UpdateCards(testEmail, testKeyID,
+					[]card.DataCardFull{{MetaInfo: "bank", Number: "1", Period: "2", CVV: "3", FullName: "4", Version: 4}}).Return(nil)
+			},
+		},
+		{
+			name: "Binaries",
+			in:   binaries,
+			expect: func() {
+				rotationApp.EXPECT().UpdateBinaries(testEmail, testKeyID,
+					[]binary.DataFull{{MetaInfo: "photo", Bytes: []byte("bytes"), Version: 5}}).Return(nil)
+			},
+		},
+		{
+			name:     "Empty batch",
+			in:       &pb.BatchUpdateRequest{KeyId: testKeyID},
+			expect:   func() {},
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Rotation is not in progress",
+			in:   texts,
+			expect: func() {
+				rotationApp.EXPECT().UpdateTexts(testEmail, testKeyID, gomock.Any()).Return(errs.ErrNoRotation)
+			},
+			wantErr:  true,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "Version conflict",
+			in:   creds,
+			expect: func() {
+				rotationApp.EXPECT().UpdateCreds(testEmail, testKeyID, gomock.Any()).Return(errs.ErrConflict)
+			},
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Not found",
+			in:   cards,
+			expect: func() {
+				rotationApp.EXPECT().UpdateCards(testEmail, testKeyID, gomock.Any()).Return(errs.ErrNotFound)
+			},
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			tt.expect()
+
+			rotationRPC := NewRotationServiceRPC(rotationApp)
+			_, err := rotationRPC.BatchUpdate(ownerContext(), tt.in)
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+func TestRotationServiceRPC_Finish(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rotationApp := mock.NewMockRotationApp(ctrl)
+	rotationApp.EXPECT().Finish(testEmail, testKeyID).Return(nil)
+	rotationApp.EXPECT().Finish(testEmail, "ffffffffffffffff").Return(errs.ErrNoRotation)
+
+	rotationRPC := NewRotationServiceRPC(rotationApp)
+
+	_, err := rotationRPC.Finish(ownerContext(), &pb.FinishRequest{KeyId: testKeyID})
+	require.NoError(t, err)
+
+	_, err = rotationRPC.Finish(ownerContext(), &pb.FinishRequest{KeyId: "ffffffffffffffff"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// testChunkStream - Поток UpdateBinary, отдающий заранее подготовленные сообщения.
+type testChunkStream struct {
+	grpc.ServerStream
+	msgs []*pb.BinaryChunk
+}
+
+func (s *testChunkStream) Context() context.Context {
+	return ownerContext()
+}
+
+func (s *testChunkStream) Recv() (*pb.BinaryChunk, error) {
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return msg, nil
+}
+
+func (s *testChunkStream) SendAndClose(*pb.Empty) error {
+	return nil
+}
+
+func TestRotationServiceRPC_UpdateBinary(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rotationApp := mock.NewMockRotationApp(ctrl)
+
+	data := []byte("0101010101")
+	sum := sha256.Sum256(data)
+
+	tests := []struct {
+		name     string
+		msgs     []*pb.BinaryChunk
+		callApp  bool
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			msgs: []*pb.BinaryChunk{
+				{KeyId: testKeyID, MetaInfo: "photo", Version: 3, Chunk: data[:4]},
+				{Chunk: data[4:], Checksum: sum[:]},
+			},
+			callApp: true,
+		},
+		{
+			name: "Invalid checksum",
+			msgs: []*pb.BinaryChunk{
+				{KeyId: testKeyID, MetaInfo: "photo", Version: 3, Chunk: data[:4]},
+				{Chunk: data[4:], Checksum: []byte("0000")},
+			},
+			callApp:  true,
+			wantErr:  true,
+			wantCode: codes.DataLoss,
+		},
+		{
+			name: "Without meta",
+			msgs: []*pb.BinaryChunk{
+				{KeyId: testKeyID, Chunk: data, Checksum: sum[:]},
+			},
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Encrypted with another key",
+			msgs: []*pb.BinaryChunk{
+				{KeyId: testKeyID, MetaInfo: "photo", Version: 3, Chunk: data, Checksum: sum[:]},
+			},
+			callApp:  true,
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Rotation is not in progress",
+			msgs: []*pb.BinaryChunk{
+				{KeyId: testKeyID, MetaInfo: "photo", Version: 3, Chunk: data, Checksum: sum[:]},
+			},
+			callApp:  true,
+			errApp:   errs.ErrNoRotation,
+			wantErr:  true,
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "Version conflict",
+			msgs: []*pb.BinaryChunk{
+				{KeyId: testKeyID, MetaInfo: "photo", Version: 3, Chunk: data, Checksum: sum[:]},
+			},
+			callApp:  true,
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if tt.callApp {
+				upload := binary.DataUpload{MetaInfo: "photo", Version: 3}
+
+				rotationApp.EXPECT().
+					UpdateBinary(testEmail, testKeyID, upload, gomock.Any()).
+					DoAndReturn(func(email, keyID string, in binary.DataUpload, r io.Reader) error {
+						got, err := io.ReadAll(r)
+						if err != nil {
+							return err
+						}
+
+						assert.Equal(t, data, got)
+						return tt.errApp
+					})
+			}
+
+			rotationRPC := NewRotationServiceRPC(rotationApp)
+			err := rotationRPC.UpdateBinary(&testChunkStream{msgs: tt.msgs})
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package grpc_service_rotation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"io"
+
+	"GophKeeper/pkg/errs"
+	pb "GophKeeper/pkg/proto/rotation"
+)
+
+// chunkReader - io.Reader поверх потока UpdateBinary.
+// io.EOF возвращается только после успешной проверки контрольной суммы,
+// иначе - errs.ErrChecksum, чтобы хранилище не сохранило поврежденные данные.
+type chunkReader struct {
+	stream pb.RotationService_UpdateBinaryServer
+	hash   hash.Hash
+	chunk  []byte
+	done   bool
+}
+
+func newChunkReader(stream pb.RotationService_UpdateBinaryServer) *chunkReader {
+	return &chunkReader{
+		stream: stream,
+		hash:   sha256.New(),
+	}
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+
+	for len(r.chunk) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		msg, err := r.stream.Recv()
+		if err != nil {
+			// Клиент закрыл поток, не передав контрольную сумму
+			if err == io.EOF {
+				return 0, errs.ErrChecksum
+			}
+			return 0, err
+		}
+
+		if err = r.push(msg); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
+
+// push - Обработка очередного сообщения потока.
+func (r *chunkReader) push(msg *pb.BinaryChunk) error {
+
+	r.hash.Write(msg.Chunk)
+	r.chunk = msg.Chunk
+
+	if len(msg.Checksum) != 0 {
+		if !bytes.Equal(msg.Checksum, r.hash.Sum(nil)) {
+			return errs.ErrChecksum
+		}
+		r.done = true
+	}
+
+	return nil
+}
@@ -5,7 +5,10 @@
 package grpc_service_text
 
 import (
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
 	text "GophKeeper/internal/server/model/text"
+	trash "GophKeeper/internal/server/model/trash"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,58 +38,160 @@ func (m *MockTextApp) EXPECT() *MockTextAppMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockTextApp) Change(in text.DataTextFull) error {
+func (m *MockTextApp) Change(email string, in text.DataTextFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockTextAppMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockTextAppMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockTextApp)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockTextApp)(nil).Change), email, in)
 }
 
 // Create mocks base method.
-func (m *MockTextApp) Create(in text.DataTextFull) error {
+func (m *MockTextApp) Create(email string, in text.DataTextFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", in)
+	ret := m.ctrl.Call(m, "Create", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTextAppMockRecorder) Create(in interface{}) *gomock.Call {
+func (mr *MockTextAppMockRecorder) Create(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTextApp)(nil).Create), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTextApp)(nil).Create), email, in)
 }
 
 // Delete mocks base method.
-func (m *MockTextApp) Delete(in text.DataTextGet) error {
+func (m *MockTextApp) Delete(email string, in text.DataTextGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTextAppMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockTextAppMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTextApp)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTextApp)(nil).Delete), email, in)
 }
 
 // Get mocks base method.
-func (m *MockTextApp) Get(in text.DataTextGet) (text.DataTextFull, error) {
+func (m *MockTextApp) Get(email string, in text.DataTextGet) (text.DataTextFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(text.DataTextFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockTextAppMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockTextAppMockRecorder) Get(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTextApp)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTextApp)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockTextApp) GetRevision(email string, in revision.Request) (text.DataTextFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(text.DataTextFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockTextAppMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockTextApp)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockTextApp) List(email string, in page.Request) (page.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].(page.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTextAppMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTextApp)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockTextApp) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockTextAppMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockTextApp)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockTextApp) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockTextAppMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockTextApp)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockTextApp) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockTextAppMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTextApp)(nil).Purge), email, meta)
+}
+
+// Restore mocks base method.
+func (m *MockTextApp) Restore(email string, in revision.Request) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTextAppMockRecorder) Restore(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTextApp)(nil).Restore), email, in)
+}
+
+// Undelete mocks base method.
+func (m *MockTextApp) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockTextAppMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockTextApp)(nil).Undelete), email, meta)
 }
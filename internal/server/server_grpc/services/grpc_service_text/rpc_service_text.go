@@ -7,17 +7,29 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/server/model/trash"
 	"GophKeeper/pkg/errs"
+	"GophKeeper/pkg/md_ctx"
 	"GophKeeper/pkg/proto/text"
 )
 
 type TextApp interface {
-	Create(in text.DataTextFull) error
-	Get(in text.DataTextGet) (text.DataTextFull, error)
-	Delete(in text.DataTextGet) error
-	Change(in text.DataTextFull) error
+	Create(email string, in text.DataTextFull) error
+	Get(email string, in text.DataTextGet) (text.DataTextFull, error)
+	Delete(email string, in text.DataTextGet) error
+	Change(email string, in text.DataTextFull) error
+	List(email string, in page.Request) (page.Page, error)
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	GetRevision(email string, in revision.Request) (text.DataTextFull, error)
+	Restore(email string, in revision.Request) error
+	ListTrash(email string) ([]trash.Item, error)
+	Undelete(email, meta string) error
+	Purge(email, meta string) error
 }
 
 type TextServiceRPC struct {
@@ -40,12 +52,17 @@ func NewTextServiceRPC(textApp TextApp) *TextServiceRPC {
 // Create - Добавление новых данных.
 func (serv *TextServiceRPC) Create(ctx context.Context, in *text_store.CreateRequest) (*text_store.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.Empty{}, errEmail
+	}
+
 	data := text.DataTextFull{
 		MetaInfo: in.MetaInfo,
 		Text:     string(in.Text),
 	}
 
-	err := serv.textApp.Create(data)
+	err := serv.textApp.Create(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrAlreadyExist) {
 			return &text_store.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
@@ -64,17 +81,31 @@ func (serv *TextServiceRPC) Create(ctx context.Context, in *text_store.CreateReq
 // Change - Изменение существующих данных.
 func (serv *TextServiceRPC) Change(ctx context.Context, in *text_store.ChangeRequest) (*text_store.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.Empty{}, errEmail
+	}
+
 	data := text.DataTextFull{
 		MetaInfo: in.MetaInfo,
 		Text:     string(in.Text),
+		Version:  in.Version,
 	}
 
-	err := serv.textApp.Change(data)
+	err := serv.textApp.Change(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &text_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &text_store.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &text_store.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed change text data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -88,16 +119,30 @@ func (serv *TextServiceRPC) Change(ctx context.Context, in *text_store.ChangeReq
 // Delete - Удаление существующих данных.
 func (serv *TextServiceRPC) Delete(ctx context.Context, in *text_store.DeleteRequest) (*text_store.Empty, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.Empty{}, errEmail
+	}
+
 	data := text.DataTextGet{
 		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
 	}
 
-	err := serv.textApp.Delete(data)
+	err := serv.textApp.Delete(email, data)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &text_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
 		}
 
+		if errors.Is(err, errs.ErrConflict) {
+			return &text_store.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &text_store.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
 		serv.logger.Error("failed delete text data",
 			zap.Error(err),
 			zap.String("meta", in.MetaInfo))
@@ -111,11 +156,16 @@ func (serv *TextServiceRPC) Delete(ctx context.Context, in *text_store.DeleteReq
 // Get - Получение данных по email и метаданным.
 func (serv *TextServiceRPC) Get(ctx context.Context, in *text_store.GetRequest) (*text_store.GetResponse, error) {
 
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.GetResponse{}, errEmail
+	}
+
 	inData := text.DataTextGet{
 		MetaInfo: in.MetaInfo,
 	}
 
-	data, err := serv.textApp.Get(inData)
+	data, err := serv.textApp.Get(email, inData)
 	if err != nil {
 		if errors.Is(err, errs.ErrNotFound) {
 			return &text_store.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
@@ -129,9 +179,245 @@ func (serv *TextServiceRPC) Get(ctx context.Context, in *text_store.GetRequest)
 	}
 
 	out := &text_store.GetResponse{
-		MetaInfo: data.MetaInfo,
-		Text:     []byte(data.Text),
+		MetaInfo:  data.MetaInfo,
+		Text:      []byte(data.Text),
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}
+
+	return out, nil
+}
+
+// List - Получение страницы списка метаинформации.
+func (serv *TextServiceRPC) List(ctx context.Context, in *text_store.ListRequest) (*text_store.ListResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.ListResponse{}, errEmail
+	}
+
+	req := page.Request{
+		Prefix:   in.Prefix,
+		Contains: in.Contains,
+		After:    in.PageToken,
+		Limit:    int(in.Limit),
+	}
+
+	list, err := serv.textApp.List(email, req)
+	if err != nil {
+		serv.logger.Error("failed list text data",
+			zap.Error(err),
+			zap.String("prefix", in.Prefix),
+			zap.String("contains", in.Contains))
+
+		return &text_store.ListResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &text_store.ListResponse{
+		MetaInfo:      list.MetaInfo,
+		Versions:      list.Versions,
+		NextPageToken: list.NextPageToken,
+	}, nil
+}
+
+// ListRevisions - Получение прежних версий данных, от новых к старым.
+func (serv *TextServiceRPC) ListRevisions(ctx context.Context, in *text_store.ListRevisionsRequest) (*text_store.ListRevisionsResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.ListRevisionsResponse{}, errEmail
+	}
+
+	revs, err := serv.textApp.ListRevisions(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &text_store.ListRevisionsResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed list text data revisions",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &text_store.ListRevisionsResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &text_store.ListRevisionsResponse{
+		Revisions: make([]*text_store.Revision, 0, len(revs)),
+	}
+
+	for _, rev := range revs {
+		out.Revisions = append(out.Revisions, &text_store.Revision{
+			Version:   rev.Version,
+			UpdatedAt: timestamppb.New(rev.UpdatedAt),
+		})
+	}
+
+	return out, nil
+}
+
+// GetRevision - Получение прежней версии данных.
+func (serv *TextServiceRPC) GetRevision(ctx context.Context, in *text_store.GetRevisionRequest) (*text_store.GetResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.GetResponse{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+	}
+
+	data, err := serv.textApp.GetRevision(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &text_store.GetResponse{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed get text data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &text_store.GetResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &text_store.GetResponse{
+		MetaInfo:  data.MetaInfo,
+		Text:      []byte(data.Text),
+		Version:   data.Version,
+		UpdatedAt: timestamppb.New(data.UpdatedAt),
+	}, nil
+}
+
+// Restore - Восстановление прежней версии как новой версии данных.
+func (serv *TextServiceRPC) Restore(ctx context.Context, in *text_store.RestoreRequest) (*text_store.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.Empty{}, errEmail
+	}
+
+	req := revision.Request{
+		MetaInfo: in.MetaInfo,
+		Version:  in.Version,
+		Current:  in.CurrentVersion,
+	}
+
+	err := serv.textApp.Restore(email, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &text_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrConflict) {
+			return &text_store.Empty{}, status.Errorf(codes.Aborted, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrInvalidArgument) {
+			return &text_store.Empty{}, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+
+		serv.logger.Error("failed restore text data revision",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo),
+			zap.Int64("version", in.Version))
+
+		return &text_store.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &text_store.Empty{}, nil
+}
+
+// ListTrash - Получение удаленных данных из корзины.
+func (serv *TextServiceRPC) ListTrash(ctx context.Context, _ *text_store.ListTrashRequest) (*text_store.ListTrashResponse, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.ListTrashResponse{}, errEmail
+	}
+
+	items, err := serv.textApp.ListTrash(email)
+	if err != nil {
+		serv.logger.Error("failed list text data trash", zap.Error(err))
+		return &text_store.ListTrashResponse{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	out := &text_store.ListTrashResponse{
+		Items: make([]*text_store.TrashItem, 0, len(items)),
+	}
+
+	for _, item := range items {
+		out.Items = append(out.Items, &text_store.TrashItem{
+			MetaInfo:  item.MetaInfo,
+			DeletedAt: timestamppb.New(item.DeletedAt),
+		})
 	}
 
 	return out, nil
 }
+
+// Undelete - Восстановление данных из корзины.
+func (serv *TextServiceRPC) Undelete(ctx context.Context, in *text_store.UndeleteRequest) (*text_store.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.Empty{}, errEmail
+	}
+
+	err := serv.textApp.Undelete(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &text_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, errs.ErrAlreadyExist) {
+			return &text_store.Empty{}, status.Errorf(codes.AlreadyExists, err.Error())
+		}
+
+		serv.logger.Error("failed undelete text data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &text_store.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &text_store.Empty{}, nil
+}
+
+// Purge - Окончательное удаление данных из корзины.
+func (serv *TextServiceRPC) Purge(ctx context.Context, in *text_store.PurgeRequest) (*text_store.Empty, error) {
+
+	email, errEmail := serv.userEmail(ctx)
+	if errEmail != nil {
+		return &text_store.Empty{}, errEmail
+	}
+
+	err := serv.textApp.Purge(email, in.MetaInfo)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return &text_store.Empty{}, status.Errorf(codes.NotFound, err.Error())
+		}
+
+		serv.logger.Error("failed purge text data",
+			zap.Error(err),
+			zap.String("meta", in.MetaInfo))
+
+		return &text_store.Empty{}, status.Errorf(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return &text_store.Empty{}, nil
+}
+
+// userEmail - Получение email владельца данных из метаданных ctx.
+func (serv *TextServiceRPC) userEmail(ctx context.Context) (string, error) {
+
+	email, ok := md_ctx.ValueFromContext(ctx, "email")
+	if !ok {
+		serv.logger.Error("failed found email in ctx metadata")
+		// Internal, т.к. Interceptor должен был положить email в ctx
+		return ``, status.Error(codes.Internal, errs.ErrInternal.Error())
+	}
+
+	return email, nil
+}
@@ -4,20 +4,38 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/server/model/trash"
 	mock "GophKeeper/internal/server/server_grpc/services/grpc_service_text/mocks"
 	"GophKeeper/pkg/errs"
 	pb "GophKeeper/pkg/proto/text"
 )
 
+// testEmail - Владелец данных, которого ValidateInterceptor кладет в метаданные.
+const testEmail = "test@email.com"
+
+// testUpdatedAt - Время последнего изменения данных.
+var testUpdatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// ownerContext - Контекст запроса от пользователя testEmail.
+func ownerContext() context.Context {
+	md := metadata.New(map[string]string{"email": testEmail})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
 func TestTextServiceRPC_Create(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -71,10 +89,10 @@ func TestTextServiceRPC_Create(t *testing.T) {
 				Text:     string(tt.in.Text),
 			}
 
-			textApp.EXPECT().Create(data).Return(tt.errApp)
+			textApp.EXPECT().Create(testEmail, data).Return(tt.errApp)
 
 			serv := NewTextServiceRPC(textApp)
-			_, err := serv.Create(context.Background(), tt.in)
+			_, err := serv.Create(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -120,6 +138,27 @@ func TestTextServiceRPC_Change(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Version conflict",
+			in: &pb.ChangeRequest{
+				MetaInfo: "book1",
+				Text:     []byte("testText"),
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			in: &pb.ChangeRequest{
+				MetaInfo: "book1",
+				Text:     []byte("testText"),
+			},
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
 		{
 			name: "Anomaly app service",
 			in: &pb.ChangeRequest{
@@ -138,12 +177,13 @@ func TestTextServiceRPC_Change(t *testing.T) {
 			data := text.DataTextFull{
 				MetaInfo: tt.in.MetaInfo,
 				Text:     string(tt.in.Text),
+				Version:  tt.in.Version,
 			}
 
-			textApp.EXPECT().Change(data).Return(tt.errApp)
+			textApp.EXPECT().Change(testEmail, data).Return(tt.errApp)
 
 			serv := NewTextServiceRPC(textApp)
-			_, err := serv.Change(context.Background(), tt.in)
+			_, err := serv.Change(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -187,6 +227,25 @@ func TestTextServiceRPC_Delete(t *testing.T) {
 			wantErr:  true,
 			wantCode: codes.NotFound,
 		},
+		{
+			name: "Version conflict",
+			in: &pb.DeleteRequest{
+				MetaInfo: "book1",
+				Version:  2,
+			},
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name: "Missing version",
+			in: &pb.DeleteRequest{
+				MetaInfo: "book1",
+			},
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
 		{
 			name: "Anomaly app service",
 			in: &pb.DeleteRequest{
@@ -203,12 +262,13 @@ func TestTextServiceRPC_Delete(t *testing.T) {
 
 			data := text.DataTextGet{
 				MetaInfo: tt.in.MetaInfo,
+				Version:  tt.in.Version,
 			}
 
-			textApp.EXPECT().Delete(data).Return(tt.errApp)
+			textApp.EXPECT().Delete(testEmail, data).Return(tt.errApp)
 
 			serv := NewTextServiceRPC(textApp)
-			_, err := serv.Delete(context.Background(), tt.in)
+			_, err := serv.Delete(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -242,8 +302,10 @@ func TestTextServiceRPC_Get(t *testing.T) {
 				MetaInfo: "book1",
 			},
 			out: &pb.GetResponse{
-				MetaInfo: "book1",
-				Text:     []byte("testText"),
+				MetaInfo:  "book1",
+				Text:      []byte("testText"),
+				Version:   3,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
 			},
 			errApp:  nil,
 			wantErr: false,
@@ -276,13 +338,15 @@ func TestTextServiceRPC_Get(t *testing.T) {
 			}
 
 			outApp := text.DataTextFull{
-				MetaInfo: tt.in.MetaInfo,
-				Text:     "testText",
+				MetaInfo:  tt.in.MetaInfo,
+				Text:      "testText",
+				Version:   3,
+				UpdatedAt: testUpdatedAt,
 			}
 
-			textApp.EXPECT().Get(data).Return(outApp, tt.errApp)
+			textApp.EXPECT().Get(testEmail, data).Return(outApp, tt.errApp)
 			serv := NewTextServiceRPC(textApp)
-			get, err := serv.Get(context.Background(), tt.in)
+			get, err := serv.Get(ownerContext(), tt.in)
 
 			if tt.wantErr {
 				if e, ok := status.FromError(err); ok {
@@ -295,3 +359,461 @@ func TestTextServiceRPC_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestTextServiceRPC_List(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		in       *pb.ListRequest
+		outApp   page.Page
+		out      *pb.ListResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			in: &pb.ListRequest{
+				Prefix:    "mail",
+				Contains:  "ru",
+				PageToken: "mail.com",
+				Limit:     2,
+			},
+			outApp: page.Page{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			out: &pb.ListResponse{
+				MetaInfo:      []string{"mail.org.ru", "mail.ru"},
+				Versions:      []int64{3, 1},
+				NextPageToken: "mail.ru",
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			in:       &pb.ListRequest{},
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := page.Request{
+				Prefix:   tt.in.Prefix,
+				Contains: tt.in.Contains,
+				After:    tt.in.PageToken,
+				Limit:    int(tt.in.Limit),
+			}
+
+			textApp.EXPECT().List(testEmail, req).Return(tt.outApp, tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			out, err := serv.List(ownerContext(), tt.in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestTextServiceRPC_NoOwner(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Без email в метаданных запрос не должен доходить до сервиса приложения
+	textApp := mock.NewMockTextApp(ctrl)
+	serv := NewTextServiceRPC(textApp)
+
+	_, err := serv.Get(context.Background(), &pb.GetRequest{MetaInfo: "note"})
+
+	e, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, e.Code())
+}
+
+func TestTextServiceRPC_ListRevisions(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []revision.Revision
+		out      *pb.ListRevisionsResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []revision.Revision{{Version: 2, UpdatedAt: testUpdatedAt}, {Version: 1, UpdatedAt: testUpdatedAt}},
+			out: &pb.ListRevisionsResponse{
+				Revisions: []*pb.Revision{
+					{Version: 2, UpdatedAt: timestamppb.New(testUpdatedAt)},
+					{Version: 1, UpdatedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			textApp.EXPECT().ListRevisions(testEmail, "book1").Return(tt.outApp, tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			out, err := serv.ListRevisions(ownerContext(), &pb.ListRevisionsRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestTextServiceRPC_GetRevision(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		out      *pb.GetResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			out: &pb.GetResponse{
+				MetaInfo:  "book1",
+				Text:      []byte("testText"),
+				Version:   1,
+				UpdatedAt: timestamppb.New(testUpdatedAt),
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			req := revision.Request{
+				MetaInfo: "book1",
+				Version:  1,
+			}
+
+			outApp := text.DataTextFull{
+				MetaInfo:  "book1",
+				Text:      "testText",
+				Version:   1,
+				UpdatedAt: testUpdatedAt,
+			}
+
+			textApp.EXPECT().GetRevision(testEmail, req).Return(outApp, tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			out, err := serv.GetRevision(ownerContext(), &pb.GetRevisionRequest{MetaInfo: "book1", Version: 1})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestTextServiceRPC_Restore(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Version conflict",
+			errApp:   errs.ErrConflict,
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Missing version",
+			errApp:   errs.ErrInvalidArgument,
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			in := &pb.RestoreRequest{
+				MetaInfo:       "book1",
+				Version:        1,
+				CurrentVersion: 3,
+			}
+
+			req := revision.Request{
+				MetaInfo: in.MetaInfo,
+				Version:  in.Version,
+				Current:  in.CurrentVersion,
+			}
+
+			textApp.EXPECT().Restore(testEmail, req).Return(tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			_, err := serv.Restore(ownerContext(), in)
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTextServiceRPC_ListTrash(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		outApp   []trash.Item
+		out      *pb.ListTrashResponse
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:   "Success",
+			outApp: []trash.Item{{MetaInfo: "book1", DeletedAt: testUpdatedAt}},
+			out: &pb.ListTrashResponse{
+				Items: []*pb.TrashItem{
+					{MetaInfo: "book1", DeletedAt: timestamppb.New(testUpdatedAt)},
+				},
+			},
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			textApp.EXPECT().ListTrash(testEmail).Return(tt.outApp, tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			out, err := serv.ListTrash(ownerContext(), &pb.ListTrashRequest{})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestTextServiceRPC_Undelete(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Already exists",
+			errApp:   errs.ErrAlreadyExist,
+			wantErr:  true,
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			textApp.EXPECT().Undelete(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			_, err := serv.Undelete(ownerContext(), &pb.UndeleteRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTextServiceRPC_Purge(t *testing.T) {
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	textApp := mock.NewMockTextApp(ctrl)
+
+	tests := []struct {
+		name     string
+		errApp   error
+		wantErr  bool
+		wantCode codes.Code
+	}{
+		{
+			name:    "Success",
+			errApp:  nil,
+			wantErr: false,
+		},
+		{
+			name:     "Not found",
+			errApp:   errs.ErrNotFound,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "Anomaly app service",
+			errApp:   fmt.Errorf("unknown error"),
+			wantErr:  true,
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			textApp.EXPECT().Purge(testEmail, "book1").Return(tt.errApp)
+
+			serv := NewTextServiceRPC(textApp)
+			_, err := serv.Purge(ownerContext(), &pb.PurgeRequest{MetaInfo: "book1"})
+
+			if tt.wantErr {
+				e, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCode, e.Code())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -6,8 +6,20 @@ import (
 )
 
 type AuthStorage interface {
-	Create(cred auth.Credential) error
-	Find(cred auth.Credential) error
-	Update(email, password string) error
+	Create(user auth.User) error
+	// Get - Учетная запись пользователя email, errs.ErrNotFound, если ее нет.
+	Get(email string) (auth.User, error)
+	Update(email, passwordHash string) error
+	// Delete - Удаление пользователя email. В БД вместе с ним удаляются все его
+	// данные и сессии. Если пользователя нет, возвращается errs.ErrNotFound.
 	Delete(email string) error
+
+	// GetTOTP - Второй фактор пользователя email.
+	// Если второй фактор не подключен, возвращается errs.ErrNotFound.
+	GetTOTP(email string) (auth.TOTP, error)
+	// SetTOTP - Сохранение второго фактора пользователя email взамен прежнего.
+	// Если пользователя нет, возвращается errs.ErrNotFound.
+	SetTOTP(email string, in auth.TOTP) error
+	// DeleteTOTP - Отключение второго фактора. errs.ErrNotFound, если он не подключен.
+	DeleteTOTP(email string) error
 }
@@ -2,8 +2,11 @@ package auth_store
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/server/model/auth"
@@ -15,6 +18,16 @@ var (
                    VALUES ($1, $2)`
 	queryDelete = `DELETE FROM users 
                    WHERE id = $1`
+	queryDeleteText = `DELETE FROM text_data
+                       WHERE user_id = $1`
+	queryDeleteBinary = `DELETE FROM bin_data
+                         WHERE user_id = $1`
+	queryDeleteCred = `DELETE FROM cred_data
+                       WHERE user_id = $1`
+	queryDeleteCard = `DELETE FROM card_data
+                       WHERE user_id = $1`
+	queryDeleteSessions = `DELETE FROM sessions
+                           WHERE user_id = $1`
 	queryUpdate = `UPDATE users
                    SET password_hash = $1
                    WHERE id = $2`
@@ -24,6 +37,21 @@ var (
 	queryGetID = `SELECT id
                   FROM users 
                   WHERE email = $1`
+	queryGetTOTP = `SELECT t.sealed_secret, t.confirmed, t.recovery_hashes, t.last_step
+                    FROM users_totp t
+                    JOIN users u ON u.id = t.user_id
+                    WHERE u.email = $1`
+	querySetTOTP = `INSERT INTO users_totp (user_id, sealed_secret, confirmed, recovery_hashes, last_step)
+                    SELECT id, $2, $3, $4, $5
+                    FROM users
+                    WHERE email = $1
+                    ON CONFLICT (user_id) DO UPDATE
+                    SET sealed_secret = EXCLUDED.sealed_secret,
+                        confirmed = EXCLUDED.confirmed,
+                        recovery_hashes = EXCLUDED.recovery_hashes,
+                        last_step = EXCLUDED.last_step`
+	queryDeleteTOTP = `DELETE FROM users_totp
+                       WHERE user_id = (SELECT id FROM users WHERE email = $1)`
 )
 
 type PostgresStorage struct {
@@ -40,19 +68,21 @@ func NewPostgresStorage(db *sqlx.DB) *PostgresStorage {
 }
 
 // Create Создание нового пользователя.
-func (store *PostgresStorage) Create(cred auth.Credential) error {
+func (store *PostgresStorage) Create(user auth.User) error {
 
-	if _, ok := store.userID(cred.Email); ok {
+	if _, ok := store.userID(user.Email); ok {
 		return errs.ErrAlreadyExist
 	}
 
-	if _, err := store.db.ExecContext(context.Background(), queryCreate, cred.Email, cred.Password); err != nil {
+	if _, err := store.db.ExecContext(context.Background(), queryCreate, user.Email, user.PasswordHash); err != nil {
 		return err
 	}
 	return nil
 }
 
-// Delete Удаление пользователя
+// Delete Удаление пользователя вместе со всеми его данными и сессиями
+// в одной транзакции. Прежние версии, части файлов, ключ хранилища
+// и второй фактор удаляются каскадно.
 func (store *PostgresStorage) Delete(email string) error {
 
 	userID, ok := store.userID(email)
@@ -60,39 +90,131 @@ func (store *PostgresStorage) Delete(email string) error {
 		return errs.ErrNotFound
 	}
 
-	if _, err := store.db.ExecContext(context.Background(), queryDelete, userID); err != nil {
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		queryDeleteText,
+		queryDeleteBinary,
+		queryDeleteCred,
+		queryDeleteCard,
+		queryDeleteSessions,
+	} {
+		if _, err = tx.ExecContext(ctx, query, userID); err != nil {
+			store.logger.Error("failed delete user data", zap.Error(err))
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, queryDelete, userID)
+	if err != nil {
+		store.logger.Error("failed delete user", zap.Error(err))
+		return err
+	}
+
+	if err = store.affected(res); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		store.logger.Error("failed delete user", zap.Error(err))
 		return err
 	}
+
 	return nil
 }
 
-// Update - Обновление пароля пользователя
-func (store *PostgresStorage) Update(email, password string) error {
+// Update - Замена хэша пароля пользователя
+func (store *PostgresStorage) Update(email, passwordHash string) error {
 
 	userID, ok := store.userID(email)
 	if !ok {
 		return errs.ErrNotFound
 	}
 
-	if _, err := store.db.ExecContext(context.Background(), queryUpdate, password, userID); err != nil {
+	if _, err := store.db.ExecContext(context.Background(), queryUpdate, passwordHash, userID); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// Find - Поиск пользователя по имени и паролю
-func (store *PostgresStorage) Find(cred auth.Credential) error {
+// Get - Поиск пользователя по Email
+func (store *PostgresStorage) Get(email string) (auth.User, error) {
 
-	row := store.db.QueryRowContext(context.Background(), queryFind, cred.Email)
+	row := store.db.QueryRowContext(context.Background(), queryFind, email)
 
-	var pwd string
-	if err := row.Scan(&pwd); err != nil {
-		return errs.ErrNotFound
+	var hash sql.NullString
+	if err := row.Scan(&hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.User{}, errs.ErrNotFound
+		}
+		return auth.User{}, err
+	}
+
+	return auth.User{Email: email, PasswordHash: hash.String}, nil
+}
+
+// GetTOTP - Второй фактор пользователя
+func (store *PostgresStorage) GetTOTP(email string) (auth.TOTP, error) {
+
+	var in auth.TOTP
+
+	row := store.db.QueryRowContext(context.Background(), queryGetTOTP, email)
+	if err := row.Scan(&in.SealedSecret, &in.Confirmed, pq.Array(&in.RecoveryHashes), &in.LastStep); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.TOTP{}, errs.ErrNotFound
+		}
+		return auth.TOTP{}, err
+	}
+
+	return in, nil
+}
+
+// SetTOTP - Сохранение второго фактора пользователя
+func (store *PostgresStorage) SetTOTP(email string, in auth.TOTP) error {
+
+	hashes := in.RecoveryHashes
+	if hashes == nil {
+		hashes = []string{}
+	}
+
+	res, err := store.db.ExecContext(context.Background(), querySetTOTP,
+		email, in.SealedSecret, in.Confirmed, pq.Array(hashes), in.LastStep)
+	if err != nil {
+		return err
+	}
+
+	return store.affected(res)
+}
+
+// DeleteTOTP - Отключение второго фактора пользователя
+func (store *PostgresStorage) DeleteTOTP(email string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryDeleteTOTP, email)
+	if err != nil {
+		return err
+	}
+
+	return store.affected(res)
+}
+
+// affected - errs.ErrNotFound, если запрос не затронул ни одной строки.
+func (store *PostgresStorage) affected(res sql.Result) error {
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
 	}
 
-	if pwd != cred.Password {
-		return ErrInvalidPassword
+	if rows == 0 {
+		return errs.ErrNotFound
 	}
 
 	return nil
@@ -9,45 +9,43 @@ import (
 
 type MemoryStorage struct {
 	mutex sync.RWMutex
-	users map[string]auth.Credential
+	users map[string]auth.User
+	totp  map[string]auth.TOTP
 }
 
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		users: make(map[string]auth.Credential),
+		users: make(map[string]auth.User),
+		totp:  make(map[string]auth.TOTP),
 	}
 }
 
 // Create Создание нового пользователя
-func (store *MemoryStorage) Create(cred auth.Credential) error {
+func (store *MemoryStorage) Create(user auth.User) error {
 
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
 
-	if _, ok := store.users[cred.Email]; ok {
+	if _, ok := store.users[user.Email]; ok {
 		return errs.ErrAlreadyExist
 	}
 
-	store.users[cred.Email] = cred
+	store.users[user.Email] = user
 	return nil
 }
 
-// Find Поиск пользователя по Email и паролю
-func (store *MemoryStorage) Find(cred auth.Credential) error {
+// Get - Поиск пользователя по Email
+func (store *MemoryStorage) Get(email string) (auth.User, error) {
 
 	store.mutex.RLock()
 	defer store.mutex.RUnlock()
 
-	user, ok := store.users[cred.Email]
+	user, ok := store.users[email]
 	if !ok {
-		return errs.ErrNotFound
+		return auth.User{}, errs.ErrNotFound
 	}
 
-	if user.Password != cred.Password {
-		return ErrInvalidPassword
-	}
-
-	return nil
+	return user, nil
 }
 
 // Delete Удаление пользователя
@@ -61,10 +59,12 @@ func (store *MemoryStorage) Delete(email string) error {
 	}
 
 	delete(store.users, email)
+	delete(store.totp, email)
 	return nil
 }
 
-func (store *MemoryStorage) Update(email, password string) error {
+// Update - Замена хэша пароля пользователя
+func (store *MemoryStorage) Update(email, passwordHash string) error {
 
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
@@ -74,8 +74,52 @@ func (store *MemoryStorage) Update(email, password string) error {
 		return errs.ErrNotFound
 	}
 
-	user.Password = password
+	user.PasswordHash = passwordHash
 
 	store.users[email] = user
 	return nil
 }
+
+// GetTOTP - Второй фактор пользователя
+func (store *MemoryStorage) GetTOTP(email string) (auth.TOTP, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	in, ok := store.totp[email]
+	if !ok {
+		return auth.TOTP{}, errs.ErrNotFound
+	}
+
+	in.RecoveryHashes = append([]string(nil), in.RecoveryHashes...)
+	return in, nil
+}
+
+// SetTOTP - Сохранение второго фактора пользователя
+func (store *MemoryStorage) SetTOTP(email string, in auth.TOTP) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.users[email]; !ok {
+		return errs.ErrNotFound
+	}
+
+	in.RecoveryHashes = append([]string(nil), in.RecoveryHashes...)
+	store.totp[email] = in
+	return nil
+}
+
+// DeleteTOTP - Отключение второго фактора пользователя
+func (store *MemoryStorage) DeleteTOTP(email string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.totp[email]; !ok {
+		return errs.ErrNotFound
+	}
+
+	delete(store.totp, email)
+	return nil
+}
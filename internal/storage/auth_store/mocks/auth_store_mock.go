@@ -35,17 +35,17 @@ func (m *MockAuthStorage) EXPECT() *MockAuthStorageMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockAuthStorage) Create(cred auth.Credential) error {
+func (m *MockAuthStorage) Create(user auth.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", cred)
+	ret := m.ctrl.Call(m, "Create", user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockAuthStorageMockRecorder) Create(cred interface{}) *gomock.Call {
+func (mr *MockAuthStorageMockRecorder) Create(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuthStorage)(nil).Create), cred)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuthStorage)(nil).Create), user)
 }
 
 // Delete mocks base method.
@@ -62,30 +62,74 @@ func (mr *MockAuthStorageMockRecorder) Delete(email interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAuthStorage)(nil).Delete), email)
 }
 
-// Find mocks base method.
-func (m *MockAuthStorage) Find(cred auth.Credential) error {
+// DeleteTOTP mocks base method.
+func (m *MockAuthStorage) DeleteTOTP(email string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Find", cred)
+	ret := m.ctrl.Call(m, "DeleteTOTP", email)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Find indicates an expected call of Find.
-func (mr *MockAuthStorageMockRecorder) Find(cred interface{}) *gomock.Call {
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockAuthStorageMockRecorder) DeleteTOTP(email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockAuthStorage)(nil).Find), cred)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockAuthStorage)(nil).DeleteTOTP), email)
+}
+
+// Get mocks base method.
+func (m *MockAuthStorage) Get(email string) (auth.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", email)
+	ret0, _ := ret[0].(auth.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAuthStorageMockRecorder) Get(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAuthStorage)(nil).Get), email)
+}
+
+// GetTOTP mocks base method.
+func (m *MockAuthStorage) GetTOTP(email string) (auth.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", email)
+	ret0, _ := ret[0].(auth.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockAuthStorageMockRecorder) GetTOTP(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockAuthStorage)(nil).GetTOTP), email)
+}
+
+// SetTOTP mocks base method.
+func (m *MockAuthStorage) SetTOTP(email string, in auth.TOTP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTP", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTP indicates an expected call of SetTOTP.
+func (mr *MockAuthStorageMockRecorder) SetTOTP(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTP", reflect.TypeOf((*MockAuthStorage)(nil).SetTOTP), email, in)
 }
 
 // Update mocks base method.
-func (m *MockAuthStorage) Update(email, password string) error {
+func (m *MockAuthStorage) Update(email, passwordHash string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", email, password)
+	ret := m.ctrl.Call(m, "Update", email, passwordHash)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockAuthStorageMockRecorder) Update(email, password interface{}) *gomock.Call {
+func (mr *MockAuthStorageMockRecorder) Update(email, passwordHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAuthStorage)(nil).Update), email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAuthStorage)(nil).Update), email, passwordHash)
 }
@@ -2,12 +2,48 @@
 package binary_store
 
 import (
+	"io"
+	"time"
+
 	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 )
 
+// BinaryStorage - Хранилище бинарных данных.
+// Все операции выполняются в рамках данных пользователя с указанным email.
 type BinaryStorage interface {
-	Create(in binary.DataFull) error
-	Get(in binary.DataGet) (binary.DataFull, error)
-	Delete(in binary.DataGet) error
-	Change(in binary.DataFull) error
+	Create(email string, in binary.DataFull) error
+	Get(email string, in binary.DataGet) (binary.DataFull, error)
+	Delete(email string, in binary.DataGet) error
+	Change(email string, in binary.DataFull) error
+	// ChangeBatch - Изменение нескольких записей: все или ни одной.
+	// errs.ErrNotFound или errs.ErrConflict, если хотя бы одна запись не найдена
+	// или изменена с ожидаемой версии.
+	ChangeBatch(email string, in []binary.DataFull) error
+	List(email string, in page.Request) ([]page.Item, error)
+
+	// ListRevisions - Прежние версии данных meta, от новых к старым.
+	// errs.ErrNotFound, если данных нет.
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	// GetRevision - Прежняя версия данных, errs.ErrNotFound, если ее нет.
+	GetRevision(email string, in revision.Request) (binary.DataFull, error)
+
+	// ListTrash - Удаленные данные, отсортированные по метаинформации.
+	ListTrash(email string) ([]trash.Item, error)
+	// Undelete - Восстановление удаленных данных. errs.ErrNotFound, если их нет в корзине,
+	// errs.ErrAlreadyExist, если после удаления созданы данные с той же метаинформацией.
+	Undelete(email, meta string) error
+	// Purge - Окончательное удаление данных из корзины.
+	Purge(email, meta string) error
+	// PurgeExpired - Окончательное удаление данных всех пользователей, удаленных раньше before.
+	// Возвращает количество удаленных записей.
+	PurgeExpired(before time.Time) (int, error)
+
+	// Upload - Запись данных, читаемых из r до io.EOF.
+	// Если чтение из r завершилось ошибкой, данные не сохраняются.
+	Upload(email string, in binary.DataUpload, r io.Reader) error
+	// Download - Запись сохраненных данных в w.
+	Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error)
 }
@@ -1,10 +1,13 @@
 package binary_store
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jmoiron/sqlx"
@@ -12,107 +15,597 @@ import (
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
+	"GophKeeper/internal/storage/history"
 	"GophKeeper/pkg/errs"
 )
 
 var (
-	queryInsert = `INSERT INTO bin_data (meta, bytes) 
-                   VALUES ($1, $2)`
-	queryDelete = `DELETE FROM bin_data 
-                   WHERE meta = $1`
+	queryInsert = `INSERT INTO bin_data (user_id, meta)
+                   SELECT id, $2
+                   FROM users
+                   WHERE email = $1
+                   RETURNING id`
+	// queryDelete - Перемещение данных в корзину
+	queryDelete = `UPDATE bin_data
+                   SET deleted_at = now()
+                   WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL
+                     AND version = $3`
+	queryGetID = `SELECT id, version, updated_at
+                  FROM bin_data
+                  WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL`
+	queryLockID = queryGetID + ` FOR UPDATE`
 	queryUpdate = `UPDATE bin_data
-                   SET bytes = $1
-                   WHERE meta = $2`
-	queryGet = `SELECT bytes
-                FROM bin_data 
-                WHERE meta = $1`
+                   SET version = version + 1, updated_at = now()
+                   WHERE id = $1`
+	queryList = `SELECT meta, version
+                 FROM bin_data
+                 WHERE user_id = (SELECT id FROM users WHERE email = $1)
+                   AND deleted_at IS NULL AND meta LIKE $2 AND meta LIKE $3 AND meta > $4
+                 ORDER BY meta
+                 LIMIT $5`
+
+	queryInsertChunk = `INSERT INTO bin_chunks (data_id, idx, bytes) VALUES ($1, $2, $3)`
+	queryDeleteChunk = `DELETE FROM bin_chunks WHERE data_id = $1`
+	queryGetChunk    = `SELECT bytes FROM bin_chunks WHERE data_id = $1 ORDER BY idx`
+
+	queryInsertHistory = `INSERT INTO bin_history (data_id, version, updated_at)
+                          VALUES ($1, $2, $3)
+                          RETURNING id`
+	queryCopyHistoryChunk = `INSERT INTO bin_history_chunks (history_id, idx, bytes)
+                             SELECT $2, idx, bytes
+                             FROM bin_chunks
+                             WHERE data_id = $1`
+	queryPruneHistory = `DELETE FROM bin_history
+                         WHERE data_id = $1 AND version <= $2 - $3::INTEGER`
+	queryRevisions = `SELECT h.version, h.updated_at
+                      FROM bin_history h
+                      JOIN bin_data d ON d.id = h.data_id
+                      WHERE d.user_id = (SELECT id FROM users WHERE email = $1) AND d.meta = $2 AND d.deleted_at IS NULL
+                      ORDER BY h.version DESC`
+	queryGetRevision = `SELECT h.id, h.version, h.updated_at
+                        FROM bin_history h
+                        JOIN bin_data d ON d.id = h.data_id
+                        WHERE d.user_id = (SELECT id FROM users WHERE email = $1) AND d.meta = $2 AND d.deleted_at IS NULL
+                          AND h.version = $3`
+	queryGetHistoryChunk = `SELECT bytes FROM bin_history_chunks WHERE history_id = $1 ORDER BY idx`
+
+	queryListTrash = `SELECT meta, deleted_at
+                      FROM bin_data
+                      WHERE user_id = (SELECT id FROM users WHERE email = $1) AND deleted_at IS NOT NULL
+                      ORDER BY meta`
+	queryUndelete = `UPDATE bin_data
+                     SET deleted_at = NULL
+                     WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NOT NULL`
+	queryPurge = `DELETE FROM bin_data
+                  WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NOT NULL`
+	queryPurgeExpired = `DELETE FROM bin_data WHERE deleted_at < $1`
 )
 
 type PostgresStorage struct {
 	db     *sqlx.DB
 	logger *zap.Logger
+	// retention - Количество хранимых прежних версий записи
+	retention int
 }
 
 // NewPostgresStorage - Создание хранилища в БД Postgres.
-func NewPostgresStorage(db *sqlx.DB) *PostgresStorage {
+// opts - параметры хранения прежних версий данных.
+func NewPostgresStorage(db *sqlx.DB, opts ...history.Options) *PostgresStorage {
 	return &PostgresStorage{
-		db:     db,
-		logger: zap.L(),
+		db:        db,
+		logger:    zap.L(),
+		retention: history.NewConfig(opts...).Retention,
 	}
 }
 
 // Create Создание новых бинарных данных.
-func (store *PostgresStorage) Create(data binary.DataFull) error {
+func (store *PostgresStorage) Create(email string, data binary.DataFull) error {
+	return store.Upload(email, binary.DataUpload{MetaInfo: data.MetaInfo}, bytes.NewReader(data.Bytes))
+}
 
-	if _, err := store.db.ExecContext(context.Background(), queryInsert, data.MetaInfo, data.Bytes); err != nil {
+// Delete Перемещение данных в корзину.
+// В корзине хранится только последнее удаление данных с той же метаинформацией.
+func (store *PostgresStorage) Delete(email string, in binary.DataGet) error {
 
-		pqErr := err.(*pq.Error)
-		if pqErr.Code == pgerrcode.UniqueViolation {
-			return errs.ErrAlreadyExist
-		}
+	ctx := context.Background()
 
-		err = fmt.Errorf("pg error on INSERT: %s. %v", pqErr.Code.Name(), err)
-		store.logger.Error("failed create bin data", zap.Error(err))
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
 		return err
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-// Delete Удаление бинарных данных.
-func (store *PostgresStorage) Delete(in binary.DataGet) error {
+	if _, err = tx.ExecContext(ctx, queryPurge, email, in.MetaInfo); err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed delete bin data", zap.Error(err))
+		return err
+	}
 
-	res, err := store.db.ExecContext(context.Background(), queryDelete, in.MetaInfo)
+	res, err := tx.ExecContext(ctx, queryDelete, email, in.MetaInfo, in.Version)
 	if err != nil {
-		pqErr := err.(*pq.Error)
-		err = fmt.Errorf("pg error on DELETE: %s. %v", pqErr.Code.Name(), err)
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
 		store.logger.Error("failed delete bin data", zap.Error(err))
 		return err
 	}
 
 	if rows, _ := res.RowsAffected(); rows == 0 {
-		return errs.ErrNotFound
+		return store.notChanged(email, in.MetaInfo)
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed delete bin data", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
 // Change Изменение бинарных данных.
-func (store *PostgresStorage) Change(in binary.DataFull) error {
+func (store *PostgresStorage) Change(email string, in binary.DataFull) error {
+	upload := binary.DataUpload{
+		MetaInfo:  in.MetaInfo,
+		Overwrite: true,
+		Version:   in.Version,
+	}
+
+	return store.Upload(email, upload, bytes.NewReader(in.Bytes))
+}
+
+// Get Получение бинарных данных по метаинформации.
+func (store *PostgresStorage) Get(email string, in binary.DataGet) (binary.DataFull, error) {
+
+	var buf bytes.Buffer
+	info, err := store.Download(email, in, &buf)
+	if err != nil {
+		return binary.DataFull{}, err
+	}
+
+	return binary.DataFull{
+		MetaInfo:  in.MetaInfo,
+		Bytes:     buf.Bytes(),
+		Version:   info.Version,
+		UpdatedAt: info.UpdatedAt,
+	}, nil
+}
+
+// List Получение отсортированного списка метаинформации и версий бинарных данных, прошедших фильтр in.
+func (store *PostgresStorage) List(email string, in page.Request) ([]page.Item, error) {
+
+	items := make([]page.Item, 0, in.Limit)
+	if err := store.db.SelectContext(
+		context.Background(),
+		&items,
+		queryList,
+		email,
+		in.PrefixPattern(),
+		in.ContainsPattern(),
+		in.After,
+		in.Limit); err != nil {
+
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed list bin data", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Upload Запись бинарных данных частями по binary.ChunkSize в одной транзакции.
+// Данные целиком в памяти не хранятся.
+func (store *PostgresStorage) Upload(email string, in binary.DataUpload, r io.Reader) error {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
 
-	res, err := store.db.ExecContext(context.Background(), queryUpdate, in.Bytes, in.MetaInfo)
+	if err = store.upload(ctx, tx, email, in, r); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed upload bin data", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ChangeBatch Изменение нескольких записей в одной транзакции.
+func (store *PostgresStorage) ChangeBatch(email string, in []binary.DataFull) error {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, nil)
 	if err != nil {
-		pqErr := err.(*pq.Error)
-		err = fmt.Errorf("pg error on UPDATE: %s. %v", pqErr.Code.Name(), err)
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, data := range in {
+		upload := binary.DataUpload{
+			MetaInfo:  data.MetaInfo,
+			Overwrite: true,
+			Version:   data.Version,
+		}
+
+		if err = store.upload(ctx, tx, email, upload, bytes.NewReader(data.Bytes)); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
 		store.logger.Error("failed update bin data", zap.Error(err))
 		return err
 	}
 
-	if rows, _ := res.RowsAffected(); rows == 0 {
-		return errs.ErrNotFound
+	return nil
+}
+
+// upload - Запись данных, читаемых из r, в транзакции tx по частям binary.ChunkSize.
+func (store *PostgresStorage) upload(ctx context.Context, tx *sqlx.Tx, email string, in binary.DataUpload, r io.Reader) error {
+
+	id, err := store.uploadID(ctx, tx, email, in)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, binary.ChunkSize)
+	for idx := 0; ; idx++ {
+
+		n, errRead := io.ReadFull(r, buf)
+		if n > 0 {
+			if _, err = tx.ExecContext(ctx, queryInsertChunk, id, idx, buf[:n]); err != nil {
+				err = fmt.Errorf("pg error on INSERT chunk: %v", err)
+				store.logger.Error("failed upload bin data", zap.Error(err))
+				return err
+			}
+		}
+
+		if errRead == io.EOF || errRead == io.ErrUnexpectedEOF {
+			return nil
+		}
+
+		if errRead != nil {
+			return errRead
+		}
+	}
+}
+
+// uploadID - Получение идентификатора записи для загрузки данных.
+// При in.Overwrite проверяется версия, старые части данных переносятся в историю
+// и удаляются, иначе создается новая запись.
+func (store *PostgresStorage) uploadID(ctx context.Context, tx *sqlx.Tx, email string, in binary.DataUpload) (int, error) {
+
+	var id int
+
+	if !in.Overwrite {
+		if err := tx.QueryRowContext(ctx, queryInsert, email, in.MetaInfo).Scan(&id); err != nil {
+			// Владелец данных не найден
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, errs.ErrNotFound
+			}
+
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+				return 0, errs.ErrAlreadyExist
+			}
+
+			err = fmt.Errorf("pg error on INSERT: %v", err)
+			store.logger.Error("failed create bin data", zap.Error(err))
+			return 0, err
+		}
+
+		return id, nil
+	}
+
+	var info binary.DataInfo
+	if err := tx.QueryRowContext(ctx, queryLockID, email, in.MetaInfo).Scan(&id, &info.Version, &info.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed update bin data", zap.Error(err))
+		return 0, err
+	}
+
+	if in.Version != info.Version {
+		return 0, errs.ErrConflict
+	}
+
+	if err := store.archive(ctx, tx, id, info); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryUpdate, id); err != nil {
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
+		store.logger.Error("failed update bin data", zap.Error(err))
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryDeleteChunk, id); err != nil {
+		err = fmt.Errorf("pg error on DELETE chunk: %v", err)
+		store.logger.Error("failed update bin data", zap.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Download Запись бинарных данных в w по частям, в порядке их сохранения.
+func (store *PostgresStorage) Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error) {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return binary.DataInfo{}, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	info := binary.DataInfo{MetaInfo: in.MetaInfo}
+	if err = tx.QueryRowContext(ctx, queryGetID, email, in.MetaInfo).Scan(&id, &info.Version, &info.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return binary.DataInfo{}, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on GET: %v", err)
+		store.logger.Error("failed get bin data", zap.Error(err))
+		return binary.DataInfo{}, err
+	}
+
+	rows, err := tx.QueryContext(ctx, queryGetChunk, id)
+	if err != nil {
+		err = fmt.Errorf("pg error on GET chunk: %v", err)
+		store.logger.Error("failed get bin data", zap.Error(err))
+		return binary.DataInfo{}, err
+	}
+	defer rows.Close()
+
+	var chunk []byte
+	for rows.Next() {
+		if err = rows.Scan(&chunk); err != nil {
+			err = fmt.Errorf("pg error on GET chunk: %v", err)
+			store.logger.Error("failed get bin data", zap.Error(err))
+			return binary.DataInfo{}, err
+		}
+
+		if _, err = w.Write(chunk); err != nil {
+			return binary.DataInfo{}, err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on GET chunk: %v", err)
+		store.logger.Error("failed get bin data", zap.Error(err))
+		return binary.DataInfo{}, err
+	}
+
+	return info, tx.Commit()
+}
+
+// archive - Копирование текущих частей данных id версии info в историю
+// и удаление версий сверх retention.
+func (store *PostgresStorage) archive(ctx context.Context, tx *sqlx.Tx, id int, info binary.DataInfo) error {
+
+	if store.retention == 0 {
+		return nil
+	}
+
+	var historyID int
+	if err := tx.QueryRowContext(ctx, queryInsertHistory, id, info.Version, info.UpdatedAt).Scan(&historyID); err != nil {
+		err = fmt.Errorf("pg error on INSERT history: %v", err)
+		store.logger.Error("failed archive bin data", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryCopyHistoryChunk, id, historyID); err != nil {
+		err = fmt.Errorf("pg error on INSERT history chunk: %v", err)
+		store.logger.Error("failed archive bin data", zap.Error(err))
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryPruneHistory, id, info.Version, store.retention); err != nil {
+		err = fmt.Errorf("pg error on DELETE history: %v", err)
+		store.logger.Error("failed archive bin data", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
-// Get Получение бинарных данных по метаинформации.
-func (store *PostgresStorage) Get(in binary.DataGet) (binary.DataFull, error) {
+// ListRevisions Получение прежних версий данных, от новых к старым.
+func (store *PostgresStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+
+	rows, err := store.db.QueryContext(context.Background(), queryRevisions, email, meta)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT history: %v", err)
+		store.logger.Error("failed list bin revisions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	revs := make([]revision.Revision, 0)
+	for rows.Next() {
+		var rev revision.Revision
+		if err = rows.Scan(&rev.Version, &rev.UpdatedAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT history: %v", err)
+			store.logger.Error("failed list bin revisions", zap.Error(err))
+			return nil, err
+		}
+
+		revs = append(revs, rev)
+	}
 
-	row := store.db.QueryRowContext(context.Background(), queryGet, in.MetaInfo)
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT history: %v", err)
+		store.logger.Error("failed list bin revisions", zap.Error(err))
+		return nil, err
+	}
+
+	// Пустая история - нужно отличить запись без прежних версий от отсутствующей
+	if len(revs) == 0 {
+		if err = store.notChanged(email, meta); !errors.Is(err, errs.ErrConflict) {
+			return nil, err
+		}
+	}
 
-	var data []byte
-	if err := row.Scan(&data); err != nil {
+	return revs, nil
+}
+
+// GetRevision Получение прежней версии бинарных данных.
+func (store *PostgresStorage) GetRevision(email string, in revision.Request) (binary.DataFull, error) {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return binary.DataFull{}, err
+	}
+	defer tx.Rollback()
+
+	var historyID int
+	data := binary.DataFull{MetaInfo: in.MetaInfo}
+	err = tx.QueryRowContext(ctx, queryGetRevision, email, in.MetaInfo, in.Version).Scan(&historyID, &data.Version, &data.UpdatedAt)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return binary.DataFull{}, errs.ErrNotFound
 		}
 
-		pqErr := err.(*pq.Error)
-		err = fmt.Errorf("pg error on GET: %s. %v", pqErr.Code.Name(), err)
-		store.logger.Error("failed get bin data", zap.Error(err))
+		err = fmt.Errorf("pg error on GET history: %v", err)
+		store.logger.Error("failed get bin revision", zap.Error(err))
 		return binary.DataFull{}, err
 	}
 
-	return binary.DataFull{
-		MetaInfo: in.MetaInfo,
-		Bytes:    data,
-	}, nil
+	var chunks [][]byte
+	if err = tx.SelectContext(ctx, &chunks, queryGetHistoryChunk, historyID); err != nil {
+		err = fmt.Errorf("pg error on GET history chunk: %v", err)
+		store.logger.Error("failed get bin revision", zap.Error(err))
+		return binary.DataFull{}, err
+	}
+
+	data.Bytes = bytes.Join(chunks, nil)
+	return data, tx.Commit()
+}
+
+// ListTrash Получение удаленных данных, отсортированных по метаинформации.
+func (store *PostgresStorage) ListTrash(email string) ([]trash.Item, error) {
+
+	rows, err := store.db.QueryContext(context.Background(), queryListTrash, email)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT trash: %v", err)
+		store.logger.Error("failed list bin data trash", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]trash.Item, 0)
+	for rows.Next() {
+		var item trash.Item
+		if err = rows.Scan(&item.MetaInfo, &item.DeletedAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT trash: %v", err)
+			store.logger.Error("failed list bin data trash", zap.Error(err))
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT trash: %v", err)
+		store.logger.Error("failed list bin data trash", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Undelete Восстановление удаленных данных из корзины.
+func (store *PostgresStorage) Undelete(email, meta string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryUndelete, email, meta)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+			return errs.ErrAlreadyExist
+		}
+
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
+		store.logger.Error("failed undelete bin data", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// Purge Окончательное удаление данных из корзины.
+func (store *PostgresStorage) Purge(email, meta string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryPurge, email, meta)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed purge bin data", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeExpired Окончательное удаление данных всех пользователей, удаленных раньше before.
+func (store *PostgresStorage) PurgeExpired(before time.Time) (int, error) {
+
+	res, err := store.db.ExecContext(context.Background(), queryPurgeExpired, before)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed purge expired bin data", zap.Error(err))
+		return 0, err
+	}
+
+	rows, _ := res.RowsAffected()
+	return int(rows), nil
+}
+
+// notChanged - Причина, по которой данные не удалены:
+// errs.ErrNotFound, если данных нет, иначе errs.ErrConflict - не совпала версия.
+func (store *PostgresStorage) notChanged(email, meta string) error {
+
+	var id int
+	var info binary.DataInfo
+
+	err := store.db.QueryRowContext(context.Background(), queryGetID, email, meta).Scan(&id, &info.Version, &info.UpdatedAt)
+	switch {
+	case err == nil:
+		return errs.ErrConflict
+
+	case errors.Is(err, sql.ErrNoRows):
+		return errs.ErrNotFound
+	}
+
+	err = fmt.Errorf("pg error on SELECT: %v", err)
+	store.logger.Error("failed get bin data version", zap.Error(err))
+	return err
 }
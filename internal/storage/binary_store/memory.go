@@ -1,82 +1,329 @@
 package binary_store
 
 import (
+	"io"
+	"sort"
 	"sync"
+	"time"
 
 	"GophKeeper/internal/server/model/binary"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
+	"GophKeeper/internal/storage/history"
+	"GophKeeper/internal/storage/trashcan"
 	"GophKeeper/pkg/errs"
 )
 
 type MemoryStorage struct {
 	mutex sync.RWMutex
-	creds []binary.DataFull
+	// creds - Бинарные данные по email владельца
+	creds map[string][]binary.DataFull
+	// history - Прежние версии данных
+	history *history.Memory[binary.DataFull]
+	// trash - Удаленные данные
+	trash *trashcan.Memory[binary.DataFull]
 }
 
-func NewMemoryStorage() *MemoryStorage {
-	return &MemoryStorage{}
+// NewMemoryStorage - Создание хранилища в памяти.
+// opts - параметры хранения прежних версий данных.
+func NewMemoryStorage(opts ...history.Options) *MemoryStorage {
+	return &MemoryStorage{
+		creds:   make(map[string][]binary.DataFull),
+		history: history.NewMemory[binary.DataFull](history.NewConfig(opts...)),
+		trash:   trashcan.NewMemory[binary.DataFull](),
+	}
 }
 
-func (store *MemoryStorage) Create(in binary.DataFull) error {
+func (store *MemoryStorage) Create(email string, in binary.DataFull) error {
 
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
 
-	_, err := store.Find(in.MetaInfo)
+	_, err := store.Find(email, in.MetaInfo)
 	if err == nil {
 		return errs.ErrAlreadyExist
 	}
 
-	store.creds = append(store.creds, in)
+	in.Version = 1
+	in.UpdatedAt = time.Now()
+
+	// Новые данные начинают историю версий заново
+	store.history.Forget(email, in.MetaInfo)
+
+	store.creds[email] = append(store.creds[email], in)
 	return nil
 }
 
-func (store *MemoryStorage) Get(in binary.DataGet) (binary.DataFull, error) {
+func (store *MemoryStorage) Get(email string, in binary.DataGet) (binary.DataFull, error) {
 
 	store.mutex.RLock()
 	defer store.mutex.RUnlock()
 
-	idx, err := store.Find(in.MetaInfo)
+	idx, err := store.Find(email, in.MetaInfo)
 	if err != nil {
 		return binary.DataFull{}, err
 	}
 
-	return store.creds[idx], nil
+	return store.creds[email][idx], nil
 }
 
-func (store *MemoryStorage) Delete(in binary.DataGet) error {
+func (store *MemoryStorage) Delete(email string, in binary.DataGet) error {
 
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
 
-	idx, err := store.Find(in.MetaInfo)
+	idx, err := store.Find(email, in.MetaInfo)
 	if err != nil {
 		return err
 	}
 
+	if in.Version != store.creds[email][idx].Version {
+		return errs.ErrConflict
+	}
+
+	// Данные перемещаются в корзину вместе с историей версий
+	store.trash.Put(email, in.MetaInfo, store.creds[email][idx], time.Now())
+
 	// Удаление из найденного элемента из слайса
-	store.creds[idx] = store.creds[len(store.creds)-1]
-	store.creds = store.creds[:len(store.creds)-1]
+	creds := store.creds[email]
+	creds[idx] = creds[len(creds)-1]
+	store.creds[email] = creds[:len(creds)-1]
 
 	return nil
 }
 
-func (store *MemoryStorage) Change(in binary.DataFull) error {
+func (store *MemoryStorage) Change(email string, in binary.DataFull) error {
 
 	store.mutex.Lock()
 	defer store.mutex.Unlock()
 
-	idx, err := store.Find(in.MetaInfo)
+	if err := store.check(email, in); err != nil {
+		return err
+	}
+
+	store.change(email, in)
+	return nil
+}
+
+// ChangeBatch - Изменение нескольких записей пользователя email.
+// Записи изменяются, только если все они найдены и их версии совпадают с ожидаемыми.
+func (store *MemoryStorage) ChangeBatch(email string, in []binary.DataFull) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, data := range in {
+		if err := store.check(email, data); err != nil {
+			return err
+		}
+	}
+
+	for _, data := range in {
+		store.change(email, data)
+	}
+
+	return nil
+}
+
+// check - Проверка, что данные in есть и их версия совпадает с ожидаемой.
+func (store *MemoryStorage) check(email string, in binary.DataFull) error {
+
+	idx, err := store.Find(email, in.MetaInfo)
+	if err != nil {
+		return err
+	}
+
+	if in.Version != store.creds[email][idx].Version {
+		return errs.ErrConflict
+	}
+
+	return nil
+}
+
+// change - Изменение проверенных данных с сохранением прежней версии в истории.
+func (store *MemoryStorage) change(email string, in binary.DataFull) {
+
+	idx, _ := store.Find(email, in.MetaInfo)
+
+	creds := store.creds[email]
+	prev := creds[idx]
+	store.history.Push(email, in.MetaInfo, revision.Revision{Version: prev.Version, UpdatedAt: prev.UpdatedAt}, prev)
+
+	creds[idx].Bytes = in.Bytes
+	creds[idx].Version++
+	creds[idx].UpdatedAt = time.Now()
+}
+
+// ListRevisions - Прежние версии данных meta пользователя email, от новых к старым.
+func (store *MemoryStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if _, err := store.Find(email, meta); err != nil {
+		return nil, err
+	}
+
+	return store.history.List(email, meta), nil
+}
+
+// GetRevision - Прежняя версия in.Version данных пользователя email.
+func (store *MemoryStorage) GetRevision(email string, in revision.Request) (binary.DataFull, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if _, err := store.Find(email, in.MetaInfo); err != nil {
+		return binary.DataFull{}, err
+	}
+
+	data, _, ok := store.history.Get(email, in.MetaInfo, in.Version)
+	if !ok {
+		return binary.DataFull{}, errs.ErrNotFound
+	}
+
+	return data, nil
+}
+
+// List - Получение отсортированной метаинформации и версий данных пользователя email,
+// прошедших фильтр in. Не более in.Limit записей, если он задан.
+func (store *MemoryStorage) List(email string, in page.Request) ([]page.Item, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	items := make([]page.Item, 0)
+	for _, data := range store.creds[email] {
+		if in.Match(data.MetaInfo) {
+			items = append(items, page.Item{MetaInfo: data.MetaInfo, Version: data.Version})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].MetaInfo < items[j].MetaInfo
+	})
+
+	if in.Limit > 0 && len(items) > in.Limit {
+		items = items[:in.Limit]
+	}
+
+	return items, nil
+}
+
+// Upload - Сохранение данных, прочитанных из r.
+// Чтение выполняется до блокировки хранилища.
+func (store *MemoryStorage) Upload(email string, in binary.DataUpload, r io.Reader) error {
+
+	data, err := io.ReadAll(r)
 	if err != nil {
 		return err
 	}
 
-	store.creds[idx].Bytes = in.Bytes
+	full := binary.DataFull{
+		MetaInfo: in.MetaInfo,
+		Bytes:    data,
+		Version:  in.Version,
+	}
+
+	if in.Overwrite {
+		return store.Change(email, full)
+	}
+
+	return store.Create(email, full)
+}
+
+// Download - Запись данных в w.
+// Запись выполняется после снятия блокировки хранилища.
+func (store *MemoryStorage) Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error) {
+
+	data, err := store.Get(email, in)
+	if err != nil {
+		return binary.DataInfo{}, err
+	}
+
+	info := binary.DataInfo{
+		MetaInfo:  data.MetaInfo,
+		Version:   data.Version,
+		UpdatedAt: data.UpdatedAt,
+	}
+
+	_, err = w.Write(data.Bytes)
+	return info, err
+}
+
+// ListTrash - Удаленные данные пользователя email, отсортированные по метаинформации.
+func (store *MemoryStorage) ListTrash(email string) ([]trash.Item, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.trash.List(email), nil
+}
+
+// Undelete - Восстановление удаленных данных meta из корзины.
+func (store *MemoryStorage) Undelete(email, meta string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, err := store.Find(email, meta); err == nil {
+		return errs.ErrAlreadyExist
+	}
+
+	data, ok := store.trash.Take(email, meta)
+	if !ok {
+		return errs.ErrNotFound
+	}
+
+	store.creds[email] = append(store.creds[email], data)
+	return nil
+}
+
+// Purge - Окончательное удаление данных meta из корзины.
+func (store *MemoryStorage) Purge(email, meta string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if !store.trash.Remove(email, meta) {
+		return errs.ErrNotFound
+	}
+
+	store.forget(email, meta)
 	return nil
 }
 
-func (store *MemoryStorage) Find(metaInfo string) (int, error) {
+// PurgeExpired - Окончательное удаление данных всех пользователей, удаленных раньше before.
+func (store *MemoryStorage) PurgeExpired(before time.Time) (int, error) {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var count int
+	for email, metas := range store.trash.Expire(before) {
+		for _, meta := range metas {
+			store.forget(email, meta)
+		}
+		count += len(metas)
+	}
+
+	return count, nil
+}
+
+// forget - Удаление истории версий окончательно удаленных данных,
+// если данных с такой метаинформацией больше нет.
+func (store *MemoryStorage) forget(email, meta string) {
+
+	if _, err := store.Find(email, meta); err != nil {
+		store.history.Forget(email, meta)
+	}
+}
+
+// Find - Поиск индекса данных пользователя email по метаинформации.
+func (store *MemoryStorage) Find(email, metaInfo string) (int, error) {
 
-	for idx, data := range store.creds {
+	for idx, data := range store.creds[email] {
 		if data.MetaInfo == metaInfo {
 			return idx, nil
 		}
@@ -84,3 +331,17 @@ func (store *MemoryStorage) Find(metaInfo string) (int, error) {
 
 	return -1, errs.ErrNotFound
 }
+
+// DeleteAll - Окончательное удаление всех данных пользователя email
+// вместе с прежними версиями и корзиной.
+func (store *MemoryStorage) DeleteAll(email string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.creds, email)
+	store.history.ForgetAll(email)
+	store.trash.Clear(email)
+
+	return nil
+}
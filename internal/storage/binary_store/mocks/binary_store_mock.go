@@ -6,7 +6,12 @@ package binary_store
 
 import (
 	binary "GophKeeper/internal/server/model/binary"
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
+	trash "GophKeeper/internal/server/model/trash"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,58 +40,204 @@ func (m *MockBinaryStorage) EXPECT() *MockBinaryStorageMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockBinaryStorage) Change(in binary.DataFull) error {
+func (m *MockBinaryStorage) Change(email string, in binary.DataFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockBinaryStorageMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockBinaryStorageMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockBinaryStorage)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockBinaryStorage)(nil).Change), email, in)
+}
+
+// ChangeBatch mocks base method.
+func (m *MockBinaryStorage) ChangeBatch(email string, in []binary.DataFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeBatch", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeBatch indicates an expected call of ChangeBatch.
+func (mr *MockBinaryStorageMockRecorder) ChangeBatch(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeBatch", reflect.TypeOf((*MockBinaryStorage)(nil).ChangeBatch), email, in)
 }
 
 // Create mocks base method.
-func (m *MockBinaryStorage) Create(in binary.DataFull) error {
+func (m *MockBinaryStorage) Create(email string, in binary.DataFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", in)
+	ret := m.ctrl.Call(m, "Create", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockBinaryStorageMockRecorder) Create(in interface{}) *gomock.Call {
+func (mr *MockBinaryStorageMockRecorder) Create(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBinaryStorage)(nil).Create), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBinaryStorage)(nil).Create), email, in)
 }
 
 // Delete mocks base method.
-func (m *MockBinaryStorage) Delete(in binary.DataGet) error {
+func (m *MockBinaryStorage) Delete(email string, in binary.DataGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockBinaryStorageMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockBinaryStorageMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBinaryStorage)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBinaryStorage)(nil).Delete), email, in)
+}
+
+// Download mocks base method.
+func (m *MockBinaryStorage) Download(email string, in binary.DataGet, w io.Writer) (binary.DataInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", email, in, w)
+	ret0, _ := ret[0].(binary.DataInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Download indicates an expected call of Download.
+func (mr *MockBinaryStorageMockRecorder) Download(email, in, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockBinaryStorage)(nil).Download), email, in, w)
 }
 
 // Get mocks base method.
-func (m *MockBinaryStorage) Get(in binary.DataGet) (binary.DataFull, error) {
+func (m *MockBinaryStorage) Get(email string, in binary.DataGet) (binary.DataFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(binary.DataFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockBinaryStorageMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockBinaryStorageMockRecorder) Get(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBinaryStorage)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockBinaryStorage) GetRevision(email string, in revision.Request) (binary.DataFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(binary.DataFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockBinaryStorageMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockBinaryStorage)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockBinaryStorage) List(email string, in page.Request) ([]page.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].([]page.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockBinaryStorageMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockBinaryStorage)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockBinaryStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockBinaryStorageMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockBinaryStorage)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockBinaryStorage) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockBinaryStorageMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockBinaryStorage)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockBinaryStorage) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockBinaryStorageMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockBinaryStorage)(nil).Purge), email, meta)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockBinaryStorage) PurgeExpired(before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockBinaryStorageMockRecorder) PurgeExpired(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockBinaryStorage)(nil).PurgeExpired), before)
+}
+
+// Undelete mocks base method.
+func (m *MockBinaryStorage) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockBinaryStorageMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockBinaryStorage)(nil).Undelete), email, meta)
+}
+
+// Upload mocks base method.
+func (m *MockBinaryStorage) Upload(email string, in binary.DataUpload, r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", email, in, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockBinaryStorageMockRecorder) Upload(email, in, r interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBinaryStorage)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockBinaryStorage)(nil).Upload), email, in, r)
 }
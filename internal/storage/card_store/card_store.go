@@ -1,12 +1,41 @@
 package card_store
 
 import (
+	"time"
+
 	"GophKeeper/internal/server/model/card"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 )
 
+// CardStorage - Хранилище данных банковских карт.
+// Все операции выполняются в рамках данных пользователя с указанным email.
 type CardStorage interface {
-	Create(data card.DataCardFull) error
-	Get(in card.DataCardGet) (card.DataCardFull, error)
-	Delete(in card.DataCardGet) error
-	Change(in card.DataCardFull) error
+	Create(email string, data card.DataCardFull) error
+	Get(email string, in card.DataCardGet) (card.DataCardFull, error)
+	Delete(email string, in card.DataCardGet) error
+	Change(email string, in card.DataCardFull) error
+	// ChangeBatch - Изменение нескольких записей: все или ни одной.
+	// errs.ErrNotFound или errs.ErrConflict, если хотя бы одна запись не найдена
+	// или изменена с ожидаемой версии.
+	ChangeBatch(email string, in []card.DataCardFull) error
+	List(email string, in page.Request) ([]page.Item, error)
+
+	// ListRevisions - Прежние версии данных meta, от новых к старым.
+	// errs.ErrNotFound, если данных нет.
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	// GetRevision - Прежняя версия данных, errs.ErrNotFound, если ее нет.
+	GetRevision(email string, in revision.Request) (card.DataCardFull, error)
+
+	// ListTrash - Удаленные данные, отсортированные по метаинформации.
+	ListTrash(email string) ([]trash.Item, error)
+	// Undelete - Восстановление удаленных данных. errs.ErrNotFound, если их нет в корзине,
+	// errs.ErrAlreadyExist, если после удаления созданы данные с той же метаинформацией.
+	Undelete(email, meta string) error
+	// Purge - Окончательное удаление данных из корзины.
+	Purge(email, meta string) error
+	// PurgeExpired - Окончательное удаление данных всех пользователей, удаленных раньше before.
+	// Возвращает количество удаленных записей.
+	PurgeExpired(before time.Time) (int, error)
 }
@@ -2,12 +2,41 @@
 package credential_store
 
 import (
+	"time"
+
 	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
 )
 
+// CredStorage - Хранилище логинов и паролей.
+// Все операции выполняются в рамках данных пользователя с указанным email.
 type CredStorage interface {
-	Create(data cred.CredentialFull) error
-	Get(in cred.CredentialGet) (cred.CredentialFull, error)
-	Delete(in cred.CredentialGet) error
-	Change(in cred.CredentialFull) error
+	Create(email string, data cred.CredentialFull) error
+	Get(email string, in cred.CredentialGet) (cred.CredentialFull, error)
+	Delete(email string, in cred.CredentialGet) error
+	Change(email string, in cred.CredentialFull) error
+	// ChangeBatch - Изменение нескольких записей: все или ни одной.
+	// errs.ErrNotFound или errs.ErrConflict, если хотя бы одна запись не найдена
+	// или изменена с ожидаемой версии.
+	ChangeBatch(email string, in []cred.CredentialFull) error
+	List(email string, in page.Request) ([]page.Item, error)
+
+	// ListRevisions - Прежние версии данных meta, от новых к старым.
+	// errs.ErrNotFound, если данных нет.
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	// GetRevision - Прежняя версия данных, errs.ErrNotFound, если ее нет.
+	GetRevision(email string, in revision.Request) (cred.CredentialFull, error)
+
+	// ListTrash - Удаленные данные, отсортированные по метаинформации.
+	ListTrash(email string) ([]trash.Item, error)
+	// Undelete - Восстановление удаленных данных. errs.ErrNotFound, если их нет в корзине,
+	// errs.ErrAlreadyExist, если после удаления созданы данные с той же метаинформацией.
+	Undelete(email, meta string) error
+	// Purge - Окончательное удаление данных из корзины.
+	Purge(email, meta string) error
+	// PurgeExpired - Окончательное удаление данных всех пользователей, удаленных раньше before.
+	// Возвращает количество удаленных записей.
+	PurgeExpired(before time.Time) (int, error)
 }
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jmoiron/sqlx"
@@ -12,40 +13,102 @@ import (
 	"go.uber.org/zap"
 
 	"GophKeeper/internal/server/model/cred"
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
+	"GophKeeper/internal/server/model/trash"
+	"GophKeeper/internal/storage/history"
 	"GophKeeper/pkg/errs"
 )
 
 var (
-	queryInsert = `INSERT INTO cred_data (meta, email, password_hash) 
-                   VALUES ($1, $2, $3)`
-	queryDelete = `DELETE FROM cred_data 
-                   WHERE meta = $1`
-	queryUpdate = `UPDATE cred_data
-                   SET email = $1, password_hash = $2
-                   WHERE meta = $3`
-	queryGet = `SELECT email, password_hash
+	queryInsert = `INSERT INTO cred_data (user_id, meta, email, password_hash)
+                   SELECT id, $2, $3, $4
+                   FROM users
+                   WHERE email = $1`
+	// queryDelete - Перемещение данных в корзину
+	queryDelete = `UPDATE cred_data
+                   SET deleted_at = now()
+                   WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL
+                     AND version = $3`
+	// queryUpdate - Изменение данных с сохранением прежней версии в истории
+	// и удалением версий сверх $6 (количество хранимых версий).
+	queryUpdate = `WITH cur AS (
+                       SELECT id, version, updated_at, email, password_hash
+                       FROM cred_data
+                       WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL
+                         AND version = $5
+                       FOR UPDATE
+                   ), saved AS (
+                       INSERT INTO cred_history (data_id, version, updated_at, email, password_hash)
+                       SELECT id, version, updated_at, email, password_hash
+                       FROM cur
+                       WHERE $6::INTEGER > 0
+                   ), pruned AS (
+                       DELETE FROM cred_history h
+                       USING cur
+                       WHERE h.data_id = cur.id AND h.version <= cur.version - $6::INTEGER
+                   )
+                   UPDATE cred_data
+                   SET email = $3, password_hash = $4, version = cur.version + 1, updated_at = now()
+                   FROM cur
+                   WHERE cred_data.id = cur.id`
+	queryGet = `SELECT email, password_hash, version, updated_at
                 FROM cred_data 
-                WHERE meta = $1`
+                WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL`
+	queryVersion = `SELECT version
+                    FROM cred_data
+                    WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL`
+	queryList = `SELECT meta, version
+                 FROM cred_data
+                 WHERE user_id = (SELECT id FROM users WHERE email = $1)
+                   AND deleted_at IS NULL AND meta LIKE $2 AND meta LIKE $3 AND meta > $4
+                 ORDER BY meta
+                 LIMIT $5`
+	queryRevisions = `SELECT h.version, h.updated_at
+                      FROM cred_history h
+                      JOIN cred_data d ON d.id = h.data_id
+                      WHERE d.user_id = (SELECT id FROM users WHERE email = $1) AND d.meta = $2 AND d.deleted_at IS NULL
+                      ORDER BY h.version DESC`
+	queryGetRevision = `SELECT h.email, h.password_hash, h.version, h.updated_at
+                        FROM cred_history h
+                        JOIN cred_data d ON d.id = h.data_id
+                        WHERE d.user_id = (SELECT id FROM users WHERE email = $1) AND d.meta = $2 AND d.deleted_at IS NULL
+                          AND h.version = $3`
+
+	queryListTrash = `SELECT meta, deleted_at
+                      FROM cred_data
+                      WHERE user_id = (SELECT id FROM users WHERE email = $1) AND deleted_at IS NOT NULL
+                      ORDER BY meta`
+	queryUndelete = `UPDATE cred_data
+                     SET deleted_at = NULL
+                     WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NOT NULL`
+	queryPurge = `DELETE FROM cred_data
+                  WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NOT NULL`
+	queryPurgeExpired = `DELETE FROM cred_data WHERE deleted_at < $1`
 )
 
 type PostgresStorage struct {
 	db     *sqlx.DB
 	logger *zap.Logger
+	// retention - Количество хранимых прежних версий записи
+	retention int
 }
 
 // NewPostgresStorage - Создание хранилища в БД Postgres
-func NewPostgresStorage(db *sqlx.DB) *PostgresStorage {
+// opts - параметры хранения прежних версий данных.
+func NewPostgresStorage(db *sqlx.DB, opts ...history.Options) *PostgresStorage {
 	return &PostgresStorage{
-		db:     db,
-		logger: zap.L(),
+		db:        db,
+		logger:    zap.L(),
+		retention: history.NewConfig(opts...).Retention,
 	}
 }
 
 // Create Создание новых данных.
-func (store *PostgresStorage) Create(data cred.CredentialFull) error {
-
-	if _, err := store.db.ExecContext(context.Background(), queryInsert, data.MetaInfo, data.Email, data.Password); err != nil {
+func (store *PostgresStorage) Create(email string, data cred.CredentialFull) error {
 
+	res, err := store.db.ExecContext(context.Background(), queryInsert, email, data.MetaInfo, data.Email, data.Password)
+	if err != nil {
 		pqErr := err.(*pq.Error)
 		if pqErr.Code == pgerrcode.UniqueViolation {
 			return errs.ErrAlreadyExist
@@ -55,31 +118,58 @@ func (store *PostgresStorage) Create(data cred.CredentialFull) error {
 		store.logger.Error("failed create cred data", zap.Error(err))
 		return err
 	}
+
+	// Владелец данных не найден
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
 	return nil
 }
 
-// Delete Удаление данных.
-func (store *PostgresStorage) Delete(in cred.CredentialGet) error {
+// Delete Перемещение данных в корзину.
+// В корзине хранится только последнее удаление данных с той же метаинформацией.
+func (store *PostgresStorage) Delete(email string, in cred.CredentialGet) error {
+
+	ctx := context.Background()
 
-	res, err := store.db.ExecContext(context.Background(), queryDelete, in.MetaInfo)
+	tx, err := store.db.BeginTxx(ctx, nil)
 	if err != nil {
-		pqErr := err.(*pq.Error)
-		err = fmt.Errorf("pg error on DELETE: %s. %v", pqErr.Code.Name(), err)
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, queryPurge, email, in.MetaInfo); err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed delete cred data", zap.Error(err))
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, queryDelete, email, in.MetaInfo, in.Version)
+	if err != nil {
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
 		store.logger.Error("failed delete cred data", zap.Error(err))
 		return err
 	}
 
 	if rows, _ := res.RowsAffected(); rows == 0 {
-		return errs.ErrNotFound
+		return store.notChanged(email, in.MetaInfo)
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed delete cred data", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
 // Change Изменение текстовых данных.
-func (store *PostgresStorage) Change(in cred.CredentialFull) error {
+func (store *PostgresStorage) Change(email string, in cred.CredentialFull) error {
 
-	res, err := store.db.ExecContext(context.Background(), queryUpdate, in.Email, in.Password, in.MetaInfo)
+	res, err := store.db.ExecContext(context.Background(), queryUpdate, email, in.MetaInfo, in.Email, in.Password, in.Version, store.retention)
 	if err != nil {
 		pqErr := err.(*pq.Error)
 		err = fmt.Errorf("pg error on UPDATE: %s. %v", pqErr.Code.Name(), err)
@@ -88,21 +178,57 @@ func (store *PostgresStorage) Change(in cred.CredentialFull) error {
 	}
 
 	if rows, _ := res.RowsAffected(); rows == 0 {
-		return errs.ErrNotFound
+		return store.notChanged(email, in.MetaInfo)
+	}
+
+	return nil
+}
+
+// ChangeBatch Изменение нескольких записей в одной транзакции.
+func (store *PostgresStorage) ChangeBatch(email string, in []cred.CredentialFull) error {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, data := range in {
+
+		res, errExec := tx.ExecContext(ctx, queryUpdate, email, data.MetaInfo, data.Email, data.Password, data.Version, store.retention)
+		if errExec != nil {
+			err = fmt.Errorf("pg error on UPDATE: %v", errExec)
+			store.logger.Error("failed update cred data", zap.Error(err))
+			return err
+		}
+
+		if rows, _ := res.RowsAffected(); rows == 0 {
+			return store.notChanged(email, data.MetaInfo)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed update cred data", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
 // Get Получение текстовых данных по метаинформации.
-func (store *PostgresStorage) Get(in cred.CredentialGet) (cred.CredentialFull, error) {
+func (store *PostgresStorage) Get(email string, in cred.CredentialGet) (cred.CredentialFull, error) {
 
-	row := store.db.QueryRowContext(context.Background(), queryGet, in.MetaInfo)
+	row := store.db.QueryRowContext(context.Background(), queryGet, email, in.MetaInfo)
 
-	var email string
-	var pwd string
+	data := cred.CredentialFull{
+		MetaInfo: in.MetaInfo,
+	}
 
-	if err := row.Scan(&email, &pwd); err != nil {
+	if err := row.Scan(&data.Email, &data.Password, &data.Version, &data.UpdatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return cred.CredentialFull{}, errs.ErrNotFound
 		}
@@ -113,9 +239,192 @@ func (store *PostgresStorage) Get(in cred.CredentialGet) (cred.CredentialFull, e
 		return cred.CredentialFull{}, err
 	}
 
-	return cred.CredentialFull{
+	return data, nil
+}
+
+// List Получение отсортированного списка метаинформации и версий логинов и паролей, прошедших фильтр in.
+func (store *PostgresStorage) List(email string, in page.Request) ([]page.Item, error) {
+
+	items := make([]page.Item, 0, in.Limit)
+	if err := store.db.SelectContext(
+		context.Background(),
+		&items,
+		queryList,
+		email,
+		in.PrefixPattern(),
+		in.ContainsPattern(),
+		in.After,
+		in.Limit); err != nil {
+
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed list cred data", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ListRevisions Получение прежних версий данных, от новых к старым.
+func (store *PostgresStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+
+	rows, err := store.db.QueryContext(context.Background(), queryRevisions, email, meta)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT history: %v", err)
+		store.logger.Error("failed list cred revisions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	revs := make([]revision.Revision, 0)
+	for rows.Next() {
+		var rev revision.Revision
+		if err = rows.Scan(&rev.Version, &rev.UpdatedAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT history: %v", err)
+			store.logger.Error("failed list cred revisions", zap.Error(err))
+			return nil, err
+		}
+
+		revs = append(revs, rev)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT history: %v", err)
+		store.logger.Error("failed list cred revisions", zap.Error(err))
+		return nil, err
+	}
+
+	// Пустая история - нужно отличить запись без прежних версий от отсутствующей
+	if len(revs) == 0 {
+		if err = store.notChanged(email, meta); !errors.Is(err, errs.ErrConflict) {
+			return nil, err
+		}
+	}
+
+	return revs, nil
+}
+
+// GetRevision Получение прежней версии данных.
+func (store *PostgresStorage) GetRevision(email string, in revision.Request) (cred.CredentialFull, error) {
+
+	row := store.db.QueryRowContext(context.Background(), queryGetRevision, email, in.MetaInfo, in.Version)
+
+	data := cred.CredentialFull{
 		MetaInfo: in.MetaInfo,
-		Email:    email,
-		Password: pwd,
-	}, nil
+	}
+
+	if err := row.Scan(&data.Email, &data.Password, &data.Version, &data.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return cred.CredentialFull{}, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on GET history: %v", err)
+		store.logger.Error("failed get cred revision", zap.Error(err))
+		return cred.CredentialFull{}, err
+	}
+
+	return data, nil
+}
+
+// ListTrash Получение удаленных данных, отсортированных по метаинформации.
+func (store *PostgresStorage) ListTrash(email string) ([]trash.Item, error) {
+
+	rows, err := store.db.QueryContext(context.Background(), queryListTrash, email)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT trash: %v", err)
+		store.logger.Error("failed list cred data trash", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]trash.Item, 0)
+	for rows.Next() {
+		var item trash.Item
+		if err = rows.Scan(&item.MetaInfo, &item.DeletedAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT trash: %v", err)
+			store.logger.Error("failed list cred data trash", zap.Error(err))
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT trash: %v", err)
+		store.logger.Error("failed list cred data trash", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Undelete Восстановление удаленных данных из корзины.
+func (store *PostgresStorage) Undelete(email, meta string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryUndelete, email, meta)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+			return errs.ErrAlreadyExist
+		}
+
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
+		store.logger.Error("failed undelete cred data", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// Purge Окончательное удаление данных из корзины.
+func (store *PostgresStorage) Purge(email, meta string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryPurge, email, meta)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed purge cred data", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeExpired Окончательное удаление данных всех пользователей, удаленных раньше before.
+func (store *PostgresStorage) PurgeExpired(before time.Time) (int, error) {
+
+	res, err := store.db.ExecContext(context.Background(), queryPurgeExpired, before)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed purge expired cred data", zap.Error(err))
+		return 0, err
+	}
+
+	rows, _ := res.RowsAffected()
+	return int(rows), nil
+}
+
+// notChanged - Причина, по которой данные не изменены:
+// errs.ErrNotFound, если данных нет, иначе errs.ErrConflict - не совпала версия.
+func (store *PostgresStorage) notChanged(email, meta string) error {
+
+	var version int64
+	err := store.db.QueryRowContext(context.Background(), queryVersion, email, meta).Scan(&version)
+	switch {
+	case err == nil:
+		return errs.ErrConflict
+
+	case errors.Is(err, sql.ErrNoRows):
+		return errs.ErrNotFound
+	}
+
+	err = fmt.Errorf("pg error on SELECT: %v", err)
+	store.logger.Error("failed get cred data version", zap.Error(err))
+	return err
 }
@@ -6,7 +6,11 @@ package credential_store
 
 import (
 	cred "GophKeeper/internal/server/model/cred"
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
+	trash "GophKeeper/internal/server/model/trash"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,58 +39,175 @@ func (m *MockCredStorage) EXPECT() *MockCredStorageMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockCredStorage) Change(in cred.CredentialFull) error {
+func (m *MockCredStorage) Change(email string, in cred.CredentialFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockCredStorageMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockCredStorageMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockCredStorage)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockCredStorage)(nil).Change), email, in)
+}
+
+// ChangeBatch mocks base method.
+func (m *MockCredStorage) ChangeBatch(email string, in []cred.CredentialFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeBatch", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeBatch indicates an expected call of ChangeBatch.
+func (mr *MockCredStorageMockRecorder) ChangeBatch(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeBatch", reflect.TypeOf((*MockCredStorage)(nil).ChangeBatch), email, in)
 }
 
 // Create mocks base method.
-func (m *MockCredStorage) Create(data cred.CredentialFull) error {
+func (m *MockCredStorage) Create(email string, data cred.CredentialFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", data)
+	ret := m.ctrl.Call(m, "Create", email, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockCredStorageMockRecorder) Create(data interface{}) *gomock.Call {
+func (mr *MockCredStorageMockRecorder) Create(email, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCredStorage)(nil).Create), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCredStorage)(nil).Create), email, data)
 }
 
 // Delete mocks base method.
-func (m *MockCredStorage) Delete(in cred.CredentialGet) error {
+func (m *MockCredStorage) Delete(email string, in cred.CredentialGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCredStorageMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockCredStorageMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCredStorage)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCredStorage)(nil).Delete), email, in)
 }
 
 // Get mocks base method.
-func (m *MockCredStorage) Get(in cred.CredentialGet) (cred.CredentialFull, error) {
+func (m *MockCredStorage) Get(email string, in cred.CredentialGet) (cred.CredentialFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(cred.CredentialFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCredStorageMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockCredStorageMockRecorder) Get(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCredStorage)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockCredStorage) GetRevision(email string, in revision.Request) (cred.CredentialFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(cred.CredentialFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockCredStorageMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockCredStorage)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockCredStorage) List(email string, in page.Request) ([]page.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].([]page.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCredStorageMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCredStorage)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockCredStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockCredStorageMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockCredStorage)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockCredStorage) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockCredStorageMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockCredStorage)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockCredStorage) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockCredStorageMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockCredStorage)(nil).Purge), email, meta)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockCredStorage) PurgeExpired(before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockCredStorageMockRecorder) PurgeExpired(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockCredStorage)(nil).PurgeExpired), before)
+}
+
+// Undelete mocks base method.
+func (m *MockCredStorage) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockCredStorageMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCredStorage)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockCredStorage)(nil).Undelete), email, meta)
 }
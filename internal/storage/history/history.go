@@ -0,0 +1,118 @@
+package history
+
+import (
+	"sort"
+
+	"GophKeeper/internal/server/model/revision"
+)
+
+// Options - Параметры хранения прежних версий данных.
+type Options func(cfg *Config)
+
+// Config - Настройки хранения прежних версий данных.
+type Config struct {
+	// Retention - Количество хранимых прежних версий записи, 0 - история не ведется
+	Retention int
+}
+
+// NewConfig - Настройки хранения с учетом opts.
+// По умолчанию хранится revision.DefaultRetention версий.
+func NewConfig(opts ...Options) Config {
+
+	cfg := Config{Retention: revision.DefaultRetention}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.Retention < 0 {
+		cfg.Retention = 0
+	}
+
+	return cfg
+}
+
+// WithRetention - Количество хранимых прежних версий записи.
+func WithRetention(n int) Options {
+	return func(cfg *Config) {
+		cfg.Retention = n
+	}
+}
+
+// entry - Прежняя версия данных.
+type entry[T any] struct {
+	revision.Revision
+	data T
+}
+
+// Memory - Прежние версии данных типа T в памяти.
+// Не потокобезопасна, блокировку выполняет хранилище данных.
+type Memory[T any] struct {
+	retention int
+	// entries - Прежние версии по email владельца и метаинформации, от старых к новым
+	entries map[string]map[string][]entry[T]
+}
+
+// NewMemory - Создание хранилища прежних версий в памяти.
+func NewMemory[T any](cfg Config) *Memory[T] {
+	return &Memory[T]{
+		retention: cfg.Retention,
+		entries:   make(map[string]map[string][]entry[T]),
+	}
+}
+
+// Push - Сохранение прежней версии данных meta пользователя email.
+// Версии сверх Retention удаляются, начиная с самых старых.
+func (h *Memory[T]) Push(email, meta string, rev revision.Revision, data T) {
+
+	if h.retention == 0 {
+		return
+	}
+
+	if h.entries[email] == nil {
+		h.entries[email] = make(map[string][]entry[T])
+	}
+
+	entries := append(h.entries[email][meta], entry[T]{Revision: rev, data: data})
+	if len(entries) > h.retention {
+		entries = entries[len(entries)-h.retention:]
+	}
+
+	h.entries[email][meta] = entries
+}
+
+// List - Прежние версии данных meta, от новых к старым.
+func (h *Memory[T]) List(email, meta string) []revision.Revision {
+
+	entries := h.entries[email][meta]
+
+	revs := make([]revision.Revision, 0, len(entries))
+	for _, e := range entries {
+		revs = append(revs, e.Revision)
+	}
+
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Version > revs[j].Version })
+	return revs
+}
+
+// Get - Прежняя версия version данных meta.
+func (h *Memory[T]) Get(email, meta string, version int64) (T, revision.Revision, bool) {
+
+	for _, e := range h.entries[email][meta] {
+		if e.Version == version {
+			return e.data, e.Revision, true
+		}
+	}
+
+	var data T
+	return data, revision.Revision{}, false
+}
+
+// Forget - Удаление всех прежних версий данных meta.
+func (h *Memory[T]) Forget(email, meta string) {
+	delete(h.entries[email], meta)
+}
+
+// ForgetAll - Удаление всех прежних версий данных пользователя email.
+func (h *Memory[T]) ForgetAll(email string) {
+	delete(h.entries, email)
+}
@@ -0,0 +1,28 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/server/model/revision"
+)
+
+func TestHistory_Config(t *testing.T) {
+
+	require.Equal(t, revision.DefaultRetention, NewConfig().Retention)
+	require.Equal(t, 3, NewConfig(WithRetention(3)).Retention)
+	require.Equal(t, 0, NewConfig(WithRetention(-1)).Retention)
+}
+
+func TestHistory_MemoryDisabled(t *testing.T) {
+
+	h := NewMemory[string](NewConfig(WithRetention(0)))
+
+	h.Push("test@email.com", "note", revision.Revision{Version: 1}, "text")
+
+	require.Empty(t, h.List("test@email.com", "note"))
+
+	_, _, ok := h.Get("test@email.com", "note", 1)
+	require.False(t, ok)
+}
@@ -0,0 +1,183 @@
+package key_store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/pkg/errs"
+)
+
+var (
+	queryGet = `SELECT k.sealed_key
+                FROM vault_keys k
+                JOIN users u ON u.id = k.user_id
+                WHERE u.email = $1`
+	queryCreate = `INSERT INTO vault_keys (user_id, sealed_key)
+                   SELECT id, $2
+                   FROM users
+                   WHERE email = $1`
+	queryGetRotation = `SELECT r.key_id, r.sealed_key, r.started_at
+                        FROM key_rotations r
+                        JOIN users u ON u.id = r.user_id
+                        WHERE u.email = $1`
+	queryBeginRotation = `INSERT INTO key_rotations (user_id, key_id, sealed_key)
+                          SELECT id, $2, $3
+                          FROM users
+                          WHERE email = $1`
+	// queryFinishRotation - Удаление смены ключа. Возвращает новый ключ хранилища.
+	queryFinishRotation = `DELETE FROM key_rotations
+                           WHERE user_id = (SELECT id FROM users WHERE email = $1)
+                           RETURNING sealed_key`
+	queryUpdateKey = `UPDATE vault_keys
+                      SET sealed_key = $2
+                      WHERE user_id = (SELECT id FROM users WHERE email = $1)`
+)
+
+type PostgresStorage struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewPostgresStorage - Создание хранилища в БД Postgres
+func NewPostgresStorage(db *sqlx.DB) *PostgresStorage {
+	return &PostgresStorage{
+		db:     db,
+		logger: zap.L(),
+	}
+}
+
+// Get Зашифрованный ключ пользователя email.
+func (store *PostgresStorage) Get(email string) ([]byte, error) {
+
+	var sealed []byte
+
+	row := store.db.QueryRowContext(context.Background(), queryGet, email)
+	if err := row.Scan(&sealed); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed get vault key", zap.Error(err))
+		return nil, err
+	}
+
+	return sealed, nil
+}
+
+// Create Сохранение зашифрованного ключа пользователя email.
+func (store *PostgresStorage) Create(email string, sealed []byte) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryCreate, email, sealed)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+			return errs.ErrAlreadyExist
+		}
+
+		err = fmt.Errorf("pg error on INSERT: %v", err)
+		store.logger.Error("failed create vault key", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetRotation Незавершенная смена ключа пользователя email.
+func (store *PostgresStorage) GetRotation(email string) (rotation.Rotation, error) {
+
+	var r rotation.Rotation
+
+	row := store.db.QueryRowContext(context.Background(), queryGetRotation, email)
+	if err := row.Scan(&r.KeyID, &r.SealedKey, &r.StartedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return rotation.Rotation{}, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed get key rotation", zap.Error(err))
+		return rotation.Rotation{}, err
+	}
+
+	return r, nil
+}
+
+// BeginRotation Начало смены ключа пользователя email.
+func (store *PostgresStorage) BeginRotation(email string, in rotation.Rotation) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryBeginRotation, email, in.KeyID, in.SealedKey)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+			return errs.ErrAlreadyExist
+		}
+
+		err = fmt.Errorf("pg error on INSERT: %v", err)
+		store.logger.Error("failed begin key rotation", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// FinishRotation Завершение смены ключа пользователя email.
+// Смена ключа удаляется и ключ хранилища заменяется в одной транзакции.
+func (store *PostgresStorage) FinishRotation(email string) error {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	var sealed []byte
+	if err = tx.QueryRowContext(ctx, queryFinishRotation, email).Scan(&sealed); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed finish key rotation", zap.Error(err))
+		return err
+	}
+
+	if len(sealed) != 0 {
+		res, errUpdate := tx.ExecContext(ctx, queryUpdateKey, email, sealed)
+		if errUpdate != nil {
+			err = fmt.Errorf("pg error on UPDATE: %v", errUpdate)
+			store.logger.Error("failed finish key rotation", zap.Error(err))
+			return err
+		}
+
+		if rows, _ := res.RowsAffected(); rows == 0 {
+			return errs.ErrNotFound
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed finish key rotation", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
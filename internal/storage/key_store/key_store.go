@@ -0,0 +1,25 @@
+//go:generate mockgen -source key_store.go -destination mocks/key_store_mock.go -package key_store
+package key_store
+
+import "GophKeeper/internal/server/model/rotation"
+
+// KeyStorage - Хранилище ключей хранилища пользователей.
+// Ключ зашифрован мастер-паролем на клиенте, сервер хранит его как есть.
+type KeyStorage interface {
+	// Get - Зашифрованный ключ пользователя email.
+	// Если ключа нет, возвращается errs.ErrNotFound.
+	Get(email string) ([]byte, error)
+	// Create - Сохранение зашифрованного ключа пользователя email.
+	// Если ключ уже сохранен, возвращается errs.ErrAlreadyExist.
+	Create(email string, sealed []byte) error
+
+	// GetRotation - Незавершенная смена ключа пользователя email.
+	// Если смена ключа не начата, возвращается errs.ErrNotFound.
+	GetRotation(email string) (rotation.Rotation, error)
+	// BeginRotation - Начало смены ключа. Если смена уже начата,
+	// возвращается errs.ErrAlreadyExist.
+	BeginRotation(email string, in rotation.Rotation) error
+	// FinishRotation - Завершение смены ключа. Если в смене ключа есть новый
+	// ключ хранилища, он заменяет прежний. errs.ErrNotFound, если смена не начата.
+	FinishRotation(email string) error
+}
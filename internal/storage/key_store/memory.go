@@ -0,0 +1,116 @@
+package key_store
+
+import (
+	"sync"
+	"time"
+
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/pkg/errs"
+)
+
+type MemoryStorage struct {
+	mutex sync.RWMutex
+	// keys - Зашифрованные ключи по email владельца
+	keys map[string][]byte
+	// rotations - Незавершенные смены ключа по email владельца
+	rotations map[string]rotation.Rotation
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		keys:      make(map[string][]byte),
+		rotations: make(map[string]rotation.Rotation),
+	}
+}
+
+// Get Зашифрованный ключ пользователя email.
+func (store *MemoryStorage) Get(email string) ([]byte, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	sealed, ok := store.keys[email]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+
+	return append([]byte(nil), sealed...), nil
+}
+
+// Create Сохранение зашифрованного ключа пользователя email.
+func (store *MemoryStorage) Create(email string, sealed []byte) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.keys[email]; ok {
+		return errs.ErrAlreadyExist
+	}
+
+	store.keys[email] = append([]byte(nil), sealed...)
+	return nil
+}
+
+// GetRotation Незавершенная смена ключа пользователя email.
+func (store *MemoryStorage) GetRotation(email string) (rotation.Rotation, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	r, ok := store.rotations[email]
+	if !ok {
+		return rotation.Rotation{}, errs.ErrNotFound
+	}
+
+	return r, nil
+}
+
+// BeginRotation Начало смены ключа пользователя email.
+func (store *MemoryStorage) BeginRotation(email string, in rotation.Rotation) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.rotations[email]; ok {
+		return errs.ErrAlreadyExist
+	}
+
+	in.SealedKey = append([]byte(nil), in.SealedKey...)
+	in.StartedAt = time.Now()
+	store.rotations[email] = in
+	return nil
+}
+
+// FinishRotation Завершение смены ключа пользователя email.
+func (store *MemoryStorage) FinishRotation(email string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	r, ok := store.rotations[email]
+	if !ok {
+		return errs.ErrNotFound
+	}
+
+	if len(r.SealedKey) != 0 {
+		if _, ok = store.keys[email]; !ok {
+			return errs.ErrNotFound
+		}
+		store.keys[email] = r.SealedKey
+	}
+
+	delete(store.rotations, email)
+	return nil
+}
+
+// DeleteAll Удаление ключа и незавершенной смены ключа пользователя email.
+func (store *MemoryStorage) DeleteAll(email string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.keys, email)
+	delete(store.rotations, email)
+
+	return nil
+}
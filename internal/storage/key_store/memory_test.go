@@ -0,0 +1,95 @@
+package key_store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/server/model/rotation"
+	"GophKeeper/pkg/errs"
+)
+
+func TestKeyStore_Memory(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	_, err := store.Get(email)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	sealed := []byte("sealed key")
+	require.NoError(t, store.Create(email, sealed))
+
+	// Ключ не перезаписывается
+	require.ErrorIs(t, store.Create(email, []byte("other key")), errs.ErrAlreadyExist)
+
+	got, err := store.Get(email)
+	require.NoError(t, err)
+	require.Equal(t, sealed, got)
+
+	_, err = store.Get("other@email.com")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func TestKeyStore_MemoryRotation(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	require.NoError(t, store.Create(email, []byte("old key")))
+
+	_, err := store.GetRotation(email)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+	require.ErrorIs(t, store.FinishRotation(email), errs.ErrNotFound)
+
+	in := rotation.Rotation{KeyID: "0102030405060708", SealedKey: []byte("new key")}
+	require.NoError(t, store.BeginRotation(email, in))
+	require.ErrorIs(t, store.BeginRotation(email, in), errs.ErrAlreadyExist)
+
+	got, err := store.GetRotation(email)
+	require.NoError(t, err)
+	require.Equal(t, in.KeyID, got.KeyID)
+	require.Equal(t, in.SealedKey, got.SealedKey)
+	require.False(t, got.StartedAt.IsZero())
+
+	// Пока смена не завершена, ключ хранилища прежний
+	sealed, err := store.Get(email)
+	require.NoError(t, err)
+	require.Equal(t, []byte("old key"), sealed)
+
+	require.NoError(t, store.FinishRotation(email))
+
+	sealed, err = store.Get(email)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new key"), sealed)
+
+	_, err = store.GetRotation(email)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Смена ключей из файлов не меняет ключ хранилища
+	other := "other@email.com"
+	require.NoError(t, store.BeginRotation(other, rotation.Rotation{KeyID: "0807060504030201"}))
+	require.NoError(t, store.FinishRotation(other))
+	_, err = store.Get(other)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func TestKeyStore_MemoryDeleteAll(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	require.NoError(t, store.Create(email, []byte("old key")))
+	require.NoError(t, store.BeginRotation(email, rotation.Rotation{KeyID: "0102030405060708", SealedKey: []byte("new key")}))
+	require.NoError(t, store.Create("other@email.com", []byte("other key")))
+
+	require.NoError(t, store.DeleteAll(email))
+
+	_, err := store.Get(email)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+	_, err = store.GetRotation(email)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = store.Get("other@email.com")
+	require.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: key_store.go
+
+// Package key_store is a generated GoMock package.
+package key_store
+
+import (
+	rotation "GophKeeper/internal/server/model/rotation"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockKeyStorage is a mock of KeyStorage interface.
+type MockKeyStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyStorageMockRecorder
+}
+
+// MockKeyStorageMockRecorder is the mock recorder for MockKeyStorage.
+type MockKeyStorageMockRecorder struct {
+	mock *MockKeyStorage
+}
+
+// NewMockKeyStorage creates a new mock instance.
+func NewMockKeyStorage(ctrl *gomock.Controller) *MockKeyStorage {
+	mock := &MockKeyStorage{ctrl: ctrl}
+	mock.recorder = &MockKeyStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyStorage) EXPECT() *MockKeyStorageMockRecorder {
+	return m.recorder
+}
+
+// BeginRotation mocks base method.
+func (m *MockKeyStorage) BeginRotation(email string, in rotation.Rotation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRotation", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginRotation indicates an expected call of BeginRotation.
+func (mr *MockKeyStorageMockRecorder) BeginRotation(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRotation", reflect.TypeOf((*MockKeyStorage)(nil).BeginRotation), email, in)
+}
+
+// Create mocks base method.
+func (m *MockKeyStorage) Create(email string, sealed []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", email, sealed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockKeyStorageMockRecorder) Create(email, sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockKeyStorage)(nil).Create), email, sealed)
+}
+
+// FinishRotation mocks base method.
+func (m *MockKeyStorage) FinishRotation(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRotation", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishRotation indicates an expected call of FinishRotation.
+func (mr *MockKeyStorageMockRecorder) FinishRotation(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRotation", reflect.TypeOf((*MockKeyStorage)(nil).FinishRotation), email)
+}
+
+// Get mocks base method.
+func (m *MockKeyStorage) Get(email string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", email)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockKeyStorageMockRecorder) Get(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockKeyStorage)(nil).Get), email)
+}
+
+// GetRotation mocks base method.
+func (m *MockKeyStorage) GetRotation(email string) (rotation.Rotation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRotation", email)
+	ret0, _ := ret[0].(rotation.Rotation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRotation indicates an expected call of GetRotation.
+func (mr *MockKeyStorageMockRecorder) GetRotation(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRotation", reflect.TypeOf((*MockKeyStorage)(nil).GetRotation), email)
+}
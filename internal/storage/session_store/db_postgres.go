@@ -0,0 +1,181 @@
+package session_store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"GophKeeper/internal/server/model/auth"
+	"GophKeeper/pkg/errs"
+)
+
+var (
+	queryDeleteExpired = `DELETE FROM sessions
+                          WHERE user_id = (SELECT id FROM users WHERE email = $1) AND expires_at < now()`
+	queryCreate = `INSERT INTO sessions (id, user_id, refresh_hash, created_at, expires_at)
+                   SELECT $1, id, $3, $4, $5
+                   FROM users
+                   WHERE email = $2`
+	queryFind = `SELECT s.id, u.email, s.refresh_hash, s.created_at, s.expires_at
+                 FROM sessions s
+                 JOIN users u ON u.id = s.user_id
+                 WHERE s.id = $1`
+	// queryRotate - Замена refresh token с возвратом сессии до замены
+	queryRotate = `UPDATE sessions s
+                   SET refresh_hash = $2, expires_at = $3
+                   FROM sessions prev, users u
+                   WHERE s.refresh_hash = $1 AND prev.id = s.id AND u.id = s.user_id
+                   RETURNING s.id, u.email, prev.refresh_hash, s.created_at, prev.expires_at`
+	queryList = `SELECT s.id, u.email, s.refresh_hash, s.created_at, s.expires_at
+                 FROM sessions s
+                 JOIN users u ON u.id = s.user_id
+                 WHERE u.email = $1
+                 ORDER BY s.created_at DESC`
+	queryDelete = `DELETE FROM sessions
+                   WHERE id = $2 AND user_id = (SELECT id FROM users WHERE email = $1)`
+	queryDeleteOthers = `DELETE FROM sessions
+                         WHERE id <> $2 AND user_id = (SELECT id FROM users WHERE email = $1)`
+)
+
+type PostgresStorage struct {
+	db     *sqlx.DB
+	logger *zap.Logger
+}
+
+// NewPostgresStorage - Создание хранилища в БД Postgres
+func NewPostgresStorage(db *sqlx.DB) *PostgresStorage {
+	return &PostgresStorage{
+		db:     db,
+		logger: zap.L(),
+	}
+}
+
+// Create Открытие сессии.
+func (store *PostgresStorage) Create(in auth.Session) error {
+
+	ctx := context.Background()
+
+	if _, err := store.db.ExecContext(ctx, queryDeleteExpired, in.Email); err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed delete expired sessions", zap.Error(err))
+		return err
+	}
+
+	res, err := store.db.ExecContext(ctx, queryCreate, in.ID, in.Email, in.RefreshHash, in.CreatedAt, in.ExpiresAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+			return errs.ErrAlreadyExist
+		}
+
+		err = fmt.Errorf("pg error on INSERT: %v", err)
+		store.logger.Error("failed create session", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// Find Поиск сессии по идентификатору.
+func (store *PostgresStorage) Find(id string) (auth.Session, error) {
+
+	row := store.db.QueryRowContext(context.Background(), queryFind, id)
+	return store.scan(row, "failed find session")
+}
+
+// Rotate Замена refresh token сессии.
+func (store *PostgresStorage) Rotate(refreshHash, newHash string, expiresAt time.Time) (auth.Session, error) {
+
+	row := store.db.QueryRowContext(context.Background(), queryRotate, refreshHash, newHash, expiresAt)
+	return store.scan(row, "failed rotate session")
+}
+
+// List Сессии пользователя email, от новых к старым.
+func (store *PostgresStorage) List(email string) ([]auth.Session, error) {
+
+	list := make([]auth.Session, 0)
+
+	rows, err := store.db.QueryContext(context.Background(), queryList, email)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed list sessions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s auth.Session
+		if err = rows.Scan(&s.ID, &s.Email, &s.RefreshHash, &s.CreatedAt, &s.ExpiresAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT: %v", err)
+			store.logger.Error("failed list sessions", zap.Error(err))
+			return nil, err
+		}
+
+		list = append(list, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed list sessions", zap.Error(err))
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Delete Закрытие сессии id пользователя email.
+func (store *PostgresStorage) Delete(email, id string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryDelete, email, id)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed delete session", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteOthers Закрытие всех сессий пользователя email, кроме keep.
+func (store *PostgresStorage) DeleteOthers(email, keep string) error {
+
+	if _, err := store.db.ExecContext(context.Background(), queryDeleteOthers, email, keep); err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed delete other sessions", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// scan - Чтение сессии из результата запроса.
+func (store *PostgresStorage) scan(row *sql.Row, msg string) (auth.Session, error) {
+
+	var s auth.Session
+	if err := row.Scan(&s.ID, &s.Email, &s.RefreshHash, &s.CreatedAt, &s.ExpiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.Session{}, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error: %v", err)
+		store.logger.Error(msg, zap.Error(err))
+		return auth.Session{}, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,126 @@
+package session_store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"GophKeeper/internal/server/model/auth"
+	"GophKeeper/pkg/errs"
+)
+
+type MemoryStorage struct {
+	mutex sync.RWMutex
+	// sessions - Сессии по идентификатору
+	sessions map[string]auth.Session
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		sessions: make(map[string]auth.Session),
+	}
+}
+
+// Create Открытие сессии.
+func (store *MemoryStorage) Create(in auth.Session) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	for id, s := range store.sessions {
+		if s.Email == in.Email && s.ExpiresAt.Before(now) {
+			delete(store.sessions, id)
+		}
+	}
+
+	if _, ok := store.sessions[in.ID]; ok {
+		return errs.ErrAlreadyExist
+	}
+
+	store.sessions[in.ID] = in
+	return nil
+}
+
+// Find Поиск сессии по идентификатору.
+func (store *MemoryStorage) Find(id string) (auth.Session, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	s, ok := store.sessions[id]
+	if !ok {
+		return auth.Session{}, errs.ErrNotFound
+	}
+
+	return s, nil
+}
+
+// Rotate Замена refresh token сессии.
+func (store *MemoryStorage) Rotate(refreshHash, newHash string, expiresAt time.Time) (auth.Session, error) {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for id, s := range store.sessions {
+		if s.RefreshHash != refreshHash {
+			continue
+		}
+
+		rotated := s
+		rotated.RefreshHash = newHash
+		rotated.ExpiresAt = expiresAt
+		store.sessions[id] = rotated
+
+		return s, nil
+	}
+
+	return auth.Session{}, errs.ErrNotFound
+}
+
+// List Сессии пользователя email, от новых к старым.
+func (store *MemoryStorage) List(email string) ([]auth.Session, error) {
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	list := make([]auth.Session, 0)
+	for _, s := range store.sessions {
+		if s.Email == email {
+			list = append(list, s)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+// Delete Закрытие сессии id пользователя email.
+func (store *MemoryStorage) Delete(email, id string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	s, ok := store.sessions[id]
+	if !ok || s.Email != email {
+		return errs.ErrNotFound
+	}
+
+	delete(store.sessions, id)
+	return nil
+}
+
+// DeleteOthers Закрытие всех сессий пользователя email, кроме keep.
+func (store *MemoryStorage) DeleteOthers(email, keep string) error {
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for id, s := range store.sessions {
+		if s.Email == email && id != keep {
+			delete(store.sessions, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package session_store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/internal/server/model/auth"
+	"GophKeeper/pkg/errs"
+)
+
+func TestSessionStore_Memory(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	first := auth.Session{
+		ID:          "first",
+		Email:       email,
+		RefreshHash: "hash1",
+		CreatedAt:   time.Now().Add(-time.Minute),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	second := auth.Session{
+		ID:          "second",
+		Email:       email,
+		RefreshHash: "hash2",
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	require.NoError(t, store.Create(first))
+	require.NoError(t, store.Create(second))
+	require.ErrorIs(t, store.Create(first), errs.ErrAlreadyExist)
+
+	found, err := store.Find(first.ID)
+	require.NoError(t, err)
+	require.Equal(t, first, found)
+
+	list, err := store.List(email)
+	require.NoError(t, err)
+	require.Equal(t, []auth.Session{second, first}, list)
+
+	// Чужую сессию закрыть нельзя
+	require.ErrorIs(t, store.Delete("other@email.com", first.ID), errs.ErrNotFound)
+
+	require.NoError(t, store.Delete(email, first.ID))
+	require.ErrorIs(t, store.Delete(email, first.ID), errs.ErrNotFound)
+
+	_, err = store.Find(first.ID)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+}
+
+func TestSessionStore_MemoryRotate(t *testing.T) {
+
+	store := NewMemoryStorage()
+
+	in := auth.Session{
+		ID:          "session",
+		Email:       "test@email.com",
+		RefreshHash: "hash1",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	require.NoError(t, store.Create(in))
+
+	expiresAt := time.Now().Add(2 * time.Hour)
+	prev, err := store.Rotate("hash1", "hash2", expiresAt)
+	require.NoError(t, err)
+	require.Equal(t, in, prev)
+
+	// Прежний refresh token больше не действует
+	_, err = store.Rotate("hash1", "hash3", expiresAt)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	found, err := store.Find(in.ID)
+	require.NoError(t, err)
+	require.Equal(t, "hash2", found.RefreshHash)
+	require.Equal(t, expiresAt, found.ExpiresAt)
+}
+
+func TestSessionStore_MemoryDeleteOthers(t *testing.T) {
+
+	store := NewMemoryStorage()
+	expiresAt := time.Now().Add(time.Hour)
+
+	require.NoError(t, store.Create(auth.Session{ID: "keep", Email: "test@email.com", ExpiresAt: expiresAt}))
+	require.NoError(t, store.Create(auth.Session{ID: "drop", Email: "test@email.com", ExpiresAt: expiresAt}))
+	require.NoError(t, store.Create(auth.Session{ID: "other", Email: "other@email.com", ExpiresAt: expiresAt}))
+
+	require.NoError(t, store.DeleteOthers("test@email.com", "keep"))
+
+	_, err := store.Find("keep")
+	require.NoError(t, err)
+
+	_, err = store.Find("drop")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	_, err = store.Find("other")
+	require.NoError(t, err)
+}
+
+func TestSessionStore_MemoryExpired(t *testing.T) {
+
+	store := NewMemoryStorage()
+
+	require.NoError(t, store.Create(auth.Session{
+		ID:        "expired",
+		Email:     "test@email.com",
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}))
+
+	// Новая сессия владельца удаляет его истекшие сессии
+	require.NoError(t, store.Create(auth.Session{
+		ID:        "fresh",
+		Email:     "test@email.com",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	_, err := store.Find("expired")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+}
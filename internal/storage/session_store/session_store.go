@@ -0,0 +1,28 @@
+//go:generate mockgen -source session_store.go -destination mocks/session_store_mock.go -package session_store
+package session_store
+
+import (
+	"time"
+
+	"GophKeeper/internal/server/model/auth"
+)
+
+// SessionStorage - Реестр сессий пользователей.
+type SessionStorage interface {
+	// Create - Открытие сессии.
+	// Истекшие сессии владельца при этом удаляются.
+	Create(in auth.Session) error
+	// Find - Поиск сессии по идентификатору.
+	// Если сессии нет, возвращается errs.ErrNotFound.
+	Find(id string) (auth.Session, error)
+	// Rotate - Замена refresh token сессии с хэшем refreshHash на токен с хэшем newHash.
+	// Возвращает сессию до замены. Если сессии нет, возвращается errs.ErrNotFound.
+	Rotate(refreshHash, newHash string, expiresAt time.Time) (auth.Session, error)
+	// List - Сессии пользователя email, от новых к старым.
+	List(email string) ([]auth.Session, error)
+	// Delete - Закрытие сессии id пользователя email.
+	// Если сессии нет, возвращается errs.ErrNotFound.
+	Delete(email, id string) error
+	// DeleteOthers - Закрытие всех сессий пользователя email, кроме keep.
+	DeleteOthers(email, keep string) error
+}
@@ -5,47 +5,110 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
 
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/server/model/trash"
+	"GophKeeper/internal/storage/history"
 	"GophKeeper/pkg/errs"
 )
 
 var (
-	queryInsert = `INSERT INTO text_data (meta, text) 
-                   VALUES ($1, $2)`
-	queryDelete = `DELETE FROM text_data 
-                   WHERE meta = $1`
-	queryUpdate = `UPDATE text_data
-                   SET text = $1
-                   WHERE meta = $2`
-	queryGet = `SELECT text
+	queryInsert = `INSERT INTO text_data (user_id, meta, text)
+                   SELECT id, $2, $3
+                   FROM users
+                   WHERE email = $1`
+	// queryDelete - Перемещение данных в корзину
+	queryDelete = `UPDATE text_data
+                   SET deleted_at = now()
+                   WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL
+                     AND version = $3`
+	// queryUpdate - Изменение данных с сохранением прежней версии в истории
+	// и удалением версий сверх $5 (количество хранимых версий).
+	queryUpdate = `WITH cur AS (
+                       SELECT id, version, updated_at, text
+                       FROM text_data
+                       WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL
+                         AND version = $4
+                       FOR UPDATE
+                   ), saved AS (
+                       INSERT INTO text_history (data_id, version, updated_at, text)
+                       SELECT id, version, updated_at, text
+                       FROM cur
+                       WHERE $5::INTEGER > 0
+                   ), pruned AS (
+                       DELETE FROM text_history h
+                       USING cur
+                       WHERE h.data_id = cur.id AND h.version <= cur.version - $5::INTEGER
+                   )
+                   UPDATE text_data
+                   SET text = $3, version = cur.version + 1, updated_at = now()
+                   FROM cur
+                   WHERE text_data.id = cur.id`
+	queryGet = `SELECT text, version, updated_at
                 FROM text_data 
-                WHERE meta = $1`
+                WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL`
+	queryVersion = `SELECT version
+                    FROM text_data
+                    WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NULL`
+	queryList = `SELECT meta, version
+                 FROM text_data
+                 WHERE user_id = (SELECT id FROM users WHERE email = $1)
+                   AND deleted_at IS NULL AND meta LIKE $2 AND meta LIKE $3 AND meta > $4
+                 ORDER BY meta
+                 LIMIT $5`
+	queryRevisions = `SELECT h.version, h.updated_at
+                      FROM text_history h
+                      JOIN text_data d ON d.id = h.data_id
+                      WHERE d.user_id = (SELECT id FROM users WHERE email = $1) AND d.meta = $2 AND d.deleted_at IS NULL
+                      ORDER BY h.version DESC`
+	queryGetRevision = `SELECT h.text, h.version, h.updated_at
+                        FROM text_history h
+                        JOIN text_data d ON d.id = h.data_id
+                        WHERE d.user_id = (SELECT id FROM users WHERE email = $1) AND d.meta = $2 AND d.deleted_at IS NULL
+                          AND h.version = $3`
+
+	queryListTrash = `SELECT meta, deleted_at
+                      FROM text_data
+                      WHERE user_id = (SELECT id FROM users WHERE email = $1) AND deleted_at IS NOT NULL
+                      ORDER BY meta`
+	queryUndelete = `UPDATE text_data
+                     SET deleted_at = NULL
+                     WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NOT NULL`
+	queryPurge = `DELETE FROM text_data
+                  WHERE user_id = (SELECT id FROM users WHERE email = $1) AND meta = $2 AND deleted_at IS NOT NULL`
+	queryPurgeExpired = `DELETE FROM text_data WHERE deleted_at < $1`
 )
 
 type PostgresStorage struct {
 	db     *sqlx.DB
 	logger *zap.Logger
+	// retention - Количество хранимых прежних версий записи
+	retention int
 }
 
 // NewPostgresStorage - Создание хранилища в БД Postgres.
-func NewPostgresStorage(db *sqlx.DB) *PostgresStorage {
+// opts - параметры хранения прежних версий данных.
+func NewPostgresStorage(db *sqlx.DB, opts ...history.Options) *PostgresStorage {
 	return &PostgresStorage{
-		db:     db,
-		logger: zap.L(),
+		db:        db,
+		logger:    zap.L(),
+		retention: history.NewConfig(opts...).Retention,
 	}
 }
 
 // Create Создание новых текстовых данных.
-func (store *PostgresStorage) Create(data text.DataTextFull) error {
-
-	if _, err := store.db.ExecContext(context.Background(), queryInsert, data.MetaInfo, data.Text); err != nil {
+func (store *PostgresStorage) Create(email string, data text.DataTextFull) error {
 
+	res, err := store.db.ExecContext(context.Background(), queryInsert, email, data.MetaInfo, data.Text)
+	if err != nil {
 		pqErr := err.(*pq.Error)
 		if pqErr.Code == pgerrcode.UniqueViolation {
 			return errs.ErrAlreadyExist
@@ -55,31 +118,58 @@ func (store *PostgresStorage) Create(data text.DataTextFull) error {
 		store.logger.Error("failed create text data", zap.Error(err))
 		return err
 	}
+
+	// Владелец данных не найден
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
 	return nil
 }
 
-// Delete Удаление текстовых данных.
-func (store *PostgresStorage) Delete(in text.DataTextGet) error {
+// Delete Перемещение данных в корзину.
+// В корзине хранится только последнее удаление данных с той же метаинформацией.
+func (store *PostgresStorage) Delete(email string, in text.DataTextGet) error {
+
+	ctx := context.Background()
 
-	res, err := store.db.ExecContext(context.Background(), queryDelete, in.MetaInfo)
+	tx, err := store.db.BeginTxx(ctx, nil)
 	if err != nil {
-		pqErr := err.(*pq.Error)
-		err = fmt.Errorf("pg error on DELETE: %s. %v", pqErr.Code.Name(), err)
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, queryPurge, email, in.MetaInfo); err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed delete text data", zap.Error(err))
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, queryDelete, email, in.MetaInfo, in.Version)
+	if err != nil {
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
 		store.logger.Error("failed delete text data", zap.Error(err))
 		return err
 	}
 
 	if rows, _ := res.RowsAffected(); rows == 0 {
-		return errs.ErrNotFound
+		return store.notChanged(email, in.MetaInfo)
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed delete text data", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
 // Change Изменение текстовых данных.
-func (store *PostgresStorage) Change(in text.DataTextFull) error {
+func (store *PostgresStorage) Change(email string, in text.DataTextFull) error {
 
-	res, err := store.db.ExecContext(context.Background(), queryUpdate, in.Text, in.MetaInfo)
+	res, err := store.db.ExecContext(context.Background(), queryUpdate, email, in.MetaInfo, in.Text, in.Version, store.retention)
 	if err != nil {
 		pqErr := err.(*pq.Error)
 		err = fmt.Errorf("pg error on UPDATE: %s. %v", pqErr.Code.Name(), err)
@@ -88,19 +178,57 @@ func (store *PostgresStorage) Change(in text.DataTextFull) error {
 	}
 
 	if rows, _ := res.RowsAffected(); rows == 0 {
-		return errs.ErrNotFound
+		return store.notChanged(email, in.MetaInfo)
+	}
+
+	return nil
+}
+
+// ChangeBatch Изменение нескольких записей в одной транзакции.
+func (store *PostgresStorage) ChangeBatch(email string, in []text.DataTextFull) error {
+
+	ctx := context.Background()
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		store.logger.Error("failed begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, data := range in {
+
+		res, errExec := tx.ExecContext(ctx, queryUpdate, email, data.MetaInfo, data.Text, data.Version, store.retention)
+		if errExec != nil {
+			err = fmt.Errorf("pg error on UPDATE: %v", errExec)
+			store.logger.Error("failed update text data", zap.Error(err))
+			return err
+		}
+
+		if rows, _ := res.RowsAffected(); rows == 0 {
+			return store.notChanged(email, data.MetaInfo)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		err = fmt.Errorf("pg error on COMMIT: %v", err)
+		store.logger.Error("failed update text data", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
 // Get Получение текстовых данных по метаинформации.
-func (store *PostgresStorage) Get(in text.DataTextGet) (text.DataTextFull, error) {
+func (store *PostgresStorage) Get(email string, in text.DataTextGet) (text.DataTextFull, error) {
+
+	row := store.db.QueryRowContext(context.Background(), queryGet, email, in.MetaInfo)
 
-	row := store.db.QueryRowContext(context.Background(), queryGet, in.MetaInfo)
+	data := text.DataTextFull{
+		MetaInfo: in.MetaInfo,
+	}
 
-	var data string
-	if err := row.Scan(&data); err != nil {
+	if err := row.Scan(&data.Text, &data.Version, &data.UpdatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return text.DataTextFull{}, errs.ErrNotFound
 		}
@@ -111,8 +239,192 @@ func (store *PostgresStorage) Get(in text.DataTextGet) (text.DataTextFull, error
 		return text.DataTextFull{}, err
 	}
 
-	return text.DataTextFull{
+	return data, nil
+}
+
+// List Получение отсортированного списка метаинформации и версий текстовых данных, прошедших фильтр in.
+func (store *PostgresStorage) List(email string, in page.Request) ([]page.Item, error) {
+
+	items := make([]page.Item, 0, in.Limit)
+	if err := store.db.SelectContext(
+		context.Background(),
+		&items,
+		queryList,
+		email,
+		in.PrefixPattern(),
+		in.ContainsPattern(),
+		in.After,
+		in.Limit); err != nil {
+
+		err = fmt.Errorf("pg error on SELECT: %v", err)
+		store.logger.Error("failed list text data", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ListRevisions Получение прежних версий данных, от новых к старым.
+func (store *PostgresStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+
+	rows, err := store.db.QueryContext(context.Background(), queryRevisions, email, meta)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT history: %v", err)
+		store.logger.Error("failed list text revisions", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	revs := make([]revision.Revision, 0)
+	for rows.Next() {
+		var rev revision.Revision
+		if err = rows.Scan(&rev.Version, &rev.UpdatedAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT history: %v", err)
+			store.logger.Error("failed list text revisions", zap.Error(err))
+			return nil, err
+		}
+
+		revs = append(revs, rev)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT history: %v", err)
+		store.logger.Error("failed list text revisions", zap.Error(err))
+		return nil, err
+	}
+
+	// Пустая история - нужно отличить запись без прежних версий от отсутствующей
+	if len(revs) == 0 {
+		if err = store.notChanged(email, meta); !errors.Is(err, errs.ErrConflict) {
+			return nil, err
+		}
+	}
+
+	return revs, nil
+}
+
+// GetRevision Получение прежней версии данных.
+func (store *PostgresStorage) GetRevision(email string, in revision.Request) (text.DataTextFull, error) {
+
+	row := store.db.QueryRowContext(context.Background(), queryGetRevision, email, in.MetaInfo, in.Version)
+
+	data := text.DataTextFull{
 		MetaInfo: in.MetaInfo,
-		Text:     data,
-	}, nil
+	}
+
+	if err := row.Scan(&data.Text, &data.Version, &data.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return text.DataTextFull{}, errs.ErrNotFound
+		}
+
+		err = fmt.Errorf("pg error on GET history: %v", err)
+		store.logger.Error("failed get text revision", zap.Error(err))
+		return text.DataTextFull{}, err
+	}
+
+	return data, nil
+}
+
+// ListTrash Получение удаленных данных, отсортированных по метаинформации.
+func (store *PostgresStorage) ListTrash(email string) ([]trash.Item, error) {
+
+	rows, err := store.db.QueryContext(context.Background(), queryListTrash, email)
+	if err != nil {
+		err = fmt.Errorf("pg error on SELECT trash: %v", err)
+		store.logger.Error("failed list text data trash", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]trash.Item, 0)
+	for rows.Next() {
+		var item trash.Item
+		if err = rows.Scan(&item.MetaInfo, &item.DeletedAt); err != nil {
+			err = fmt.Errorf("pg error on SELECT trash: %v", err)
+			store.logger.Error("failed list text data trash", zap.Error(err))
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		err = fmt.Errorf("pg error on SELECT trash: %v", err)
+		store.logger.Error("failed list text data trash", zap.Error(err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Undelete Восстановление удаленных данных из корзины.
+func (store *PostgresStorage) Undelete(email, meta string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryUndelete, email, meta)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgerrcode.UniqueViolation {
+			return errs.ErrAlreadyExist
+		}
+
+		err = fmt.Errorf("pg error on UPDATE: %v", err)
+		store.logger.Error("failed undelete text data", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// Purge Окончательное удаление данных из корзины.
+func (store *PostgresStorage) Purge(email, meta string) error {
+
+	res, err := store.db.ExecContext(context.Background(), queryPurge, email, meta)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed purge text data", zap.Error(err))
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeExpired Окончательное удаление данных всех пользователей, удаленных раньше before.
+func (store *PostgresStorage) PurgeExpired(before time.Time) (int, error) {
+
+	res, err := store.db.ExecContext(context.Background(), queryPurgeExpired, before)
+	if err != nil {
+		err = fmt.Errorf("pg error on DELETE: %v", err)
+		store.logger.Error("failed purge expired text data", zap.Error(err))
+		return 0, err
+	}
+
+	rows, _ := res.RowsAffected()
+	return int(rows), nil
+}
+
+// notChanged - Причина, по которой данные не изменены:
+// errs.ErrNotFound, если данных нет, иначе errs.ErrConflict - не совпала версия.
+func (store *PostgresStorage) notChanged(email, meta string) error {
+
+	var version int64
+	err := store.db.QueryRowContext(context.Background(), queryVersion, email, meta).Scan(&version)
+	switch {
+	case err == nil:
+		return errs.ErrConflict
+
+	case errors.Is(err, sql.ErrNoRows):
+		return errs.ErrNotFound
+	}
+
+	err = fmt.Errorf("pg error on SELECT: %v", err)
+	store.logger.Error("failed get text data version", zap.Error(err))
+	return err
 }
@@ -2,16 +2,21 @@ package text_store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/storage/history"
 	"GophKeeper/pkg/errs"
 )
 
 func TestTextStore_Memory(t *testing.T) {
 
 	store := NewMemoryStorage()
+	email := "test@email.com"
 
 	testDataOK := text.DataTextFull{
 		MetaInfo: "www.ololo.com",
@@ -19,6 +24,7 @@ func TestTextStore_Memory(t *testing.T) {
 	}
 
 	testDataChange := text.DataTextFull{
+		Version:  1,
 		MetaInfo: "www.ololo.com",
 		Text:     "qwerty123",
 	}
@@ -31,35 +37,348 @@ func TestTextStore_Memory(t *testing.T) {
 		MetaInfo: "www.test.com",
 	}
 
-	errCreate := store.Create(testDataOK)
+	errCreate := store.Create(email, testDataOK)
 	require.NoError(t, errCreate)
 
-	data, errGet := store.Get(testDataGet)
+	data, errGet := store.Get(email, testDataGet)
 	require.NoError(t, errGet)
-	require.Equal(t, data, testDataOK)
+	require.Equal(t, int64(1), data.Version)
+	testDataOK.Version, testDataOK.UpdatedAt = data.Version, data.UpdatedAt
+	require.Equal(t, testDataOK, data)
 
-	_, errGet = store.Get(testDataFail)
+	_, errGet = store.Get(email, testDataFail)
 	require.Error(t, errGet, errs.ErrNotFound)
 
-	errChange := store.Change(testDataChange)
+	errChange := store.Change(email, testDataChange)
 	require.NoError(t, errChange)
 
-	data, errGet = store.Get(testDataGet)
+	data, errGet = store.Get(email, testDataGet)
 	require.NoError(t, errGet)
-	require.Equal(t, data, testDataChange)
+	require.Equal(t, int64(2), data.Version)
+	testDataChange.Version, testDataChange.UpdatedAt = data.Version, data.UpdatedAt
+	require.Equal(t, testDataChange, data)
 
-	errDel := store.Delete(testDataGet)
+	testDataGet.Version = 2
+	errDel := store.Delete(email, testDataGet)
 	require.NoError(t, errDel)
 
-	_, errGet = store.Get(testDataGet)
+	_, errGet = store.Get(email, testDataGet)
 	require.Error(t, errGet, errs.ErrNotFound)
 
-	errChange = store.Change(testDataChange)
+	errChange = store.Change(email, testDataChange)
 	require.Error(t, errGet, errs.ErrNotFound)
 
-	errCreate = store.Create(testDataOK)
+	errCreate = store.Create(email, testDataOK)
 	require.NoError(t, errCreate)
 
-	errCreate = store.Create(testDataOK)
+	errCreate = store.Create(email, testDataOK)
 	require.Error(t, errCreate, errs.ErrAlreadyExist)
 }
+
+func TestTextStore_MemoryOwner(t *testing.T) {
+
+	store := NewMemoryStorage()
+	owner := "owner@email.com"
+	other := "other@email.com"
+
+	testData := text.DataTextFull{
+		MetaInfo: "www.ololo.com",
+		Text:     "qwerty",
+	}
+
+	testDataGet := text.DataTextGet{
+		MetaInfo: "www.ololo.com",
+	}
+
+	errCreate := store.Create(owner, testData)
+	require.NoError(t, errCreate)
+
+	// Данные другого пользователя недоступны
+	_, errGet := store.Get(other, testDataGet)
+	require.ErrorIs(t, errGet, errs.ErrNotFound)
+
+	errChange := store.Change(other, testData)
+	require.ErrorIs(t, errChange, errs.ErrNotFound)
+
+	errDel := store.Delete(other, testDataGet)
+	require.ErrorIs(t, errDel, errs.ErrNotFound)
+
+	// Одинаковая метаинформация у разных пользователей не конфликтует
+	errCreate = store.Create(other, testData)
+	require.NoError(t, errCreate)
+
+	testDataGet.Version = 1
+	errDel = store.Delete(owner, testDataGet)
+	require.NoError(t, errDel)
+
+	data, errGet := store.Get(other, testDataGet)
+	require.NoError(t, errGet)
+	testData.Version, testData.UpdatedAt = data.Version, data.UpdatedAt
+	require.Equal(t, testData, data)
+}
+
+func TestTextStore_MemoryList(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	for _, meta := range []string{"mail.ru", "gmail", "mail.google", "yandex"} {
+		errCreate := store.Create(email, text.DataTextFull{MetaInfo: meta})
+		require.NoError(t, errCreate)
+	}
+
+	tests := []struct {
+		name string
+		in   page.Request
+		want []string
+	}{
+		{
+			name: "All",
+			in:   page.Request{},
+			want: []string{"gmail", "mail.google", "mail.ru", "yandex"},
+		},
+		{
+			name: "Prefix",
+			in:   page.Request{Prefix: "mail"},
+			want: []string{"mail.google", "mail.ru"},
+		},
+		{
+			name: "Contains",
+			in:   page.Request{Contains: "mail"},
+			want: []string{"gmail", "mail.google", "mail.ru"},
+		},
+		{
+			name: "After and limit",
+			in:   page.Request{After: "gmail", Limit: 2},
+			want: []string{"mail.google", "mail.ru"},
+		},
+		{
+			name: "Nothing found",
+			in:   page.Request{Prefix: "unknown"},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			items, err := store.List(email, tt.in)
+			require.NoError(t, err)
+
+			metas := make([]string, 0, len(items))
+			for _, item := range items {
+				metas = append(metas, item.MetaInfo)
+				require.Equal(t, int64(1), item.Version)
+			}
+			require.Equal(t, tt.want, metas)
+		})
+	}
+
+	// Список отражает текущую версию записи
+	require.NoError(t, store.Change(email, text.DataTextFull{MetaInfo: "mail.ru", Version: 1}))
+
+	items, err := store.List(email, page.Request{Prefix: "mail.ru"})
+	require.NoError(t, err)
+	require.Equal(t, []page.Item{{MetaInfo: "mail.ru", Version: 2}}, items)
+
+	items, err = store.List("other@email.com", page.Request{})
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
+
+func TestTextStore_MemoryVersion(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	require.NoError(t, store.Create(email, text.DataTextFull{MetaInfo: "www.ololo.com", Text: "qwerty"}))
+
+	// Изменение с устаревшей версией отклоняется
+	errChange := store.Change(email, text.DataTextFull{MetaInfo: "www.ololo.com", Text: "qwerty123", Version: 2})
+	require.ErrorIs(t, errChange, errs.ErrConflict)
+
+	// Нулевая версия не отключает проверку версии
+	errChange = store.Change(email, text.DataTextFull{MetaInfo: "www.ololo.com"})
+	require.ErrorIs(t, errChange, errs.ErrConflict)
+
+	errDel := store.Delete(email, text.DataTextGet{MetaInfo: "www.ololo.com"})
+	require.ErrorIs(t, errDel, errs.ErrConflict)
+
+	errChange = store.Change(email, text.DataTextFull{MetaInfo: "www.ololo.com", Text: "qwerty123", Version: 1})
+	require.NoError(t, errChange)
+
+	data, errGet := store.Get(email, text.DataTextGet{MetaInfo: "www.ololo.com"})
+	require.NoError(t, errGet)
+	require.Equal(t, int64(2), data.Version)
+
+	errDel = store.Delete(email, text.DataTextGet{MetaInfo: "www.ololo.com", Version: 1})
+	require.ErrorIs(t, errDel, errs.ErrConflict)
+
+	errDel = store.Delete(email, text.DataTextGet{MetaInfo: "www.ololo.com", Version: 2})
+	require.NoError(t, errDel)
+}
+
+func TestTextStore_MemoryRevisions(t *testing.T) {
+
+	store := NewMemoryStorage(history.WithRetention(2))
+	email := "test@email.com"
+	meta := "revisions"
+
+	first := text.DataTextFull{MetaInfo: meta, Text: "qwerty"}
+	require.NoError(t, store.Create(email, first))
+
+	// Без изменений прежних версий нет
+	revs, err := store.ListRevisions(email, meta)
+	require.NoError(t, err)
+	require.Empty(t, revs)
+
+	_, err = store.ListRevisions(email, "unknown")
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	require.NoError(t, store.Change(email, text.DataTextFull{MetaInfo: meta, Version: 1, Text: "qwerty123"}))
+	require.NoError(t, store.Change(email, text.DataTextFull{MetaInfo: meta, Version: 2, Text: "qwerty456"}))
+
+	revs, err = store.ListRevisions(email, meta)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	require.Equal(t, int64(2), revs[0].Version)
+	require.Equal(t, int64(1), revs[1].Version)
+
+	data, err := store.GetRevision(email, revision.Request{MetaInfo: meta, Version: 1})
+	require.NoError(t, err)
+	require.Equal(t, first.Text, data.Text)
+	require.Equal(t, int64(1), data.Version)
+
+	_, err = store.GetRevision(email, revision.Request{MetaInfo: meta, Version: 3})
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// Хранится не больше двух версий - самая старая удаляется
+	first.Version = 3
+	require.NoError(t, store.Change(email, first))
+
+	revs, err = store.ListRevisions(email, meta)
+	require.NoError(t, err)
+	require.Equal(t, []int64{3, 2}, []int64{revs[0].Version, revs[1].Version})
+
+	_, err = store.GetRevision(email, revision.Request{MetaInfo: meta, Version: 1})
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// История другого пользователя недоступна
+	_, err = store.ListRevisions("other@email.com", meta)
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	// История удаляется вместе с данными
+	require.NoError(t, store.Delete(email, text.DataTextGet{MetaInfo: meta, Version: 4}))
+	require.NoError(t, store.Create(email, first))
+
+	revs, err = store.ListRevisions(email, meta)
+	require.NoError(t, err)
+	require.Empty(t, revs)
+}
+
+func TestTextStore_MemoryTrash(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+	meta := "trash"
+
+	first := text.DataTextFull{MetaInfo: meta, Text: "qwerty"}
+	require.NoError(t, store.Create(email, first))
+	first.Version = 1
+	require.NoError(t, store.Change(email, first))
+
+	// Удаленные данные недоступны, но лежат в корзине
+	require.NoError(t, store.Delete(email, text.DataTextGet{MetaInfo: meta, Version: 2}))
+
+	_, err := store.Get(email, text.DataTextGet{MetaInfo: meta})
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	items, err := store.ListTrash(email)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, meta, items[0].MetaInfo)
+	require.False(t, items[0].DeletedAt.IsZero())
+
+	items, err = store.ListTrash("other@email.com")
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	// Восстановленные данные возвращаются вместе с историей версий
+	require.NoError(t, store.Undelete(email, meta))
+	require.ErrorIs(t, store.Undelete(email, meta), errs.ErrAlreadyExist)
+
+	data, err := store.Get(email, text.DataTextGet{MetaInfo: meta})
+	require.NoError(t, err)
+	require.Equal(t, first.Text, data.Text)
+	require.Equal(t, int64(2), data.Version)
+
+	revs, err := store.ListRevisions(email, meta)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+
+	// Восстановление невозможно, если создали данные с той же метаинформацией
+	require.NoError(t, store.Delete(email, text.DataTextGet{MetaInfo: meta, Version: 2}))
+	require.NoError(t, store.Create(email, first))
+	require.ErrorIs(t, store.Undelete(email, meta), errs.ErrAlreadyExist)
+
+	require.NoError(t, store.Purge(email, meta))
+	require.ErrorIs(t, store.Purge(email, meta), errs.ErrNotFound)
+
+	// Просроченные данные удаляются окончательно
+	require.NoError(t, store.Delete(email, text.DataTextGet{MetaInfo: meta, Version: 1}))
+
+	purged, err := store.PurgeExpired(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, purged)
+
+	purged, err = store.PurgeExpired(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	require.ErrorIs(t, store.Undelete(email, meta), errs.ErrNotFound)
+}
+
+// TestTextStore_MemoryChangeBatch - Записи изменяются все вместе или не изменяются совсем.
+func TestTextStore_MemoryChangeBatch(t *testing.T) {
+
+	store := NewMemoryStorage()
+	email := "test@email.com"
+
+	require.NoError(t, store.Create(email, text.DataTextFull{MetaInfo: "one", Text: "one"}))
+	require.NoError(t, store.Create(email, text.DataTextFull{MetaInfo: "two", Text: "two"}))
+
+	// Одна из записей изменена с ожидаемой версии - не изменяется ни одна
+	err := store.ChangeBatch(email, []text.DataTextFull{
+		{MetaInfo: "one", Text: "one-new", Version: 1},
+		{MetaInfo: "two", Text: "two-new", Version: 2},
+	})
+	require.ErrorIs(t, err, errs.ErrConflict)
+
+	// Одной из записей нет
+	err = store.ChangeBatch(email, []text.DataTextFull{
+		{MetaInfo: "one", Text: "one-new", Version: 1},
+		{MetaInfo: "three", Text: "two-new"},
+	})
+	require.ErrorIs(t, err, errs.ErrNotFound)
+
+	data, err := store.Get(email, text.DataTextGet{MetaInfo: "one"})
+	require.NoError(t, err)
+	require.Equal(t, "one", data.Text)
+	require.Equal(t, int64(1), data.Version)
+
+	err = store.ChangeBatch(email, []text.DataTextFull{
+		{MetaInfo: "one", Text: "one-new", Version: 1},
+		{MetaInfo: "two", Text: "two-new", Version: 1},
+	})
+	require.NoError(t, err)
+
+	data, err = store.Get(email, text.DataTextGet{MetaInfo: "two"})
+	require.NoError(t, err)
+	require.Equal(t, "two-new", data.Text)
+	require.Equal(t, int64(2), data.Version)
+
+	// Прежние данные сохраняются в истории
+	revisions, err := store.ListRevisions(email, "one")
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+}
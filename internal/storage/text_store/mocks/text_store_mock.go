@@ -5,8 +5,12 @@
 package credential_store
 
 import (
+	page "GophKeeper/internal/server/model/page"
+	revision "GophKeeper/internal/server/model/revision"
 	text "GophKeeper/internal/server/model/text"
+	trash "GophKeeper/internal/server/model/trash"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,58 +39,175 @@ func (m *MockTextStorage) EXPECT() *MockTextStorageMockRecorder {
 }
 
 // Change mocks base method.
-func (m *MockTextStorage) Change(in text.DataTextFull) error {
+func (m *MockTextStorage) Change(email string, in text.DataTextFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Change", in)
+	ret := m.ctrl.Call(m, "Change", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Change indicates an expected call of Change.
-func (mr *MockTextStorageMockRecorder) Change(in interface{}) *gomock.Call {
+func (mr *MockTextStorageMockRecorder) Change(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockTextStorage)(nil).Change), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Change", reflect.TypeOf((*MockTextStorage)(nil).Change), email, in)
+}
+
+// ChangeBatch mocks base method.
+func (m *MockTextStorage) ChangeBatch(email string, in []text.DataTextFull) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeBatch", email, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeBatch indicates an expected call of ChangeBatch.
+func (mr *MockTextStorageMockRecorder) ChangeBatch(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeBatch", reflect.TypeOf((*MockTextStorage)(nil).ChangeBatch), email, in)
 }
 
 // Create mocks base method.
-func (m *MockTextStorage) Create(data text.DataTextFull) error {
+func (m *MockTextStorage) Create(email string, data text.DataTextFull) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", data)
+	ret := m.ctrl.Call(m, "Create", email, data)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTextStorageMockRecorder) Create(data interface{}) *gomock.Call {
+func (mr *MockTextStorageMockRecorder) Create(email, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTextStorage)(nil).Create), data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTextStorage)(nil).Create), email, data)
 }
 
 // Delete mocks base method.
-func (m *MockTextStorage) Delete(in text.DataTextGet) error {
+func (m *MockTextStorage) Delete(email string, in text.DataTextGet) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", email, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTextStorageMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockTextStorageMockRecorder) Delete(email, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTextStorage)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTextStorage)(nil).Delete), email, in)
 }
 
 // Get mocks base method.
-func (m *MockTextStorage) Get(in text.DataTextGet) (text.DataTextFull, error) {
+func (m *MockTextStorage) Get(email string, in text.DataTextGet) (text.DataTextFull, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", in)
+	ret := m.ctrl.Call(m, "Get", email, in)
 	ret0, _ := ret[0].(text.DataTextFull)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockTextStorageMockRecorder) Get(in interface{}) *gomock.Call {
+func (mr *MockTextStorageMockRecorder) Get(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTextStorage)(nil).Get), email, in)
+}
+
+// GetRevision mocks base method.
+func (m *MockTextStorage) GetRevision(email string, in revision.Request) (text.DataTextFull, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", email, in)
+	ret0, _ := ret[0].(text.DataTextFull)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockTextStorageMockRecorder) GetRevision(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockTextStorage)(nil).GetRevision), email, in)
+}
+
+// List mocks base method.
+func (m *MockTextStorage) List(email string, in page.Request) ([]page.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", email, in)
+	ret0, _ := ret[0].([]page.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockTextStorageMockRecorder) List(email, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTextStorage)(nil).List), email, in)
+}
+
+// ListRevisions mocks base method.
+func (m *MockTextStorage) ListRevisions(email, meta string) ([]revision.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", email, meta)
+	ret0, _ := ret[0].([]revision.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockTextStorageMockRecorder) ListRevisions(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockTextStorage)(nil).ListRevisions), email, meta)
+}
+
+// ListTrash mocks base method.
+func (m *MockTextStorage) ListTrash(email string) ([]trash.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", email)
+	ret0, _ := ret[0].([]trash.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockTextStorageMockRecorder) ListTrash(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockTextStorage)(nil).ListTrash), email)
+}
+
+// Purge mocks base method.
+func (m *MockTextStorage) Purge(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockTextStorageMockRecorder) Purge(email, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockTextStorage)(nil).Purge), email, meta)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockTextStorage) PurgeExpired(before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockTextStorageMockRecorder) PurgeExpired(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockTextStorage)(nil).PurgeExpired), before)
+}
+
+// Undelete mocks base method.
+func (m *MockTextStorage) Undelete(email, meta string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undelete", email, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undelete indicates an expected call of Undelete.
+func (mr *MockTextStorageMockRecorder) Undelete(email, meta interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTextStorage)(nil).Get), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undelete", reflect.TypeOf((*MockTextStorage)(nil).Undelete), email, meta)
 }
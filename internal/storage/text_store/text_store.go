@@ -2,12 +2,41 @@
 package text_store
 
 import (
+	"time"
+
+	"GophKeeper/internal/server/model/page"
+	"GophKeeper/internal/server/model/revision"
 	"GophKeeper/internal/server/model/text"
+	"GophKeeper/internal/server/model/trash"
 )
 
+// TextStorage - Хранилище текстовых данных.
+// Все операции выполняются в рамках данных пользователя с указанным email.
 type TextStorage interface {
-	Create(data text.DataTextFull) error
-	Get(in text.DataTextGet) (text.DataTextFull, error)
-	Delete(in text.DataTextGet) error
-	Change(in text.DataTextFull) error
+	Create(email string, data text.DataTextFull) error
+	Get(email string, in text.DataTextGet) (text.DataTextFull, error)
+	Delete(email string, in text.DataTextGet) error
+	Change(email string, in text.DataTextFull) error
+	// ChangeBatch - Изменение нескольких записей: все или ни одной.
+	// errs.ErrNotFound или errs.ErrConflict, если хотя бы одна запись не найдена
+	// или изменена с ожидаемой версии.
+	ChangeBatch(email string, in []text.DataTextFull) error
+	List(email string, in page.Request) ([]page.Item, error)
+
+	// ListRevisions - Прежние версии данных meta, от новых к старым.
+	// errs.ErrNotFound, если данных нет.
+	ListRevisions(email, meta string) ([]revision.Revision, error)
+	// GetRevision - Прежняя версия данных, errs.ErrNotFound, если ее нет.
+	GetRevision(email string, in revision.Request) (text.DataTextFull, error)
+
+	// ListTrash - Удаленные данные, отсортированные по метаинформации.
+	ListTrash(email string) ([]trash.Item, error)
+	// Undelete - Восстановление удаленных данных. errs.ErrNotFound, если их нет в корзине,
+	// errs.ErrAlreadyExist, если после удаления созданы данные с той же метаинформацией.
+	Undelete(email, meta string) error
+	// Purge - Окончательное удаление данных из корзины.
+	Purge(email, meta string) error
+	// PurgeExpired - Окончательное удаление данных всех пользователей, удаленных раньше before.
+	// Возвращает количество удаленных записей.
+	PurgeExpired(before time.Time) (int, error)
 }
@@ -0,0 +1,103 @@
+package trashcan
+
+import (
+	"sort"
+	"time"
+
+	"GophKeeper/internal/server/model/trash"
+)
+
+// entry - Удаленные данные.
+type entry[T any] struct {
+	trash.Item
+	data T
+}
+
+// Memory - Корзина удаленных данных типа T в памяти.
+// Для каждой метаинформации хранится только последнее удаление.
+// Не потокобезопасна, блокировку выполняет хранилище данных.
+type Memory[T any] struct {
+	// entries - Удаленные данные по email владельца
+	entries map[string][]entry[T]
+}
+
+// NewMemory - Создание корзины в памяти.
+func NewMemory[T any]() *Memory[T] {
+	return &Memory[T]{
+		entries: make(map[string][]entry[T]),
+	}
+}
+
+// Put - Перемещение данных meta пользователя email в корзину.
+// Ранее удаленные данные с той же метаинформацией заменяются.
+func (m *Memory[T]) Put(email, meta string, data T, deletedAt time.Time) {
+
+	m.Remove(email, meta)
+	m.entries[email] = append(m.entries[email], entry[T]{
+		Item: trash.Item{MetaInfo: meta, DeletedAt: deletedAt},
+		data: data,
+	})
+}
+
+// List - Содержимое корзины пользователя email, отсортированное по метаинформации.
+func (m *Memory[T]) List(email string) []trash.Item {
+
+	items := make([]trash.Item, 0, len(m.entries[email]))
+	for _, e := range m.entries[email] {
+		items = append(items, e.Item)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].MetaInfo < items[j].MetaInfo })
+	return items
+}
+
+// Take - Извлечение данных meta из корзины.
+func (m *Memory[T]) Take(email, meta string) (T, bool) {
+
+	for idx, e := range m.entries[email] {
+		if e.MetaInfo == meta {
+			m.remove(email, idx)
+			return e.data, true
+		}
+	}
+
+	var data T
+	return data, false
+}
+
+// Remove - Окончательное удаление данных meta из корзины.
+func (m *Memory[T]) Remove(email, meta string) bool {
+	_, ok := m.Take(email, meta)
+	return ok
+}
+
+// Clear - Окончательное удаление всех данных пользователя email из корзины.
+func (m *Memory[T]) Clear(email string) {
+	delete(m.entries, email)
+}
+
+// Expire - Окончательное удаление данных, удаленных раньше before.
+// Возвращает метаинформацию удаленных данных по email владельца.
+func (m *Memory[T]) Expire(before time.Time) map[string][]string {
+
+	expired := make(map[string][]string)
+	for email, entries := range m.entries {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.DeletedAt.Before(before) {
+				expired[email] = append(expired[email], e.MetaInfo)
+				continue
+			}
+			kept = append(kept, e)
+		}
+		m.entries[email] = kept
+	}
+
+	return expired
+}
+
+// remove - Удаление idx-го элемента корзины пользователя email.
+func (m *Memory[T]) remove(email string, idx int) {
+	entries := m.entries[email]
+	m.entries[email] = append(entries[:idx], entries[idx+1:]...)
+}
@@ -0,0 +1,57 @@
+package trashcan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashcan_Memory(t *testing.T) {
+
+	m := NewMemory[string]()
+	now := time.Now()
+
+	m.Put("test@email.com", "b", "first", now)
+	m.Put("test@email.com", "a", "second", now)
+
+	// Повторное удаление заменяет прежние данные
+	m.Put("test@email.com", "b", "third", now.Add(time.Minute))
+
+	items := m.List("test@email.com")
+	require.Len(t, items, 2)
+	require.Equal(t, "a", items[0].MetaInfo)
+	require.Equal(t, "b", items[1].MetaInfo)
+	require.Empty(t, m.List("other@email.com"))
+
+	data, ok := m.Take("test@email.com", "b")
+	require.True(t, ok)
+	require.Equal(t, "third", data)
+
+	_, ok = m.Take("test@email.com", "b")
+	require.False(t, ok)
+
+	require.True(t, m.Remove("test@email.com", "a"))
+	require.False(t, m.Remove("test@email.com", "a"))
+}
+
+func TestTrashcan_MemoryExpire(t *testing.T) {
+
+	m := NewMemory[string]()
+	now := time.Now()
+
+	m.Put("test@email.com", "old", "data", now.Add(-time.Hour))
+	m.Put("test@email.com", "new", "data", now)
+	m.Put("other@email.com", "old", "data", now.Add(-time.Hour))
+
+	expired := m.Expire(now.Add(-time.Minute))
+	require.Equal(t, map[string][]string{
+		"test@email.com":  {"old"},
+		"other@email.com": {"old"},
+	}, expired)
+
+	items := m.List("test@email.com")
+	require.Len(t, items, 1)
+	require.Equal(t, "new", items[0].MetaInfo)
+	require.Empty(t, m.List("other@email.com"))
+}
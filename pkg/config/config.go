@@ -0,0 +1,270 @@
+// Package config - Загрузка конфигурации из нескольких источников по порядку:
+// значения по умолчанию, файл JSON или YAML, переменные окружения, флаги.
+// Каждый следующий источник переопределяет предыдущий.
+//
+// Поля конфигурации описываются тегами структуры: json - имя в файле,
+// env - имя переменной окружения, а через запятую - ее прежние имена, которые
+// читаются, если переменная с основным именем не задана.
+// Поля без тегов или с тегом "-" не загружаются.
+// Поддерживаются типы string, bool, int и time.Duration.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileFlag - Флаг с путем к файлу конфигурации.
+	FileFlag = "c"
+	// FileEnv - Переменная окружения с путем к файлу конфигурации, если флаг не задан.
+	FileEnv = "CONFIG"
+	// Default - Источник значений по умолчанию.
+	Default = "default"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Error - Неверное значение поля конфигурации и его источник.
+type Error struct {
+	Field  string
+	Source string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("config %s (%s): %v", e.Field, e.Source, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// field - Поле конфигурации.
+type field struct {
+	value reflect.Value
+	// env - Имена переменной окружения в порядке приоритета.
+	env []string
+}
+
+// Loader - Загрузка полей конфигурации с учетом источника каждого значения.
+type Loader struct {
+	fields map[string]field
+	// names - Имена полей в порядке объявления.
+	names []string
+	// sources - Источник текущего значения поля.
+	sources map[string]string
+}
+
+// New - Загрузчик конфигурации cfg - указателя на структуру,
+// уже заполненную значениями по умолчанию.
+func New(cfg interface{}) *Loader {
+
+	v := reflect.ValueOf(cfg).Elem()
+	l := &Loader{
+		fields:  make(map[string]field),
+		sources: make(map[string]string),
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i)
+		name := strings.Split(tag.Tag.Get("json"), ",")[0]
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+
+		var env []string
+		if value := tag.Tag.Get("env"); len(value) != 0 && value != "-" {
+			env = strings.Split(value, ",")
+		}
+
+		l.fields[name] = field{value: v.Field(i), env: env}
+		l.names = append(l.names, name)
+		l.sources[name] = Default
+	}
+
+	return l
+}
+
+// FilePath - Путь к файлу конфигурации из флага FileFlag,
+// а если флаг не задан - из переменной окружения FileEnv.
+func FilePath(fs *flag.FlagSet, lookup func(string) (string, bool)) string {
+
+	path, _ := lookup(FileEnv)
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == FileFlag {
+			path = f.Value.String()
+		}
+	})
+
+	return path
+}
+
+// File - Значения из файла path: YAML для расширений .yaml и .yml, иначе JSON.
+// Неизвестные поля в файле - ошибка.
+func (l *Loader) File(path string) error {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	source := "file " + path
+
+	unknown := make([]string, 0)
+	for name := range values {
+		if _, ok := l.fields[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return &Error{Field: unknown[0], Source: source, Err: fmt.Errorf("unknown field")}
+	}
+
+	for _, name := range l.names {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		switch value.(type) {
+		case nil:
+			continue
+		case map[string]interface{}, []interface{}:
+			return &Error{Field: name, Source: source, Err: fmt.Errorf("scalar value expected")}
+		}
+
+		if err = l.Set(name, fmt.Sprint(value), source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Env - Значения из переменных окружения, заданных тегами env.
+// Заданная пустая переменная тоже переопределяет значение.
+func (l *Loader) Env(lookup func(string) (string, bool)) error {
+
+	for _, name := range l.names {
+		for _, env := range l.fields[name].env {
+			value, ok := lookup(env)
+			if !ok {
+				continue
+			}
+
+			if err := l.Set(name, value, "env "+env); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+// Flags - Значения флагов fs, заданных в командной строке.
+// names - имена полей конфигурации по имени флага.
+func (l *Loader) Flags(fs *flag.FlagSet, names map[string]string) error {
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		name, ok := names[f.Name]
+		if !ok || err != nil {
+			return
+		}
+
+		err = l.Set(name, f.Value.String(), "flag -"+f.Name)
+	})
+
+	return err
+}
+
+// Set - Значение поля name из источника source.
+func (l *Loader) Set(name, value, source string) error {
+
+	f, ok := l.fields[name]
+	if !ok {
+		return &Error{Field: name, Source: source, Err: fmt.Errorf("unknown field")}
+	}
+
+	if err := parse(f.value, value); err != nil {
+		return &Error{Field: name, Source: source, Err: err}
+	}
+
+	l.sources[name] = source
+	return nil
+}
+
+// Source - Источник текущего значения поля name.
+func (l *Loader) Source(name string) string {
+	return l.sources[name]
+}
+
+// Errorf - Ошибка проверки значения поля name с указанием его источника.
+func (l *Loader) Errorf(name, format string, args ...interface{}) error {
+	return &Error{Field: name, Source: l.sources[name], Err: fmt.Errorf(format, args...)}
+}
+
+// parse - Запись строкового значения в поле v.
+func parse(v reflect.Value, value string) error {
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(int64(n))
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
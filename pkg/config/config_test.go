@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Addr    string        `env:"TEST_ADDR" json:"addr"`
+	Secret  string        `env:"TEST_SECRET,TEST_OLD_SECRET" json:"secret"`
+	Count   int           `env:"TEST_COUNT" json:"count"`
+	Enabled bool          `env:"TEST_ENABLED" json:"enabled"`
+	Timeout time.Duration `env:"TEST_TIMEOUT" json:"timeout"`
+	NoEnv   string        `json:"no_env"`
+	Skipped string        `env:"SKIPPED" json:"-"`
+}
+
+// testFlags - Поля конфигурации по имени флага.
+var testFlags = map[string]string{
+	"a": "addr",
+	"s": "secret",
+	"n": "count",
+	"e": "enabled",
+	"t": "timeout",
+}
+
+// source - Файл конфигурации и окружение для загрузки.
+type source struct {
+	// file - Имя и содержимое файла. Пустое имя - без файла.
+	file    string
+	content string
+	env     map[string]string
+	args    []string
+}
+
+// load - Загрузка конфигурации в том же порядке, что и у клиента и сервера.
+// Возвращает путь к файлу конфигурации, если он создан.
+func load(t *testing.T, src source) (*testConfig, *Loader, string, error) {
+
+	cfg := &testConfig{Addr: ":3200", Count: 1, Timeout: time.Second}
+
+	path := ``
+	if len(src.file) != 0 {
+		path = filepath.Join(t.TempDir(), src.file)
+		require.NoError(t, os.WriteFile(path, []byte(src.content), 0600))
+		src.args = append([]string{"-" + FileFlag, path}, src.args...)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String(FileFlag, "", "config file")
+	for name := range testFlags {
+		fs.String(name, "", name)
+	}
+	require.NoError(t, fs.Parse(src.args))
+
+	lookup := func(name string) (string, bool) {
+		value, ok := src.env[name]
+		return value, ok
+	}
+
+	loader := New(cfg)
+
+	if file := FilePath(fs, lookup); len(file) != 0 {
+		if err := loader.File(file); err != nil {
+			return cfg, loader, path, err
+		}
+	}
+
+	if err := loader.Env(lookup); err != nil {
+		return cfg, loader, path, err
+	}
+
+	return cfg, loader, path, loader.Flags(fs, testFlags)
+}
+
+func TestLoader_Precedence(t *testing.T) {
+
+	for _, file := range []struct{ name, content string }{
+		{"config.json", `{"addr": ":1", "secret": "file", "count": 2, "enabled": true, "timeout": "2s", "no_env": "file"}`},
+		{"config.yaml", "addr: ':1'\nsecret: file\ncount: 2\nenabled: true\ntimeout: 2s\nno_env: file\n"},
+	} {
+		t.Run(file.name, func(t *testing.T) {
+
+			cfg, loader, path, err := load(t, source{
+				file:    file.name,
+				content: file.content,
+				env:     map[string]string{"TEST_SECRET": "env", "TEST_COUNT": "3", "TEST_TIMEOUT": "3s"},
+				args:    []string{"-s", "flag", "-t", "4s"},
+			})
+			require.NoError(t, err)
+
+			fromFile := "file " + path
+			require.Equal(t, &testConfig{
+				Addr:    ":1",
+				Secret:  "flag",
+				Count:   3,
+				Enabled: true,
+				Timeout: 4 * time.Second,
+				NoEnv:   "file",
+			}, cfg)
+
+			require.Equal(t, fromFile, loader.Source("addr"))
+			require.Equal(t, "flag -s", loader.Source("secret"))
+			require.Equal(t, "env TEST_COUNT", loader.Source("count"))
+			require.Equal(t, fromFile, loader.Source("enabled"))
+			require.Equal(t, "flag -t", loader.Source("timeout"))
+			require.Equal(t, fromFile, loader.Source("no_env"))
+		})
+	}
+
+	// Без файла, окружения и флагов остаются значения по умолчанию
+	cfg, loader, _, err := load(t, source{})
+	require.NoError(t, err)
+	require.Equal(t, &testConfig{Addr: ":3200", Count: 1, Timeout: time.Second}, cfg)
+	for _, name := range []string{"addr", "secret", "count", "enabled", "timeout", "no_env"} {
+		require.Equal(t, Default, loader.Source(name), name)
+	}
+
+	// Заданная пустая переменная окружения тоже переопределяет значение
+	cfg, _, _, err = load(t, source{env: map[string]string{"TEST_ADDR": ""}})
+	require.NoError(t, err)
+	require.Empty(t, cfg.Addr)
+}
+
+func TestLoader_LegacyEnv(t *testing.T) {
+
+	cfg, loader, _, err := load(t, source{env: map[string]string{"TEST_OLD_SECRET": "old"}})
+	require.NoError(t, err)
+	require.Equal(t, "old", cfg.Secret)
+	require.Equal(t, "env TEST_OLD_SECRET", loader.Source("secret"))
+
+	// Основное имя важнее прежнего
+	cfg, loader, _, err = load(t, source{env: map[string]string{"TEST_OLD_SECRET": "old", "TEST_SECRET": "new"}})
+	require.NoError(t, err)
+	require.Equal(t, "new", cfg.Secret)
+	require.Equal(t, "env TEST_SECRET", loader.Source("secret"))
+
+	// Поле без json не загружается и из окружения
+	cfg, _, _, err = load(t, source{env: map[string]string{"SKIPPED": "value"}})
+	require.NoError(t, err)
+	require.Empty(t, cfg.Skipped)
+}
+
+func TestFilePath(t *testing.T) {
+
+	_, _, _, err := load(t, source{env: map[string]string{FileEnv: filepath.Join(t.TempDir(), "missing.json")}})
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Флаг важнее переменной окружения
+	_, loader, path, err := load(t, source{
+		file:    "config.json",
+		content: `{"addr": ":1"}`,
+		env:     map[string]string{FileEnv: "missing.json"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "file "+path, loader.Source("addr"))
+}
+
+func TestLoader_Errors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  source
+		// want - Текст ошибки, %s заменяется путем к файлу.
+		want  string
+		field string
+	}{
+		{
+			name:  "file unknown field",
+			src:   source{file: "c.json", content: `{"addr": ":1", "extra": 1}`},
+			want:  "config extra (file %s): unknown field",
+			field: "extra",
+		},
+		{
+			name:  "file nested value",
+			src:   source{file: "c.yaml", content: "addr:\n  host: localhost\n"},
+			want:  "config addr (file %s): scalar value expected",
+			field: "addr",
+		},
+		{
+			name:  "file invalid integer",
+			src:   source{file: "c.json", content: `{"count": "many"}`},
+			want:  `config count (file %s): invalid integer "many"`,
+			field: "count",
+		},
+		{
+			name:  "env invalid boolean",
+			src:   source{env: map[string]string{"TEST_ENABLED": "maybe"}},
+			want:  `config enabled (env TEST_ENABLED): invalid boolean "maybe"`,
+			field: "enabled",
+		},
+		{
+			name:  "env invalid integer",
+			src:   source{env: map[string]string{"TEST_COUNT": "1.5"}},
+			want:  `config count (env TEST_COUNT): invalid integer "1.5"`,
+			field: "count",
+		},
+		{
+			name:  "flag invalid duration",
+			src:   source{args: []string{"-t", "soon"}},
+			want:  `config timeout (flag -t): time: invalid duration "soon"`,
+			field: "timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, _, path, err := load(t, tt.src)
+			require.EqualError(t, err, strings.Replace(tt.want, "%s", path, 1))
+
+			var cfgErr *Error
+			require.True(t, errors.As(err, &cfgErr))
+			require.Equal(t, tt.field, cfgErr.Field)
+		})
+	}
+
+	_, _, path, err := load(t, source{file: "c.json", content: `{"addr": `})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "config file "+path)
+}
+
+func TestLoader_Errorf(t *testing.T) {
+
+	_, loader, path, err := load(t, source{
+		file:    "c.json",
+		content: `{"addr": "bad", "count": 0}`,
+		env:     map[string]string{"TEST_COUNT": "-1"},
+		args:    []string{"-t", "0s"},
+	})
+	require.NoError(t, err)
+
+	// Ошибка проверки указывает источник текущего значения
+	require.EqualError(t, loader.Errorf("addr", "invalid address"), "config addr (file "+path+"): invalid address")
+	require.EqualError(t, loader.Errorf("count", "can not be negative"), "config count (env TEST_COUNT): can not be negative")
+	require.EqualError(t, loader.Errorf("timeout", "must be positive"), "config timeout (flag -t): must be positive")
+	require.EqualError(t, loader.Errorf("secret", "must be set"), "config secret (default): must be set")
+}
@@ -21,4 +21,12 @@ var (
 	ErrInternal        = NewErr("internal error")
 	ErrCancel          = NewErr("operation canceled")
 	ErrLargeData       = NewErr("large data")
+	ErrChecksum        = NewErr("checksum mismatch")
+	ErrUnavailable     = NewErr("service unavailable")
+	ErrQueued          = NewErr("operation queued")
+	ErrConflict        = NewErr("version conflict")
+	ErrPlaintext       = NewErr("unencrypted data rejected")
+	ErrNoRotation      = NewErr("key rotation is not in progress")
+	ErrUnauthenticated = NewErr("unauthenticated")
+	ErrTooManyAttempts = NewErr("too many attempts")
 )
@@ -0,0 +1,193 @@
+// Package passhash - Хэширование паролей пользователей на сервере.
+//
+// Хэш хранится вместе с алгоритмом и параметрами:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<соль base64>$<хэш base64>
+//	$2a$12$...  (bcrypt)
+//
+// Прежние записи содержат пароль в том виде, в каком его передал клиент,
+// они проверяются сравнением и подлежат перехэшированию.
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Алгоритмы хэширования.
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+)
+
+// Пределы параметров хэша из хранилища. Хэш с параметрами вне пределов
+// считается поврежденным: проверка пароля по нему могла бы занять всю
+// память или процессор сервера.
+const (
+	// maxMemory - 1 GiB.
+	maxMemory     = 1024 * 1024
+	maxTime       = 16
+	minSaltSize   = 8
+	minKeySize    = 16
+	maxKeySize    = 64
+	maxBcryptCost = 16
+)
+
+// ErrMalformed - Хэш в неизвестном формате.
+var ErrMalformed = errors.New("passhash: malformed hash")
+
+// Params - Алгоритм и параметры хэширования.
+type Params struct {
+	Alg string
+	// Memory - Память Argon2id в KiB.
+	Memory uint32
+	// Time - Количество проходов Argon2id.
+	Time uint32
+	// Threads - Параллельность Argon2id.
+	Threads uint8
+	// Cost - Стоимость bcrypt.
+	Cost int
+}
+
+// DefaultArgon2id - Параметры Argon2id по RFC 9106 для ограниченной памяти.
+var DefaultArgon2id = Params{Alg: Argon2id, Memory: 64 * 1024, Time: 3, Threads: 4}
+
+// DefaultBcrypt - Параметры bcrypt.
+var DefaultBcrypt = Params{Alg: Bcrypt, Cost: 12}
+
+// ByName - Параметры по умолчанию для алгоритма alg.
+func ByName(alg string) (Params, error) {
+
+	switch alg {
+	case Argon2id:
+		return DefaultArgon2id, nil
+	case Bcrypt:
+		return DefaultBcrypt, nil
+	}
+
+	return Params{}, fmt.Errorf("passhash: unknown algorithm %q", alg)
+}
+
+// Hasher - Хэширование паролей с заданными параметрами.
+type Hasher struct {
+	params Params
+}
+
+// New - Создание Hasher с параметрами params.
+func New(params Params) Hasher {
+	return Hasher{params: params}
+}
+
+// Hash - Хэш пароля со случайной солью.
+func (h Hasher) Hash(password string) (string, error) {
+
+	if h.params.Alg == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.params.Cost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, keySize)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", Argon2id, argon2.Version, p.Memory, p.Time, p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify - Проверка пароля password по хэшу encoded за постоянное время.
+// rehash - хэш устарел (прежняя запись, другой алгоритм или параметры)
+// и после успешной проверки его нужно заменить.
+func (h Hasher) Verify(encoded, password string) (ok bool, rehash bool, err error) {
+
+	switch {
+	case strings.HasPrefix(encoded, "$"+Argon2id+"$"):
+		params, salt, key, errParse := parseArgon2id(encoded)
+		if errParse != nil {
+			return false, false, errParse
+		}
+
+		actual := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+		ok = subtle.ConstantTimeCompare(actual, key) == 1
+		return ok, params != h.params || len(key) != keySize, nil
+
+	case strings.HasPrefix(encoded, "$2"):
+		cost, errCost := bcrypt.Cost([]byte(encoded))
+		if errCost != nil || cost > maxBcryptCost {
+			return false, false, ErrMalformed
+		}
+
+		errCompare := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(errCompare, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if errCompare != nil {
+			return false, false, fmt.Errorf("%w: %v", ErrMalformed, errCompare)
+		}
+
+		return true, h.params != Params{Alg: Bcrypt, Cost: cost}, nil
+
+	case strings.HasPrefix(encoded, "$"):
+		return false, false, ErrMalformed
+	}
+
+	// Прежняя запись без хэширования на сервере
+	ok = subtle.ConstantTimeCompare([]byte(encoded), []byte(password)) == 1
+	return ok, true, nil
+}
+
+// parseArgon2id - Разбор хэша Argon2id.
+func parseArgon2id(encoded string) (Params, []byte, []byte, error) {
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	params := Params{Alg: Argon2id}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	// Sscanf не проверяет остаток строки
+	if parts[3] != fmt.Sprintf("m=%d,t=%d,p=%d", params.Memory, params.Time, params.Threads) {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	// Argon2 требует не меньше 8 KiB памяти на поток
+	if params.Time == 0 || params.Time > maxTime || params.Threads == 0 ||
+		params.Memory < 8*uint32(params.Threads) || params.Memory > maxMemory {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) < minSaltSize {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) < minKeySize || len(key) > maxKeySize {
+		return Params{}, nil, nil, ErrMalformed
+	}
+
+	return params, salt, key, nil
+}
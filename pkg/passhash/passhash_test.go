@@ -0,0 +1,147 @@
+package passhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Параметры, при которых тесты выполняются быстро.
+var (
+	testArgon2id = Params{Alg: Argon2id, Memory: 1024, Time: 1, Threads: 1}
+	testBcrypt   = Params{Alg: Bcrypt, Cost: bcrypt.MinCost}
+)
+
+func TestHasher_RoundTrip(t *testing.T) {
+
+	for _, params := range []Params{testArgon2id, testBcrypt, DefaultArgon2id} {
+		h := New(params)
+
+		encoded, err := h.Hash("secret")
+		require.NoError(t, err)
+
+		ok, rehash, err := h.Verify(encoded, "secret")
+		require.NoError(t, err, params.Alg)
+		require.True(t, ok, params.Alg)
+		require.False(t, rehash, params.Alg)
+
+		ok, _, err = h.Verify(encoded, "Secret")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// Соль случайная
+		again, err := h.Hash("secret")
+		require.NoError(t, err)
+		require.NotEqual(t, encoded, again)
+	}
+}
+
+func TestHasher_Format(t *testing.T) {
+
+	encoded, err := New(testArgon2id).Hash("secret")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"), encoded)
+
+	encoded, err = New(testBcrypt).Hash("secret")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encoded, "$2a$04$"), encoded)
+}
+
+func TestHasher_Rehash(t *testing.T) {
+
+	argon, err := New(testArgon2id).Hash("secret")
+	require.NoError(t, err)
+	bcryptHash, err := New(testBcrypt).Hash("secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		params     Params
+		encoded    string
+		password   string
+		wantOK     bool
+		wantRehash bool
+	}{
+		{name: "legacy row", params: testArgon2id, encoded: "client-hash", password: "client-hash", wantOK: true, wantRehash: true},
+		{name: "legacy row, wrong password", params: testArgon2id, encoded: "client-hash", password: "other", wantOK: false, wantRehash: true},
+		{name: "argon2id, other params", params: DefaultArgon2id, encoded: argon, password: "secret", wantOK: true, wantRehash: true},
+		{name: "bcrypt, other cost", params: Params{Alg: Bcrypt, Cost: 5}, encoded: bcryptHash, password: "secret", wantOK: true, wantRehash: true},
+		{name: "bcrypt, argon2id configured", params: testArgon2id, encoded: bcryptHash, password: "secret", wantOK: true, wantRehash: true},
+		{name: "argon2id, bcrypt configured", params: testBcrypt, encoded: argon, password: "secret", wantOK: true, wantRehash: true},
+		{name: "same params", params: testBcrypt, encoded: bcryptHash, password: "secret", wantOK: true, wantRehash: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			ok, rehash, err := New(tt.params).Verify(tt.encoded, tt.password)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantRehash, rehash)
+		})
+	}
+}
+
+func TestHasher_Malformed(t *testing.T) {
+
+	const (
+		salt = "c29tZXNhbHRzb21lc2FsdA"
+		key  = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	)
+
+	// Корректный хэш - основа поврежденных
+	valid := "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$" + key
+	_, _, err := New(testArgon2id).Verify(valid, "secret")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"unknown algorithm":  "$scrypt$ln=15,r=8,p=1$" + salt + "$" + key,
+		"missing parts":      "$argon2id$v=19$m=1024,t=1,p=1$" + salt,
+		"extra parts":        valid + "$extra",
+		"other version":      "$argon2id$v=16$m=1024,t=1,p=1$" + salt + "$" + key,
+		"bad version":        "$argon2id$v=x$m=1024,t=1,p=1$" + salt + "$" + key,
+		"version suffix":     "$argon2id$v=19x$m=1024,t=1,p=1$" + salt + "$" + key,
+		"params suffix":      "$argon2id$v=19$m=1024,t=1,p=1x$" + salt + "$" + key,
+		"params order":       "$argon2id$v=19$t=1,m=1024,p=1$" + salt + "$" + key,
+		"zero time":          "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key,
+		"huge time":          "$argon2id$v=19$m=1024,t=1000,p=1$" + salt + "$" + key,
+		"zero threads":       "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key,
+		"threads overflow":   "$argon2id$v=19$m=1024,t=1,p=256$" + salt + "$" + key,
+		"memory per thread":  "$argon2id$v=19$m=16,t=1,p=4$" + salt + "$" + key,
+		"huge memory":        "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key,
+		"memory overflow":    "$argon2id$v=19$m=4294967296,t=1,p=1$" + salt + "$" + key,
+		"negative memory":    "$argon2id$v=19$m=-1,t=1,p=1$" + salt + "$" + key,
+		"bad salt":           "$argon2id$v=19$m=1024,t=1,p=1$!!!$" + key,
+		"short salt":         "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$" + key,
+		"bad key":            "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$!!!",
+		"empty key":          "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$",
+		"short key":          "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$a2V5",
+		"bcrypt truncated":   "$2a$04$abc",
+		"bcrypt huge cost":   "$2a$31$" + strings.Repeat("a", 53),
+		"bcrypt invalid":     "$2x$",
+		"empty argon2id":     "$argon2id$",
+		"dollar only prefix": "$",
+	}
+
+	for name, encoded := range tests {
+		ok, _, err := New(testArgon2id).Verify(encoded, "secret")
+		require.ErrorIs(t, err, ErrMalformed, name)
+		require.False(t, ok, name)
+	}
+}
+
+func TestByName(t *testing.T) {
+
+	params, err := ByName(Argon2id)
+	require.NoError(t, err)
+	require.Equal(t, DefaultArgon2id, params)
+
+	params, err = ByName(Bcrypt)
+	require.NoError(t, err)
+	require.Equal(t, DefaultBcrypt, params)
+
+	_, err = ByName("md5")
+	require.Error(t, err)
+}
@@ -0,0 +1,284 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.17.3
+// source: pkg/proto/rotation/rotation.proto
+
+package rotation
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// RotationServiceClient is the client API for RotationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RotationServiceClient interface {
+	Begin(ctx context.Context, in *BeginRequest, opts ...grpc.CallOption) (*Rotation, error)
+	Get(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Rotation, error)
+	BatchUpdate(ctx context.Context, in *BatchUpdateRequest, opts ...grpc.CallOption) (*Empty, error)
+	UpdateBinary(ctx context.Context, opts ...grpc.CallOption) (RotationService_UpdateBinaryClient, error)
+	Finish(ctx context.Context, in *FinishRequest, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type rotationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRotationServiceClient(cc grpc.ClientConnInterface) RotationServiceClient {
+	return &rotationServiceClient{cc}
+}
+
+func (c *rotationServiceClient) Begin(ctx context.Context, in *BeginRequest, opts ...grpc.CallOption) (*Rotation, error) {
+	out := new(Rotation)
+	err := c.cc.Invoke(ctx, "/rotation.RotationService/Begin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotationServiceClient) Get(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Rotation, error) {
+	out := new(Rotation)
+	err := c.cc.Invoke(ctx, "/rotation.RotationService/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotationServiceClient) BatchUpdate(ctx context.Context, in *BatchUpdateRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/rotation.RotationService/BatchUpdate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rotationServiceClient) UpdateBinary(ctx context.Context, opts ...grpc.CallOption) (RotationService_UpdateBinaryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RotationService_ServiceDesc.Streams[0], "/rotation.RotationService/UpdateBinary", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rotationServiceUpdateBinaryClient{stream}
+	return x, nil
+}
+
+type RotationService_UpdateBinaryClient interface {
+	Send(*BinaryChunk) error
+	CloseAndRecv() (*Empty, error)
+	grpc.ClientStream
+}
+
+type rotationServiceUpdateBinaryClient struct {
+	grpc.ClientStream
+}
+
+func (x *rotationServiceUpdateBinaryClient) Send(m *BinaryChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *rotationServiceUpdateBinaryClient) CloseAndRecv() (*Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rotationServiceClient) Finish(ctx context.Context, in *FinishRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/rotation.RotationService/Finish", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RotationServiceServer is the server API for RotationService service.
+// All implementations must embed UnimplementedRotationServiceServer
+// for forward compatibility
+type RotationServiceServer interface {
+	Begin(context.Context, *BeginRequest) (*Rotation, error)
+	Get(context.Context, *Empty) (*Rotation, error)
+	BatchUpdate(context.Context, *BatchUpdateRequest) (*Empty, error)
+	UpdateBinary(RotationService_UpdateBinaryServer) error
+	Finish(context.Context, *FinishRequest) (*Empty, error)
+	mustEmbedUnimplementedRotationServiceServer()
+}
+
+// UnimplementedRotationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRotationServiceServer struct {
+}
+
+func (UnimplementedRotationServiceServer) Begin(context.Context, *BeginRequest) (*Rotation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Begin not implemented")
+}
+func (UnimplementedRotationServiceServer) Get(context.Context, *Empty) (*Rotation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedRotationServiceServer) BatchUpdate(context.Context, *BatchUpdateRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdate not implemented")
+}
+func (UnimplementedRotationServiceServer) UpdateBinary(RotationService_UpdateBinaryServer) error {
+	return status.Errorf(codes.Unimplemented, "method UpdateBinary not implemented")
+}
+func (UnimplementedRotationServiceServer) Finish(context.Context, *FinishRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Finish not implemented")
+}
+func (UnimplementedRotationServiceServer) mustEmbedUnimplementedRotationServiceServer() {}
+
+// UnsafeRotationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RotationServiceServer will
+// result in compilation errors.
+type UnsafeRotationServiceServer interface {
+	mustEmbedUnimplementedRotationServiceServer()
+}
+
+func RegisterRotationServiceServer(s grpc.ServiceRegistrar, srv RotationServiceServer) {
+	s.RegisterService(&RotationService_ServiceDesc, srv)
+}
+
+func _RotationService_Begin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BeginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotationServiceServer).Begin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rotation.RotationService/Begin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotationServiceServer).Begin(ctx, req.(*BeginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RotationService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotationServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rotation.RotationService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotationServiceServer).Get(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RotationService_BatchUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotationServiceServer).BatchUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rotation.RotationService/BatchUpdate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotationServiceServer).BatchUpdate(ctx, req.(*BatchUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RotationService_UpdateBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RotationServiceServer).UpdateBinary(&rotationServiceUpdateBinaryServer{stream})
+}
+
+type RotationService_UpdateBinaryServer interface {
+	SendAndClose(*Empty) error
+	Recv() (*BinaryChunk, error)
+	grpc.ServerStream
+}
+
+type rotationServiceUpdateBinaryServer struct {
+	grpc.ServerStream
+}
+
+func (x *rotationServiceUpdateBinaryServer) SendAndClose(m *Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *rotationServiceUpdateBinaryServer) Recv() (*BinaryChunk, error) {
+	m := new(BinaryChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _RotationService_Finish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RotationServiceServer).Finish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rotation.RotationService/Finish",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RotationServiceServer).Finish(ctx, req.(*FinishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RotationService_ServiceDesc is the grpc.ServiceDesc for RotationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RotationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rotation.RotationService",
+	HandlerType: (*RotationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Begin",
+			Handler:    _RotationService_Begin_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _RotationService_Get_Handler,
+		},
+		{
+			MethodName: "BatchUpdate",
+			Handler:    _RotationService_BatchUpdate_Handler,
+		},
+		{
+			MethodName: "Finish",
+			Handler:    _RotationService_Finish_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UpdateBinary",
+			Handler:       _RotationService_UpdateBinary_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/proto/rotation/rotation.proto",
+}
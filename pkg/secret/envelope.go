@@ -0,0 +1,347 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Формат конверта:
+//
+//	magic "GKE" | version | alg | keyID [8] | len(wrappedKey) uint16 | wrappedKey | noncePrefix [7]
+//	сегмент_1 | ... | сегмент_n
+//
+// Данные шифруются случайным ключом данных, который зашифрован открытым
+// ключом RSA-OAEP (SHA-256). Данные делятся на сегменты по segmentSize байт,
+// каждый сегмент шифруется AES-256-GCM. Nonce сегмента - noncePrefix,
+// номер сегмента и признак последнего сегмента, поэтому перестановка,
+// удаление и усечение сегментов обнаруживаются. Заголовок целиком
+// передается как дополнительные данные AEAD.
+const (
+	envelopeMagic = "GKE"
+	// EnvelopeVersion - Версия формата конверта.
+	EnvelopeVersion byte = 1
+	// AlgAES256GCM - Сегменты шифруются AES-256-GCM.
+	AlgAES256GCM byte = 1
+
+	// KeyIDSize - Размер идентификатора ключа RSA.
+	KeyIDSize = 8
+
+	dataKeySize     = 32
+	noncePrefixSize = 7
+	segmentSize     = 64 * 1024
+	// fixedHeaderSize - Размер заголовка до зашифрованного ключа данных.
+	fixedHeaderSize = len(envelopeMagic) + 2 + KeyIDSize + 2
+)
+
+var (
+	// ErrKeyMismatch - Данные зашифрованы другим ключом.
+	ErrKeyMismatch = errors.New("secret: data encrypted with another key")
+	// ErrMalformed - Поврежденный или усеченный конверт.
+	ErrMalformed = errors.New("secret: malformed envelope")
+	// ErrUnsupported - Неизвестная версия формата или алгоритм.
+	ErrUnsupported = errors.New("secret: unsupported envelope")
+)
+
+// KeyID - Идентификатор ключа RSA: начало SHA-256 от открытого ключа в DER.
+func KeyID(publicKey *rsa.PublicKey) []byte {
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		// Для ключа RSA ошибка невозможна
+		der = publicKey.N.Bytes()
+	}
+
+	sum := sha256.Sum256(der)
+	return sum[:KeyIDSize]
+}
+
+// Header - Заголовок конверта.
+type Header struct {
+	Version byte
+	Alg     byte
+	KeyID   []byte
+
+	wrappedKey  []byte
+	noncePrefix []byte
+	// raw - Заголовок целиком, дополнительные данные AEAD.
+	raw []byte
+}
+
+// IsEnvelope - Проверка, что data начинается с заголовка конверта.
+// Данные, зашифрованные прежним форматом (блоки RSA-OAEP), конвертом не считаются.
+func IsEnvelope(data []byte) bool {
+	return len(data) > len(envelopeMagic) &&
+		bytes.HasPrefix(data, []byte(envelopeMagic)) &&
+		data[len(envelopeMagic)] == EnvelopeVersion
+}
+
+// EncryptedWith - Проверка, что data - конверт, зашифрованный ключом publicKey.
+// Достаточно начала данных до зашифрованного ключа данных.
+func EncryptedWith(publicKey *rsa.PublicKey, data []byte) bool {
+
+	if len(data) < fixedHeaderSize || !IsEnvelope(data) {
+		return false
+	}
+
+	pos := len(envelopeMagic) + 2
+	return bytes.Equal(data[pos:pos+KeyIDSize], KeyID(publicKey))
+}
+
+// ParseHeader - Чтение заголовка конверта из начала data.
+// Возвращает заголовок и его размер. Если data короче заголовка,
+// возвращается ErrMalformed.
+func ParseHeader(data []byte) (Header, int, error) {
+
+	if len(data) < fixedHeaderSize {
+		return Header{}, 0, fmt.Errorf("%w: short header", ErrMalformed)
+	}
+
+	if !IsEnvelope(data) {
+		return Header{}, 0, ErrUnsupported
+	}
+
+	pos := len(envelopeMagic)
+	h := Header{
+		Version: data[pos],
+		Alg:     data[pos+1],
+	}
+	pos += 2
+
+	if h.Alg != AlgAES256GCM {
+		return Header{}, 0, fmt.Errorf("%w: algorithm %d", ErrUnsupported, h.Alg)
+	}
+
+	h.KeyID = data[pos : pos+KeyIDSize]
+	pos += KeyIDSize
+
+	wrappedLen := int(binary.BigEndian.Uint16(data[pos:]))
+	pos += 2
+
+	size := pos + wrappedLen + noncePrefixSize
+	if len(data) < size {
+		return Header{}, 0, fmt.Errorf("%w: short header", ErrMalformed)
+	}
+
+	h.wrappedKey = data[pos : pos+wrappedLen]
+	h.noncePrefix = data[pos+wrappedLen : size]
+	h.raw = data[:size]
+
+	return h, size, nil
+}
+
+// ReadHeader - Чтение заголовка конверта из начала r.
+// Возвращает заголовок и прочитанные байты: их нужно передать дальше
+// вместе с остатком r. Если r закончился раньше заголовка, возвращается
+// ErrMalformed, ошибки чтения из r возвращаются как есть.
+func ReadHeader(r io.Reader) (Header, []byte, error) {
+
+	raw := make([]byte, fixedHeaderSize)
+	if err := readFull(r, raw); err != nil {
+		return Header{}, nil, err
+	}
+
+	if !IsEnvelope(raw) {
+		return Header{}, nil, ErrUnsupported
+	}
+
+	wrappedLen := int(binary.BigEndian.Uint16(raw[fixedHeaderSize-2:]))
+	raw = append(raw, make([]byte, wrappedLen+noncePrefixSize)...)
+	if err := readFull(r, raw[fixedHeaderSize:]); err != nil {
+		return Header{}, nil, err
+	}
+
+	h, _, err := ParseHeader(raw)
+	if err != nil {
+		return Header{}, nil, err
+	}
+
+	return h, raw, nil
+}
+
+// readFull - io.ReadFull, для которого нехватка данных - ErrMalformed.
+func readFull(r io.Reader, buf []byte) error {
+
+	_, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: short header", ErrMalformed)
+	}
+
+	return err
+}
+
+// segments - Шифрование и расшифровка сегментов одного конверта.
+type segments struct {
+	aead        cipher.AEAD
+	noncePrefix []byte
+	aad         []byte
+	counter     uint32
+}
+
+// newEnvelope - Создание заголовка конверта для открытого ключа publicKey
+// со случайным ключом данных.
+func newEnvelope(publicKey *rsa.PublicKey) ([]byte, *segments, error) {
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, dataKey, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	noncePrefix := make([]byte, noncePrefixSize)
+	if _, err = rand.Read(noncePrefix); err != nil {
+		return nil, nil, err
+	}
+
+	header := make([]byte, 0, fixedHeaderSize+len(wrappedKey)+noncePrefixSize)
+	header = append(header, envelopeMagic...)
+	header = append(header, EnvelopeVersion, AlgAES256GCM)
+	header = append(header, KeyID(publicKey)...)
+	header = append(header, 0, 0)
+	binary.BigEndian.PutUint16(header[len(header)-2:], uint16(len(wrappedKey)))
+	header = append(header, wrappedKey...)
+	header = append(header, noncePrefix...)
+
+	seg, err := newSegments(dataKey, noncePrefix, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, seg, nil
+}
+
+// openEnvelope - Расшифровка ключа данных конверта закрытым ключом privKey.
+func openEnvelope(privKey *rsa.PrivateKey, h Header) (*segments, error) {
+
+	if !bytes.Equal(h.KeyID, KeyID(&privKey.PublicKey)) {
+		return nil, ErrKeyMismatch
+	}
+
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), nil, privKey, h.wrappedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	return newSegments(dataKey, h.noncePrefix, h.raw)
+}
+
+func newSegments(dataKey, noncePrefix, aad []byte) (*segments, error) {
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &segments{
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		aad:         aad,
+	}, nil
+}
+
+// nonce - Nonce очередного сегмента.
+func (s *segments) nonce(last bool) []byte {
+
+	nonce := make([]byte, 0, s.aead.NonceSize())
+	nonce = append(nonce, s.noncePrefix...)
+	nonce = append(nonce, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], s.counter)
+	if last {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
+
+// seal - Шифрование очередного сегмента.
+func (s *segments) seal(dst, plain []byte, last bool) []byte {
+
+	out := s.aead.Seal(dst, s.nonce(last), plain, s.aad)
+	s.counter++
+
+	return out
+}
+
+// open - Расшифровка очередного сегмента.
+func (s *segments) open(dst, sealed []byte, last bool) ([]byte, error) {
+
+	out, err := s.aead.Open(dst, s.nonce(last), sealed, s.aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: segment %d: %v", ErrMalformed, s.counter, err)
+	}
+	s.counter++
+
+	return out, nil
+}
+
+// sealedSegmentSize - Размер зашифрованного полного сегмента.
+func (s *segments) sealedSegmentSize() int {
+	return segmentSize + s.aead.Overhead()
+}
+
+// encryptEnvelope - Шифрование data в конверт.
+func encryptEnvelope(publicKey *rsa.PublicKey, data []byte) ([]byte, error) {
+
+	header, seg, err := newEnvelope(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	count := len(data)/segmentSize + 1
+	out := make([]byte, 0, len(header)+len(data)+count*seg.aead.Overhead())
+	out = append(out, header...)
+
+	for {
+		if len(data) <= segmentSize {
+			return seg.seal(out, data, true), nil
+		}
+
+		out = seg.seal(out, data[:segmentSize], false)
+		data = data[segmentSize:]
+	}
+}
+
+// decryptEnvelope - Расшифровка конверта data.
+func decryptEnvelope(privKey *rsa.PrivateKey, data []byte) ([]byte, error) {
+
+	h, size, err := ParseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	seg, err := openEnvelope(privKey, h)
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[size:]
+	out := make([]byte, 0, len(data))
+
+	for {
+		if len(data) <= seg.sealedSegmentSize() {
+			return seg.open(out, data, true)
+		}
+
+		if out, err = seg.open(out, data[:seg.sealedSegmentSize()], false); err != nil {
+			return nil, err
+		}
+		data = data[seg.sealedSegmentSize():]
+	}
+}
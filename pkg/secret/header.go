@@ -0,0 +1,11 @@
+package secret
+
+// Метаданные gRPC, в которых клиент сообщает, зашифрованы ли отправляемые данные.
+const (
+	// EncryptionHeader - Ключ метаданных со способом шифрования данных.
+	EncryptionHeader = "x-encryption"
+	// EncryptionEnvelope - Данные зашифрованы в конверт.
+	EncryptionEnvelope = "envelope"
+	// EncryptionNone - Данные не зашифрованы (режим --insecure-plaintext).
+	EncryptionNone = "none"
+)
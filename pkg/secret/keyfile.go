@@ -0,0 +1,453 @@
+package secret
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Типы ключей, которые создает cmd/crypto.
+const (
+	KeyRSA3072 = "rsa-3072"
+	KeyRSA4096 = "rsa-4096"
+	KeyX25519  = "x25519"
+	KeyEd25519 = "ed25519"
+)
+
+// KeyTypes - Поддерживаемые типы ключей.
+var KeyTypes = []string{KeyRSA3072, KeyRSA4096, KeyX25519, KeyEd25519}
+
+// Типы блоков PEM.
+const (
+	pemPrivateKey          = "PRIVATE KEY"
+	pemEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+	pemRSAPrivateKey       = "RSA PRIVATE KEY"
+	pemPublicKey           = "PUBLIC KEY"
+)
+
+// pbkdf2Iterations - Количество итераций PBKDF2 при шифровании закрытого ключа.
+// maxPBKDF2Iterations - Ограничение при чтении, чтобы файл ключа не мог
+// занять клиент вычислениями на неограниченное время.
+const (
+	pbkdf2Iterations    = 600000
+	maxPBKDF2Iterations = 10000000
+)
+
+var (
+	// ErrPassphrase - Неверная парольная фраза закрытого ключа.
+	ErrPassphrase = errors.New("secret: wrong passphrase")
+	// ErrNeedPassphrase - Закрытый ключ зашифрован, а парольная фраза не задана.
+	ErrNeedPassphrase = errors.New("secret: private key is encrypted")
+	// ErrKeyType - Неизвестный тип ключа.
+	ErrKeyType = errors.New("secret: unsupported key type")
+	// ErrNoPEM - В данных нет блока PEM.
+	ErrNoPEM = errors.New("secret: PEM block not found")
+)
+
+var (
+	oidX25519         = asn1.ObjectIdentifier{1, 3, 101, 110}
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// X25519PrivateKey - Закрытый ключ X25519.
+type X25519PrivateKey []byte
+
+// X25519PublicKey - Открытый ключ X25519.
+type X25519PublicKey []byte
+
+// Public - Открытый ключ, соответствующий закрытому.
+func (k X25519PrivateKey) Public() crypto.PublicKey {
+	pub, _ := curve25519.X25519(k, curve25519.Basepoint)
+	return X25519PublicKey(pub)
+}
+
+// GenerateKey - Создание закрытого ключа типа keyType.
+func GenerateKey(keyType string) (crypto.PrivateKey, error) {
+
+	switch keyType {
+	case KeyRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+
+	case KeyRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+
+	case KeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+
+	case KeyX25519:
+		key := make([]byte, curve25519.ScalarSize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return X25519PrivateKey(key), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrKeyType, keyType)
+}
+
+// PublicKey - Открытый ключ, соответствующий закрытому key.
+func PublicKey(key crypto.PrivateKey) (crypto.PublicKey, error) {
+
+	signer, ok := key.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrKeyType, key)
+	}
+
+	return signer.Public(), nil
+}
+
+// KeyType - Название типа ключа для сообщений пользователю.
+func KeyType(key interface{}) string {
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return fmt.Sprintf("rsa-%d", k.N.BitLen())
+	case *rsa.PublicKey:
+		return fmt.Sprintf("rsa-%d", k.N.BitLen())
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return KeyEd25519
+	case X25519PrivateKey, X25519PublicKey:
+		return KeyX25519
+	}
+
+	return fmt.Sprintf("%T", key)
+}
+
+// MarshalPrivateKey - Закрытый ключ в PEM (PKCS#8).
+// Если passphrase не пустая, ключ шифруется по PBES2
+// (PBKDF2-HMAC-SHA256, AES-256-CBC) и совместим с openssl.
+func MarshalPrivateKey(key crypto.PrivateKey, passphrase []byte) ([]byte, error) {
+
+	der, err := marshalPKCS8(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+	}
+
+	der, err = encryptPKCS8(der, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemEncryptedPrivateKey, Bytes: der}), nil
+}
+
+// MarshalPublicKey - Открытый ключ в PEM (SubjectPublicKeyInfo).
+func MarshalPublicKey(key crypto.PublicKey) ([]byte, error) {
+
+	var der []byte
+	var err error
+
+	if k, ok := key.(X25519PublicKey); ok {
+		der, err = asn1.Marshal(subjectPublicKeyInfo{
+			Algo:      pkix.AlgorithmIdentifier{Algorithm: oidX25519},
+			PublicKey: asn1.BitString{Bytes: k, BitLength: len(k) * 8},
+		})
+	} else {
+		der, err = x509.MarshalPKIXPublicKey(key)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// IsEncryptedKey - Проверка, что закрытый ключ в PEM зашифрован парольной фразой.
+func IsEncryptedKey(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil && block.Type == pemEncryptedPrivateKey
+}
+
+// ParsePrivateKey - Чтение закрытого ключа из PEM: PKCS#8, в том числе
+// зашифрованного парольной фразой passphrase, или PKCS#1 прежних версий cmd/crypto.
+// Для зашифрованного ключа без парольной фразы возвращается ErrNeedPassphrase,
+// при неверной парольной фразе - ErrPassphrase.
+func ParsePrivateKey(data, passphrase []byte) (crypto.PrivateKey, error) {
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPEM
+	}
+
+	switch block.Type {
+	case pemRSAPrivateKey:
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+
+	case pemEncryptedPrivateKey:
+		if len(passphrase) == 0 {
+			return nil, ErrNeedPassphrase
+		}
+
+		der, err := decryptPKCS8(block.Bytes, passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parsePKCS8(der)
+		if err != nil {
+			// Неверная парольная фраза может дать корректное дополнение
+			return nil, ErrPassphrase
+		}
+		return key, nil
+	}
+
+	return parsePKCS8(block.Bytes)
+}
+
+// ParsePublicKey - Чтение открытого ключа из PEM (SubjectPublicKeyInfo).
+// Тип блока не проверяется: прежние версии cmd/crypto записывали "RSA PUBLIC KEY".
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPEM
+	}
+
+	var info subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(block.Bytes, &info); err == nil && info.Algo.Algorithm.Equal(oidX25519) {
+		return X25519PublicKey(info.PublicKey.RightAlign()), nil
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// pkcs8 - Закрытый ключ PKCS#8 (RFC 5208).
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// subjectPublicKeyInfo - Открытый ключ (RFC 5280).
+type subjectPublicKeyInfo struct {
+	Algo      pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// encryptedPrivateKeyInfo - Зашифрованный закрытый ключ PKCS#8.
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params - Параметры PBES2 (RFC 8018).
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params - Параметры PBKDF2 (RFC 8018).
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// marshalPKCS8 - Закрытый ключ в DER PKCS#8.
+// Ключи X25519 кодируются вручную: x509 Go 1.18 их не поддерживает.
+func marshalPKCS8(key crypto.PrivateKey) ([]byte, error) {
+
+	k, ok := key.(X25519PrivateKey)
+	if !ok {
+		return x509.MarshalPKCS8PrivateKey(key)
+	}
+
+	inner, err := asn1.Marshal([]byte(k))
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(pkcs8{
+		Algo:       pkix.AlgorithmIdentifier{Algorithm: oidX25519},
+		PrivateKey: inner,
+	})
+}
+
+// parsePKCS8 - Чтение закрытого ключа из DER PKCS#8.
+func parsePKCS8(der []byte) (crypto.PrivateKey, error) {
+
+	var info pkcs8
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	if !info.Algo.Algorithm.Equal(oidX25519) {
+		return x509.ParsePKCS8PrivateKey(der)
+	}
+
+	var key []byte
+	if _, err := asn1.Unmarshal(info.PrivateKey, &key); err != nil {
+		return nil, err
+	}
+
+	if len(key) != curve25519.ScalarSize {
+		return nil, fmt.Errorf("%w: invalid X25519 key size %d", ErrKeyType, len(key))
+	}
+
+	return X25519PrivateKey(key), nil
+}
+
+// encryptPKCS8 - Шифрование DER PKCS#8 парольной фразой по PBES2.
+func encryptPKCS8(der, passphrase []byte) ([]byte, error) {
+
+	salt := make([]byte, 16)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2.Key(passphrase, salt, pbkdf2Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Дополнение PKCS#7
+	padLen := aes.BlockSize - len(der)%aes.BlockSize
+	sealed := append(append([]byte{}, der...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(sealed, sealed)
+
+	kdf, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdf}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo:          pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: sealed,
+	})
+}
+
+// decryptPKCS8 - Расшифровка PKCS#8, зашифрованного по PBES2
+// (PBKDF2 с HMAC-SHA1 или HMAC-SHA256, AES-CBC).
+func decryptPKCS8(der, passphrase []byte) ([]byte, error) {
+
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: encryption %v", ErrUnsupported, info.Algo.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("%w: key derivation %v", ErrUnsupported, params.KeyDerivationFunc.Algorithm)
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, err
+	}
+
+	if kdf.IterationCount <= 0 || kdf.IterationCount > maxPBKDF2Iterations {
+		return nil, fmt.Errorf("%w: PBKDF2 iterations %d", ErrUnsupported, kdf.IterationCount)
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	default:
+		return nil, fmt.Errorf("%w: PRF %v", ErrUnsupported, kdf.PRF.Algorithm)
+	}
+
+	var keySize int
+	switch alg := params.EncryptionScheme.Algorithm; {
+	case alg.Equal(oidAES128CBC):
+		keySize = 16
+	case alg.Equal(oidAES192CBC):
+		keySize = 24
+	case alg.Equal(oidAES256CBC):
+		keySize = 32
+	default:
+		return nil, fmt.Errorf("%w: cipher %v", ErrUnsupported, alg)
+	}
+
+	if kdf.KeyLength != 0 && kdf.KeyLength != keySize {
+		return nil, fmt.Errorf("%w: key length %d", ErrUnsupported, kdf.KeyLength)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+
+	sealed := info.EncryptedData
+	if len(iv) != aes.BlockSize || len(sealed) == 0 || len(sealed)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("%w: invalid encrypted key", ErrMalformed)
+	}
+
+	key := pbkdf2.Key(passphrase, kdf.Salt, kdf.IterationCount, keySize, prf)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := make([]byte, len(sealed))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, sealed)
+
+	padLen := int(plain[len(plain)-1])
+	if padLen == 0 || padLen > aes.BlockSize ||
+		!bytes.Equal(plain[len(plain)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		return nil, ErrPassphrase
+	}
+
+	return plain[:len(plain)-padLen], nil
+}
@@ -0,0 +1,187 @@
+package secret
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testKeys - Ключи каждого типа для тестов чтения и записи PEM.
+var testKeys = func() map[string]crypto.PrivateKey {
+	keys := make(map[string]crypto.PrivateKey)
+	for _, keyType := range []string{KeyRSA3072, KeyX25519, KeyEd25519} {
+		key, err := GenerateKey(keyType)
+		if err != nil {
+			panic(err)
+		}
+		keys[keyType] = key
+	}
+	return keys
+}()
+
+// keyEqual - Сравнение закрытых ключей.
+func keyEqual(t *testing.T, want, got crypto.PrivateKey) {
+	if k, ok := want.(interface{ Equal(crypto.PrivateKey) bool }); ok {
+		require.True(t, k.Equal(got))
+		return
+	}
+	require.Equal(t, want, got)
+}
+
+func TestPrivateKey_RoundTrip(t *testing.T) {
+
+	for keyType, key := range testKeys {
+		for _, passphrase := range []string{"", "correct horse"} {
+			t.Run(keyType+" passphrase "+passphrase, func(t *testing.T) {
+
+				data, err := MarshalPrivateKey(key, []byte(passphrase))
+				require.NoError(t, err)
+				require.Equal(t, len(passphrase) != 0, IsEncryptedKey(data))
+
+				parsed, err := ParsePrivateKey(data, []byte(passphrase))
+				require.NoError(t, err)
+				keyEqual(t, key, parsed)
+				require.Equal(t, keyType, KeyType(parsed))
+
+				pub, err := PublicKey(key)
+				require.NoError(t, err)
+
+				pubPEM, err := MarshalPublicKey(pub)
+				require.NoError(t, err)
+
+				parsedPub, err := ParsePublicKey(pubPEM)
+				require.NoError(t, err)
+				require.Equal(t, pub, parsedPub)
+				require.Equal(t, keyType, KeyType(parsedPub))
+			})
+		}
+	}
+}
+
+func TestParsePrivateKey_Passphrase(t *testing.T) {
+
+	data, err := MarshalPrivateKey(testKeys[KeyEd25519], []byte("secret"))
+	require.NoError(t, err)
+
+	_, err = ParsePrivateKey(data, []byte("wrong"))
+	require.ErrorIs(t, err, ErrPassphrase)
+
+	_, err = ParsePrivateKey(data, nil)
+	require.ErrorIs(t, err, ErrNeedPassphrase)
+
+	// Парольная фраза незашифрованного ключа не нужна
+	plain, err := MarshalPrivateKey(testKeys[KeyEd25519], nil)
+	require.NoError(t, err)
+
+	key, err := ParsePrivateKey(plain, []byte("ignored"))
+	require.NoError(t, err)
+	keyEqual(t, testKeys[KeyEd25519], key)
+}
+
+func TestParsePrivateKey_Corrupted(t *testing.T) {
+
+	encrypted, err := MarshalPrivateKey(testKeys[KeyX25519], []byte("secret"))
+	require.NoError(t, err)
+
+	plain, err := MarshalPrivateKey(testKeys[KeyX25519], nil)
+	require.NoError(t, err)
+
+	// reencode - Блок PEM data с данными, измененными fn.
+	reencode := func(data []byte, fn func(der []byte) []byte) []byte {
+		block, _ := pem.Decode(data)
+		require.NotNil(t, block)
+		block.Bytes = fn(append([]byte(nil), block.Bytes...))
+		return pem.EncodeToMemory(block)
+	}
+
+	// iterations - Зашифрованный ключ с заданным количеством итераций PBKDF2.
+	iterations := func(count int) []byte {
+		return reencode(encrypted, func(der []byte) []byte {
+			var info encryptedPrivateKeyInfo
+			_, err := asn1.Unmarshal(der, &info)
+			require.NoError(t, err)
+
+			var params pbes2Params
+			_, err = asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params)
+			require.NoError(t, err)
+
+			var kdf pbkdf2Params
+			_, err = asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf)
+			require.NoError(t, err)
+
+			kdf.IterationCount = count
+			kdfDER, err := asn1.Marshal(kdf)
+			require.NoError(t, err)
+
+			params.KeyDerivationFunc = pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfDER}}
+			paramsDER, err := asn1.Marshal(params)
+			require.NoError(t, err)
+
+			info.Algo = pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: paramsDER}}
+			der, err = asn1.Marshal(info)
+			require.NoError(t, err)
+			return der
+		})
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "empty", data: nil, err: ErrNoPEM},
+		{name: "not PEM", data: []byte("not a key"), err: ErrNoPEM},
+		{name: "broken base64", data: bytes.Replace(plain, []byte("\n"), []byte("\n!"), 2), err: ErrNoPEM},
+		{name: "truncated PEM", data: plain[:len(plain)-10], err: ErrNoPEM},
+		{
+			name: "truncated DER",
+			data: reencode(plain, func(der []byte) []byte { return der[:len(der)-1] }),
+		},
+		{
+			name: "X25519 key size",
+			data: reencode(plain, func(der []byte) []byte {
+				der, err := asn1.Marshal(pkcs8{
+					Algo:       pkix.AlgorithmIdentifier{Algorithm: oidX25519},
+					PrivateKey: []byte{4, 1, 0},
+				})
+				require.NoError(t, err)
+				return der
+			}),
+			err: ErrKeyType,
+		},
+		{
+			name: "encrypted data",
+			data: reencode(encrypted, func(der []byte) []byte { der[len(der)-1] ^= 1; return der }),
+			err:  ErrPassphrase,
+		},
+		{
+			name: "truncated encrypted data",
+			data: reencode(encrypted, func(der []byte) []byte { return der[:len(der)-1] }),
+		},
+		{name: "PBKDF2 iterations above limit", data: iterations(maxPBKDF2Iterations + 1), err: ErrUnsupported},
+		{name: "zero PBKDF2 iterations", data: iterations(0), err: ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePrivateKey(tt.data, []byte("secret"))
+			require.Error(t, err)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+
+	_, err = ParsePublicKey([]byte("not a key"))
+	require.ErrorIs(t, err, ErrNoPEM)
+}
+
+func TestGenerateKey_Unknown(t *testing.T) {
+	_, err := GenerateKey("dsa-1024")
+	require.ErrorIs(t, err, ErrKeyType)
+}
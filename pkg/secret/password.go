@@ -0,0 +1,212 @@
+package secret
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Наборы символов пароля.
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#$%&()*+,-./:;<=>?@[]^_{}~"
+	// ambiguousChars - Символы, которые легко перепутать при чтении.
+	ambiguousChars = "0Oo1lI"
+
+	// MinPasswordLength, MaxPasswordLength - Допустимая длина пароля.
+	MinPasswordLength = 4
+	MaxPasswordLength = 128
+	// MinPassphraseWords, MaxPassphraseWords - Допустимое количество слов парольной фразы.
+	MinPassphraseWords = 3
+	MaxPassphraseWords = 32
+)
+
+// wordlist - Список слов BIP-39 (2048 слов, 11 бит на слово).
+// Первые четыре буквы слов не повторяются.
+//
+//go:embed wordlist.txt
+var wordlist string
+
+var words = strings.Fields(wordlist)
+
+// ErrPasswordPolicy - Пароль с заданными параметрами не сгенерировать.
+var ErrPasswordPolicy = errors.New("secret: invalid password policy")
+
+// PasswordPolicy - Параметры случайного пароля.
+// В пароле есть хотя бы один символ каждого выбранного набора.
+type PasswordPolicy struct {
+	Length  int
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+	// ExcludeAmbiguous - Не использовать похожие символы (0/O/o, 1/l/I).
+	ExcludeAmbiguous bool
+}
+
+// DefaultPassword - Параметры пароля по умолчанию.
+var DefaultPassword = PasswordPolicy{
+	Length:           20,
+	Lower:            true,
+	Upper:            true,
+	Digits:           true,
+	Symbols:          true,
+	ExcludeAmbiguous: true,
+}
+
+// PassphrasePolicy - Параметры парольной фразы из случайных слов (diceware).
+type PassphrasePolicy struct {
+	Words     int
+	Separator string
+}
+
+// DefaultPassphrase - Параметры парольной фразы по умолчанию.
+var DefaultPassphrase = PassphrasePolicy{
+	Words:     6,
+	Separator: "-",
+}
+
+// Generated - Сгенерированный пароль и оценка его энтропии.
+type Generated struct {
+	Value string
+	// Entropy - Энтропия в битах при условии, что параметры генерации известны.
+	Entropy float64
+}
+
+// GeneratePassword - Случайный пароль с параметрами p.
+func GeneratePassword(p PasswordPolicy) (Generated, error) {
+
+	if p.Length < MinPasswordLength || p.Length > MaxPasswordLength {
+		return Generated{}, fmt.Errorf("%w: length must be from %d to %d", ErrPasswordPolicy, MinPasswordLength, MaxPasswordLength)
+	}
+
+	var classes []string
+	for _, class := range []struct {
+		use   bool
+		chars string
+	}{
+		{p.Lower, lowerChars},
+		{p.Upper, upperChars},
+		{p.Digits, digitChars},
+		{p.Symbols, symbolChars},
+	} {
+		if !class.use {
+			continue
+		}
+
+		chars := class.chars
+		if p.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+
+	if len(classes) == 0 {
+		return Generated{}, fmt.Errorf("%w: no character classes", ErrPasswordPolicy)
+	}
+	if p.Length < len(classes) {
+		return Generated{}, fmt.Errorf("%w: length is less than the number of character classes", ErrPasswordPolicy)
+	}
+
+	alphabet := strings.Join(classes, "")
+	value := make([]byte, p.Length)
+
+	// Пароли без какого-либо набора отбрасываются: так каждый подходящий
+	// пароль равновероятен и энтропия считается точно
+	for {
+		for i := range value {
+			n, err := randInt(len(alphabet))
+			if err != nil {
+				return Generated{}, err
+			}
+			value[i] = alphabet[n]
+		}
+
+		if hasAll(string(value), classes) {
+			break
+		}
+	}
+
+	return Generated{Value: string(value), Entropy: passwordEntropy(p.Length, classes)}, nil
+}
+
+// GeneratePassphrase - Парольная фраза из случайных слов встроенного списка.
+func GeneratePassphrase(p PassphrasePolicy) (Generated, error) {
+
+	if p.Words < MinPassphraseWords || p.Words > MaxPassphraseWords {
+		return Generated{}, fmt.Errorf("%w: words must be from %d to %d", ErrPasswordPolicy, MinPassphraseWords, MaxPassphraseWords)
+	}
+
+	chosen := make([]string, 0, p.Words)
+	for i := 0; i < p.Words; i++ {
+		n, err := randInt(len(words))
+		if err != nil {
+			return Generated{}, err
+		}
+		chosen = append(chosen, words[n])
+	}
+
+	return Generated{
+		Value:   strings.Join(chosen, p.Separator),
+		Entropy: float64(p.Words) * math.Log2(float64(len(words))),
+	}, nil
+}
+
+// randInt - Равномерно распределенное случайное число из [0, n).
+func randInt(n int) (int, error) {
+
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(v.Int64()), nil
+}
+
+// hasAll - В value есть символ каждого набора classes.
+func hasAll(value string, classes []string) bool {
+
+	for _, chars := range classes {
+		if !strings.ContainsAny(value, chars) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// passwordEntropy - log2 количества паролей длины length, в которых есть символ
+// каждого набора classes. Количество считается по формуле включений-исключений
+// как доля от всех паролей из объединения наборов.
+func passwordEntropy(length int, classes []string) float64 {
+
+	total := 0
+	for _, chars := range classes {
+		total += len(chars)
+	}
+
+	share := 0.0
+	for mask := 0; mask < 1<<len(classes); mask++ {
+		excluded, sign := 0, 1.0
+		for i, chars := range classes {
+			if mask&(1<<i) != 0 {
+				excluded += len(chars)
+				sign = -sign
+			}
+		}
+		share += sign * math.Pow(float64(total-excluded)/float64(total), float64(length))
+	}
+
+	return float64(length)*math.Log2(float64(total)) + math.Log2(share)
+}
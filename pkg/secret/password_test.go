@@ -0,0 +1,165 @@
+package secret
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// samples - Количество паролей, проверяемых для каждой политики.
+const samples = 200
+
+func TestGeneratePassword(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		policy PasswordPolicy
+		// classes - Наборы, символ каждого из которых должен быть в пароле.
+		classes []string
+	}{
+		{
+			name:    "default",
+			policy:  DefaultPassword,
+			classes: []string{"abcdefghijkmnpqrstuvwxyz", "ABCDEFGHJKLMNPQRSTUVWXYZ", "23456789", symbolChars},
+		},
+		{
+			name:    "ambiguous allowed",
+			policy:  PasswordPolicy{Length: 12, Lower: true, Upper: true, Digits: true},
+			classes: []string{lowerChars, upperChars, digitChars},
+		},
+		{
+			name:    "minimum length",
+			policy:  PasswordPolicy{Length: MinPasswordLength, Lower: true, Upper: true, Digits: true, Symbols: true, ExcludeAmbiguous: true},
+			classes: []string{"abcdefghijkmnpqrstuvwxyz", "ABCDEFGHJKLMNPQRSTUVWXYZ", "23456789", symbolChars},
+		},
+		{
+			name:    "digits only",
+			policy:  PasswordPolicy{Length: MaxPasswordLength, Digits: true, ExcludeAmbiguous: true},
+			classes: []string{"23456789"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			alphabet := strings.Join(tt.classes, "")
+
+			for i := 0; i < samples; i++ {
+				gen, err := GeneratePassword(tt.policy)
+				require.NoError(t, err)
+
+				require.Len(t, gen.Value, tt.policy.Length)
+				for _, r := range gen.Value {
+					require.True(t, strings.ContainsRune(alphabet, r), "%q is not allowed in %q", r, gen.Value)
+				}
+				for _, chars := range tt.classes {
+					require.True(t, strings.ContainsAny(gen.Value, chars), "%q has no characters of %q", gen.Value, chars)
+				}
+				if tt.policy.ExcludeAmbiguous {
+					require.False(t, strings.ContainsAny(gen.Value, ambiguousChars), gen.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePassword_Policy(t *testing.T) {
+
+	for _, p := range []PasswordPolicy{
+		{Length: MinPasswordLength - 1, Lower: true},
+		{Length: MaxPasswordLength + 1, Lower: true},
+		{Length: 20},
+	} {
+		_, err := GeneratePassword(p)
+		require.ErrorIs(t, err, ErrPasswordPolicy, "%+v", p)
+	}
+}
+
+func TestGeneratePassword_Entropy(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		policy PasswordPolicy
+		want   float64
+	}{
+		{
+			name:   "one class",
+			policy: PasswordPolicy{Length: 8, Lower: true},
+			want:   8 * math.Log2(26),
+		},
+		{
+			// 0 и 1 исключены, остается 8 цифр - ровно 3 бита на символ
+			name:   "one class without ambiguous",
+			policy: PasswordPolicy{Length: 10, Digits: true, ExcludeAmbiguous: true},
+			want:   30,
+		},
+		{
+			// Все пароли из 36 символов, кроме состоящих только из букв или только из цифр
+			name:   "two classes",
+			policy: PasswordPolicy{Length: 4, Upper: true, Digits: true},
+			want:   math.Log2(math.Pow(36, 4) - math.Pow(26, 4) - math.Pow(10, 4)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := GeneratePassword(tt.policy)
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, gen.Entropy, 1e-9)
+		})
+	}
+
+	// Ограничение наборов снижает энтропию не больше, чем на долю отброшенных паролей
+	gen, err := GeneratePassword(DefaultPassword)
+	require.NoError(t, err)
+	pool := len(strings.Join([]string{"abcdefghijkmnpqrstuvwxyz", "ABCDEFGHJKLMNPQRSTUVWXYZ", "23456789", symbolChars}, ""))
+	full := float64(DefaultPassword.Length) * math.Log2(float64(pool))
+	require.Less(t, gen.Entropy, full)
+	require.Greater(t, gen.Entropy, full-1)
+}
+
+func TestWordlist(t *testing.T) {
+
+	require.Len(t, words, 2048)
+
+	prefixes := make(map[string]string, len(words))
+	for _, word := range words {
+		prefix := word
+		if len(prefix) > 4 {
+			prefix = prefix[:4]
+		}
+		require.NotContains(t, prefixes, prefix, "%s and %s", word, prefixes[prefix])
+		prefixes[prefix] = word
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+
+	known := make(map[string]bool, len(words))
+	for _, word := range words {
+		known[word] = true
+	}
+
+	for _, n := range []int{MinPassphraseWords, DefaultPassphrase.Words, MaxPassphraseWords} {
+		for _, sep := range []string{"-", " "} {
+			gen, err := GeneratePassphrase(PassphrasePolicy{Words: n, Separator: sep})
+			require.NoError(t, err)
+
+			chosen := strings.Split(gen.Value, sep)
+			require.Len(t, chosen, n, gen.Value)
+			for _, word := range chosen {
+				require.True(t, known[word], "%q is not in the wordlist", word)
+			}
+
+			// 2048 слов - 11 бит на слово
+			require.InDelta(t, float64(11*n), gen.Entropy, 1e-9)
+		}
+	}
+
+	for _, n := range []int{0, MinPassphraseWords - 1, MaxPassphraseWords + 1} {
+		_, err := GeneratePassphrase(PassphrasePolicy{Words: n, Separator: "-"})
+		require.ErrorIs(t, err, ErrPasswordPolicy, "words %d", n)
+	}
+}
@@ -2,50 +2,49 @@ package secret
 
 import (
 	"crypto"
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/hex"
 )
 
+// Encrypt - Шифрование data открытым ключом publicKey в конверт
+// (см. формат в envelope.go). Если publicKey == nil, данные не шифруются.
 func Encrypt(publicKey *rsa.PublicKey, data []byte) ([]byte, error) {
 	if publicKey == nil {
 		return data, nil
 	}
 
-	hashFunc := sha256.New()
-	dataLen := len(data)
-	step := publicKey.Size() - hashFunc.Size()*2 - 2
-	var encryptedBytes []byte
-	for start := 0; start < dataLen; start += step {
-		finish := start + step
-		if finish > dataLen {
-			finish = dataLen
-		}
-
-		encryptedBlockBytes, err := rsa.EncryptOAEP(
-			hashFunc,
-			rand.Reader,
-			publicKey,
-			data[start:finish],
-			nil)
-
-		if err != nil {
-			return nil, err
-		}
-
-		encryptedBytes = append(encryptedBytes, encryptedBlockBytes...)
-	}
-
-	return encryptedBytes, nil
+	return encryptEnvelope(publicKey, data)
 }
 
+// Decrypt - Расшифровка data закрытым ключом privKey.
+// Кроме конвертов расшифровываются данные прежнего формата -
+// последовательность блоков RSA-OAEP. Если privKey == nil, данные не расшифровываются.
 func Decrypt(privKey *rsa.PrivateKey, data []byte) ([]byte, error) {
 
 	if privKey == nil {
 		return data, nil
 	}
 
+	if !IsEnvelope(data) {
+		return decryptLegacy(privKey, data)
+	}
+
+	plain, err := decryptEnvelope(privKey, data)
+	if err != nil && len(data)%privKey.PublicKey.Size() == 0 {
+		// Блоки прежнего формата случайно начались с заголовка конверта
+		if legacy, errLegacy := decryptLegacy(privKey, data); errLegacy == nil {
+			return legacy, nil
+		}
+	}
+
+	return plain, err
+}
+
+// decryptLegacy - Расшифровка прежнего формата: данные делились на блоки
+// и каждый блок шифровался RSA-OAEP (SHA-256) отдельно.
+func decryptLegacy(privKey *rsa.PrivateKey, data []byte) ([]byte, error) {
+
 	dataLen := len(data)
 	step := privKey.PublicKey.Size()
 	var decryptedBytes []byte
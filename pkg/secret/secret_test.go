@@ -0,0 +1,230 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testKey - Ключ RSA для тестов, генерируется один раз.
+var testKey = func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// sealedSegment - Размер зашифрованного полного сегмента.
+const sealedSegment = segmentSize + 16
+
+func randomBytes(t *testing.T, n int) []byte {
+	data := make([]byte, n)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	return data
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+
+	for _, size := range []int{0, 1, segmentSize, segmentSize + 1, 2*segmentSize + 1} {
+		plain := randomBytes(t, size)
+
+		enc, err := Encrypt(&testKey.PublicKey, plain)
+		require.NoError(t, err)
+		require.True(t, IsEnvelope(enc), "size %d", size)
+		require.True(t, EncryptedWith(&testKey.PublicKey, enc))
+
+		dec, err := Decrypt(testKey, enc)
+		require.NoError(t, err, "size %d", size)
+		require.Equal(t, len(plain), len(dec))
+		require.True(t, bytes.Equal(plain, dec), "size %d", size)
+	}
+}
+
+func TestStream(t *testing.T) {
+
+	for _, size := range []int{0, 1, segmentSize, segmentSize + 1} {
+		plain := randomBytes(t, size)
+
+		enc, err := io.ReadAll(NewEncryptReader(&testKey.PublicKey, bytes.NewReader(plain)))
+		require.NoError(t, err)
+
+		// Потоковый и обычный формат совместимы
+		dec, err := Decrypt(testKey, enc)
+		require.NoError(t, err, "size %d", size)
+		require.True(t, bytes.Equal(plain, dec))
+
+		var out bytes.Buffer
+		w := NewDecryptWriter(testKey, &out)
+		// Запись частями, не совпадающими с границами сегментов
+		for rest := enc; len(rest) != 0; {
+			n := 1000
+			if n > len(rest) {
+				n = len(rest)
+			}
+			_, err = w.Write(rest[:n])
+			require.NoError(t, err)
+			rest = rest[n:]
+		}
+		require.NoError(t, w.Close())
+		require.True(t, bytes.Equal(plain, out.Bytes()), "size %d", size)
+	}
+}
+
+// TestDecryptLegacy - Данные, зашифрованные исходной реализацией
+// (блоки RSA-OAEP SHA-256), расшифровываются без изменений.
+func TestDecryptLegacy(t *testing.T) {
+
+	keyPEM, err := os.ReadFile("testdata/legacy_key.pem")
+	require.NoError(t, err)
+	block, _ := pem.Decode(keyPEM)
+	require.NotNil(t, block)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	enc, err := os.ReadFile("testdata/legacy.bin")
+	require.NoError(t, err)
+	plain, err := os.ReadFile("testdata/legacy_plain.txt")
+	require.NoError(t, err)
+
+	require.False(t, IsEnvelope(enc))
+
+	dec, err := Decrypt(key, enc)
+	require.NoError(t, err)
+	require.Equal(t, plain, dec)
+
+	var out bytes.Buffer
+	w := NewDecryptWriter(key, &out)
+	_, err = w.Write(enc)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, plain, out.Bytes())
+
+	// Поврежденный блок прежнего формата не расшифровывается
+	enc[len(enc)-1] ^= 1
+	_, err = Decrypt(key, enc)
+	require.Error(t, err)
+}
+
+func TestDecryptTampered(t *testing.T) {
+
+	plain := randomBytes(t, 2*segmentSize+100)
+	enc, err := Encrypt(&testKey.PublicKey, plain)
+	require.NoError(t, err)
+
+	_, headerSize, err := ParseHeader(enc)
+	require.NoError(t, err)
+
+	body := enc[headerSize:]
+	require.Equal(t, 2*sealedSegment+100+16, len(body))
+
+	modify := func(fn func(data []byte) []byte) []byte {
+		data := append([]byte(nil), enc...)
+		return fn(data)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = Decrypt(other, enc)
+	require.ErrorIs(t, err, ErrKeyMismatch)
+
+	cases := map[string][]byte{
+		// Заголовок - дополнительные данные AEAD
+		"header alg": modify(func(data []byte) []byte {
+			data[len(envelopeMagic)+1] ^= 1
+			return data
+		}),
+		"header key id": modify(func(data []byte) []byte {
+			data[len(envelopeMagic)+2] ^= 1
+			return data
+		}),
+		"header nonce prefix": modify(func(data []byte) []byte {
+			data[headerSize-1] ^= 1
+			return data
+		}),
+		"wrapped key": modify(func(data []byte) []byte {
+			data[fixedHeaderSize+10] ^= 1
+			return data
+		}),
+		"segment byte": modify(func(data []byte) []byte {
+			data[headerSize+sealedSegment+5] ^= 1
+			return data
+		}),
+		"short header": enc[:fixedHeaderSize-1],
+		"no segments":  enc[:headerSize],
+		// Усечение по границе сегмента: последний сегмент потерян
+		"truncated at segment": enc[:headerSize+2*sealedSegment],
+		"truncated tail":       enc[:len(enc)-1],
+		"reordered segments": modify(func(data []byte) []byte {
+			first := append([]byte(nil), data[headerSize:headerSize+sealedSegment]...)
+			copy(data[headerSize:], data[headerSize+sealedSegment:headerSize+2*sealedSegment])
+			copy(data[headerSize+sealedSegment:], first)
+			return data
+		}),
+		"dropped segment": modify(func(data []byte) []byte {
+			return append(data[:headerSize+sealedSegment], data[headerSize+2*sealedSegment:]...)
+		}),
+		"trailing data": modify(func(data []byte) []byte {
+			return append(data, 0)
+		}),
+	}
+
+	for name, data := range cases {
+		_, err = Decrypt(testKey, data)
+		require.Error(t, err, name)
+
+		var out bytes.Buffer
+		w := NewDecryptWriter(testKey, &out)
+		_, errWrite := w.Write(data)
+		errClose := w.Close()
+		require.True(t, errWrite != nil || errClose != nil, "stream %s", name)
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+
+	enc, err := Encrypt(&testKey.PublicKey, []byte("data"))
+	require.NoError(t, err)
+
+	want, size, err := ParseHeader(enc)
+	require.NoError(t, err)
+
+	r := bytes.NewReader(enc)
+	h, raw, err := ReadHeader(r)
+	require.NoError(t, err)
+	require.Equal(t, want.KeyID, h.KeyID)
+	require.Equal(t, enc[:size], raw)
+
+	// Заголовок и остаток потока вместе - исходный конверт
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, enc, append(raw, rest...))
+
+	_, _, err = ReadHeader(bytes.NewReader(enc[:size-1]))
+	require.ErrorIs(t, err, ErrMalformed)
+
+	_, _, err = ReadHeader(bytes.NewReader([]byte("plain data, not an envelope")))
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestPlaintext(t *testing.T) {
+
+	data := []byte("plain")
+
+	enc, err := Encrypt(nil, data)
+	require.NoError(t, err)
+	require.Equal(t, data, enc)
+
+	dec, err := Decrypt(nil, data)
+	require.NoError(t, err)
+	require.Equal(t, data, dec)
+}
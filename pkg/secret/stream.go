@@ -0,0 +1,230 @@
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EncryptReader - Потоковое шифрование данных из r в конверт.
+// Результат совместим с Decrypt: данные шифруются сегментами,
+// поэтому в памяти хранится не больше одного сегмента.
+type EncryptReader struct {
+	publicKey *rsa.PublicKey
+	src       *bufio.Reader
+	seg       *segments
+	plain     []byte
+	out       []byte
+	eof       bool
+}
+
+// NewEncryptReader - Создание потока шифрования данных из r.
+// Если publicKey == nil, данные не шифруются.
+func NewEncryptReader(publicKey *rsa.PublicKey, r io.Reader) io.Reader {
+	if publicKey == nil {
+		return r
+	}
+
+	return &EncryptReader{
+		publicKey: publicKey,
+		src:       bufio.NewReader(r),
+		plain:     make([]byte, segmentSize),
+	}
+}
+
+func (er *EncryptReader) Read(p []byte) (int, error) {
+
+	for len(er.out) == 0 {
+		if er.eof {
+			return 0, io.EOF
+		}
+
+		if er.seg == nil {
+			header, seg, err := newEnvelope(er.publicKey)
+			if err != nil {
+				return 0, err
+			}
+
+			er.seg = seg
+			er.out = header
+			continue
+		}
+
+		last, n, err := er.next()
+		if err != nil {
+			return 0, err
+		}
+
+		er.out = er.seg.seal(nil, er.plain[:n], last)
+		er.eof = last
+	}
+
+	n := copy(p, er.out)
+	er.out = er.out[n:]
+
+	return n, nil
+}
+
+// next - Чтение очередного сегмента и проверка, что он последний.
+func (er *EncryptReader) next() (bool, int, error) {
+
+	n, err := io.ReadFull(er.src, er.plain)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true, n, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Полный сегмент последний, если за ним нет данных
+	if _, err = er.src.Peek(1); err == io.EOF {
+		return true, n, nil
+	} else if err != nil {
+		return false, 0, err
+	}
+
+	return false, n, nil
+}
+
+// DecryptWriter - Потоковая расшифровка данных с записью результата в w.
+// Данные расшифровываются по мере накопления полных сегментов конверта.
+// Данные прежнего формата расшифровываются по мере накопления полных блоков RSA.
+type DecryptWriter struct {
+	privKey *rsa.PrivateKey
+	dst     io.Writer
+	buf     bytes.Buffer
+	seg     *segments
+	// legacy - Данные прежнего формата (блоки RSA-OAEP).
+	legacy bool
+}
+
+// NewDecryptWriter - Создание потока расшифровки данных в w.
+// Если privKey == nil, данные записываются без изменений.
+func NewDecryptWriter(privKey *rsa.PrivateKey, w io.Writer) io.WriteCloser {
+	return &DecryptWriter{
+		privKey: privKey,
+		dst:     w,
+	}
+}
+
+func (dw *DecryptWriter) Write(p []byte) (int, error) {
+
+	if dw.privKey == nil {
+		return dw.dst.Write(p)
+	}
+
+	dw.buf.Write(p)
+
+	if dw.seg == nil && !dw.legacy {
+		ready, err := dw.header()
+		if err != nil {
+			return 0, err
+		}
+		if !ready {
+			return len(p), nil
+		}
+	}
+
+	if dw.legacy {
+		return len(p), dw.writeLegacy()
+	}
+
+	// Последний сегмент расшифровывается в Close
+	for dw.buf.Len() > dw.seg.sealedSegmentSize() {
+		if err := dw.open(dw.buf.Next(dw.seg.sealedSegmentSize()), false); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close - Расшифровка оставшихся данных.
+// Ошибка, если данные усечены.
+func (dw *DecryptWriter) Close() error {
+
+	if dw.privKey == nil {
+		return nil
+	}
+
+	if dw.legacy || dw.seg == nil {
+		if dw.buf.Len() == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("decrypt: incomplete block of %d bytes", dw.buf.Len())
+	}
+
+	return dw.open(dw.buf.Next(dw.buf.Len()), true)
+}
+
+// header - Определение формата данных и чтение заголовка конверта.
+// Возвращает false, если данных для этого пока недостаточно.
+func (dw *DecryptWriter) header() (bool, error) {
+
+	if dw.buf.Len() <= len(envelopeMagic) {
+		return false, nil
+	}
+
+	if !IsEnvelope(dw.buf.Bytes()) {
+		dw.legacy = true
+		return true, nil
+	}
+
+	_, size, err := ParseHeader(dw.buf.Bytes())
+	if errors.Is(err, ErrMalformed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Заголовок копируется: буфер переиспользуется при следующих записях
+	raw := append([]byte(nil), dw.buf.Next(size)...)
+	h, _, err := ParseHeader(raw)
+	if err != nil {
+		return false, err
+	}
+
+	if dw.seg, err = openEnvelope(dw.privKey, h); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (dw *DecryptWriter) open(sealed []byte, last bool) error {
+
+	plain, err := dw.seg.open(nil, sealed, last)
+	if err != nil {
+		return err
+	}
+
+	_, err = dw.dst.Write(plain)
+	return err
+}
+
+// writeLegacy - Расшифровка полных блоков RSA прежнего формата.
+func (dw *DecryptWriter) writeLegacy() error {
+
+	size := dw.privKey.PublicKey.Size()
+	full := dw.buf.Len() / size * size
+	data := dw.buf.Next(full)
+
+	for start := 0; start < len(data); start += size {
+		block, err := dw.privKey.Decrypt(nil, data[start:start+size], &rsa.OAEPOptions{Hash: crypto.SHA256})
+		if err != nil {
+			return err
+		}
+
+		if _, err = dw.dst.Write(block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
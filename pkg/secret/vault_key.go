@@ -0,0 +1,177 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Формат зашифрованного ключа хранилища:
+//
+//	magic "GKK" | version | time uint32 | memory uint32 | threads | salt [16] | nonce [12]
+//	AES-256-GCM(PKCS#8 закрытого ключа)
+//
+// Ключ шифрования получается из мастер-пароля через Argon2id
+// с параметрами и солью из заголовка. Заголовок - дополнительные данные AEAD.
+const (
+	vaultKeyMagic           = "GKK"
+	vaultKeyVersion    byte = 1
+	vaultKeySaltSize        = 16
+	vaultKeyNonceSize       = 12
+	vaultKeyHeaderSize      = len(vaultKeyMagic) + 1 + 4 + 4 + 1 + vaultKeySaltSize + vaultKeyNonceSize
+
+	// VaultKeyBits - Размер ключа RSA хранилища.
+	VaultKeyBits = 3072
+)
+
+// Пределы параметров Argon2id из заголовка. Параметры приходят с сервером
+// вместе с ключом, и без пределов поврежденный или подмененный ключ
+// мог бы занять всю память или процессор клиента.
+const (
+	// maxKDFMemory - 1 GiB.
+	maxKDFMemory  = 1024 * 1024
+	maxKDFTime    = 16
+	maxKDFThreads = 16
+)
+
+// ErrWrongPassword - Неверный мастер-пароль или поврежденный ключ.
+var ErrWrongPassword = errors.New("secret: wrong master password")
+
+// KDFParams - Параметры Argon2id.
+type KDFParams struct {
+	Time uint32
+	// Memory - Память в КиБ.
+	Memory  uint32
+	Threads uint8
+}
+
+// DefaultKDF - Параметры Argon2id по умолчанию.
+var DefaultKDF = KDFParams{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+// NewVaultKey - Создание ключа хранилища, зашифрованного мастер-паролем master.
+// Возвращает ключ и его зашифрованную копию для хранения на сервере.
+func NewVaultKey(master string) (*rsa.PrivateKey, []byte, error) {
+
+	key, err := rsa.GenerateKey(rand.Reader, VaultKeyBits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sealed, err := SealVaultKey(master, key, DefaultKDF)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, sealed, nil
+}
+
+// SealVaultKey - Шифрование ключа хранилища мастер-паролем master
+// со случайной солью.
+func SealVaultKey(master string, key *rsa.PrivateKey, params KDFParams) ([]byte, error) {
+
+	plain, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, vaultKeyHeaderSize)
+	pos := copy(header, vaultKeyMagic)
+	header[pos] = vaultKeyVersion
+	pos++
+	binary.BigEndian.PutUint32(header[pos:], params.Time)
+	pos += 4
+	binary.BigEndian.PutUint32(header[pos:], params.Memory)
+	pos += 4
+	header[pos] = params.Threads
+	pos++
+
+	// Соль и nonce
+	if _, err = rand.Read(header[pos:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := vaultKeyAEAD(master, header)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[vaultKeyHeaderSize-vaultKeyNonceSize:]
+	return aead.Seal(header, nonce, plain, header), nil
+}
+
+// OpenVaultKey - Расшифровка ключа хранилища мастер-паролем master.
+// При неверном пароле возвращается ErrWrongPassword.
+func OpenVaultKey(master string, sealed []byte) (*rsa.PrivateKey, error) {
+
+	if len(sealed) < vaultKeyHeaderSize || !bytes.HasPrefix(sealed, []byte(vaultKeyMagic)) {
+		return nil, fmt.Errorf("%w: not a vault key", ErrUnsupported)
+	}
+
+	if version := sealed[len(vaultKeyMagic)]; version != vaultKeyVersion {
+		return nil, fmt.Errorf("%w: vault key version %d", ErrUnsupported, version)
+	}
+
+	header := sealed[:vaultKeyHeaderSize]
+	aead, err := vaultKeyAEAD(master, header)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[vaultKeyHeaderSize-vaultKeyNonceSize:]
+	plain, err := aead.Open(nil, nonce, sealed[vaultKeyHeaderSize:], header)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: vault key is not RSA", ErrUnsupported)
+	}
+
+	return key, nil
+}
+
+// vaultKeyAEAD - AES-256-GCM с ключом из мастер-пароля и параметров заголовка.
+func vaultKeyAEAD(master string, header []byte) (cipher.AEAD, error) {
+
+	pos := len(vaultKeyMagic) + 1
+	params := KDFParams{
+		Time:    binary.BigEndian.Uint32(header[pos:]),
+		Memory:  binary.BigEndian.Uint32(header[pos+4:]),
+		Threads: header[pos+8],
+	}
+	salt := header[pos+9 : pos+9+vaultKeySaltSize]
+
+	// Argon2 требует не меньше 8 KiB памяти на поток
+	if params.Time == 0 || params.Time > maxKDFTime ||
+		params.Threads == 0 || params.Threads > maxKDFThreads ||
+		params.Memory < 8*uint32(params.Threads) || params.Memory > maxKDFMemory {
+		return nil, fmt.Errorf("%w: invalid KDF parameters", ErrUnsupported)
+	}
+
+	kek := argon2.IDKey([]byte(master), salt, params.Time, params.Memory, params.Threads, 32)
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
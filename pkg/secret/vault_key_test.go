@@ -0,0 +1,117 @@
+package secret
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testKDF - Быстрые параметры Argon2id для тестов.
+var testKDF = KDFParams{Time: 1, Memory: 1024, Threads: 1}
+
+func TestVaultKey(t *testing.T) {
+
+	key, sealed, err := NewVaultKey("master")
+	require.NoError(t, err)
+	require.Equal(t, VaultKeyBits, key.N.BitLen())
+
+	opened, err := OpenVaultKey("master", sealed)
+	require.NoError(t, err)
+	require.True(t, key.Equal(opened))
+
+	// Соль случайная: одинаковый ключ с одинаковым паролем шифруется по-разному
+	first, err := SealVaultKey("master", testKey, testKDF)
+	require.NoError(t, err)
+	second, err := SealVaultKey("master", testKey, testKDF)
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	opened, err = OpenVaultKey("master", second)
+	require.NoError(t, err)
+	require.True(t, testKey.Equal(opened))
+
+	_, err = OpenVaultKey("wrong", first)
+	require.ErrorIs(t, err, ErrWrongPassword)
+
+	_, err = OpenVaultKey("", first)
+	require.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestOpenVaultKey_Tampered(t *testing.T) {
+
+	sealed, err := SealVaultKey("master", testKey, testKDF)
+	require.NoError(t, err)
+
+	// Смещения полей заголовка
+	const (
+		posVersion = len(vaultKeyMagic)
+		posTime    = posVersion + 1
+		posMemory  = posTime + 4
+		posThreads = posMemory + 4
+		posSalt    = posThreads + 1
+		posNonce   = posSalt + vaultKeySaltSize
+	)
+
+	modify := func(fn func(data []byte)) []byte {
+		data := append([]byte(nil), sealed...)
+		fn(data)
+		return data
+	}
+
+	flip := func(pos int) []byte {
+		return modify(func(data []byte) { data[pos] ^= 1 })
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "magic", data: flip(0), err: ErrUnsupported},
+		{name: "version", data: flip(posVersion), err: ErrUnsupported},
+		{name: "short header", data: sealed[:vaultKeyHeaderSize-1], err: ErrUnsupported},
+		// Параметры, соль и nonce - дополнительные данные AEAD
+		{name: "time", data: modify(func(data []byte) { data[posTime+3] = 2 }), err: ErrWrongPassword},
+		{name: "memory", data: flip(posMemory + 2), err: ErrWrongPassword},
+		{name: "threads", data: modify(func(data []byte) { data[posThreads] = 2 }), err: ErrWrongPassword},
+		{name: "salt", data: flip(posSalt), err: ErrWrongPassword},
+		{name: "nonce", data: flip(posNonce), err: ErrWrongPassword},
+		{name: "ciphertext", data: flip(vaultKeyHeaderSize + 10), err: ErrWrongPassword},
+		{name: "truncated", data: sealed[:len(sealed)-1], err: ErrWrongPassword},
+		// Параметры вне пределов отклоняются без вычисления Argon2id
+		{
+			name: "zero time",
+			data: modify(func(data []byte) { binary.BigEndian.PutUint32(data[posTime:], 0) }),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "huge time",
+			data: modify(func(data []byte) { binary.BigEndian.PutUint32(data[posTime:], 1<<31) }),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "huge memory",
+			data: modify(func(data []byte) { binary.BigEndian.PutUint32(data[posMemory:], 1<<32-1) }),
+			err:  ErrUnsupported,
+		},
+		{
+			name: "memory below threads",
+			data: modify(func(data []byte) { binary.BigEndian.PutUint32(data[posMemory:], 7) }),
+			err:  ErrUnsupported,
+		},
+		{name: "zero threads", data: modify(func(data []byte) { data[posThreads] = 0 }), err: ErrUnsupported},
+		{name: "huge threads", data: modify(func(data []byte) { data[posThreads] = 255 }), err: ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OpenVaultKey("master", tt.data)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	// Ключ с параметрами вне пределов не создается
+	_, err = SealVaultKey("master", testKey, KDFParams{Time: maxKDFTime + 1, Memory: 1024, Threads: 1})
+	require.ErrorIs(t, err, ErrUnsupported)
+}
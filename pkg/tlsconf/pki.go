@@ -0,0 +1,128 @@
+package tlsconf
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"GophKeeper/pkg/secret"
+)
+
+// Issued - Сертификат и закрытый ключ в PEM.
+type Issued struct {
+	Cert    *x509.Certificate
+	Key     crypto.Signer
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// NewCA - Самоподписанный сертификат CA для локального развертывания.
+// keyType - тип ключа из secret.KeyTypes, кроме X25519: им нельзя подписывать.
+func NewCA(keyType, commonName string, ttl time.Duration) (*Issued, error) {
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	return issue(template, nil, keyType, ttl)
+}
+
+// NewServerCert - Сертификат сервера для имен и адресов hosts, подписанный ca.
+func NewServerCert(ca *Issued, keyType string, hosts []string, ttl time.Duration) (*Issued, error) {
+
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0]},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	return issue(template, ca, keyType, ttl)
+}
+
+// NewClientCert - Сертификат клиента для mTLS, подписанный ca.
+func NewClientCert(ca *Issued, keyType, commonName string, ttl time.Duration) (*Issued, error) {
+
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	return issue(template, ca, keyType, ttl)
+}
+
+// issue - Создание ключа и сертификата по шаблону template.
+// Если parent равен nil, сертификат самоподписанный.
+func issue(template *x509.Certificate, parent *Issued, keyType string, ttl time.Duration) (*Issued, error) {
+
+	privateKey, err := secret.GenerateKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, secret.ErrKeyType
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	// Обмен ключами TLS 1.2 с RSA требует шифрования ключа
+	if _, isRSA := privateKey.(*rsa.PrivateKey); isRSA && !template.IsCA {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	now := time.Now()
+	template.SerialNumber = serial
+	template.NotBefore = now.Add(-time.Hour)
+	template.NotAfter = now.Add(ttl)
+
+	issuer, issuerKey := template, signer
+	if parent != nil {
+		issuer, issuerKey = parent.Cert, parent.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, signer.Public(), issuerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ключи TLS читаются сервером без участия пользователя, поэтому не шифруются
+	keyPEM, err := secret.MarshalPrivateKey(privateKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Issued{
+		Cert:    cert,
+		Key:     signer,
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  keyPEM,
+	}, nil
+}
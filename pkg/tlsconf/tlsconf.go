@@ -0,0 +1,220 @@
+// Package tlsconf - Настройка TLS и взаимного TLS (mTLS) для соединения
+// клиента с сервером и перезагрузка сертификатов сервера при их изменении.
+package tlsconf
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReloadInterval - Период проверки изменения файлов сертификатов сервера.
+const ReloadInterval = 10 * time.Second
+
+// ErrNoCertificates - В файле CA нет сертификатов.
+var ErrNoCertificates = errors.New("tlsconf: no certificates found")
+
+// Server - Сертификаты сервера, которые перечитываются при изменении файлов.
+type Server struct {
+	certFile string
+	keyFile  string
+	// caFile - Сертификаты CA для проверки клиентов. Если задан,
+	// клиент обязан предъявить сертификат, подписанный этим CA (mTLS).
+	caFile string
+
+	mutex   sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time
+	logger  *zap.Logger
+}
+
+// NewServer - Загрузка сертификата certFile с ключом keyFile
+// и, если caFile не пустой, CA для проверки сертификатов клиентов.
+func NewServer(certFile, keyFile, caFile string) (*Server, error) {
+
+	s := &Server{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		logger:   zap.L(),
+	}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Config - Настройки TLS сервера. Каждое соединение использует
+// сертификаты, загруженные последними.
+func (s *Server) Config() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return s.current(), nil
+		},
+	}
+}
+
+// Reload - Повторное чтение сертификатов. При ошибке прежние остаются в силе.
+func (s *Server) Reload() error {
+
+	modTime, err := s.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return fmt.Errorf("tlsconf: load server certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if len(s.caFile) != 0 {
+		if pool, err = loadPool(s.caFile); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cert = &cert
+	s.pool = pool
+	s.modTime = modTime
+
+	return nil
+}
+
+// Watch - Перезагрузка сертификатов при изменении файлов, пока не отменен ctx.
+// Файлы проверяются раз в interval.
+func (s *Server) Watch(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			modTime, err := s.lastModified()
+			if err != nil {
+				s.logger.Error("failed check certificates", zap.Error(err))
+				continue
+			}
+
+			s.mutex.RLock()
+			changed := !modTime.Equal(s.modTime)
+			s.mutex.RUnlock()
+
+			if !changed {
+				continue
+			}
+
+			if err = s.Reload(); err != nil {
+				s.logger.Error("failed reload certificates", zap.Error(err))
+				continue
+			}
+
+			s.logger.Info("certificates reloaded", zap.String("cert", s.certFile))
+		}
+	}
+}
+
+// current - Настройки TLS с последними загруженными сертификатами.
+func (s *Server) current() *tls.Config {
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*s.cert},
+		NextProtos:   []string{"h2"},
+	}
+
+	if s.pool != nil {
+		cfg.ClientCAs = s.pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg
+}
+
+// lastModified - Время последнего изменения файлов сертификатов.
+func (s *Server) lastModified() (time.Time, error) {
+
+	var last time.Time
+	for _, file := range []string{s.certFile, s.keyFile, s.caFile} {
+		if len(file) == 0 {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+
+	return last, nil
+}
+
+// Client - Настройки TLS клиента.
+// caFile - сертификаты CA сервера, если пустой - системные.
+// certFile и keyFile - сертификат клиента для mTLS, если пустые - не предъявляется.
+func Client(caFile, certFile, keyFile string) (*tls.Config, error) {
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(caFile) != 0 {
+		pool, err := loadPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	if len(certFile) != 0 || len(keyFile) != 0 {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tlsconf: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// loadPool - Сертификаты CA из файла PEM.
+func loadPool(file string) (*x509.CertPool, error) {
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("tlsconf: read CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w in %s", ErrNoCertificates, file)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,225 @@
+package tlsconf
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"GophKeeper/pkg/secret"
+)
+
+// pki - Файлы сертификатов в каталоге теста.
+type pki struct {
+	ca         *Issued
+	caFile     string
+	serverCert string
+	serverKey  string
+	clientCert string
+	clientKey  string
+}
+
+// writePair - Запись сертификата и ключа в файлы каталога dir.
+func writePair(t *testing.T, dir, name string, issued *Issued) (string, string) {
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	require.NoError(t, os.WriteFile(certFile, issued.CertPEM, 0644))
+	require.NoError(t, os.WriteFile(keyFile, issued.KeyPEM, 0600))
+
+	return certFile, keyFile
+}
+
+// newPKI - CA, сертификаты сервера для localhost и клиента в t.TempDir().
+func newPKI(t *testing.T) *pki {
+
+	dir := t.TempDir()
+
+	ca, err := NewCA(secret.KeyEd25519, "Test CA", time.Hour)
+	require.NoError(t, err)
+
+	server, err := NewServerCert(ca, secret.KeyEd25519, []string{"localhost", "127.0.0.1"}, time.Hour)
+	require.NoError(t, err)
+
+	client, err := NewClientCert(ca, secret.KeyEd25519, "client", time.Hour)
+	require.NoError(t, err)
+
+	p := &pki{ca: ca}
+	p.caFile, _ = writePair(t, dir, "ca", ca)
+	p.serverCert, p.serverKey = writePair(t, dir, "server", server)
+	p.clientCert, p.clientKey = writePair(t, dir, "client", client)
+
+	return p
+}
+
+// handshake - Установка соединения TLS клиента client с сервером server
+// через loopback. Возвращает серийный номер сертификата сервера.
+func handshake(t *testing.T, server, client *tls.Config) (*big.Int, error) {
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		err = conn.(*tls.Conn).Handshake()
+		// Клиент в TLS 1.3 узнает об отказе сервера только при чтении
+		if err == nil {
+			_, err = conn.Write([]byte{1})
+		}
+		done <- err
+	}()
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", listener.Addr().String(), client)
+	if err == nil {
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+	}
+
+	if serverErr := <-done; err == nil {
+		err = serverErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber, nil
+}
+
+func clientConfig(t *testing.T, p *pki, withCert bool) *tls.Config {
+
+	certFile, keyFile := p.clientCert, p.clientKey
+	if !withCert {
+		certFile, keyFile = "", ""
+	}
+
+	cfg, err := Client(p.caFile, certFile, keyFile)
+	require.NoError(t, err)
+	cfg.ServerName = "localhost"
+
+	return cfg
+}
+
+func TestServer_TLS(t *testing.T) {
+
+	p := newPKI(t)
+
+	srv, err := NewServer(p.serverCert, p.serverKey, "")
+	require.NoError(t, err)
+
+	_, err = handshake(t, srv.Config(), clientConfig(t, p, false))
+	require.NoError(t, err)
+
+	// Клиент без CA сервера не доверяет самоподписанному CA
+	_, err = handshake(t, srv.Config(), &tls.Config{ServerName: "localhost", MinVersion: tls.VersionTLS12})
+	require.Error(t, err)
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+
+	p := newPKI(t)
+
+	srv, err := NewServer(p.serverCert, p.serverKey, p.caFile)
+	require.NoError(t, err)
+
+	_, err = handshake(t, srv.Config(), clientConfig(t, p, true))
+	require.NoError(t, err)
+
+	// Без сертификата клиента соединение отклоняется
+	_, err = handshake(t, srv.Config(), clientConfig(t, p, false))
+	require.Error(t, err)
+
+	// Сертификат, подписанный другим CA, отклоняется
+	otherCA, err := NewCA(secret.KeyEd25519, "Other CA", time.Hour)
+	require.NoError(t, err)
+	other, err := NewClientCert(otherCA, secret.KeyEd25519, "client", time.Hour)
+	require.NoError(t, err)
+	certFile, keyFile := writePair(t, t.TempDir(), "other", other)
+
+	cfg, err := Client(p.caFile, certFile, keyFile)
+	require.NoError(t, err)
+	cfg.ServerName = "localhost"
+
+	_, err = handshake(t, srv.Config(), cfg)
+	require.Error(t, err)
+}
+
+func TestServer_Watch(t *testing.T) {
+
+	p := newPKI(t)
+
+	srv, err := NewServer(p.serverCert, p.serverKey, p.caFile)
+	require.NoError(t, err)
+
+	// Конфигурация создается один раз, как при запуске сервера gRPC
+	serverCfg := srv.Config()
+	clientCfg := clientConfig(t, p, true)
+
+	first, err := handshake(t, serverCfg, clientCfg)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Watch(ctx, 10*time.Millisecond)
+
+	renewed, err := NewServerCert(p.ca, secret.KeyEd25519, []string{"localhost"}, time.Hour)
+	require.NoError(t, err)
+	require.NotEqual(t, 0, first.Cmp(renewed.Cert.SerialNumber))
+
+	require.NoError(t, os.WriteFile(p.serverCert, renewed.CertPEM, 0644))
+	require.NoError(t, os.WriteFile(p.serverKey, renewed.KeyPEM, 0600))
+	// Время изменения файлов может совпасть с прежним при грубом разрешении часов ФС
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(p.serverCert, later, later))
+	require.NoError(t, os.Chtimes(p.serverKey, later, later))
+
+	require.Eventually(t, func() bool {
+		serial, err := handshake(t, serverCfg, clientCfg)
+		return err == nil && serial.Cmp(renewed.Cert.SerialNumber) == 0
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Поврежденный файл не загружается, прежний сертификат остается в силе
+	require.NoError(t, os.WriteFile(p.serverCert, []byte("broken"), 0644))
+	require.Error(t, srv.Reload())
+
+	serial, err := handshake(t, serverCfg, clientCfg)
+	require.NoError(t, err)
+	require.Equal(t, 0, serial.Cmp(renewed.Cert.SerialNumber))
+}
+
+func TestClient(t *testing.T) {
+
+	p := newPKI(t)
+
+	cfg, err := Client(p.caFile, p.clientCert, p.clientKey)
+	require.NoError(t, err)
+	require.Len(t, cfg.Certificates, 1)
+
+	require.NotNil(t, cfg.RootCAs)
+	_, err = p.ca.Cert.Verify(x509.VerifyOptions{Roots: cfg.RootCAs})
+	require.NoError(t, err)
+
+	_, err = Client(p.serverKey, "", "")
+	require.ErrorIs(t, err, ErrNoCertificates)
+
+	_, err = Client("", p.clientCert, "")
+	require.Error(t, err)
+}
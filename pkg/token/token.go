@@ -1,22 +1,42 @@
 package token
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
+const (
+	// AccessTTL - Время жизни токена доступа по умолчанию.
+	AccessTTL = 15 * time.Minute
+	// RefreshTTL - Время жизни refresh token по умолчанию.
+	RefreshTTL = 30 * 24 * time.Hour
+)
+
 type Token struct {
 	Email string `json:"email"`
 	jwt.StandardClaims
 }
 
+// GenerateJWT - Создание токена доступа, действующего AccessTTL.
 func GenerateJWT(email, secretKey string) (string, error) {
+	return GenerateSessionJWT(email, ``, secretKey, AccessTTL)
+}
+
+// GenerateSessionJWT - Создание токена доступа сессии session, действующего ttl.
+// Идентификатор сессии передается в claim jti.
+func GenerateSessionJWT(email, session, secretKey string, ttl time.Duration) (string, error) {
 
 	var tokenClaim = Token{
 		Email: email,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(10 * time.Minute).Unix(),
+			Id:        session,
+			ExpiresAt: time.Now().Add(ttl).Unix(),
 		},
 	}
 
@@ -37,3 +57,51 @@ func VerifyJWT(bearerToken, secretKey string) (*jwt.Token, error) {
 
 	return token, err
 }
+
+// IsExpired - Проверка, что VerifyJWT отклонил токен из-за истекшего срока действия.
+func IsExpired(err error) bool {
+
+	var errValidation *jwt.ValidationError
+	return errors.As(err, &errValidation) && errValidation.Errors&jwt.ValidationErrorExpired != 0
+}
+
+// ExpiresAt - Время окончания действия токена без проверки подписи.
+// Используется клиентом, которому секретный ключ неизвестен.
+func ExpiresAt(bearerToken string) (time.Time, error) {
+
+	var claims Token
+	if _, _, err := new(jwt.Parser).ParseUnverified(bearerToken, &claims); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(claims.ExpiresAt, 0), nil
+}
+
+// GenerateRefresh - Создание случайного refresh token.
+func GenerateRefresh() (string, error) {
+	return random(32)
+}
+
+// GenerateSessionID - Создание случайного идентификатора сессии.
+func GenerateSessionID() (string, error) {
+	return random(16)
+}
+
+// random - Случайная строка из size байт в base64url.
+func random(size int) (string, error) {
+
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return ``, err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashRefresh - Хэш refresh token. На сервере хранятся только хэши,
+// чтобы утечка хранилища не позволяла продлевать чужие сессии.
+func HashRefresh(refresh string) string {
+
+	sum := sha256.Sum256([]byte(refresh))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,214 @@
+// Package totp - Одноразовые коды по времени (RFC 6238) для второго фактора
+// авторизации, коды восстановления и шифрование секрета на сервере.
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// Period - Шаг времени, в течение которого действует код.
+	Period = 30 * time.Second
+	// Digits - Количество цифр кода.
+	Digits = 6
+	// Skew - Допустимое расхождение часов клиента и сервера в шагах.
+	Skew = 1
+	// RecoveryCodes - Количество кодов восстановления.
+	RecoveryCodes = 10
+
+	secretSize   = 20
+	recoverySize = 5
+)
+
+// ErrMalformed - Зашифрованный секрет поврежден или зашифрован другим ключом.
+var ErrMalformed = errors.New("totp: malformed sealed secret")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret - Создание случайного секрета.
+func GenerateSecret() ([]byte, error) {
+
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// URI - Ссылка otpauth:// для приложения-аутентификатора.
+func URI(issuer, account string, secret []byte) string {
+
+	values := url.Values{}
+	values.Set("secret", encoding.EncodeToString(secret))
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(Digits))
+	values.Set("period", fmt.Sprint(int(Period/time.Second)))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+// Step - Номер шага времени t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code - Код шага step (HOTP, RFC 4226).
+func Code(secret []byte, step int64) string {
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
+
+// Validate - Проверка кода code на момент t с допуском Skew шагов.
+// Возвращает шаг, которому соответствует код: повторно код этого
+// и предыдущих шагов принимать нельзя.
+func Validate(secret []byte, code string, t time.Time) (int64, bool) {
+
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for step := current - Skew; step <= current+Skew; step++ {
+		if subtle.ConstantTimeCompare([]byte(Code(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes - Создание RecoveryCodes одноразовых кодов восстановления
+// вида xxxx-xxxx для входа без приложения-аутентификатора.
+func GenerateRecoveryCodes() ([]string, error) {
+
+	codes := make([]string, 0, RecoveryCodes)
+	buf := make([]byte, recoverySize)
+
+	for i := 0; i < RecoveryCodes; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(buf))
+		codes = append(codes, code[:4]+"-"+code[4:])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode - Хэш кода восстановления. Регистр и разделители не учитываются.
+// Коды случайные, поэтому медленное хэширование не требуется.
+func HashRecoveryCode(code string) string {
+
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseRecoveryCode - Использование кода восстановления code с хэшами
+// неиспользованных кодов hashes. Возвращает хэши без использованного кода:
+// повторно код не принимается.
+func UseRecoveryCode(hashes []string, code string) ([]string, bool) {
+
+	hash := HashRecoveryCode(code)
+	for i := range hashes {
+		if subtle.ConstantTimeCompare([]byte(hashes[i]), []byte(hash)) == 1 {
+			return append(hashes[:i:i], hashes[i+1:]...), true
+		}
+	}
+
+	return hashes, false
+}
+
+// Seal - Шифрование секрета ключом, производным от ключа сервера key.
+func Seal(key string, secret []byte) ([]byte, error) {
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, secret, nil), nil
+}
+
+// Open - Расшифровка секрета, зашифрованного Seal.
+func Open(key string, sealed []byte) ([]byte, error) {
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	secret, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return secret, nil
+}
+
+// newAEAD - AES-256-GCM с ключом, полученным из key через HKDF-SHA256.
+func newAEAD(key string) (cipher.AEAD, error) {
+
+	derived := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(key), nil, []byte("gophkeeper totp secret")), derived); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
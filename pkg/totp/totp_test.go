@@ -0,0 +1,164 @@
+package totp
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret - Секрет SHA1 из RFC 6238, приложение B.
+var rfcSecret = []byte("12345678901234567890")
+
+// TestCode_RFC6238 - Векторы RFC 6238 (приложение B, SHA1). В RFC коды
+// из 8 цифр, код из Digits цифр - их окончание.
+func TestCode_RFC6238(t *testing.T) {
+
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, v := range vectors {
+		at := time.Unix(v.unix, 0)
+		want := v.code[len(v.code)-Digits:]
+
+		require.Equal(t, want, Code(rfcSecret, Step(at)), "T = %d", v.unix)
+
+		step, ok := Validate(rfcSecret, want, at)
+		require.True(t, ok, "T = %d", v.unix)
+		require.Equal(t, Step(at), step)
+	}
+}
+
+func TestValidate_Skew(t *testing.T) {
+
+	at := time.Unix(1111111111, 0)
+	current := Step(at)
+
+	for offset := int64(-Skew); offset <= Skew; offset++ {
+		step, ok := Validate(rfcSecret, Code(rfcSecret, current+offset), at)
+		require.True(t, ok, "offset %d", offset)
+		require.Equal(t, current+offset, step)
+	}
+
+	// Коды за пределами допуска не принимаются
+	for _, offset := range []int64{-Skew - 1, Skew + 1} {
+		_, ok := Validate(rfcSecret, Code(rfcSecret, current+offset), at)
+		require.False(t, ok, "offset %d", offset)
+	}
+
+	code := Code(rfcSecret, current)
+
+	step, ok := Validate(rfcSecret, " "+code+"\n", at)
+	require.True(t, ok)
+	require.Equal(t, current, step)
+
+	for _, bad := range []string{"", code[1:], code + "0", "abcdef"} {
+		_, ok = Validate(rfcSecret, bad, at)
+		require.False(t, ok, bad)
+	}
+
+	_, ok = Validate([]byte("another secret"), code, at)
+	require.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodes)
+
+	format := regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}$`)
+	hashes := make([]string, 0, len(codes))
+	unique := make(map[string]struct{}, len(codes))
+
+	for _, code := range codes {
+		require.Regexp(t, format, code)
+		unique[code] = struct{}{}
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	require.Len(t, unique, RecoveryCodes)
+
+	// Регистр и разделители не учитываются
+	require.Equal(t, hashes[0], HashRecoveryCode(strings.ToUpper(codes[0])))
+	require.Equal(t, hashes[0], HashRecoveryCode(strings.ReplaceAll(codes[0], "-", " ")))
+
+	rest, ok := UseRecoveryCode(hashes, codes[3])
+	require.True(t, ok)
+	require.Len(t, rest, RecoveryCodes-1)
+	require.NotContains(t, rest, hashes[3])
+
+	// Исходный список не изменяется: его можно сохранить, только если вход удался
+	require.Len(t, hashes, RecoveryCodes)
+	require.Equal(t, HashRecoveryCode(codes[3]), hashes[3])
+
+	// Код действует один раз
+	again, ok := UseRecoveryCode(rest, codes[3])
+	require.False(t, ok)
+	require.Equal(t, rest, again)
+
+	_, ok = UseRecoveryCode(rest, "aaaa-aaaa")
+	require.False(t, ok)
+
+	// Остальные коды действуют
+	for i, code := range codes {
+		if i == 3 {
+			continue
+		}
+		rest, ok = UseRecoveryCode(rest, code)
+		require.True(t, ok, code)
+	}
+	require.Empty(t, rest)
+}
+
+func TestSealOpen(t *testing.T) {
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, secretSize)
+
+	sealed, err := Seal("server key", secret)
+	require.NoError(t, err)
+
+	opened, err := Open("server key", sealed)
+	require.NoError(t, err)
+	require.Equal(t, secret, opened)
+
+	_, err = Open("other key", sealed)
+	require.ErrorIs(t, err, ErrMalformed)
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = Open("server key", sealed)
+	require.ErrorIs(t, err, ErrMalformed)
+
+	_, err = Open("server key", []byte("short"))
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestURI(t *testing.T) {
+
+	uri, err := url.Parse(URI("GophKeeper", "user@mail.ru", rfcSecret))
+	require.NoError(t, err)
+
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/GophKeeper:user@mail.ru", uri.Path)
+
+	query := uri.Query()
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", query.Get("secret"))
+	require.Equal(t, "GophKeeper", query.Get("issuer"))
+	require.Equal(t, "SHA1", query.Get("algorithm"))
+	require.Equal(t, "6", query.Get("digits"))
+	require.Equal(t, "30", query.Get("period"))
+}